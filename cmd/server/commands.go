@@ -15,6 +15,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -27,6 +28,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/redis"
 	"github.com/livekit/protocol/utils"
 	"github.com/livekit/protocol/utils/guid"
 
@@ -246,3 +248,30 @@ func listNodes(c *cli.Context) error {
 
 	return nil
 }
+
+func migrateRedisKeys(c *cli.Context) error {
+	conf, err := getConfig(c)
+	if err != nil {
+		return err
+	}
+	if !conf.Redis.IsConfigured() {
+		return errors.New("redis is not configured")
+	}
+
+	rc, err := redis.GetRedisClient(&conf.Redis)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	store := service.NewShardedRedisStore(rc, conf.RedisStore.IndexShards)
+	migrated, err := store.MigrateToShardedKeys(c.Context)
+	fmt.Printf("migrated %d rooms\n", migrated)
+	if err != nil {
+		return err
+	}
+	if !conf.RedisStore.ShardedKeys {
+		fmt.Println("enable redis_store.sharded_keys for the server to use the migrated keys")
+	}
+	return nil
+}
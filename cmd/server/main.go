@@ -16,12 +16,14 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"math/rand"
 	"net"
 	"os"
 	"os/signal"
 	"runtime"
 	"runtime/pprof"
+	"slices"
 	"syscall"
 	"time"
 
@@ -173,6 +175,11 @@ func main() {
 				Usage:  "list all nodes",
 				Action: listNodes,
 			},
+			{
+				Name:   "migrate-redis-keys",
+				Usage:  "rewrites rooms stored in redis into the sharded key layout",
+				Action: migrateRedisKeys,
+			},
 			{
 				Name:   "help-verbose",
 				Usage:  "prints app help, including all generated configuration flags",
@@ -275,6 +282,7 @@ func startServer(c *cli.Context) error {
 	if err := prometheus.Init(string(currentNode.NodeID()), currentNode.NodeType()); err != nil {
 		return err
 	}
+	prometheus.ConfigureRoomMetrics(conf.Prometheus.RoomMetrics, slices.Collect(maps.Keys(conf.Tenants)))
 
 	server, err := service.InitializeServer(conf, currentNode)
 	if err != nil {
@@ -32,6 +32,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/sfu/bwe/sendsidebwe"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
@@ -56,6 +57,8 @@ type Config struct {
 	Prometheus     PrometheusConfig         `yaml:"prometheus,omitempty"`
 	RTC            RTCConfig                `yaml:"rtc,omitempty"`
 	Redis          redisLiveKit.RedisConfig `yaml:"redis,omitempty"`
+	RedisStore     RedisStoreConfig         `yaml:"redis_store,omitempty"`
+	Cluster        ClusterConfig            `yaml:"cluster,omitempty"`
 	Audio          sfu.AudioConfig          `yaml:"audio,omitempty"`
 	Video          VideoConfig              `yaml:"video,omitempty"`
 	Room           RoomConfig               `yaml:"room,omitempty"`
@@ -63,6 +66,7 @@ type Config struct {
 	Ingress        IngressConfig            `yaml:"ingress,omitempty"`
 	SIP            SIPConfig                `yaml:"sip,omitempty"`
 	WebHook        WebHookConfig            `yaml:"webhook,omitempty"`
+	Analytics      AnalyticsConfig          `yaml:"analytics,omitempty"`
 	NodeSelector   NodeSelectorConfig       `yaml:"node_selector,omitempty"`
 	KeyFile        string                   `yaml:"key_file,omitempty"`
 	Keys           map[string]string        `yaml:"keys,omitempty"`
@@ -74,6 +78,16 @@ type Config struct {
 	Logging  LoggingConfig `yaml:"logging,omitempty"`
 	Limit    LimitConfig   `yaml:"limit,omitempty"`
 
+	// labels of the current node, rooms can require nodes with given labels
+	NodeLabels map[string]string `yaml:"node_labels,omitempty"`
+	// taints of the current node as key=value, only rooms tolerating all taints are placed on the node
+	NodeTaints []string `yaml:"node_taints,omitempty"`
+
+	Drain DrainConfig `yaml:"drain,omitempty"`
+
+	// customers hosted by the deployment, rooms of each tenant are isolated from the others
+	Tenants map[string]*TenantConfig `yaml:"tenants,omitempty"`
+
 	Development bool `yaml:"development,omitempty"`
 
 	Metric metric.MetricConfig `yaml:"metric,omitempty"`
@@ -119,6 +133,31 @@ type RTCConfig struct {
 	DatachannelSlowThreshold int `yaml:"datachannel_slow_threshold,omitempty"`
 
 	ForwardStats ForwardStatsConfig `yaml:"forward_stats,omitempty"`
+
+	// admin triggered captures of decrypted RTP/RTCP of a participant
+	PacketCapture PacketCaptureConfig `yaml:"packet_capture,omitempty"`
+
+	// media relay between nodes, so tracks can be forwarded into rooms hosted on other nodes
+	Relay RelayConfig `yaml:"relay,omitempty"`
+}
+
+type PacketCaptureConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// upper bounds of a capture, requested bounds are clamped to these
+	MaxDuration time.Duration `yaml:"max_duration,omitempty"`
+	MaxBytes    int           `yaml:"max_bytes,omitempty"`
+	// captures are kept in memory of the node hosting the room, for this long after they stopped
+	Retention time.Duration `yaml:"retention,omitempty"`
+}
+
+// RelayConfig sets up the UDP transport relayed tracks are sent over. Other nodes reach it at the node IP,
+// so the port should only be open to the private network of the cluster.
+type RelayConfig struct {
+	Enabled     bool   `yaml:"enabled,omitempty"`
+	BindAddress string `yaml:"bind_address,omitempty"`
+	Port        uint32 `yaml:"port,omitempty"`
+	// shared by the nodes of the cluster to authenticate relayed packets, defaults to a key derived from the API keys
+	Key string `yaml:"key,omitempty"`
 }
 
 type TURNServer struct {
@@ -174,6 +213,46 @@ type RoomConfig struct {
 	// deprecated, moved to limits
 	MaxParticipantIdentityLength int                                   `yaml:"max_participant_identity_length,omitempty"`
 	RoomConfigurations           map[string]*livekit.RoomConfiguration `yaml:"room_configurations,omitempty"`
+	// default policy for participant sessions, can be overridden per room and per token
+	ParticipantPolicy ParticipantPolicyConfig `yaml:"participant_policy,omitempty"`
+	// participants wait in a lobby until admitted, can be changed per room
+	Lobby bool `yaml:"lobby,omitempty"`
+	// call quality report generated when a room ends
+	QualityReport QualityReportConfig `yaml:"quality_report,omitempty"`
+	// append-only journal of room events, readable with RoomService.GetRoomEvents
+	EventJournal EventJournalConfig `yaml:"event_journal,omitempty"`
+	// periodic snapshots of room state, restored when a room is created again after its node failed
+	Snapshot RoomSnapshotConfig `yaml:"snapshot,omitempty"`
+}
+
+type QualityReportConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how long reports are retrievable after the room ended
+	Retention time.Duration `yaml:"retention,omitempty"`
+}
+
+type EventJournalConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how long events are kept after the last one was written
+	Retention time.Duration `yaml:"retention,omitempty"`
+	// older events are dropped once a room has more
+	MaxEvents int `yaml:"max_events,omitempty"`
+}
+
+type RoomSnapshotConfig struct {
+	Enabled  bool          `yaml:"enabled,omitempty"`
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// snapshots that are not refreshed expire after retention, as the room is no longer hosted
+	Retention time.Duration `yaml:"retention,omitempty"`
+}
+
+type ParticipantPolicyConfig struct {
+	// participants are removed once their session exceeds this duration, 0 to disable
+	MaxDuration time.Duration `yaml:"max_duration,omitempty"`
+	// participants that neither publish unmuted tracks nor send data for this long are removed, 0 to disable
+	IdleTimeout time.Duration `yaml:"idle_timeout,omitempty"`
+	// participants are warned this long before they are removed, 0 to disable warnings
+	WarningBefore time.Duration `yaml:"warning_before,omitempty"`
 }
 
 type CodecSpec struct {
@@ -204,12 +283,126 @@ type WebHookConfig struct {
 	APIKey string `yaml:"api_key,omitempty"`
 }
 
+type AnalyticsConfig struct {
+	// identifies the deployment in analytics records
+	AnalyticsKey string `yaml:"analytics_key,omitempty"`
+	// records are written to each sink in batches of up to BatchSize, at least every FlushInterval
+	BatchSize     int           `yaml:"batch_size,omitempty"`
+	FlushInterval time.Duration `yaml:"flush_interval,omitempty"`
+	// records are dropped when a sink falls behind by more than QueueSize records
+	QueueSize int `yaml:"queue_size,omitempty"`
+
+	File  AnalyticsFileConfig  `yaml:"file,omitempty"`
+	HTTP  AnalyticsHTTPConfig  `yaml:"http,omitempty"`
+	Kafka AnalyticsKafkaConfig `yaml:"kafka,omitempty"`
+}
+
+// AnalyticsFileConfig writes JSON lines files to Directory
+type AnalyticsFileConfig struct {
+	Directory string `yaml:"directory,omitempty"`
+	// a new file is started once the current one reaches MaxSize bytes or MaxAge
+	MaxSize int64         `yaml:"max_size,omitempty"`
+	MaxAge  time.Duration `yaml:"max_age,omitempty"`
+	// oldest files are removed when there are more than MaxFiles, 0 keeps all files
+	MaxFiles int `yaml:"max_files,omitempty"`
+}
+
+// AnalyticsHTTPConfig posts batches of JSON lines to URL
+type AnalyticsHTTPConfig struct {
+	URL     string            `yaml:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Timeout time.Duration     `yaml:"timeout,omitempty"`
+}
+
+// AnalyticsKafkaConfig produces records to a Kafka topic, using the Kafka wire protocol
+type AnalyticsKafkaConfig struct {
+	Brokers  []string `yaml:"brokers,omitempty"`
+	Topic    string   `yaml:"topic,omitempty"`
+	ClientID string   `yaml:"client_id,omitempty"`
+	// none, leader (default) or all
+	RequiredAcks string        `yaml:"required_acks,omitempty"`
+	Timeout      time.Duration `yaml:"timeout,omitempty"`
+}
+
 type NodeSelectorConfig struct {
 	Kind         string         `yaml:"kind,omitempty"`
 	SortBy       string         `yaml:"sort_by,omitempty"`
 	CPULoadLimit float32        `yaml:"cpu_load_limit,omitempty"`
 	SysloadLimit float32        `yaml:"sysload_limit,omitempty"`
 	Regions      []RegionConfig `yaml:"regions,omitempty"`
+	// expression scoring each node, used by the script selector
+	Script string                 `yaml:"script,omitempty"`
+	HTTP   NodeSelectorHTTPConfig `yaml:"http,omitempty"`
+	// time allowed for the script or http selector to decide before the fallback selector is used
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// built-in selector used when the script or http selector fails
+	Fallback string `yaml:"fallback,omitempty"`
+	// node label requirements and taint tolerations of rooms, the first rule matching a room applies
+	RoomPlacement []RoomPlacementConfig `yaml:"room_placement,omitempty"`
+
+	ParticipantPlacement ParticipantPlacementConfig `yaml:"participant_placement,omitempty"`
+}
+
+// NodeSelectorHTTPConfig sets the service the http selector posts candidate nodes to
+type NodeSelectorHTTPConfig struct {
+	URL     string            `yaml:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// RoomPlacementConfig constrains the nodes rooms matching a name pattern are placed on
+type RoomPlacementConfig struct {
+	// pattern of room names, as in path.Match, e.g. acme-*
+	Room string `yaml:"room,omitempty"`
+	// labels a node is required to have
+	NodeLabels map[string]string `yaml:"node_labels,omitempty"`
+	// node taints tolerated, as key=value, or key to tolerate any value
+	Tolerations []string `yaml:"tolerations,omitempty"`
+}
+
+const (
+	ParticipantPlacementOrigin  = "origin"
+	ParticipantPlacementNearest = "nearest"
+)
+
+// ParticipantPlacementConfig decides which node a participant connects to. With the nearest policy, clients
+// located far from the region of the node hosting the room join an edge node in their region instead,
+// and tracks are relayed between the edge and the origin node.
+type ParticipantPlacementConfig struct {
+	Policy string `yaml:"policy,omitempty"`
+	// also orders the regions sent to clients asked to reconnect, regardless of the policy
+	GeoIPDatabase string `yaml:"geoip_database,omitempty"`
+	// clients are placed at the origin unless the nearest region is closer to them by at least this distance
+	MinDistanceKm float64 `yaml:"min_distance_km,omitempty"`
+}
+
+// RedisStoreConfig sets the key layout of the room store in Redis. With sharded keys, each room is stored
+// in keys of its own, hash tagged with the room name, instead of global hashes, so the load is spread across
+// the slots of a Redis Cluster. Existing keys are rewritten with the migrate-redis-keys command.
+type RedisStoreConfig struct {
+	ShardedKeys bool `yaml:"sharded_keys,omitempty"`
+	// number of sets room names are indexed in
+	IndexShards int `yaml:"index_shards,omitempty"`
+}
+
+// ClusterConfig forms a cluster of nodes without Redis. Nodes gossip their membership, the room to node map
+// and the room store, and send psrpc messages to each other over direct connections, so it is meant for small
+// clusters on a private network.
+type ClusterConfig struct {
+	// address to listen on for cluster traffic, e. g. 0.0.0.0:7890
+	BindAddress string `yaml:"bind_address,omitempty"`
+	// address other nodes reach this node at, defaults to the node IP with the bound port
+	AdvertiseAddress string `yaml:"advertise_address,omitempty"`
+	// addresses of nodes to join, any live node of the cluster will do
+	Seeds          []string      `yaml:"seeds,omitempty"`
+	GossipInterval time.Duration `yaml:"gossip_interval,omitempty"`
+	// nodes not heard from for this long are considered gone
+	NodeTimeout time.Duration `yaml:"node_timeout,omitempty"`
+	// secret shared by the nodes of the cluster, frames between nodes are authenticated with it. required
+	Key string `yaml:"key,omitempty"`
+}
+
+func (c ClusterConfig) IsConfigured() bool {
+	return c.BindAddress != ""
 }
 
 type SignalRelayConfig struct {
@@ -218,6 +411,21 @@ type SignalRelayConfig struct {
 	MaxRetryInterval time.Duration `yaml:"max_retry_interval,omitempty"`
 	StreamBufferSize int           `yaml:"stream_buffer_size,omitempty"`
 	ConnectAttempts  int           `yaml:"connect_attempts,omitempty"`
+	// ResumeOnNodeFailure re-establishes participants on another RTC node when theirs becomes unreachable
+	ResumeOnNodeFailure bool          `yaml:"resume_on_node_failure,omitempty"`
+	ResumeTimeout       time.Duration `yaml:"resume_timeout,omitempty"`
+}
+
+// TenantConfig isolates the rooms of a customer sharing the deployment. Rooms created with the API keys of
+// the tenant are namespaced as <tenant>/<room>, so tenants cannot reach rooms of each other.
+type TenantConfig struct {
+	// API keys of the tenant
+	Keys []string `yaml:"keys,omitempty"`
+	// webhooks of rooms of the tenant, sent in addition to the deployment webhooks with room names
+	// without the tenant prefix. signed with the first key of the tenant when api_key is not set
+	WebHook WebHookConfig `yaml:"webhook,omitempty"`
+	// replaces the limits applied to rooms and participants of the tenant
+	Limit *LimitConfig `yaml:"limit,omitempty"`
 }
 
 // RegionConfig lists available regions and their latitude/longitude, so the selector would prefer
@@ -226,6 +434,16 @@ type RegionConfig struct {
 	Name string  `yaml:"name,omitempty"`
 	Lat  float64 `yaml:"lat,omitempty"`
 	Lon  float64 `yaml:"lon,omitempty"`
+	// URL clients connect to the region with, sent to clients asked to reconnect
+	URL string `yaml:"url,omitempty"`
+}
+
+// DrainConfig sets how rooms leave a node that is shutting down. With reconnect, each room is moved to another
+// node and its participants are asked to reconnect there, instead of waiting for them to leave.
+type DrainConfig struct {
+	Reconnect bool `yaml:"reconnect,omitempty"`
+	// rooms move at random times within the window, spreading client reconnects
+	Window time.Duration `yaml:"window,omitempty"`
 }
 
 type LimitConfig struct {
@@ -239,6 +457,28 @@ type LimitConfig struct {
 	MaxRoomNameLength            int    `yaml:"max_room_name_length,omitempty"`
 	MaxParticipantIdentityLength int    `yaml:"max_participant_identity_length,omitempty"`
 	MaxParticipantNameLength     int    `yaml:"max_participant_name_length,omitempty"`
+
+	// predicts the load a participant adds before admitting it, on top of the static limits above
+	Admission AdmissionConfig `yaml:"admission,omitempty"`
+}
+
+type AdmissionConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// joins are refused, and new rooms placed elsewhere, when the CPU load of the node would exceed this
+	MaxCPULoad float64 `yaml:"max_cpu_load,omitempty"`
+	// same for bytes in & out per second, defaults to limit.bytes_per_sec
+	MaxBytesPerSec float64 `yaml:"max_bytes_per_sec,omitempty"`
+	// tracks a publisher is expected to publish, e. g. microphone & camera
+	TracksPerPublisher int `yaml:"tracks_per_publisher,omitempty"`
+	// cost of forwarding a track until the node has forwarded enough tracks to learn its own, in CPU cores
+	CPUPerTrack float64 `yaml:"cpu_per_track,omitempty"`
+	// same, in bytes per second
+	BytesPerTrack float64 `yaml:"bytes_per_track,omitempty"`
+	// CPU cost of forwarding tracks of a room relative to the node's average, keyed by codec mime type.
+	// the most expensive codec enabled for the room applies, e. g. video/av1: 1.5
+	CodecCostFactors map[string]float64 `yaml:"codec_cost_factors,omitempty"`
+	// suggested to refused clients
+	RetryAfter time.Duration `yaml:"retry_after,omitempty"`
 }
 
 func (l LimitConfig) CheckRoomNameLength(name string) bool {
@@ -284,9 +524,10 @@ type APIConfig struct {
 }
 
 type PrometheusConfig struct {
-	Port     uint32 `yaml:"port,omitempty"`
-	Username string `yaml:"username,omitempty"`
-	Password string `yaml:"password,omitempty"`
+	Port        uint32                       `yaml:"port,omitempty"`
+	Username    string                       `yaml:"username,omitempty"`
+	Password    string                       `yaml:"password,omitempty"`
+	RoomMetrics prometheus.RoomMetricsConfig `yaml:"room_metrics,omitempty"`
 }
 
 type ForwardStatsConfig struct {
@@ -326,6 +567,17 @@ var DefaultConfig = Config{
 			UseSendSideBWE:            false,
 			SendSideBWE:               sendsidebwe.DefaultSendSideBWEConfig,
 		},
+		PacketCapture: PacketCaptureConfig{
+			MaxDuration: 5 * time.Minute,
+			MaxBytes:    100 << 20,
+			Retention:   10 * time.Minute,
+		},
+		Relay: RelayConfig{
+			Port: 7883,
+		},
+	},
+	Prometheus: PrometheusConfig{
+		RoomMetrics: prometheus.DefaultRoomMetricsConfig,
 	},
 	Audio: sfu.DefaultAudioConfig,
 	Video: VideoConfig{
@@ -333,6 +585,9 @@ var DefaultConfig = Config{
 		StreamTrackerManager: sfu.DefaultStreamTrackerManagerConfig,
 	},
 	Redis: redisLiveKit.RedisConfig{},
+	RedisStore: RedisStoreConfig{
+		IndexShards: 16,
+	},
 	Room: RoomConfig{
 		AutoCreate: true,
 		EnabledCodecs: []CodecSpec{
@@ -349,6 +604,17 @@ var DefaultConfig = Config{
 		CreateRoomEnabled:  true,
 		CreateRoomTimeout:  10 * time.Second,
 		CreateRoomAttempts: 3,
+		QualityReport: QualityReportConfig{
+			Retention: 24 * time.Hour,
+		},
+		EventJournal: EventJournalConfig{
+			Retention: 72 * time.Hour,
+			MaxEvents: 10000,
+		},
+		Snapshot: RoomSnapshotConfig{
+			Interval:  30 * time.Second,
+			Retention: 10 * time.Minute,
+		},
 	},
 	Limit: LimitConfig{
 		MaxMetadataSize:              64000,
@@ -356,6 +622,14 @@ var DefaultConfig = Config{
 		MaxRoomNameLength:            256,
 		MaxParticipantIdentityLength: 256,
 		MaxParticipantNameLength:     256,
+
+		Admission: AdmissionConfig{
+			MaxCPULoad:         0.85,
+			TracksPerPublisher: 2,
+			CPUPerTrack:        0.002,
+			BytesPerTrack:      62_500,
+			RetryAfter:         5 * time.Second,
+		},
 	},
 	Logging: LoggingConfig{
 		PionLevel: "error",
@@ -363,11 +637,41 @@ var DefaultConfig = Config{
 	TURN: TURNConfig{
 		Enabled: false,
 	},
+	Cluster: ClusterConfig{
+		GossipInterval: time.Second,
+		NodeTimeout:    5 * time.Second,
+	},
+	Drain: DrainConfig{
+		Window: 30 * time.Second,
+	},
 	NodeSelector: NodeSelectorConfig{
 		Kind:         "any",
 		SortBy:       "random",
 		SysloadLimit: 0.9,
 		CPULoadLimit: 0.9,
+		Timeout:      500 * time.Millisecond,
+		Fallback:     "any",
+		ParticipantPlacement: ParticipantPlacementConfig{
+			Policy:        ParticipantPlacementOrigin,
+			MinDistanceKm: 1000,
+		},
+	},
+	Analytics: AnalyticsConfig{
+		BatchSize:     100,
+		FlushInterval: 5 * time.Second,
+		QueueSize:     10000,
+		File: AnalyticsFileConfig{
+			MaxSize: 100 << 20,
+			MaxAge:  time.Hour,
+		},
+		HTTP: AnalyticsHTTPConfig{
+			Timeout: 10 * time.Second,
+		},
+		Kafka: AnalyticsKafkaConfig{
+			ClientID:     "livekit-server",
+			RequiredAcks: "leader",
+			Timeout:      10 * time.Second,
+		},
 	},
 	SignalRelay: SignalRelayConfig{
 		RetryTimeout:     7500 * time.Millisecond,
@@ -375,6 +679,7 @@ var DefaultConfig = Config{
 		MaxRetryInterval: 4 * time.Second,
 		StreamBufferSize: 1000,
 		ConnectAttempts:  3,
+		ResumeTimeout:    30 * time.Second,
 	},
 	PSRPC:  rpc.DefaultPSRPCConfig,
 	Keys:   map[string]string{},
@@ -411,6 +716,9 @@ func NewConfig(confString string, strictMode bool, c *cli.Context, baseFlags []c
 	if err := conf.RTC.Validate(conf.Development); err != nil {
 		return nil, fmt.Errorf("could not validate RTC config: %v", err)
 	}
+	if err := conf.Audio.QualityScorer.Validate(); err != nil {
+		return nil, fmt.Errorf("could not validate audio config: %v", err)
+	}
 
 	// expand env vars in filenames
 	file, err := homedir.Expand(os.ExpandEnv(conf.KeyFile))
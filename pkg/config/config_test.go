@@ -22,6 +22,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/livekit/livekit-server/pkg/config/configtest"
+	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 )
 
 func TestConfig_UnmarshalKeys(t *testing.T) {
@@ -49,6 +50,17 @@ room:
 	require.Error(t, err)
 }
 
+func TestConfig_AudioQualityScorer(t *testing.T) {
+	conf, err := NewConfig(`audio:
+  quality_scorer: emodel`, true, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, connectionquality.AudioScorerEModel, conf.Audio.QualityScorer)
+
+	_, err = NewConfig(`audio:
+  quality_scorer: e-model`, true, nil, nil)
+	require.ErrorContains(t, err, "e-model")
+}
+
 func TestGeneratedFlags(t *testing.T) {
 	generatedFlags, err := GenerateCLIFlags(nil, false)
 	require.NoError(t, err)
@@ -20,6 +20,7 @@ type MetricConfig struct {
 	Timestamper MetricTimestamperConfig `yaml:"timestamper_config,omitempty"`
 	Collector   MetricsCollectorConfig  `yaml:"collector,omitempty"`
 	Reporter    MetricsReporterConfig   `yaml:"reporter,omitempty"`
+	Aggregator  MetricsAggregatorConfig `yaml:"aggregator,omitempty"`
 }
 
 var (
@@ -27,5 +28,6 @@ var (
 		Timestamper: DefaultMetricTimestamperConfig,
 		Collector:   DefaultMetricsCollectorConfig,
 		Reporter:    DefaultMetricsReporterConfig,
+		Aggregator:  DefaultMetricsAggregatorConfig,
 	}
 )
@@ -0,0 +1,112 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"github.com/livekit/protocol/livekit"
+)
+
+// --------------------------------------------------------
+
+type MetricsAggregatorConfig struct {
+	// record samples and event durations of metrics batches in Prometheus histograms
+	Prometheus bool `yaml:"prometheus,omitempty" json:"prometheus,omitempty"`
+	// write metrics batches to the analytics sinks
+	Analytics bool `yaml:"analytics,omitempty" json:"analytics,omitempty"`
+	// allow-list of metric labels, either predefined label names (e.g. AGENTS_TTS_TTFB) or custom labels.
+	// when empty, all predefined labels are aggregated and custom labels are dropped
+	Labels []string `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
+
+func (c MetricsAggregatorConfig) Enabled() bool {
+	return c.Prometheus || c.Analytics
+}
+
+var (
+	DefaultMetricsAggregatorConfig = MetricsAggregatorConfig{}
+)
+
+// --------------------------------------------------------
+
+// MetricsLabelFilter selects time series and events of metrics batches by their label
+type MetricsLabelFilter struct {
+	allowed map[string]struct{}
+}
+
+func NewMetricsLabelFilter(labels []string) *MetricsLabelFilter {
+	f := &MetricsLabelFilter{
+		allowed: make(map[string]struct{}, len(labels)),
+	}
+	for _, label := range labels {
+		f.allowed[label] = struct{}{}
+	}
+	return f
+}
+
+func (f *MetricsLabelFilter) IsAllowed(label string, predefined bool) bool {
+	if len(f.allowed) == 0 {
+		return predefined
+	}
+	_, ok := f.allowed[label]
+	return ok
+}
+
+// Filter returns a batch with the allowed time series and events only, nil if there are none.
+// The returned batch shares string data, series and events with the given one.
+func (f *MetricsLabelFilter) Filter(mb *livekit.MetricsBatch) *livekit.MetricsBatch {
+	if mb == nil {
+		return nil
+	}
+
+	filtered := &livekit.MetricsBatch{
+		TimestampMs:         mb.TimestampMs,
+		NormalizedTimestamp: mb.NormalizedTimestamp,
+		StrData:             mb.StrData,
+	}
+	for _, ts := range mb.TimeSeries {
+		if name, predefined, ok := MetricLabelName(mb, ts.Label); ok && f.IsAllowed(name, predefined) {
+			filtered.TimeSeries = append(filtered.TimeSeries, ts)
+		}
+	}
+	for _, ev := range mb.Events {
+		if name, predefined, ok := MetricLabelName(mb, ev.Label); ok && f.IsAllowed(name, predefined) {
+			filtered.Events = append(filtered.Events, ev)
+		}
+	}
+	if len(filtered.TimeSeries) == 0 && len(filtered.Events) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+// MetricLabelName resolves a label index of a batch, predefined labels resolve to their MetricLabel name
+func MetricLabelName(mb *livekit.MetricsBatch, label uint32) (name string, predefined bool, ok bool) {
+	if label < uint32(livekit.MetricLabel_METRIC_LABEL_PREDEFINED_MAX_VALUE) {
+		name, ok = livekit.MetricLabel_name[int32(label)]
+		return name, true, ok
+	}
+
+	name, ok = MetricStrData(mb, label)
+	return name, false, ok
+}
+
+// MetricStrData resolves an index into the string data of a batch
+func MetricStrData(mb *livekit.MetricsBatch, index uint32) (string, bool) {
+	idx := int(index) - int(livekit.MetricLabel_METRIC_LABEL_PREDEFINED_MAX_VALUE)
+	if idx < 0 || idx >= len(mb.StrData) {
+		return "", false
+	}
+	return mb.StrData[idx], true
+}
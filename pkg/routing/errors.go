@@ -28,6 +28,7 @@ var (
 	ErrInvalidRouterMessage = errors.New("invalid router message")
 	ErrChannelClosed        = errors.New("channel closed")
 	ErrChannelFull          = errors.New("channel is full")
+	ErrClusterKeyNotSet     = errors.New("cluster key is required and not set")
 
 	// errors when starting signal connection
 	ErrRequestChannelClosed       = errors.New("request channel closed")
@@ -0,0 +1,80 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geoip locates IP addresses with a MaxMind DB file, i. e. GeoLite2-City.mmdb or any database
+// in the same format carrying location.latitude and location.longitude for its networks.
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+var (
+	ErrInvalidDatabase = errors.New("invalid geoip database")
+	ErrNoLocation      = errors.New("no location for address")
+)
+
+type Location struct {
+	Lat float64
+	Lon float64
+}
+
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+type record struct {
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buf)
+}
+
+func FromBytes(buf []byte) (*Reader, error) {
+	db, err := maxminddb.FromBytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+	return &Reader{db: db}, nil
+}
+
+// Locate returns the location of the network the address is in
+func (r *Reader) Locate(ip net.IP) (Location, error) {
+	// an IPv6 address cannot be in an IPv4 database
+	if ip.To4() == nil && r.db.Metadata.IPVersion != 6 {
+		return Location{}, ErrNoLocation
+	}
+
+	var rec record
+	if err := r.db.Lookup(ip, &rec); err != nil {
+		return Location{}, err
+	}
+	if rec.Location.Latitude == nil || rec.Location.Longitude == nil {
+		return Location{}, ErrNoLocation
+	}
+	return Location{Lat: *rec.Location.Latitude, Lon: *rec.Location.Longitude}, nil
+}
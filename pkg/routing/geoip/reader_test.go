@@ -0,0 +1,163 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geoip
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader(t *testing.T) {
+	db := buildTestDatabase(t, map[string]Location{
+		"81.2.69.0/24":  {Lat: 51.5142, Lon: -0.0931},
+		"175.16.0.0/16": {Lat: 43.88, Lon: 125.3228},
+	})
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, os.WriteFile(path, db, 0644))
+
+	r, err := Open(path)
+	require.NoError(t, err)
+
+	loc, err := r.Locate(net.ParseIP("81.2.69.160"))
+	require.NoError(t, err)
+	require.Equal(t, Location{Lat: 51.5142, Lon: -0.0931}, loc)
+
+	loc, err = r.Locate(net.ParseIP("175.16.199.1"))
+	require.NoError(t, err)
+	require.Equal(t, Location{Lat: 43.88, Lon: 125.3228}, loc)
+
+	_, err = r.Locate(net.ParseIP("10.0.0.1"))
+	require.ErrorIs(t, err, ErrNoLocation)
+
+	_, err = r.Locate(net.ParseIP("2001:db8::1"))
+	require.ErrorIs(t, err, ErrNoLocation)
+
+	_, err = FromBytes([]byte("not a database"))
+	require.ErrorIs(t, err, ErrInvalidDatabase)
+}
+
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+const dataSectionSeparatorSize = 16
+
+const (
+	typeString = 2
+	typeDouble = 3
+	typeUint16 = 5
+	typeUint32 = 6
+	typeMap    = 7
+)
+
+// buildTestDatabase writes an IPv4 database with 24 bit records
+func buildTestDatabase(t *testing.T, networks map[string]Location) []byte {
+	type node struct {
+		children [2]int // -1 for no data, < -1 for data at -(value+2)
+	}
+	nodes := []*node{{children: [2]int{-1, -1}}}
+
+	var data []byte
+	cidrs := make([]string, 0, len(networks))
+	for cidr := range networks {
+		cidrs = append(cidrs, cidr)
+	}
+	sort.Strings(cidrs)
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		ones, _ := ipNet.Mask.Size()
+		loc := networks[cidr]
+
+		dataOffset := len(data)
+		data = append(data, encodeMap(
+			"location", encodeMap(
+				"latitude", encodeDouble(loc.Lat),
+				"longitude", encodeDouble(loc.Lon),
+			),
+		)...)
+
+		ip := ipNet.IP.To4()
+		n := nodes[0]
+		for i := 0; i < ones; i++ {
+			bit := (ip[i>>3] >> (7 - uint(i&7))) & 1
+			if i == ones-1 {
+				n.children[bit] = -(dataOffset + 2)
+				break
+			}
+			if n.children[bit] < 0 {
+				nodes = append(nodes, &node{children: [2]int{-1, -1}})
+				n.children[bit] = len(nodes) - 1
+			}
+			n = nodes[n.children[bit]]
+		}
+	}
+
+	var db []byte
+	nodeCount := len(nodes)
+	for _, n := range nodes {
+		for _, c := range n.children {
+			var record int
+			switch {
+			case c == -1:
+				record = nodeCount
+			case c < -1:
+				record = nodeCount + dataSectionSeparatorSize - (c + 2)
+			default:
+				record = c
+			}
+			db = append(db, byte(record>>16), byte(record>>8), byte(record))
+		}
+	}
+	db = append(db, make([]byte, dataSectionSeparatorSize)...)
+	db = append(db, data...)
+	db = append(db, metadataStartMarker...)
+	db = append(db, encodeMap(
+		"node_count", encodeUint32(uint32(nodeCount)),
+		"record_size", encodeUint16(24),
+		"ip_version", encodeUint16(4),
+	)...)
+	return db
+}
+
+func encodeMap(kvs ...any) []byte {
+	b := []byte{typeMap<<5 | byte(len(kvs)/2)}
+	for i := 0; i < len(kvs); i += 2 {
+		b = append(b, encodeString(kvs[i].(string))...)
+		b = append(b, kvs[i+1].([]byte)...)
+	}
+	return b
+}
+
+func encodeString(s string) []byte {
+	return append([]byte{typeString<<5 | byte(len(s))}, s...)
+}
+
+func encodeDouble(v float64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{typeDouble<<5 | 8}, math.Float64bits(v))
+}
+
+func encodeUint16(v uint16) []byte {
+	return binary.BigEndian.AppendUint16([]byte{typeUint16<<5 | 2}, v)
+}
+
+func encodeUint32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32([]byte{typeUint32<<5 | 4}, v)
+}
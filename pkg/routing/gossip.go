@@ -0,0 +1,1078 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"hash/fnv"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+const (
+	gossipFanout        = 3
+	gossipPeerQueueSize = 4096
+	gossipDialTimeout   = 2 * time.Second
+	gossipWriteTimeout  = 5 * time.Second
+	gossipRedialDelay   = time.Second
+	// cleared rooms are kept long enough for every node to learn about it
+	gossipRoomTombstoneTTL = time.Minute
+	// time given to peers to receive the leave notice
+	gossipLeaveTimeout = 500 * time.Millisecond
+	// time to wait for the owner of a lock to answer
+	gossipLockTimeout = 2 * time.Second
+
+	gossipNonceSize    = 16
+	gossipTagSize      = 16
+	gossipMaxFrameSize = 64 << 20
+)
+
+var (
+	errGossipLockTimeout = errors.New("lock owner did not respond")
+	errGossipFrameSize   = errors.New("gossip frame too large")
+	errGossipInvalidTag  = errors.New("invalid gossip frame tag")
+)
+
+// GossipMember is a node of the cluster, as gossiped between nodes
+type GossipMember struct {
+	NodeID  livekit.NodeID
+	Address string
+	// incremented by the member every gossip interval, the highest one seen wins
+	Heartbeat uint64
+	// marshaled livekit.Node
+	Node []byte
+	Left bool
+	// psrpc channels the member has queue subscribers on
+	QueueChannels []string
+}
+
+type gossipRoute struct {
+	NodeID livekit.NodeID
+	// unix nanoseconds of the assignment, the latest one wins
+	Version int64
+	Cleared bool
+}
+
+// GossipKey identifies a replicated entry, entries of a room are scoped by Room
+type GossipKey struct {
+	Kind string
+	Room livekit.RoomName
+	ID   string
+}
+
+type gossipEntry struct {
+	Value []byte
+	// unix nanoseconds of the write, the latest one wins
+	Version int64
+	NodeID  livekit.NodeID
+	// unix nanoseconds, zero when the entry does not expire
+	ExpiresAt int64
+	Deleted   bool
+}
+
+func (e gossipEntry) isExpired(now time.Time) bool {
+	return e.ExpiresAt != 0 && e.ExpiresAt < now.UnixNano()
+}
+
+func (e gossipEntry) isNewerThan(o gossipEntry) bool {
+	return e.Version > o.Version || (e.Version == o.Version && e.NodeID > o.NodeID)
+}
+
+type gossipState struct {
+	Members []GossipMember
+	Rooms   map[livekit.RoomName]gossipRoute
+	Entries map[GossipKey]gossipEntry
+}
+
+type gossipPublish struct {
+	Channel psrpc.Channel
+	// marshaled anypb.Any
+	Message []byte
+	// the only node delivering the message to queue subscribers
+	QueueNodeID livekit.NodeID
+}
+
+type gossipLockRequest struct {
+	ID     uint64
+	NodeID livekit.NodeID
+	Key    string
+	Token  string
+	// zero to unlock
+	Duration time.Duration
+}
+
+type gossipLockResponse struct {
+	ID uint64
+	OK bool
+}
+
+// gossipFrame is what nodes send each other, one field is set.
+//
+// On a connection, the accepting node first sends a random nonce. Each frame is then written as its length,
+// the gob encoded frame and a truncated HMAC-SHA256 under the cluster key of the nonce, the sequence number
+// of the frame on the connection and the encoded frame, so frames can neither be forged nor replayed.
+type gossipFrame struct {
+	State        *gossipState
+	Publish      *gossipPublish
+	LockRequest  *gossipLockRequest
+	LockResponse *gossipLockResponse
+}
+
+type gossipMember struct {
+	GossipMember
+	// when the heartbeat last increased, according to the local clock
+	seenAt time.Time
+	queues map[string]struct{}
+}
+
+type gossipLock struct {
+	token     string
+	expiresAt time.Time
+}
+
+// Gossip keeps the membership of a cluster without Redis. Every gossip interval a node pushes its view of the members,
+// of the room to node map and of replicated entries to a few peers, the view of a member with the highest heartbeat
+// wins and the latest write of a room assignment or an entry wins. Writes are also pushed to every peer right away.
+// A member is gone when its heartbeat has not increased for the node timeout.
+// Messages of the bus and lock requests are sent over the same connections, so they are ordered between two nodes.
+type Gossip struct {
+	conf        config.ClusterConfig
+	currentNode LocalNode
+
+	lock      sync.RWMutex
+	address   string
+	heartbeat uint64
+	members   map[livekit.NodeID]*gossipMember
+	// heartbeat of members removed as dead, older news about them is ignored
+	removed   map[livekit.NodeID]uint64
+	rooms     map[livekit.RoomName]gossipRoute
+	queues    []string
+	peers     map[string]*gossipPeer
+	inbound   map[net.Conn]struct{}
+	onPublish func(*gossipPublish)
+
+	// serializes changes to entries, so onEntry sees them in order
+	entryLock sync.Mutex
+	entries   map[GossipKey]gossipEntry
+	onEntry   func(key GossipKey, value []byte, expiresAt time.Time, deleted bool)
+
+	// locks owned by this node, and requests waiting for the owner of a lock
+	locks        map[string]gossipLock
+	lockWaiters  map[uint64]chan bool
+	lockRequests atomic.Uint64
+
+	listener  net.Listener
+	isStarted atomic.Bool
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+func NewGossip(conf config.ClusterConfig, currentNode LocalNode) (*Gossip, error) {
+	if conf.Key == "" {
+		return nil, ErrClusterKeyNotSet
+	}
+	if conf.GossipInterval <= 0 {
+		conf.GossipInterval = config.DefaultConfig.Cluster.GossipInterval
+	}
+	if conf.NodeTimeout <= 0 {
+		conf.NodeTimeout = config.DefaultConfig.Cluster.NodeTimeout
+	}
+
+	g := &Gossip{
+		conf:        conf,
+		currentNode: currentNode,
+		members:     make(map[livekit.NodeID]*gossipMember),
+		removed:     make(map[livekit.NodeID]uint64),
+		rooms:       make(map[livekit.RoomName]gossipRoute),
+		peers:       make(map[string]*gossipPeer),
+		inbound:     make(map[net.Conn]struct{}),
+		entries:     make(map[GossipKey]gossipEntry),
+		locks:       make(map[string]gossipLock),
+		lockWaiters: make(map[uint64]chan bool),
+	}
+	g.ctx, g.cancel = context.WithCancel(context.Background())
+	return g, nil
+}
+
+// Address is the address other nodes reach this node at, known once started
+func (g *Gossip) Address() string {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return g.address
+}
+
+// OnPublish sets the callback receiving messages published on other nodes
+func (g *Gossip) OnPublish(f func(p *gossipPublish)) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.onPublish = f
+}
+
+// OnEntry sets the callback receiving replicated entries, made on this node or on others, in the order they apply.
+// Entries known so far are passed right away. The callback must not call back into Gossip.
+func (g *Gossip) OnEntry(f func(key GossipKey, value []byte, expiresAt time.Time, deleted bool)) {
+	g.entryLock.Lock()
+	defer g.entryLock.Unlock()
+
+	g.onEntry = f
+	for key, e := range g.entries {
+		g.notifyEntryLocked(key, e)
+	}
+}
+
+func (g *Gossip) NodeID() livekit.NodeID {
+	return g.currentNode.NodeID()
+}
+
+func (g *Gossip) Start() error {
+	if g.isStarted.Swap(true) {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", g.conf.BindAddress)
+	if err != nil {
+		g.isStarted.Store(false)
+		return err
+	}
+
+	address := g.conf.AdvertiseAddress
+	if address == "" {
+		host, _, _ := net.SplitHostPort(g.conf.BindAddress)
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+			host = g.currentNode.Clone().Ip
+		}
+		address = net.JoinHostPort(host, strconv.Itoa(listener.Addr().(*net.TCPAddr).Port))
+	}
+
+	g.lock.Lock()
+	g.listener = listener
+	g.address = address
+	g.lock.Unlock()
+
+	logger.Infow("using gossip routing", "address", address, "seeds", g.conf.Seeds)
+
+	go g.acceptWorker()
+	go g.gossipWorker()
+	return nil
+}
+
+// Leave tells peers this node is leaving, so they do not wait for it to time out
+func (g *Gossip) Leave() {
+	if !g.isStarted.Load() {
+		return
+	}
+
+	g.lock.Lock()
+	g.heartbeat++
+	state := g.stateLocked(true)
+	peers := g.livePeersLocked(0)
+	g.lock.Unlock()
+
+	for _, p := range peers {
+		p.send(&gossipFrame{State: state})
+	}
+
+	deadline := time.Now().Add(gossipLeaveTimeout)
+	for _, p := range peers {
+		p.flush(deadline)
+	}
+}
+
+func (g *Gossip) Stop() {
+	if !g.isStarted.Swap(false) {
+		return
+	}
+	g.cancel()
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	_ = g.listener.Close()
+	for conn := range g.inbound {
+		_ = conn.Close()
+	}
+	for _, p := range g.peers {
+		p.close()
+	}
+}
+
+// Push sends the state of this node to all live peers right away, instead of waiting for the next gossip round
+func (g *Gossip) Push() {
+	if !g.isStarted.Load() {
+		return
+	}
+
+	g.lock.Lock()
+	state := g.stateLocked(false)
+	peers := g.livePeersLocked(0)
+	g.lock.Unlock()
+
+	for _, p := range peers {
+		p.send(&gossipFrame{State: state})
+	}
+}
+
+// Nodes lists the live members, including this node
+func (g *Gossip) Nodes() ([]*livekit.Node, error) {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	nodes := []*livekit.Node{g.currentNode.Clone()}
+	for _, m := range g.members {
+		if !g.isAliveLocked(m) {
+			continue
+		}
+		n := &livekit.Node{}
+		if err := proto.Unmarshal(m.Node, n); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+func (g *Gossip) Node(nodeID livekit.NodeID) (*livekit.Node, error) {
+	if nodeID == g.currentNode.NodeID() {
+		return g.currentNode.Clone(), nil
+	}
+
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	m, ok := g.members[nodeID]
+	if !ok || !g.isAliveLocked(m) {
+		return nil, ErrNotFound
+	}
+	n := &livekit.Node{}
+	if err := proto.Unmarshal(m.Node, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// RemoveDeadMembers forgets members that left or timed out, along with the rooms cleared long enough ago
+func (g *Gossip) RemoveDeadMembers() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for nodeID, m := range g.members {
+		if g.isAliveLocked(m) {
+			continue
+		}
+		g.removed[nodeID] = m.Heartbeat
+		delete(g.members, nodeID)
+		if p := g.peers[m.Address]; p != nil {
+			p.close()
+			delete(g.peers, m.Address)
+		}
+	}
+
+	now := time.Now()
+	tombstoneVersion := now.Add(-gossipRoomTombstoneTTL).UnixNano()
+	for roomName, route := range g.rooms {
+		if route.Cleared && route.Version < tombstoneVersion {
+			delete(g.rooms, roomName)
+		}
+	}
+	for key, l := range g.locks {
+		if now.After(l.expiresAt) {
+			delete(g.locks, key)
+		}
+	}
+
+	g.entryLock.Lock()
+	for key, e := range g.entries {
+		if e.isExpired(now) || (e.Deleted && e.Version < tombstoneVersion) {
+			delete(g.entries, key)
+		}
+	}
+	g.entryLock.Unlock()
+}
+
+func (g *Gossip) RoomNode(roomName livekit.RoomName) (livekit.NodeID, bool) {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	route, ok := g.rooms[roomName]
+	if !ok || route.Cleared {
+		return "", false
+	}
+	return route.NodeID, true
+}
+
+func (g *Gossip) SetRoomNode(roomName livekit.RoomName, nodeID livekit.NodeID) {
+	g.setRoute(roomName, nodeID, false)
+}
+
+func (g *Gossip) ClearRoom(roomName livekit.RoomName) {
+	g.setRoute(roomName, "", true)
+}
+
+func (g *Gossip) setRoute(roomName livekit.RoomName, nodeID livekit.NodeID, cleared bool) {
+	g.lock.Lock()
+	// never go back in time when the clock of another node is ahead
+	version := max(time.Now().UnixNano(), g.rooms[roomName].Version+1)
+	g.rooms[roomName] = gossipRoute{NodeID: nodeID, Version: version, Cleared: cleared}
+	g.lock.Unlock()
+
+	// assignments are needed by other nodes right away
+	g.Push()
+}
+
+// SetEntry replicates a value to all nodes, it expires at expiresAt unless that is zero
+func (g *Gossip) SetEntry(key GossipKey, value []byte, expiresAt time.Time) {
+	e := gossipEntry{Value: value}
+	if !expiresAt.IsZero() {
+		e.ExpiresAt = expiresAt.UnixNano()
+	}
+
+	g.entryLock.Lock()
+	changes := g.writeEntriesLocked(map[GossipKey]gossipEntry{key: e})
+	g.entryLock.Unlock()
+
+	g.pushEntries(changes)
+}
+
+// DeleteEntries removes the entries matching a filter from all nodes
+func (g *Gossip) DeleteEntries(match func(key GossipKey) bool) {
+	g.entryLock.Lock()
+	changes := make(map[GossipKey]gossipEntry)
+	for key, e := range g.entries {
+		if !e.Deleted && match(key) {
+			changes[key] = gossipEntry{Deleted: true}
+		}
+	}
+	changes = g.writeEntriesLocked(changes)
+	g.entryLock.Unlock()
+
+	g.pushEntries(changes)
+}
+
+// writeEntriesLocked versions and applies changes made on this node
+func (g *Gossip) writeEntriesLocked(changes map[GossipKey]gossipEntry) map[GossipKey]gossipEntry {
+	now := time.Now().UnixNano()
+	for key, e := range changes {
+		// never go back in time when the clock of another node is ahead
+		e.Version = max(now, g.entries[key].Version+1)
+		e.NodeID = g.currentNode.NodeID()
+		changes[key] = e
+		g.entries[key] = e
+		g.notifyEntryLocked(key, e)
+	}
+	return changes
+}
+
+func (g *Gossip) mergeEntries(entries map[GossipKey]gossipEntry) {
+	now := time.Now()
+
+	g.entryLock.Lock()
+	defer g.entryLock.Unlock()
+
+	for key, e := range entries {
+		if existing, ok := g.entries[key]; e.isExpired(now) || (ok && !e.isNewerThan(existing)) {
+			continue
+		}
+		g.entries[key] = e
+		g.notifyEntryLocked(key, e)
+	}
+}
+
+func (g *Gossip) notifyEntryLocked(key GossipKey, e gossipEntry) {
+	if g.onEntry == nil {
+		return
+	}
+	var expiresAt time.Time
+	if e.ExpiresAt != 0 {
+		expiresAt = time.Unix(0, e.ExpiresAt)
+	}
+	g.onEntry(key, e.Value, expiresAt, e.Deleted)
+}
+
+// pushEntries sends changes to all live peers right away, the next gossip rounds repair what gets lost
+func (g *Gossip) pushEntries(entries map[GossipKey]gossipEntry) {
+	if len(entries) == 0 || !g.isStarted.Load() {
+		return
+	}
+
+	g.lock.Lock()
+	peers := g.livePeersLocked(0)
+	g.lock.Unlock()
+
+	frame := &gossipFrame{State: &gossipState{Entries: entries}}
+	for _, p := range peers {
+		p.send(frame)
+	}
+}
+
+// SetQueueChannels sets the psrpc channels this node has queue subscribers on, and tells peers right away
+func (g *Gossip) SetQueueChannels(channels []string) {
+	channels = slices.Clone(channels)
+	slices.Sort(channels)
+
+	g.lock.Lock()
+	g.queues = channels
+	g.heartbeat++
+	g.lock.Unlock()
+
+	g.Push()
+}
+
+// QueueNode picks the node delivering a message to the queue subscribers of a channel, among the nodes
+// subscribed to it. Nodes agree on the pick as long as they agree on the members. When no node is known
+// to be subscribed, it picks among all live nodes.
+func (g *Gossip) QueueNode(channel string) livekit.NodeID {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	var nodeIDs []livekit.NodeID
+	if _, ok := slices.BinarySearch(g.queues, channel); ok {
+		nodeIDs = append(nodeIDs, g.currentNode.NodeID())
+	}
+	for nodeID, m := range g.members {
+		if _, ok := m.queues[channel]; ok && g.isAliveLocked(m) {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	if len(nodeIDs) == 0 {
+		nodeIDs = g.liveNodeIDsLocked()
+	}
+	return pickGossipNode(channel, nodeIDs)
+}
+
+// TryLock takes a lock for duration, it returns false while the lock is held with another token.
+// Locks are kept by the live node picked for the key, a lock may be lost when that node changes.
+func (g *Gossip) TryLock(ctx context.Context, key string, token string, duration time.Duration) (bool, error) {
+	return g.requestLock(ctx, &gossipLockRequest{Key: key, Token: token, Duration: duration})
+}
+
+// Unlock releases a lock, it returns false when the lock is not held with token
+func (g *Gossip) Unlock(ctx context.Context, key string, token string) (bool, error) {
+	return g.requestLock(ctx, &gossipLockRequest{Key: key, Token: token})
+}
+
+func (g *Gossip) requestLock(ctx context.Context, req *gossipLockRequest) (bool, error) {
+	req.NodeID = g.currentNode.NodeID()
+
+	g.lock.Lock()
+	owner := pickGossipNode(req.Key, g.liveNodeIDsLocked())
+	if owner == req.NodeID {
+		g.lock.Unlock()
+		return g.handleLock(req), nil
+	}
+	req.ID = g.lockRequests.Inc()
+	res := make(chan bool, 1)
+	g.lockWaiters[req.ID] = res
+	peer := g.peerLocked(g.members[owner].Address)
+	g.lock.Unlock()
+
+	defer func() {
+		g.lock.Lock()
+		delete(g.lockWaiters, req.ID)
+		g.lock.Unlock()
+	}()
+
+	peer.send(&gossipFrame{LockRequest: req})
+
+	timer := time.NewTimer(gossipLockTimeout)
+	defer timer.Stop()
+	select {
+	case ok := <-res:
+		return ok, nil
+	case <-timer.C:
+		return false, errGossipLockTimeout
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// handleLock takes or releases a lock kept by this node
+func (g *Gossip) handleLock(req *gossipLockRequest) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	now := time.Now()
+	l, held := g.locks[req.Key]
+	held = held && now.Before(l.expiresAt)
+	if req.Duration == 0 {
+		if !held || l.token != req.Token {
+			return false
+		}
+		delete(g.locks, req.Key)
+		return true
+	}
+
+	if held && l.token != req.Token {
+		return false
+	}
+	g.locks[req.Key] = gossipLock{token: req.Token, expiresAt: now.Add(req.Duration)}
+	return true
+}
+
+func (g *Gossip) respondLock(req *gossipLockRequest) {
+	ok := g.handleLock(req)
+
+	g.lock.Lock()
+	m := g.members[req.NodeID]
+	if m == nil || m.Address == "" {
+		// the requester times out
+		g.lock.Unlock()
+		return
+	}
+	peer := g.peerLocked(m.Address)
+	g.lock.Unlock()
+
+	peer.send(&gossipFrame{LockResponse: &gossipLockResponse{ID: req.ID, OK: ok}})
+}
+
+func (g *Gossip) publish(p *gossipPublish) {
+	g.lock.Lock()
+	peers := g.livePeersLocked(0)
+	g.lock.Unlock()
+
+	frame := &gossipFrame{Publish: p}
+	for _, peer := range peers {
+		peer.send(frame)
+	}
+}
+
+func (g *Gossip) gossipWorker() {
+	ticker := time.NewTicker(g.conf.GossipInterval)
+	defer ticker.Stop()
+
+	for {
+		g.lock.Lock()
+		g.heartbeat++
+		state := g.stateLocked(false)
+		peers := g.livePeersLocked(gossipFanout)
+		// keep knocking on seeds until they are members, that is how nodes join
+		for _, seed := range g.conf.Seeds {
+			if seed != g.address && !g.isMemberAddressLocked(seed) {
+				peers = append(peers, g.peerLocked(seed))
+			}
+		}
+		g.lock.Unlock()
+
+		for _, p := range peers {
+			p.send(&gossipFrame{State: state})
+		}
+
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-ticker.C:
+			g.RemoveDeadMembers()
+		}
+	}
+}
+
+func (g *Gossip) acceptWorker() {
+	for {
+		conn, err := g.listener.Accept()
+		if err != nil {
+			if g.ctx.Err() == nil {
+				logger.Warnw("gossip listener failed", err)
+			}
+			return
+		}
+
+		g.lock.Lock()
+		g.inbound[conn] = struct{}{}
+		g.lock.Unlock()
+
+		go g.readWorker(conn)
+	}
+}
+
+func (g *Gossip) readWorker(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+		g.lock.Lock()
+		delete(g.inbound, conn)
+		g.lock.Unlock()
+	}()
+
+	nonce := make([]byte, gossipNonceSize)
+	if _, err := cryptorand.Read(nonce); err != nil {
+		return
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(gossipWriteTimeout))
+	if _, err := conn.Write(nonce); err != nil {
+		return
+	}
+
+	r := newGossipFrameReader(conn, []byte(g.conf.Key), nonce)
+	for {
+		frame, err := r.read()
+		if err != nil {
+			if errors.Is(err, errGossipInvalidTag) || errors.Is(err, errGossipFrameSize) {
+				logger.Warnw("rejecting gossip connection", err, "remote", conn.RemoteAddr().String())
+			}
+			return
+		}
+
+		switch {
+		case frame.State != nil:
+			g.merge(frame.State)
+			g.mergeEntries(frame.State.Entries)
+		case frame.LockRequest != nil:
+			g.respondLock(frame.LockRequest)
+		case frame.LockResponse != nil:
+			g.lock.RLock()
+			res := g.lockWaiters[frame.LockResponse.ID]
+			g.lock.RUnlock()
+			if res != nil {
+				res <- frame.LockResponse.OK
+			}
+		case frame.Publish != nil:
+			g.lock.RLock()
+			onPublish := g.onPublish
+			g.lock.RUnlock()
+			if onPublish != nil {
+				onPublish(frame.Publish)
+			}
+		}
+	}
+}
+
+func (g *Gossip) merge(state *gossipState) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	now := time.Now()
+	for _, m := range state.Members {
+		if m.NodeID == g.currentNode.NodeID() {
+			continue
+		}
+		if heartbeat, ok := g.removed[m.NodeID]; ok && m.Heartbeat <= heartbeat {
+			continue
+		}
+
+		existing, ok := g.members[m.NodeID]
+		if ok && m.Heartbeat <= existing.Heartbeat {
+			continue
+		}
+		if !ok && !m.Left {
+			logger.Infow("node joined cluster", "nodeID", m.NodeID, "address", m.Address)
+		} else if ok && m.Left && !existing.Left {
+			logger.Infow("node left cluster", "nodeID", m.NodeID, "address", m.Address)
+		}
+		queues := make(map[string]struct{}, len(m.QueueChannels))
+		for _, channel := range m.QueueChannels {
+			queues[channel] = struct{}{}
+		}
+		g.members[m.NodeID] = &gossipMember{GossipMember: m, seenAt: now, queues: queues}
+	}
+
+	for roomName, route := range state.Rooms {
+		existing, ok := g.rooms[roomName]
+		if !ok || route.Version > existing.Version || (route.Version == existing.Version && route.NodeID > existing.NodeID) {
+			g.rooms[roomName] = route
+		}
+	}
+}
+
+func (g *Gossip) stateLocked(left bool) *gossipState {
+	self := GossipMember{
+		NodeID:        g.currentNode.NodeID(),
+		Address:       g.address,
+		Heartbeat:     g.heartbeat,
+		Left:          left,
+		QueueChannels: g.queues,
+	}
+	self.Node, _ = proto.Marshal(g.currentNode.Clone())
+
+	state := &gossipState{
+		Members: []GossipMember{self},
+		Rooms:   make(map[livekit.RoomName]gossipRoute, len(g.rooms)),
+	}
+	for _, m := range g.members {
+		state.Members = append(state.Members, m.GossipMember)
+	}
+	for roomName, route := range g.rooms {
+		state.Rooms[roomName] = route
+	}
+
+	g.entryLock.Lock()
+	state.Entries = make(map[GossipKey]gossipEntry, len(g.entries))
+	for key, e := range g.entries {
+		state.Entries[key] = e
+	}
+	g.entryLock.Unlock()
+	return state
+}
+
+func (g *Gossip) isAliveLocked(m *gossipMember) bool {
+	return !m.Left && time.Since(m.seenAt) < g.conf.NodeTimeout
+}
+
+func (g *Gossip) isMemberAddressLocked(address string) bool {
+	for _, m := range g.members {
+		if m.Address == address && g.isAliveLocked(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// liveNodeIDsLocked returns the IDs of live members, including this node
+func (g *Gossip) liveNodeIDsLocked() []livekit.NodeID {
+	nodeIDs := []livekit.NodeID{g.currentNode.NodeID()}
+	for nodeID, m := range g.members {
+		if g.isAliveLocked(m) {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+	}
+	return nodeIDs
+}
+
+// livePeersLocked returns connections to live members, up to n picked at random when n > 0
+func (g *Gossip) livePeersLocked(n int) []*gossipPeer {
+	var peers []*gossipPeer
+	for _, m := range g.members {
+		if g.isAliveLocked(m) && m.Address != "" {
+			peers = append(peers, g.peerLocked(m.Address))
+		}
+	}
+	if n > 0 && len(peers) > n {
+		rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+		peers = peers[:n]
+	}
+	return peers
+}
+
+func (g *Gossip) peerLocked(address string) *gossipPeer {
+	p := g.peers[address]
+	if p == nil {
+		p = newGossipPeer(g.ctx, address, []byte(g.conf.Key))
+		g.peers[address] = p
+	}
+	return p
+}
+
+// pickGossipNode picks a node for a key by rendezvous hashing, the pick only changes for keys of nodes coming or going
+func pickGossipNode(key string, nodeIDs []livekit.NodeID) livekit.NodeID {
+	var picked livekit.NodeID
+	var best uint64
+	for _, nodeID := range nodeIDs {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		_, _ = h.Write([]byte(nodeID))
+		if sum := h.Sum64(); picked == "" || sum > best || (sum == best && nodeID > picked) {
+			picked, best = nodeID, sum
+		}
+	}
+	return picked
+}
+
+// ---------------------------------------------------------------
+
+// gossipPeer is the outgoing connection to a node, frames are sent in order and dropped while it is unreachable
+type gossipPeer struct {
+	address string
+	key     []byte
+	frames  chan *gossipFrame
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+func newGossipPeer(ctx context.Context, address string, key []byte) *gossipPeer {
+	p := &gossipPeer{
+		address: address,
+		key:     key,
+		frames:  make(chan *gossipFrame, gossipPeerQueueSize),
+	}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	go p.writeWorker()
+	return p
+}
+
+func (p *gossipPeer) send(frame *gossipFrame) {
+	select {
+	case p.frames <- frame:
+	default:
+		logger.Warnw("gossip peer queue full, dropping frame", nil, "address", p.address)
+	}
+}
+
+// flush waits for queued frames to be written
+func (p *gossipPeer) flush(deadline time.Time) {
+	for len(p.frames) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (p *gossipPeer) close() {
+	p.cancel()
+}
+
+func (p *gossipPeer) writeWorker() {
+	for p.ctx.Err() == nil {
+		dialer := net.Dialer{Timeout: gossipDialTimeout}
+		conn, err := dialer.DialContext(p.ctx, "tcp", p.address)
+		if err != nil {
+			logger.Debugw("could not connect to gossip peer", "address", p.address, "error", err)
+			// frames are not worth keeping for an unreachable node
+			for len(p.frames) > 0 {
+				<-p.frames
+			}
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-time.After(gossipRedialDelay):
+			}
+			continue
+		}
+
+		p.write(conn)
+		_ = conn.Close()
+	}
+}
+
+func (p *gossipPeer) write(conn net.Conn) {
+	nonce := make([]byte, gossipNonceSize)
+	_ = conn.SetReadDeadline(time.Now().Add(gossipDialTimeout))
+	if _, err := io.ReadFull(conn, nonce); err != nil {
+		logger.Debugw("could not read gossip peer nonce", "address", p.address, "error", err)
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	w := bufio.NewWriter(conn)
+	fw := newGossipFrameWriter(w, p.key, nonce)
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case frame := <-p.frames:
+			_ = conn.SetWriteDeadline(time.Now().Add(gossipWriteTimeout))
+			if err := fw.write(frame); err != nil {
+				logger.Debugw("could not write to gossip peer", "address", p.address, "error", err)
+				return
+			}
+			// batch frames queued meanwhile into the same write
+			if len(p.frames) == 0 {
+				if err := w.Flush(); err != nil {
+					logger.Debugw("could not write to gossip peer", "address", p.address, "error", err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// ---------------------------------------------------------------
+
+// gossipFrameAuth tags the frames of a connection
+type gossipFrameAuth struct {
+	key   []byte
+	nonce []byte
+	seq   uint64
+}
+
+func (a *gossipFrameAuth) tag(payload []byte) []byte {
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], a.seq)
+	a.seq++
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write(a.nonce)
+	mac.Write(seq[:])
+	mac.Write(payload)
+	return mac.Sum(nil)[:gossipTagSize]
+}
+
+type gossipFrameWriter struct {
+	w    io.Writer
+	auth gossipFrameAuth
+	buf  bytes.Buffer
+}
+
+func newGossipFrameWriter(w io.Writer, key []byte, nonce []byte) *gossipFrameWriter {
+	return &gossipFrameWriter{w: w, auth: gossipFrameAuth{key: key, nonce: nonce}}
+}
+
+func (fw *gossipFrameWriter) write(frame *gossipFrame) error {
+	// each frame is encoded on its own, so it can be verified before it is decoded
+	fw.buf.Reset()
+	fw.buf.Write(make([]byte, 4))
+	if err := gob.NewEncoder(&fw.buf).Encode(frame); err != nil {
+		return err
+	}
+	b := fw.buf.Bytes()
+	payload := b[4:]
+	if len(payload) > gossipMaxFrameSize {
+		return errGossipFrameSize
+	}
+	binary.BigEndian.PutUint32(b, uint32(len(payload)))
+	fw.buf.Write(fw.auth.tag(payload))
+	_, err := fw.w.Write(fw.buf.Bytes())
+	return err
+}
+
+type gossipFrameReader struct {
+	r    *bufio.Reader
+	auth gossipFrameAuth
+}
+
+func newGossipFrameReader(r io.Reader, key []byte, nonce []byte) *gossipFrameReader {
+	return &gossipFrameReader{r: bufio.NewReader(r), auth: gossipFrameAuth{key: key, nonce: nonce}}
+}
+
+func (fr *gossipFrameReader) read() (*gossipFrame, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(fr.r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > gossipMaxFrameSize {
+		return nil, errGossipFrameSize
+	}
+	b := make([]byte, int(n)+gossipTagSize)
+	if _, err := io.ReadFull(fr.r, b); err != nil {
+		return nil, err
+	}
+	payload, tag := b[:n], b[n:]
+	if !hmac.Equal(tag, fr.auth.tag(payload)) {
+		return nil, errGossipInvalidTag
+	}
+
+	frame := &gossipFrame{}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
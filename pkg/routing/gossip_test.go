@@ -0,0 +1,379 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+	"github.com/livekit/psrpc/pkg/client"
+	"github.com/livekit/psrpc/pkg/info"
+	"github.com/livekit/psrpc/pkg/rand"
+	"github.com/livekit/psrpc/pkg/server"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+)
+
+const gossipTestTimeout = 3 * time.Second
+
+func newTestGossip(t *testing.T, nodeID livekit.NodeID, seeds ...string) *routing.Gossip {
+	return newTestGossipWithKey(t, nodeID, "cluster secret", seeds...)
+}
+
+func newTestGossipWithKey(t *testing.T, nodeID livekit.NodeID, key string, seeds ...string) *routing.Gossip {
+	node, err := routing.NewLocalNodeFromNodeProto(&livekit.Node{
+		Id:    string(nodeID),
+		Ip:    "127.0.0.1",
+		State: livekit.NodeState_SERVING,
+	})
+	require.NoError(t, err)
+
+	g, err := routing.NewGossip(config.ClusterConfig{
+		BindAddress:    "127.0.0.1:0",
+		Seeds:          seeds,
+		GossipInterval: 20 * time.Millisecond,
+		NodeTimeout:    300 * time.Millisecond,
+		Key:            key,
+	}, node)
+	require.NoError(t, err)
+	require.NoError(t, g.Start())
+	t.Cleanup(g.Stop)
+	return g
+}
+
+func requireNodes(t *testing.T, g *routing.Gossip, expected ...livekit.NodeID) {
+	slices.Sort(expected)
+	require.Eventually(t, func() bool {
+		nodes, err := g.Nodes()
+		require.NoError(t, err)
+		var ids []livekit.NodeID
+		for _, n := range nodes {
+			ids = append(ids, livekit.NodeID(n.Id))
+		}
+		slices.Sort(ids)
+		return slices.Equal(expected, ids)
+	}, gossipTestTimeout, 10*time.Millisecond)
+}
+
+func TestGossip(t *testing.T) {
+	t.Run("nodes join through a seed", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+		g2 := newTestGossip(t, "ND_2", g1.Address())
+		g3 := newTestGossip(t, "ND_3", g1.Address())
+
+		requireNodes(t, g1, "ND_1", "ND_2", "ND_3")
+		requireNodes(t, g2, "ND_2", "ND_1", "ND_3")
+		requireNodes(t, g3, "ND_3", "ND_1", "ND_2")
+
+		node, err := g3.Node("ND_2")
+		require.NoError(t, err)
+		require.Equal(t, livekit.NodeState_SERVING, node.State)
+	})
+
+	t.Run("cluster key is required", func(t *testing.T) {
+		node, err := routing.NewLocalNodeFromNodeProto(&livekit.Node{Id: "ND_1", Ip: "127.0.0.1"})
+		require.NoError(t, err)
+		_, err = routing.NewGossip(config.ClusterConfig{BindAddress: "127.0.0.1:0"}, node)
+		require.ErrorIs(t, err, routing.ErrClusterKeyNotSet)
+	})
+
+	t.Run("nodes with another key cannot join", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+		g2 := newTestGossipWithKey(t, "ND_2", "other secret", g1.Address())
+		g3 := newTestGossip(t, "ND_3", g1.Address())
+
+		requireNodes(t, g3, "ND_3", "ND_1")
+		time.Sleep(100 * time.Millisecond)
+		requireNodes(t, g1, "ND_1", "ND_3")
+		requireNodes(t, g2, "ND_2")
+	})
+
+	t.Run("unauthenticated frames are rejected", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+
+		conn, err := net.Dial("tcp", g1.Address())
+		require.NoError(t, err)
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(gossipTestTimeout))
+
+		nonce := make([]byte, 16)
+		_, err = io.ReadFull(conn, nonce)
+		require.NoError(t, err)
+
+		// a frame with a bad tag closes the connection
+		frame := []byte{0, 0, 0, 4, 1, 2, 3, 4}
+		frame = append(frame, make([]byte, 16)...)
+		_, err = conn.Write(frame)
+		require.NoError(t, err)
+		_, err = conn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("left and dead nodes are removed", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+		g2 := newTestGossip(t, "ND_2", g1.Address())
+		g3 := newTestGossip(t, "ND_3", g1.Address())
+		requireNodes(t, g1, "ND_1", "ND_2", "ND_3")
+
+		g3.Leave()
+		g3.Stop()
+		requireNodes(t, g1, "ND_1", "ND_2")
+
+		// stopped without leaving, times out
+		g2.Stop()
+		requireNodes(t, g1, "ND_1")
+
+		_, err := g1.Node("ND_2")
+		require.ErrorIs(t, err, routing.ErrNotFound)
+	})
+
+	t.Run("room to node map is replicated", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+		g2 := newTestGossip(t, "ND_2", g1.Address())
+		g3 := newTestGossip(t, "ND_3", g1.Address())
+		requireNodes(t, g2, "ND_2", "ND_1", "ND_3")
+
+		g2.SetRoomNode("room", "ND_3")
+		for _, g := range []*routing.Gossip{g1, g3} {
+			require.Eventually(t, func() bool {
+				nodeID, ok := g.RoomNode("room")
+				return ok && nodeID == "ND_3"
+			}, gossipTestTimeout, 10*time.Millisecond)
+		}
+
+		// the latest assignment wins
+		g3.SetRoomNode("room", "ND_1")
+		require.Eventually(t, func() bool {
+			nodeID, ok := g2.RoomNode("room")
+			return ok && nodeID == "ND_1"
+		}, gossipTestTimeout, 10*time.Millisecond)
+
+		g1.ClearRoom("room")
+		for _, g := range []*routing.Gossip{g2, g3} {
+			require.Eventually(t, func() bool {
+				_, ok := g.RoomNode("room")
+				return !ok
+			}, gossipTestTimeout, 10*time.Millisecond)
+		}
+	})
+
+	t.Run("message bus delivers across nodes", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+		g2 := newTestGossip(t, "ND_2", g1.Address())
+
+		kps1, err := rpc.NewKeepalivePubSub(rpc.ClientParams{Bus: routing.NewGossipMessageBus(g1)})
+		require.NoError(t, err)
+		kps2, err := rpc.NewKeepalivePubSub(rpc.ClientParams{Bus: routing.NewGossipMessageBus(g2)})
+		require.NoError(t, err)
+
+		requireNodes(t, g1, "ND_1", "ND_2")
+		requireNodes(t, g2, "ND_2", "ND_1")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sub1, err := kps1.SubscribePing(ctx, "ND_2")
+		require.NoError(t, err)
+		sub2, err := kps2.SubscribePing(ctx, "ND_2")
+		require.NoError(t, err)
+
+		kps1.PublishPing(ctx, "ND_2", &rpc.KeepalivePing{Timestamp: 42})
+		for _, sub := range []<-chan *rpc.KeepalivePing{sub1.Channel(), sub2.Channel()} {
+			select {
+			case ping := <-sub:
+				require.Equal(t, int64(42), ping.Timestamp)
+			case <-time.After(gossipTestTimeout):
+				require.Fail(t, "ping not delivered")
+			}
+		}
+	})
+	t.Run("entries are replicated", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+		g2 := newTestGossip(t, "ND_2", g1.Address())
+		requireNodes(t, g2, "ND_2", "ND_1")
+
+		var lock sync.Mutex
+		values := make(map[routing.GossipKey]string)
+		g2.OnEntry(func(key routing.GossipKey, value []byte, _ time.Time, deleted bool) {
+			lock.Lock()
+			defer lock.Unlock()
+			if deleted {
+				delete(values, key)
+			} else {
+				values[key] = string(value)
+			}
+		})
+		requireValues := func(expected map[routing.GossipKey]string) {
+			require.Eventually(t, func() bool {
+				lock.Lock()
+				defer lock.Unlock()
+				if len(values) != len(expected) {
+					return false
+				}
+				for key, value := range expected {
+					if values[key] != value {
+						return false
+					}
+				}
+				return true
+			}, gossipTestTimeout, 10*time.Millisecond)
+		}
+
+		k1 := routing.GossipKey{Kind: "participant", Room: "room", ID: "p1"}
+		k2 := routing.GossipKey{Kind: "participant", Room: "room", ID: "p2"}
+		k3 := routing.GossipKey{Kind: "participant", Room: "other", ID: "p1"}
+		g1.SetEntry(k1, []byte("a"), time.Time{})
+		g1.SetEntry(k2, []byte("b"), time.Time{})
+		g1.SetEntry(k3, []byte("c"), time.Time{})
+		requireValues(map[routing.GossipKey]string{k1: "a", k2: "b", k3: "c"})
+
+		// the latest write wins
+		g2.SetEntry(k1, []byte("d"), time.Time{})
+		g1.DeleteEntries(func(key routing.GossipKey) bool {
+			return key.Room == "other"
+		})
+		requireValues(map[routing.GossipKey]string{k1: "d", k2: "b"})
+
+		// a node joining later learns entries through gossip
+		g3 := newTestGossip(t, "ND_3", g1.Address())
+		var joined sync.Map
+		g3.OnEntry(func(key routing.GossipKey, value []byte, _ time.Time, deleted bool) {
+			if !deleted {
+				joined.Store(key, string(value))
+			}
+		})
+		require.Eventually(t, func() bool {
+			value, ok := joined.Load(k1)
+			return ok && value == "d"
+		}, gossipTestTimeout, 10*time.Millisecond)
+	})
+
+	t.Run("locks are exclusive across nodes", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+		g2 := newTestGossip(t, "ND_2", g1.Address())
+		g3 := newTestGossip(t, "ND_3", g1.Address())
+		for _, g := range []*routing.Gossip{g1, g2, g3} {
+			requireNodes(t, g, "ND_1", "ND_2", "ND_3")
+		}
+
+		ctx := context.Background()
+		for i, key := range []string{"room-a", "room-b", "room-c", "room-d"} {
+			holder, other := []*routing.Gossip{g1, g2, g3}[i%3], []*routing.Gossip{g1, g2, g3}[(i+1)%3]
+
+			ok, err := holder.TryLock(ctx, key, "token1", time.Minute)
+			require.NoError(t, err)
+			require.True(t, ok)
+			ok, err = other.TryLock(ctx, key, "token2", time.Minute)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			ok, err = other.Unlock(ctx, key, "token2")
+			require.NoError(t, err)
+			require.False(t, ok)
+			ok, err = holder.Unlock(ctx, key, "token1")
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = other.TryLock(ctx, key, "token2", time.Minute)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+
+		// expired locks can be taken again
+		ok, err := g1.TryLock(ctx, "room-e", "token1", 50*time.Millisecond)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Eventually(t, func() bool {
+			ok, err := g2.TryLock(ctx, "room-e", "token2", time.Minute)
+			return err == nil && ok
+		}, gossipTestTimeout, 10*time.Millisecond)
+	})
+
+	t.Run("queue messages are delivered once", func(t *testing.T) {
+		g1 := newTestGossip(t, "ND_1")
+		g2 := newTestGossip(t, "ND_2", g1.Address())
+		g3 := newTestGossip(t, "ND_3", g1.Address())
+		for _, g := range []*routing.Gossip{g1, g2, g3} {
+			requireNodes(t, g, "ND_1", "ND_2", "ND_3")
+		}
+
+		var lock sync.Mutex
+		handled := make(map[string][]livekit.NodeID)
+		register := func(g *routing.Gossip, topic string) {
+			sd := &info.ServiceDefinition{Name: "GossipTest", ID: rand.NewServerID()}
+			sd.RegisterMethod("Handle", false, false, false, true)
+			srv := server.NewRPCServer(sd, routing.NewGossipMessageBus(g))
+			t.Cleanup(func() { srv.Close(true) })
+			require.NoError(t, server.RegisterHandler(srv, "Handle", []string{topic}, func(_ context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				handled[req.Value] = append(handled[req.Value], g.NodeID())
+				return req, nil
+			}, nil))
+		}
+		register(g1, "shared")
+		register(g2, "shared")
+		register(g2, "only2")
+
+		sd := &info.ServiceDefinition{Name: "GossipTest", ID: rand.NewClientID()}
+		sd.RegisterMethod("Handle", false, false, false, true)
+		c, err := client.NewRPCClient(sd, routing.NewGossipMessageBus(g3))
+		require.NoError(t, err)
+		t.Cleanup(c.Close)
+
+		request := func(topic, value string) error {
+			_, err := client.RequestSingle[*wrapperspb.StringValue](context.Background(), c, "Handle", []string{topic}, wrapperspb.String(value), psrpc.WithRequestTimeout(200*time.Millisecond))
+			return err
+		}
+
+		// the node without subscribers never gets it, once subscriptions are known
+		i := 0
+		require.Eventually(t, func() bool {
+			i++
+			return request("only2", "probe"+strconv.Itoa(i)) == nil
+		}, gossipTestTimeout, 10*time.Millisecond)
+		require.NoError(t, request("only2", "a"))
+
+		require.Eventually(t, func() bool {
+			i++
+			return request("shared", "probe"+strconv.Itoa(i)) == nil
+		}, gossipTestTimeout, 10*time.Millisecond)
+		require.NoError(t, request("shared", "b"))
+		require.NoError(t, request("shared", "c"))
+
+		// give duplicates time to show up
+		time.Sleep(100 * time.Millisecond)
+		lock.Lock()
+		defer lock.Unlock()
+		require.Equal(t, []livekit.NodeID{"ND_2"}, handled["a"])
+		require.Len(t, handled["b"], 1)
+		require.Len(t, handled["c"], 1)
+		for value, nodeIDs := range handled {
+			require.Len(t, nodeIDs, 1, value)
+		}
+	})
+}
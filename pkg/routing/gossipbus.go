@@ -0,0 +1,144 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/psrpc"
+	"github.com/livekit/psrpc/testutils"
+)
+
+// gossipMessageBus delivers psrpc messages across a gossip cluster. A published message is sent to every live node,
+// which delivers it to its subscribers. A message for queue subscribers is delivered on one node only, picked by
+// Gossip.QueueNode among the nodes advertising queue subscribers on the channel.
+type gossipMessageBus struct {
+	psrpc.MessageBus
+
+	gossip *Gossip
+
+	lock        sync.Mutex
+	queueCounts map[string]int
+}
+
+func NewGossipMessageBus(g *Gossip) psrpc.MessageBus {
+	b := &gossipMessageBus{
+		gossip:      g,
+		queueCounts: make(map[string]int),
+	}
+	// subscriptions are tracked to advertise queue channels, a channel is withdrawn once its last subscription
+	// closes. The readers psrpc buses return have an unexported method, so they cannot be wrapped outside of
+	// psrpc, and the interceptor bus of testutils is the only exported way to see a subscription close: its
+	// reader returns false once closed. Only the subscribe interceptor is set, the bus adds no test behavior.
+	b.MessageBus = testutils.NewTestBus(psrpc.NewLocalMessageBus(), testutils.WithSubscribeInterceptor(b.trackQueue))
+	g.OnPublish(b.deliver)
+	return b
+}
+
+// isQueueChannel tells channels of queue subscriptions, psrpc marks their server channel
+func isQueueChannel(channel psrpc.Channel) bool {
+	return strings.HasSuffix(channel.Server, ".Q")
+}
+
+func (b *gossipMessageBus) trackQueue(_ context.Context, channel psrpc.Channel, next testutils.ReadHandler) testutils.ReadHandler {
+	if !isQueueChannel(channel) {
+		return next
+	}
+	b.updateQueue(channel.Legacy, 1)
+
+	var once sync.Once
+	return func() ([]byte, bool) {
+		data, ok := next()
+		if !ok {
+			once.Do(func() {
+				b.updateQueue(channel.Legacy, -1)
+			})
+		}
+		return data, ok
+	}
+}
+
+func (b *gossipMessageBus) updateQueue(channel string, delta int) {
+	b.lock.Lock()
+	count := b.queueCounts[channel] + delta
+	if count > 0 {
+		b.queueCounts[channel] = count
+	} else {
+		delete(b.queueCounts, channel)
+	}
+	changed := count == 0 || count == delta
+	var channels []string
+	if changed {
+		channels = slices.Collect(maps.Keys(b.queueCounts))
+	}
+	b.lock.Unlock()
+
+	if changed {
+		b.gossip.SetQueueChannels(channels)
+	}
+}
+
+func (b *gossipMessageBus) Publish(ctx context.Context, channel psrpc.Channel, msg proto.Message) error {
+	var queueNodeID livekit.NodeID
+	if isQueueChannel(channel) {
+		queueNodeID = b.gossip.QueueNode(channel.Legacy)
+	}
+	if err := b.publishLocal(ctx, channel, msg, queueNodeID); err != nil {
+		return err
+	}
+
+	a, err := anypb.New(msg)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(a)
+	if err != nil {
+		return err
+	}
+	b.gossip.publish(&gossipPublish{Channel: channel, Message: data, QueueNodeID: queueNodeID})
+	return nil
+}
+
+func (b *gossipMessageBus) publishLocal(ctx context.Context, channel psrpc.Channel, msg proto.Message, queueNodeID livekit.NodeID) error {
+	if isQueueChannel(channel) && queueNodeID != b.gossip.NodeID() {
+		return nil
+	}
+	return b.MessageBus.Publish(ctx, channel, msg)
+}
+
+func (b *gossipMessageBus) deliver(p *gossipPublish) {
+	a := &anypb.Any{}
+	if err := proto.Unmarshal(p.Message, a); err != nil {
+		logger.Warnw("could not unmarshal gossip message", err, "channel", p.Channel.Legacy)
+		return
+	}
+	msg, err := a.UnmarshalNew()
+	if err != nil {
+		logger.Warnw("could not unmarshal gossip message", err, "channel", p.Channel.Legacy)
+		return
+	}
+	if err := b.publishLocal(context.Background(), p.Channel, msg, p.QueueNodeID); err != nil {
+		logger.Warnw("could not deliver gossip message", err, "channel", p.Channel.Legacy)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"context"
+
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/rpc"
+)
+
+var _ Router = (*GossipRouter)(nil)
+
+// GossipRouter routes across a cluster formed without Redis, node registration and the room to node map
+// are replicated by Gossip, and signaling goes through a psrpc bus from NewGossipMessageBus
+type GossipRouter struct {
+	*LocalRouter
+
+	gossip    *Gossip
+	kps       rpc.KeepalivePubSub
+	ctx       context.Context
+	isStarted atomic.Bool
+
+	cancel func()
+}
+
+func NewGossipRouter(lr *LocalRouter, g *Gossip, kps rpc.KeepalivePubSub) *GossipRouter {
+	gr := &GossipRouter{
+		LocalRouter: lr,
+		gossip:      g,
+		kps:         kps,
+	}
+	gr.ctx, gr.cancel = context.WithCancel(context.Background())
+	return gr
+}
+
+// RegisterNode joins the cluster, later calls send updated node stats right away
+func (r *GossipRouter) RegisterNode() error {
+	if err := r.gossip.Start(); err != nil {
+		return err
+	}
+	r.gossip.Push()
+	return nil
+}
+
+func (r *GossipRouter) UnregisterNode() error {
+	r.gossip.Leave()
+	return nil
+}
+
+func (r *GossipRouter) RemoveDeadNodes() error {
+	r.gossip.RemoveDeadMembers()
+	return nil
+}
+
+// GetNodeForRoom finds the node where the room is hosted at
+func (r *GossipRouter) GetNodeForRoom(_ context.Context, roomName livekit.RoomName) (*livekit.Node, error) {
+	nodeID, ok := r.gossip.RoomNode(roomName)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return r.GetNode(nodeID)
+}
+
+func (r *GossipRouter) SetNodeForRoom(_ context.Context, roomName livekit.RoomName, nodeID livekit.NodeID) error {
+	r.gossip.SetRoomNode(roomName, nodeID)
+	return nil
+}
+
+func (r *GossipRouter) ClearRoomState(_ context.Context, roomName livekit.RoomName) error {
+	r.gossip.ClearRoom(roomName)
+	return nil
+}
+
+func (r *GossipRouter) GetNode(nodeID livekit.NodeID) (*livekit.Node, error) {
+	return r.gossip.Node(nodeID)
+}
+
+func (r *GossipRouter) ListNodes() ([]*livekit.Node, error) {
+	return r.gossip.Nodes()
+}
+
+func (r *GossipRouter) CreateRoom(ctx context.Context, req *livekit.CreateRoomRequest) (res *livekit.Room, err error) {
+	rtcNode, err := r.GetNodeForRoom(ctx, livekit.RoomName(req.Name))
+	if err != nil {
+		return
+	}
+
+	return r.CreateRoomWithNodeID(ctx, req, livekit.NodeID(rtcNode.Id))
+}
+
+// StartParticipantSignal signal connection sets up paths to the RTC node, and starts to route messages to that message queue
+func (r *GossipRouter) StartParticipantSignal(ctx context.Context, roomName livekit.RoomName, pi ParticipantInit) (res StartParticipantSignalResults, err error) {
+	rtcNode, err := r.GetNodeForRoom(ctx, roomName)
+	if err != nil {
+		return
+	}
+
+	return r.StartParticipantSignalWithNodeID(ctx, roomName, pi, livekit.NodeID(rtcNode.Id))
+}
+
+func (r *GossipRouter) Start() error {
+	if r.isStarted.Swap(true) {
+		return nil
+	}
+	if err := r.gossip.Start(); err != nil {
+		return err
+	}
+
+	workerStarted := make(chan error)
+	go runStatsWorker(r.ctx, r.currentNode, r.kps)
+	go runKeepaliveWorker(r.ctx, r.currentNode, r.kps, r.RegisterNode, workerStarted)
+
+	// wait until worker is running
+	return <-workerStarted
+}
+
+func (r *GossipRouter) Drain() {
+	r.currentNode.SetState(livekit.NodeState_SHUTTING_DOWN)
+	if err := r.RegisterNode(); err != nil {
+		logger.Errorw("failed to mark as draining", err, "nodeID", r.currentNode.NodeID())
+	}
+}
+
+func (r *GossipRouter) Stop() {
+	if !r.isStarted.Swap(false) {
+		return
+	}
+	logger.Debugw("stopping GossipRouter")
+	_ = r.UnregisterNode()
+	r.cancel()
+	r.gossip.Stop()
+}
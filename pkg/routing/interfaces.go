@@ -162,6 +162,7 @@ func CreateRouter(
 	signalClient SignalClient,
 	roomManagerClient RoomManagerClient,
 	kps rpc.KeepalivePubSub,
+	g *Gossip,
 ) Router {
 	lr := NewLocalRouter(node, signalClient, roomManagerClient)
 
@@ -169,6 +170,10 @@ func CreateRouter(
 		return NewRedisRouter(lr, rc, kps)
 	}
 
+	if g != nil {
+		return NewGossipRouter(lr, g, kps)
+	}
+
 	// local routing and store
 	logger.Infow("using single-node routing")
 	return lr
@@ -190,6 +195,14 @@ type ParticipantInit struct {
 	SubscriberAllowPause *bool
 	DisableICELite       bool
 	CreateRoom           *livekit.CreateRoomRequest
+	// token claims not covered by the grants, they are sent along with the grants
+	PolicyClaim json.RawMessage
+}
+
+// sessionClaims is the claims of a participant's token sent to the RTC node
+type sessionClaims struct {
+	*auth.ClaimGrants
+	Policy json.RawMessage `json:"policy,omitempty"`
 }
 
 func (pi *ParticipantInit) MarshalLogObject(e zapcore.ObjectEncoder) error {
@@ -225,6 +238,11 @@ func (pi *ParticipantInit) ToStartSession(roomName livekit.RoomName, connectionI
 	if err != nil {
 		return nil, err
 	}
+	if len(pi.PolicyClaim) != 0 {
+		if claims, err = json.Marshal(&sessionClaims{ClaimGrants: pi.Grants, Policy: pi.PolicyClaim}); err != nil {
+			return nil, err
+		}
+	}
 
 	ss := &livekit.StartSession{
 		RoomName: string(roomName),
@@ -252,7 +270,8 @@ func (pi *ParticipantInit) ToStartSession(roomName livekit.RoomName, connectionI
 
 func ParticipantInitFromStartSession(ss *livekit.StartSession, region string) (*ParticipantInit, error) {
 	claims := &auth.ClaimGrants{}
-	if err := json.Unmarshal([]byte(ss.GrantsJson), claims); err != nil {
+	session := &sessionClaims{ClaimGrants: claims}
+	if err := json.Unmarshal([]byte(ss.GrantsJson), session); err != nil {
 		return nil, err
 	}
 
@@ -269,6 +288,7 @@ func ParticipantInitFromStartSession(ss *livekit.StartSession, region string) (*
 		ID:              livekit.ParticipantID(ss.ParticipantId),
 		DisableICELite:  ss.DisableIceLite,
 		CreateRoom:      ss.CreateRoom,
+		PolicyClaim:     session.Policy,
 	}
 	if ss.SubscriberAllowPause != nil {
 		subscriberAllowPause := *ss.SubscriberAllowPause
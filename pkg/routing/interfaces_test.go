@@ -0,0 +1,63 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/routing"
+)
+
+func TestParticipantInitStartSession(t *testing.T) {
+	pi := &routing.ParticipantInit{
+		Identity: "participant",
+		Grants: &auth.ClaimGrants{
+			Name:       "name",
+			Video:      &auth.VideoGrant{Room: "room", RoomJoin: true},
+			Attributes: map[string]string{"key": "value"},
+		},
+		CreateRoom: &livekit.CreateRoomRequest{Name: "room"},
+	}
+
+	t.Run("without policy claim", func(t *testing.T) {
+		ss, err := pi.ToStartSession("room", "CO_connection")
+		require.NoError(t, err)
+
+		restored, err := routing.ParticipantInitFromStartSession(ss, "region")
+		require.NoError(t, err)
+		require.Equal(t, pi.Grants.Video, restored.Grants.Video)
+		require.Equal(t, pi.Grants.Attributes, restored.Grants.Attributes)
+		require.Nil(t, restored.PolicyClaim)
+	})
+
+	t.Run("policy claim is sent with the grants", func(t *testing.T) {
+		withPolicy := *pi
+		withPolicy.PolicyClaim = json.RawMessage(`{"max_duration":"90m"}`)
+		ss, err := withPolicy.ToStartSession("room", "CO_connection")
+		require.NoError(t, err)
+
+		restored, err := routing.ParticipantInitFromStartSession(ss, "region")
+		require.NoError(t, err)
+		require.Equal(t, pi.Grants.Name, restored.Grants.Name)
+		require.Equal(t, pi.Grants.Video, restored.Grants.Video)
+		require.JSONEq(t, `{"max_duration":"90m"}`, string(restored.PolicyClaim))
+	})
+}
@@ -147,7 +147,7 @@ func (r *LocalRouter) statsWorker() {
 			return
 		}
 		// update every 10 seconds
-		<-time.After(statsUpdateInterval)
+		<-time.After(StatsUpdateInterval)
 		r.currentNode.UpdateNodeStats()
 	}
 }
@@ -28,6 +28,7 @@ type MessageChannel struct {
 	msgChan      chan proto.Message
 	onClose      func()
 	isClosed     bool
+	err          error
 	lock         sync.RWMutex
 }
 
@@ -75,12 +76,18 @@ func (m *MessageChannel) ReadChan() <-chan proto.Message {
 }
 
 func (m *MessageChannel) Close() {
+	m.CloseWithError(nil)
+}
+
+// CloseWithError closes the channel as its source failed
+func (m *MessageChannel) CloseWithError(err error) {
 	m.lock.Lock()
 	if m.isClosed {
 		m.lock.Unlock()
 		return
 	}
 	m.isClosed = true
+	m.err = err
 	close(m.msgChan)
 	m.lock.Unlock()
 
@@ -89,6 +96,13 @@ func (m *MessageChannel) Close() {
 	}
 }
 
+// Err returns the error the channel was closed with, nil if it is open or was closed normally
+func (m *MessageChannel) Err() error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.err
+}
+
 func (m *MessageChannel) ConnectionID() livekit.ConnectionID {
 	return m.connectionID
 }
@@ -15,9 +15,12 @@
 package routing_test
 
 import (
+	"errors"
 	"sync"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/livekit/protocol/livekit"
 
 	"github.com/livekit/livekit-server/pkg/routing"
@@ -48,3 +51,15 @@ func TestMessageChannel_WriteMessageClosed(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMessageChannel_CloseWithError(t *testing.T) {
+	m := routing.NewMessageChannel(livekit.ConnectionID("test"), routing.DefaultMessageChannelSize)
+	m.Close()
+	require.NoError(t, m.Err())
+
+	m = routing.NewMessageChannel(livekit.ConnectionID("test"), routing.DefaultMessageChannelSize)
+	err := errors.New("stream failed")
+	m.CloseWithError(err)
+	require.ErrorIs(t, m.Err(), err)
+	require.True(t, m.IsClosed())
+}
@@ -35,9 +35,11 @@ import (
 const (
 	// expire participant mappings after a day
 	participantMappingTTL = 24 * time.Hour
-	statsUpdateInterval   = 2 * time.Second
 	statsMaxDelaySeconds  = float64(30)
 
+	// StatsUpdateInterval is how often a node refreshes its stats
+	StatsUpdateInterval = 2 * time.Second
+
 	// hash of node_id => Node proto
 	NodesKey = "nodes"
 
@@ -205,14 +207,23 @@ func (r *RedisRouter) Stop() {
 
 // update node stats and cleanup
 func (r *RedisRouter) statsWorker() {
+	runStatsWorker(r.ctx, r.currentNode, r.kps)
+}
+
+func (r *RedisRouter) keepaliveWorker(startedChan chan error) {
+	runKeepaliveWorker(r.ctx, r.currentNode, r.kps, r.RegisterNode, startedChan)
+}
+
+// runStatsWorker pings the node through the message bus, stats are only updated when the bus delivers the pings
+func runStatsWorker(ctx context.Context, currentNode LocalNode, kps rpc.KeepalivePubSub) {
 	goroutineDumped := false
-	for r.ctx.Err() == nil {
+	for ctx.Err() == nil {
 		// update periodically
 		select {
-		case <-time.After(statsUpdateInterval):
-			r.kps.PublishPing(r.ctx, r.currentNode.NodeID(), &rpc.KeepalivePing{Timestamp: time.Now().Unix()})
+		case <-time.After(StatsUpdateInterval):
+			kps.PublishPing(ctx, currentNode.NodeID(), &rpc.KeepalivePing{Timestamp: time.Now().Unix()})
 
-			delaySeconds := r.currentNode.SecondsSinceNodeStatsUpdate()
+			delaySeconds := currentNode.SecondsSinceNodeStatsUpdate()
 			if delaySeconds > statsMaxDelaySeconds {
 				if !goroutineDumped {
 					goroutineDumped = true
@@ -225,14 +236,14 @@ func (r *RedisRouter) statsWorker() {
 			} else {
 				goroutineDumped = false
 			}
-		case <-r.ctx.Done():
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (r *RedisRouter) keepaliveWorker(startedChan chan error) {
-	pings, err := r.kps.SubscribePing(r.ctx, r.currentNode.NodeID())
+func runKeepaliveWorker(ctx context.Context, currentNode LocalNode, kps rpc.KeepalivePubSub, registerNode func() error, startedChan chan error) {
+	pings, err := kps.SubscribePing(ctx, currentNode.NodeID())
 	if err != nil {
 		startedChan <- err
 		return
@@ -240,17 +251,17 @@ func (r *RedisRouter) keepaliveWorker(startedChan chan error) {
 	close(startedChan)
 
 	for ping := range pings.Channel() {
-		if time.Since(time.Unix(ping.Timestamp, 0)) > statsUpdateInterval {
+		if time.Since(time.Unix(ping.Timestamp, 0)) > StatsUpdateInterval {
 			logger.Infow("keep alive too old, skipping", "timestamp", ping.Timestamp)
 			continue
 		}
 
-		if !r.currentNode.UpdateNodeStats() {
+		if !currentNode.UpdateNodeStats() {
 			continue
 		}
 
 		// TODO: check stats against config.Limit values
-		if err := r.RegisterNode(); err != nil {
+		if err := registerNode(); err != nil {
 			logger.Errorw("could not update node", err)
 		}
 	}
@@ -0,0 +1,250 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+const (
+	// a node's cost per track is only learnt once it forwards enough tracks to amortize its base load
+	capacityMinTracksToLearn = 10
+	capacityCostAlpha        = 0.2
+
+	// stats lag behind joins, admitted participants count against the node until stats catch up
+	capacityReservationWindow = 10 * time.Second
+)
+
+// TrackCost is the cost of forwarding a track on a node
+type TrackCost struct {
+	CPU            float64 // CPU cores
+	BytesInPerSec  float64
+	BytesOutPerSec float64
+	// false while the configured defaults are used
+	Learnt bool
+}
+
+// ParticipantCost is the load a participant is expected to add to the node hosting its room
+type ParticipantCost struct {
+	TracksIn       int
+	TracksOut      int
+	CPU            float64 // CPU cores
+	BytesInPerSec  float64
+	BytesOutPerSec float64
+}
+
+func (c ParticipantCost) add(o ParticipantCost) ParticipantCost {
+	return ParticipantCost{
+		TracksIn:       c.TracksIn + o.TracksIn,
+		TracksOut:      c.TracksOut + o.TracksOut,
+		CPU:            c.CPU + o.CPU,
+		BytesInPerSec:  c.BytesInPerSec + o.BytesInPerSec,
+		BytesOutPerSec: c.BytesOutPerSec + o.BytesOutPerSec,
+	}
+}
+
+// Admission is the outcome of checking a participant against a node, along with the model inputs it was based on
+type Admission struct {
+	NodeID      livekit.NodeID
+	TrackCost   TrackCost
+	Participant ParticipantCost
+	// predicted load of the node once the participant has joined
+	CPULoad     float64
+	BytesPerSec float64
+	Admitted    bool
+}
+
+// CapacityModel learns the cost of forwarding a track on each node from node stats,
+// and predicts whether a node has headroom for a participant
+type CapacityModel struct {
+	conf     config.AdmissionConfig
+	maxBytes float64
+
+	lock  sync.Mutex
+	nodes map[livekit.NodeID]*nodeCapacity
+}
+
+type nodeCapacity struct {
+	cost           TrackCost
+	statsUpdatedAt int64
+	reservations   []capacityReservation
+}
+
+type capacityReservation struct {
+	cost ParticipantCost
+	at   time.Time
+}
+
+func NewCapacityModel(conf config.AdmissionConfig, limit config.LimitConfig) *CapacityModel {
+	maxBytes := conf.MaxBytesPerSec
+	if maxBytes == 0 && limit.BytesPerSec > 0 {
+		maxBytes = float64(limit.BytesPerSec)
+	}
+	return &CapacityModel{
+		conf:     conf,
+		maxBytes: maxBytes,
+		nodes:    make(map[livekit.NodeID]*nodeCapacity),
+	}
+}
+
+// Check predicts the load of the node if a participant joined the room, room is nil for a new room
+func (m *CapacityModel) Check(node *livekit.Node, room *livekit.Room, canPublish, canSubscribe bool) Admission {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	nc := m.observeLocked(node)
+	a := Admission{
+		NodeID:      livekit.NodeID(node.Id),
+		TrackCost:   nc.cost,
+		Participant: m.estimate(nc.cost, room, canPublish, canSubscribe),
+		Admitted:    true,
+	}
+
+	stats := node.Stats
+	if stats == nil {
+		// not reporting yet
+		return a
+	}
+
+	var reserved ParticipantCost
+	for _, r := range nc.reservations {
+		reserved = reserved.add(r.cost)
+	}
+
+	numCPUs := float64(max(stats.NumCpus, 1))
+	a.CPULoad = (float64(stats.CpuLoad)*numCPUs + reserved.CPU + a.Participant.CPU) / numCPUs
+	a.BytesPerSec = float64(stats.BytesInPerSec+stats.BytesOutPerSec) +
+		reserved.BytesInPerSec + reserved.BytesOutPerSec +
+		a.Participant.BytesInPerSec + a.Participant.BytesOutPerSec
+
+	if m.conf.MaxCPULoad > 0 && a.CPULoad > m.conf.MaxCPULoad {
+		a.Admitted = false
+	}
+	if m.maxBytes > 0 && a.BytesPerSec > m.maxBytes {
+		a.Admitted = false
+	}
+	return a
+}
+
+// Reserve counts an admitted participant against its node until node stats reflect it
+func (m *CapacityModel) Reserve(a Admission) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	nc := m.nodes[a.NodeID]
+	if nc == nil {
+		return
+	}
+	nc.reservations = append(nc.reservations, capacityReservation{cost: a.Participant, at: time.Now()})
+}
+
+// Admit checks the participant, and reserves its cost when admitted
+func (m *CapacityModel) Admit(node *livekit.Node, room *livekit.Room, canPublish, canSubscribe bool) Admission {
+	a := m.Check(node, room, canPublish, canSubscribe)
+	if a.Admitted {
+		m.Reserve(a)
+	}
+	return a
+}
+
+func (m *CapacityModel) observeLocked(node *livekit.Node) *nodeCapacity {
+	nodeID := livekit.NodeID(node.Id)
+	nc := m.nodes[nodeID]
+	if nc == nil {
+		nc = &nodeCapacity{
+			cost: TrackCost{
+				CPU:            m.conf.CPUPerTrack,
+				BytesInPerSec:  m.conf.BytesPerTrack,
+				BytesOutPerSec: m.conf.BytesPerTrack,
+			},
+		}
+		m.nodes[nodeID] = nc
+	}
+
+	expiredBefore := time.Now().Add(-capacityReservationWindow)
+	for len(nc.reservations) > 0 && nc.reservations[0].at.Before(expiredBefore) {
+		nc.reservations = nc.reservations[1:]
+	}
+
+	stats := node.Stats
+	if stats == nil || stats.UpdatedAt <= nc.statsUpdatedAt {
+		return nc
+	}
+	nc.statsUpdatedAt = stats.UpdatedAt
+
+	numTracks := stats.NumTracksIn + stats.NumTracksOut
+	if numTracks < capacityMinTracksToLearn {
+		return nc
+	}
+
+	learn := func(prev, sample float64) float64 {
+		if !nc.cost.Learnt {
+			return sample
+		}
+		return prev + capacityCostAlpha*(sample-prev)
+	}
+	nc.cost.CPU = learn(nc.cost.CPU, float64(stats.CpuLoad)*float64(max(stats.NumCpus, 1))/float64(numTracks))
+	if stats.NumTracksIn > 0 {
+		nc.cost.BytesInPerSec = learn(nc.cost.BytesInPerSec, float64(stats.BytesInPerSec)/float64(stats.NumTracksIn))
+	}
+	if stats.NumTracksOut > 0 {
+		nc.cost.BytesOutPerSec = learn(nc.cost.BytesOutPerSec, float64(stats.BytesOutPerSec)/float64(stats.NumTracksOut))
+	}
+	nc.cost.Learnt = true
+	return nc
+}
+
+// estimate counts the tracks the participant would subscribe to, and the subscriptions to its own tracks by the
+// participants already in the room
+func (m *CapacityModel) estimate(cost TrackCost, room *livekit.Room, canPublish, canSubscribe bool) ParticipantCost {
+	tracksPerPublisher := max(m.conf.TracksPerPublisher, 1)
+
+	var c ParticipantCost
+	if canSubscribe && room != nil {
+		c.TracksOut += int(room.NumPublishers) * tracksPerPublisher
+	}
+	if canPublish {
+		c.TracksIn = tracksPerPublisher
+		if room != nil {
+			c.TracksOut += tracksPerPublisher * int(room.NumParticipants)
+		}
+	}
+
+	c.CPU = float64(c.TracksIn+c.TracksOut) * cost.CPU * m.codecCostFactor(room)
+	c.BytesInPerSec = float64(c.TracksIn) * cost.BytesInPerSec
+	c.BytesOutPerSec = float64(c.TracksOut) * cost.BytesOutPerSec
+	return c
+}
+
+func (m *CapacityModel) codecCostFactor(room *livekit.Room) float64 {
+	factor := 1.0
+	if room == nil {
+		return factor
+	}
+	for _, codec := range room.EnabledCodecs {
+		for mime, f := range m.conf.CodecCostFactors {
+			if strings.EqualFold(codec.Mime, mime) {
+				factor = max(factor, f)
+			}
+		}
+	}
+	return factor
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing/selector"
+)
+
+func TestCapacityModel(t *testing.T) {
+	conf := config.AdmissionConfig{
+		Enabled:            true,
+		MaxCPULoad:         0.8,
+		TracksPerPublisher: 2,
+		CPUPerTrack:        0.01,
+		BytesPerTrack:      1000,
+	}
+
+	newNode := func(cpuLoad float32, tracksIn, tracksOut int32, updatedAt int64) *livekit.Node {
+		return &livekit.Node{
+			Id:    "ND_capacity",
+			State: livekit.NodeState_SERVING,
+			Stats: &livekit.NodeStats{
+				UpdatedAt:      updatedAt,
+				NumCpus:        4,
+				CpuLoad:        cpuLoad,
+				NumTracksIn:    tracksIn,
+				NumTracksOut:   tracksOut,
+				BytesInPerSec:  float32(tracksIn) * 2000,
+				BytesOutPerSec: float32(tracksOut) * 3000,
+			},
+		}
+	}
+	room := &livekit.Room{NumParticipants: 10, NumPublishers: 4}
+
+	t.Run("configured cost until learnt", func(t *testing.T) {
+		m := selector.NewCapacityModel(conf, config.LimitConfig{})
+		a := m.Check(newNode(0.1, 2, 2, time.Now().Unix()), room, true, true)
+		require.False(t, a.TrackCost.Learnt)
+		require.True(t, a.Admitted)
+
+		// subscribes to 4 publishers, and its own tracks go to the 10 participants
+		require.Equal(t, 2, a.Participant.TracksIn)
+		require.Equal(t, 4*2+2*10, a.Participant.TracksOut)
+		require.InDelta(t, 30*0.01, a.Participant.CPU, 1e-9)
+		require.InDelta(t, 2000, a.Participant.BytesInPerSec, 1e-9)
+		require.InDelta(t, 28000, a.Participant.BytesOutPerSec, 1e-9)
+		require.InDelta(t, 0.1+30*0.01/4, a.CPULoad, 1e-6)
+	})
+
+	t.Run("subscriber only", func(t *testing.T) {
+		m := selector.NewCapacityModel(conf, config.LimitConfig{})
+		a := m.Check(newNode(0.1, 2, 2, time.Now().Unix()), room, false, true)
+		require.Equal(t, 0, a.Participant.TracksIn)
+		require.Equal(t, 8, a.Participant.TracksOut)
+	})
+
+	t.Run("learns cost from stats", func(t *testing.T) {
+		m := selector.NewCapacityModel(conf, config.LimitConfig{})
+		now := time.Now().Unix()
+
+		// 0.4 * 4 cores over 40 tracks
+		a := m.Check(newNode(0.4, 10, 30, now), room, true, true)
+		require.True(t, a.TrackCost.Learnt)
+		require.InDelta(t, 0.04, a.TrackCost.CPU, 1e-6)
+		require.InDelta(t, 2000, a.TrackCost.BytesInPerSec, 1e-3)
+		require.InDelta(t, 3000, a.TrackCost.BytesOutPerSec, 1e-3)
+
+		// same stats are not learnt twice, newer stats move the estimate towards the sample
+		a = m.Check(newNode(0.8, 10, 30, now), room, true, true)
+		require.InDelta(t, 0.04, a.TrackCost.CPU, 1e-6)
+		a = m.Check(newNode(0.8, 10, 30, now+1), room, true, true)
+		require.Greater(t, a.TrackCost.CPU, 0.04)
+		require.Less(t, a.TrackCost.CPU, 0.08)
+	})
+
+	t.Run("refuses when cpu would be exceeded", func(t *testing.T) {
+		m := selector.NewCapacityModel(conf, config.LimitConfig{})
+		a := m.Check(newNode(0.75, 2, 2, time.Now().Unix()), room, true, true)
+		require.False(t, a.Admitted)
+
+		// a participant in a new room is cheap
+		a = m.Check(newNode(0.75, 2, 2, time.Now().Unix()), nil, true, true)
+		require.True(t, a.Admitted)
+	})
+
+	t.Run("refuses when bandwidth would be exceeded", func(t *testing.T) {
+		m := selector.NewCapacityModel(conf, config.LimitConfig{BytesPerSec: 30000})
+		a := m.Check(newNode(0.1, 2, 2, time.Now().Unix()), room, true, true)
+		require.False(t, a.Admitted)
+		require.InDelta(t, 2*2000+2*3000+30000, a.BytesPerSec, 1e-3)
+	})
+
+	t.Run("admitted participants are reserved", func(t *testing.T) {
+		m := selector.NewCapacityModel(conf, config.LimitConfig{})
+		node := newNode(0.55, 2, 2, time.Now().Unix())
+
+		// each adds 0.075 to the load
+		require.True(t, m.Admit(node, room, true, true).Admitted)
+		require.True(t, m.Admit(node, room, true, true).Admitted)
+		require.True(t, m.Admit(node, room, true, true).Admitted)
+		require.False(t, m.Admit(node, room, true, true).Admitted)
+	})
+
+	t.Run("codec cost", func(t *testing.T) {
+		codecConf := conf
+		codecConf.CodecCostFactors = map[string]float64{"video/av1": 1.5}
+		m := selector.NewCapacityModel(codecConf, config.LimitConfig{})
+
+		a := m.Check(newNode(0.1, 2, 2, time.Now().Unix()), &livekit.Room{
+			NumParticipants: 10,
+			NumPublishers:   4,
+			EnabledCodecs:   []*livekit.Codec{{Mime: "video/VP8"}, {Mime: "video/AV1"}},
+		}, true, true)
+		require.InDelta(t, 30*0.01*1.5, a.Participant.CPU, 1e-9)
+	})
+}
@@ -22,4 +22,9 @@ var (
 	ErrCurrentRegionUnknownLatLon = errors.New("unknown lat and lon for the current region")
 	ErrSortByNotSet               = errors.New("sort by option cannot be blank")
 	ErrSortByUnknown              = errors.New("unknown sort by option")
+	ErrUnknownPlacementPolicy     = errors.New("unknown participant placement policy")
+	ErrGeoIPDatabaseNotSet        = errors.New("participant placement requires a geoip database")
+	ErrRelayNotEnabled            = errors.New("participant placement requires relay between nodes")
+	ErrScriptNotSet               = errors.New("script selector requires a script")
+	ErrHTTPURLNotSet              = errors.New("http selector requires a url")
 )
@@ -0,0 +1,148 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+// HTTPSelector posts the available nodes and the room being placed to a service deciding on placement,
+// and selects the node scoring highest in its response. The service receives
+// {"room": <Room>, "nodes": [{"node": <Node>, "labels": {...}, "taints": [...]}, ...]} and responds with
+// {"scores": {"<node id>": <score>, ...}}, nodes missing from scores or scored negative are excluded.
+type HTTPSelector struct {
+	conf     config.NodeSelectorHTTPConfig
+	client   *http.Client
+	timeout  time.Duration
+	fallback NodeSelector
+}
+
+type httpSelectorRequest struct {
+	Room  json.RawMessage    `json:"room"`
+	Nodes []httpSelectorNode `json:"nodes"`
+}
+
+// labels and taints are not part of the Node proto, they are sent next to it
+type httpSelectorNode struct {
+	Node   json.RawMessage   `json:"node"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Taints []string          `json:"taints,omitempty"`
+}
+
+type httpSelectorResponse struct {
+	Scores map[livekit.NodeID]float64 `json:"scores"`
+}
+
+func NewHTTPSelector(conf config.NodeSelectorHTTPConfig, timeout time.Duration, fallback NodeSelector) (*HTTPSelector, error) {
+	if conf.URL == "" {
+		return nil, ErrHTTPURLNotSet
+	}
+
+	return &HTTPSelector{
+		conf:     conf,
+		client:   &http.Client{},
+		timeout:  timeout,
+		fallback: fallback,
+	}, nil
+}
+
+func (s *HTTPSelector) SelectNode(nodes []*livekit.Node) (*livekit.Node, error) {
+	return s.SelectNodeForRoom(context.Background(), nil, nodes)
+}
+
+func (s *HTTPSelector) SelectNodeForRoom(ctx context.Context, room *livekit.Room, nodes []*livekit.Node) (*livekit.Node, error) {
+	nodes = GetAvailableNodes(nodes)
+	if len(nodes) == 0 {
+		return nil, ErrNoAvailableNodes
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	scores, err := s.scoreNodes(ctx, room, nodes)
+	if err != nil {
+		logger.Warnw("could not score nodes with placement service, using fallback selector", err, "room", room.GetName())
+		return s.fallback.SelectNode(nodes)
+	}
+	return selectScoredNode(nodes, scores, s.fallback)
+}
+
+func (s *HTTPSelector) scoreNodes(ctx context.Context, room *livekit.Room, nodes []*livekit.Node) (map[livekit.NodeID]float64, error) {
+	if room == nil {
+		room = &livekit.Room{}
+	}
+	roomData, err := protojson.Marshal(room)
+	if err != nil {
+		return nil, err
+	}
+	body := httpSelectorRequest{
+		Room:  roomData,
+		Nodes: make([]httpSelectorNode, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		nodeData, err := protojson.Marshal(node)
+		if err != nil {
+			return nil, err
+		}
+		labels, taints := GetNodeLabels(node)
+		body.Nodes = append(body.Nodes, httpSelectorNode{Node: nodeData, Labels: labels, Taints: taints})
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.conf.URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.conf.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil, fmt.Errorf("placement service returned %s", res.Status)
+	}
+
+	var resp httpSelectorResponse
+	if err = json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	return resp.Scores, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing/selector"
+)
+
+func TestHTTPSelector(t *testing.T) {
+	fallback := &selector.AnySelector{SortBy: "random"}
+	east := newTestNodeInRegion("us-east", true)
+	west := newTestNodeInRegion("us-west", true)
+	selector.SetNodeLabels(west, map[string]string{"gpu": "true"}, []string{"dedicated"})
+	nodes := []*livekit.Node{east, west}
+
+	t.Run("selects highest score", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "secret", r.Header.Get("Authorization"))
+
+			var req struct {
+				Room  map[string]any `json:"room"`
+				Nodes []struct {
+					Node   map[string]any    `json:"node"`
+					Labels map[string]string `json:"labels"`
+					Taints []string          `json:"taints"`
+				} `json:"nodes"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, "premium", req.Room["metadata"])
+			require.Len(t, req.Nodes, 2)
+			require.Equal(t, east.Id, req.Nodes[0].Node["id"])
+			require.Empty(t, req.Nodes[0].Labels)
+			require.Equal(t, west.Id, req.Nodes[1].Node["id"])
+			require.Equal(t, map[string]string{"gpu": "true"}, req.Nodes[1].Labels)
+			require.Equal(t, []string{"dedicated"}, req.Nodes[1].Taints)
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"scores": map[string]float64{east.Id: 1, west.Id: 2},
+			})
+		}))
+		defer srv.Close()
+
+		s, err := selector.NewHTTPSelector(config.NodeSelectorHTTPConfig{
+			URL:     srv.URL,
+			Headers: map[string]string{"Authorization": "secret"},
+		}, time.Second, fallback)
+		require.NoError(t, err)
+
+		node, err := s.SelectNodeForRoom(context.Background(), &livekit.Room{Name: "room", Metadata: "premium"}, nodes)
+		require.NoError(t, err)
+		require.Equal(t, west, node)
+	})
+
+	t.Run("falls back on timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer srv.Close()
+
+		s, err := selector.NewHTTPSelector(config.NodeSelectorHTTPConfig{URL: srv.URL}, 50*time.Millisecond, fallback)
+		require.NoError(t, err)
+
+		node, err := s.SelectNode(nodes)
+		require.NoError(t, err)
+		require.NotNil(t, node)
+	})
+
+	t.Run("falls back on error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		s, err := selector.NewHTTPSelector(config.NodeSelectorHTTPConfig{URL: srv.URL}, time.Second, fallback)
+		require.NoError(t, err)
+
+		node, err := s.SelectNode(nodes)
+		require.NoError(t, err)
+		require.NotNil(t, node)
+	})
+}
@@ -15,6 +15,7 @@
 package selector
 
 import (
+	"context"
 	"errors"
 
 	"github.com/livekit/protocol/livekit"
@@ -30,8 +31,33 @@ type NodeSelector interface {
 	SelectNode(nodes []*livekit.Node) (*livekit.Node, error)
 }
 
+// RoomNodeSelector is implemented by selectors that decide on the room being placed
+type RoomNodeSelector interface {
+	NodeSelector
+	SelectNodeForRoom(ctx context.Context, room *livekit.Room, nodes []*livekit.Node) (*livekit.Node, error)
+}
+
 func CreateNodeSelector(conf *config.Config) (NodeSelector, error) {
 	kind := conf.NodeSelector.Kind
+	if kind == "" {
+		kind = "any"
+	}
+	switch kind {
+	case "script", "http":
+		fallback, err := createBuiltinNodeSelector(conf, conf.NodeSelector.Fallback)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "script" {
+			return NewScriptSelector(conf.NodeSelector.Script, conf.NodeSelector.Timeout, fallback)
+		}
+		return NewHTTPSelector(conf.NodeSelector.HTTP, conf.NodeSelector.Timeout, fallback)
+	default:
+		return createBuiltinNodeSelector(conf, kind)
+	}
+}
+
+func createBuiltinNodeSelector(conf *config.Config, kind string) (NodeSelector, error) {
 	if kind == "" {
 		kind = "any"
 	}
@@ -0,0 +1,162 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+// livekit.Node has no room for labels, they are carried as an unknown field that survives marshaling,
+// so they reach other nodes wherever the node is registered. The number is far above any field of
+// livekit.Node.
+const nodeLabelsFieldNumber protowire.Number = 536870000
+
+type nodeLabels struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Taints []string          `json:"taints,omitempty"`
+}
+
+// SetNodeLabels attaches labels and taints to a node, replacing previous ones
+func SetNodeLabels(node *livekit.Node, labels map[string]string, taints []string) {
+	m := node.ProtoReflect()
+	unknown := removeNodeLabelsField(m.GetUnknown())
+	if len(labels) != 0 || len(taints) != 0 {
+		data, err := json.Marshal(nodeLabels{Labels: labels, Taints: taints})
+		if err != nil {
+			logger.Errorw("could not marshal node labels", err)
+			return
+		}
+		unknown = protowire.AppendTag(unknown, nodeLabelsFieldNumber, protowire.BytesType)
+		unknown = protowire.AppendBytes(unknown, data)
+	}
+	m.SetUnknown(unknown)
+}
+
+// GetNodeLabels returns the labels and taints of a node
+func GetNodeLabels(node *livekit.Node) (map[string]string, []string) {
+	b := node.ProtoReflect().GetUnknown()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, nil
+		}
+		m := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if m < 0 {
+			return nil, nil
+		}
+		if num == nodeLabelsFieldNumber && typ == protowire.BytesType {
+			data, _ := protowire.ConsumeBytes(b[n:])
+			var nl nodeLabels
+			if err := json.Unmarshal(data, &nl); err != nil {
+				return nil, nil
+			}
+			return nl.Labels, nl.Taints
+		}
+		b = b[n+m:]
+	}
+	return nil, nil
+}
+
+func removeNodeLabelsField(b []byte) []byte {
+	var out []byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return out
+		}
+		m := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if m < 0 {
+			return out
+		}
+		if num != nodeLabelsFieldNumber {
+			out = append(out, b[:n+m]...)
+		}
+		b = b[n+m:]
+	}
+	return out
+}
+
+// PlacementRules constrains the nodes rooms are placed on. Rooms are placed on nodes having all labels
+// required by the first rule matching the room, and tolerate the taints listed by that rule. Nodes with
+// taints are left to rooms tolerating all of them.
+type PlacementRules struct {
+	rules []config.RoomPlacementConfig
+}
+
+func NewPlacementRules(rules []config.RoomPlacementConfig) (*PlacementRules, error) {
+	for _, rule := range rules {
+		if _, err := path.Match(rule.Room, ""); err != nil {
+			return nil, err
+		}
+	}
+	return &PlacementRules{rules: rules}, nil
+}
+
+// FilterNodes returns the nodes a room can be placed on
+func (p *PlacementRules) FilterNodes(roomName livekit.RoomName, nodes []*livekit.Node) []*livekit.Node {
+	rule := p.ruleForRoom(roomName)
+	filtered := make([]*livekit.Node, 0, len(nodes))
+	for _, node := range nodes {
+		labels, taints := GetNodeLabels(node)
+		if hasLabels(labels, rule.NodeLabels) && toleratesTaints(rule.Tolerations, taints) {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
+func (p *PlacementRules) ruleForRoom(roomName livekit.RoomName) config.RoomPlacementConfig {
+	for _, rule := range p.rules {
+		if ok, _ := path.Match(rule.Room, string(roomName)); ok {
+			return rule
+		}
+	}
+	return config.RoomPlacementConfig{}
+}
+
+func hasLabels(labels map[string]string, required map[string]string) bool {
+	for k, v := range required {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+func toleratesTaints(tolerations []string, taints []string) bool {
+	for _, taint := range taints {
+		key, _, _ := strings.Cut(taint, "=")
+		tolerated := false
+		for _, toleration := range tolerations {
+			if toleration == taint || toleration == key {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing/selector"
+)
+
+func TestNodeLabels(t *testing.T) {
+	node := newTestNodeInRegion("us-east", true)
+	selector.SetNodeLabels(node, map[string]string{"tier": "premium"}, []string{"customer=acme"})
+	selector.SetNodeLabels(node, map[string]string{"tier": "premium", "gpu": "true"}, []string{"customer=acme"})
+
+	// labels survive registration with other nodes
+	data, err := proto.Marshal(node)
+	require.NoError(t, err)
+	registered := &livekit.Node{}
+	require.NoError(t, proto.Unmarshal(data, registered))
+
+	labels, taints := selector.GetNodeLabels(registered)
+	require.Equal(t, map[string]string{"tier": "premium", "gpu": "true"}, labels)
+	require.Equal(t, []string{"customer=acme"}, taints)
+	require.Equal(t, "us-east", registered.Region)
+
+	selector.SetNodeLabels(registered, nil, nil)
+	labels, taints = selector.GetNodeLabels(registered)
+	require.Empty(t, labels)
+	require.Empty(t, taints)
+}
+
+func TestPlacementRules(t *testing.T) {
+	plain := newTestNodeInRegion("us-east", true)
+	gpu := newTestNodeInRegion("us-east", true)
+	selector.SetNodeLabels(gpu, map[string]string{"gpu": "true"}, nil)
+	acme := newTestNodeInRegion("us-east", true)
+	selector.SetNodeLabels(acme, map[string]string{"gpu": "true"}, []string{"customer=acme"})
+	nodes := []*livekit.Node{plain, gpu, acme}
+
+	rules, err := selector.NewPlacementRules([]config.RoomPlacementConfig{
+		{Room: "acme-*", Tolerations: []string{"customer=acme"}},
+		{Room: "vision-*", NodeLabels: map[string]string{"gpu": "true"}},
+		{Room: "any-*", Tolerations: []string{"customer"}},
+	})
+	require.NoError(t, err)
+
+	// tainted nodes are left out unless tolerated
+	require.Equal(t, []*livekit.Node{plain, gpu}, rules.FilterNodes("room", nodes))
+	require.Equal(t, []*livekit.Node{plain, gpu, acme}, rules.FilterNodes("acme-room", nodes))
+	require.Equal(t, []*livekit.Node{plain, gpu, acme}, rules.FilterNodes("any-room", nodes))
+	// required labels
+	require.Equal(t, []*livekit.Node{gpu}, rules.FilterNodes("vision-room", nodes))
+
+	_, err = selector.NewPlacementRules([]config.RoomPlacementConfig{{Room: "[acme"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"hash/fnv"
+	"math"
+	"net"
+	"sort"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing/geoip"
+)
+
+// Locator finds the location of a client address
+type Locator interface {
+	Locate(ip net.IP) (geoip.Location, error)
+}
+
+// ParticipantPlacer picks the node a participant connects to, which is either the origin node hosting the room
+// or an edge node in the region nearest to the client
+type ParticipantPlacer struct {
+	locator     Locator
+	regions     map[string]config.RegionConfig
+	minDistance float64
+	limits      config.LimitConfig
+}
+
+// NewParticipantPlacer returns nil when participants are always placed at the origin. The locator is nil
+// without a geoip database.
+func NewParticipantPlacer(conf *config.Config, locator Locator) (*ParticipantPlacer, error) {
+	pc := conf.NodeSelector.ParticipantPlacement
+	switch pc.Policy {
+	case "", config.ParticipantPlacementOrigin:
+		return nil, nil
+	case config.ParticipantPlacementNearest:
+	default:
+		return nil, ErrUnknownPlacementPolicy
+	}
+	if locator == nil {
+		return nil, ErrGeoIPDatabaseNotSet
+	}
+	if !conf.RTC.Relay.Enabled {
+		return nil, ErrRelayNotEnabled
+	}
+	return NewParticipantPlacerWithLocator(locator, conf.NodeSelector.Regions, pc.MinDistanceKm, conf.Limit), nil
+}
+
+func NewParticipantPlacerWithLocator(locator Locator, regions []config.RegionConfig, minDistanceKm float64, limits config.LimitConfig) *ParticipantPlacer {
+	p := &ParticipantPlacer{
+		locator:     locator,
+		regions:     make(map[string]config.RegionConfig, len(regions)),
+		minDistance: minDistanceKm * 1000,
+		limits:      limits,
+	}
+	for _, region := range regions {
+		p.regions[region.Name] = region
+	}
+	return p
+}
+
+// SelectNode returns the edge node for a client of the room, or nil when it should connect to the origin.
+// Nodes of a region are picked by room name, so that participants of a room nearby share an edge node.
+func (p *ParticipantPlacer) SelectNode(clientIP string, roomName livekit.RoomName, origin *livekit.Node, nodes []*livekit.Node) *livekit.Node {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return nil
+	}
+	originRegion, ok := p.regions[origin.Region]
+	if !ok {
+		return nil
+	}
+	loc, err := p.locator.Locate(ip)
+	if err != nil {
+		return nil
+	}
+
+	var nearestNodes []*livekit.Node
+	nearestRegion := ""
+	minDist := math.MaxFloat64
+	for _, node := range GetAvailableNodes(nodes) {
+		if LimitsReached(p.limits, node.Stats) {
+			continue
+		}
+		if node.Region == nearestRegion {
+			nearestNodes = append(nearestNodes, node)
+			continue
+		}
+		region, ok := p.regions[node.Region]
+		if !ok {
+			continue
+		}
+		if dist := distanceBetween(loc.Lat, loc.Lon, region.Lat, region.Lon); dist < minDist {
+			minDist = dist
+			nearestRegion = node.Region
+			nearestNodes = append(nearestNodes[:0], node)
+		}
+	}
+
+	if len(nearestNodes) == 0 || nearestRegion == origin.Region {
+		return nil
+	}
+	if distanceBetween(loc.Lat, loc.Lon, originRegion.Lat, originRegion.Lon)-minDist < p.minDistance {
+		return nil
+	}
+
+	sort.Slice(nearestNodes, func(i, j int) bool {
+		return nearestNodes[i].Id < nearestNodes[j].Id
+	})
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(roomName))
+	return nearestNodes[h.Sum32()%uint32(len(nearestNodes))]
+}
+
+// SortRegionsByDistance orders regions nearest to the client first. Returns false, leaving the order as is,
+// when the client cannot be located.
+func SortRegionsByDistance(locator Locator, clientIP string, regions []config.RegionConfig) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	loc, err := locator.Locate(ip)
+	if err != nil {
+		return false
+	}
+
+	sort.SliceStable(regions, func(i, j int) bool {
+		return distanceBetween(loc.Lat, loc.Lon, regions[i].Lat, regions[i].Lon) <
+			distanceBetween(loc.Lat, loc.Lon, regions[j].Lat, regions[j].Lon)
+	})
+	return true
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing/geoip"
+	"github.com/livekit/livekit-server/pkg/routing/selector"
+)
+
+const (
+	regionFrankfurt = "eu-central"
+	clientLondon    = "81.2.69.160"
+	clientNewYork   = "66.42.0.1"
+)
+
+type testLocator map[string]geoip.Location
+
+func (l testLocator) Locate(ip net.IP) (geoip.Location, error) {
+	if loc, ok := l[ip.String()]; ok {
+		return loc, nil
+	}
+	return geoip.Location{}, geoip.ErrNoLocation
+}
+
+func TestParticipantPlacer(t *testing.T) {
+	rc := []config.RegionConfig{
+		{Name: regionWest, Lat: 37.64046607830567, Lon: -120.88026233189062},
+		{Name: regionEast, Lat: 40.68914362140307, Lon: -74.04445748616385},
+		{Name: regionFrankfurt, Lat: 50.110924, Lon: 8.682127},
+	}
+	locator := testLocator{
+		clientLondon:  {Lat: 51.5142, Lon: -0.0931},
+		clientNewYork: {Lat: 40.7128, Lon: -74.006},
+	}
+	p := selector.NewParticipantPlacerWithLocator(locator, rc, 1000, config.LimitConfig{})
+
+	origin := newTestNodeInRegion(regionWest, true)
+	east := newTestNodeInRegion(regionEast, true)
+	frankfurt := []*livekit.Node{
+		newTestNodeInRegion(regionFrankfurt, true),
+		newTestNodeInRegion(regionFrankfurt, true),
+	}
+	nodes := []*livekit.Node{origin, east, frankfurt[0], frankfurt[1]}
+
+	t.Run("places at the nearest region", func(t *testing.T) {
+		require.Equal(t, east, p.SelectNode(clientNewYork, "room", origin, nodes))
+
+		node := p.SelectNode(clientLondon, "room", origin, nodes)
+		require.Contains(t, frankfurt, node)
+		// participants of the same room share the edge node
+		require.Equal(t, node, p.SelectNode(clientLondon, "room", origin, nodes))
+	})
+
+	t.Run("places at the origin when it is the nearest", func(t *testing.T) {
+		eastOrigin := newTestNodeInRegion(regionEast, true)
+		require.Nil(t, p.SelectNode(clientNewYork, "room", eastOrigin, append(nodes, eastOrigin)))
+	})
+
+	t.Run("places at the origin when the edge is not much closer", func(t *testing.T) {
+		p := selector.NewParticipantPlacerWithLocator(locator, rc, 10000, config.LimitConfig{})
+		require.Nil(t, p.SelectNode(clientLondon, "room", origin, nodes))
+	})
+
+	t.Run("places at the origin for unknown clients", func(t *testing.T) {
+		require.Nil(t, p.SelectNode("10.0.0.1", "room", origin, nodes))
+		require.Nil(t, p.SelectNode("", "room", origin, nodes))
+	})
+
+	t.Run("skips unavailable nodes", func(t *testing.T) {
+		unavailable := newTestNodeInRegion(regionEast, true)
+		unavailable.State = livekit.NodeState_SHUTTING_DOWN
+		require.Nil(t, p.SelectNode(clientNewYork, "room", origin, []*livekit.Node{origin, unavailable}))
+	})
+}
+
+func TestSortRegionsByDistance(t *testing.T) {
+	rc := []config.RegionConfig{
+		{Name: regionWest, Lat: 37.64046607830567, Lon: -120.88026233189062},
+		{Name: regionEast, Lat: 40.68914362140307, Lon: -74.04445748616385},
+		{Name: regionFrankfurt, Lat: 50.110924, Lon: 8.682127},
+	}
+	locator := testLocator{
+		clientLondon: {Lat: 51.5142, Lon: -0.0931},
+	}
+	names := func(regions []config.RegionConfig) []string {
+		var names []string
+		for _, region := range regions {
+			names = append(names, region.Name)
+		}
+		return names
+	}
+
+	t.Run("nearest region first", func(t *testing.T) {
+		regions := slices.Clone(rc)
+		require.True(t, selector.SortRegionsByDistance(locator, clientLondon, regions))
+		require.Equal(t, []string{regionFrankfurt, regionEast, regionWest}, names(regions))
+	})
+
+	t.Run("order is kept for unknown clients", func(t *testing.T) {
+		regions := slices.Clone(rc)
+		require.False(t, selector.SortRegionsByDistance(locator, clientNewYork, regions))
+		require.False(t, selector.SortRegionsByDistance(locator, "", regions))
+		require.Equal(t, names(rc), names(regions))
+	})
+}
@@ -0,0 +1,215 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/d5/tengo/v2"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+var errInvalidScriptResult = errors.New("invalid node score")
+
+// ScriptSelector scores each available node with a script expression and selects the node scoring highest.
+// The expression sees the node as n and the room being placed as room, and returns a number. Nodes scored
+// false, undefined or negative are excluded.
+// expression examples:
+// least loaded node: 1 - n.sysload
+// nodes with a gpu: n.labels.gpu == "true"
+// nodes in the contract region: n.region == "us-east" ? 1 - n.sysload : false
+// premium rooms first on dedicated nodes: room.metadata == "premium" ? (n.region == "us-east-dedicated" ? 10 : 1) : 1
+type ScriptSelector struct {
+	compiled *tengo.Compiled
+	timeout  time.Duration
+	fallback NodeSelector
+}
+
+func NewScriptSelector(expr string, timeout time.Duration, fallback NodeSelector) (*ScriptSelector, error) {
+	if expr == "" {
+		return nil, ErrScriptNotSet
+	}
+
+	script := tengo.NewScript([]byte("__res__ := (" + expr + ")"))
+	if err := script.Add("n", &nodeObject{node: &livekit.Node{}}); err != nil {
+		return nil, err
+	}
+	if err := script.Add("room", &roomObject{}); err != nil {
+		return nil, err
+	}
+	compiled, err := script.Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScriptSelector{
+		compiled: compiled,
+		timeout:  timeout,
+		fallback: fallback,
+	}, nil
+}
+
+func (s *ScriptSelector) SelectNode(nodes []*livekit.Node) (*livekit.Node, error) {
+	return s.SelectNodeForRoom(context.Background(), nil, nodes)
+}
+
+func (s *ScriptSelector) SelectNodeForRoom(ctx context.Context, room *livekit.Room, nodes []*livekit.Node) (*livekit.Node, error) {
+	nodes = GetAvailableNodes(nodes)
+	if len(nodes) == 0 {
+		return nil, ErrNoAvailableNodes
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	scores, err := s.scoreNodes(ctx, room, nodes)
+	if err != nil {
+		logger.Warnw("could not score nodes with script, using fallback selector", err, "room", room.GetName())
+		return s.fallback.SelectNode(nodes)
+	}
+	return selectScoredNode(nodes, scores, s.fallback)
+}
+
+func (s *ScriptSelector) scoreNodes(ctx context.Context, room *livekit.Room, nodes []*livekit.Node) (map[livekit.NodeID]float64, error) {
+	scores := make(map[livekit.NodeID]float64, len(nodes))
+	for _, node := range nodes {
+		// compiled scripts are not safe for concurrent use, each run gets its own copy
+		c := s.compiled.Clone()
+		if err := c.Set("n", &nodeObject{node: node}); err != nil {
+			return nil, err
+		}
+		if err := c.Set("room", &roomObject{room: room}); err != nil {
+			return nil, err
+		}
+		if err := c.RunContext(ctx); err != nil {
+			return nil, err
+		}
+
+		switch res := c.Get("__res__").Object().(type) {
+		case *tengo.Int:
+			scores[livekit.NodeID(node.Id)] = float64(res.Value)
+		case *tengo.Float:
+			scores[livekit.NodeID(node.Id)] = res.Value
+		case *tengo.Bool:
+			if !res.IsFalsy() {
+				scores[livekit.NodeID(node.Id)] = 0
+			}
+		case *tengo.Undefined:
+		default:
+			return nil, errInvalidScriptResult
+		}
+	}
+	return scores, nil
+}
+
+type nodeObject struct {
+	tengo.ObjectImpl
+	node *livekit.Node
+}
+
+func (n *nodeObject) TypeName() string {
+	return "nodeObject"
+}
+
+func (n *nodeObject) String() string {
+	return n.node.String()
+}
+
+func (n *nodeObject) IndexGet(index tengo.Object) (res tengo.Object, err error) {
+	field, ok := index.(*tengo.String)
+	if !ok {
+		return nil, tengo.ErrInvalidIndexType
+	}
+
+	stats := n.node.GetStats()
+	switch field.Value {
+	case "id":
+		return &tengo.String{Value: n.node.Id}, nil
+	case "ip":
+		return &tengo.String{Value: n.node.Ip}, nil
+	case "region":
+		return &tengo.String{Value: n.node.Region}, nil
+	case "labels":
+		labels, _ := GetNodeLabels(n.node)
+		m := make(map[string]tengo.Object, len(labels))
+		for k, v := range labels {
+			m[k] = &tengo.String{Value: v}
+		}
+		return &tengo.ImmutableMap{Value: m}, nil
+	case "num_cpus":
+		return &tengo.Int{Value: int64(stats.GetNumCpus())}, nil
+	case "cpu_load":
+		return &tengo.Float{Value: float64(stats.GetCpuLoad())}, nil
+	case "sysload":
+		if stats == nil {
+			return &tengo.Float{}, nil
+		}
+		return &tengo.Float{Value: float64(GetNodeSysload(n.node))}, nil
+	case "num_rooms":
+		return &tengo.Int{Value: int64(stats.GetNumRooms())}, nil
+	case "num_clients":
+		return &tengo.Int{Value: int64(stats.GetNumClients())}, nil
+	case "num_tracks_in":
+		return &tengo.Int{Value: int64(stats.GetNumTracksIn())}, nil
+	case "num_tracks_out":
+		return &tengo.Int{Value: int64(stats.GetNumTracksOut())}, nil
+	case "bytes_in_per_sec":
+		return &tengo.Float{Value: float64(stats.GetBytesInPerSec())}, nil
+	case "bytes_out_per_sec":
+		return &tengo.Float{Value: float64(stats.GetBytesOutPerSec())}, nil
+	}
+	return &tengo.Undefined{}, nil
+}
+
+type roomObject struct {
+	tengo.ObjectImpl
+	room *livekit.Room
+}
+
+func (r *roomObject) TypeName() string {
+	return "roomObject"
+}
+
+func (r *roomObject) String() string {
+	return r.room.String()
+}
+
+func (r *roomObject) IndexGet(index tengo.Object) (res tengo.Object, err error) {
+	field, ok := index.(*tengo.String)
+	if !ok {
+		return nil, tengo.ErrInvalidIndexType
+	}
+
+	switch field.Value {
+	case "name":
+		return &tengo.String{Value: r.room.GetName()}, nil
+	case "sid":
+		return &tengo.String{Value: r.room.GetSid()}, nil
+	case "metadata":
+		return &tengo.String{Value: r.room.GetMetadata()}, nil
+	case "max_participants":
+		return &tengo.Int{Value: int64(r.room.GetMaxParticipants())}, nil
+	case "num_participants":
+		return &tengo.Int{Value: int64(r.room.GetNumParticipants())}, nil
+	}
+	return &tengo.Undefined{}, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/routing/selector"
+)
+
+func TestScriptSelector(t *testing.T) {
+	fallback := &selector.AnySelector{SortBy: "random"}
+	east := newTestNodeInRegion("us-east", true)
+	west := newTestNodeInRegion("us-west", true)
+	nodes := []*livekit.Node{east, west}
+
+	t.Run("selects highest score", func(t *testing.T) {
+		s, err := selector.NewScriptSelector(`n.region == "us-west" ? 2 : 1`, 0, fallback)
+		require.NoError(t, err)
+
+		node, err := s.SelectNode(nodes)
+		require.NoError(t, err)
+		require.Equal(t, west, node)
+	})
+
+	t.Run("room info", func(t *testing.T) {
+		s, err := selector.NewScriptSelector(`room.metadata == "premium" ? n.region == "us-east" : n.region == "us-west"`, 0, fallback)
+		require.NoError(t, err)
+
+		node, err := s.SelectNodeForRoom(context.Background(), &livekit.Room{Name: "room", Metadata: "premium"}, nodes)
+		require.NoError(t, err)
+		require.Equal(t, east, node)
+
+		node, err = s.SelectNodeForRoom(context.Background(), &livekit.Room{Name: "room"}, nodes)
+		require.NoError(t, err)
+		require.Equal(t, west, node)
+	})
+
+	t.Run("all nodes excluded", func(t *testing.T) {
+		s, err := selector.NewScriptSelector(`n.region == "eu-west" ? 1 : -1`, 0, fallback)
+		require.NoError(t, err)
+
+		_, err = s.SelectNode(nodes)
+		require.ErrorIs(t, err, selector.ErrNoAvailableNodes)
+	})
+
+	t.Run("falls back on invalid result", func(t *testing.T) {
+		s, err := selector.NewScriptSelector(`n.region`, 0, fallback)
+		require.NoError(t, err)
+
+		node, err := s.SelectNode(nodes)
+		require.NoError(t, err)
+		require.NotNil(t, node)
+	})
+
+	t.Run("invalid script", func(t *testing.T) {
+		_, err := selector.NewScriptSelector(`n.region ==`, 0, fallback)
+		require.Error(t, err)
+
+		_, err = selector.NewScriptSelector("", 0, fallback)
+		require.ErrorIs(t, err, selector.ErrScriptNotSet)
+	})
+}
@@ -113,3 +113,33 @@ func SelectSortedNode(nodes []*livekit.Node, sortBy string) (*livekit.Node, erro
 		return nil, ErrSortByUnknown
 	}
 }
+
+// selectScoredNode selects the node with the highest score. Nodes without a score, or with a negative one,
+// are excluded. Ties are broken by the tiebreak selector.
+func selectScoredNode(nodes []*livekit.Node, scores map[livekit.NodeID]float64, tiebreak NodeSelector) (*livekit.Node, error) {
+	var best []*livekit.Node
+	var bestScore float64
+	for _, node := range nodes {
+		score, ok := scores[livekit.NodeID(node.Id)]
+		if !ok || score < 0 {
+			continue
+		}
+		switch {
+		case len(best) == 0 || score > bestScore:
+			best = []*livekit.Node{node}
+			bestScore = score
+		case score == bestScore:
+			best = append(best, node)
+		}
+	}
+
+	if len(best) == 0 {
+		return nil, ErrNoAvailableNodes
+	}
+	if len(best) > 1 {
+		if node, err := tiebreak.SelectNode(best); err == nil {
+			return node, nil
+		}
+	}
+	return best[0], nil
+}
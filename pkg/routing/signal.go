@@ -133,7 +133,11 @@ func (r *signalClient) StartParticipantSignal(
 		)
 		l.Debugw("signal stream closed", "error", err)
 
-		resChan.Close()
+		// the stream failing rather than being closed by either side means the RTC node is unreachable
+		if errors.Is(err, psrpc.ErrStreamClosed) {
+			err = nil
+		}
+		resChan.CloseWithError(err)
 	}()
 
 	return connectionID, sink, resChan, nil
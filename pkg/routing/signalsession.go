@@ -0,0 +1,177 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"sort"
+	"sync"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// SignalSession follows the signal messages of a participant relayed by the signal node, keeping the state
+// the client would send in a SyncState when resuming. When the RTC node hosting the participant fails,
+// the session is resumed on another node with it.
+type SignalSession struct {
+	lock          sync.Mutex
+	autoSubscribe bool
+	participantID livekit.ParticipantID
+	left          bool
+
+	offer          *livekit.SessionDescription
+	answer         *livekit.SessionDescription
+	publishTracks  map[string]*livekit.TrackPublishedResponse // cid -> track
+	subscriptions  map[livekit.TrackID]bool
+	disabledTracks map[livekit.TrackID]bool
+	dataChannels   []*livekit.DataChannelInfo
+}
+
+func NewSignalSession(pi ParticipantInit) *SignalSession {
+	s := &SignalSession{
+		autoSubscribe:  pi.AutoSubscribe,
+		publishTracks:  make(map[string]*livekit.TrackPublishedResponse),
+		subscriptions:  make(map[livekit.TrackID]bool),
+		disabledTracks: make(map[livekit.TrackID]bool),
+	}
+	if pi.Reconnect {
+		s.participantID = pi.ID
+	}
+	return s
+}
+
+// ParticipantID is set once the participant joined
+func (s *SignalSession) ParticipantID() livekit.ParticipantID {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.participantID
+}
+
+// CanResume returns true when the participant joined and has not been asked to leave
+func (s *SignalSession) CanResume() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.participantID != "" && !s.left
+}
+
+// ObserveRequest records a request sent by the client
+func (s *SignalSession) ObserveRequest(req *livekit.SignalRequest) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch m := req.Message.(type) {
+	case *livekit.SignalRequest_Offer:
+		s.offer = m.Offer
+	case *livekit.SignalRequest_Answer:
+		s.answer = m.Answer
+	case *livekit.SignalRequest_Subscription:
+		s.updateSubscriptionLocked(m.Subscription)
+	case *livekit.SignalRequest_TrackSetting:
+		for _, trackID := range m.TrackSetting.TrackSids {
+			if m.TrackSetting.Disabled {
+				s.disabledTracks[livekit.TrackID(trackID)] = true
+			} else {
+				delete(s.disabledTracks, livekit.TrackID(trackID))
+			}
+		}
+	case *livekit.SignalRequest_Leave:
+		s.left = true
+	case *livekit.SignalRequest_SyncState:
+		// the client resumed, its own state replaces the one followed so far
+		state := m.SyncState
+		s.offer = state.Offer
+		s.answer = state.Answer
+		s.publishTracks = make(map[string]*livekit.TrackPublishedResponse, len(state.PublishTracks))
+		for _, t := range state.PublishTracks {
+			s.publishTracks[t.Cid] = t
+		}
+		s.subscriptions = make(map[livekit.TrackID]bool)
+		s.updateSubscriptionLocked(state.Subscription)
+		s.disabledTracks = make(map[livekit.TrackID]bool, len(state.TrackSidsDisabled))
+		for _, trackID := range state.TrackSidsDisabled {
+			s.disabledTracks[livekit.TrackID(trackID)] = true
+		}
+		s.dataChannels = state.DataChannels
+	}
+}
+
+func (s *SignalSession) updateSubscriptionLocked(sub *livekit.UpdateSubscription) {
+	if sub == nil {
+		return
+	}
+	for _, trackID := range sub.TrackSids {
+		s.subscriptions[livekit.TrackID(trackID)] = sub.Subscribe
+	}
+	for _, pt := range sub.ParticipantTracks {
+		for _, trackID := range pt.TrackSids {
+			s.subscriptions[livekit.TrackID(trackID)] = sub.Subscribe
+		}
+	}
+}
+
+// ObserveResponse records a response sent to the client
+func (s *SignalSession) ObserveResponse(res *livekit.SignalResponse) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch m := res.Message.(type) {
+	case *livekit.SignalResponse_Join:
+		s.participantID = livekit.ParticipantID(m.Join.GetParticipant().GetSid())
+	case *livekit.SignalResponse_TrackPublished:
+		s.publishTracks[m.TrackPublished.Cid] = m.TrackPublished
+	case *livekit.SignalResponse_TrackUnpublished:
+		for cid, t := range s.publishTracks {
+			if t.GetTrack().GetSid() == m.TrackUnpublished.TrackSid {
+				delete(s.publishTracks, cid)
+			}
+		}
+	case *livekit.SignalResponse_Leave:
+		s.left = true
+	}
+}
+
+// SyncState returns the state of the client, as it would sync it when resuming
+func (s *SignalSession) SyncState() *livekit.SyncState {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// with auto subscribe, tracks unsubscribed by the client are listed and vice versa
+	subscription := &livekit.UpdateSubscription{
+		Subscribe: !s.autoSubscribe,
+	}
+	for trackID, subscribed := range s.subscriptions {
+		if subscribed == subscription.Subscribe {
+			subscription.TrackSids = append(subscription.TrackSids, string(trackID))
+		}
+	}
+	sort.Strings(subscription.TrackSids)
+
+	state := &livekit.SyncState{
+		Answer:            s.answer,
+		Offer:             s.offer,
+		Subscription:      subscription,
+		TrackSidsDisabled: livekit.IDsAsStrings(maps.Keys(s.disabledTracks)),
+		DataChannels:      s.dataChannels,
+	}
+	sort.Strings(state.TrackSidsDisabled)
+
+	cids := maps.Keys(s.publishTracks)
+	sort.Strings(cids)
+	for _, cid := range cids {
+		state.PublishTracks = append(state.PublishTracks, s.publishTracks[cid])
+	}
+	return state
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/routing"
+)
+
+func TestSignalSession(t *testing.T) {
+	join := &livekit.SignalResponse{
+		Message: &livekit.SignalResponse_Join{
+			Join: &livekit.JoinResponse{Participant: &livekit.ParticipantInfo{Sid: "PA_1"}},
+		},
+	}
+
+	t.Run("resumable once joined", func(t *testing.T) {
+		s := routing.NewSignalSession(routing.ParticipantInit{AutoSubscribe: true})
+		require.False(t, s.CanResume())
+
+		s.ObserveResponse(join)
+		require.True(t, s.CanResume())
+		require.Equal(t, livekit.ParticipantID("PA_1"), s.ParticipantID())
+
+		s.ObserveRequest(&livekit.SignalRequest{Message: &livekit.SignalRequest_Leave{Leave: &livekit.LeaveRequest{}}})
+		require.False(t, s.CanResume())
+	})
+
+	t.Run("sync state", func(t *testing.T) {
+		s := routing.NewSignalSession(routing.ParticipantInit{AutoSubscribe: true})
+		s.ObserveResponse(join)
+		s.ObserveRequest(&livekit.SignalRequest{Message: &livekit.SignalRequest_Offer{Offer: &livekit.SessionDescription{Sdp: "offer"}}})
+		s.ObserveRequest(&livekit.SignalRequest{Message: &livekit.SignalRequest_Answer{Answer: &livekit.SessionDescription{Sdp: "answer"}}})
+		for _, cid := range []string{"cid2", "cid1", "cid3"} {
+			s.ObserveResponse(&livekit.SignalResponse{
+				Message: &livekit.SignalResponse_TrackPublished{
+					TrackPublished: &livekit.TrackPublishedResponse{Cid: cid, Track: &livekit.TrackInfo{Sid: "TR_" + cid}},
+				},
+			})
+		}
+		s.ObserveResponse(&livekit.SignalResponse{
+			Message: &livekit.SignalResponse_TrackUnpublished{TrackUnpublished: &livekit.TrackUnpublishedResponse{TrackSid: "TR_cid3"}},
+		})
+		s.ObserveRequest(&livekit.SignalRequest{
+			Message: &livekit.SignalRequest_Subscription{
+				Subscription: &livekit.UpdateSubscription{TrackSids: []string{"TR_b", "TR_a"}, Subscribe: false},
+			},
+		})
+		s.ObserveRequest(&livekit.SignalRequest{
+			Message: &livekit.SignalRequest_Subscription{
+				Subscription: &livekit.UpdateSubscription{TrackSids: []string{"TR_b"}, Subscribe: true},
+			},
+		})
+		s.ObserveRequest(&livekit.SignalRequest{
+			Message: &livekit.SignalRequest_TrackSetting{
+				TrackSetting: &livekit.UpdateTrackSettings{TrackSids: []string{"TR_c"}, Disabled: true},
+			},
+		})
+
+		state := s.SyncState()
+		require.Equal(t, "offer", state.Offer.Sdp)
+		require.Equal(t, "answer", state.Answer.Sdp)
+		require.Len(t, state.PublishTracks, 2)
+		require.Equal(t, "cid1", state.PublishTracks[0].Cid)
+		require.Equal(t, "cid2", state.PublishTracks[1].Cid)
+		require.False(t, state.Subscription.Subscribe)
+		require.Equal(t, []string{"TR_a"}, state.Subscription.TrackSids)
+		require.Equal(t, []string{"TR_c"}, state.TrackSidsDisabled)
+	})
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/rtc/dynacast"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+)
+
+var _ types.MediaTrack = (*BridgedTrack)(nil)
+
+type BridgedTrackParams struct {
+	Source              types.MediaTrack
+	ParticipantID       livekit.ParticipantID
+	ParticipantIdentity livekit.ParticipantIdentity
+	ParticipantVersion  uint32
+	ReceiverConfig      ReceiverConfig
+	SubscriberConfig    DirectionConfig
+	AudioConfig         sfu.AudioConfig
+	DynacastPauseDelay  time.Duration
+	Telemetry           telemetry.TelemetryService
+	Logger              logger.Logger
+}
+
+// BridgedTrack republishes a track of another room on this node. It shares the receivers
+// of the source track, subscribers in both rooms are fed by the same sfu.TrackReceiver fan-out.
+// Max subscribed qualities are reported to the source the same way a remote node reports them.
+type BridgedTrack struct {
+	params BridgedTrackParams
+
+	*MediaTrackReceiver
+
+	dynacastManager *dynacast.DynacastManager
+}
+
+func NewBridgedTrack(params BridgedTrackParams) *BridgedTrack {
+	ti := params.Source.ToProto()
+	ti.Sid = guid.New(utils.TrackPrefix)
+
+	t := &BridgedTrack{
+		params: params,
+	}
+	t.MediaTrackReceiver = NewMediaTrackReceiver(MediaTrackReceiverParams{
+		MediaTrack:          t,
+		IsRelayed:           false,
+		ParticipantID:       params.ParticipantID,
+		ParticipantIdentity: params.ParticipantIdentity,
+		ParticipantVersion:  params.ParticipantVersion,
+		ReceiverConfig:      params.ReceiverConfig,
+		SubscriberConfig:    params.SubscriberConfig,
+		AudioConfig:         params.AudioConfig,
+		Telemetry:           params.Telemetry,
+		Logger:              params.Logger,
+	}, ti)
+
+	if notifier, ok := params.Source.(types.LocalMediaTrack); ok && ti.Type == livekit.TrackType_VIDEO {
+		t.dynacastManager = dynacast.NewDynacastManager(dynacast.DynacastManagerParams{
+			DynacastPauseDelay: params.DynacastPauseDelay,
+			Logger:             params.Logger,
+		})
+		t.MediaTrackReceiver.OnSetupReceiver(func(mime mime.MimeType) {
+			t.dynacastManager.AddCodec(mime)
+		})
+		t.MediaTrackReceiver.OnSubscriberMaxQualityChange(
+			func(subscriberID livekit.ParticipantID, mimeType mime.MimeType, layer int32) {
+				t.dynacastManager.NotifySubscriberMaxQuality(
+					subscriberID,
+					mimeType,
+					buffer.SpatialLayerToVideoQuality(layer, t.MediaTrackReceiver.TrackInfo()),
+				)
+			},
+		)
+		t.dynacastManager.OnSubscribedMaxQualityChange(func(_ []*livekit.SubscribedCodec, maxSubscribedQualities []types.SubscribedCodecQuality) {
+			notifier.NotifySubscriberNodeMaxQuality(t.nodeID(), maxSubscribedQualities)
+		})
+	}
+
+	t.syncReceivers()
+	params.Source.AddOnClose(func(_ bool) {
+		t.Close(false)
+	})
+	return t
+}
+
+// SourceID is the ID of the track being forwarded
+func (t *BridgedTrack) SourceID() livekit.TrackID {
+	return t.params.Source.ID()
+}
+
+// SyncSource picks up changes of the source track, i. e. mute state, layers and receivers of late codecs
+func (t *BridgedTrack) SyncSource() {
+	ti := t.params.Source.ToProto()
+	ti.Sid = string(t.ID())
+	t.MediaTrackReceiver.UpdateTrackInfo(ti)
+	if ti.Muted != t.IsMuted() {
+		t.SetMuted(ti.Muted)
+	}
+	t.syncReceivers()
+}
+
+func (t *BridgedTrack) syncReceivers() {
+	existing := make(map[mime.MimeType]bool)
+	for _, r := range t.MediaTrackReceiver.Receivers() {
+		existing[r.Mime()] = true
+	}
+	for priority, r := range t.params.Source.Receivers() {
+		if !existing[r.Mime()] {
+			t.MediaTrackReceiver.SetupReceiver(r, priority, "")
+		}
+	}
+}
+
+func (t *BridgedTrack) nodeID() livekit.NodeID {
+	// qualities are reported to the source keyed by the bridged track, as if it were a subscriber node
+	return livekit.NodeID(t.ID())
+}
+
+func (t *BridgedTrack) ToProto() *livekit.TrackInfo {
+	return t.MediaTrackReceiver.TrackInfoClone()
+}
+
+func (t *BridgedTrack) SetMuted(muted bool) {
+	if !muted && t.dynacastManager != nil {
+		t.dynacastManager.ForceUpdate()
+	}
+
+	t.MediaTrackReceiver.SetMuted(muted)
+}
+
+func (t *BridgedTrack) OnTrackSubscribed() {}
+
+func (t *BridgedTrack) Close(isExpectedToResume bool) {
+	t.MediaTrackReceiver.SetClosing()
+	if t.dynacastManager != nil {
+		t.dynacastManager.Close()
+
+		if notifier, ok := t.params.Source.(types.LocalMediaTrack); ok {
+			var qualities []types.SubscribedCodecQuality
+			for _, r := range t.MediaTrackReceiver.Receivers() {
+				qualities = append(qualities, types.SubscribedCodecQuality{CodecMime: r.Mime(), Quality: livekit.VideoQuality_OFF})
+			}
+			notifier.NotifySubscriberNodeMaxQuality(t.nodeID(), qualities)
+		}
+	}
+	// receivers belong to the source track, only the subscriptions of this track are removed
+	t.MediaTrackReceiver.ClearAllReceivers(isExpectedToResume)
+	t.MediaTrackReceiver.Close(isExpectedToResume)
+}
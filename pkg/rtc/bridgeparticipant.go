@@ -0,0 +1,311 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	BridgeAttributeSourceRoom          = "lk.bridge.source_room"
+	BridgeAttributeSourceIdentity      = "lk.bridge.source_identity"
+	BridgeAttributeDestinationRoom     = "lk.bridge.destination_room"
+	bridgeParticipantIdentityPrefix    = "bridge_"
+	bridgeParticipantIdentitySeparator = "_"
+)
+
+var _ types.Participant = (*BridgeParticipant)(nil)
+
+// BridgeParticipantIdentity is the identity of the virtual participant forwarding tracks of publisher
+// in its room to destination room. The same identity is used in both rooms.
+func BridgeParticipantIdentity(publisher livekit.ParticipantIdentity, destination livekit.RoomName) livekit.ParticipantIdentity {
+	return livekit.ParticipantIdentity(bridgeParticipantIdentityPrefix + string(publisher) + bridgeParticipantIdentitySeparator + string(destination))
+}
+
+type BridgeParticipantParams struct {
+	Identity         livekit.ParticipantIdentity
+	Name             string
+	Kind             livekit.ParticipantInfo_Kind
+	Attributes       map[string]string
+	VersionGenerator utils.TimedVersionGenerator
+	Logger           logger.Logger
+}
+
+// BridgeParticipant is a virtual participant without a transport. It represents a room bridge
+// in the participant list of a room, as the publisher of forwarded tracks in the destination room
+// and as a non-publishing member in the source room.
+type BridgeParticipant struct {
+	params      BridgeParticipantParams
+	id          livekit.ParticipantID
+	connectedAt time.Time
+
+	lock         sync.RWMutex
+	version      uint32
+	timedVersion utils.TimedVersion
+	state        livekit.ParticipantInfo_State
+	tracks       map[livekit.TrackID]types.MediaTrack
+	onClose      []func(*BridgeParticipant)
+
+	onSourceTrackUpdated func(track types.MediaTrack)
+}
+
+func NewBridgeParticipant(params BridgeParticipantParams) *BridgeParticipant {
+	p := &BridgeParticipant{
+		params:      params,
+		id:          livekit.ParticipantID(guid.New(utils.ParticipantPrefix)),
+		connectedAt: time.Now(),
+		state:       livekit.ParticipantInfo_ACTIVE,
+		tracks:      make(map[livekit.TrackID]types.MediaTrack),
+	}
+	p.timedVersion.Update(params.VersionGenerator.Next())
+	return p
+}
+
+func (p *BridgeParticipant) ID() livekit.ParticipantID {
+	return p.id
+}
+
+func (p *BridgeParticipant) Identity() livekit.ParticipantIdentity {
+	return p.params.Identity
+}
+
+func (p *BridgeParticipant) State() livekit.ParticipantInfo_State {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.state
+}
+
+func (p *BridgeParticipant) ConnectedAt() time.Time {
+	return p.connectedAt
+}
+
+func (p *BridgeParticipant) CloseReason() types.ParticipantCloseReason {
+	return types.ParticipantCloseReasonNone
+}
+
+func (p *BridgeParticipant) Kind() livekit.ParticipantInfo_Kind {
+	return p.params.Kind
+}
+
+func (p *BridgeParticipant) IsRecorder() bool {
+	return false
+}
+
+func (p *BridgeParticipant) IsDependent() bool {
+	return false
+}
+
+func (p *BridgeParticipant) IsAgent() bool {
+	return false
+}
+
+func (p *BridgeParticipant) CanSkipBroadcast() bool {
+	return false
+}
+
+func (p *BridgeParticipant) Version() utils.TimedVersion {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.timedVersion
+}
+
+func (p *BridgeParticipant) ToProto() *livekit.ParticipantInfo {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	pi := &livekit.ParticipantInfo{
+		Sid:         string(p.id),
+		Identity:    string(p.params.Identity),
+		Name:        p.params.Name,
+		State:       p.state,
+		JoinedAt:    p.connectedAt.Unix(),
+		JoinedAtMs:  p.connectedAt.UnixMilli(),
+		Version:     p.version,
+		Attributes:  p.params.Attributes,
+		IsPublisher: len(p.tracks) != 0,
+		Kind:        p.params.Kind,
+		Permission: &livekit.ParticipantPermission{
+			CanPublish: true,
+		},
+	}
+	for _, t := range p.tracks {
+		pi.Tracks = append(pi.Tracks, t.ToProto())
+	}
+	return pi
+}
+
+func (p *BridgeParticipant) IsPublisher() bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return len(p.tracks) != 0
+}
+
+func (p *BridgeParticipant) GetPublishedTrack(trackID livekit.TrackID) types.MediaTrack {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.tracks[trackID]
+}
+
+func (p *BridgeParticipant) GetPublishedTracks() []types.MediaTrack {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return maps.Values(p.tracks)
+}
+
+// AddPublishedTrack adds a forwarded track, returns false when the participant is already closed
+func (p *BridgeParticipant) AddPublishedTrack(track types.MediaTrack) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.state == livekit.ParticipantInfo_DISCONNECTED {
+		return false
+	}
+	p.tracks[track.ID()] = track
+	p.updateVersionLocked()
+	return true
+}
+
+func (p *BridgeParticipant) RemovePublishedTrack(track types.MediaTrack, isExpectedToResume bool, shouldClose bool) {
+	p.lock.Lock()
+	delete(p.tracks, track.ID())
+	p.updateVersionLocked()
+	p.lock.Unlock()
+
+	if shouldClose {
+		track.Close(isExpectedToResume)
+	}
+}
+
+// MarkUpdated bumps the version after a forwarded track changed
+func (p *BridgeParticipant) MarkUpdated() {
+	p.lock.Lock()
+	p.updateVersionLocked()
+	p.lock.Unlock()
+}
+
+func (p *BridgeParticipant) updateVersionLocked() {
+	p.version++
+	p.timedVersion.Update(p.params.VersionGenerator.Next())
+}
+
+func (p *BridgeParticipant) GetAudioLevel() (level float64, active bool) {
+	for _, t := range p.GetPublishedTracks() {
+		if t.Source() != livekit.TrackSource_MICROPHONE {
+			continue
+		}
+		if tl, ta := t.GetAudioLevel(); ta {
+			active = true
+			if tl > level {
+				level = tl
+			}
+		}
+	}
+	return
+}
+
+func (p *BridgeParticipant) HasPermission(_ livekit.TrackID, _ livekit.ParticipantIdentity) bool {
+	// permissions of the source publisher were checked when the bridge was set up
+	return true
+}
+
+func (p *BridgeParticipant) Hidden() bool {
+	return false
+}
+
+func (p *BridgeParticipant) IsClosed() bool {
+	return p.State() == livekit.ParticipantInfo_DISCONNECTED
+}
+
+func (p *BridgeParticipant) OnClose(f func(*BridgeParticipant)) {
+	p.lock.Lock()
+	p.onClose = append(p.onClose, f)
+	p.lock.Unlock()
+}
+
+// OnSourceTrackUpdated is invoked when a track of a participant in the same room changed,
+// the bridge uses it to mirror changes of the tracks it forwards
+func (p *BridgeParticipant) OnSourceTrackUpdated(f func(track types.MediaTrack)) {
+	p.lock.Lock()
+	p.onSourceTrackUpdated = f
+	p.lock.Unlock()
+}
+
+func (p *BridgeParticipant) sourceTrackUpdated(track types.MediaTrack) {
+	p.lock.RLock()
+	onSourceTrackUpdated := p.onSourceTrackUpdated
+	p.lock.RUnlock()
+
+	if onSourceTrackUpdated != nil {
+		onSourceTrackUpdated(track)
+	}
+}
+
+func (p *BridgeParticipant) Close(_ bool, reason types.ParticipantCloseReason, isExpectedToResume bool) error {
+	p.lock.Lock()
+	if p.state == livekit.ParticipantInfo_DISCONNECTED {
+		p.lock.Unlock()
+		return nil
+	}
+	p.state = livekit.ParticipantInfo_DISCONNECTED
+	p.updateVersionLocked()
+	tracks := maps.Values(p.tracks)
+	p.tracks = make(map[livekit.TrackID]types.MediaTrack)
+	onClose := p.onClose
+	p.lock.Unlock()
+
+	p.params.Logger.Infow("closing bridge participant", "reason", reason.String())
+	for _, t := range tracks {
+		t.Close(isExpectedToResume)
+	}
+	for _, f := range onClose {
+		f(p)
+	}
+	return nil
+}
+
+func (p *BridgeParticipant) SubscriptionPermission() (*livekit.SubscriptionPermission, utils.TimedVersion) {
+	return nil, 0
+}
+
+func (p *BridgeParticipant) UpdateSubscriptionPermission(
+	_ *livekit.SubscriptionPermission,
+	_ utils.TimedVersion,
+	_ func(participantID livekit.ParticipantID) types.LocalParticipant,
+) error {
+	return nil
+}
+
+func (p *BridgeParticipant) DebugInfo() map[string]interface{} {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return map[string]interface{}{
+		"ID":       p.id,
+		"Identity": p.params.Identity,
+		"State":    p.state.String(),
+		"Kind":     p.params.Kind.String(),
+		"Tracks":   maps.Keys(p.tracks),
+	}
+}
+
+func (p *BridgeParticipant) OnMetrics(_ func(types.Participant, *livekit.DataPacket)) {}
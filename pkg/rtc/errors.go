@@ -20,11 +20,15 @@ import (
 
 var (
 	ErrRoomClosed               = errors.New("room has already closed")
+	ErrRoomNotStarted           = errors.New("room has not started yet")
 	ErrParticipantSessionClosed = errors.New("participant session is already closed")
 	ErrPermissionDenied         = errors.New("no permissions to access the room")
 	ErrMaxParticipantsExceeded  = errors.New("room has exceeded its max participants")
 	ErrLimitExceeded            = errors.New("node has exceeded its configured limit")
 	ErrAlreadyJoined            = errors.New("a participant with the same identity is already in the room")
+	ErrParticipantNotFound      = errors.New("participant is not in the room")
+	ErrCannotMoveParticipant    = errors.New("agent and recorder participants cannot be moved")
+	ErrParticipantNotWaiting    = errors.New("participant is not waiting in the lobby")
 	ErrDataChannelUnavailable   = errors.New("data channel is not available")
 	ErrDataChannelBufferFull    = errors.New("data channel buffer is full")
 	ErrTransportFailure         = errors.New("transport failure")
@@ -41,6 +45,7 @@ var (
 	ErrNoSubscribePermission     = errors.New("participant is not given permission to subscribe to tracks")
 	ErrTrackNotFound             = errors.New("track cannot be found")
 	ErrTrackNotBound             = errors.New("track not bound")
+	ErrTrackNotRelayable         = errors.New("track cannot be relayed")
 	ErrSubscriptionLimitExceeded = errors.New("participant has exceeded its subscription limit")
 
 	ErrNoSubscribeMetricsPermission = errors.New("participant is not given permission to subscribe to metrics")
@@ -161,6 +161,7 @@ func NewMediaTrackReceiver(params MediaTrackReceiverParams, ti *livekit.TrackInf
 		IsRelayed:        params.IsRelayed,
 		ReceiverConfig:   params.ReceiverConfig,
 		SubscriberConfig: params.SubscriberConfig,
+		AudioScorer:      params.AudioConfig.QualityScorer,
 		Telemetry:        params.Telemetry,
 		Logger:           params.Logger,
 	})
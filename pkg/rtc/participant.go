@@ -48,6 +48,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/bwe"
 	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/sfu/pacer"
@@ -144,6 +145,7 @@ type ParticipantParams struct {
 	GetParticipantInfo             func(pID livekit.ParticipantID) *livekit.ParticipantInfo
 	GetRegionSettings              func(ip string) *livekit.RegionSettings
 	GetSubscriberForwarderState    func(p types.LocalParticipant) (map[livekit.TrackID]*livekit.RTPForwarderState, error)
+	RecordRoomEvent                func(event *RoomEvent)
 	DisableSupervisor              bool
 	ReconnectOnPublicationError    bool
 	ReconnectOnSubscriptionError   bool
@@ -1204,6 +1206,13 @@ func (p *ParticipantImpl) SetMigrateState(s types.MigrateState) {
 	p.migrateState.Store(s)
 	p.dirty.Store(true)
 
+	switch {
+	case s == types.MigrateStateSync:
+		p.recordRoomEvent(&RoomEvent{Type: RoomEventMigrationStarted})
+	case s == types.MigrateStateComplete && preState == types.MigrateStateSync:
+		p.recordRoomEvent(&RoomEvent{Type: RoomEventMigrationCompleted, Details: map[string]string{"tracks": strconv.Itoa(len(migratedTracks))}})
+	}
+
 	switch s {
 	case types.MigrateStateSync:
 		p.TransportManager.ProcessPendingPublisherOffer()
@@ -1347,8 +1356,11 @@ func (p *ParticipantImpl) VerifySubscribeParticipantInfo(pID livekit.Participant
 		return
 	}
 
-	if f := p.params.GetParticipantInfo; f != nil {
-		if info := f(pID); info != nil {
+	p.lock.RLock()
+	getParticipantInfo := p.params.GetParticipantInfo
+	p.lock.RUnlock()
+	if getParticipantInfo != nil {
+		if info := getParticipantInfo(pID); info != nil {
 			_ = p.SendParticipantUpdate([]*livekit.ParticipantInfo{info})
 		}
 	}
@@ -1475,6 +1487,10 @@ func (h SubscriberTransportHandler) OnStreamStateChange(update *streamallocator.
 	return h.p.onStreamStateChange(update)
 }
 
+func (h SubscriberTransportHandler) OnCongestionStateChange(fromState bwe.CongestionState, toState bwe.CongestionState, estimatedAvailableChannelCapacity int64) {
+	h.p.onCongestionStateChange(fromState, toState, estimatedAvailableChannelCapacity)
+}
+
 func (h SubscriberTransportHandler) OnInitialConnected() {
 	h.p.onSubscriberInitialConnected()
 }
@@ -1601,7 +1617,7 @@ func (p *ParticipantImpl) setupSubscriptionManager() {
 	p.SubscriptionManager = NewSubscriptionManager(SubscriptionManagerParams{
 		Participant:              p,
 		Logger:                   p.subLogger.WithoutSampler(),
-		TrackResolver:            p.params.TrackResolver,
+		TrackResolver:            p.resolveTrack,
 		Telemetry:                p.params.Telemetry,
 		OnTrackSubscribed:        p.onTrackSubscribed,
 		OnTrackUnsubscribed:      p.onTrackUnsubscribed,
@@ -1612,6 +1628,13 @@ func (p *ParticipantImpl) setupSubscriptionManager() {
 	})
 }
 
+func (p *ParticipantImpl) resolveTrack(sub types.LocalParticipant, trackID livekit.TrackID) types.MediaResolverResult {
+	p.lock.RLock()
+	trackResolver := p.params.TrackResolver
+	p.lock.RUnlock()
+	return trackResolver(sub, trackID)
+}
+
 func (p *ParticipantImpl) MetricsCollectorTimeToCollectMetrics() {
 	publisherRTT, ok := p.TransportManager.GetPublisherRTT()
 	if ok {
@@ -2118,9 +2141,16 @@ func (p *ParticipantImpl) onStreamStateChange(update *streamallocator.StreamStat
 	streamStateUpdate := &livekit.StreamStateUpdate{}
 	for _, streamStateInfo := range update.StreamStates {
 		state := livekit.StreamState_ACTIVE
+		eventType := RoomEventStreamResumed
 		if streamStateInfo.State == streamallocator.StreamStatePaused {
 			state = livekit.StreamState_PAUSED
+			eventType = RoomEventStreamPaused
 		}
+		p.recordRoomEvent(&RoomEvent{
+			Type:    eventType,
+			TrackID: string(streamStateInfo.TrackID),
+			Details: map[string]string{"publisher_id": string(streamStateInfo.ParticipantID)},
+		})
 		streamStateUpdate.StreamStates = append(streamStateUpdate.StreamStates, &livekit.StreamStateInfo{
 			ParticipantSid: string(streamStateInfo.ParticipantID),
 			TrackSid:       string(streamStateInfo.TrackID),
@@ -2135,6 +2165,17 @@ func (p *ParticipantImpl) onStreamStateChange(update *streamallocator.StreamStat
 	})
 }
 
+func (p *ParticipantImpl) onCongestionStateChange(fromState bwe.CongestionState, toState bwe.CongestionState, estimatedAvailableChannelCapacity int64) {
+	p.recordRoomEvent(&RoomEvent{
+		Type: RoomEventCongestionChanged,
+		Details: map[string]string{
+			"from":         fromState.String(),
+			"to":           toState.String(),
+			"capacity_bps": strconv.FormatInt(estimatedAvailableChannelCapacity, 10),
+		},
+	})
+}
+
 func (p *ParticipantImpl) onSubscribedMaxQualityChange(
 	trackID livekit.TrackID,
 	trackInfo *livekit.TrackInfo,
@@ -2182,6 +2223,16 @@ func (p *ParticipantImpl) onSubscribedMaxQualityChange(
 		SubscribedCodecs:    subscribedQualities,
 	}
 
+	maxQualities := make(map[string]string, len(maxSubscribedQualities))
+	for _, maxSubscribedQuality := range maxSubscribedQualities {
+		maxQualities[maxSubscribedQuality.CodecMime.String()] = maxSubscribedQuality.Quality.String()
+	}
+	p.recordRoomEvent(&RoomEvent{
+		Type:    RoomEventSubscribedQualities,
+		TrackID: string(trackID),
+		Details: maxQualities,
+	})
+
 	p.pubLogger.Debugw(
 		"sending max subscribed quality",
 		"trackID", trackID,
@@ -2914,6 +2965,8 @@ func (p *ParticipantImpl) IssueFullReconnect(reason types.ParticipantCloseReason
 		scr = types.SignallingCloseReasonFullReconnectDataChannelError
 	case types.ParticipantCloseReasonNegotiateFailed:
 		scr = types.SignallingCloseReasonFullReconnectNegotiateFailed
+	case types.ParticipantCloseReasonNodeDraining:
+		scr = types.SignallingCloseReasonFullReconnectNodeDraining
 	}
 	p.CloseSignalConnection(scr)
 
@@ -2921,6 +2974,42 @@ func (p *ParticipantImpl) IssueFullReconnect(reason types.ParticipantCloseReason
 	p.Close(false, reason, false)
 }
 
+func (p *ParticipantImpl) MoveToRoom(params types.MoveToRoomParams) {
+	// fire onClose callback for the room being left, a new one is set up by the destination room
+	p.lock.Lock()
+	onClose := p.onClose
+	p.onClose = nil
+	p.lock.Unlock()
+	if onClose != nil {
+		onClose(p)
+	}
+
+	// tracks of the previous room cannot be resolved anymore
+	p.SubscriptionManager.UnsubscribeAll()
+
+	p.lock.Lock()
+	p.params.TrackResolver = params.TrackResolver
+	p.params.GetParticipantInfo = params.GetParticipantInfo
+
+	grants := p.grants.Load().Clone()
+	grants.Video.Room = string(params.RoomName)
+	p.grants.Store(grants)
+	p.dirty.Store(true)
+	p.lock.Unlock()
+
+	p.params.Logger.Infow("moved to room", "destinationRoom", params.RoomName)
+}
+
+func (p *ParticipantImpl) recordRoomEvent(event *RoomEvent) {
+	if p.params.RecordRoomEvent == nil {
+		return
+	}
+
+	event.ParticipantIdentity = string(p.params.Identity)
+	event.ParticipantID = string(p.params.SID)
+	p.params.RecordRoomEvent(event)
+}
+
 func (p *ParticipantImpl) onPublicationError(trackID livekit.TrackID) {
 	if p.params.ReconnectOnPublicationError {
 		p.pubLogger.Infow("issuing full reconnect on publication error", "trackID", trackID)
@@ -2946,6 +3035,13 @@ func (p *ParticipantImpl) onSubscriptionError(trackID livekit.TrackID, fatal boo
 		},
 	})
 
+	p.recordRoomEvent(&RoomEvent{
+		Type:    RoomEventSubscriptionFailed,
+		TrackID: string(trackID),
+		Reason:  err.Error(),
+		Details: map[string]string{"fatal": strconv.FormatBool(fatal)},
+	})
+
 	if p.params.ReconnectOnSubscriptionError && fatal {
 		p.subLogger.Infow("issuing full reconnect on subscription error", "trackID", trackID)
 		p.IssueFullReconnect(types.ParticipantCloseReasonSubscriptionError)
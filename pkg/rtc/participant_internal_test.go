@@ -242,6 +242,31 @@ func TestOutOfOrderUpdates(t *testing.T) {
 	require.Equal(t, "second update", sent.GetUpdate().Participants[0].Metadata)
 }
 
+func TestFullReconnectOnNodeDraining(t *testing.T) {
+	p := newParticipantForTestWithOpts("test", &participantOpts{
+		protocolVersion: 13,
+		clientInfo:      &livekit.ClientInfo{Address: "81.2.69.160"},
+	})
+	regions := &livekit.RegionSettings{Regions: []*livekit.RegionInfo{{Region: "eu-central", Url: "wss://eu.example.com"}}}
+	var clientIP string
+	p.params.GetRegionSettings = func(ip string) *livekit.RegionSettings {
+		clientIP = ip
+		return regions
+	}
+	sink := p.getResponseSink().(*routingfakes.FakeMessageSink)
+
+	p.IssueFullReconnect(types.ParticipantCloseReasonNodeDraining)
+
+	// regions are looked up for the address of the client
+	require.Equal(t, "81.2.69.160", clientIP)
+	require.Equal(t, 1, sink.WriteMessageCallCount())
+	leave := sink.WriteMessageArgsForCall(0).(*livekit.SignalResponse).GetLeave()
+	require.Equal(t, livekit.LeaveRequest_RECONNECT, leave.Action)
+	require.Equal(t, livekit.DisconnectReason_SERVER_SHUTDOWN, leave.Reason)
+	require.True(t, proto.Equal(regions, leave.Regions))
+	require.Equal(t, 1, sink.CloseCallCount())
+}
+
 // after disconnection, things should continue to function and not panic
 func TestDisconnectTiming(t *testing.T) {
 	t.Run("Negotiate doesn't panic after channel closed", func(t *testing.T) {
@@ -0,0 +1,177 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// name of the token claim overriding the room policy for a participant, values are durations, i. e.
+	// "policy": {"max_duration": "90m", "idle_timeout": "5m", "warning_before": "1m"}.
+	// unlike attributes, claims are signed with the token and cannot be changed by the participant
+	ParticipantPolicyClaim = "policy"
+
+	// topic of data messages warning a participant about an upcoming removal
+	ParticipantPolicyWarningTopic = "lk.policy.warning"
+
+	participantPolicyCheckInterval = time.Second
+)
+
+type ParticipantPolicyWarning struct {
+	Reason           string `json:"reason"`
+	RemainingSeconds int64  `json:"remaining_seconds"`
+}
+
+type participantPolicyClaim struct {
+	MaxDuration   string `json:"max_duration,omitempty"`
+	IdleTimeout   string `json:"idle_timeout,omitempty"`
+	WarningBefore string `json:"warning_before,omitempty"`
+}
+
+// participantPolicyFromClaim reads the per token overrides of a participant's policy,
+// fields not set in the claim are left at zero and fall back to the room policy
+func participantPolicyFromClaim(data json.RawMessage, l logger.Logger) config.ParticipantPolicyConfig {
+	var policy config.ParticipantPolicyConfig
+	if len(data) == 0 {
+		return policy
+	}
+
+	var claim participantPolicyClaim
+	if err := json.Unmarshal(data, &claim); err != nil {
+		l.Warnw("ignoring invalid participant policy claim", err)
+		return policy
+	}
+
+	for key, field := range map[string]struct {
+		value string
+		d     *time.Duration
+	}{
+		"max_duration":   {claim.MaxDuration, &policy.MaxDuration},
+		"idle_timeout":   {claim.IdleTimeout, &policy.IdleTimeout},
+		"warning_before": {claim.WarningBefore, &policy.WarningBefore},
+	} {
+		if field.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(field.value)
+		if err != nil || parsed < 0 {
+			l.Warnw("ignoring invalid participant policy claim", err, "field", key, "value", field.value)
+			continue
+		}
+		*field.d = parsed
+	}
+	return policy
+}
+
+func mergeParticipantPolicy(room config.ParticipantPolicyConfig, token config.ParticipantPolicyConfig) config.ParticipantPolicyConfig {
+	if token.MaxDuration != 0 {
+		room.MaxDuration = token.MaxDuration
+	}
+	if token.IdleTimeout != 0 {
+		room.IdleTimeout = token.IdleTimeout
+	}
+	if token.WarningBefore != 0 {
+		room.WarningBefore = token.WarningBefore
+	}
+	return room
+}
+
+// participantPolicyState tracks a participant session against its policy
+type participantPolicyState struct {
+	tokenPolicy config.ParticipantPolicyConfig
+	joinedAt    time.Time
+	lastActive  time.Time
+	warned      map[types.ParticipantCloseReason]bool
+}
+
+func newParticipantPolicyState(tokenPolicy config.ParticipantPolicyConfig, joinedAt time.Time) *participantPolicyState {
+	return &participantPolicyState{
+		tokenPolicy: tokenPolicy,
+		joinedAt:    joinedAt,
+		lastActive:  joinedAt,
+		warned:      make(map[types.ParticipantCloseReason]bool),
+	}
+}
+
+func (s *participantPolicyState) markActive(at time.Time) {
+	if at.After(s.lastActive) {
+		s.lastActive = at
+	}
+	// a new idle period gets a new warning
+	delete(s.warned, types.ParticipantCloseReasonIdleTimeout)
+}
+
+// evaluate returns the reason to remove the participant, or a warning when a removal is coming up
+func (s *participantPolicyState) evaluate(roomPolicy config.ParticipantPolicyConfig, now time.Time) (types.ParticipantCloseReason, *ParticipantPolicyWarning) {
+	policy := mergeParticipantPolicy(roomPolicy, s.tokenPolicy)
+
+	var warning *ParticipantPolicyWarning
+	for _, limit := range []struct {
+		reason  types.ParticipantCloseReason
+		name    string
+		timeout time.Duration
+		since   time.Time
+	}{
+		{types.ParticipantCloseReasonMaxDurationExceeded, "max_duration", policy.MaxDuration, s.joinedAt},
+		{types.ParticipantCloseReasonIdleTimeout, "idle_timeout", policy.IdleTimeout, s.lastActive},
+	} {
+		if limit.timeout <= 0 {
+			continue
+		}
+		remaining := limit.since.Add(limit.timeout).Sub(now)
+		if remaining <= 0 {
+			return limit.reason, nil
+		}
+		if warning == nil && policy.WarningBefore > 0 && remaining <= policy.WarningBefore && !s.warned[limit.reason] {
+			s.warned[limit.reason] = true
+			warning = &ParticipantPolicyWarning{
+				Reason:           limit.name,
+				RemainingSeconds: int64((remaining + time.Second - 1) / time.Second),
+			}
+		}
+	}
+	return types.ParticipantCloseReasonNone, warning
+}
+
+func sendParticipantPolicyWarning(p types.LocalParticipant, warning *ParticipantPolicyWarning) error {
+	payload, err := json.Marshal(warning)
+	if err != nil {
+		return err
+	}
+	topic := ParticipantPolicyWarningTopic
+	data, err := proto.Marshal(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   &topic,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return p.SendDataPacket(livekit.DataPacket_RELIABLE, data)
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func TestParticipantPolicyFromClaim(t *testing.T) {
+	policy := participantPolicyFromClaim(json.RawMessage(`{"max_duration":"1h30m","idle_timeout":"invalid","other":"5m"}`), logger.GetLogger())
+	require.Equal(t, config.ParticipantPolicyConfig{MaxDuration: 90 * time.Minute}, policy)
+
+	require.Equal(t, config.ParticipantPolicyConfig{}, participantPolicyFromClaim(nil, logger.GetLogger()))
+	require.Equal(t, config.ParticipantPolicyConfig{}, participantPolicyFromClaim(json.RawMessage(`"90m"`), logger.GetLogger()))
+}
+
+func TestParticipantPolicyEvaluate(t *testing.T) {
+	joinedAt := time.Now()
+	roomPolicy := config.ParticipantPolicyConfig{
+		MaxDuration:   time.Hour,
+		IdleTimeout:   10 * time.Minute,
+		WarningBefore: time.Minute,
+	}
+
+	t.Run("max duration", func(t *testing.T) {
+		s := newParticipantPolicyState(config.ParticipantPolicyConfig{}, joinedAt)
+		s.markActive(joinedAt.Add(58 * time.Minute))
+
+		reason, warning := s.evaluate(roomPolicy, joinedAt.Add(58*time.Minute))
+		require.Equal(t, types.ParticipantCloseReasonNone, reason)
+		require.Nil(t, warning)
+
+		reason, warning = s.evaluate(roomPolicy, joinedAt.Add(59*time.Minute+30*time.Second))
+		require.Equal(t, types.ParticipantCloseReasonNone, reason)
+		require.Equal(t, &ParticipantPolicyWarning{Reason: "max_duration", RemainingSeconds: 30}, warning)
+
+		// warned only once
+		_, warning = s.evaluate(roomPolicy, joinedAt.Add(59*time.Minute+40*time.Second))
+		require.Nil(t, warning)
+
+		reason, _ = s.evaluate(roomPolicy, joinedAt.Add(time.Hour))
+		require.Equal(t, types.ParticipantCloseReasonMaxDurationExceeded, reason)
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		s := newParticipantPolicyState(config.ParticipantPolicyConfig{}, joinedAt)
+
+		_, warning := s.evaluate(roomPolicy, joinedAt.Add(9*time.Minute+30*time.Second))
+		require.Equal(t, "idle_timeout", warning.Reason)
+
+		// activity resets the timeout and the warning
+		s.markActive(joinedAt.Add(9*time.Minute + 40*time.Second))
+		reason, warning := s.evaluate(roomPolicy, joinedAt.Add(12*time.Minute))
+		require.Equal(t, types.ParticipantCloseReasonNone, reason)
+		require.Nil(t, warning)
+
+		_, warning = s.evaluate(roomPolicy, joinedAt.Add(19*time.Minute))
+		require.Equal(t, "idle_timeout", warning.Reason)
+
+		reason, _ = s.evaluate(roomPolicy, joinedAt.Add(20*time.Minute))
+		require.Equal(t, types.ParticipantCloseReasonIdleTimeout, reason)
+	})
+
+	t.Run("token overrides room policy", func(t *testing.T) {
+		s := newParticipantPolicyState(config.ParticipantPolicyConfig{MaxDuration: 5 * time.Minute}, joinedAt)
+
+		reason, _ := s.evaluate(roomPolicy, joinedAt.Add(5*time.Minute))
+		require.Equal(t, types.ParticipantCloseReasonMaxDurationExceeded, reason)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := newParticipantPolicyState(config.ParticipantPolicyConfig{}, joinedAt)
+
+		reason, warning := s.evaluate(config.ParticipantPolicyConfig{}, joinedAt.Add(24*time.Hour))
+		require.Equal(t, types.ParticipantCloseReasonNone, reason)
+		require.Nil(t, warning)
+	})
+}
+
+func TestRoomParticipantPolicy(t *testing.T) {
+	t.Run("participant is warned and removed", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+		p := rm.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+
+		rm.SetParticipantPolicy(config.ParticipantPolicyConfig{
+			MaxDuration:   2 * time.Second,
+			WarningBefore: 2 * time.Second,
+		})
+
+		require.Eventually(t, func() bool {
+			return p.SendDataPacketCallCount() > 0
+		}, 3*time.Second, 50*time.Millisecond)
+		_, data := p.SendDataPacketArgsForCall(0)
+		dp := &livekit.DataPacket{}
+		require.NoError(t, proto.Unmarshal(data, dp))
+		require.Equal(t, ParticipantPolicyWarningTopic, dp.GetUser().GetTopic())
+		warning := &ParticipantPolicyWarning{}
+		require.NoError(t, json.Unmarshal(dp.GetUser().GetPayload(), warning))
+		require.Equal(t, "max_duration", warning.Reason)
+
+		require.Eventually(t, func() bool {
+			return rm.GetParticipant("p0") == nil
+		}, 4*time.Second, 50*time.Millisecond)
+		require.Equal(t, 1, p.CloseCallCount())
+		_, reason, _ := p.CloseArgsForCall(0)
+		require.Equal(t, types.ParticipantCloseReasonMaxDurationExceeded, reason)
+	})
+}
@@ -0,0 +1,227 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/rtc/dynacast"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/livekit-server/pkg/sfu/relay"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+)
+
+var (
+	_ types.MediaTrack = (*RelayedTrack)(nil)
+	_ relay.Handler    = (*RelayedTrack)(nil)
+)
+
+type RelayedTrackParams struct {
+	// track as published on the origin node
+	Source                     *livekit.TrackInfo
+	SessionID                  uint64
+	SourceAddress              *net.UDPAddr
+	Codecs                     []webrtc.RTPCodecParameters
+	HeaderExtensions           []webrtc.RTPHeaderExtensionParameter
+	Transport                  *relay.Transport
+	ParticipantID              livekit.ParticipantID
+	ParticipantIdentity        livekit.ParticipantIdentity
+	ReceiverConfig             ReceiverConfig
+	SubscriberConfig           DirectionConfig
+	AudioConfig                sfu.AudioConfig
+	PLIThrottleConfig          sfu.PLIThrottleConfig
+	StreamTrackerManagerConfig sfu.StreamTrackerManagerConfig
+	DynacastPauseDelay         time.Duration
+	Telemetry                  telemetry.TelemetryService
+	Logger                     logger.Logger
+}
+
+// RelayedTrack publishes a track of a room on another node. The origin node sends the packets of the track
+// through a TrackRelay, they are fed into a RelayReceiver per codec. Max subscribed qualities are reported
+// back to the origin node, which applies them to the publisher as for a subscriber node.
+type RelayedTrack struct {
+	params RelayedTrackParams
+
+	*MediaTrackReceiver
+
+	receivers       []*sfu.RelayReceiver
+	dynacastManager *dynacast.DynacastManager
+
+	lock                         sync.Mutex
+	onSubscribedMaxQualityChange func(qualities []types.SubscribedCodecQuality)
+}
+
+func NewRelayedTrack(params RelayedTrackParams) *RelayedTrack {
+	ti := utils.CloneProto(params.Source)
+	ti.Sid = guid.New(utils.TrackPrefix)
+
+	t := &RelayedTrack{
+		params: params,
+	}
+	t.MediaTrackReceiver = NewMediaTrackReceiver(MediaTrackReceiverParams{
+		MediaTrack:          t,
+		IsRelayed:           true,
+		ParticipantID:       params.ParticipantID,
+		ParticipantIdentity: params.ParticipantIdentity,
+		ReceiverConfig:      params.ReceiverConfig,
+		SubscriberConfig:    params.SubscriberConfig,
+		AudioConfig:         params.AudioConfig,
+		Telemetry:           params.Telemetry,
+		Logger:              params.Logger,
+	}, ti)
+
+	if ti.Type == livekit.TrackType_VIDEO {
+		t.dynacastManager = dynacast.NewDynacastManager(dynacast.DynacastManagerParams{
+			DynacastPauseDelay: params.DynacastPauseDelay,
+			Logger:             params.Logger,
+		})
+		t.MediaTrackReceiver.OnSetupReceiver(func(mime mime.MimeType) {
+			t.dynacastManager.AddCodec(mime)
+		})
+		t.MediaTrackReceiver.OnSubscriberMaxQualityChange(
+			func(subscriberID livekit.ParticipantID, mimeType mime.MimeType, layer int32) {
+				t.dynacastManager.NotifySubscriberMaxQuality(
+					subscriberID,
+					mimeType,
+					buffer.SpatialLayerToVideoQuality(layer, t.MediaTrackReceiver.TrackInfo()),
+				)
+			},
+		)
+		t.dynacastManager.OnSubscribedMaxQualityChange(func(_ []*livekit.SubscribedCodec, maxSubscribedQualities []types.SubscribedCodecQuality) {
+			t.lock.Lock()
+			onSubscribedMaxQualityChange := t.onSubscribedMaxQualityChange
+			t.lock.Unlock()
+
+			if onSubscribedMaxQualityChange != nil {
+				onSubscribedMaxQualityChange(maxSubscribedQualities)
+			}
+		})
+	}
+
+	for priority, codec := range params.Codecs {
+		r := sfu.NewRelayReceiver(
+			sfu.RelayReceiverParams{
+				TrackInfo:                  ti,
+				StreamID:                   PackStreamID(params.ParticipantID, livekit.TrackID(ti.Sid)),
+				Codec:                      codec,
+				HeaderExtensions:           params.HeaderExtensions,
+				PacketBufferSizeVideo:      params.ReceiverConfig.PacketBufferSizeVideo,
+				PacketBufferSizeAudio:      params.ReceiverConfig.PacketBufferSizeAudio,
+				StreamTrackerManagerConfig: params.StreamTrackerManagerConfig,
+				Logger:                     LoggerWithCodecMime(params.Logger, mime.NormalizeMimeType(codec.MimeType)),
+				OnKeyFrameRequest: func(layer int32) {
+					_ = params.Transport.WriteKeyFrameRequest(params.SourceAddress, params.SessionID, priority, layer)
+				},
+			},
+			sfu.WithPliThrottleConfig(params.PLIThrottleConfig),
+			sfu.WithAudioConfig(params.AudioConfig),
+			sfu.WithLoadBalanceThreshold(20),
+			sfu.WithStreamTrackers(),
+		)
+		t.receivers = append(t.receivers, r)
+		t.MediaTrackReceiver.SetupReceiver(r, priority, "")
+	}
+
+	params.Transport.SetSession(params.SessionID, t)
+	return t
+}
+
+// SourceID is the ID of the track on the origin node
+func (t *RelayedTrack) SourceID() livekit.TrackID {
+	return livekit.TrackID(t.params.Source.Sid)
+}
+
+func (t *RelayedTrack) SessionID() uint64 {
+	return t.params.SessionID
+}
+
+// OnSubscribedMaxQualityChange is called with qualities to be sent to the origin node
+func (t *RelayedTrack) OnSubscribedMaxQualityChange(f func(qualities []types.SubscribedCodecQuality)) {
+	t.lock.Lock()
+	t.onSubscribedMaxQualityChange = f
+	t.lock.Unlock()
+}
+
+// UpdateSource picks up changes of the track on the origin node, i. e. mute state and layers
+func (t *RelayedTrack) UpdateSource(source *livekit.TrackInfo) {
+	ti := utils.CloneProto(source)
+	ti.Sid = string(t.ID())
+	t.MediaTrackReceiver.UpdateTrackInfo(ti)
+	if ti.Muted != t.IsMuted() {
+		t.SetMuted(ti.Muted)
+	}
+}
+
+// NotifySubscriberNodeMaxQuality applies qualities subscribed on a node this track is relayed on to,
+// they are passed on to the origin node along with the ones subscribed here
+func (t *RelayedTrack) NotifySubscriberNodeMaxQuality(nodeID livekit.NodeID, qualities []types.SubscribedCodecQuality) {
+	if t.dynacastManager != nil {
+		t.dynacastManager.NotifySubscriberNodeMaxQuality(nodeID, qualities)
+	}
+}
+
+func (t *RelayedTrack) HandleRTP(codec int, layer int32, pkt []byte) {
+	if codec < len(t.receivers) {
+		t.receivers[codec].WriteRTP(layer, pkt)
+	}
+}
+
+func (t *RelayedTrack) HandleSenderReport(codec int, layer int32, sr *livekit.RTCPSenderReportState) {
+	if codec < len(t.receivers) {
+		t.receivers[codec].SetSenderReport(layer, sr)
+	}
+}
+
+func (t *RelayedTrack) HandleKeyFrameRequest(_ int, _ int32) {}
+
+func (t *RelayedTrack) ToProto() *livekit.TrackInfo {
+	return t.MediaTrackReceiver.TrackInfoClone()
+}
+
+func (t *RelayedTrack) SetMuted(muted bool) {
+	if !muted && t.dynacastManager != nil {
+		t.dynacastManager.ForceUpdate()
+	}
+
+	t.MediaTrackReceiver.SetMuted(muted)
+}
+
+func (t *RelayedTrack) OnTrackSubscribed() {}
+
+func (t *RelayedTrack) Close(isExpectedToResume bool) {
+	t.params.Transport.RemoveSession(t.params.SessionID)
+
+	t.MediaTrackReceiver.SetClosing()
+	if t.dynacastManager != nil {
+		t.dynacastManager.Close()
+	}
+	for _, r := range t.receivers {
+		r.Close()
+	}
+	t.MediaTrackReceiver.ClearAllReceivers(isExpectedToResume)
+	t.MediaTrackReceiver.Close(isExpectedToResume)
+}
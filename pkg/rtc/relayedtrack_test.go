@@ -0,0 +1,256 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/livekit-server/pkg/sfu/relay"
+	"github.com/livekit/livekit-server/pkg/telemetry/telemetryfakes"
+)
+
+func TestRelayedTrack(t *testing.T) {
+	t.Run("packets are relayed on to another node", func(t *testing.T) {
+		// publisher node -> relayed track -> track relay -> relayed track on a third node
+		tP, tA, tB := newTestRelayTransport(t), newTestRelayTransport(t), newTestRelayTransport(t)
+		publisher := newTestRelaySource(t, tP)
+
+		opus := webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2},
+			PayloadType:        111,
+		}
+		rtA := newTestRelayedTrack(t, tA, publisher.sessionID, tP.LocalAddr(), livekit.TrackType_AUDIO, opus)
+		tr := newTestTrackRelay(t, rtA, tA)
+		rtB := newTestRelayedTrack(t, tB, tr.SessionID(), tA.LocalAddr(), livekit.TrackType_AUDIO, tr.Codecs()...)
+		tr.Start(tB.LocalAddr(), rtB.ID())
+		require.Equal(t, rtB.ID(), tr.DestinationTrackID())
+		require.Equal(t, rtA.SourceID(), rtB.SourceID())
+
+		sub := newTestRelaySubscriber()
+		require.NoError(t, rtB.Receivers()[0].AddDownTrack(sub))
+
+		for sn := uint16(1); sn <= 3; sn++ {
+			require.NoError(t, tP.WriteRTP(tA.LocalAddr(), publisher.sessionID, 0, 0, testRelayRTPPacket(t, sn)))
+		}
+		for sn := uint16(1); sn <= 3; sn++ {
+			require.Equal(t, sn, sub.next(t))
+		}
+	})
+
+	t.Run("subscribed qualities and key frame requests reach the origin node", func(t *testing.T) {
+		tP, tA, tB := newTestRelayTransport(t), newTestRelayTransport(t), newTestRelayTransport(t)
+		publisher := newTestRelaySource(t, tP)
+
+		vp8 := webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000},
+			PayloadType:        96,
+		}
+		rtA := newTestRelayedTrack(t, tA, publisher.sessionID, tP.LocalAddr(), livekit.TrackType_VIDEO, vp8)
+		qualities := make(chan []types.SubscribedCodecQuality, 10)
+		rtA.OnSubscribedMaxQualityChange(func(q []types.SubscribedCodecQuality) {
+			qualities <- q
+		})
+		tr := newTestTrackRelay(t, rtA, tA)
+		rtB := newTestRelayedTrack(t, tB, tr.SessionID(), tA.LocalAddr(), livekit.TrackType_VIDEO, tr.Codecs()...)
+		tr.Start(tB.LocalAddr(), rtB.ID())
+
+		// qualities subscribed on the far node are reported to the origin node as for a subscriber node
+		tr.UpdateSubscribedQualities([]types.SubscribedCodecQuality{{CodecMime: mime.MimeTypeVP8, Quality: livekit.VideoQuality_MEDIUM}})
+		select {
+		case q := <-qualities:
+			require.Equal(t, []types.SubscribedCodecQuality{{CodecMime: mime.MimeTypeVP8, Quality: livekit.VideoQuality_MEDIUM}}, q)
+		case <-time.After(time.Second):
+			t.Fatal("subscribed quality not reported")
+		}
+
+		// a key frame request of the far node is passed on to the publisher once the layer is received
+		require.NoError(t, tP.WriteRTP(tA.LocalAddr(), publisher.sessionID, 0, 0, testRelayRTPPacket(t, 1)))
+		require.Eventually(t, func() bool {
+			_ = tB.WriteKeyFrameRequest(tA.LocalAddr(), tr.SessionID(), 0, 0)
+			return publisher.keyFrameRequests.Load() > 0
+		}, time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("closing the source closes the relay", func(t *testing.T) {
+		tP, tA := newTestRelayTransport(t), newTestRelayTransport(t)
+		publisher := newTestRelaySource(t, tP)
+
+		opus := webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2},
+			PayloadType:        111,
+		}
+		rtA := newTestRelayedTrack(t, tA, publisher.sessionID, tP.LocalAddr(), livekit.TrackType_AUDIO, opus)
+		tr := newTestTrackRelay(t, rtA, tA)
+		closed := make(chan struct{})
+		tr.OnClose(func(_ *TrackRelay) { close(closed) })
+
+		rtA.Close(false)
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatal("relay not closed")
+		}
+		require.True(t, tr.IsClosed())
+	})
+}
+
+func newTestRelayTransport(t *testing.T) *relay.Transport {
+	tr, err := relay.NewTransport("127.0.0.1", 0, []byte("secret"), logger.GetLogger())
+	require.NoError(t, err)
+	t.Cleanup(tr.Close)
+	return tr
+}
+
+func newTestRelayedTrack(
+	t *testing.T,
+	transport *relay.Transport,
+	sessionID uint64,
+	source *net.UDPAddr,
+	kind livekit.TrackType,
+	codecs ...webrtc.RTPCodecParameters,
+) *RelayedTrack {
+	ti := &livekit.TrackInfo{Sid: "TR_source", Type: kind, Name: "track"}
+	if kind == livekit.TrackType_VIDEO {
+		ti.Width, ti.Height = 1280, 720
+		ti.Layers = []*livekit.VideoLayer{{Quality: livekit.VideoQuality_HIGH, Width: 1280, Height: 720}}
+	}
+	rt := NewRelayedTrack(RelayedTrackParams{
+		Source:              ti,
+		SessionID:           sessionID,
+		SourceAddress:       source,
+		Codecs:              codecs,
+		Transport:           transport,
+		ParticipantID:       "PA_relay",
+		ParticipantIdentity: "relay",
+		ReceiverConfig:      ReceiverConfig{PacketBufferSizeVideo: 500, PacketBufferSizeAudio: 200},
+		Telemetry:           &telemetryfakes.FakeTelemetryService{},
+		Logger:              logger.GetLogger(),
+	})
+	t.Cleanup(func() { rt.Close(false) })
+	return rt
+}
+
+func newTestTrackRelay(t *testing.T, source types.MediaTrack, transport *relay.Transport) *TrackRelay {
+	tr, err := NewTrackRelay(TrackRelayParams{
+		Source:          source,
+		DestinationNode: "ND_destination",
+		Transport:       transport,
+		Logger:          logger.GetLogger(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(tr.Close)
+	return tr
+}
+
+func testRelayRTPPacket(t *testing.T, sn uint16) []byte {
+	pkt := rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    111,
+			SequenceNumber: sn,
+			Timestamp:      uint32(sn) * 960,
+			SSRC:           1234,
+		},
+		Payload: []byte{0x10, 0x00, 0x00, 0x00},
+	}
+	b, err := pkt.Marshal()
+	require.NoError(t, err)
+	return b
+}
+
+// ---------------------------------------------------------
+
+// testRelaySource stands in for the publisher on the origin node
+type testRelaySource struct {
+	sessionID        uint64
+	keyFrameRequests atomic.Int32
+}
+
+func newTestRelaySource(t *testing.T, transport *relay.Transport) *testRelaySource {
+	s := &testRelaySource{}
+	s.sessionID = transport.NewSession(s)
+	t.Cleanup(func() { transport.RemoveSession(s.sessionID) })
+	return s
+}
+
+func (s *testRelaySource) HandleRTP(_ int, _ int32, _ []byte) {}
+
+func (s *testRelaySource) HandleSenderReport(_ int, _ int32, _ *livekit.RTCPSenderReportState) {}
+
+func (s *testRelaySource) HandleKeyFrameRequest(_ int, _ int32) {
+	s.keyFrameRequests.Inc()
+}
+
+// ---------------------------------------------------------
+
+// testRelaySubscriber is attached to a relay receiver in place of a down track
+type testRelaySubscriber struct {
+	packets chan uint16
+	closed  atomic.Bool
+}
+
+func newTestRelaySubscriber() *testRelaySubscriber {
+	return &testRelaySubscriber{packets: make(chan uint16, 10)}
+}
+
+func (s *testRelaySubscriber) next(t *testing.T) uint16 {
+	select {
+	case sn := <-s.packets:
+		return sn
+	case <-time.After(time.Second):
+		t.Fatal("no packet forwarded")
+		return 0
+	}
+}
+
+func (s *testRelaySubscriber) UpTrackLayersChange()                           {}
+func (s *testRelaySubscriber) UpTrackBitrateAvailabilityChange()              {}
+func (s *testRelaySubscriber) UpTrackMaxPublishedLayerChange(_ int32)         {}
+func (s *testRelaySubscriber) UpTrackMaxTemporalLayerSeenChange(_ int32)      {}
+func (s *testRelaySubscriber) UpTrackBitrateReport(_ []int32, _ sfu.Bitrates) {}
+func (s *testRelaySubscriber) Resync()                                        {}
+func (s *testRelaySubscriber) SetReceiver(_ sfu.TrackReceiver)                {}
+func (s *testRelaySubscriber) ID() string                                     { return "DT_test" }
+func (s *testRelaySubscriber) SubscriberID() livekit.ParticipantID            { return "PA_test" }
+func (s *testRelaySubscriber) IsClosed() bool                                 { return s.closed.Load() }
+func (s *testRelaySubscriber) Close()                                         { s.closed.Store(true) }
+
+func (s *testRelaySubscriber) WriteRTP(p *buffer.ExtPacket, _ int32) error {
+	s.packets <- p.Packet.SequenceNumber
+	return nil
+}
+
+func (s *testRelaySubscriber) HandleRTCPSenderReportData(
+	_ webrtc.PayloadType,
+	_ bool,
+	_ int32,
+	_ *livekit.RTCPSenderReportState,
+) error {
+	return nil
+}
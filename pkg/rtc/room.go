@@ -16,6 +16,7 @@ package rtc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"slices"
@@ -24,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pion/webrtc/v4"
 	"go.uber.org/atomic"
 	"golang.org/x/exp/maps"
 	"google.golang.org/protobuf/proto"
@@ -127,6 +129,9 @@ type Room struct {
 	agentParticpants          map[livekit.ParticipantIdentity]*agentJob
 	bufferFactory             *buffer.FactoryOfBufferFactory
 
+	// virtual participants of room bridges, identity -> BridgeParticipant
+	bridgeParticipants map[livekit.ParticipantIdentity]*BridgeParticipant
+
 	// batch update participant info for non-publishers
 	batchedUpdates   map[livekit.ParticipantIdentity]*participantUpdate
 	batchedUpdatesMu sync.Mutex
@@ -136,18 +141,40 @@ type Room struct {
 	trailer []byte
 
 	onParticipantChanged func(p types.LocalParticipant)
+	onTrackChanged       func(p types.Participant, track types.MediaTrack)
 	onRoomUpdated        func()
 	onClose              func()
+	onEvent              atomic.Pointer[func(event *RoomEvent)]
 
 	simulationLock                                 sync.Mutex
 	disconnectSignalOnResumeParticipants           map[livekit.ParticipantIdentity]time.Time
 	disconnectSignalOnResumeNoMessagesParticipants map[livekit.ParticipantIdentity]*disconnectSignalOnResumeNoMessages
 
 	userPacketDeduper *UserPacketDeduper
+
+	policyLock        sync.Mutex
+	participantPolicy config.ParticipantPolicyConfig
+	policyStates      map[livekit.ParticipantIdentity]*participantPolicyState
+
+	scheduleLock   sync.Mutex
+	schedule       *RoomScheduleWindow
+	scheduleWarned bool
+
+	lobbyLock        sync.Mutex
+	lobbyEnabled     bool
+	heldParticipants map[livekit.ParticipantIdentity]*heldParticipant
+
+	// subscription permissions of a restored snapshot, protected by lock
+	restoredPermissions map[livekit.ParticipantIdentity]*livekit.SubscriptionPermission
 }
 
 type ParticipantOptions struct {
 	AutoSubscribe bool
+	// policy claim of the participant's token, overrides the room policy
+	PolicyClaim json.RawMessage
+
+	// recovered participants were hosted by a node that failed, their state is synced by the signal node
+	recovered bool
 }
 
 type agentDispatch struct {
@@ -266,6 +293,7 @@ func NewRoom(
 		participantRequestSources:            make(map[livekit.ParticipantIdentity]routing.MessageSource),
 		hasPublished:                         make(map[livekit.ParticipantIdentity]bool),
 		agentParticpants:                     make(map[livekit.ParticipantIdentity]*agentJob),
+		bridgeParticipants:                   make(map[livekit.ParticipantIdentity]*BridgeParticipant),
 		bufferFactory:                        buffer.NewFactoryOfBufferFactory(config.Receiver.PacketBufferSizeVideo, config.Receiver.PacketBufferSizeAudio),
 		batchedUpdates:                       make(map[livekit.ParticipantIdentity]*participantUpdate),
 		closed:                               make(chan struct{}),
@@ -273,6 +301,10 @@ func NewRoom(
 		disconnectSignalOnResumeParticipants: make(map[livekit.ParticipantIdentity]time.Time),
 		disconnectSignalOnResumeNoMessagesParticipants: make(map[livekit.ParticipantIdentity]*disconnectSignalOnResumeNoMessages),
 		userPacketDeduper: NewUserPacketDeduper(),
+		participantPolicy: roomConfig.ParticipantPolicy,
+		policyStates:      make(map[livekit.ParticipantIdentity]*participantPolicyState),
+		lobbyEnabled:      roomConfig.Lobby,
+		heldParticipants:  make(map[livekit.ParticipantIdentity]*heldParticipant),
 	}
 
 	if r.protoRoom.EmptyTimeout == 0 {
@@ -296,6 +328,8 @@ func NewRoom(
 	go r.connectionQualityWorker()
 	go r.changeUpdateWorker()
 	go r.simulationCleanupWorker()
+	go r.participantPolicyWorker()
+	go r.scheduleWorker()
 
 	return r
 }
@@ -359,7 +393,13 @@ func (r *Room) GetParticipantCount() int {
 }
 
 func (r *Room) GetActiveSpeakers() []*livekit.SpeakerInfo {
-	participants := r.GetParticipants()
+	var participants []types.Participant
+	for _, p := range r.GetParticipants() {
+		participants = append(participants, p)
+	}
+	for _, p := range r.GetBridgeParticipants() {
+		participants = append(participants, p)
+	}
 	speakers := make([]*livekit.SpeakerInfo, 0, len(participants))
 	for _, p := range participants {
 		level, active := p.GetAudioLevel()
@@ -425,7 +465,102 @@ func (r *Room) Join(participant types.LocalParticipant, requestSource routing.Me
 		return ErrRoomClosed
 	}
 
-	if r.participants[participant.Identity()] != nil {
+	if err := r.canJoinLocked(participant); err != nil {
+		return err
+	}
+
+	awaitingStart, err := r.checkScheduleForJoin(participant, true)
+	if err != nil {
+		return err
+	}
+	awaitingAdmission := r.needsAdmission(participant)
+	if awaitingStart || awaitingAdmission {
+		r.holdParticipant(participant, awaitingAdmission, awaitingStart)
+	}
+
+	if r.FirstJoinedAt() == 0 && !participant.IsDependent() {
+		r.joinedAt.Store(time.Now().Unix())
+	}
+
+	r.setParticipantCallbacks(participant)
+
+	r.launchTargetAgents(maps.Values(r.agentDispatches), participant, livekit.JobType_JT_PARTICIPANT)
+
+	r.Logger.Debugw("new participant joined",
+		"pID", participant.ID(),
+		"participant", participant.Identity(),
+		"clientInfo", logger.Proto(participant.GetClientInfo()),
+		"options", opts,
+		"numParticipants", len(r.participants),
+	)
+
+	if participant.IsRecorder() && !r.protoRoom.ActiveRecording {
+		r.protoRoom.ActiveRecording = true
+		r.protoProxy.MarkDirty(true)
+	} else {
+		r.protoProxy.MarkDirty(false)
+	}
+
+	r.participants[participant.Identity()] = participant
+	r.participantOpts[participant.Identity()] = opts
+	r.participantRequestSources[participant.Identity()] = requestSource
+	r.addParticipantPolicyState(participant, opts)
+	if awaitingAdmission {
+		r.notifyWaitingLocked(participant)
+	}
+
+	if r.onParticipantChanged != nil {
+		r.onParticipantChanged(participant)
+	}
+	r.RecordEvent(&RoomEvent{
+		Type:                RoomEventParticipantJoined,
+		ParticipantIdentity: string(participant.Identity()),
+		ParticipantID:       string(participant.ID()),
+		Details: map[string]string{
+			"kind":    participant.Kind().String(),
+			"sdk":     participant.GetClientInfo().GetSdk().String(),
+			"version": participant.GetClientInfo().GetVersion(),
+		},
+	})
+
+	time.AfterFunc(time.Minute, func() {
+		if !participant.Verify() {
+			r.RemoveParticipant(participant.Identity(), participant.ID(), types.ParticipantCloseReasonJoinTimeout)
+		}
+	})
+
+	if opts != nil && opts.recovered {
+		// the client already joined, the session is migrated once its state is synced
+		return nil
+	}
+
+	joinResponse := r.createJoinResponseLocked(participant, iceServers)
+	if err := participant.SendJoinResponse(joinResponse); err != nil {
+		prometheus.ServiceOperationCounter.WithLabelValues("participant_join", "error", "send_response").Add(1)
+		return err
+	}
+
+	participant.SetMigrateState(types.MigrateStateComplete)
+
+	if participant.SubscriberAsPrimary() {
+		// initiates sub connection as primary
+		if participant.ProtocolVersion().SupportFastStart() {
+			go func() {
+				r.subscribeToExistingTracks(participant)
+				participant.Negotiate(true)
+			}()
+		} else {
+			participant.Negotiate(true)
+		}
+	}
+
+	prometheus.ServiceOperationCounter.WithLabelValues("participant_join", "success", "").Add(1)
+
+	return nil
+}
+
+func (r *Room) canJoinLocked(participant types.LocalParticipant) error {
+	if r.participants[participant.Identity()] != nil || r.bridgeParticipants[participant.Identity()] != nil {
 		return ErrAlreadyJoined
 	}
 	if r.protoRoom.MaxParticipants > 0 && !participant.IsDependent() {
@@ -439,11 +574,10 @@ func (r *Room) Join(participant types.LocalParticipant, requestSource routing.Me
 			return ErrMaxParticipantsExceeded
 		}
 	}
+	return nil
+}
 
-	if r.FirstJoinedAt() == 0 && !participant.IsDependent() {
-		r.joinedAt.Store(time.Now().Unix())
-	}
-
+func (r *Room) setParticipantCallbacks(participant types.LocalParticipant) {
 	participant.OnStateChange(func(p types.LocalParticipant, state livekit.ParticipantInfo_State) {
 		if r.onParticipantChanged != nil {
 			r.onParticipantChanged(p)
@@ -519,61 +653,17 @@ func (r *Room) Join(participant types.LocalParticipant, requestSource routing.Me
 			}, true)
 		}
 	})
+}
 
-	r.launchTargetAgents(maps.Values(r.agentDispatches), participant, livekit.JobType_JT_PARTICIPANT)
-
-	r.Logger.Debugw("new participant joined",
-		"pID", participant.ID(),
-		"participant", participant.Identity(),
-		"clientInfo", logger.Proto(participant.GetClientInfo()),
-		"options", opts,
-		"numParticipants", len(r.participants),
-	)
-
-	if participant.IsRecorder() && !r.protoRoom.ActiveRecording {
-		r.protoRoom.ActiveRecording = true
-		r.protoProxy.MarkDirty(true)
-	} else {
-		r.protoProxy.MarkDirty(false)
-	}
-
-	r.participants[participant.Identity()] = participant
-	r.participantOpts[participant.Identity()] = opts
-	r.participantRequestSources[participant.Identity()] = requestSource
-
-	if r.onParticipantChanged != nil {
-		r.onParticipantChanged(participant)
-	}
-
-	time.AfterFunc(time.Minute, func() {
-		if !participant.Verify() {
-			r.RemoveParticipant(participant.Identity(), participant.ID(), types.ParticipantCloseReasonJoinTimeout)
-		}
-	})
-
-	joinResponse := r.createJoinResponseLocked(participant, iceServers)
-	if err := participant.SendJoinResponse(joinResponse); err != nil {
-		prometheus.ServiceOperationCounter.WithLabelValues("participant_join", "error", "send_response").Add(1)
-		return err
-	}
-
-	participant.SetMigrateState(types.MigrateStateComplete)
-
-	if participant.SubscriberAsPrimary() {
-		// initiates sub connection as primary
-		if participant.ProtocolVersion().SupportFastStart() {
-			go func() {
-				r.subscribeToExistingTracks(participant)
-				participant.Negotiate(true)
-			}()
-		} else {
-			participant.Negotiate(true)
-		}
-	}
-
-	prometheus.ServiceOperationCounter.WithLabelValues("participant_join", "success", "").Add(1)
-
-	return nil
+func (r *Room) clearParticipantCallbacks(p types.LocalParticipant) {
+	p.OnTrackUpdated(nil)
+	p.OnTrackPublished(nil)
+	p.OnTrackUnpublished(nil)
+	p.OnStateChange(nil)
+	p.OnParticipantUpdate(nil)
+	p.OnDataPacket(nil)
+	p.OnMetrics(nil)
+	p.OnSubscribeStatusChanged(nil)
 }
 
 func (r *Room) ReplaceParticipantRequestSource(identity livekit.ParticipantIdentity, reqSource routing.MessageSource) {
@@ -591,6 +681,16 @@ func (r *Room) GetParticipantRequestSource(identity livekit.ParticipantIdentity)
 	return r.participantRequestSources[identity]
 }
 
+// GetParticipantPolicyClaim returns the policy claim of the participant's token, to be kept in refreshed tokens
+func (r *Room) GetParticipantPolicyClaim(identity livekit.ParticipantIdentity) json.RawMessage {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if opts := r.participantOpts[identity]; opts != nil {
+		return opts.PolicyClaim
+	}
+	return nil
+}
+
 func (r *Room) ResumeParticipant(
 	p types.LocalParticipant,
 	requestSource routing.MessageSource,
@@ -655,6 +755,35 @@ func (r *Room) ResumeParticipant(
 	return nil
 }
 
+// RecoverParticipant joins a participant re-created after the node hosting it failed. Instead of a join,
+// the client is sent a reconnect response, its state is then synced as with a migration.
+func (r *Room) RecoverParticipant(
+	p types.LocalParticipant,
+	requestSource routing.MessageSource,
+	opts *ParticipantOptions,
+	iceServers []*livekit.ICEServer,
+	reason livekit.ReconnectReason,
+) error {
+	opts.recovered = true
+	if err := r.Join(p, requestSource, opts, iceServers); err != nil {
+		return err
+	}
+
+	if err := p.HandleReconnectAndSendResponse(reason, &livekit.ReconnectResponse{
+		IceServers:          iceServers,
+		ClientConfiguration: p.GetClientConfiguration(),
+	}); err != nil {
+		return err
+	}
+
+	if err := p.SendParticipantUpdate(r.getOtherParticipantInfo("")); err != nil {
+		return err
+	}
+
+	_ = p.SendRoomUpdate(r.ToProto())
+	return nil
+}
+
 func (r *Room) RemoveParticipant(identity livekit.ParticipantIdentity, pID livekit.ParticipantID, reason types.ParticipantCloseReason) {
 	r.lock.Lock()
 	p, ok := r.participants[identity]
@@ -670,31 +799,7 @@ func (r *Room) RemoveParticipant(identity livekit.ParticipantIdentity, pID livek
 	}
 
 	agentJob := r.agentParticpants[identity]
-
-	delete(r.participants, identity)
-	delete(r.participantOpts, identity)
-	delete(r.participantRequestSources, identity)
-	delete(r.hasPublished, identity)
-	delete(r.agentParticpants, identity)
-	if !p.Hidden() {
-		r.protoRoom.NumParticipants--
-	}
-
-	immediateChange := false
-	if p.IsRecorder() {
-		activeRecording := false
-		for _, op := range r.participants {
-			if op.IsRecorder() {
-				activeRecording = true
-				break
-			}
-		}
-
-		if r.protoRoom.ActiveRecording != activeRecording {
-			r.protoRoom.ActiveRecording = activeRecording
-			immediateChange = true
-		}
-	}
+	immediateChange := r.deleteParticipantLocked(p)
 	r.lock.Unlock()
 	r.protoProxy.MarkDirty(immediateChange)
 
@@ -724,17 +829,16 @@ func (r *Room) RemoveParticipant(identity livekit.ParticipantIdentity, pID livek
 		}()
 	}
 
-	p.OnTrackUpdated(nil)
-	p.OnTrackPublished(nil)
-	p.OnTrackUnpublished(nil)
-	p.OnStateChange(nil)
-	p.OnParticipantUpdate(nil)
-	p.OnDataPacket(nil)
-	p.OnMetrics(nil)
-	p.OnSubscribeStatusChanged(nil)
+	r.clearParticipantCallbacks(p)
 
 	// close participant as well
 	_ = p.Close(true, reason, false)
+	r.RecordEvent(&RoomEvent{
+		Type:                RoomEventParticipantLeft,
+		ParticipantIdentity: string(p.Identity()),
+		ParticipantID:       string(p.ID()),
+		Reason:              p.CloseReason().String(),
+	})
 
 	r.leftAt.Store(time.Now().Unix())
 
@@ -746,6 +850,302 @@ func (r *Room) RemoveParticipant(identity livekit.ParticipantIdentity, pID livek
 	}
 }
 
+// MoveOutParticipant detaches a participant from the room without closing it, so that it can be moved
+// into another room. Published tracks are withdrawn and other participants see it leave.
+func (r *Room) MoveOutParticipant(identity livekit.ParticipantIdentity) (types.LocalParticipant, routing.MessageSource, *ParticipantOptions, error) {
+	r.lock.Lock()
+	p, ok := r.participants[identity]
+	if !ok {
+		r.lock.Unlock()
+		return nil, nil, nil, ErrParticipantNotFound
+	}
+	if p.IsDependent() || r.agentParticpants[identity] != nil {
+		r.lock.Unlock()
+		return nil, nil, nil, ErrCannotMoveParticipant
+	}
+
+	requestSource := r.participantRequestSources[identity]
+	opts := r.participantOpts[identity]
+	immediateChange := r.deleteParticipantLocked(p)
+	r.lock.Unlock()
+	r.protoProxy.MarkDirty(immediateChange)
+
+	r.clearParticipantCallbacks(p)
+
+	// tracks move along with the participant, subscribers in this room have to let go of them
+	subscribers := make(map[livekit.ParticipantID]types.LocalParticipant)
+	for _, op := range r.GetParticipants() {
+		subscribers[op.ID()] = op
+	}
+	for _, t := range p.GetPublishedTracks() {
+		r.trackManager.RemoveTrack(t)
+		for _, subID := range t.GetAllSubscribers() {
+			if op := subscribers[subID]; op != nil {
+				op.UnsubscribeFromTrack(t.ID())
+			}
+		}
+	}
+
+	// the moving participant sees everyone in this room leave
+	others := r.getOtherParticipantInfo(identity)
+	for _, pi := range others {
+		pi.State = livekit.ParticipantInfo_DISCONNECTED
+	}
+	if len(others) > 0 {
+		if err := p.SendParticipantUpdate(others); err != nil {
+			p.GetLogger().Warnw("could not send departure updates", err)
+		}
+	}
+
+	r.leftAt.Store(time.Now().Unix())
+
+	// and everyone in this room sees the moving participant leave
+	if !p.Hidden() {
+		pi := p.ToProto()
+		pi.State = livekit.ParticipantInfo_DISCONNECTED
+		r.sendParticipantUpdates(r.pushAndDequeueUpdates(pi, types.ParticipantCloseReasonMoveToRoom, true))
+	}
+
+	r.Logger.Infow("participant moved out", "participant", identity, "pID", p.ID())
+	return p, requestSource, opts, nil
+}
+
+// MoveInParticipant attaches a participant that was moved out of another room, re-using its transport.
+// Its published tracks are made available to this room and it is subscribed to existing tracks.
+func (r *Room) MoveInParticipant(participant types.LocalParticipant, requestSource routing.MessageSource, opts *ParticipantOptions) error {
+	r.lock.Lock()
+	if err := r.canMoveInLocked(participant); err != nil {
+		r.lock.Unlock()
+		return err
+	}
+
+	if r.FirstJoinedAt() == 0 && !participant.IsDependent() {
+		r.joinedAt.Store(time.Now().Unix())
+	}
+
+	r.setParticipantCallbacks(participant)
+
+	if participant.IsRecorder() && !r.protoRoom.ActiveRecording {
+		r.protoRoom.ActiveRecording = true
+		r.protoProxy.MarkDirty(true)
+	} else {
+		r.protoProxy.MarkDirty(false)
+	}
+
+	r.participants[participant.Identity()] = participant
+	r.participantOpts[participant.Identity()] = opts
+	r.participantRequestSources[participant.Identity()] = requestSource
+	r.addParticipantPolicyState(participant, opts)
+	r.lock.Unlock()
+
+	r.Logger.Infow("participant moved in",
+		"participant", participant.Identity(),
+		"pID", participant.ID(),
+		"numParticipants", r.GetParticipantCount(),
+	)
+
+	if err := participant.SendRoomUpdate(r.ToProto()); err != nil {
+		return err
+	}
+	if err := participant.SendParticipantUpdate(r.getOtherParticipantInfo(participant.Identity())); err != nil {
+		return err
+	}
+
+	r.broadcastParticipantState(participant, broadcastOptions{skipSource: true, immediate: true})
+	if r.onParticipantChanged != nil {
+		r.onParticipantChanged(participant)
+	}
+
+	for _, track := range participant.GetPublishedTracks() {
+		r.onTrackPublished(participant, track)
+	}
+
+	if participant.State() == livekit.ParticipantInfo_ACTIVE {
+		r.subscribeToExistingTracks(participant)
+	}
+	return nil
+}
+
+// CanMoveInParticipant checks whether a participant could be moved into the room, so that a move is
+// refused before the participant leaves its current room
+func (r *Room) CanMoveInParticipant(participant types.LocalParticipant) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.canMoveInLocked(participant)
+}
+
+func (r *Room) canMoveInLocked(participant types.LocalParticipant) error {
+	if r.IsClosed() {
+		return ErrRoomClosed
+	}
+	if err := r.canJoinLocked(participant); err != nil {
+		return err
+	}
+	if _, err := r.checkScheduleForJoin(participant, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Room) deleteParticipantLocked(p types.LocalParticipant) bool {
+	identity := p.Identity()
+	delete(r.participants, identity)
+	delete(r.participantOpts, identity)
+	delete(r.participantRequestSources, identity)
+	delete(r.hasPublished, identity)
+	delete(r.agentParticpants, identity)
+	r.policyLock.Lock()
+	delete(r.policyStates, identity)
+	r.policyLock.Unlock()
+	r.lobbyLock.Lock()
+	delete(r.heldParticipants, identity)
+	r.lobbyLock.Unlock()
+	if !p.Hidden() {
+		r.protoRoom.NumParticipants--
+	}
+
+	immediateChange := false
+	if p.IsRecorder() {
+		activeRecording := false
+		for _, op := range r.participants {
+			if op.IsRecorder() {
+				activeRecording = true
+				break
+			}
+		}
+
+		if r.protoRoom.ActiveRecording != activeRecording {
+			r.protoRoom.ActiveRecording = activeRecording
+			immediateChange = true
+		}
+	}
+	return immediateChange
+}
+
+// GetParticipantInfo returns the info of a participant or bridge participant in the room
+func (r *Room) GetParticipantInfo(pID livekit.ParticipantID) *livekit.ParticipantInfo {
+	if p := r.GetParticipantByID(pID); p != nil {
+		return p.ToProto()
+	}
+	for _, bp := range r.GetBridgeParticipants() {
+		if bp.ID() == pID {
+			return bp.ToProto()
+		}
+	}
+	return nil
+}
+
+func (r *Room) GetBridgeParticipant(identity livekit.ParticipantIdentity) *BridgeParticipant {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.bridgeParticipants[identity]
+}
+
+func (r *Room) GetBridgeParticipants() []*BridgeParticipant {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return maps.Values(r.bridgeParticipants)
+}
+
+// AddBridgeParticipant adds the virtual participant of a room bridge to the room
+func (r *Room) AddBridgeParticipant(bp *BridgeParticipant) error {
+	r.lock.Lock()
+	if r.IsClosed() {
+		r.lock.Unlock()
+		return ErrRoomClosed
+	}
+	if r.participants[bp.Identity()] != nil || r.bridgeParticipants[bp.Identity()] != nil {
+		r.lock.Unlock()
+		return ErrAlreadyJoined
+	}
+	r.bridgeParticipants[bp.Identity()] = bp
+	r.lock.Unlock()
+
+	r.Logger.Infow("bridge participant added", "participant", bp.Identity(), "pID", bp.ID(), "kind", bp.Kind())
+	r.protoProxy.MarkDirty(false)
+	r.broadcastBridgeParticipantState(bp)
+	return nil
+}
+
+// RemoveBridgeParticipant closes the virtual participant and withdraws its tracks from the room
+func (r *Room) RemoveBridgeParticipant(identity livekit.ParticipantIdentity, reason types.ParticipantCloseReason) {
+	r.lock.Lock()
+	bp, ok := r.bridgeParticipants[identity]
+	if ok {
+		delete(r.bridgeParticipants, identity)
+	}
+	r.lock.Unlock()
+	if !ok {
+		return
+	}
+
+	for _, t := range bp.GetPublishedTracks() {
+		r.trackManager.RemoveTrack(t)
+	}
+	_ = bp.Close(false, reason, false)
+
+	r.Logger.Infow("bridge participant removed", "participant", identity, "pID", bp.ID(), "reason", reason.String())
+	r.protoProxy.MarkDirty(false)
+	r.broadcastBridgeParticipantState(bp)
+}
+
+// PublishBridgedTrack makes a forwarded track, bridged or relayed from another node, available in the room
+// and subscribes existing participants to it
+func (r *Room) PublishBridgedTrack(identity livekit.ParticipantIdentity, track types.MediaTrack) error {
+	bp := r.GetBridgeParticipant(identity)
+	if bp == nil {
+		return ErrParticipantNotFound
+	}
+	if !bp.AddPublishedTrack(track) {
+		return ErrParticipantSessionClosed
+	}
+	r.trackManager.AddTrack(track, bp.Identity(), bp.ID())
+	r.broadcastBridgeParticipantState(bp)
+
+	r.lock.RLock()
+	for _, p := range r.participants {
+		if p.State() != livekit.ParticipantInfo_ACTIVE || !r.autoSubscribe(p) {
+			continue
+		}
+		p.SubscribeToTrack(track.ID())
+	}
+	r.lock.RUnlock()
+
+	if r.onTrackChanged != nil {
+		r.onTrackChanged(bp, track)
+	}
+	r.protoProxy.MarkDirty(false)
+	return nil
+}
+
+// UnpublishBridgedTrack withdraws a forwarded track from the room
+func (r *Room) UnpublishBridgedTrack(identity livekit.ParticipantIdentity, track types.MediaTrack) {
+	bp := r.GetBridgeParticipant(identity)
+	if bp == nil || bp.GetPublishedTrack(track.ID()) == nil {
+		return
+	}
+	r.trackManager.RemoveTrack(track)
+	bp.RemovePublishedTrack(track, false, true)
+	r.broadcastBridgeParticipantState(bp)
+	r.protoProxy.MarkDirty(false)
+}
+
+// UpdateBridgedTrack broadcasts changes of a forwarded track
+func (r *Room) UpdateBridgedTrack(identity livekit.ParticipantIdentity, track types.MediaTrack) {
+	if bp := r.GetBridgeParticipant(identity); bp != nil {
+		bp.MarkUpdated()
+		r.broadcastBridgeParticipantState(bp)
+		if r.onTrackChanged != nil {
+			r.onTrackChanged(bp, track)
+		}
+	}
+}
+
+func (r *Room) broadcastBridgeParticipantState(bp *BridgeParticipant) {
+	r.sendParticipantUpdates(r.pushAndDequeueUpdates(bp.ToProto(), bp.CloseReason(), true))
+}
+
 func (r *Room) UpdateSubscriptions(
 	participant types.LocalParticipant,
 	trackIDs []livekit.TrackID,
@@ -776,6 +1176,12 @@ func (r *Room) SyncState(participant types.LocalParticipant, state *livekit.Sync
 	pLogger := participant.GetLogger()
 	pLogger.Infow("setting sync state", "state", logger.Proto(state))
 
+	// a recovered participant has no state on this node yet, it is migrated from the synced one
+	if participant.MigrateState() == types.MigrateStateInit {
+		r.migrateRecoveredParticipant(participant, state)
+		return nil
+	}
+
 	shouldReconnect := false
 	pubTracks := state.GetPublishTracks()
 	existingPubTracks := participant.GetPublishedTracks()
@@ -827,6 +1233,38 @@ func (r *Room) SyncState(participant types.LocalParticipant, state *livekit.Sync
 	return nil
 }
 
+func (r *Room) migrateRecoveredParticipant(participant types.LocalParticipant, state *livekit.SyncState) {
+	var previousOffer, previousAnswer *webrtc.SessionDescription
+	if state.Offer != nil {
+		offer := FromProtoSessionDescription(state.Offer)
+		previousOffer = &offer
+	}
+	if state.Answer != nil {
+		answer := FromProtoSessionDescription(state.Answer)
+		previousAnswer = &answer
+	}
+	participant.SetMigrateInfo(previousOffer, previousAnswer, state.PublishTracks, state.DataChannels)
+	participant.SetMigrateState(types.MigrateStateSync)
+	if len(state.PublishTracks) == 0 {
+		participant.SetMigrateState(types.MigrateStateComplete)
+	}
+
+	for _, trackSid := range state.TrackSidsDisabled {
+		participant.UpdateSubscribedTrackSettings(livekit.TrackID(trackSid), &livekit.UpdateTrackSettings{Disabled: true})
+	}
+	if sub := state.GetSubscription(); sub != nil {
+		r.UpdateSubscriptions(
+			participant,
+			livekit.StringsAsIDs[livekit.TrackID](sub.TrackSids),
+			sub.ParticipantTracks,
+			sub.Subscribe,
+		)
+	}
+
+	// the client is connected to the failed node, it has to restart ICE to reach this one
+	participant.ICERestart(nil)
+}
+
 func (r *Room) UpdateSubscriptionPermission(participant types.LocalParticipant, subscriptionPermission *livekit.SubscriptionPermission) error {
 	if err := participant.UpdateSubscriptionPermission(subscriptionPermission, utils.TimedVersion(0), r.GetParticipantByID); err != nil {
 		return err
@@ -856,6 +1294,8 @@ func (r *Room) ResolveMediaTrackForSubscriber(sub types.LocalParticipant, trackI
 	// when publisher is not found, we will assume it doesn't have permission to access
 	if pub != nil {
 		res.HasPermission = IsParticipantExemptFromTrackPermissionsRestrictions(sub) || pub.HasPermission(trackID, sub.Identity())
+	} else if bp := r.GetBridgeParticipant(info.PublisherIdentity); bp != nil && bp.ID() == info.PublisherID {
+		res.HasPermission = bp.HasPermission(trackID, sub.Identity())
 	}
 
 	return res
@@ -923,6 +1363,9 @@ func (r *Room) Close(reason types.ParticipantCloseReason) {
 	for _, p := range r.GetParticipants() {
 		_ = p.Close(true, reason, false)
 	}
+	for _, bp := range r.GetBridgeParticipants() {
+		r.RemoveBridgeParticipant(bp.Identity(), reason)
+	}
 
 	r.protoProxy.Stop()
 
@@ -939,6 +1382,11 @@ func (r *Room) OnParticipantChanged(f func(participant types.LocalParticipant))
 	r.onParticipantChanged = f
 }
 
+// OnTrackChanged is called with tracks published or updated by participants, and tracks forwarded into the room
+func (r *Room) OnTrackChanged(f func(participant types.Participant, track types.MediaTrack)) {
+	r.onTrackChanged = f
+}
+
 func (r *Room) SendDataPacket(dp *livekit.DataPacket, kind livekit.DataPacket_Kind) {
 	r.onDataPacket(nil, kind, dp)
 }
@@ -1128,6 +1576,9 @@ func (r *Room) getOtherParticipantInfo(identity livekit.ParticipantIdentity) []*
 			pi = append(pi, p.ToProto())
 		}
 	}
+	for _, bp := range r.GetBridgeParticipants() {
+		pi = append(pi, bp.ToProto())
+	}
 
 	return pi
 }
@@ -1150,6 +1601,9 @@ func (r *Room) createJoinResponseLocked(participant types.LocalParticipant, iceS
 			otherParticipants = append(otherParticipants, p.ToProto())
 		}
 	}
+	for _, bp := range r.bridgeParticipants {
+		otherParticipants = append(otherParticipants, bp.ToProto())
+	}
 
 	iceConfig := participant.GetICEConfig()
 	hasICEFallback := iceConfig.GetPreferencePublisher() != livekit.ICECandidateType_ICT_NONE || iceConfig.GetPreferenceSubscriber() != livekit.ICECandidateType_ICT_NONE
@@ -1209,6 +1663,19 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 	}
 
 	r.trackManager.AddTrack(track, participant.Identity(), participant.ID())
+	if r.onTrackChanged != nil {
+		r.onTrackChanged(participant, track)
+	}
+	r.RecordEvent(&RoomEvent{
+		Type:                RoomEventTrackPublished,
+		ParticipantIdentity: string(participant.Identity()),
+		ParticipantID:       string(participant.ID()),
+		TrackID:             string(track.ID()),
+		Details: map[string]string{
+			"kind":   track.Kind().String(),
+			"source": track.Source().String(),
+		},
+	})
 
 	// launch jobs
 	r.lock.Lock()
@@ -1253,16 +1720,30 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 	}
 }
 
-func (r *Room) onTrackUpdated(p types.LocalParticipant, _ types.MediaTrack) {
+func (r *Room) onTrackUpdated(p types.LocalParticipant, track types.MediaTrack) {
 	// send track updates to everyone, especially if track was updated by admin
 	r.broadcastParticipantState(p, broadcastOptions{})
 	if r.onParticipantChanged != nil {
 		r.onParticipantChanged(p)
 	}
+
+	// bridges forwarding the track mirror the change
+	for _, bp := range r.GetBridgeParticipants() {
+		bp.sourceTrackUpdated(track)
+	}
+	if r.onTrackChanged != nil {
+		r.onTrackChanged(p, track)
+	}
 }
 
 func (r *Room) onTrackUnpublished(p types.LocalParticipant, track types.MediaTrack) {
 	r.trackManager.RemoveTrack(track)
+	r.RecordEvent(&RoomEvent{
+		Type:                RoomEventTrackUnpublished,
+		ParticipantIdentity: string(p.Identity()),
+		ParticipantID:       string(p.ID()),
+		TrackID:             string(track.ID()),
+	})
 	if !p.IsClosed() {
 		r.broadcastParticipantState(p, broadcastOptions{skipSource: true})
 	}
@@ -1281,10 +1762,21 @@ func (r *Room) onParticipantUpdate(p types.LocalParticipant) {
 }
 
 func (r *Room) onDataPacket(source types.LocalParticipant, kind livekit.DataPacket_Kind, dp *livekit.DataPacket) {
+	if source != nil {
+		r.markParticipantActive(source.Identity())
+
+		if dp.GetUser().GetTopic() == LobbyAdmitTopic {
+			r.handleLobbyAdmit(source, dp.GetUser().GetPayload())
+			return
+		}
+	}
 	BroadcastDataPacketForRoom(r, source, kind, dp, r.Logger)
 }
 
 func (r *Room) onMetrics(source types.Participant, dp *livekit.DataPacket) {
+	if metrics := dp.GetMetrics(); metrics != nil {
+		r.telemetry.ParticipantMetrics(context.Background(), source.ID(), metrics)
+	}
 	BroadcastMetricsForRoom(r, source, dp, r.Logger)
 }
 
@@ -1309,6 +1801,12 @@ func (r *Room) subscribeToExistingTracks(p types.LocalParticipant) {
 			p.SubscribeToTrack(track.ID())
 		}
 	}
+	for _, bp := range r.GetBridgeParticipants() {
+		for _, track := range bp.GetPublishedTracks() {
+			trackIDs = append(trackIDs, track.ID())
+			p.SubscribeToTrack(track.ID())
+		}
+	}
 	if len(trackIDs) > 0 {
 		r.Logger.Debugw("subscribed participant to existing tracks", "trackID", trackIDs)
 	}
@@ -1456,6 +1954,12 @@ func (r *Room) updateProto() *livekit.Room {
 			room.NumPublishers++
 		}
 	}
+	// bridges do not keep a room alive, they are counted as publishers only
+	for _, bp := range r.GetBridgeParticipants() {
+		if bp.IsPublisher() {
+			room.NumPublishers++
+		}
+	}
 
 	return room
 }
@@ -1632,6 +2136,100 @@ func (r *Room) simulationCleanupWorker() {
 	}
 }
 
+// SetParticipantPolicy replaces the session policy of the room, token overrides of participants still apply
+func (r *Room) SetParticipantPolicy(policy config.ParticipantPolicyConfig) {
+	r.policyLock.Lock()
+	r.participantPolicy = policy
+	r.policyLock.Unlock()
+
+	r.Logger.Infow("participant policy updated",
+		"maxDuration", policy.MaxDuration,
+		"idleTimeout", policy.IdleTimeout,
+		"warningBefore", policy.WarningBefore,
+	)
+}
+
+func (r *Room) GetParticipantPolicy() config.ParticipantPolicyConfig {
+	r.policyLock.Lock()
+	defer r.policyLock.Unlock()
+	return r.participantPolicy
+}
+
+func (r *Room) addParticipantPolicyState(p types.LocalParticipant, opts *ParticipantOptions) {
+	joinedAt := p.ConnectedAt()
+	if joinedAt.IsZero() {
+		joinedAt = time.Now()
+	}
+	var claim json.RawMessage
+	if opts != nil {
+		claim = opts.PolicyClaim
+	}
+	state := newParticipantPolicyState(participantPolicyFromClaim(claim, p.GetLogger()), joinedAt)
+
+	r.policyLock.Lock()
+	r.policyStates[p.Identity()] = state
+	r.policyLock.Unlock()
+}
+
+func (r *Room) markParticipantActive(identity livekit.ParticipantIdentity) {
+	r.policyLock.Lock()
+	if state := r.policyStates[identity]; state != nil {
+		state.markActive(time.Now())
+	}
+	r.policyLock.Unlock()
+}
+
+func (r *Room) participantPolicyWorker() {
+	ticker := time.NewTicker(participantPolicyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+		}
+
+		for _, p := range r.GetParticipants() {
+			if p.IsDependent() || p.State() == livekit.ParticipantInfo_DISCONNECTED {
+				continue
+			}
+
+			// publishing media counts as activity
+			for _, track := range p.GetPublishedTracks() {
+				if !track.IsMuted() {
+					r.markParticipantActive(p.Identity())
+					break
+				}
+			}
+
+			r.policyLock.Lock()
+			state := r.policyStates[p.Identity()]
+			if state == nil {
+				r.policyLock.Unlock()
+				continue
+			}
+			reason, warning := state.evaluate(r.participantPolicy, time.Now())
+			if reason != types.ParticipantCloseReasonNone {
+				// removed once, the state is gone when the participant leaves
+				delete(r.policyStates, p.Identity())
+			}
+			r.policyLock.Unlock()
+
+			if warning != nil {
+				p.GetLogger().Infow("participant policy warning", "reason", warning.Reason, "remaining", warning.RemainingSeconds)
+				if err := sendParticipantPolicyWarning(p, warning); err != nil {
+					p.GetLogger().Warnw("could not send participant policy warning", err)
+				}
+			}
+			if reason != types.ParticipantCloseReasonNone {
+				p.GetLogger().Infow("removing participant by policy", "reason", reason.String())
+				go r.RemoveParticipant(p.Identity(), p.ID(), reason)
+			}
+		}
+	}
+}
+
 func (r *Room) launchRoomAgents(ads []*agentDispatch) {
 	if r.agentClient == nil {
 		return
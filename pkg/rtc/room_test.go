@@ -29,6 +29,7 @@ import (
 	"github.com/livekit/livekit-server/version"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/metric"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
 	"github.com/livekit/livekit-server/pkg/sfu"
@@ -166,6 +167,99 @@ func TestRoomJoin(t *testing.T) {
 	})
 }
 
+func TestMoveParticipant(t *testing.T) {
+	t.Run("participant leaves source and joins destination", func(t *testing.T) {
+		src := newRoomWithParticipants(t, testRoomOpts{num: 3})
+		dst := newRoomWithParticipants(t, testRoomOpts{num: 2})
+
+		p := src.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+		others := src.GetParticipants()
+		p.IdentityReturns("mover")
+		src.lock.Lock()
+		src.participants["mover"] = p
+		delete(src.participants, "p0")
+		src.lock.Unlock()
+
+		var subscriber *typesfakes.FakeLocalParticipant
+		for _, op := range others {
+			if op != p {
+				subscriber = op.(*typesfakes.FakeLocalParticipant)
+				break
+			}
+		}
+		track := &typesfakes.FakeMediaTrack{}
+		track.IDReturns("TR_mover")
+		track.GetAllSubscribersReturns([]livekit.ParticipantID{subscriber.ID()})
+		p.GetPublishedTracksReturns([]types.MediaTrack{track})
+
+		moved, _, _, err := src.MoveOutParticipant("mover")
+		require.NoError(t, err)
+		require.Equal(t, p, moved)
+		// subscribers in the source room drop the moved tracks
+		require.Equal(t, 1, subscriber.UnsubscribeFromTrackCallCount())
+		require.Equal(t, livekit.TrackID("TR_mover"), subscriber.UnsubscribeFromTrackArgsForCall(0))
+		require.Nil(t, src.GetParticipant("mover"))
+		require.Len(t, src.GetParticipants(), 2)
+		// the mover is told about everyone else leaving
+		for _, pi := range p.SendParticipantUpdateArgsForCall(p.SendParticipantUpdateCallCount() - 1) {
+			require.Equal(t, livekit.ParticipantInfo_DISCONNECTED, pi.State)
+		}
+		// and the others are told about the mover leaving
+		for _, op := range others {
+			if op == p {
+				continue
+			}
+			fakeP := op.(*typesfakes.FakeLocalParticipant)
+			require.NotZero(t, fakeP.SendParticipantUpdateCallCount())
+		}
+
+		require.NoError(t, dst.MoveInParticipant(p, nil, &ParticipantOptions{AutoSubscribe: true}))
+		require.Equal(t, p, dst.GetParticipant("mover"))
+		require.Len(t, dst.GetParticipants(), 3)
+		require.Equal(t, 1, p.SendRoomUpdateCallCount())
+
+		// subscribed to tracks of the destination room only
+		require.Equal(t, 2, p.SubscribeToTrackCallCount())
+	})
+
+	t.Run("agents cannot be moved", func(t *testing.T) {
+		src := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		p := src.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+		p.IsDependentReturns(true)
+
+		_, _, _, err := src.MoveOutParticipant("p0")
+		require.ErrorIs(t, err, ErrCannotMoveParticipant)
+		require.NotNil(t, src.GetParticipant("p0"))
+	})
+
+	t.Run("moved in dependent participants do not count as joined", func(t *testing.T) {
+		dst := newRoomWithParticipants(t, testRoomOpts{num: 0})
+		p := NewMockParticipant("agent", types.CurrentProtocol, false, false)
+		p.IsDependentReturns(true)
+
+		require.NoError(t, dst.MoveInParticipant(p, nil, &ParticipantOptions{}))
+		require.Equal(t, p, dst.GetParticipant("agent"))
+		require.Equal(t, int64(0), dst.FirstJoinedAt())
+	})
+
+	t.Run("cannot exceed max participants of destination", func(t *testing.T) {
+		src := newRoomWithParticipants(t, testRoomOpts{num: 2})
+		dst := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		dst.lock.Lock()
+		dst.protoRoom.MaxParticipants = 1
+		dst.lock.Unlock()
+
+		// the move is refused before the participant leaves the source room
+		p := src.GetParticipant("p1").(*typesfakes.FakeLocalParticipant)
+		require.Equal(t, ErrMaxParticipantsExceeded, dst.CanMoveInParticipant(p))
+		require.Equal(t, p, src.GetParticipant("p1"))
+		require.Len(t, src.GetParticipants(), 2)
+		require.Zero(t, p.CloseCallCount())
+		require.Zero(t, p.MoveToRoomCallCount())
+		require.Nil(t, dst.GetParticipant("p1"))
+	})
+}
+
 // various state changes to participant and that others are receiving update
 func TestParticipantUpdate(t *testing.T) {
 	tests := []struct {
@@ -794,6 +888,40 @@ type testRoomOpts struct {
 	audioSmoothIntervals uint32
 }
 
+func TestRoomSnapshot(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
+	defer rm.Close(types.ParticipantCloseReasonNone)
+
+	rm.SetMetadata("snapshot metadata")
+	p0 := rm.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+	p0.SubscriptionPermissionReturns(&livekit.SubscriptionPermission{AllParticipants: false}, utils.TimedVersion(0))
+
+	snapshot := rm.Snapshot()
+	require.Equal(t, "snapshot metadata", snapshot.Room.Metadata)
+	require.Len(t, snapshot.SubscriptionPermissions, 1)
+	require.NotNil(t, snapshot.SubscriptionPermissions["p0"])
+
+	// restored on a new instance of the room
+	snapshot.AgentDispatches = append(snapshot.AgentDispatches, &livekit.AgentDispatch{Id: "AD_1", AgentName: "agent", Room: "room"})
+	restored := newRoomWithParticipants(t, testRoomOpts{})
+	defer restored.Close(types.ParticipantCloseReasonNone)
+	restored.RestoreSnapshot(snapshot)
+
+	dispatches, err := restored.GetAgentDispatches("")
+	require.NoError(t, err)
+	require.Len(t, dispatches, 1)
+	require.Equal(t, "AD_1", dispatches[0].Id)
+
+	// permissions are kept until the publisher joins again
+	require.Len(t, restored.Snapshot().SubscriptionPermissions, 1)
+	p := NewMockParticipant("p0", types.CurrentProtocol, false, false)
+	require.NoError(t, restored.Join(p, nil, nil, iceServersForRoom))
+	restored.RestoreSubscriptionPermission(p)
+	require.Equal(t, 1, p.UpdateSubscriptionPermissionCallCount())
+	permission, _, _ := p.UpdateSubscriptionPermissionArgsForCall(0)
+	require.False(t, permission.AllParticipants)
+}
+
 func newRoomWithParticipants(t *testing.T, opts testRoomOpts) *Room {
 	rm := NewRoom(
 		&livekit.Room{Name: "room"},
@@ -816,7 +944,7 @@ func newRoomWithParticipants(t *testing.T, opts testRoomOpts) *Room {
 			NodeId:   "testnode",
 			Region:   "testregion",
 		},
-		telemetry.NewTelemetryService(webhook.NewDefaultNotifier("", "", nil), &telemetryfakes.FakeAnalyticsService{}),
+		telemetry.NewTelemetryService(webhook.NewDefaultNotifier("", "", nil), &telemetryfakes.FakeAnalyticsService{}, metric.MetricsAggregatorConfig{}),
 		nil, nil, nil,
 	)
 	for i := 0; i < opts.num+opts.numHidden; i++ {
@@ -0,0 +1,249 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+)
+
+type RoomBridgeParams struct {
+	SourceRoom         *Room
+	DestinationRoom    *Room
+	Publisher          types.LocalParticipant
+	DynacastPauseDelay time.Duration
+	VersionGenerator   utils.TimedVersionGenerator
+	Telemetry          telemetry.TelemetryService
+	Logger             logger.Logger
+}
+
+// RoomBridge forwards tracks of a publisher into another room on the same node.
+// It is represented by a virtual participant with the same identity in both rooms,
+// as an ingress publishing the forwarded tracks in the destination room and as an
+// egress in the source room. The bridge closes when its last forwarded track closes
+// or when either room closes.
+type RoomBridge struct {
+	params                 RoomBridgeParams
+	identity               livekit.ParticipantIdentity
+	sourceParticipant      *BridgeParticipant
+	destinationParticipant *BridgeParticipant
+
+	lock    sync.Mutex
+	tracks  map[livekit.TrackID]*BridgedTrack // source track ID -> bridged track
+	closed  bool
+	onClose func(b *RoomBridge)
+}
+
+func NewRoomBridge(params RoomBridgeParams) (*RoomBridge, error) {
+	identity := BridgeParticipantIdentity(params.Publisher.Identity(), params.DestinationRoom.Name())
+	b := &RoomBridge{
+		params:   params,
+		identity: identity,
+		tracks:   make(map[livekit.TrackID]*BridgedTrack),
+	}
+
+	name := params.Publisher.ToProto().Name
+	b.sourceParticipant = NewBridgeParticipant(BridgeParticipantParams{
+		Identity: identity,
+		Name:     name,
+		Kind:     livekit.ParticipantInfo_EGRESS,
+		Attributes: map[string]string{
+			BridgeAttributeSourceIdentity:  string(params.Publisher.Identity()),
+			BridgeAttributeDestinationRoom: string(params.DestinationRoom.Name()),
+		},
+		VersionGenerator: params.VersionGenerator,
+		Logger:           params.Logger,
+	})
+	b.destinationParticipant = NewBridgeParticipant(BridgeParticipantParams{
+		Identity: identity,
+		Name:     name,
+		Kind:     livekit.ParticipantInfo_INGRESS,
+		Attributes: map[string]string{
+			BridgeAttributeSourceRoom:     string(params.SourceRoom.Name()),
+			BridgeAttributeSourceIdentity: string(params.Publisher.Identity()),
+		},
+		VersionGenerator: params.VersionGenerator,
+		Logger:           params.Logger,
+	})
+
+	if err := params.SourceRoom.AddBridgeParticipant(b.sourceParticipant); err != nil {
+		return nil, err
+	}
+	if err := params.DestinationRoom.AddBridgeParticipant(b.destinationParticipant); err != nil {
+		params.SourceRoom.RemoveBridgeParticipant(identity, types.ParticipantCloseReasonJoinFailed)
+		return nil, err
+	}
+
+	b.sourceParticipant.OnSourceTrackUpdated(b.onSourceTrackUpdated)
+	// either side going away, i. e. room closing, takes down the bridge
+	b.sourceParticipant.OnClose(func(_ *BridgeParticipant) { b.Close() })
+	b.destinationParticipant.OnClose(func(_ *BridgeParticipant) { b.Close() })
+	return b, nil
+}
+
+func (b *RoomBridge) Identity() livekit.ParticipantIdentity {
+	return b.identity
+}
+
+func (b *RoomBridge) SourceRoom() *Room {
+	return b.params.SourceRoom
+}
+
+func (b *RoomBridge) DestinationRoom() *Room {
+	return b.params.DestinationRoom
+}
+
+func (b *RoomBridge) SourceParticipant() *BridgeParticipant {
+	return b.sourceParticipant
+}
+
+func (b *RoomBridge) DestinationParticipant() *BridgeParticipant {
+	return b.destinationParticipant
+}
+
+func (b *RoomBridge) OnClose(f func(b *RoomBridge)) {
+	b.lock.Lock()
+	b.onClose = f
+	b.lock.Unlock()
+}
+
+// ForwardTrack starts forwarding a track of the publisher, returns the existing forward when
+// the track is already bridged
+func (b *RoomBridge) ForwardTrack(trackID livekit.TrackID) (*BridgedTrack, error) {
+	source := b.params.Publisher.GetPublishedTrack(trackID)
+	if source == nil {
+		return nil, ErrTrackNotFound
+	}
+	if len(source.Receivers()) == 0 {
+		return nil, ErrTrackNotBound
+	}
+
+	b.lock.Lock()
+	if b.closed {
+		b.lock.Unlock()
+		return nil, ErrRoomClosed
+	}
+	if bt := b.tracks[trackID]; bt != nil {
+		b.lock.Unlock()
+		return bt, nil
+	}
+
+	destRoom := b.params.DestinationRoom
+	var audioConfig sfu.AudioConfig
+	if destRoom.audioConfig != nil {
+		audioConfig = *destRoom.audioConfig
+	}
+	bt := NewBridgedTrack(BridgedTrackParams{
+		Source:              source,
+		ParticipantID:       b.destinationParticipant.ID(),
+		ParticipantIdentity: b.identity,
+		ReceiverConfig:      destRoom.config.Receiver,
+		SubscriberConfig:    destRoom.config.Subscriber,
+		AudioConfig:         audioConfig,
+		DynacastPauseDelay:  b.params.DynacastPauseDelay,
+		Telemetry:           b.params.Telemetry,
+		Logger:              LoggerWithTrack(b.params.Logger, trackID, false),
+	})
+	b.tracks[trackID] = bt
+	b.lock.Unlock()
+
+	bt.AddOnClose(func(_ bool) {
+		b.onBridgedTrackClosed(trackID, bt)
+	})
+	if err := destRoom.PublishBridgedTrack(b.identity, bt); err != nil {
+		bt.Close(false)
+		return nil, err
+	}
+
+	b.params.Logger.Infow("forwarding track", "sourceTrackID", trackID, "trackID", bt.ID())
+	return bt, nil
+}
+
+// StopTrack stops forwarding a track, the bridge closes when no forwarded track is left
+func (b *RoomBridge) StopTrack(trackID livekit.TrackID) error {
+	b.lock.Lock()
+	bt := b.tracks[trackID]
+	b.lock.Unlock()
+	if bt == nil {
+		return ErrTrackNotFound
+	}
+
+	bt.Close(false)
+	return nil
+}
+
+// Tracks returns forwarded tracks keyed by source track ID
+func (b *RoomBridge) Tracks() map[livekit.TrackID]*BridgedTrack {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return maps.Clone(b.tracks)
+}
+
+func (b *RoomBridge) onBridgedTrackClosed(trackID livekit.TrackID, bt *BridgedTrack) {
+	b.lock.Lock()
+	if b.tracks[trackID] == bt {
+		delete(b.tracks, trackID)
+	}
+	empty := len(b.tracks) == 0
+	b.lock.Unlock()
+
+	b.params.DestinationRoom.UnpublishBridgedTrack(b.identity, bt)
+	b.params.Logger.Infow("stopped forwarding track", "sourceTrackID", trackID, "trackID", bt.ID())
+
+	if empty {
+		b.Close()
+	}
+}
+
+func (b *RoomBridge) onSourceTrackUpdated(track types.MediaTrack) {
+	b.lock.Lock()
+	bt := b.tracks[track.ID()]
+	b.lock.Unlock()
+	if bt == nil {
+		return
+	}
+
+	bt.SyncSource()
+	b.params.DestinationRoom.UpdateBridgedTrack(b.identity, bt)
+}
+
+func (b *RoomBridge) Close() {
+	b.lock.Lock()
+	if b.closed {
+		b.lock.Unlock()
+		return
+	}
+	b.closed = true
+	onClose := b.onClose
+	b.lock.Unlock()
+
+	b.params.DestinationRoom.RemoveBridgeParticipant(b.identity, types.ParticipantCloseReasonNone)
+	b.params.SourceRoom.RemoveBridgeParticipant(b.identity, types.ParticipantCloseReasonNone)
+	b.params.Logger.Infow("room bridge closed")
+
+	if onClose != nil {
+		onClose(b)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+	"github.com/livekit/livekit-server/pkg/sfu"
+)
+
+func TestRoomBridge(t *testing.T) {
+	t.Run("forwarded track is published by a virtual participant", func(t *testing.T) {
+		src, dst, publisher, track := newBridgeTestRooms(t)
+		bridge := newTestRoomBridge(t, src, dst, publisher)
+
+		sp := src.GetBridgeParticipant(bridge.Identity())
+		require.NotNil(t, sp)
+		require.Equal(t, livekit.ParticipantInfo_EGRESS, sp.Kind())
+		dp := dst.GetBridgeParticipant(bridge.Identity())
+		require.NotNil(t, dp)
+		require.Equal(t, livekit.ParticipantInfo_INGRESS, dp.Kind())
+
+		bt, err := bridge.ForwardTrack(track.ID())
+		require.NoError(t, err)
+		require.NotEqual(t, track.ID(), bt.ID())
+		require.Equal(t, track.ID(), bt.SourceID())
+		require.True(t, dp.IsPublisher())
+		require.False(t, sp.IsPublisher())
+
+		// forwarding again returns the existing track
+		bt2, err := bridge.ForwardTrack(track.ID())
+		require.NoError(t, err)
+		require.Equal(t, bt, bt2)
+
+		// active participants in the destination room are subscribed
+		sub := dst.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+		require.Equal(t, 1, sub.SubscribeToTrackCallCount())
+		require.Equal(t, bt.ID(), sub.SubscribeToTrackArgsForCall(0))
+
+		res := dst.ResolveMediaTrackForSubscriber(sub, bt.ID())
+		require.Equal(t, types.MediaTrack(bt), res.Track)
+		require.Equal(t, dp.ID(), res.PublisherID)
+		require.True(t, res.HasPermission)
+		require.NotNil(t, dst.GetParticipantInfo(dp.ID()))
+
+		// and both rooms list the bridge
+		var found int
+		for _, room := range []*Room{src, dst} {
+			for _, pi := range room.getOtherParticipantInfo("p0") {
+				if pi.Identity == string(bridge.Identity()) {
+					found++
+				}
+			}
+		}
+		require.Equal(t, 2, found)
+	})
+
+	t.Run("unknown track", func(t *testing.T) {
+		src, dst, publisher, _ := newBridgeTestRooms(t)
+		bridge := newTestRoomBridge(t, src, dst, publisher)
+
+		_, err := bridge.ForwardTrack("TR_unknown")
+		require.ErrorIs(t, err, ErrTrackNotFound)
+	})
+
+	t.Run("closes with the source track", func(t *testing.T) {
+		src, dst, publisher, track := newBridgeTestRooms(t)
+		bridge := newTestRoomBridge(t, src, dst, publisher)
+		closed := make(chan struct{})
+		bridge.OnClose(func(_ *RoomBridge) { close(closed) })
+
+		bt, err := bridge.ForwardTrack(track.ID())
+		require.NoError(t, err)
+
+		require.Equal(t, 1, track.AddOnCloseCallCount())
+		track.AddOnCloseArgsForCall(0)(false)
+
+		<-closed
+		require.Nil(t, src.GetBridgeParticipant(bridge.Identity()))
+		require.Nil(t, dst.GetBridgeParticipant(bridge.Identity()))
+		require.Nil(t, dst.trackManager.GetTrackInfo(bt.ID()))
+	})
+
+	t.Run("closes with the destination room", func(t *testing.T) {
+		src, dst, publisher, track := newBridgeTestRooms(t)
+		bridge := newTestRoomBridge(t, src, dst, publisher)
+
+		bt, err := bridge.ForwardTrack(track.ID())
+		require.NoError(t, err)
+
+		dst.Close(types.ParticipantCloseReasonRoomClosed)
+		require.False(t, bt.IsOpen())
+		require.Nil(t, src.GetBridgeParticipant(bridge.Identity()))
+		require.Empty(t, bridge.Tracks())
+	})
+}
+
+func newBridgeTestRooms(t *testing.T) (*Room, *Room, *typesfakes.FakeLocalParticipant, *typesfakes.FakeLocalMediaTrack) {
+	src := newRoomWithParticipants(t, testRoomOpts{num: 1})
+	dst := newRoomWithParticipants(t, testRoomOpts{num: 2})
+	dst.protoRoom.Name = "overflow"
+
+	track := &typesfakes.FakeLocalMediaTrack{}
+	track.IDReturns("TR_source")
+	track.KindReturns(livekit.TrackType_AUDIO)
+	track.ToProtoReturns(&livekit.TrackInfo{
+		Sid:  "TR_source",
+		Type: livekit.TrackType_AUDIO,
+		Name: "mic",
+	})
+	track.ReceiversReturns([]sfu.TrackReceiver{
+		NewDummyReceiver("TR_source", "stream", webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus},
+		}, nil),
+	})
+
+	publisher := src.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+	publisher.GetPublishedTrackStub = func(trackID livekit.TrackID) types.MediaTrack {
+		if trackID == track.ID() {
+			return track
+		}
+		return nil
+	}
+	return src, dst, publisher, track
+}
+
+func newTestRoomBridge(t *testing.T, src, dst *Room, publisher types.LocalParticipant) *RoomBridge {
+	bridge, err := NewRoomBridge(RoomBridgeParams{
+		SourceRoom:       src,
+		DestinationRoom:  dst,
+		Publisher:        publisher,
+		VersionGenerator: utils.NewDefaultTimedVersionGenerator(),
+		Logger:           logger.GetLogger(),
+	})
+	require.NoError(t, err)
+	return bridge
+}
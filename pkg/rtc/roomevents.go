@@ -0,0 +1,71 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"time"
+)
+
+type RoomEventType string
+
+const (
+	RoomEventRoomStarted         RoomEventType = "room_started"
+	RoomEventRoomEnded           RoomEventType = "room_ended"
+	RoomEventParticipantJoined   RoomEventType = "participant_joined"
+	RoomEventParticipantLeft     RoomEventType = "participant_left"
+	RoomEventTrackPublished      RoomEventType = "track_published"
+	RoomEventTrackUnpublished    RoomEventType = "track_unpublished"
+	RoomEventSubscriptionFailed  RoomEventType = "subscription_failed"
+	RoomEventMigrationStarted    RoomEventType = "migration_started"
+	RoomEventMigrationCompleted  RoomEventType = "migration_completed"
+	RoomEventCongestionChanged   RoomEventType = "congestion_state_changed"
+	RoomEventStreamPaused        RoomEventType = "stream_paused"
+	RoomEventStreamResumed       RoomEventType = "stream_resumed"
+	RoomEventSubscribedQualities RoomEventType = "subscribed_qualities_changed"
+)
+
+// RoomEvent is an entry of the event journal of a room, kept for troubleshooting after the fact.
+// Participant fields refer to the participant the event happened to, e. g. the subscriber of a paused stream.
+type RoomEvent struct {
+	Type                RoomEventType     `json:"type"`
+	At                  int64             `json:"at"` // unix milliseconds
+	RoomID              string            `json:"room_id,omitempty"`
+	ParticipantIdentity string            `json:"participant_identity,omitempty"`
+	ParticipantID       string            `json:"participant_id,omitempty"`
+	TrackID             string            `json:"track_id,omitempty"`
+	Reason              string            `json:"reason,omitempty"`
+	Details             map[string]string `json:"details,omitempty"`
+}
+
+// OnEvent sets the callback receiving the room's journal events, invoked synchronously so it must not block
+func (r *Room) OnEvent(f func(event *RoomEvent)) {
+	r.onEvent.Store(&f)
+}
+
+// RecordEvent adds an event to the room's journal
+func (r *Room) RecordEvent(event *RoomEvent) {
+	onEvent := r.onEvent.Load()
+	if onEvent == nil || *onEvent == nil {
+		return
+	}
+
+	if event.At == 0 {
+		event.At = time.Now().UnixMilli()
+	}
+	if event.RoomID == "" {
+		event.RoomID = string(r.ID())
+	}
+	(*onEvent)(event)
+}
@@ -0,0 +1,237 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// sent to participants with an admin grant when a participant starts waiting in the lobby
+	LobbyWaitingTopic = "lk.lobby.waiting"
+	// sent by a participant with an admin grant to admit a waiting participant, it is not forwarded
+	LobbyAdmitTopic = "lk.lobby.admit"
+)
+
+type LobbyParticipant struct {
+	Identity string `json:"identity"`
+	Name     string `json:"name,omitempty"`
+}
+
+type LobbyAdmitRequest struct {
+	Identity string `json:"identity"`
+}
+
+// heldParticipant is a participant parked in the lobby, with its transport established but hidden
+// and unable to publish or subscribe. It is let in once nothing holds it anymore.
+type heldParticipant struct {
+	permission        *livekit.ParticipantPermission
+	awaitingAdmission bool
+	awaitingStart     bool
+}
+
+// SetLobbyEnabled turns the lobby on or off, participants waiting for admission are admitted
+// when it is turned off
+func (r *Room) SetLobbyEnabled(enabled bool) {
+	r.lobbyLock.Lock()
+	r.lobbyEnabled = enabled
+	r.lobbyLock.Unlock()
+
+	r.Logger.Infow("room lobby updated", "enabled", enabled)
+	if !enabled {
+		r.endHold(func(_ livekit.ParticipantIdentity, h *heldParticipant) { h.awaitingAdmission = false })
+	}
+}
+
+func (r *Room) IsLobbyEnabled() bool {
+	r.lobbyLock.Lock()
+	defer r.lobbyLock.Unlock()
+	return r.lobbyEnabled
+}
+
+// AdmitParticipant lets a participant waiting in the lobby into the room
+func (r *Room) AdmitParticipant(identity livekit.ParticipantIdentity) error {
+	r.lobbyLock.Lock()
+	h := r.heldParticipants[identity]
+	waiting := h != nil && h.awaitingAdmission
+	r.lobbyLock.Unlock()
+	if !waiting {
+		return ErrParticipantNotWaiting
+	}
+
+	r.endHold(func(heldIdentity livekit.ParticipantIdentity, h *heldParticipant) {
+		if heldIdentity == identity {
+			h.awaitingAdmission = false
+		}
+	})
+	return nil
+}
+
+// GetWaitingParticipants returns participants waiting in the lobby for admission
+func (r *Room) GetWaitingParticipants() []*LobbyParticipant {
+	r.lobbyLock.Lock()
+	var identities []livekit.ParticipantIdentity
+	for identity, h := range r.heldParticipants {
+		if h.awaitingAdmission {
+			identities = append(identities, identity)
+		}
+	}
+	r.lobbyLock.Unlock()
+
+	waiting := make([]*LobbyParticipant, 0, len(identities))
+	for _, identity := range identities {
+		if p := r.GetParticipant(identity); p != nil {
+			waiting = append(waiting, &LobbyParticipant{
+				Identity: string(identity),
+				Name:     p.ToProto().Name,
+			})
+		}
+	}
+	return waiting
+}
+
+func (r *Room) IsParticipantHeld(identity livekit.ParticipantIdentity) bool {
+	r.lobbyLock.Lock()
+	defer r.lobbyLock.Unlock()
+	_, ok := r.heldParticipants[identity]
+	return ok
+}
+
+// needsAdmission returns whether a joining participant has to be admitted,
+// agents, recorders and room admins skip the lobby
+func (r *Room) needsAdmission(participant types.LocalParticipant) bool {
+	if participant.IsDependent() || isRoomAdmin(participant) {
+		return false
+	}
+	return r.IsLobbyEnabled()
+}
+
+func (r *Room) holdParticipant(participant types.LocalParticipant, awaitingAdmission bool, awaitingStart bool) {
+	permission := participant.ClaimGrants().Video.ToPermission()
+
+	r.lobbyLock.Lock()
+	r.heldParticipants[participant.Identity()] = &heldParticipant{
+		permission:        permission,
+		awaitingAdmission: awaitingAdmission,
+		awaitingStart:     awaitingStart,
+	}
+	r.lobbyLock.Unlock()
+
+	held := proto.Clone(permission).(*livekit.ParticipantPermission)
+	held.CanPublish = false
+	held.CanPublishData = false
+	held.CanSubscribe = false
+	held.CanPublishSources = nil
+	held.Hidden = true
+	participant.SetPermission(held)
+	participant.GetLogger().Infow("holding participant in lobby", "awaitingAdmission", awaitingAdmission, "awaitingStart", awaitingStart)
+}
+
+// notifyWaitingLocked announces a participant waiting for admission to admins in the room and to webhooks
+func (r *Room) notifyWaitingLocked(participant types.LocalParticipant) {
+	pi := participant.ToProto()
+	r.telemetry.ParticipantWaiting(context.Background(), utils.CloneProto(r.protoRoom), pi)
+
+	payload, err := json.Marshal(&LobbyParticipant{Identity: pi.Identity, Name: pi.Name})
+	if err != nil {
+		return
+	}
+	topic := LobbyWaitingTopic
+	data, err := proto.Marshal(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   &topic,
+			},
+		},
+	})
+	if err != nil {
+		return
+	}
+	for _, op := range r.participants {
+		if op != participant && isRoomAdmin(op) {
+			_ = op.SendDataPacket(livekit.DataPacket_RELIABLE, data)
+		}
+	}
+}
+
+// endHold updates what held participants wait for, participants that do not wait for anything anymore are let in
+func (r *Room) endHold(update func(identity livekit.ParticipantIdentity, h *heldParticipant)) {
+	type release struct {
+		identity   livekit.ParticipantIdentity
+		permission *livekit.ParticipantPermission
+		admitted   bool
+	}
+	var released []release
+
+	r.lobbyLock.Lock()
+	for identity, h := range r.heldParticipants {
+		wasAwaitingAdmission := h.awaitingAdmission
+		update(identity, h)
+		if h.awaitingAdmission || h.awaitingStart {
+			continue
+		}
+		delete(r.heldParticipants, identity)
+		released = append(released, release{identity, h.permission, wasAwaitingAdmission})
+	}
+	r.lobbyLock.Unlock()
+
+	for _, rel := range released {
+		participant := r.GetParticipant(rel.identity)
+		if participant == nil {
+			continue
+		}
+
+		participant.GetLogger().Infow("letting participant in from lobby")
+		participant.SetPermission(rel.permission)
+		if rel.admitted {
+			r.telemetry.ParticipantAdmitted(context.Background(), r.ToProto(), participant.ToProto())
+		}
+		if participant.State() == livekit.ParticipantInfo_ACTIVE {
+			r.subscribeToExistingTracks(participant)
+		}
+	}
+}
+
+// handleLobbyAdmit admits a participant on request of an admin in the room
+func (r *Room) handleLobbyAdmit(source types.LocalParticipant, payload []byte) {
+	if !isRoomAdmin(source) {
+		source.GetLogger().Warnw("ignoring lobby admission from participant without admin grant", nil)
+		return
+	}
+
+	var req LobbyAdmitRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		source.GetLogger().Warnw("could not parse lobby admission", err)
+		return
+	}
+	if err := r.AdmitParticipant(livekit.ParticipantIdentity(req.Identity)); err != nil {
+		source.GetLogger().Infow("could not admit participant", "error", err, "participant", req.Identity)
+	}
+}
+
+func isRoomAdmin(p types.LocalParticipant) bool {
+	grants := p.ClaimGrants()
+	return grants != nil && grants.Video != nil && grants.Video.RoomAdmin
+}
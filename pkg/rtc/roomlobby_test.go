@@ -0,0 +1,131 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func TestRoomLobby(t *testing.T) {
+	newLobbyRoom := func(t *testing.T) (*Room, *typesfakes.FakeLocalParticipant) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		admin := rm.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+		admin.ClaimGrantsReturns(&auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomJoin: true, RoomAdmin: true},
+		})
+		rm.SetLobbyEnabled(true)
+		return rm, admin
+	}
+	joinGuest := func(t *testing.T, rm *Room) *typesfakes.FakeLocalParticipant {
+		p := NewMockParticipant("guest", types.CurrentProtocol, false, false)
+		p.ClaimGrantsReturns(&auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomJoin: true, CanPublish: newBool(true), CanSubscribe: newBool(true)},
+		})
+		require.NoError(t, rm.Join(p, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom))
+		return p
+	}
+
+	t.Run("participants wait hidden until admitted", func(t *testing.T) {
+		rm, admin := newLobbyRoom(t)
+		defer rm.Close(types.ParticipantCloseReasonNone)
+
+		p := joinGuest(t, rm)
+		require.True(t, rm.IsParticipantHeld(p.Identity()))
+		require.Equal(t, 1, p.SetPermissionCallCount())
+		held := p.SetPermissionArgsForCall(0)
+		require.True(t, held.Hidden)
+		require.False(t, held.CanSubscribe)
+		require.False(t, held.CanPublish)
+
+		waiting := rm.GetWaitingParticipants()
+		require.Len(t, waiting, 1)
+		require.Equal(t, "guest", waiting[0].Identity)
+
+		// admins are told about the waiting participant
+		require.Equal(t, 1, admin.SendDataPacketCallCount())
+		_, data := admin.SendDataPacketArgsForCall(0)
+		dp := &livekit.DataPacket{}
+		require.NoError(t, proto.Unmarshal(data, dp))
+		require.Equal(t, LobbyWaitingTopic, dp.GetUser().GetTopic())
+
+		require.NoError(t, rm.AdmitParticipant(p.Identity()))
+		require.False(t, rm.IsParticipantHeld(p.Identity()))
+		require.Equal(t, 2, p.SetPermissionCallCount())
+		admitted := p.SetPermissionArgsForCall(1)
+		require.False(t, admitted.Hidden)
+		require.True(t, admitted.CanSubscribe)
+
+		require.ErrorIs(t, rm.AdmitParticipant(p.Identity()), ErrParticipantNotWaiting)
+	})
+
+	t.Run("admins skip the lobby", func(t *testing.T) {
+		rm, _ := newLobbyRoom(t)
+		defer rm.Close(types.ParticipantCloseReasonNone)
+
+		p := NewMockParticipant("host", types.CurrentProtocol, false, false)
+		p.ClaimGrantsReturns(&auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomJoin: true, RoomAdmin: true},
+		})
+		require.NoError(t, rm.Join(p, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom))
+		require.False(t, rm.IsParticipantHeld(p.Identity()))
+		require.Equal(t, 0, p.SetPermissionCallCount())
+	})
+
+	t.Run("admin admits with a data message", func(t *testing.T) {
+		rm, admin := newLobbyRoom(t)
+		defer rm.Close(types.ParticipantCloseReasonNone)
+
+		p := joinGuest(t, rm)
+		payload, err := json.Marshal(&LobbyAdmitRequest{Identity: "guest"})
+		require.NoError(t, err)
+		topic := LobbyAdmitTopic
+		admitPacket := &livekit.DataPacket{
+			Value: &livekit.DataPacket_User{
+				User: &livekit.UserPacket{Payload: payload, Topic: &topic},
+			},
+		}
+
+		// ignored without an admin grant
+		admin.ClaimGrantsReturns(&auth.ClaimGrants{Video: &auth.VideoGrant{RoomJoin: true}})
+		rm.onDataPacket(admin, livekit.DataPacket_RELIABLE, admitPacket)
+		require.True(t, rm.IsParticipantHeld(p.Identity()))
+
+		admin.ClaimGrantsReturns(&auth.ClaimGrants{Video: &auth.VideoGrant{RoomJoin: true, RoomAdmin: true}})
+		rm.onDataPacket(admin, livekit.DataPacket_RELIABLE, admitPacket)
+		require.False(t, rm.IsParticipantHeld(p.Identity()))
+		// admission requests are not forwarded
+		require.Equal(t, 0, p.SendDataPacketCallCount())
+	})
+
+	t.Run("turning the lobby off admits everyone", func(t *testing.T) {
+		rm, _ := newLobbyRoom(t)
+		defer rm.Close(types.ParticipantCloseReasonNone)
+
+		p := joinGuest(t, rm)
+		rm.SetLobbyEnabled(false)
+		require.False(t, rm.IsParticipantHeld(p.Identity()))
+		require.Empty(t, rm.GetWaitingParticipants())
+	})
+}
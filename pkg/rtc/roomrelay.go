@@ -0,0 +1,234 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/relay"
+)
+
+type RoomRelayParams struct {
+	SourceRoom       *Room
+	DestinationRoom  livekit.RoomName
+	DestinationNode  livekit.NodeID
+	Publisher        types.LocalParticipant
+	Transport        *relay.Transport
+	VersionGenerator utils.TimedVersionGenerator
+	Logger           logger.Logger
+}
+
+// RoomRelay forwards tracks of a publisher into a room hosted on another node, it is the origin side of
+// a RoomBridge spanning nodes. The bridge is represented by an egress virtual participant in the source room,
+// the destination node publishes the relayed tracks with an ingress participant of the same identity.
+// Setting up relayed tracks and passing on changes is up to the owner, over psrpc.
+type RoomRelay struct {
+	params            RoomRelayParams
+	identity          livekit.ParticipantIdentity
+	sourceParticipant *BridgeParticipant
+
+	lock           sync.Mutex
+	tracks         map[livekit.TrackID]*TrackRelay // source track ID -> relay
+	closed         bool
+	onTrackUpdated func(r *RoomRelay, track types.MediaTrack)
+	onTrackClosed  func(r *RoomRelay, tr *TrackRelay)
+	onClose        func(r *RoomRelay)
+}
+
+func NewRoomRelay(params RoomRelayParams) (*RoomRelay, error) {
+	identity := BridgeParticipantIdentity(params.Publisher.Identity(), params.DestinationRoom)
+	r := &RoomRelay{
+		params:   params,
+		identity: identity,
+		tracks:   make(map[livekit.TrackID]*TrackRelay),
+	}
+
+	r.sourceParticipant = NewBridgeParticipant(BridgeParticipantParams{
+		Identity: identity,
+		Name:     params.Publisher.ToProto().Name,
+		Kind:     livekit.ParticipantInfo_EGRESS,
+		Attributes: map[string]string{
+			BridgeAttributeSourceIdentity:  string(params.Publisher.Identity()),
+			BridgeAttributeDestinationRoom: string(params.DestinationRoom),
+		},
+		VersionGenerator: params.VersionGenerator,
+		Logger:           params.Logger,
+	})
+	if err := params.SourceRoom.AddBridgeParticipant(r.sourceParticipant); err != nil {
+		return nil, err
+	}
+
+	r.sourceParticipant.OnSourceTrackUpdated(r.onSourceTrackUpdated)
+	r.sourceParticipant.OnClose(func(_ *BridgeParticipant) { r.Close() })
+	return r, nil
+}
+
+func (r *RoomRelay) Identity() livekit.ParticipantIdentity {
+	return r.identity
+}
+
+func (r *RoomRelay) SourceRoom() *Room {
+	return r.params.SourceRoom
+}
+
+func (r *RoomRelay) DestinationRoom() livekit.RoomName {
+	return r.params.DestinationRoom
+}
+
+func (r *RoomRelay) DestinationNode() livekit.NodeID {
+	return r.params.DestinationNode
+}
+
+func (r *RoomRelay) SourceParticipant() *BridgeParticipant {
+	return r.sourceParticipant
+}
+
+// OnTrackUpdated is called when a relayed track changed on this node
+func (r *RoomRelay) OnTrackUpdated(f func(r *RoomRelay, track types.MediaTrack)) {
+	r.lock.Lock()
+	r.onTrackUpdated = f
+	r.lock.Unlock()
+}
+
+// OnTrackClosed is called when a track stopped being relayed, i. e. it was unpublished or stopped
+func (r *RoomRelay) OnTrackClosed(f func(r *RoomRelay, tr *TrackRelay)) {
+	r.lock.Lock()
+	r.onTrackClosed = f
+	r.lock.Unlock()
+}
+
+func (r *RoomRelay) OnClose(f func(r *RoomRelay)) {
+	r.lock.Lock()
+	r.onClose = f
+	r.lock.Unlock()
+}
+
+// ForwardTrack attaches a relay to a track of the publisher, it sends once started with the address of the
+// destination node. Returns the existing relay when the track is already relayed.
+func (r *RoomRelay) ForwardTrack(trackID livekit.TrackID) (*TrackRelay, bool, error) {
+	source := r.params.Publisher.GetPublishedTrack(trackID)
+	if source == nil {
+		return nil, false, ErrTrackNotFound
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return nil, false, ErrRoomClosed
+	}
+	if tr := r.tracks[trackID]; tr != nil {
+		return tr, false, nil
+	}
+
+	tr, err := NewTrackRelay(TrackRelayParams{
+		Source:          source,
+		DestinationNode: r.params.DestinationNode,
+		Transport:       r.params.Transport,
+		Logger:          LoggerWithTrack(r.params.Logger, trackID, false),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	r.tracks[trackID] = tr
+	tr.OnClose(func(tr *TrackRelay) {
+		r.onTrackRelayClosed(trackID, tr)
+	})
+
+	r.params.Logger.Infow("relaying track", "sourceTrackID", trackID, "sessionID", tr.SessionID())
+	return tr, true, nil
+}
+
+// StopTrack stops relaying a track, the relay closes when no relayed track is left
+func (r *RoomRelay) StopTrack(trackID livekit.TrackID) error {
+	tr := r.Track(trackID)
+	if tr == nil {
+		return ErrTrackNotFound
+	}
+
+	tr.Close()
+	return nil
+}
+
+func (r *RoomRelay) Track(trackID livekit.TrackID) *TrackRelay {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.tracks[trackID]
+}
+
+// Tracks returns relayed tracks keyed by source track ID
+func (r *RoomRelay) Tracks() map[livekit.TrackID]*TrackRelay {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return maps.Clone(r.tracks)
+}
+
+func (r *RoomRelay) onTrackRelayClosed(trackID livekit.TrackID, tr *TrackRelay) {
+	r.lock.Lock()
+	if r.tracks[trackID] == tr {
+		delete(r.tracks, trackID)
+	}
+	empty := len(r.tracks) == 0
+	onTrackClosed := r.onTrackClosed
+	r.lock.Unlock()
+
+	r.params.Logger.Infow("stopped relaying track", "sourceTrackID", trackID, "sessionID", tr.SessionID())
+	if onTrackClosed != nil {
+		onTrackClosed(r, tr)
+	}
+
+	if empty {
+		r.Close()
+	}
+}
+
+func (r *RoomRelay) onSourceTrackUpdated(track types.MediaTrack) {
+	r.lock.Lock()
+	tr := r.tracks[track.ID()]
+	onTrackUpdated := r.onTrackUpdated
+	r.lock.Unlock()
+
+	if tr != nil && onTrackUpdated != nil {
+		onTrackUpdated(r, track)
+	}
+}
+
+func (r *RoomRelay) Close() {
+	r.lock.Lock()
+	if r.closed {
+		r.lock.Unlock()
+		return
+	}
+	r.closed = true
+	tracks := maps.Values(r.tracks)
+	onClose := r.onClose
+	r.lock.Unlock()
+
+	for _, tr := range tracks {
+		tr.Close()
+	}
+	r.params.SourceRoom.RemoveBridgeParticipant(r.identity, types.ParticipantCloseReasonNone)
+	r.params.Logger.Infow("room relay closed")
+
+	if onClose != nil {
+		onClose(r)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// topic of data messages warning participants that the room is about to end
+	RoomScheduleWarningTopic = "lk.schedule.warning"
+
+	roomScheduleCheckInterval = time.Second
+)
+
+// RoomScheduleWindow is the current occurrence of a room schedule
+type RoomScheduleWindow struct {
+	// joins before StartAt are rejected, or held in the lobby when HoldBeforeStart is set
+	StartAt         time.Time
+	HoldBeforeStart bool
+	// the room is closed at EndAt, participants are warned WarningBefore ahead
+	EndAt         time.Time
+	WarningBefore time.Duration
+}
+
+type RoomScheduleWarning struct {
+	EndTime          int64 `json:"end_time"`
+	RemainingSeconds int64 `json:"remaining_seconds"`
+}
+
+// SetSchedule applies a schedule window to the room, replacing the previous one
+func (r *Room) SetSchedule(window RoomScheduleWindow) {
+	r.scheduleLock.Lock()
+	r.schedule = &window
+	r.scheduleWarned = false
+	r.scheduleLock.Unlock()
+
+	r.Logger.Infow("room schedule updated",
+		"startAt", window.StartAt,
+		"endAt", window.EndAt,
+		"holdBeforeStart", window.HoldBeforeStart,
+	)
+	r.checkSchedule(time.Now())
+}
+
+// ClearSchedule removes the schedule, participants held in the lobby are let in
+func (r *Room) ClearSchedule() {
+	r.scheduleLock.Lock()
+	r.schedule = nil
+	r.scheduleLock.Unlock()
+
+	r.Logger.Infow("room schedule removed")
+	r.endHold(func(_ livekit.ParticipantIdentity, h *heldParticipant) { h.awaitingStart = false })
+}
+
+func (r *Room) GetSchedule() (RoomScheduleWindow, bool) {
+	r.scheduleLock.Lock()
+	defer r.scheduleLock.Unlock()
+	if r.schedule == nil {
+		return RoomScheduleWindow{}, false
+	}
+	return *r.schedule, true
+}
+
+// checkScheduleForJoin returns whether the participant has to wait in the lobby for the start,
+// or an error when the room does not accept participants at this time
+func (r *Room) checkScheduleForJoin(participant types.LocalParticipant, canHold bool) (bool, error) {
+	if participant.IsDependent() {
+		return false, nil
+	}
+
+	window, ok := r.GetSchedule()
+	if !ok {
+		return false, nil
+	}
+
+	now := time.Now()
+	if !window.EndAt.IsZero() && !now.Before(window.EndAt) {
+		return false, ErrRoomClosed
+	}
+	if now.Before(window.StartAt) {
+		if !window.HoldBeforeStart || !canHold {
+			return false, ErrRoomNotStarted
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *Room) scheduleWorker() {
+	ticker := time.NewTicker(roomScheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.closed:
+			return
+		case now := <-ticker.C:
+			r.checkSchedule(now)
+		}
+	}
+}
+
+func (r *Room) checkSchedule(now time.Time) {
+	r.scheduleLock.Lock()
+	if r.schedule == nil {
+		r.scheduleLock.Unlock()
+		return
+	}
+	window := *r.schedule
+	started := !now.Before(window.StartAt)
+
+	var warning *RoomScheduleWarning
+	if !window.EndAt.IsZero() && window.WarningBefore > 0 && !r.scheduleWarned {
+		if remaining := window.EndAt.Sub(now); remaining > 0 && remaining <= window.WarningBefore {
+			r.scheduleWarned = true
+			warning = &RoomScheduleWarning{
+				EndTime:          window.EndAt.Unix(),
+				RemainingSeconds: int64((remaining + time.Second - 1) / time.Second),
+			}
+		}
+	}
+	r.scheduleLock.Unlock()
+
+	if started {
+		r.endHold(func(_ livekit.ParticipantIdentity, h *heldParticipant) { h.awaitingStart = false })
+	}
+
+	if warning != nil {
+		r.Logger.Infow("room ending soon", "endAt", window.EndAt, "remaining", warning.RemainingSeconds)
+		r.sendScheduleWarning(warning)
+	}
+
+	if !window.EndAt.IsZero() && !now.Before(window.EndAt) {
+		r.Logger.Infow("closing room at scheduled end", "endAt", window.EndAt)
+		r.Close(types.ParticipantCloseReasonRoomClosed)
+	}
+}
+
+func (r *Room) sendScheduleWarning(warning *RoomScheduleWarning) {
+	payload, err := json.Marshal(warning)
+	if err != nil {
+		r.Logger.Errorw("could not marshal room schedule warning", err)
+		return
+	}
+	topic := RoomScheduleWarningTopic
+	r.SendDataPacket(&livekit.DataPacket{
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   &topic,
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
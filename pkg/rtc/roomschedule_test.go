@@ -0,0 +1,100 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func TestRoomSchedule(t *testing.T) {
+	t.Run("joins before start are rejected", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+		rm.SetSchedule(RoomScheduleWindow{
+			StartAt: time.Now().Add(time.Hour),
+			EndAt:   time.Now().Add(2 * time.Hour),
+		})
+
+		p := NewMockParticipant("early", types.CurrentProtocol, false, false)
+		require.ErrorIs(t, rm.Join(p, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom), ErrRoomNotStarted)
+	})
+
+	t.Run("early joins wait in the lobby until start", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+		rm.SetSchedule(RoomScheduleWindow{
+			StartAt:         time.Now().Add(time.Second),
+			EndAt:           time.Now().Add(time.Hour),
+			HoldBeforeStart: true,
+		})
+
+		p := NewMockParticipant("early", types.CurrentProtocol, false, false)
+		p.ClaimGrantsReturns(&auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomJoin: true, CanPublish: newBool(true), CanSubscribe: newBool(true)},
+		})
+		require.NoError(t, rm.Join(p, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom))
+		require.True(t, rm.IsParticipantHeld(p.Identity()))
+
+		require.Equal(t, 1, p.SetPermissionCallCount())
+		held := p.SetPermissionArgsForCall(0)
+		require.False(t, held.CanPublish)
+		require.False(t, held.CanSubscribe)
+
+		require.Eventually(t, func() bool {
+			return p.SetPermissionCallCount() == 2
+		}, 3*time.Second, 50*time.Millisecond)
+		released := p.SetPermissionArgsForCall(1)
+		require.True(t, released.CanPublish)
+		require.True(t, released.CanSubscribe)
+		require.False(t, rm.IsParticipantHeld(p.Identity()))
+	})
+
+	t.Run("room is closed at the end with a warning", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
+		rm.SetSchedule(RoomScheduleWindow{
+			EndAt:         time.Now().Add(2 * time.Second),
+			WarningBefore: 2 * time.Second,
+		})
+
+		for _, p := range rm.GetParticipants() {
+			require.Equal(t, 1, p.(*typesfakes.FakeLocalParticipant).SendDataPacketCallCount())
+		}
+		p := rm.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+		_, data := p.SendDataPacketArgsForCall(0)
+		dp := &livekit.DataPacket{}
+		require.NoError(t, proto.Unmarshal(data, dp))
+		require.Equal(t, RoomScheduleWarningTopic, dp.GetUser().GetTopic())
+		warning := &RoomScheduleWarning{}
+		require.NoError(t, json.Unmarshal(dp.GetUser().GetPayload(), warning))
+		require.LessOrEqual(t, warning.RemainingSeconds, int64(2))
+
+		require.Eventually(t, rm.IsClosed, 4*time.Second, 50*time.Millisecond)
+	})
+}
+
+func newBool(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"maps"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// RoomSnapshot is the state of a room that would be lost with the node hosting it. Rooms are snapshotted
+// periodically, so the state is restored when the room is created again on another node.
+type RoomSnapshot struct {
+	Room            *livekit.Room
+	Internal        *livekit.RoomInternal
+	AgentDispatches []*livekit.AgentDispatch
+	// subscription permissions of publishers, applied when they join again
+	SubscriptionPermissions map[livekit.ParticipantIdentity]*livekit.SubscriptionPermission
+	CreatedAt               time.Time
+}
+
+func (r *Room) Snapshot() *RoomSnapshot {
+	snapshot := &RoomSnapshot{
+		SubscriptionPermissions: make(map[livekit.ParticipantIdentity]*livekit.SubscriptionPermission),
+		CreatedAt:               time.Now(),
+	}
+	if internal := r.Internal(); internal != nil {
+		snapshot.Internal = utils.CloneProto(internal)
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	// participant counts are not part of the state restored
+	snapshot.Room = utils.CloneProto(r.protoRoom)
+	for _, ad := range r.agentDispatches {
+		di := utils.CloneProto(ad.AgentDispatch)
+		// jobs do not outlive the node running them
+		if di.State != nil {
+			di.State.Jobs = nil
+		}
+		snapshot.AgentDispatches = append(snapshot.AgentDispatches, di)
+	}
+
+	// permissions of participants yet to join again are kept for the next snapshot
+	for identity, permission := range r.restoredPermissions {
+		snapshot.SubscriptionPermissions[identity] = permission
+	}
+	for identity, p := range r.participants {
+		if permission, _ := p.SubscriptionPermission(); permission != nil {
+			snapshot.SubscriptionPermissions[identity] = utils.CloneProto(permission)
+		}
+	}
+	return snapshot
+}
+
+// RestoreSnapshot brings back the agent dispatches of a previous instance of the room, and keeps
+// subscription permissions until publishers join again. Room and internal state are restored when
+// the room is created.
+func (r *Room) RestoreSnapshot(snapshot *RoomSnapshot) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, dispatch := range snapshot.AgentDispatches {
+		if r.agentDispatches[dispatch.Id] != nil || r.hasAgentDispatchLocked(dispatch.AgentName, dispatch.Metadata) {
+			// dispatches of the room configuration are created with the room
+			continue
+		}
+		di := utils.CloneProto(dispatch)
+		if di.State == nil {
+			di.State = &livekit.AgentDispatchState{CreatedAt: time.Now().UnixNano()}
+		}
+		r.agentDispatches[di.Id] = newAgentDispatch(di)
+	}
+
+	r.restoredPermissions = maps.Clone(snapshot.SubscriptionPermissions)
+	r.Logger.Infow("restored room snapshot",
+		"snapshotAt", snapshot.CreatedAt,
+		"numAgentDispatches", len(snapshot.AgentDispatches),
+		"numSubscriptionPermissions", len(snapshot.SubscriptionPermissions),
+	)
+}
+
+func (r *Room) hasAgentDispatchLocked(agentName string, metadata string) bool {
+	for _, ad := range r.agentDispatches {
+		if ad.AgentName == agentName && ad.Metadata == metadata {
+			return true
+		}
+	}
+	return false
+}
+
+// RestoreSubscriptionPermission applies the permission a participant had before the room was restored,
+// unless the participant already set one.
+func (r *Room) RestoreSubscriptionPermission(participant types.LocalParticipant) {
+	r.lock.Lock()
+	permission := r.restoredPermissions[participant.Identity()]
+	delete(r.restoredPermissions, participant.Identity())
+	r.lock.Unlock()
+
+	if permission == nil {
+		return
+	}
+	if existing, _ := participant.SubscriptionPermission(); existing != nil {
+		return
+	}
+	if err := r.UpdateSubscriptionPermission(participant, permission); err != nil {
+		participant.GetLogger().Warnw("could not restore subscription permission", err)
+	}
+}
@@ -181,6 +181,17 @@ func (m *SubscriptionManager) UnsubscribeFromTrack(trackID livekit.TrackID) {
 	m.queueReconcile(trackID)
 }
 
+// UnsubscribeAll drops all desired subscriptions, used when the tracks can no longer be resolved
+func (m *SubscriptionManager) UnsubscribeAll() {
+	m.lock.RLock()
+	trackIDs := maps.Keys(m.subscriptions)
+	m.lock.RUnlock()
+
+	for _, trackID := range trackIDs {
+		m.UnsubscribeFromTrack(trackID)
+	}
+}
+
 func (m *SubscriptionManager) GetSubscribedTracks() []types.SubscribedTrack {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
@@ -0,0 +1,278 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/pion/webrtc/v4"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/livekit-server/pkg/sfu/relay"
+)
+
+var _ relay.Handler = (*TrackRelay)(nil)
+
+// subscriberNodeQualityNotifier is implemented by tracks which can be relayed, published or relayed themselves
+type subscriberNodeQualityNotifier interface {
+	NotifySubscriberNodeMaxQuality(nodeID livekit.NodeID, qualities []types.SubscribedCodecQuality)
+}
+
+type TrackRelayParams struct {
+	Source          types.MediaTrack
+	DestinationNode livekit.NodeID
+	Transport       *relay.Transport
+	Logger          logger.Logger
+}
+
+// TrackRelay sends a published track to a RelayedTrack on another node. It attaches to the receivers
+// of the track like a subscriber and sends the packets of every layer as received from the publisher.
+// Qualities subscribed on the other node are applied to the track as for a subscriber node,
+// layers above them are not sent.
+type TrackRelay struct {
+	params    TrackRelayParams
+	notifier  subscriberNodeQualityNotifier
+	sessionID uint64
+	address   atomic.Pointer[net.UDPAddr]
+	senders   []*relaySender
+
+	destinationTrackID atomic.String
+
+	lock    sync.Mutex
+	closed  bool
+	onClose func(t *TrackRelay)
+}
+
+func NewTrackRelay(params TrackRelayParams) (*TrackRelay, error) {
+	notifier, ok := params.Source.(subscriberNodeQualityNotifier)
+	if !ok {
+		return nil, ErrTrackNotRelayable
+	}
+
+	t := &TrackRelay{
+		params:   params,
+		notifier: notifier,
+	}
+	for _, r := range params.Source.Receivers() {
+		if _, ok := r.(*DummyReceiver); ok {
+			// codec not published yet
+			continue
+		}
+		t.senders = append(t.senders, &relaySender{
+			relay:    t,
+			codec:    len(t.senders),
+			receiver: r,
+			isSVC:    mime.IsMimeTypeSVC(r.Mime()),
+		})
+	}
+	if len(t.senders) == 0 {
+		return nil, ErrTrackNotBound
+	}
+
+	t.sessionID = params.Transport.NewSession(t)
+	for _, s := range t.senders {
+		s.maxLayer.Store(buffer.DefaultMaxLayerSpatial)
+		if err := s.receiver.AddDownTrack(s); err != nil {
+			t.Close()
+			return nil, err
+		}
+	}
+
+	params.Source.AddOnClose(func(_ bool) {
+		t.Close()
+	})
+	return t, nil
+}
+
+// SessionID identifies the relay on both nodes
+func (t *TrackRelay) SessionID() uint64 {
+	return t.sessionID
+}
+
+func (t *TrackRelay) SourceID() livekit.TrackID {
+	return t.params.Source.ID()
+}
+
+// Codecs lists the codecs in the order packets are indexed by
+func (t *TrackRelay) Codecs() []webrtc.RTPCodecParameters {
+	codecs := make([]webrtc.RTPCodecParameters, 0, len(t.senders))
+	for _, s := range t.senders {
+		codecs = append(codecs, s.receiver.Codec())
+	}
+	return codecs
+}
+
+func (t *TrackRelay) HeaderExtensions() []webrtc.RTPHeaderExtensionParameter {
+	return t.senders[0].receiver.HeaderExtensions()
+}
+
+// DestinationTrackID is the ID of the relayed track in the destination room, known once started
+func (t *TrackRelay) DestinationTrackID() livekit.TrackID {
+	return livekit.TrackID(t.destinationTrackID.Load())
+}
+
+// Start sends packets to the relay transport of the destination node, the relay is set up over psrpc in between
+func (t *TrackRelay) Start(address *net.UDPAddr, destinationTrackID livekit.TrackID) {
+	t.destinationTrackID.Store(string(destinationTrackID))
+	t.address.Store(address)
+	for _, s := range t.senders {
+		s.receiver.SendPLI(buffer.DefaultMaxLayerSpatial, true)
+	}
+}
+
+// UpdateSubscribedQualities applies qualities subscribed on the destination node
+func (t *TrackRelay) UpdateSubscribedQualities(qualities []types.SubscribedCodecQuality) {
+	ti := t.params.Source.ToProto()
+	for _, q := range qualities {
+		for _, s := range t.senders {
+			if s.receiver.Mime() == q.CodecMime {
+				s.maxLayer.Store(buffer.VideoQualityToSpatialLayer(q.Quality, ti))
+			}
+		}
+	}
+	t.notifier.NotifySubscriberNodeMaxQuality(t.subscriberNodeID(), qualities)
+}
+
+func (t *TrackRelay) subscriberNodeID() livekit.NodeID {
+	// several rooms on the destination node may receive the track, each relay reports as its own subscriber node
+	return livekit.NodeID(fmt.Sprintf("%s_%x", t.params.DestinationNode, t.sessionID))
+}
+
+func (t *TrackRelay) OnClose(f func(t *TrackRelay)) {
+	t.lock.Lock()
+	t.onClose = f
+	t.lock.Unlock()
+}
+
+func (t *TrackRelay) IsClosed() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.closed
+}
+
+func (t *TrackRelay) Close() {
+	t.lock.Lock()
+	if t.closed {
+		t.lock.Unlock()
+		return
+	}
+	t.closed = true
+	onClose := t.onClose
+	t.lock.Unlock()
+
+	t.params.Transport.RemoveSession(t.sessionID)
+	qualities := make([]types.SubscribedCodecQuality, 0, len(t.senders))
+	for _, s := range t.senders {
+		s.closed.Store(true)
+		s.receiver.DeleteDownTrack(s.SubscriberID())
+		qualities = append(qualities, types.SubscribedCodecQuality{CodecMime: s.receiver.Mime(), Quality: livekit.VideoQuality_OFF})
+	}
+	if t.params.Source.Kind() == livekit.TrackType_VIDEO {
+		t.notifier.NotifySubscriberNodeMaxQuality(t.subscriberNodeID(), qualities)
+	}
+
+	t.params.Logger.Debugw("track relay closed", "sessionID", t.sessionID)
+	if onClose != nil {
+		onClose(t)
+	}
+}
+
+func (t *TrackRelay) HandleRTP(_ int, _ int32, _ []byte) {}
+
+func (t *TrackRelay) HandleSenderReport(_ int, _ int32, _ *livekit.RTCPSenderReportState) {}
+
+func (t *TrackRelay) HandleKeyFrameRequest(codec int, layer int32) {
+	if codec < len(t.senders) {
+		t.senders[codec].receiver.SendPLI(layer, false)
+	}
+}
+
+// ---------------------------------------------------------
+
+var _ sfu.TrackSender = (*relaySender)(nil)
+
+// relaySender is attached to a receiver of the relayed track in place of a down track
+type relaySender struct {
+	relay    *TrackRelay
+	codec    int
+	receiver sfu.TrackReceiver
+	isSVC    bool
+	maxLayer atomic.Int32
+	closed   atomic.Bool
+}
+
+func (s *relaySender) UpTrackLayersChange()                           {}
+func (s *relaySender) UpTrackBitrateAvailabilityChange()              {}
+func (s *relaySender) UpTrackMaxPublishedLayerChange(_ int32)         {}
+func (s *relaySender) UpTrackMaxTemporalLayerSeenChange(_ int32)      {}
+func (s *relaySender) UpTrackBitrateReport(_ []int32, _ sfu.Bitrates) {}
+func (s *relaySender) Resync()                                        {}
+func (s *relaySender) SetReceiver(_ sfu.TrackReceiver)                {}
+
+func (s *relaySender) ID() string {
+	return fmt.Sprintf("RL_%x_%d", s.relay.sessionID, s.codec)
+}
+
+func (s *relaySender) SubscriberID() livekit.ParticipantID {
+	return livekit.ParticipantID(s.ID())
+}
+
+func (s *relaySender) IsClosed() bool {
+	return s.closed.Load()
+}
+
+// Close is called when the receiver closes, i. e. the publisher left
+func (s *relaySender) Close() {
+	s.relay.Close()
+}
+
+func (s *relaySender) WriteRTP(p *buffer.ExtPacket, layer int32) error {
+	addr := s.relay.address.Load()
+	if addr == nil || s.closed.Load() {
+		return nil
+	}
+	if s.isSVC {
+		// all spatial layers are in the single stream
+		layer = 0
+	} else if layer > s.maxLayer.Load() {
+		return nil
+	}
+	return s.relay.params.Transport.WriteRTP(addr, s.relay.sessionID, s.codec, layer, p.RawPacket)
+}
+
+func (s *relaySender) HandleRTCPSenderReportData(
+	_ webrtc.PayloadType,
+	isSVC bool,
+	layer int32,
+	publisherSRData *livekit.RTCPSenderReportState,
+) error {
+	addr := s.relay.address.Load()
+	if addr == nil || s.closed.Load() || publisherSRData == nil {
+		return nil
+	}
+	if isSVC {
+		layer = 0
+	}
+	return s.relay.params.Transport.WriteSenderReport(addr, s.relay.sessionID, s.codec, layer, publisherSRData)
+}
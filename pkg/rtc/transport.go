@@ -508,6 +508,7 @@ func NewPCTransport(params TransportParams) (*PCTransport, error) {
 			Logger:    params.Logger.WithComponent(utils.ComponentCongestionControl),
 		}, params.CongestionControlConfig.Enabled, params.CongestionControlConfig.AllowPause)
 		t.streamAllocator.OnStreamStateChange(params.Handler.OnStreamStateChange)
+		t.streamAllocator.OnChannelCongestionStateChange(params.Handler.OnCongestionStateChange)
 		t.streamAllocator.Start()
 
 		if bwe != nil {
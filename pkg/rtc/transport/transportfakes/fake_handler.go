@@ -6,6 +6,7 @@ import (
 
 	"github.com/livekit/livekit-server/pkg/rtc/transport"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/bwe"
 	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
 	"github.com/livekit/protocol/livekit"
 	webrtc "github.com/pion/webrtc/v4"
@@ -23,6 +24,13 @@ type FakeHandler struct {
 	onAnswerReturnsOnCall map[int]struct {
 		result1 error
 	}
+	OnCongestionStateChangeStub        func(bwe.CongestionState, bwe.CongestionState, int64)
+	onCongestionStateChangeMutex       sync.RWMutex
+	onCongestionStateChangeArgsForCall []struct {
+		arg1 bwe.CongestionState
+		arg2 bwe.CongestionState
+		arg3 int64
+	}
 	OnDataPacketStub        func(livekit.DataPacket_Kind, []byte)
 	onDataPacketMutex       sync.RWMutex
 	onDataPacketArgsForCall []struct {
@@ -162,6 +170,40 @@ func (fake *FakeHandler) OnAnswerReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeHandler) OnCongestionStateChange(arg1 bwe.CongestionState, arg2 bwe.CongestionState, arg3 int64) {
+	fake.onCongestionStateChangeMutex.Lock()
+	fake.onCongestionStateChangeArgsForCall = append(fake.onCongestionStateChangeArgsForCall, struct {
+		arg1 bwe.CongestionState
+		arg2 bwe.CongestionState
+		arg3 int64
+	}{arg1, arg2, arg3})
+	stub := fake.OnCongestionStateChangeStub
+	fake.recordInvocation("OnCongestionStateChange", []interface{}{arg1, arg2, arg3})
+	fake.onCongestionStateChangeMutex.Unlock()
+	if stub != nil {
+		fake.OnCongestionStateChangeStub(arg1, arg2, arg3)
+	}
+}
+
+func (fake *FakeHandler) OnCongestionStateChangeCallCount() int {
+	fake.onCongestionStateChangeMutex.RLock()
+	defer fake.onCongestionStateChangeMutex.RUnlock()
+	return len(fake.onCongestionStateChangeArgsForCall)
+}
+
+func (fake *FakeHandler) OnCongestionStateChangeCalls(stub func(bwe.CongestionState, bwe.CongestionState, int64)) {
+	fake.onCongestionStateChangeMutex.Lock()
+	defer fake.onCongestionStateChangeMutex.Unlock()
+	fake.OnCongestionStateChangeStub = stub
+}
+
+func (fake *FakeHandler) OnCongestionStateChangeArgsForCall(i int) (bwe.CongestionState, bwe.CongestionState, int64) {
+	fake.onCongestionStateChangeMutex.RLock()
+	defer fake.onCongestionStateChangeMutex.RUnlock()
+	argsForCall := fake.onCongestionStateChangeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
 func (fake *FakeHandler) OnDataPacket(arg1 livekit.DataPacket_Kind, arg2 []byte) {
 	var arg2Copy []byte
 	if arg2 != nil {
@@ -591,6 +633,8 @@ func (fake *FakeHandler) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.onAnswerMutex.RLock()
 	defer fake.onAnswerMutex.RUnlock()
+	fake.onCongestionStateChangeMutex.RLock()
+	defer fake.onCongestionStateChangeMutex.RUnlock()
 	fake.onDataPacketMutex.RLock()
 	defer fake.onDataPacketMutex.RUnlock()
 	fake.onDataSendErrorMutex.RLock()
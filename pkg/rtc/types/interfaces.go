@@ -111,6 +111,10 @@ const (
 	ParticipantCloseReasonRoomClosed
 	ParticipantCloseReasonUserUnavailable
 	ParticipantCloseReasonUserRejected
+	ParticipantCloseReasonMoveToRoom
+	ParticipantCloseReasonMaxDurationExceeded
+	ParticipantCloseReasonIdleTimeout
+	ParticipantCloseReasonNodeDraining
 )
 
 func (p ParticipantCloseReason) String() string {
@@ -169,6 +173,14 @@ func (p ParticipantCloseReason) String() string {
 		return "USER_UNAVAILABLE"
 	case ParticipantCloseReasonUserRejected:
 		return "USER_REJECTED"
+	case ParticipantCloseReasonMoveToRoom:
+		return "MOVE_TO_ROOM"
+	case ParticipantCloseReasonMaxDurationExceeded:
+		return "MAX_DURATION_EXCEEDED"
+	case ParticipantCloseReasonIdleTimeout:
+		return "IDLE_TIMEOUT"
+	case ParticipantCloseReasonNodeDraining:
+		return "NODE_DRAINING"
 	default:
 		return fmt.Sprintf("%d", int(p))
 	}
@@ -189,11 +201,12 @@ func (p ParticipantCloseReason) ToDisconnectReason() livekit.DisconnectReason {
 		return livekit.DisconnectReason_DUPLICATE_IDENTITY
 	case ParticipantCloseReasonMigrationRequested, ParticipantCloseReasonMigrationComplete, ParticipantCloseReasonSimulateMigration:
 		return livekit.DisconnectReason_MIGRATION
-	case ParticipantCloseReasonServiceRequestRemoveParticipant:
+	case ParticipantCloseReasonServiceRequestRemoveParticipant, ParticipantCloseReasonMoveToRoom,
+		ParticipantCloseReasonMaxDurationExceeded, ParticipantCloseReasonIdleTimeout:
 		return livekit.DisconnectReason_PARTICIPANT_REMOVED
 	case ParticipantCloseReasonServiceRequestDeleteRoom:
 		return livekit.DisconnectReason_ROOM_DELETED
-	case ParticipantCloseReasonSimulateNodeFailure, ParticipantCloseReasonSimulateServerLeave:
+	case ParticipantCloseReasonSimulateNodeFailure, ParticipantCloseReasonSimulateServerLeave, ParticipantCloseReasonNodeDraining:
 		return livekit.DisconnectReason_SERVER_SHUTDOWN
 	case ParticipantCloseReasonNegotiateFailed, ParticipantCloseReasonPublicationError, ParticipantCloseReasonSubscriptionError, ParticipantCloseReasonDataChannelError, ParticipantCloseReasonMigrateCodecMismatch:
 		return livekit.DisconnectReason_STATE_MISMATCH
@@ -227,6 +240,7 @@ const (
 	SignallingCloseReasonParticipantClose
 	SignallingCloseReasonDisconnectOnResume
 	SignallingCloseReasonDisconnectOnResumeNoMessages
+	SignallingCloseReasonFullReconnectNodeDraining
 )
 
 func (s SignallingCloseReason) String() string {
@@ -253,6 +267,8 @@ func (s SignallingCloseReason) String() string {
 		return "DISCONNECT_ON_RESUME"
 	case SignallingCloseReasonDisconnectOnResumeNoMessages:
 		return "DISCONNECT_ON_RESUME_NO_MESSAGES"
+	case SignallingCloseReasonFullReconnectNodeDraining:
+		return "FULL_RECONNECT_NODE_DRAINING"
 	default:
 		return fmt.Sprintf("%d", int(s))
 	}
@@ -308,6 +324,16 @@ type Participant interface {
 
 // -------------------------------------------------------
 
+// MoveToRoomParams holds the room scoped dependencies that are rebound when a participant
+// is moved to another room while keeping its transport
+type MoveToRoomParams struct {
+	RoomName           livekit.RoomName
+	TrackResolver      MediaTrackResolver
+	GetParticipantInfo func(pID livekit.ParticipantID) *livekit.ParticipantInfo
+}
+
+// -------------------------------------------------------
+
 type AddTrackParams struct {
 	Stereo bool
 	Red    bool
@@ -412,6 +438,9 @@ type LocalParticipant interface {
 	HandleReconnectAndSendResponse(reconnectReason livekit.ReconnectReason, reconnectResponse *livekit.ReconnectResponse) error
 	IssueFullReconnect(reason ParticipantCloseReason)
 
+	// MoveToRoom fires close callbacks for the current room and rebinds the participant to another room
+	MoveToRoom(params MoveToRoomParams)
+
 	// callbacks
 	OnStateChange(func(p LocalParticipant, state livekit.ParticipantInfo_State))
 	OnMigrateStateChange(func(p LocalParticipant, migrateState MigrateState))
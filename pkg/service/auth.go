@@ -16,14 +16,20 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/twitchtv/twirp"
 
 	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
 )
 
 const (
@@ -34,6 +40,8 @@ const (
 
 type grantsKey struct{}
 
+type policyClaimKey struct{}
+
 type grantsValue struct {
 	claims *auth.ClaimGrants
 	apiKey string
@@ -98,15 +106,56 @@ func (m *APIKeyAuthMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request,
 
 		// set grants in context
 		ctx := r.Context()
-		r = r.WithContext(context.WithValue(ctx, grantsKey{}, &grantsValue{
+		ctx = context.WithValue(ctx, grantsKey{}, &grantsValue{
 			claims: grants,
 			apiKey: v.APIKey(),
-		}))
+		})
+		if policyClaim := participantPolicyClaim(authToken, secret); policyClaim != nil {
+			ctx = context.WithValue(ctx, policyClaimKey{}, policyClaim)
+		}
+		r = r.WithContext(ctx)
 	}
 
 	next.ServeHTTP(w, r)
 }
 
+// participantPolicyClaim returns the participant policy claim of a verified token, it is not part of the grants
+func participantPolicyClaim(token string, secret string) json.RawMessage {
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil
+	}
+	claims := make(map[string]json.RawMessage)
+	if err = tok.Claims([]byte(secret), &claims); err != nil {
+		return nil
+	}
+	return claims[rtc.ParticipantPolicyClaim]
+}
+
+// tokenWithClaims signs a token like auth.AccessToken does, with claims in addition to the grants
+func tokenWithClaims(apiKey string, secret string, validFor time.Duration, grants *auth.ClaimGrants, claims map[string]any) (string, error) {
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(secret)},
+		(&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	cl := jwt.Claims{
+		Issuer:    apiKey,
+		NotBefore: jwt.NewNumericDate(now),
+		Expiry:    jwt.NewNumericDate(now.Add(validFor)),
+		Subject:   grants.Identity,
+	}
+	return jwt.Signed(sig).Claims(cl).Claims(grants).Claims(claims).CompactSerialize()
+}
+
+// GetParticipantPolicyClaim returns the participant policy claim of the request token
+func GetParticipantPolicyClaim(ctx context.Context) json.RawMessage {
+	claim, _ := ctx.Value(policyClaimKey{}).(json.RawMessage)
+	return claim
+}
+
 func WithAPIKey(ctx context.Context, grants *auth.ClaimGrants, apiKey string) context.Context {
 	return context.WithValue(ctx, grantsKey{}, &grantsValue{
 		claims: grants,
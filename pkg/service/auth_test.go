@@ -15,10 +15,14 @@
 package service_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/stretchr/testify/require"
 
 	"github.com/livekit/protocol/auth"
@@ -72,3 +76,40 @@ func TestAuthMiddleware(t *testing.T) {
 	require.Nil(t, grants)
 	require.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestAuthMiddlewarePolicyClaim(t *testing.T) {
+	api := "APIabcdefg"
+	secret := "somesecretencodedinbase62extendto32bytes"
+	provider := &authfakes.FakeKeyProvider{}
+	provider.GetSecretReturns(secret)
+
+	m := service.NewAPIKeyAuthMiddleware(provider)
+	var policyClaim json.RawMessage
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policyClaim = service.GetParticipantPolicyClaim(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	sig, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte(secret)}, nil)
+	require.NoError(t, err)
+	token, err := jwt.Signed(sig).
+		Claims(jwt.Claims{Issuer: api, Subject: "participant", Expiry: jwt.NewNumericDate(time.Now().Add(time.Minute))}).
+		Claims(&auth.ClaimGrants{Video: &auth.VideoGrant{Room: "room", RoomJoin: true}}).
+		Claims(map[string]any{"policy": map[string]string{"max_duration": "90m"}}).
+		CompactSerialize()
+	require.NoError(t, err)
+
+	r := &http.Request{Header: http.Header{}}
+	service.SetAuthorizationToken(r, token)
+	m.ServeHTTP(httptest.NewRecorder(), r, handler)
+	require.JSONEq(t, `{"max_duration":"90m"}`, string(policyClaim))
+
+	// tokens without the claim
+	policyClaim = nil
+	token, err = auth.NewAccessToken(api, secret).AddGrant(&auth.VideoGrant{Room: "room", RoomJoin: true}).ToJWT()
+	require.NoError(t, err)
+	r = &http.Request{Header: http.Header{}}
+	service.SetAuthorizationToken(r, token)
+	m.ServeHTTP(httptest.NewRecorder(), r, handler)
+	require.Nil(t, policyClaim)
+}
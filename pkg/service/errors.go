@@ -42,4 +42,24 @@ var (
 	ErrSIPTrunkNotFound                 = psrpc.NewErrorf(psrpc.NotFound, "requested sip trunk does not exist")
 	ErrSIPDispatchRuleNotFound          = psrpc.NewErrorf(psrpc.NotFound, "requested sip dispatch rule does not exist")
 	ErrSIPParticipantNotFound           = psrpc.NewErrorf(psrpc.NotFound, "requested sip participant does not exist")
+	ErrDestinationRoomOnOtherNode       = psrpc.NewErrorf(psrpc.FailedPrecondition, "destination room is hosted on another node")
+	ErrForwardToOtherNodeUnsupported    = psrpc.NewErrorf(psrpc.Unimplemented, "forwarding into a room hosted on another node requires rtc.relay to be enabled")
+	ErrMoveToSameRoom                   = psrpc.NewErrorf(psrpc.InvalidArgument, "destination room must differ from the current room")
+	ErrForwardToSameRoom                = psrpc.NewErrorf(psrpc.InvalidArgument, "tracks cannot be forwarded into their own room")
+	ErrTrackNotReceiving                = psrpc.NewErrorf(psrpc.FailedPrecondition, "track has not started receiving media")
+	ErrBridgeNotFound                   = psrpc.NewErrorf(psrpc.NotFound, "track is not forwarded to the destination room")
+	ErrRoomScheduleNotFound             = psrpc.NewErrorf(psrpc.NotFound, "room schedule does not exist")
+	ErrRoomParticipantPolicyNotFound    = psrpc.NewErrorf(psrpc.NotFound, "room participant policy does not exist")
+	ErrRoomQualityReportNotFound        = psrpc.NewErrorf(psrpc.NotFound, "room quality report does not exist")
+	ErrRoomSnapshotNotFound             = psrpc.NewErrorf(psrpc.NotFound, "room snapshot does not exist")
+	ErrInvalidRoomSchedule              = psrpc.NewErrorf(psrpc.InvalidArgument, "invalid room schedule")
+	ErrParticipantNotWaiting            = psrpc.NewErrorf(psrpc.NotFound, "participant is not waiting in the lobby")
+	ErrRoomNotStarted                   = psrpc.NewErrorf(psrpc.FailedPrecondition, "room has not started yet")
+	ErrRoomScheduleEnded                = psrpc.NewErrorf(psrpc.FailedPrecondition, "room schedule has ended")
+	ErrPacketCaptureDisabled            = psrpc.NewErrorf(psrpc.FailedPrecondition, "packet capture is not enabled")
+	ErrPacketCaptureNotFound            = psrpc.NewErrorf(psrpc.NotFound, "packet capture does not exist")
+	ErrInvalidPageToken                 = psrpc.NewErrorf(psrpc.InvalidArgument, "invalid page token")
+	ErrNodeOverloaded                   = psrpc.NewErrorf(psrpc.Unavailable, "node would be overloaded")
+	ErrRelayNotEnabled                  = psrpc.NewErrorf(psrpc.FailedPrecondition, "relay between nodes is not enabled")
+	ErrRelayedTrackNotFound             = psrpc.NewErrorf(psrpc.NotFound, "relayed track does not exist")
 )
@@ -0,0 +1,447 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+)
+
+const (
+	gossipKindRoom                  = "room"
+	gossipKindParticipant           = "participant"
+	gossipKindAgentDispatch         = "agent_dispatch"
+	gossipKindAgentJob              = "agent_job"
+	gossipKindRoomSchedule          = "room_schedule"
+	gossipKindRoomParticipantPolicy = "room_participant_policy"
+	gossipKindRoomQualityReport     = "room_quality_report"
+	gossipKindRoomEvents            = "room_events"
+	gossipKindRoomSnapshot          = "room_snapshot"
+
+	gossipRoomLockPrefix = "room_lock:"
+)
+
+// kinds removed along with their room
+var gossipRoomScopedKinds = map[string]bool{
+	gossipKindRoom:                  true,
+	gossipKindParticipant:           true,
+	gossipKindAgentDispatch:         true,
+	gossipKindAgentJob:              true,
+	gossipKindRoomSnapshot:          true,
+	gossipKindRoomParticipantPolicy: true,
+}
+
+type gossipRoom struct {
+	Room     []byte `json:"room"`
+	Internal []byte `json:"internal,omitempty"`
+}
+
+type gossipRoomSnapshot struct {
+	Room                    []byte            `json:"room"`
+	Internal                []byte            `json:"internal,omitempty"`
+	AgentDispatches         [][]byte          `json:"agent_dispatches,omitempty"`
+	SubscriptionPermissions map[string][]byte `json:"subscription_permissions,omitempty"`
+	CreatedAt               int64             `json:"created_at"`
+}
+
+type gossipRoomEvents struct {
+	Events  []*rtc.RoomEvent `json:"events"`
+	Dropped int64            `json:"dropped"`
+}
+
+// GossipStore shares rooms, participants and the per-room stores between the nodes of a gossip cluster.
+// Every write is replicated to all nodes and applied to the embedded LocalStore, which serves reads.
+// Room locks are kept by one node of the cluster.
+type GossipStore struct {
+	*LocalStore
+
+	gossip *routing.Gossip
+	// serializes appends to room journals
+	eventsLock sync.Mutex
+}
+
+func NewGossipStore(g *routing.Gossip) *GossipStore {
+	s := &GossipStore{
+		LocalStore: NewLocalStore(),
+		gossip:     g,
+	}
+	g.OnEntry(s.apply)
+	return s
+}
+
+func (s *GossipStore) StoreRoom(_ context.Context, room *livekit.Room, internal *livekit.RoomInternal) error {
+	if room.CreationTime == 0 {
+		now := time.Now()
+		room.CreationTime = now.Unix()
+		room.CreationTimeMs = now.UnixMilli()
+	}
+
+	var value gossipRoom
+	var err error
+	if value.Room, err = proto.Marshal(room); err != nil {
+		return err
+	}
+	if internal != nil {
+		if value.Internal, err = proto.Marshal(internal); err != nil {
+			return err
+		}
+	}
+	return s.setEntry(gossipKindRoom, livekit.RoomName(room.Name), "", value, time.Time{})
+}
+
+func (s *GossipStore) DeleteRoom(_ context.Context, roomName livekit.RoomName) error {
+	s.gossip.DeleteEntries(func(key routing.GossipKey) bool {
+		return key.Room == roomName && gossipRoomScopedKinds[key.Kind]
+	})
+	return nil
+}
+
+func (s *GossipStore) LockRoom(ctx context.Context, roomName livekit.RoomName, duration time.Duration) (string, error) {
+	token := guid.New("LOCK")
+	key := gossipRoomLockPrefix + string(roomName)
+
+	startTime := time.Now()
+	for {
+		locked, err := s.gossip.TryLock(ctx, key, token, duration)
+		if err != nil {
+			return "", err
+		}
+		if locked {
+			return token, nil
+		}
+
+		// stop waiting past lock duration
+		if time.Since(startTime) > duration {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return "", ErrRoomLockFailed
+}
+
+func (s *GossipStore) UnlockRoom(ctx context.Context, roomName livekit.RoomName, uid string) error {
+	unlocked, err := s.gossip.Unlock(ctx, gossipRoomLockPrefix+string(roomName), uid)
+	if err != nil {
+		return err
+	}
+	if !unlocked {
+		return ErrRoomUnlockFailed
+	}
+	return nil
+}
+
+func (s *GossipStore) StoreParticipant(_ context.Context, roomName livekit.RoomName, participant *livekit.ParticipantInfo) error {
+	return s.setProtoEntry(gossipKindParticipant, roomName, participant.Identity, participant)
+}
+
+func (s *GossipStore) DeleteParticipant(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) error {
+	s.deleteEntry(gossipKindParticipant, roomName, string(identity))
+	return nil
+}
+
+func (s *GossipStore) StoreAgentDispatch(_ context.Context, dispatch *livekit.AgentDispatch) error {
+	return s.setProtoEntry(gossipKindAgentDispatch, livekit.RoomName(dispatch.Room), dispatch.Id, dispatch)
+}
+
+func (s *GossipStore) DeleteAgentDispatch(_ context.Context, dispatch *livekit.AgentDispatch) error {
+	s.deleteEntry(gossipKindAgentDispatch, livekit.RoomName(dispatch.Room), dispatch.Id)
+	return nil
+}
+
+func (s *GossipStore) StoreAgentJob(_ context.Context, job *livekit.Job) error {
+	return s.setProtoEntry(gossipKindAgentJob, livekit.RoomName(job.Room.Name), job.Id, job)
+}
+
+func (s *GossipStore) DeleteAgentJob(_ context.Context, job *livekit.Job) error {
+	s.deleteEntry(gossipKindAgentJob, livekit.RoomName(job.Room.Name), job.Id)
+	return nil
+}
+
+func (s *GossipStore) StoreRoomSchedule(_ context.Context, schedule *RoomSchedule) error {
+	return s.setEntry(gossipKindRoomSchedule, livekit.RoomName(schedule.Room), "", schedule, time.Time{})
+}
+
+func (s *GossipStore) DeleteRoomSchedule(_ context.Context, roomName livekit.RoomName) error {
+	s.deleteEntry(gossipKindRoomSchedule, roomName, "")
+	return nil
+}
+
+func (s *GossipStore) StoreRoomParticipantPolicy(_ context.Context, policy *RoomParticipantPolicy) error {
+	return s.setEntry(gossipKindRoomParticipantPolicy, livekit.RoomName(policy.Room), "", policy, time.Time{})
+}
+
+func (s *GossipStore) StoreRoomQualityReport(_ context.Context, report *telemetry.QualityReport, retention time.Duration) error {
+	return s.setEntry(gossipKindRoomQualityReport, livekit.RoomName(report.RoomName), "", report, time.Now().Add(retention))
+}
+
+func (s *GossipStore) StoreRoomSnapshot(_ context.Context, snapshot *rtc.RoomSnapshot, retention time.Duration) error {
+	value := gossipRoomSnapshot{
+		SubscriptionPermissions: make(map[string][]byte, len(snapshot.SubscriptionPermissions)),
+		CreatedAt:               snapshot.CreatedAt.UnixMilli(),
+	}
+	var err error
+	if value.Room, err = proto.Marshal(snapshot.Room); err != nil {
+		return err
+	}
+	if snapshot.Internal != nil {
+		if value.Internal, err = proto.Marshal(snapshot.Internal); err != nil {
+			return err
+		}
+	}
+	for _, dispatch := range snapshot.AgentDispatches {
+		data, err := proto.Marshal(dispatch)
+		if err != nil {
+			return err
+		}
+		value.AgentDispatches = append(value.AgentDispatches, data)
+	}
+	for identity, permission := range snapshot.SubscriptionPermissions {
+		if value.SubscriptionPermissions[string(identity)], err = proto.Marshal(permission); err != nil {
+			return err
+		}
+	}
+	return s.setEntry(gossipKindRoomSnapshot, livekit.RoomName(snapshot.Room.Name), "", value, time.Now().Add(retention))
+}
+
+func (s *GossipStore) DeleteRoomSnapshot(_ context.Context, roomName livekit.RoomName) error {
+	s.deleteEntry(gossipKindRoomSnapshot, roomName, "")
+	return nil
+}
+
+// AppendRoomEvents replicates the whole journal of the room, events are appended by the node hosting it
+func (s *GossipStore) AppendRoomEvents(_ context.Context, roomName livekit.RoomName, events []*rtc.RoomEvent, retention time.Duration, maxEvents int) error {
+	s.eventsLock.Lock()
+	defer s.eventsLock.Unlock()
+
+	journal, dropped := s.loadRoomEvents(roomName)
+	journal = append(journal, events...)
+	if maxEvents > 0 && len(journal) > maxEvents {
+		drop := len(journal) - maxEvents
+		journal = journal[drop:]
+		dropped += int64(drop)
+	}
+	value := gossipRoomEvents{Events: journal, Dropped: dropped}
+	return s.setEntry(gossipKindRoomEvents, roomName, "", value, time.Now().Add(retention))
+}
+
+func (s *GossipStore) setProtoEntry(kind string, roomName livekit.RoomName, id string, msg proto.Message) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.gossip.SetEntry(routing.GossipKey{Kind: kind, Room: roomName, ID: id}, data, time.Time{})
+	return nil
+}
+
+func (s *GossipStore) setEntry(kind string, roomName livekit.RoomName, id string, value any, expiresAt time.Time) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.gossip.SetEntry(routing.GossipKey{Kind: kind, Room: roomName, ID: id}, data, expiresAt)
+	return nil
+}
+
+func (s *GossipStore) deleteEntry(kind string, roomName livekit.RoomName, id string) {
+	key := routing.GossipKey{Kind: kind, Room: roomName, ID: id}
+	s.gossip.DeleteEntries(func(k routing.GossipKey) bool {
+		return k == key
+	})
+}
+
+// apply updates the local view with an entry written on any node
+func (s *GossipStore) apply(key routing.GossipKey, value []byte, expiresAt time.Time, deleted bool) {
+	var err error
+	if deleted {
+		s.applyDelete(key)
+	} else {
+		err = s.applyValue(key, value, expiresAt)
+	}
+	if err != nil {
+		logger.Warnw("could not apply replicated entry", err, "kind", key.Kind, "room", key.Room, "id", key.ID)
+	}
+}
+
+func (s *GossipStore) applyValue(key routing.GossipKey, value []byte, expiresAt time.Time) error {
+	ctx := context.Background()
+	switch key.Kind {
+	case gossipKindRoom:
+		var v gossipRoom
+		if err := json.Unmarshal(value, &v); err != nil {
+			return err
+		}
+		room := &livekit.Room{}
+		if err := proto.Unmarshal(v.Room, room); err != nil {
+			return err
+		}
+		var internal *livekit.RoomInternal
+		if v.Internal != nil {
+			internal = &livekit.RoomInternal{}
+			if err := proto.Unmarshal(v.Internal, internal); err != nil {
+				return err
+			}
+		}
+		return s.LocalStore.StoreRoom(ctx, room, internal)
+
+	case gossipKindParticipant:
+		participant := &livekit.ParticipantInfo{}
+		if err := proto.Unmarshal(value, participant); err != nil {
+			return err
+		}
+		return s.LocalStore.StoreParticipant(ctx, key.Room, participant)
+
+	case gossipKindAgentDispatch:
+		dispatch := &livekit.AgentDispatch{}
+		if err := proto.Unmarshal(value, dispatch); err != nil {
+			return err
+		}
+		return s.LocalStore.StoreAgentDispatch(ctx, dispatch)
+
+	case gossipKindAgentJob:
+		job := &livekit.Job{}
+		if err := proto.Unmarshal(value, job); err != nil {
+			return err
+		}
+		return s.LocalStore.StoreAgentJob(ctx, job)
+
+	case gossipKindRoomSchedule:
+		schedule := &RoomSchedule{}
+		if err := json.Unmarshal(value, schedule); err != nil {
+			return err
+		}
+		return s.LocalStore.StoreRoomSchedule(ctx, schedule)
+
+	case gossipKindRoomParticipantPolicy:
+		policy := &RoomParticipantPolicy{}
+		if err := json.Unmarshal(value, policy); err != nil {
+			return err
+		}
+		return s.LocalStore.StoreRoomParticipantPolicy(ctx, policy)
+
+	case gossipKindRoomQualityReport:
+		report := &telemetry.QualityReport{}
+		if err := json.Unmarshal(value, report); err != nil {
+			return err
+		}
+		s.storeRoomQualityReport(report, expiresAt)
+
+	case gossipKindRoomEvents:
+		var v gossipRoomEvents
+		if err := json.Unmarshal(value, &v); err != nil {
+			return err
+		}
+		s.storeRoomEvents(key.Room, v.Events, v.Dropped, expiresAt)
+
+	case gossipKindRoomSnapshot:
+		snapshot, err := decodeGossipRoomSnapshot(value)
+		if err != nil {
+			return err
+		}
+		s.storeRoomSnapshot(snapshot, expiresAt)
+	}
+	return nil
+}
+
+func (s *GossipStore) applyDelete(key routing.GossipKey) {
+	ctx := context.Background()
+	switch key.Kind {
+	case gossipKindRoom:
+		// entries of the room are deleted on their own
+		s.lock.Lock()
+		delete(s.rooms, key.Room)
+		delete(s.roomInternal, key.Room)
+		s.lock.Unlock()
+
+	case gossipKindParticipant:
+		_ = s.LocalStore.DeleteParticipant(ctx, key.Room, livekit.ParticipantIdentity(key.ID))
+
+	case gossipKindAgentDispatch:
+		_ = s.LocalStore.DeleteAgentDispatch(ctx, &livekit.AgentDispatch{Id: key.ID, Room: string(key.Room)})
+
+	case gossipKindAgentJob:
+		_ = s.LocalStore.DeleteAgentJob(ctx, &livekit.Job{Id: key.ID, Room: &livekit.Room{Name: string(key.Room)}})
+
+	case gossipKindRoomSchedule:
+		_ = s.LocalStore.DeleteRoomSchedule(ctx, key.Room)
+
+	case gossipKindRoomParticipantPolicy:
+		s.lock.Lock()
+		delete(s.roomParticipantPolicies, key.Room)
+		s.lock.Unlock()
+
+	case gossipKindRoomQualityReport:
+		s.lock.Lock()
+		delete(s.roomQualityReports, key.Room)
+		s.lock.Unlock()
+
+	case gossipKindRoomEvents:
+		s.lock.Lock()
+		delete(s.roomEvents, key.Room)
+		s.lock.Unlock()
+
+	case gossipKindRoomSnapshot:
+		_ = s.LocalStore.DeleteRoomSnapshot(ctx, key.Room)
+	}
+}
+
+func decodeGossipRoomSnapshot(value []byte) (*rtc.RoomSnapshot, error) {
+	var v gossipRoomSnapshot
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, err
+	}
+
+	snapshot := &rtc.RoomSnapshot{
+		Room:                    &livekit.Room{},
+		SubscriptionPermissions: make(map[livekit.ParticipantIdentity]*livekit.SubscriptionPermission, len(v.SubscriptionPermissions)),
+		CreatedAt:               time.UnixMilli(v.CreatedAt),
+	}
+	if err := proto.Unmarshal(v.Room, snapshot.Room); err != nil {
+		return nil, err
+	}
+	if v.Internal != nil {
+		snapshot.Internal = &livekit.RoomInternal{}
+		if err := proto.Unmarshal(v.Internal, snapshot.Internal); err != nil {
+			return nil, err
+		}
+	}
+	for _, data := range v.AgentDispatches {
+		dispatch := &livekit.AgentDispatch{}
+		if err := proto.Unmarshal(data, dispatch); err != nil {
+			return nil, err
+		}
+		snapshot.AgentDispatches = append(snapshot.AgentDispatches, dispatch)
+	}
+	for identity, data := range v.SubscriptionPermissions {
+		permission := &livekit.SubscriptionPermission{}
+		if err := proto.Unmarshal(data, permission); err != nil {
+			return nil, err
+		}
+		snapshot.SubscriptionPermissions[livekit.ParticipantIdentity(identity)] = permission
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/routingfakes"
+	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/service/servicefakes"
+)
+
+const gossipTestTimeout = 3 * time.Second
+
+func newTestGossip(t *testing.T, nodeID livekit.NodeID, seeds ...string) *routing.Gossip {
+	node, err := routing.NewLocalNodeFromNodeProto(&livekit.Node{
+		Id:    string(nodeID),
+		Ip:    "127.0.0.1",
+		State: livekit.NodeState_SERVING,
+	})
+	require.NoError(t, err)
+
+	g, err := routing.NewGossip(config.ClusterConfig{
+		BindAddress:    "127.0.0.1:0",
+		Seeds:          seeds,
+		GossipInterval: 20 * time.Millisecond,
+		NodeTimeout:    300 * time.Millisecond,
+		Key:            "cluster secret",
+	}, node)
+	require.NoError(t, err)
+	require.NoError(t, g.Start())
+	t.Cleanup(g.Stop)
+
+	require.Eventually(t, func() bool {
+		nodes, err := g.Nodes()
+		return err == nil && len(nodes) == len(seeds)+1
+	}, gossipTestTimeout, 10*time.Millisecond)
+	return g
+}
+
+// hostingNode serves participant and room requests the way the node hosting a room does
+type hostingNode struct {
+	store *service.GossipStore
+
+	lock    sync.Mutex
+	removed []livekit.ParticipantIdentity
+	deleted []livekit.RoomName
+}
+
+func (h *hostingNode) RemoveParticipant(ctx context.Context, req *livekit.RoomParticipantIdentity) (*livekit.RemoveParticipantResponse, error) {
+	h.lock.Lock()
+	h.removed = append(h.removed, livekit.ParticipantIdentity(req.Identity))
+	h.lock.Unlock()
+	return &livekit.RemoveParticipantResponse{}, h.store.DeleteParticipant(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity))
+}
+
+func (h *hostingNode) MutePublishedTrack(context.Context, *livekit.MuteRoomTrackRequest) (*livekit.MuteRoomTrackResponse, error) {
+	return &livekit.MuteRoomTrackResponse{}, nil
+}
+
+func (h *hostingNode) UpdateParticipant(_ context.Context, req *livekit.UpdateParticipantRequest) (*livekit.ParticipantInfo, error) {
+	return &livekit.ParticipantInfo{Identity: req.Identity}, nil
+}
+
+func (h *hostingNode) UpdateSubscriptions(context.Context, *livekit.UpdateSubscriptionsRequest) (*livekit.UpdateSubscriptionsResponse, error) {
+	return &livekit.UpdateSubscriptionsResponse{}, nil
+}
+
+func (h *hostingNode) DeleteRoom(_ context.Context, req *livekit.DeleteRoomRequest) (*livekit.DeleteRoomResponse, error) {
+	h.lock.Lock()
+	h.deleted = append(h.deleted, livekit.RoomName(req.Room))
+	h.lock.Unlock()
+	return &livekit.DeleteRoomResponse{}, nil
+}
+
+func (h *hostingNode) SendData(context.Context, *livekit.SendDataRequest) (*livekit.SendDataResponse, error) {
+	return &livekit.SendDataResponse{}, nil
+}
+
+func (h *hostingNode) UpdateRoomMetadata(_ context.Context, req *livekit.UpdateRoomMetadataRequest) (*livekit.Room, error) {
+	return &livekit.Room{Name: req.Room, Metadata: req.Metadata}, nil
+}
+
+func TestGossipStore(t *testing.T) {
+	const roomName = livekit.RoomName("shared")
+
+	g1 := newTestGossip(t, "ND_HOST")
+	g2 := newTestGossip(t, "ND_API", g1.Address())
+	hostStore := service.NewGossipStore(g1)
+	apiStore := service.NewGossipStore(g2)
+
+	ctx := context.Background()
+	topicFormatter := rpc.NewTopicFormatter()
+
+	// the room and its participants live on the hosting node
+	host := &hostingNode{store: hostStore}
+	hostBus := routing.NewGossipMessageBus(g1)
+	participantServer, err := rpc.NewTypedParticipantServer(host, hostBus)
+	require.NoError(t, err)
+	t.Cleanup(participantServer.Kill)
+	roomServer, err := rpc.NewTypedRoomServer(host, hostBus)
+	require.NoError(t, err)
+	t.Cleanup(roomServer.Kill)
+	for _, identity := range []livekit.ParticipantIdentity{"alice", "bob"} {
+		require.NoError(t, participantServer.RegisterAllParticipantTopics(topicFormatter.ParticipantTopic(ctx, roomName, identity)))
+	}
+	require.NoError(t, roomServer.RegisterAllRoomTopics(topicFormatter.RoomTopic(ctx, roomName)))
+
+	require.NoError(t, hostStore.StoreRoom(ctx, &livekit.Room{Sid: "RM_shared", Name: string(roomName)}, &livekit.RoomInternal{}))
+	require.NoError(t, hostStore.StoreParticipant(ctx, roomName, &livekit.ParticipantInfo{Sid: "PA_alice", Identity: "alice"}))
+	require.NoError(t, hostStore.StoreParticipant(ctx, roomName, &livekit.ParticipantInfo{Sid: "PA_bob", Identity: "bob"}))
+
+	// RoomService is called on a node that does not host the room
+	apiBus := routing.NewGossipMessageBus(g2)
+	roomClient, err := rpc.NewTypedRoomClient(rpc.ClientParams{Bus: apiBus})
+	require.NoError(t, err)
+	participantClient, err := rpc.NewTypedParticipantClient(rpc.ClientParams{Bus: apiBus})
+	require.NoError(t, err)
+	svc, err := service.NewRoomService(
+		&config.Config{},
+		config.APIConfig{ExecutionTimeout: 2 * time.Second},
+		&routingfakes.FakeRouter{},
+		&servicefakes.FakeRoomAllocator{},
+		apiStore,
+		nil,
+		topicFormatter,
+		roomClient,
+		participantClient,
+		nil,
+	)
+	require.NoError(t, err)
+	grants := &auth.ClaimGrants{Video: &auth.VideoGrant{RoomAdmin: true, RoomCreate: true, Room: string(roomName)}}
+	apiCtx := service.WithGrants(ctx, grants, "")
+
+	t.Run("participants are listed", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			res, err := svc.ListParticipants(apiCtx, &livekit.ListParticipantsRequest{Room: string(roomName)})
+			return err == nil && len(res.Participants) == 2
+		}, gossipTestTimeout, 10*time.Millisecond)
+	})
+
+	t.Run("participants are removed on the hosting node", func(t *testing.T) {
+		_, err := svc.RemoveParticipant(apiCtx, &livekit.RoomParticipantIdentity{Room: string(roomName), Identity: "bob"})
+		require.NoError(t, err)
+
+		host.lock.Lock()
+		require.Equal(t, []livekit.ParticipantIdentity{"bob"}, host.removed)
+		host.lock.Unlock()
+		require.Eventually(t, func() bool {
+			res, err := svc.ListParticipants(apiCtx, &livekit.ListParticipantsRequest{Room: string(roomName)})
+			return err == nil && len(res.Participants) == 1 && res.Participants[0].Identity == "alice"
+		}, gossipTestTimeout, 10*time.Millisecond)
+	})
+
+	t.Run("rooms are locked across nodes", func(t *testing.T) {
+		token, err := hostStore.LockRoom(ctx, roomName, 5*time.Second)
+		require.NoError(t, err)
+
+		_, err = apiStore.LockRoom(ctx, roomName, 200*time.Millisecond)
+		require.ErrorIs(t, err, service.ErrRoomLockFailed)
+		require.ErrorIs(t, apiStore.UnlockRoom(ctx, roomName, "LOCK_other"), service.ErrRoomUnlockFailed)
+
+		require.NoError(t, hostStore.UnlockRoom(ctx, roomName, token))
+		token, err = apiStore.LockRoom(ctx, roomName, 200*time.Millisecond)
+		require.NoError(t, err)
+		require.NoError(t, apiStore.UnlockRoom(ctx, roomName, token))
+	})
+
+	t.Run("rooms are deleted on every node", func(t *testing.T) {
+		_, err := svc.DeleteRoom(apiCtx, &livekit.DeleteRoomRequest{Room: string(roomName)})
+		require.NoError(t, err)
+
+		host.lock.Lock()
+		require.Equal(t, []livekit.RoomName{roomName}, host.deleted)
+		host.lock.Unlock()
+		require.Eventually(t, func() bool {
+			_, _, err := hostStore.LoadRoom(ctx, roomName, false)
+			participants, _ := hostStore.ListParticipants(ctx, roomName)
+			return err == service.ErrRoomNotFound && len(participants) == 0
+		}, gossipTestTimeout, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pion/turn/v4"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/selector"
+)
+
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+
+	// node stats are refreshed on keepalive pings going through the message bus,
+	// stale stats mean the bus or the node is stuck. a few missed updates are tolerated,
+	// updates can be late and their timestamp has a resolution of a second
+	healthMaxStatsAge  = 3 * routing.StatsUpdateInterval
+	healthCheckTimeout = 2 * time.Second
+
+	HealthStatusOK       = "ok"
+	HealthStatusNotReady = "not_ready"
+	HealthStatusDisabled = "disabled"
+)
+
+type HealthCheck struct {
+	Status  string         `json:"status"`
+	Message string         `json:"message,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type HealthReport struct {
+	Status  string                  `json:"status"`
+	NodeID  string                  `json:"node_id"`
+	Version string                  `json:"version,omitempty"`
+	Checks  map[string]*HealthCheck `json:"checks,omitempty"`
+}
+
+func (h *HealthReport) IsOK() bool {
+	return h.Status == HealthStatusOK
+}
+
+// healthChecker reports whether the node is alive, and whether it should be sent new sessions
+type healthChecker struct {
+	conf        *config.Config
+	currentNode routing.LocalNode
+	router      routing.Router
+	redisClient redis.UniversalClient
+	turnServer  *turn.Server
+	version     string
+	isRunning   func() bool
+}
+
+// Liveness only depends on the process, a node that cannot reach its dependencies is not restarted
+func (h *healthChecker) Liveness() *HealthReport {
+	report := &HealthReport{
+		Status:  HealthStatusOK,
+		NodeID:  string(h.currentNode.NodeID()),
+		Version: h.version,
+	}
+	if !h.isRunning() {
+		report.Status = HealthStatusNotReady
+	}
+	return report
+}
+
+func (h *healthChecker) Readiness(ctx context.Context) *HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	report := &HealthReport{
+		Status:  HealthStatusOK,
+		NodeID:  string(h.currentNode.NodeID()),
+		Version: h.version,
+		Checks: map[string]*HealthCheck{
+			"server": h.checkRunning(),
+			"router": h.checkRouter(),
+			"redis":  h.checkRedis(ctx),
+			"psrpc":  h.checkMessageBus(),
+			"drain":  h.checkDrain(),
+			"limits": h.checkLimits(),
+			"turn":   h.checkTURN(),
+		},
+	}
+	for _, check := range report.Checks {
+		if check.Status == HealthStatusNotReady {
+			report.Status = HealthStatusNotReady
+			break
+		}
+	}
+	return report
+}
+
+func (h *healthChecker) checkRunning() *HealthCheck {
+	if !h.isRunning() {
+		return &HealthCheck{Status: HealthStatusNotReady, Message: "server is not running"}
+	}
+	return &HealthCheck{Status: HealthStatusOK}
+}
+
+func (h *healthChecker) checkRouter() *HealthCheck {
+	nodes, err := h.router.ListNodes()
+	if err != nil {
+		return &HealthCheck{Status: HealthStatusNotReady, Message: err.Error()}
+	}
+	for _, n := range nodes {
+		if livekit.NodeID(n.Id) == h.currentNode.NodeID() {
+			return &HealthCheck{
+				Status:  HealthStatusOK,
+				Details: map[string]any{"nodes": len(nodes)},
+			}
+		}
+	}
+	return &HealthCheck{Status: HealthStatusNotReady, Message: "node is not registered"}
+}
+
+func (h *healthChecker) checkRedis(ctx context.Context) *HealthCheck {
+	if h.redisClient == nil {
+		return &HealthCheck{Status: HealthStatusDisabled}
+	}
+
+	start := time.Now()
+	if err := h.redisClient.Ping(ctx).Err(); err != nil {
+		return &HealthCheck{Status: HealthStatusNotReady, Message: err.Error()}
+	}
+	return &HealthCheck{
+		Status:  HealthStatusOK,
+		Details: map[string]any{"latency_ms": time.Since(start).Milliseconds()},
+	}
+}
+
+func (h *healthChecker) checkMessageBus() *HealthCheck {
+	stats := h.currentNode.Clone().Stats
+	if stats == nil {
+		return &HealthCheck{Status: HealthStatusNotReady, Message: "node stats not available"}
+	}
+
+	age := time.Since(time.Unix(stats.UpdatedAt, 0))
+	check := &HealthCheck{
+		Status:  HealthStatusOK,
+		Details: map[string]any{"stats_updated_at": stats.UpdatedAt},
+	}
+	if age > healthMaxStatsAge {
+		check.Status = HealthStatusNotReady
+		check.Message = fmt.Sprintf("node stats not updated for %s", age.Truncate(time.Second))
+	}
+	return check
+}
+
+func (h *healthChecker) checkDrain() *HealthCheck {
+	state := h.currentNode.Clone().State
+	check := &HealthCheck{
+		Status:  HealthStatusOK,
+		Details: map[string]any{"state": state.String()},
+	}
+	if state != livekit.NodeState_SERVING {
+		check.Status = HealthStatusNotReady
+		check.Message = "node is not serving"
+	}
+	return check
+}
+
+func (h *healthChecker) checkLimits() *HealthCheck {
+	stats := h.currentNode.Clone().Stats
+	check := &HealthCheck{Status: HealthStatusOK}
+	if stats != nil {
+		check.Details = map[string]any{
+			"num_tracks":    stats.NumTracksIn + stats.NumTracksOut,
+			"bytes_per_sec": stats.BytesInPerSec + stats.BytesOutPerSec,
+		}
+	}
+	if selector.LimitsReached(h.conf.Limit, stats) {
+		check.Status = HealthStatusNotReady
+		check.Message = "node limits reached"
+	}
+	return check
+}
+
+func (h *healthChecker) checkTURN() *HealthCheck {
+	if !h.conf.TURN.Enabled {
+		return &HealthCheck{Status: HealthStatusDisabled}
+	}
+	if h.turnServer == nil {
+		return &HealthCheck{Status: HealthStatusNotReady, Message: "TURN server is not running"}
+	}
+	return &HealthCheck{
+		Status: HealthStatusOK,
+		Details: map[string]any{
+			"allocations": h.turnServer.AllocationCount(),
+			"udp_port":    h.conf.TURN.UDPPort,
+			"tls_port":    h.conf.TURN.TLSPort,
+		},
+	}
+}
+
+// ---------------------------------------------------------------
+
+func (s *LivekitServer) healthz(w http.ResponseWriter, _ *http.Request) {
+	writeHealthReport(w, s.health.Liveness())
+}
+
+func (s *LivekitServer) readyz(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, s.health.Readiness(r.Context()))
+}
+
+func writeHealthReport(w http.ResponseWriter, report *HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !report.IsOK() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
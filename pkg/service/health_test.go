@@ -0,0 +1,138 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/routingfakes"
+)
+
+func newTestHealthChecker(t *testing.T) (*healthChecker, *routing.LocalNodeImpl, *routingfakes.FakeRouter) {
+	node, err := routing.NewLocalNodeFromNodeProto(&livekit.Node{
+		Id:    "ND_test",
+		State: livekit.NodeState_SERVING,
+		Stats: &livekit.NodeStats{UpdatedAt: time.Now().Unix()},
+	})
+	require.NoError(t, err)
+
+	router := &routingfakes.FakeRouter{}
+	router.ListNodesReturns([]*livekit.Node{node.Clone()}, nil)
+
+	return &healthChecker{
+		conf:        &config.Config{},
+		currentNode: node,
+		router:      router,
+		isRunning:   func() bool { return true },
+	}, node, router
+}
+
+func TestHealthChecker(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		h, _, _ := newTestHealthChecker(t)
+		report := h.Readiness(context.Background())
+		require.True(t, report.IsOK(), report)
+		require.Equal(t, HealthStatusDisabled, report.Checks["redis"].Status)
+		require.Equal(t, HealthStatusDisabled, report.Checks["turn"].Status)
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		h, node, _ := newTestHealthChecker(t)
+		node.SetState(livekit.NodeState_SHUTTING_DOWN)
+
+		report := h.Readiness(context.Background())
+		require.False(t, report.IsOK())
+		require.Equal(t, HealthStatusNotReady, report.Checks["drain"].Status)
+		// still alive
+		require.True(t, h.Liveness().IsOK())
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		h, _, router := newTestHealthChecker(t)
+		router.ListNodesReturns(nil, nil)
+		require.Equal(t, HealthStatusNotReady, h.Readiness(context.Background()).Checks["router"].Status)
+
+		router.ListNodesReturns(nil, errors.New("connection refused"))
+		check := h.Readiness(context.Background()).Checks["router"]
+		require.Equal(t, HealthStatusNotReady, check.Status)
+		require.Equal(t, "connection refused", check.Message)
+	})
+
+	t.Run("stale stats", func(t *testing.T) {
+		h, node, _ := newTestHealthChecker(t)
+		node.SetStats(&livekit.NodeStats{UpdatedAt: time.Now().Add(-time.Minute).Unix()})
+		require.Equal(t, HealthStatusNotReady, h.Readiness(context.Background()).Checks["psrpc"].Status)
+	})
+
+	t.Run("late stats", func(t *testing.T) {
+		h, node, _ := newTestHealthChecker(t)
+		// a couple of late updates keep the node ready
+		node.SetStats(&livekit.NodeStats{UpdatedAt: time.Now().Add(-healthMaxStatsAge + time.Second).Unix()})
+		require.Equal(t, HealthStatusOK, h.Readiness(context.Background()).Checks["psrpc"].Status)
+
+		node.SetStats(&livekit.NodeStats{UpdatedAt: time.Now().Add(-healthMaxStatsAge - 2*time.Second).Unix()})
+		require.Equal(t, HealthStatusNotReady, h.Readiness(context.Background()).Checks["psrpc"].Status)
+	})
+
+	t.Run("limits reached", func(t *testing.T) {
+		h, node, _ := newTestHealthChecker(t)
+		h.conf.Limit.NumTracks = 10
+		node.SetStats(&livekit.NodeStats{UpdatedAt: time.Now().Unix(), NumTracksIn: 4, NumTracksOut: 6})
+		require.Equal(t, HealthStatusNotReady, h.Readiness(context.Background()).Checks["limits"].Status)
+	})
+
+	t.Run("turn not running", func(t *testing.T) {
+		h, _, _ := newTestHealthChecker(t)
+		h.conf.TURN.Enabled = true
+		require.Equal(t, HealthStatusNotReady, h.Readiness(context.Background()).Checks["turn"].Status)
+	})
+
+	t.Run("http", func(t *testing.T) {
+		h, node, _ := newTestHealthChecker(t)
+		s := &LivekitServer{health: h}
+
+		w := httptest.NewRecorder()
+		s.readyz(w, httptest.NewRequest(http.MethodGet, readyzPath, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		node.SetState(livekit.NodeState_SHUTTING_DOWN)
+		w = httptest.NewRecorder()
+		s.readyz(w, httptest.NewRequest(http.MethodGet, readyzPath, nil))
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+		var report HealthReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		require.Equal(t, HealthStatusNotReady, report.Status)
+		require.Equal(t, "ND_test", report.NodeID)
+		require.Equal(t, "SHUTTING_DOWN", report.Checks["drain"].Details["state"])
+
+		w = httptest.NewRecorder()
+		s.healthz(w, httptest.NewRequest(http.MethodGet, healthzPath, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
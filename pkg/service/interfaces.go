@@ -18,6 +18,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 )
 
@@ -42,6 +46,12 @@ type ObjectStore interface {
 
 //counterfeiter:generate . ServiceStore
 type ServiceStore interface {
+	RoomScheduleStore
+	RoomParticipantPolicyStore
+	RoomQualityReportStore
+	RoomEventStore
+	RoomSnapshotStore
+
 	LoadRoom(ctx context.Context, roomName livekit.RoomName, includeInternal bool) (*livekit.Room, *livekit.RoomInternal, error)
 	DeleteRoom(ctx context.Context, roomName livekit.RoomName) error
 
@@ -52,6 +62,47 @@ type ServiceStore interface {
 	ListParticipants(ctx context.Context, roomName livekit.RoomName) ([]*livekit.ParticipantInfo, error)
 }
 
+//counterfeiter:generate . RoomScheduleStore
+type RoomScheduleStore interface {
+	StoreRoomSchedule(ctx context.Context, schedule *RoomSchedule) error
+	LoadRoomSchedule(ctx context.Context, roomName livekit.RoomName) (*RoomSchedule, error)
+	// ListRoomSchedules returns all schedules, or the ones of the given rooms when roomNames is not nil
+	ListRoomSchedules(ctx context.Context, roomNames []livekit.RoomName) ([]*RoomSchedule, error)
+	DeleteRoomSchedule(ctx context.Context, roomName livekit.RoomName) error
+}
+
+//counterfeiter:generate . RoomParticipantPolicyStore
+type RoomParticipantPolicyStore interface {
+	// StoreRoomParticipantPolicy keeps the participant policy of a room until the room is deleted
+	StoreRoomParticipantPolicy(ctx context.Context, policy *RoomParticipantPolicy) error
+	LoadRoomParticipantPolicy(ctx context.Context, roomName livekit.RoomName) (*RoomParticipantPolicy, error)
+}
+
+//counterfeiter:generate . RoomQualityReportStore
+type RoomQualityReportStore interface {
+	// StoreRoomQualityReport keeps the latest report of a room for the given retention
+	StoreRoomQualityReport(ctx context.Context, report *telemetry.QualityReport, retention time.Duration) error
+	LoadRoomQualityReport(ctx context.Context, roomName livekit.RoomName) (*telemetry.QualityReport, error)
+}
+
+//counterfeiter:generate . RoomEventStore
+type RoomEventStore interface {
+	// AppendRoomEvents adds events to the journal of a room, keeping at most maxEvents, the journal expires
+	// after retention without new events
+	AppendRoomEvents(ctx context.Context, roomName livekit.RoomName, events []*rtc.RoomEvent, retention time.Duration, maxEvents int) error
+	// ListRoomEvents returns up to limit events starting at offset, counted from the first event ever appended,
+	// along with the offset of the next page. Events dropped from the journal are skipped.
+	ListRoomEvents(ctx context.Context, roomName livekit.RoomName, offset int64, limit int) ([]*rtc.RoomEvent, int64, error)
+}
+
+//counterfeiter:generate . RoomSnapshotStore
+type RoomSnapshotStore interface {
+	// StoreRoomSnapshot replaces the snapshot of a room, it expires after retention unless replaced again
+	StoreRoomSnapshot(ctx context.Context, snapshot *rtc.RoomSnapshot, retention time.Duration) error
+	LoadRoomSnapshot(ctx context.Context, roomName livekit.RoomName) (*rtc.RoomSnapshot, error)
+	DeleteRoomSnapshot(ctx context.Context, roomName livekit.RoomName) error
+}
+
 //counterfeiter:generate . EgressStore
 type EgressStore interface {
 	StoreEgress(ctx context.Context, info *livekit.EgressInfo) error
@@ -77,6 +128,12 @@ type RoomAllocator interface {
 	SelectRoomNode(ctx context.Context, roomName livekit.RoomName, nodeID livekit.NodeID) error
 	CreateRoom(ctx context.Context, req *livekit.CreateRoomRequest, isExplicit bool) (*livekit.Room, *livekit.RoomInternal, bool, error)
 	ValidateCreateRoom(ctx context.Context, roomName livekit.RoomName) error
+	// AdmitParticipant checks a participant joining the room on node, reserve counts the participant against the node
+	// until its stats reflect it, it is false for a dry run
+	AdmitParticipant(ctx context.Context, roomName livekit.RoomName, node *livekit.Node, grants *auth.ClaimGrants, reserve bool) error
+	SelectParticipantNode(ctx context.Context, roomName livekit.RoomName, clientIP string) (livekit.NodeID, error)
+	// SortRegionsByDistance orders regions nearest to the client first, returns false when it cannot be located
+	SortRegionsByDistance(clientIP string, regions []config.RegionConfig) bool
 }
 
 //counterfeiter:generate . SIPStore
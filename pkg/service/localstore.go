@@ -16,11 +16,14 @@ package service
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/thoas/go-funk"
 
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/utils"
 )
@@ -36,18 +39,50 @@ type LocalStore struct {
 	agentDispatches map[livekit.RoomName]map[string]*livekit.AgentDispatch
 	agentJobs       map[livekit.RoomName]map[string]*livekit.Job
 
+	roomSchedules map[livekit.RoomName]*RoomSchedule
+
+	roomParticipantPolicies map[livekit.RoomName]*RoomParticipantPolicy
+
+	roomQualityReports map[livekit.RoomName]*localQualityReport
+
+	roomEvents map[livekit.RoomName]*localRoomEvents
+
+	roomSnapshots map[livekit.RoomName]*localRoomSnapshot
+
 	lock       sync.RWMutex
 	globalLock sync.Mutex
 }
 
+type localQualityReport struct {
+	report    *telemetry.QualityReport
+	expiresAt time.Time
+}
+
+type localRoomSnapshot struct {
+	snapshot  *rtc.RoomSnapshot
+	expiresAt time.Time
+}
+
+type localRoomEvents struct {
+	events []*rtc.RoomEvent
+	// number of events dropped from the front
+	dropped   int64
+	expiresAt time.Time
+}
+
 func NewLocalStore() *LocalStore {
 	return &LocalStore{
-		rooms:           make(map[livekit.RoomName]*livekit.Room),
-		roomInternal:    make(map[livekit.RoomName]*livekit.RoomInternal),
-		participants:    make(map[livekit.RoomName]map[livekit.ParticipantIdentity]*livekit.ParticipantInfo),
-		agentDispatches: make(map[livekit.RoomName]map[string]*livekit.AgentDispatch),
-		agentJobs:       make(map[livekit.RoomName]map[string]*livekit.Job),
-		lock:            sync.RWMutex{},
+		rooms:                   make(map[livekit.RoomName]*livekit.Room),
+		roomInternal:            make(map[livekit.RoomName]*livekit.RoomInternal),
+		participants:            make(map[livekit.RoomName]map[livekit.ParticipantIdentity]*livekit.ParticipantInfo),
+		agentDispatches:         make(map[livekit.RoomName]map[string]*livekit.AgentDispatch),
+		agentJobs:               make(map[livekit.RoomName]map[string]*livekit.Job),
+		roomSchedules:           make(map[livekit.RoomName]*RoomSchedule),
+		roomParticipantPolicies: make(map[livekit.RoomName]*RoomParticipantPolicy),
+		roomQualityReports:      make(map[livekit.RoomName]*localQualityReport),
+		roomEvents:              make(map[livekit.RoomName]*localRoomEvents),
+		roomSnapshots:           make(map[livekit.RoomName]*localRoomSnapshot),
+		lock:                    sync.RWMutex{},
 	}
 }
 
@@ -112,6 +147,8 @@ func (s *LocalStore) DeleteRoom(ctx context.Context, roomName livekit.RoomName)
 	delete(s.roomInternal, livekit.RoomName(room.Name))
 	delete(s.agentDispatches, livekit.RoomName(room.Name))
 	delete(s.agentJobs, livekit.RoomName(room.Name))
+	delete(s.roomSnapshots, livekit.RoomName(room.Name))
+	delete(s.roomParticipantPolicies, livekit.RoomName(room.Name))
 	return nil
 }
 
@@ -281,3 +318,223 @@ func (s *LocalStore) DeleteAgentJob(ctx context.Context, job *livekit.Job) error
 
 	return nil
 }
+
+func (s *LocalStore) StoreRoomSchedule(_ context.Context, schedule *RoomSchedule) error {
+	clone := *schedule
+
+	s.lock.Lock()
+	s.roomSchedules[livekit.RoomName(schedule.Room)] = &clone
+	s.lock.Unlock()
+
+	return nil
+}
+
+func (s *LocalStore) LoadRoomSchedule(_ context.Context, roomName livekit.RoomName) (*RoomSchedule, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	schedule := s.roomSchedules[roomName]
+	if schedule == nil {
+		return nil, ErrRoomScheduleNotFound
+	}
+	clone := *schedule
+	return &clone, nil
+}
+
+func (s *LocalStore) ListRoomSchedules(_ context.Context, roomNames []livekit.RoomName) ([]*RoomSchedule, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	schedules := make([]*RoomSchedule, 0)
+	for name, schedule := range s.roomSchedules {
+		if roomNames == nil || funk.Contains(roomNames, name) {
+			clone := *schedule
+			schedules = append(schedules, &clone)
+		}
+	}
+	return schedules, nil
+}
+
+func (s *LocalStore) DeleteRoomSchedule(_ context.Context, roomName livekit.RoomName) error {
+	s.lock.Lock()
+	delete(s.roomSchedules, roomName)
+	s.lock.Unlock()
+
+	return nil
+}
+
+func (s *LocalStore) StoreRoomParticipantPolicy(_ context.Context, policy *RoomParticipantPolicy) error {
+	clone := *policy
+
+	s.lock.Lock()
+	s.roomParticipantPolicies[livekit.RoomName(policy.Room)] = &clone
+	s.lock.Unlock()
+
+	return nil
+}
+
+func (s *LocalStore) LoadRoomParticipantPolicy(_ context.Context, roomName livekit.RoomName) (*RoomParticipantPolicy, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	policy := s.roomParticipantPolicies[roomName]
+	if policy == nil {
+		return nil, ErrRoomParticipantPolicyNotFound
+	}
+	clone := *policy
+	return &clone, nil
+}
+
+func (s *LocalStore) StoreRoomQualityReport(_ context.Context, report *telemetry.QualityReport, retention time.Duration) error {
+	s.storeRoomQualityReport(report, time.Now().Add(retention))
+	return nil
+}
+
+func (s *LocalStore) storeRoomQualityReport(report *telemetry.QualityReport, expiresAt time.Time) {
+	now := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// drop expired reports of other rooms
+	for name, r := range s.roomQualityReports {
+		if now.After(r.expiresAt) {
+			delete(s.roomQualityReports, name)
+		}
+	}
+	s.roomQualityReports[livekit.RoomName(report.RoomName)] = &localQualityReport{
+		report:    report,
+		expiresAt: expiresAt,
+	}
+}
+
+func (s *LocalStore) LoadRoomQualityReport(_ context.Context, roomName livekit.RoomName) (*telemetry.QualityReport, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	r := s.roomQualityReports[roomName]
+	if r == nil || time.Now().After(r.expiresAt) {
+		return nil, ErrRoomQualityReportNotFound
+	}
+	return r.report, nil
+}
+
+func (s *LocalStore) StoreRoomSnapshot(_ context.Context, snapshot *rtc.RoomSnapshot, retention time.Duration) error {
+	s.storeRoomSnapshot(snapshot, time.Now().Add(retention))
+	return nil
+}
+
+func (s *LocalStore) storeRoomSnapshot(snapshot *rtc.RoomSnapshot, expiresAt time.Time) {
+	now := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// drop expired snapshots of other rooms
+	for name, r := range s.roomSnapshots {
+		if now.After(r.expiresAt) {
+			delete(s.roomSnapshots, name)
+		}
+	}
+	s.roomSnapshots[livekit.RoomName(snapshot.Room.Name)] = &localRoomSnapshot{
+		snapshot:  snapshot,
+		expiresAt: expiresAt,
+	}
+}
+
+func (s *LocalStore) LoadRoomSnapshot(_ context.Context, roomName livekit.RoomName) (*rtc.RoomSnapshot, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	r := s.roomSnapshots[roomName]
+	if r == nil || time.Now().After(r.expiresAt) {
+		return nil, ErrRoomSnapshotNotFound
+	}
+	return r.snapshot, nil
+}
+
+func (s *LocalStore) DeleteRoomSnapshot(_ context.Context, roomName livekit.RoomName) error {
+	s.lock.Lock()
+	delete(s.roomSnapshots, roomName)
+	s.lock.Unlock()
+
+	return nil
+}
+
+func (s *LocalStore) AppendRoomEvents(_ context.Context, roomName livekit.RoomName, events []*rtc.RoomEvent, retention time.Duration, maxEvents int) error {
+	now := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// drop expired journals of other rooms
+	for name, e := range s.roomEvents {
+		if now.After(e.expiresAt) {
+			delete(s.roomEvents, name)
+		}
+	}
+
+	e := s.roomEvents[roomName]
+	if e == nil {
+		e = &localRoomEvents{}
+		s.roomEvents[roomName] = e
+	}
+	e.events = append(e.events, events...)
+	if maxEvents > 0 && len(e.events) > maxEvents {
+		drop := len(e.events) - maxEvents
+		e.events = append([]*rtc.RoomEvent(nil), e.events[drop:]...)
+		e.dropped += int64(drop)
+	}
+	e.expiresAt = now.Add(retention)
+	return nil
+}
+
+// loadRoomEvents returns the journal of a room, unless it expired
+func (s *LocalStore) loadRoomEvents(roomName livekit.RoomName) ([]*rtc.RoomEvent, int64) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	e := s.roomEvents[roomName]
+	if e == nil || time.Now().After(e.expiresAt) {
+		return nil, 0
+	}
+	return slices.Clone(e.events), e.dropped
+}
+
+// storeRoomEvents replaces the journal of a room
+func (s *LocalStore) storeRoomEvents(roomName livekit.RoomName, events []*rtc.RoomEvent, dropped int64, expiresAt time.Time) {
+	now := time.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// drop expired journals of other rooms
+	for name, e := range s.roomEvents {
+		if now.After(e.expiresAt) {
+			delete(s.roomEvents, name)
+		}
+	}
+	s.roomEvents[roomName] = &localRoomEvents{
+		events:    events,
+		dropped:   dropped,
+		expiresAt: expiresAt,
+	}
+}
+
+func (s *LocalStore) ListRoomEvents(_ context.Context, roomName livekit.RoomName, offset int64, limit int) ([]*rtc.RoomEvent, int64, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	e := s.roomEvents[roomName]
+	if e == nil || time.Now().After(e.expiresAt) {
+		return nil, offset, nil
+	}
+
+	count := e.dropped + int64(len(e.events))
+	start := max(offset, e.dropped)
+	if start >= count || limit <= 0 {
+		return nil, max(offset, count), nil
+	}
+	end := min(start+int64(limit), count)
+	return slices.Clone(e.events[start-e.dropped : end-e.dropped]), end, nil
+}
@@ -0,0 +1,320 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/sfu/capture"
+)
+
+const (
+	packetCapturePrefix = "PC_"
+	// downloads are served at /capture/<room>/<capture id>
+	packetCapturePath = "/capture/"
+	// captures are fetched from the hosting node in chunks, keeping psrpc messages small
+	packetCaptureChunkSize = 1 << 20
+)
+
+// StartPacketCaptureRequest starts capturing decrypted RTP and RTCP of a participant, in both directions.
+// Bounds are clamped to rtc.packet_capture limits, zero uses the limit.
+type StartPacketCaptureRequest struct {
+	Room            string `json:"room"`
+	Identity        string `json:"identity"`
+	DurationSeconds uint32 `json:"duration_seconds"`
+	MaxBytes        int    `json:"max_bytes"`
+	HeadersOnly     bool   `json:"headers_only"`
+}
+
+func (r *StartPacketCaptureRequest) GetRoom() string {
+	return r.Room
+}
+
+func (r *StartPacketCaptureRequest) GetIdentity() string {
+	return r.Identity
+}
+
+type StopPacketCaptureRequest struct {
+	Room      string `json:"room"`
+	CaptureID string `json:"capture_id"`
+}
+
+func (r *StopPacketCaptureRequest) GetRoom() string {
+	return r.Room
+}
+
+type PacketCaptureResponse struct {
+	Capture *capture.CaptureInfo `json:"capture"`
+}
+
+// GetPacketCaptureRequest reads up to Length bytes of a capture at Offset, Length is clamped to packetCaptureChunkSize
+type GetPacketCaptureRequest struct {
+	Room      string `json:"room"`
+	CaptureID string `json:"capture_id"`
+	Offset    int64  `json:"offset"`
+	Length    int    `json:"length"`
+}
+
+func (r *GetPacketCaptureRequest) GetRoom() string {
+	return r.Room
+}
+
+type GetPacketCaptureResponse struct {
+	Capture *capture.CaptureInfo `json:"capture"`
+	// chunk of the pcapng file at the requested offset
+	Data []byte `json:"data"`
+}
+
+// StartPacketCapture starts a capture on the node hosting the room. The capture is downloadable
+// from /capture/<room>/<capture id> while running and for rtc.packet_capture.retention after it stopped,
+// as long as the room is open.
+func (s *RoomService) StartPacketCapture(ctx context.Context, req *StartPacketCaptureRequest) (*PacketCaptureResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "durationSeconds", req.DurationSeconds, "maxBytes", req.MaxBytes, "headersOnly", req.HeadersOnly)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	return s.extensionClient.StartPacketCapture(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) StopPacketCapture(ctx context.Context, req *StopPacketCaptureRequest) (*PacketCaptureResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "captureID", req.CaptureID)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	return s.extensionClient.StopPacketCapture(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+// DownloadPacketCapture serves a capture as a pcapng file, authenticated with an admin token of the room.
+// The file is streamed in chunks read from the hosting node, ending at the size the capture had when the download started.
+func (s *RoomService) DownloadPacketCapture(w http.ResponseWriter, r *http.Request) {
+	name, captureID, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, packetCapturePath), "/")
+	if r.Method != http.MethodGet || !ok || name == "" || captureID == "" {
+		handleError(w, r, http.StatusNotFound, ErrPacketCaptureNotFound)
+		return
+	}
+
+	ctx := r.Context()
+	roomName := TenantRoomName(GetTenant(ctx), livekit.RoomName(name))
+	if err := EnsureAdminPermission(ctx, roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	topic := s.topicFormatter.RoomTopic(ctx, roomName)
+	req := &GetPacketCaptureRequest{
+		Room:      string(roomName),
+		CaptureID: captureID,
+		Length:    packetCaptureChunkSize,
+	}
+	res, err := s.extensionClient.GetPacketCapture(ctx, topic, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var perr psrpc.Error
+		if errors.As(err, &perr) {
+			status = perr.ToHttp()
+		}
+		handleError(w, r, status, err, "room", roomName, "captureID", captureID)
+		return
+	}
+
+	size := int64(res.Capture.Bytes)
+	w.Header().Set("Content-Type", "application/x-pcapng")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", captureID+".pcapng"))
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	for {
+		// a running capture keeps growing, the file ends at the size reported by the first chunk
+		data := res.Data[:min(int64(len(res.Data)), size-req.Offset)]
+		if _, err = w.Write(data); err != nil {
+			return
+		}
+		req.Offset += int64(len(data))
+		if req.Offset >= size || len(data) == 0 {
+			return
+		}
+
+		req.Length = int(min(packetCaptureChunkSize, size-req.Offset))
+		if res, err = s.extensionClient.GetPacketCapture(ctx, topic, req); err != nil {
+			// headers are already sent, the client sees a short read
+			logger.Warnw("failed to read packet capture", err, "room", roomName, "captureID", captureID, "offset", req.Offset)
+			return
+		}
+	}
+}
+
+// ---------------------------------------------------------------
+
+type packetCaptureEntry struct {
+	capture  *capture.Capture
+	roomName livekit.RoomName
+}
+
+// packetCaptures keeps the captures of rooms hosted on this node, until retention has passed after they stopped
+type packetCaptures struct {
+	conf config.PacketCaptureConfig
+
+	lock     sync.Mutex
+	captures map[string]*packetCaptureEntry
+}
+
+func newPacketCaptures(conf config.PacketCaptureConfig) *packetCaptures {
+	return &packetCaptures{
+		conf:     conf,
+		captures: make(map[string]*packetCaptureEntry),
+	}
+}
+
+func (p *packetCaptures) start(roomName livekit.RoomName, identity livekit.ParticipantIdentity, tap *capture.Tap, req *StartPacketCaptureRequest, logger logger.Logger) (*capture.Capture, error) {
+	if !p.conf.Enabled {
+		return nil, ErrPacketCaptureDisabled
+	}
+
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if duration <= 0 || (p.conf.MaxDuration > 0 && duration > p.conf.MaxDuration) {
+		duration = p.conf.MaxDuration
+	}
+	maxBytes := req.MaxBytes
+	if maxBytes <= 0 || (p.conf.MaxBytes > 0 && maxBytes > p.conf.MaxBytes) {
+		maxBytes = p.conf.MaxBytes
+	}
+
+	c := capture.NewCapture(capture.CaptureParams{
+		ID:                  guid.New(packetCapturePrefix),
+		ParticipantIdentity: string(identity),
+		Duration:            duration,
+		MaxBytes:            maxBytes,
+		HeadersOnly:         req.HeadersOnly,
+		Logger:              logger,
+	})
+
+	p.lock.Lock()
+	p.captures[c.ID()] = &packetCaptureEntry{
+		capture:  c,
+		roomName: roomName,
+	}
+	p.lock.Unlock()
+
+	c.OnStop(func(c *capture.Capture) {
+		time.AfterFunc(p.conf.Retention, func() {
+			p.lock.Lock()
+			delete(p.captures, c.ID())
+			p.lock.Unlock()
+		})
+	})
+	tap.Attach(c)
+	logger.Infow("packet capture started", "captureID", c.ID(), "duration", duration, "maxBytes", maxBytes, "headersOnly", req.HeadersOnly)
+	return c, nil
+}
+
+func (p *packetCaptures) get(roomName livekit.RoomName, captureID string) (*capture.Capture, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	entry := p.captures[captureID]
+	if entry == nil || entry.roomName != roomName {
+		return nil, ErrPacketCaptureNotFound
+	}
+	return entry.capture, nil
+}
+
+// closeRoom stops running captures of a room, requests are no longer routed to this node once the room is closed
+func (p *packetCaptures) closeRoom(roomName livekit.RoomName) {
+	p.lock.Lock()
+	var captures []*capture.Capture
+	for _, entry := range p.captures {
+		if entry.roomName == roomName {
+			captures = append(captures, entry.capture)
+		}
+	}
+	p.lock.Unlock()
+
+	for _, c := range captures {
+		c.Close()
+	}
+}
+
+func (p *packetCaptures) stopAll() {
+	p.lock.Lock()
+	var captures []*capture.Capture
+	for _, entry := range p.captures {
+		captures = append(captures, entry.capture)
+	}
+	p.lock.Unlock()
+
+	for _, c := range captures {
+		c.Close()
+	}
+}
+
+// ---------------------------------------------------------------
+
+func (r *RoomManager) StartPacketCapture(ctx context.Context, req *StartPacketCaptureRequest) (*PacketCaptureResponse, error) {
+	room, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := r.packetCaptures.start(room.Name(), participant.Identity(), participant.GetBufferFactory().CaptureTap(), req, participant.GetLogger())
+	if err != nil {
+		return nil, err
+	}
+	return &PacketCaptureResponse{Capture: c.Info()}, nil
+}
+
+func (r *RoomManager) StopPacketCapture(ctx context.Context, req *StopPacketCaptureRequest) (*PacketCaptureResponse, error) {
+	c, err := r.packetCaptures.get(livekit.RoomName(req.Room), req.CaptureID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Stop()
+	return &PacketCaptureResponse{Capture: c.Info()}, nil
+}
+
+func (r *RoomManager) GetPacketCapture(ctx context.Context, req *GetPacketCaptureRequest) (*GetPacketCaptureResponse, error) {
+	c, err := r.packetCaptures.get(livekit.RoomName(req.Room), req.CaptureID)
+	if err != nil {
+		return nil, err
+	}
+
+	// info is taken before reading so the reported size is always readable
+	info := c.Info()
+	data := make([]byte, min(max(req.Length, 0), packetCaptureChunkSize))
+	n, err := c.ReadAt(data, req.Offset)
+	if err != nil && err != io.EOF {
+		return nil, psrpc.NewError(psrpc.InvalidArgument, err)
+	}
+	return &GetPacketCaptureResponse{
+		Capture: info,
+		Data:    data[:n],
+	}, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/sfu/capture"
+)
+
+// packetCaptureExtension serves captures like the hosting node, recording requested chunks
+type packetCaptureExtension struct {
+	RoomExtensionServerImpl
+	roomManager *RoomManager
+	requests    []*GetPacketCaptureRequest
+}
+
+func (e *packetCaptureExtension) GetPacketCapture(ctx context.Context, req *GetPacketCaptureRequest) (*GetPacketCaptureResponse, error) {
+	e.requests = append(e.requests, req)
+	return e.roomManager.GetPacketCapture(ctx, req)
+}
+
+func TestDownloadPacketCapture(t *testing.T) {
+	captures := newPacketCaptures(config.PacketCaptureConfig{Enabled: true, Retention: time.Minute})
+	c, err := captures.start("acme/room", "p1", capture.NewTap(), &StartPacketCaptureRequest{}, logger.GetLogger())
+	require.NoError(t, err)
+	defer c.Close()
+
+	// large enough to need two chunks
+	pkt := make([]byte, 1200)
+	pkt[0] = 0x80
+	for c.Info().Bytes <= packetCaptureChunkSize {
+		c.WriteRTP(capture.DirectionIngress, pkt)
+	}
+	c.Stop()
+
+	bus := psrpc.NewLocalMessageBus()
+	ext := &packetCaptureExtension{roomManager: &RoomManager{packetCaptures: captures}}
+	server := NewRoomExtensionServer(ext, bus)
+	topicFormatter := rpc.NewTopicFormatter()
+	require.NoError(t, server.RegisterAllRoomTopics(topicFormatter.RoomTopic(context.Background(), "acme/room")))
+	client, err := NewRoomExtensionClient(rpc.ClientParams{Bus: bus})
+	require.NoError(t, err)
+	svc := &RoomService{topicFormatter: topicFormatter, extensionClient: client}
+
+	download := func(tenant, room string) *httptest.ResponseRecorder {
+		ctx := WithGrants(context.Background(), &auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomAdmin: true, Room: "acme/room"},
+		}, "")
+		if tenant != "" {
+			ctx = context.WithValue(ctx, tenantKey{}, tenant)
+		}
+		req := httptest.NewRequest(http.MethodGet, packetCapturePath+room+"/"+c.ID(), nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		svc.DownloadPacketCapture(w, req)
+		return w
+	}
+
+	t.Run("room is namespaced to the tenant", func(t *testing.T) {
+		ext.requests = nil
+		w := download("acme", "room")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.True(t, bytes.Equal(c.Bytes(), w.Body.Bytes()))
+		require.Equal(t, "application/x-pcapng", w.Header().Get("Content-Type"))
+
+		require.Len(t, ext.requests, 2)
+		require.Equal(t, "acme/room", ext.requests[0].Room)
+		require.Equal(t, int64(0), ext.requests[0].Offset)
+		require.Equal(t, int64(packetCaptureChunkSize), ext.requests[1].Offset)
+		require.Equal(t, c.Info().Bytes-packetCaptureChunkSize, ext.requests[1].Length)
+	})
+
+	t.Run("other tenants cannot download", func(t *testing.T) {
+		ext.requests = nil
+		w := download("globex", "room")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Empty(t, ext.requests)
+	})
+}
@@ -16,7 +16,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"slices"
 	"strconv"
 	"strings"
@@ -34,6 +36,8 @@ import (
 	"github.com/livekit/protocol/utils/guid"
 	"github.com/livekit/psrpc"
 
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/version"
 )
 
@@ -44,12 +48,21 @@ const (
 	RoomsKey        = "rooms"
 	RoomInternalKey = "room_internal"
 
-	// EgressKey is a hash of egressID => egress info
+	// with sharded keys, every room is stored in its own keys, hash tagged with the room name so keys of
+	// a room share a Redis Cluster slot. RoomPrefix is a key containing the Room proto
+	RoomPrefix         = "room:"
+	RoomInternalPrefix = "room_internal:"
+	// RoomIndexPrefix is a set of room names, rooms are spread over a fixed number of sets
+	RoomIndexPrefix = "rooms_index:"
+
+	// EgressKey is a hash of egressID => egress info. egresses and ingresses stay in global hashes with the
+	// sharded layout, they are looked up by ID rather than by room and their number does not grow with rooms
 	EgressKey        = "egress"
 	EndedEgressKey   = "ended_egress"
 	RoomEgressPrefix = "egress:room:"
 
-	// IngressKey is a hash of ingressID => ingress info
+	// IngressKey is a hash of ingressID => ingress info. ingress keys, including the set of ingresses of a
+	// room, share the {ingress} hash tag so ingress updates stay transactional on Redis Cluster
 	IngressKey         = "ingress"
 	StreamKeyKey       = "{ingress}_stream_key"
 	IngressStatePrefix = "{ingress}_state:"
@@ -61,11 +74,31 @@ const (
 	// RoomLockPrefix is a simple key containing a provided lock uid
 	RoomLockPrefix = "room_lock:"
 
+	// RoomSchedulesKey is hash of room_name => RoomSchedule json
+	RoomSchedulesKey = "room_schedules"
+
+	// RoomParticipantPoliciesKey is hash of room_name => RoomParticipantPolicy json
+	RoomParticipantPoliciesKey = "room_participant_policies"
+
+	// RoomQualityReportPrefix is a key containing the QualityReport json of the last session of a room
+	RoomQualityReportPrefix = "room_quality_report:"
+
+	// RoomSnapshotPrefix is a hash of the protos making up the snapshot of a room
+	RoomSnapshotPrefix = "room_snapshot:"
+
+	// RoomEventsPrefix is a list of RoomEvent json of a room, the oldest first
+	RoomEventsPrefix = "room_events:"
+	// RoomEventsCountPrefix is the number of events ever appended to the list of a room
+	RoomEventsCountPrefix = "room_events_count:"
+
 	// Agents
 	AgentDispatchPrefix = "agent_dispatch:"
 	AgentJobPrefix      = "agent_job:"
 
 	maxRetries = 5
+
+	// number of keys read in a single pipeline or scan
+	listBatchSize = 100
 )
 
 type RedisStore struct {
@@ -73,6 +106,10 @@ type RedisStore struct {
 	unlockScript *redis.Script
 	ctx          context.Context
 	done         chan struct{}
+
+	// sharded key layout, see NewShardedRedisStore
+	sharded     bool
+	indexShards int
 }
 
 func NewRedisStore(rc redis.UniversalClient) *RedisStore {
@@ -88,6 +125,34 @@ func NewRedisStore(rc redis.UniversalClient) *RedisStore {
 	}
 }
 
+// NewShardedRedisStore stores each room in keys of its own instead of global hashes, so rooms are
+// distributed across the slots of a Redis Cluster. Room names are indexed in indexShards sets.
+// Keys written by a store that is not sharded are rewritten with MigrateToShardedKeys.
+func NewShardedRedisStore(rc redis.UniversalClient, indexShards int) *RedisStore {
+	s := NewRedisStore(rc)
+	s.sharded = true
+	s.indexShards = max(indexShards, 1)
+	return s
+}
+
+// roomKey returns the key of a room scoped prefix, hash tagged with the room name when sharded
+func (s *RedisStore) roomKey(prefix string, roomName livekit.RoomName) string {
+	if s.sharded {
+		return prefix + "{" + string(roomName) + "}"
+	}
+	return prefix + string(roomName)
+}
+
+func (s *RedisStore) roomIndexKey(roomName livekit.RoomName) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(roomName))
+	return s.roomIndexShardKey(int(h.Sum32() % uint32(s.indexShards)))
+}
+
+func (s *RedisStore) roomIndexShardKey(shard int) string {
+	return RoomIndexPrefix + "{" + strconv.Itoa(shard) + "}"
+}
+
 func (s *RedisStore) Start() error {
 	if s.done != nil {
 		return nil
@@ -110,6 +175,12 @@ func (s *RedisStore) Start() error {
 		}
 	}
 
+	if s.sharded {
+		if n, err := s.rc.HLen(s.ctx, RoomsKey).Result(); err == nil && n > 0 {
+			logger.Warnw("rooms stored with the previous key layout are not visible, migrate them with migrate-redis-keys", nil, "rooms", n)
+		}
+	}
+
 	go s.egressWorker()
 	return nil
 }
@@ -134,8 +205,14 @@ func (s *RedisStore) StoreRoom(_ context.Context, room *livekit.Room, internal *
 		return err
 	}
 
+	roomName := livekit.RoomName(room.Name)
 	pp := s.rc.Pipeline()
-	pp.HSet(s.ctx, RoomsKey, room.Name, roomData)
+	if s.sharded {
+		pp.Set(s.ctx, s.roomKey(RoomPrefix, roomName), roomData, 0)
+		pp.SAdd(s.ctx, s.roomIndexKey(roomName), room.Name)
+	} else {
+		pp.HSet(s.ctx, RoomsKey, room.Name, roomData)
+	}
 
 	var internalData []byte
 	if internal != nil {
@@ -143,7 +220,13 @@ func (s *RedisStore) StoreRoom(_ context.Context, room *livekit.Room, internal *
 		if err != nil {
 			return err
 		}
-		pp.HSet(s.ctx, RoomInternalKey, room.Name, internalData)
+		if s.sharded {
+			pp.Set(s.ctx, s.roomKey(RoomInternalPrefix, roomName), internalData, 0)
+		} else {
+			pp.HSet(s.ctx, RoomInternalKey, room.Name, internalData)
+		}
+	} else if s.sharded {
+		pp.Del(s.ctx, s.roomKey(RoomInternalPrefix, roomName))
 	} else {
 		pp.HDel(s.ctx, RoomInternalKey, room.Name)
 	}
@@ -156,9 +239,16 @@ func (s *RedisStore) StoreRoom(_ context.Context, room *livekit.Room, internal *
 
 func (s *RedisStore) LoadRoom(_ context.Context, roomName livekit.RoomName, includeInternal bool) (*livekit.Room, *livekit.RoomInternal, error) {
 	pp := s.rc.Pipeline()
-	pp.HGet(s.ctx, RoomsKey, string(roomName))
-	if includeInternal {
-		pp.HGet(s.ctx, RoomInternalKey, string(roomName))
+	if s.sharded {
+		pp.Get(s.ctx, s.roomKey(RoomPrefix, roomName))
+		if includeInternal {
+			pp.Get(s.ctx, s.roomKey(RoomInternalPrefix, roomName))
+		}
+	} else {
+		pp.HGet(s.ctx, RoomsKey, string(roomName))
+		if includeInternal {
+			pp.HGet(s.ctx, RoomInternalKey, string(roomName))
+		}
 	}
 
 	res, err := pp.Exec(s.ctx)
@@ -196,6 +286,10 @@ func (s *RedisStore) LoadRoom(_ context.Context, roomName livekit.RoomName, incl
 }
 
 func (s *RedisStore) ListRooms(_ context.Context, roomNames []livekit.RoomName) ([]*livekit.Room, error) {
+	if s.sharded {
+		return s.listShardedRooms(roomNames)
+	}
+
 	var items []string
 	var err error
 	if roomNames == nil {
@@ -230,6 +324,52 @@ func (s *RedisStore) ListRooms(_ context.Context, roomNames []livekit.RoomName)
 	return rooms, nil
 }
 
+func (s *RedisStore) listShardedRooms(roomNames []livekit.RoomName) ([]*livekit.Room, error) {
+	if roomNames == nil {
+		pp := s.rc.Pipeline()
+		cmds := make([]*redis.StringSliceCmd, 0, s.indexShards)
+		for shard := 0; shard < s.indexShards; shard++ {
+			cmds = append(cmds, pp.SMembers(s.ctx, s.roomIndexShardKey(shard)))
+		}
+		if _, err := pp.Exec(s.ctx); err != nil && err != redis.Nil {
+			return nil, errors.Wrap(err, "could not get rooms")
+		}
+		for _, cmd := range cmds {
+			for _, name := range cmd.Val() {
+				roomNames = append(roomNames, livekit.RoomName(name))
+			}
+		}
+	}
+
+	rooms := make([]*livekit.Room, 0, len(roomNames))
+	for batch := range slices.Chunk(roomNames, listBatchSize) {
+		pp := s.rc.Pipeline()
+		cmds := make([]*redis.StringCmd, 0, len(batch))
+		for _, roomName := range batch {
+			cmds = append(cmds, pp.Get(s.ctx, s.roomKey(RoomPrefix, roomName)))
+		}
+		if _, err := pp.Exec(s.ctx); err != nil && err != redis.Nil {
+			return nil, errors.Wrap(err, "could not get rooms by names")
+		}
+
+		for _, cmd := range cmds {
+			data, err := cmd.Bytes()
+			if err == redis.Nil {
+				// deleted since it was listed
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			room := &livekit.Room{}
+			if err = proto.Unmarshal(data, room); err != nil {
+				return nil, err
+			}
+			rooms = append(rooms, room)
+		}
+	}
+	return rooms, nil
+}
+
 func (s *RedisStore) DeleteRoom(ctx context.Context, roomName livekit.RoomName) error {
 	_, _, err := s.LoadRoom(ctx, roomName, false)
 	if err == ErrRoomNotFound {
@@ -237,11 +377,19 @@ func (s *RedisStore) DeleteRoom(ctx context.Context, roomName livekit.RoomName)
 	}
 
 	pp := s.rc.Pipeline()
-	pp.HDel(s.ctx, RoomsKey, string(roomName))
-	pp.HDel(s.ctx, RoomInternalKey, string(roomName))
-	pp.Del(s.ctx, RoomParticipantsPrefix+string(roomName))
-	pp.Del(s.ctx, AgentDispatchPrefix+string(roomName))
-	pp.Del(s.ctx, AgentJobPrefix+string(roomName))
+	if s.sharded {
+		pp.Del(s.ctx, s.roomKey(RoomPrefix, roomName))
+		pp.Del(s.ctx, s.roomKey(RoomInternalPrefix, roomName))
+		pp.SRem(s.ctx, s.roomIndexKey(roomName), string(roomName))
+	} else {
+		pp.HDel(s.ctx, RoomsKey, string(roomName))
+		pp.HDel(s.ctx, RoomInternalKey, string(roomName))
+	}
+	pp.Del(s.ctx, s.roomKey(RoomParticipantsPrefix, roomName))
+	pp.Del(s.ctx, s.roomKey(AgentDispatchPrefix, roomName))
+	pp.Del(s.ctx, s.roomKey(AgentJobPrefix, roomName))
+	pp.Del(s.ctx, s.roomKey(RoomSnapshotPrefix, roomName))
+	pp.HDel(s.ctx, RoomParticipantPoliciesKey, string(roomName))
 
 	_, err = pp.Exec(s.ctx)
 	return err
@@ -249,7 +397,7 @@ func (s *RedisStore) DeleteRoom(ctx context.Context, roomName livekit.RoomName)
 
 func (s *RedisStore) LockRoom(_ context.Context, roomName livekit.RoomName, duration time.Duration) (string, error) {
 	token := guid.New("LOCK")
-	key := RoomLockPrefix + string(roomName)
+	key := s.roomKey(RoomLockPrefix, roomName)
 
 	startTime := time.Now()
 	for {
@@ -273,7 +421,7 @@ func (s *RedisStore) LockRoom(_ context.Context, roomName livekit.RoomName, dura
 }
 
 func (s *RedisStore) UnlockRoom(_ context.Context, roomName livekit.RoomName, uid string) error {
-	key := RoomLockPrefix + string(roomName)
+	key := s.roomKey(RoomLockPrefix, roomName)
 	res, err := s.unlockScript.Run(s.ctx, s.rc, []string{key}, uid).Result()
 	if err != nil {
 		return err
@@ -288,7 +436,7 @@ func (s *RedisStore) UnlockRoom(_ context.Context, roomName livekit.RoomName, ui
 }
 
 func (s *RedisStore) StoreParticipant(_ context.Context, roomName livekit.RoomName, participant *livekit.ParticipantInfo) error {
-	key := RoomParticipantsPrefix + string(roomName)
+	key := s.roomKey(RoomParticipantsPrefix, roomName)
 
 	data, err := proto.Marshal(participant)
 	if err != nil {
@@ -299,7 +447,7 @@ func (s *RedisStore) StoreParticipant(_ context.Context, roomName livekit.RoomNa
 }
 
 func (s *RedisStore) LoadParticipant(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) (*livekit.ParticipantInfo, error) {
-	key := RoomParticipantsPrefix + string(roomName)
+	key := s.roomKey(RoomParticipantsPrefix, roomName)
 	data, err := s.rc.HGet(s.ctx, key, string(identity)).Result()
 	if err == redis.Nil {
 		return nil, ErrParticipantNotFound
@@ -315,7 +463,7 @@ func (s *RedisStore) LoadParticipant(_ context.Context, roomName livekit.RoomNam
 }
 
 func (s *RedisStore) ListParticipants(_ context.Context, roomName livekit.RoomName) ([]*livekit.ParticipantInfo, error) {
-	key := RoomParticipantsPrefix + string(roomName)
+	key := s.roomKey(RoomParticipantsPrefix, roomName)
 	items, err := s.rc.HVals(s.ctx, key).Result()
 	if err == redis.Nil {
 		return nil, nil
@@ -335,11 +483,271 @@ func (s *RedisStore) ListParticipants(_ context.Context, roomName livekit.RoomNa
 }
 
 func (s *RedisStore) DeleteParticipant(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) error {
-	key := RoomParticipantsPrefix + string(roomName)
+	key := s.roomKey(RoomParticipantsPrefix, roomName)
 
 	return s.rc.HDel(s.ctx, key, string(identity)).Err()
 }
 
+func (s *RedisStore) StoreRoomSchedule(_ context.Context, schedule *RoomSchedule) error {
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+
+	return s.rc.HSet(s.ctx, RoomSchedulesKey, schedule.Room, data).Err()
+}
+
+func (s *RedisStore) LoadRoomSchedule(_ context.Context, roomName livekit.RoomName) (*RoomSchedule, error) {
+	data, err := s.rc.HGet(s.ctx, RoomSchedulesKey, string(roomName)).Result()
+	if err == redis.Nil {
+		return nil, ErrRoomScheduleNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	schedule := &RoomSchedule{}
+	if err = json.Unmarshal([]byte(data), schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *RedisStore) ListRoomSchedules(_ context.Context, roomNames []livekit.RoomName) ([]*RoomSchedule, error) {
+	var items []string
+	if roomNames == nil {
+		var err error
+		items, err = s.rc.HVals(s.ctx, RoomSchedulesKey).Result()
+		if err != nil && err != redis.Nil {
+			return nil, errors.Wrap(err, "could not get room schedules")
+		}
+	} else {
+		results, err := s.rc.HMGet(s.ctx, RoomSchedulesKey, livekit.IDsAsStrings(roomNames)...).Result()
+		if err != nil && err != redis.Nil {
+			return nil, errors.Wrap(err, "could not get room schedules by names")
+		}
+		for _, r := range results {
+			if item, ok := r.(string); ok {
+				items = append(items, item)
+			}
+		}
+	}
+
+	schedules := make([]*RoomSchedule, 0, len(items))
+	for _, item := range items {
+		schedule := &RoomSchedule{}
+		if err := json.Unmarshal([]byte(item), schedule); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+func (s *RedisStore) DeleteRoomSchedule(_ context.Context, roomName livekit.RoomName) error {
+	return s.rc.HDel(s.ctx, RoomSchedulesKey, string(roomName)).Err()
+}
+
+func (s *RedisStore) StoreRoomParticipantPolicy(_ context.Context, policy *RoomParticipantPolicy) error {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	return s.rc.HSet(s.ctx, RoomParticipantPoliciesKey, policy.Room, data).Err()
+}
+
+func (s *RedisStore) LoadRoomParticipantPolicy(_ context.Context, roomName livekit.RoomName) (*RoomParticipantPolicy, error) {
+	data, err := s.rc.HGet(s.ctx, RoomParticipantPoliciesKey, string(roomName)).Result()
+	if err == redis.Nil {
+		return nil, ErrRoomParticipantPolicyNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	policy := &RoomParticipantPolicy{}
+	if err = json.Unmarshal([]byte(data), policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *RedisStore) StoreRoomQualityReport(_ context.Context, report *telemetry.QualityReport, retention time.Duration) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	return s.rc.Set(s.ctx, s.roomKey(RoomQualityReportPrefix, livekit.RoomName(report.RoomName)), data, retention).Err()
+}
+
+func (s *RedisStore) LoadRoomQualityReport(_ context.Context, roomName livekit.RoomName) (*telemetry.QualityReport, error) {
+	data, err := s.rc.Get(s.ctx, s.roomKey(RoomQualityReportPrefix, roomName)).Result()
+	if err == redis.Nil {
+		return nil, ErrRoomQualityReportNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	report := &telemetry.QualityReport{}
+	if err = json.Unmarshal([]byte(data), report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+const (
+	snapshotRoomField             = "room"
+	snapshotInternalField         = "internal"
+	snapshotCreatedAtField        = "created_at"
+	snapshotDispatchFieldPrefix   = "dispatch:"
+	snapshotPermissionFieldPrefix = "permission:"
+)
+
+func (s *RedisStore) StoreRoomSnapshot(_ context.Context, snapshot *rtc.RoomSnapshot, retention time.Duration) error {
+	values := map[string]interface{}{
+		snapshotCreatedAtField: snapshot.CreatedAt.UnixMilli(),
+	}
+	fields := map[string]proto.Message{
+		snapshotRoomField: snapshot.Room,
+	}
+	if snapshot.Internal != nil {
+		fields[snapshotInternalField] = snapshot.Internal
+	}
+	for _, dispatch := range snapshot.AgentDispatches {
+		fields[snapshotDispatchFieldPrefix+dispatch.Id] = dispatch
+	}
+	for identity, permission := range snapshot.SubscriptionPermissions {
+		fields[snapshotPermissionFieldPrefix+string(identity)] = permission
+	}
+	for field, msg := range fields {
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		values[field] = data
+	}
+
+	// replaced as a whole, dispatches and permissions removed since the last snapshot must not remain
+	key := s.roomKey(RoomSnapshotPrefix, livekit.RoomName(snapshot.Room.Name))
+	tx := s.rc.TxPipeline()
+	tx.Del(s.ctx, key)
+	tx.HSet(s.ctx, key, values)
+	tx.Expire(s.ctx, key, retention)
+	_, err := tx.Exec(s.ctx)
+	return err
+}
+
+func (s *RedisStore) LoadRoomSnapshot(_ context.Context, roomName livekit.RoomName) (*rtc.RoomSnapshot, error) {
+	values, err := s.rc.HGetAll(s.ctx, s.roomKey(RoomSnapshotPrefix, roomName)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	if values[snapshotRoomField] == "" {
+		return nil, ErrRoomSnapshotNotFound
+	}
+
+	snapshot := &rtc.RoomSnapshot{
+		Room:                    &livekit.Room{},
+		SubscriptionPermissions: make(map[livekit.ParticipantIdentity]*livekit.SubscriptionPermission),
+	}
+	for field, value := range values {
+		switch {
+		case field == snapshotRoomField:
+			err = proto.Unmarshal([]byte(value), snapshot.Room)
+		case field == snapshotInternalField:
+			snapshot.Internal = &livekit.RoomInternal{}
+			err = proto.Unmarshal([]byte(value), snapshot.Internal)
+		case field == snapshotCreatedAtField:
+			var createdAt int64
+			createdAt, err = strconv.ParseInt(value, 10, 64)
+			snapshot.CreatedAt = time.UnixMilli(createdAt)
+		case strings.HasPrefix(field, snapshotDispatchFieldPrefix):
+			dispatch := &livekit.AgentDispatch{}
+			err = proto.Unmarshal([]byte(value), dispatch)
+			snapshot.AgentDispatches = append(snapshot.AgentDispatches, dispatch)
+		case strings.HasPrefix(field, snapshotPermissionFieldPrefix):
+			permission := &livekit.SubscriptionPermission{}
+			err = proto.Unmarshal([]byte(value), permission)
+			identity := livekit.ParticipantIdentity(strings.TrimPrefix(field, snapshotPermissionFieldPrefix))
+			snapshot.SubscriptionPermissions[identity] = permission
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
+
+func (s *RedisStore) DeleteRoomSnapshot(_ context.Context, roomName livekit.RoomName) error {
+	return s.rc.Del(s.ctx, s.roomKey(RoomSnapshotPrefix, roomName)).Err()
+}
+
+func (s *RedisStore) AppendRoomEvents(_ context.Context, roomName livekit.RoomName, events []*rtc.RoomEvent, retention time.Duration, maxEvents int) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		values = append(values, data)
+	}
+
+	key := s.roomKey(RoomEventsPrefix, roomName)
+	countKey := s.roomKey(RoomEventsCountPrefix, roomName)
+	tx := s.rc.TxPipeline()
+	tx.RPush(s.ctx, key, values...)
+	if maxEvents > 0 {
+		tx.LTrim(s.ctx, key, int64(-maxEvents), -1)
+	}
+	tx.IncrBy(s.ctx, countKey, int64(len(events)))
+	tx.Expire(s.ctx, key, retention)
+	tx.Expire(s.ctx, countKey, retention)
+	_, err := tx.Exec(s.ctx)
+	return err
+}
+
+func (s *RedisStore) ListRoomEvents(_ context.Context, roomName livekit.RoomName, offset int64, limit int) ([]*rtc.RoomEvent, int64, error) {
+	key := s.roomKey(RoomEventsPrefix, roomName)
+	tx := s.rc.TxPipeline()
+	countCmd := tx.Get(s.ctx, s.roomKey(RoomEventsCountPrefix, roomName))
+	lenCmd := tx.LLen(s.ctx, key)
+	if _, err := tx.Exec(s.ctx); err != nil && err != redis.Nil {
+		return nil, offset, err
+	}
+
+	count, err := countCmd.Int64()
+	if err == redis.Nil {
+		return nil, offset, nil
+	} else if err != nil {
+		return nil, offset, err
+	}
+
+	// index in the list of the event at offset, older events may have been trimmed
+	first := count - lenCmd.Val()
+	start := max(offset, first)
+	if start >= count || limit <= 0 {
+		return nil, max(offset, count), nil
+	}
+	values, err := s.rc.LRange(s.ctx, key, start-first, start-first+int64(limit)-1).Result()
+	if err != nil {
+		return nil, offset, err
+	}
+
+	events := make([]*rtc.RoomEvent, 0, len(values))
+	for _, v := range values {
+		event := &rtc.RoomEvent{}
+		if err = json.Unmarshal([]byte(v), event); err != nil {
+			return nil, offset, err
+		}
+		events = append(events, event)
+	}
+	return events, start + int64(len(values)), nil
+}
+
 func (s *RedisStore) StoreEgress(_ context.Context, info *livekit.EgressInfo) error {
 	data, err := proto.Marshal(info)
 	if err != nil {
@@ -348,7 +756,7 @@ func (s *RedisStore) StoreEgress(_ context.Context, info *livekit.EgressInfo) er
 
 	pp := s.rc.Pipeline()
 	pp.HSet(s.ctx, EgressKey, info.EgressId, data)
-	pp.SAdd(s.ctx, RoomEgressPrefix+info.RoomName, info.EgressId)
+	pp.SAdd(s.ctx, s.roomKey(RoomEgressPrefix, livekit.RoomName(info.RoomName)), info.EgressId)
 	if _, err = pp.Exec(s.ctx); err != nil {
 		return errors.Wrap(err, "could not store egress info")
 	}
@@ -400,7 +808,7 @@ func (s *RedisStore) ListEgress(_ context.Context, roomName livekit.RoomName, ac
 			}
 		}
 	} else {
-		egressIDs, err := s.rc.SMembers(s.ctx, RoomEgressPrefix+string(roomName)).Result()
+		egressIDs, err := s.rc.SMembers(s.ctx, s.roomKey(RoomEgressPrefix, roomName)).Result()
 		if err != nil {
 			if err == redis.Nil {
 				return nil, nil
@@ -484,7 +892,7 @@ func (s *RedisStore) CleanEndedEgress() error {
 
 		if endedAt < expiry {
 			pp := s.rc.Pipeline()
-			pp.SRem(s.ctx, RoomEgressPrefix+roomName, egressID)
+			pp.SRem(s.ctx, s.roomKey(RoomEgressPrefix, livekit.RoomName(roomName)), egressID)
 			pp.HDel(s.ctx, EgressKey, egressID)
 			// Delete the EndedEgressKey entry last so that future sweeper runs get another chance to delete dangling data is the deletion partially failed.
 			pp.HDel(s.ctx, EndedEgressKey, egressID)
@@ -841,7 +1249,7 @@ func (s *RedisStore) StoreAgentDispatch(_ context.Context, dispatch *livekit.Age
 		di.State.Jobs = nil
 	}
 
-	key := AgentDispatchPrefix + string(dispatch.Room)
+	key := s.roomKey(AgentDispatchPrefix, livekit.RoomName(dispatch.Room))
 
 	data, err := proto.Marshal(di)
 	if err != nil {
@@ -853,12 +1261,12 @@ func (s *RedisStore) StoreAgentDispatch(_ context.Context, dispatch *livekit.Age
 
 // This will not delete the jobs created by the dispatch
 func (s *RedisStore) DeleteAgentDispatch(_ context.Context, dispatch *livekit.AgentDispatch) error {
-	key := AgentDispatchPrefix + string(dispatch.Room)
+	key := s.roomKey(AgentDispatchPrefix, livekit.RoomName(dispatch.Room))
 	return s.rc.HDel(s.ctx, key, dispatch.Id).Err()
 }
 
 func (s *RedisStore) ListAgentDispatches(_ context.Context, roomName livekit.RoomName) ([]*livekit.AgentDispatch, error) {
-	key := AgentDispatchPrefix + string(roomName)
+	key := s.roomKey(AgentDispatchPrefix, roomName)
 	dispatches, err := redisLoadAll[livekit.AgentDispatch](s.ctx, s, key)
 	if err != nil {
 		return nil, err
@@ -869,7 +1277,7 @@ func (s *RedisStore) ListAgentDispatches(_ context.Context, roomName livekit.Roo
 		dMap[di.Id] = di
 	}
 
-	key = AgentJobPrefix + string(roomName)
+	key = s.roomKey(AgentJobPrefix, roomName)
 	jobs, err := redisLoadAll[livekit.Job](s.ctx, s, key)
 	if err != nil {
 		return nil, err
@@ -895,7 +1303,7 @@ func (s *RedisStore) StoreAgentJob(_ context.Context, job *livekit.Job) error {
 		return psrpc.NewErrorf(psrpc.InvalidArgument, "job doesn't have a valid Room field")
 	}
 
-	key := AgentJobPrefix + string(job.Room.Name)
+	key := s.roomKey(AgentJobPrefix, livekit.RoomName(job.Room.Name))
 
 	jb := utils.CloneProto(job)
 
@@ -922,10 +1330,127 @@ func (s *RedisStore) DeleteAgentJob(_ context.Context, job *livekit.Job) error {
 		return psrpc.NewErrorf(psrpc.InvalidArgument, "job doesn't have a valid Room field")
 	}
 
-	key := AgentJobPrefix + string(job.Room.Name)
+	key := s.roomKey(AgentJobPrefix, livekit.RoomName(job.Room.Name))
 	return s.rc.HDel(s.ctx, key, job.Id).Err()
 }
 
+// MigrateToShardedKeys rewrites rooms stored in the global hashes by a store that is not sharded into
+// the sharded key layout, and returns the number of rooms migrated. Migrated rooms are removed from the
+// global hashes, so it can be run again when interrupted.
+func (s *RedisStore) MigrateToShardedKeys(ctx context.Context) (int, error) {
+	if !s.sharded {
+		return 0, errors.New("store is not sharded")
+	}
+	legacy := NewRedisStore(s.rc)
+
+	names, err := s.rc.HKeys(ctx, RoomsKey).Result()
+	if err != nil && err != redis.Nil {
+		return 0, errors.Wrap(err, "could not get rooms")
+	}
+
+	migrated := 0
+	roomPrefixes := []string{RoomParticipantsPrefix, AgentDispatchPrefix, AgentJobPrefix}
+	for batch := range slices.Chunk(names, listBatchSize) {
+		pp := s.rc.Pipeline()
+		roomsCmd := pp.HMGet(ctx, RoomsKey, batch...)
+		internalsCmd := pp.HMGet(ctx, RoomInternalKey, batch...)
+		hashCmds := make([][]*redis.MapStringStringCmd, len(batch))
+		for i, name := range batch {
+			for _, prefix := range roomPrefixes {
+				hashCmds[i] = append(hashCmds[i], pp.HGetAll(ctx, legacy.roomKey(prefix, livekit.RoomName(name))))
+			}
+		}
+		if _, err = pp.Exec(ctx); err != nil && err != redis.Nil {
+			return migrated, errors.Wrap(err, "could not load rooms")
+		}
+
+		n := 0
+		pp = s.rc.Pipeline()
+		for i, name := range batch {
+			roomName := livekit.RoomName(name)
+			roomData, ok := roomsCmd.Val()[i].(string)
+			if !ok {
+				// deleted since it was listed
+				continue
+			}
+			pp.Set(ctx, s.roomKey(RoomPrefix, roomName), roomData, 0)
+			pp.SAdd(ctx, s.roomIndexKey(roomName), name)
+			if internalData, ok := internalsCmd.Val()[i].(string); ok {
+				pp.Set(ctx, s.roomKey(RoomInternalPrefix, roomName), internalData, 0)
+			}
+			for j, prefix := range roomPrefixes {
+				if values := hashCmds[i][j].Val(); len(values) != 0 {
+					pp.HSet(ctx, s.roomKey(prefix, roomName), values)
+				}
+				pp.Del(ctx, legacy.roomKey(prefix, roomName))
+			}
+			n++
+		}
+		pp.HDel(ctx, RoomsKey, batch...)
+		pp.HDel(ctx, RoomInternalKey, batch...)
+		if _, err = pp.Exec(ctx); err != nil {
+			return migrated, errors.Wrap(err, "could not store rooms")
+		}
+		migrated += n
+	}
+
+	// room scoped keys that outlive rooms, such as events and quality reports, are found by their prefix
+	for _, prefix := range []string{RoomSnapshotPrefix, RoomEventsPrefix, RoomEventsCountPrefix, RoomQualityReportPrefix, RoomEgressPrefix} {
+		if err = s.migrateRoomKeys(ctx, prefix); err != nil {
+			return migrated, err
+		}
+	}
+	return migrated, nil
+}
+
+// migrateRoomKeys moves keys of the prefix written by a store that is not sharded to their sharded key,
+// keeping their value and expiry
+func (s *RedisStore) migrateRoomKeys(ctx context.Context, prefix string) error {
+	scan := func(ctx context.Context, c redis.UniversalClient) error {
+		iter := c.Scan(ctx, 0, prefix+"*", listBatchSize).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			roomName := strings.TrimPrefix(key, prefix)
+			if strings.HasPrefix(roomName, "{") && strings.HasSuffix(roomName, "}") {
+				// already sharded
+				continue
+			}
+
+			value, err := s.rc.Dump(ctx, key).Result()
+			if err == redis.Nil {
+				// expired since it was listed
+				continue
+			} else if err != nil {
+				return errors.Wrapf(err, "could not read %s", key)
+			}
+			ttl, err := s.rc.PTTL(ctx, key).Result()
+			if err != nil {
+				return errors.Wrapf(err, "could not read %s", key)
+			}
+			if ttl < 0 {
+				// no expiry
+				ttl = 0
+			}
+
+			pp := s.rc.Pipeline()
+			pp.RestoreReplace(ctx, s.roomKey(prefix, livekit.RoomName(roomName)), ttl, value)
+			pp.Del(ctx, key)
+			if _, err = pp.Exec(ctx); err != nil {
+				return errors.Wrapf(err, "could not move %s", key)
+			}
+		}
+		return iter.Err()
+	}
+
+	// keys of a cluster are spread over its masters, each is scanned
+	if cc, ok := s.rc.(*redis.ClusterClient); ok {
+		return cc.ForEachMaster(ctx, func(ctx context.Context, c *redis.Client) error {
+			return scan(ctx, c)
+		})
+	}
+	return scan(ctx, s.rc)
+}
+
 func redisStoreOne(ctx context.Context, s *RedisStore, key, id string, p proto.Message) error {
 	if id == "" {
 		return errors.New("id is not set")
@@ -16,6 +16,7 @@ package service_test
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -29,7 +30,9 @@ import (
 	"github.com/livekit/protocol/utils"
 	"github.com/livekit/protocol/utils/guid"
 
+	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/telemetry"
 )
 
 func redisStoreDocker(t testing.TB) *service.RedisStore {
@@ -115,6 +118,120 @@ func TestParticipantPersistence(t *testing.T) {
 	require.Equal(t, err, service.ErrParticipantNotFound)
 }
 
+func TestShardedRoomStore(t *testing.T) {
+	ctx := context.Background()
+	rc := redisClient(t)
+	rs := service.NewRedisStore(rc)
+	srs := service.NewShardedRedisStore(rc, 4)
+
+	roomNames := []livekit.RoomName{"sharded_room1", "sharded_room2", "sharded_room3"}
+	t.Cleanup(func() {
+		for _, roomName := range roomNames {
+			_ = rs.DeleteRoom(ctx, roomName)
+			_ = srs.DeleteRoom(ctx, roomName)
+		}
+	})
+
+	// rooms stored with the previous layout are rewritten by the migration
+	for _, roomName := range roomNames {
+		require.NoError(t, rs.StoreRoom(ctx, &livekit.Room{Sid: "RM_" + string(roomName), Name: string(roomName)}, &livekit.RoomInternal{}))
+		require.NoError(t, rs.StoreParticipant(ctx, roomName, &livekit.ParticipantInfo{Sid: "PA_test", Identity: "test"}))
+	}
+	// events and quality reports outlive their room
+	require.NoError(t, rs.AppendRoomEvents(ctx, "sharded_ended", []*rtc.RoomEvent{{Type: rtc.RoomEventParticipantJoined, At: 1}}, time.Minute, 10))
+	require.NoError(t, rs.StoreRoomQualityReport(ctx, &telemetry.QualityReport{RoomName: "sharded_ended", Score: 4}, time.Minute))
+	migrated, err := srs.MigrateToShardedKeys(ctx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, migrated, len(roomNames))
+
+	for _, roomName := range roomNames {
+		_, _, err = rs.LoadRoom(ctx, roomName, false)
+		require.ErrorIs(t, err, service.ErrRoomNotFound)
+
+		room, internal, err := srs.LoadRoom(ctx, roomName, true)
+		require.NoError(t, err)
+		require.Equal(t, "RM_"+string(roomName), room.Sid)
+		require.NotNil(t, internal)
+
+		participants, err := srs.ListParticipants(ctx, roomName)
+		require.NoError(t, err)
+		require.Len(t, participants, 1)
+		require.Equal(t, "PA_test", participants[0].Sid)
+	}
+
+	events, _, err := srs.ListRoomEvents(ctx, "sharded_ended", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	report, err := srs.LoadRoomQualityReport(ctx, "sharded_ended")
+	require.NoError(t, err)
+	require.Equal(t, float32(4), report.Score)
+	_, err = rs.LoadRoomQualityReport(ctx, "sharded_ended")
+	require.ErrorIs(t, err, service.ErrRoomQualityReportNotFound)
+
+	rooms, err := srs.ListRooms(ctx, nil)
+	require.NoError(t, err)
+	found := 0
+	for _, room := range rooms {
+		if slices.Contains(roomNames, livekit.RoomName(room.Name)) {
+			found++
+		}
+	}
+	require.Equal(t, len(roomNames), found)
+
+	rooms, err = srs.ListRooms(ctx, []livekit.RoomName{"sharded_room1", "sharded_missing"})
+	require.NoError(t, err)
+	require.Len(t, rooms, 1)
+
+	require.NoError(t, srs.DeleteRoom(ctx, "sharded_room1"))
+	rooms, err = srs.ListRooms(ctx, []livekit.RoomName{"sharded_room1"})
+	require.NoError(t, err)
+	require.Empty(t, rooms)
+	participants, err := srs.ListParticipants(ctx, "sharded_room1")
+	require.NoError(t, err)
+	require.Empty(t, participants)
+}
+
+func TestRoomParticipantPolicyStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) service.ObjectStore{
+		"local": func(t *testing.T) service.ObjectStore {
+			return service.NewLocalStore()
+		},
+		"redis": func(t *testing.T) service.ObjectStore {
+			return redisStoreDocker(t)
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+			room := &livekit.Room{Sid: guid.New(utils.RoomPrefix), Name: "policy-" + guid.New("")}
+			roomName := livekit.RoomName(room.Name)
+
+			_, err := store.LoadRoomParticipantPolicy(ctx, roomName)
+			require.ErrorIs(t, err, service.ErrRoomParticipantPolicyNotFound)
+
+			require.NoError(t, store.StoreRoom(ctx, room, nil))
+			policy := &service.RoomParticipantPolicy{
+				Room:               room.Name,
+				MaxDurationSeconds: 3600,
+				IdleTimeoutSeconds: 300,
+			}
+			require.NoError(t, store.StoreRoomParticipantPolicy(ctx, policy))
+
+			loaded, err := store.LoadRoomParticipantPolicy(ctx, roomName)
+			require.NoError(t, err)
+			require.Equal(t, policy, loaded)
+			require.Equal(t, time.Hour, loaded.ParticipantPolicyConfig().MaxDuration)
+
+			// deleted along with the room
+			require.NoError(t, store.DeleteRoom(ctx, roomName))
+			_, err = store.LoadRoomParticipantPolicy(ctx, roomName)
+			require.ErrorIs(t, err, service.ErrRoomParticipantPolicyNotFound)
+		})
+	}
+}
+
 func TestRoomLock(t *testing.T) {
 	ctx := context.Background()
 	rs := redisStore(t)
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/utils"
@@ -27,7 +28,9 @@ import (
 
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/geoip"
 	"github.com/livekit/livekit-server/pkg/routing/selector"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 )
 
 type StandardRoomAllocator struct {
@@ -35,6 +38,14 @@ type StandardRoomAllocator struct {
 	router    routing.Router
 	selector  selector.NodeSelector
 	roomStore ObjectStore
+	// nil when admission control is disabled
+	capacity *selector.CapacityModel
+	// nil without a geoip database
+	locator selector.Locator
+	// nil when participants always join the origin node
+	placer *selector.ParticipantPlacer
+	// node label requirements and taint tolerations of rooms
+	placement *selector.PlacementRules
 }
 
 func NewRoomAllocator(conf *config.Config, router routing.Router, rs ObjectStore) (RoomAllocator, error) {
@@ -43,12 +54,29 @@ func NewRoomAllocator(conf *config.Config, router routing.Router, rs ObjectStore
 		return nil, err
 	}
 
-	return &StandardRoomAllocator{
+	r := &StandardRoomAllocator{
 		config:    conf,
 		router:    router,
 		selector:  ns,
 		roomStore: rs,
-	}, nil
+	}
+	if conf.Limit.Admission.Enabled {
+		r.capacity = selector.NewCapacityModel(conf.Limit.Admission, conf.Limit)
+	}
+	if path := conf.NodeSelector.ParticipantPlacement.GeoIPDatabase; path != "" {
+		db, err := geoip.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		r.locator = db
+	}
+	if r.placer, err = selector.NewParticipantPlacer(conf, r.locator); err != nil {
+		return nil, err
+	}
+	if r.placement, err = selector.NewPlacementRules(conf.NodeSelector.RoomPlacement); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
 func (r *StandardRoomAllocator) AutoCreateEnabled(context.Context) bool {
@@ -90,6 +118,13 @@ func (r *StandardRoomAllocator) CreateRoom(ctx context.Context, req *livekit.Cre
 		return nil, nil, false, err
 	}
 
+	if created {
+		req, err = r.applyRoomSchedule(ctx, req)
+		if err != nil {
+			return nil, nil, false, err
+		}
+	}
+
 	if req.EmptyTimeout > 0 {
 		rm.EmptyTimeout = req.EmptyTimeout
 	}
@@ -141,7 +176,7 @@ func (r *StandardRoomAllocator) SelectRoomNode(ctx context.Context, roomName liv
 	// if already assigned and still available, keep it on that node
 	if err == nil && selector.IsAvailable(existing) {
 		// if node hosting the room is full, deny entry
-		if selector.LimitsReached(r.config.Limit, existing.Stats) {
+		if selector.LimitsReached(roomLimitConfig(r.config, roomName), existing.Stats) {
 			return routing.ErrNodeLimitReached
 		}
 
@@ -154,11 +189,27 @@ func (r *StandardRoomAllocator) SelectRoomNode(ctx context.Context, roomName liv
 		if err != nil {
 			return err
 		}
+		nodes = r.placement.FilterNodes(roomName, nodes)
+
+		var admissions map[livekit.NodeID]selector.Admission
+		if r.capacity != nil {
+			if nodes, admissions, err = r.filterNodesWithCapacity(nodes); err != nil {
+				return err
+			}
+		}
 
-		node, err := r.selector.SelectNode(nodes)
+		var node *livekit.Node
+		if rs, ok := r.selector.(selector.RoomNodeSelector); ok {
+			node, err = rs.SelectNodeForRoom(ctx, r.roomForNodeSelection(ctx, roomName), nodes)
+		} else {
+			node, err = r.selector.SelectNode(nodes)
+		}
 		if err != nil {
 			return err
 		}
+		if a, ok := admissions[livekit.NodeID(node.Id)]; ok {
+			r.capacity.Reserve(a)
+		}
 
 		nodeID = livekit.NodeID(node.Id)
 	}
@@ -172,6 +223,16 @@ func (r *StandardRoomAllocator) SelectRoomNode(ctx context.Context, roomName liv
 	return nil
 }
 
+// roomForNodeSelection returns the stored room when it was created ahead of placement, rooms created
+// on join are only known by name
+func (r *StandardRoomAllocator) roomForNodeSelection(ctx context.Context, roomName livekit.RoomName) *livekit.Room {
+	room, _, err := r.roomStore.LoadRoom(ctx, roomName, false)
+	if err != nil || room == nil {
+		return &livekit.Room{Name: string(roomName)}
+	}
+	return room
+}
+
 func (r *StandardRoomAllocator) ValidateCreateRoom(ctx context.Context, roomName livekit.RoomName) error {
 	// when auto create is disabled, we'll check to ensure it's already created
 	if !r.config.Room.AutoCreate {
@@ -180,9 +241,148 @@ func (r *StandardRoomAllocator) ValidateCreateRoom(ctx context.Context, roomName
 			return err
 		}
 	}
+
+	// scheduled rooms only accept joins during an occurrence, unless early joins wait in the lobby
+	schedule, err := r.roomStore.LoadRoomSchedule(ctx, roomName)
+	if errors.Is(err, ErrRoomScheduleNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	start, _, ok := schedule.Window(time.Now())
+	if !ok {
+		return ErrRoomScheduleEnded
+	}
+	if time.Now().Before(start) && schedule.EarlyJoin != RoomScheduleEarlyJoinLobby {
+		return ErrRoomNotStarted
+	}
 	return nil
 }
 
+// AdmitParticipant refuses a participant joining a room when its expected cost would overload the node hosting the room.
+// Admitted participants are reserved on the node when reserve is set, a dry run only checks them.
+func (r *StandardRoomAllocator) AdmitParticipant(ctx context.Context, roomName livekit.RoomName, node *livekit.Node, grants *auth.ClaimGrants, reserve bool) error {
+	if r.capacity == nil {
+		return nil
+	}
+
+	room, _, err := r.roomStore.LoadRoom(ctx, roomName, false)
+	if errors.Is(err, ErrRoomNotFound) {
+		room = nil
+	} else if err != nil {
+		return err
+	}
+
+	if !reserve {
+		if !r.capacity.Check(node, room, grants.Video.GetCanPublish(), grants.Video.GetCanSubscribe()).Admitted {
+			return ErrNodeOverloaded
+		}
+		return nil
+	}
+
+	a := r.capacity.Admit(node, room, grants.Video.GetCanPublish(), grants.Video.GetCanSubscribe())
+	recordAdmission(a)
+	if !a.Admitted {
+		prometheus.RecordAdmissionDecision(prometheus.AdmissionRefused)
+		logger.Infow("refusing participant, node would be overloaded",
+			"room", roomName,
+			"nodeID", node.Id,
+			"predictedCPULoad", a.CPULoad,
+			"predictedBytesPerSec", a.BytesPerSec,
+		)
+		return ErrNodeOverloaded
+	}
+	prometheus.RecordAdmissionDecision(prometheus.AdmissionAdmitted)
+	return nil
+}
+
+// SelectParticipantNode returns the edge node a participant connecting from clientIP should join the room on,
+// or an empty node ID when it should join the origin node hosting the room
+func (r *StandardRoomAllocator) SelectParticipantNode(ctx context.Context, roomName livekit.RoomName, clientIP string) (livekit.NodeID, error) {
+	if r.placer == nil {
+		return "", nil
+	}
+
+	origin, err := r.router.GetNodeForRoom(ctx, roomName)
+	if err != nil {
+		return "", err
+	}
+	nodes, err := r.router.ListNodes()
+	if err != nil {
+		return "", err
+	}
+	// edge nodes are held to the constraints of the room as well
+	nodes = r.placement.FilterNodes(roomName, nodes)
+
+	node := r.placer.SelectNode(clientIP, roomName, origin, nodes)
+	if node == nil || node.Id == origin.Id {
+		return "", nil
+	}
+	logger.Debugw("placing participant on edge node", "room", roomName, "originNodeID", origin.Id, "edgeNodeID", node.Id, "region", node.Region)
+	return livekit.NodeID(node.Id), nil
+}
+
+func (r *StandardRoomAllocator) SortRegionsByDistance(clientIP string, regions []config.RegionConfig) bool {
+	if r.locator == nil {
+		return false
+	}
+	return selector.SortRegionsByDistance(r.locator, clientIP, regions)
+}
+
+// filterNodesWithCapacity drops nodes that would be overloaded by the first participant of a new room,
+// returning the admissions to reserve on the selected node
+func (r *StandardRoomAllocator) filterNodesWithCapacity(nodes []*livekit.Node) ([]*livekit.Node, map[livekit.NodeID]selector.Admission, error) {
+	nodes = selector.GetAvailableNodes(nodes)
+	if len(nodes) == 0 {
+		return nil, nil, selector.ErrNoAvailableNodes
+	}
+
+	admissions := make(map[livekit.NodeID]selector.Admission, len(nodes))
+	admitted := make([]*livekit.Node, 0, len(nodes))
+	for _, node := range nodes {
+		a := r.capacity.Check(node, nil, true, true)
+		recordAdmission(a)
+		if a.Admitted {
+			admitted = append(admitted, node)
+			admissions[a.NodeID] = a
+		}
+	}
+
+	switch {
+	case len(admitted) == 0:
+		prometheus.RecordAdmissionDecision(prometheus.AdmissionRefused)
+		return nil, nil, ErrNodeOverloaded
+	case len(admitted) < len(nodes):
+		prometheus.RecordAdmissionDecision(prometheus.AdmissionRedirected)
+	default:
+		prometheus.RecordAdmissionDecision(prometheus.AdmissionAdmitted)
+	}
+	return admitted, admissions, nil
+}
+
+func recordAdmission(a selector.Admission) {
+	prometheus.RecordAdmissionModel(
+		a.NodeID,
+		a.TrackCost.CPU, a.TrackCost.BytesInPerSec, a.TrackCost.BytesOutPerSec,
+		a.Participant.TracksIn, a.Participant.TracksOut,
+		a.CPULoad, a.BytesPerSec,
+	)
+}
+
+// applyRoomSchedule fills the configuration of a scheduled room not given in the request
+func (r *StandardRoomAllocator) applyRoomSchedule(ctx context.Context, req *livekit.CreateRoomRequest) (*livekit.CreateRoomRequest, error) {
+	schedule, err := r.roomStore.LoadRoomSchedule(ctx, livekit.RoomName(req.Name))
+	if errors.Is(err, ErrRoomScheduleNotFound) {
+		return req, nil
+	} else if err != nil {
+		return req, err
+	}
+
+	clone := utils.CloneProto(req)
+	schedule.applyToCreateRoomRequest(clone)
+	return clone, nil
+}
+
 func applyDefaultRoomConfig(room *livekit.Room, internal *livekit.RoomInternal, conf *config.RoomConfig) {
 	room.EmptyTimeout = conf.EmptyTimeout
 	room.DepartureTimeout = conf.DepartureTimeout
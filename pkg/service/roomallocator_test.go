@@ -17,9 +17,11 @@ package service_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 
 	"github.com/livekit/livekit-server/pkg/config"
@@ -47,6 +49,71 @@ func TestCreateRoom(t *testing.T) {
 	})
 }
 
+func TestScheduledRoom(t *testing.T) {
+	conf, err := config.NewConfig("", true, nil, nil)
+	require.NoError(t, err)
+	node, err := routing.NewLocalNode(conf)
+	require.NoError(t, err)
+
+	newAllocator := func(schedule *service.RoomSchedule) service.RoomAllocator {
+		store := &servicefakes.FakeObjectStore{}
+		store.LoadRoomReturns(nil, nil, service.ErrRoomNotFound)
+		store.LoadRoomScheduleReturns(schedule, nil)
+		router := &routingfakes.FakeRouter{}
+		router.GetNodeForRoomReturns(node.Clone(), nil)
+
+		ra, err := service.NewRoomAllocator(conf, router, store)
+		require.NoError(t, err)
+		return ra
+	}
+	now := time.Now()
+
+	t.Run("joins before start are rejected", func(t *testing.T) {
+		ra := newAllocator(&service.RoomSchedule{
+			Room:      "standup",
+			StartTime: now.Add(time.Hour).Unix(),
+			EndTime:   now.Add(2 * time.Hour).Unix(),
+		})
+		require.ErrorIs(t, ra.ValidateCreateRoom(context.Background(), "standup"), service.ErrRoomNotStarted)
+	})
+
+	t.Run("joins before start wait in the lobby", func(t *testing.T) {
+		ra := newAllocator(&service.RoomSchedule{
+			Room:      "standup",
+			StartTime: now.Add(time.Hour).Unix(),
+			EndTime:   now.Add(2 * time.Hour).Unix(),
+			EarlyJoin: service.RoomScheduleEarlyJoinLobby,
+		})
+		require.NoError(t, ra.ValidateCreateRoom(context.Background(), "standup"))
+	})
+
+	t.Run("joins after the last occurrence are rejected", func(t *testing.T) {
+		ra := newAllocator(&service.RoomSchedule{
+			Room:      "standup",
+			StartTime: now.Add(-2 * time.Hour).Unix(),
+			EndTime:   now.Add(-time.Hour).Unix(),
+		})
+		require.ErrorIs(t, ra.ValidateCreateRoom(context.Background(), "standup"), service.ErrRoomScheduleEnded)
+	})
+
+	t.Run("room is created with the scheduled configuration", func(t *testing.T) {
+		ra := newAllocator(&service.RoomSchedule{
+			Room:            "standup",
+			StartTime:       now.Add(-time.Minute).Unix(),
+			EndTime:         now.Add(time.Hour).Unix(),
+			MaxParticipants: 8,
+			Metadata:        "daily standup",
+		})
+		require.NoError(t, ra.ValidateCreateRoom(context.Background(), "standup"))
+
+		room, _, created, err := ra.CreateRoom(context.Background(), &livekit.CreateRoomRequest{Name: "standup"}, false)
+		require.NoError(t, err)
+		require.True(t, created)
+		require.Equal(t, uint32(8), room.MaxParticipants)
+		require.Equal(t, "daily standup", room.Metadata)
+	})
+}
+
 func SelectRoomNode(t *testing.T) {
 	t.Run("reject new participants when track limit has been reached", func(t *testing.T) {
 		conf, err := config.NewConfig("", true, nil, nil)
@@ -85,9 +152,79 @@ func SelectRoomNode(t *testing.T) {
 	})
 }
 
+func TestAdmission(t *testing.T) {
+	conf, err := config.NewConfig("", true, nil, nil)
+	require.NoError(t, err)
+	conf.Limit.Admission.Enabled = true
+	conf.Limit.Admission.MaxCPULoad = 0.8
+
+	newNode := func(id string, cpuLoad float32) *livekit.Node {
+		return &livekit.Node{
+			Id:    id,
+			State: livekit.NodeState_SERVING,
+			Stats: &livekit.NodeStats{UpdatedAt: time.Now().Unix(), NumCpus: 8, CpuLoad: cpuLoad},
+		}
+	}
+	grants := &auth.ClaimGrants{Video: &auth.VideoGrant{RoomJoin: true}}
+
+	t.Run("refuses participants that would overload the node", func(t *testing.T) {
+		store := &servicefakes.FakeObjectStore{}
+		store.LoadRoomReturns(&livekit.Room{Name: "big-room", NumParticipants: 200, NumPublishers: 20}, nil, nil)
+		ra, err := service.NewRoomAllocator(conf, &routingfakes.FakeRouter{}, store)
+		require.NoError(t, err)
+
+		require.NoError(t, ra.AdmitParticipant(context.Background(), "big-room", newNode("ND_idle", 0.1), grants, true))
+		require.ErrorIs(t, ra.AdmitParticipant(context.Background(), "big-room", newNode("ND_busy", 0.75), grants, true), service.ErrNodeOverloaded)
+		require.ErrorIs(t, ra.AdmitParticipant(context.Background(), "big-room", newNode("ND_busy", 0.75), grants, false), service.ErrNodeOverloaded)
+	})
+
+	t.Run("dry runs do not reserve capacity", func(t *testing.T) {
+		store := &servicefakes.FakeObjectStore{}
+		store.LoadRoomReturns(&livekit.Room{Name: "big-room", NumParticipants: 200, NumPublishers: 20}, nil, nil)
+		newAllocator := func() service.RoomAllocator {
+			ra, err := service.NewRoomAllocator(conf, &routingfakes.FakeRouter{}, store)
+			require.NoError(t, err)
+			return ra
+		}
+		node := newNode("ND_idle", 0.5)
+
+		// reservations fill the node up
+		ra := newAllocator()
+		admitted := 0
+		for ; admitted < 1000; admitted++ {
+			if ra.AdmitParticipant(context.Background(), "big-room", node, grants, true) != nil {
+				break
+			}
+		}
+		require.Less(t, admitted, 1000)
+
+		ra = newAllocator()
+		for i := 0; i <= admitted; i++ {
+			require.NoError(t, ra.AdmitParticipant(context.Background(), "big-room", node, grants, false))
+		}
+		require.NoError(t, ra.AdmitParticipant(context.Background(), "big-room", node, grants, true))
+	})
+
+	t.Run("new rooms are placed on nodes with headroom", func(t *testing.T) {
+		router := &routingfakes.FakeRouter{}
+		router.GetNodeForRoomReturns(nil, routing.ErrNotFound)
+		router.ListNodesReturns([]*livekit.Node{newNode("ND_busy", 0.85), newNode("ND_idle", 0.1)}, nil)
+		ra, err := service.NewRoomAllocator(conf, router, &servicefakes.FakeObjectStore{})
+		require.NoError(t, err)
+
+		require.NoError(t, ra.SelectRoomNode(context.Background(), "new-room", ""))
+		_, _, nodeID := router.SetNodeForRoomArgsForCall(0)
+		require.Equal(t, livekit.NodeID("ND_idle"), nodeID)
+
+		router.ListNodesReturns([]*livekit.Node{newNode("ND_busy", 0.85)}, nil)
+		require.ErrorIs(t, ra.SelectRoomNode(context.Background(), "new-room", ""), service.ErrNodeOverloaded)
+	})
+}
+
 func newTestRoomAllocator(t *testing.T, conf *config.Config, node *livekit.Node) (service.RoomAllocator, *config.Config) {
 	store := &servicefakes.FakeObjectStore{}
 	store.LoadRoomReturns(nil, nil, service.ErrRoomNotFound)
+	store.LoadRoomScheduleReturns(nil, service.ErrRoomScheduleNotFound)
 	router := &routingfakes.FakeRouter{}
 
 	router.GetNodeForRoomReturns(node, nil)
@@ -0,0 +1,124 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DrainRooms moves the rooms hosted on this node to other nodes as it shuts down. Each room is assigned
+// to a node that is still serving, and its participants are asked to reconnect there. Rooms move at
+// random times within the window, so their clients do not all reconnect at once, while participants of
+// a room move together.
+func (r *RoomManager) DrainRooms(window time.Duration) {
+	r.lock.RLock()
+	rooms := maps.Values(r.rooms)
+	r.lock.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, room := range rooms {
+		var delay time.Duration
+		if window > 0 {
+			delay = time.Duration(rand.Int63n(int64(window)))
+		}
+		wg.Add(1)
+		time.AfterFunc(delay, func() {
+			defer wg.Done()
+			r.drainRoom(room)
+		})
+	}
+	wg.Wait()
+}
+
+func (r *RoomManager) drainRoom(room *rtc.Room) {
+	ctx := context.Background()
+	roomName := room.Name()
+
+	// the final snapshot is stored before the room can be placed on another node, which restores it
+	r.lock.RLock()
+	snapshotter := r.snapshotters[roomName]
+	r.lock.RUnlock()
+	if snapshotter != nil {
+		snapshotter.Handoff()
+	}
+
+	// the assignment to this node is cleared for the room to be placed on another one
+	if err := r.router.ClearRoomState(ctx, roomName); err != nil {
+		room.Logger.Warnw("could not clear room node", err)
+		return
+	}
+	if err := r.roomAllocator.SelectRoomNode(ctx, roomName, ""); err != nil {
+		room.Logger.Warnw("could not move room off draining node", err)
+		// participants stay until they leave or the node stops
+		if err = r.router.SetNodeForRoom(ctx, roomName, r.currentNode.NodeID()); err != nil {
+			room.Logger.Errorw("could not restore room node", err)
+		}
+		return
+	}
+
+	participants := room.GetParticipants()
+	room.Logger.Infow("moving room off draining node", "numParticipants", len(participants))
+	for _, p := range participants {
+		p.IssueFullReconnect(types.ParticipantCloseReasonNodeDraining)
+	}
+}
+
+// isRoomMoved returns true when the room is assigned to another node
+func (r *RoomManager) isRoomMoved(ctx context.Context, roomName livekit.RoomName) bool {
+	node, err := r.router.GetNodeForRoom(ctx, roomName)
+	return err == nil && livekit.NodeID(node.Id) != r.currentNode.NodeID()
+}
+
+// regionSettings lists the regions clients reconnect to, nearest to the client first. When the client cannot
+// be located, the region of the node hosting the room comes first.
+func (r *RoomManager) regionSettings(ctx context.Context, roomName livekit.RoomName, clientIP string) *livekit.RegionSettings {
+	regions := make([]config.RegionConfig, 0, len(r.config.NodeSelector.Regions))
+	for _, region := range r.config.NodeSelector.Regions {
+		if region.URL != "" {
+			regions = append(regions, region)
+		}
+	}
+	if len(regions) == 0 {
+		return nil
+	}
+
+	if !r.roomAllocator.SortRegionsByDistance(clientIP, regions) {
+		if node, err := r.router.GetNodeForRoom(ctx, roomName); err == nil {
+			if i := slices.IndexFunc(regions, func(region config.RegionConfig) bool { return region.Name == node.Region }); i > 0 {
+				region := regions[i]
+				copy(regions[1:i+1], regions[:i])
+				regions[0] = region
+			}
+		}
+	}
+
+	settings := &livekit.RegionSettings{}
+	for _, region := range regions {
+		settings.Regions = append(settings.Regions, &livekit.RegionInfo{Region: region.Name, Url: region.URL})
+	}
+	return settings
+}
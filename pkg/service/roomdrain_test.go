@@ -0,0 +1,272 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/protocol/webhook"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/metric"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/routingfakes"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/telemetryfakes"
+)
+
+func TestDrainRooms(t *testing.T) {
+	t.Run("rooms move off the draining node", func(t *testing.T) {
+		r, router, allocator := newDrainTestRoomManager(t)
+		participants := addDrainTestRoom(t, r, "room1", 2)
+		participants = append(participants, addDrainTestRoom(t, r, "room2", 1)...)
+
+		start := time.Now()
+		r.DrainRooms(100 * time.Millisecond)
+		require.Less(t, time.Since(start), time.Second)
+
+		// each room is placed on another node, its participants are asked to reconnect there
+		require.Equal(t, 2, router.ClearRoomStateCallCount())
+		require.ElementsMatch(t, []livekit.RoomName{"room1", "room2"}, allocator.selected)
+		require.Zero(t, router.SetNodeForRoomCallCount())
+		for _, p := range participants {
+			require.Equal(t, 1, p.IssueFullReconnectCallCount())
+			require.Equal(t, types.ParticipantCloseReasonNodeDraining, p.IssueFullReconnectArgsForCall(0))
+		}
+	})
+
+	t.Run("room stays when no other node can host it", func(t *testing.T) {
+		r, router, allocator := newDrainTestRoomManager(t)
+		allocator.err = errors.New("no available nodes")
+		participants := addDrainTestRoom(t, r, "room1", 2)
+
+		r.DrainRooms(0)
+
+		require.Equal(t, 1, router.SetNodeForRoomCallCount())
+		_, roomName, nodeID := router.SetNodeForRoomArgsForCall(0)
+		require.Equal(t, livekit.RoomName("room1"), roomName)
+		require.Equal(t, r.currentNode.NodeID(), nodeID)
+		for _, p := range participants {
+			require.Zero(t, p.IssueFullReconnectCallCount())
+		}
+	})
+
+	t.Run("room stays when its node cannot be cleared", func(t *testing.T) {
+		r, router, allocator := newDrainTestRoomManager(t)
+		router.ClearRoomStateReturns(errors.New("store unavailable"))
+		participants := addDrainTestRoom(t, r, "room1", 1)
+
+		r.DrainRooms(0)
+
+		require.Empty(t, allocator.selected)
+		require.Zero(t, participants[0].IssueFullReconnectCallCount())
+	})
+}
+
+func TestDrainRoomSnapshot(t *testing.T) {
+	prometheus.Init("test", livekit.NodeType_SERVER)
+
+	conf, err := config.NewConfig("", true, nil, nil)
+	require.NoError(t, err)
+	conf.Room.Snapshot = config.RoomSnapshotConfig{Enabled: true, Interval: time.Hour, Retention: time.Hour}
+	conf.Room.QualityReport = config.QualityReportConfig{Enabled: true, Retention: time.Hour}
+	currentNode, err := routing.NewLocalNode(conf)
+	require.NoError(t, err)
+	currentNode.SetNodeID("ND_draining")
+
+	store := NewLocalStore()
+	router := &routingfakes.FakeRouter{}
+	router.GetNodeForRoomReturns(&livekit.Node{Id: "ND_draining"}, nil)
+	roomTelemetry := &telemetryfakes.FakeTelemetryService{}
+	roomTelemetry.RoomQualityReportReturns(&telemetry.QualityReport{RoomName: "room1"})
+	r, err := NewLocalRoomManager(
+		conf,
+		store,
+		currentNode,
+		router,
+		&edgeTestRoomAllocator{store: store},
+		roomTelemetry,
+		nil,
+		nil,
+		store,
+		nil,
+		utils.NewDefaultTimedVersionGenerator(),
+		nil,
+		psrpc.NewLocalMessageBus(),
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	t.Cleanup(r.Stop)
+
+	room, err := r.getOrCreateRoom(context.Background(), &livekit.CreateRoomRequest{Name: "room1"})
+	require.NoError(t, err)
+	room.Release()
+
+	// the snapshot is in place before the room can be placed on another node
+	router.ClearRoomStateStub = func(ctx context.Context, roomName livekit.RoomName) error {
+		snapshot, err := store.LoadRoomSnapshot(ctx, roomName)
+		require.NoError(t, err)
+		require.Equal(t, room.ID(), livekit.RoomID(snapshot.Room.Sid))
+		return nil
+	}
+	r.drainRoom(room)
+	require.Equal(t, 1, router.ClearRoomStateCallCount())
+
+	// the new node restored the snapshot, the old room closing does not write it again or end the room
+	require.NoError(t, store.DeleteRoomSnapshot(context.Background(), "room1"))
+	router.GetNodeForRoomReturns(&livekit.Node{Id: "ND_new"}, nil)
+	room.Close(types.ParticipantCloseReasonNone)
+	require.Eventually(t, func() bool {
+		return r.GetRoom(context.Background(), "room1") == nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = store.LoadRoomSnapshot(context.Background(), "room1")
+	require.ErrorIs(t, err, ErrRoomSnapshotNotFound)
+	_, err = store.LoadRoomQualityReport(context.Background(), "room1")
+	require.Error(t, err)
+	require.Zero(t, roomTelemetry.RoomEndedCallCount())
+	_, _, err = store.LoadRoom(context.Background(), "room1", false)
+	require.NoError(t, err)
+}
+
+func TestRegionSettings(t *testing.T) {
+	r, router, allocator := newDrainTestRoomManager(t)
+	r.config.NodeSelector.Regions = []config.RegionConfig{
+		{Name: "us-west", URL: "wss://us-west.example.com"},
+		{Name: "us-east", URL: "wss://us-east.example.com"},
+		{Name: "internal"},
+		{Name: "eu-central", URL: "wss://eu-central.example.com"},
+	}
+	router.GetNodeForRoomReturns(&livekit.Node{Id: "ND_new", Region: "us-east"}, nil)
+	regionNames := func(settings *livekit.RegionSettings) []string {
+		var names []string
+		for _, region := range settings.Regions {
+			names = append(names, region.Region)
+		}
+		return names
+	}
+
+	t.Run("nearest regions first", func(t *testing.T) {
+		allocator.order = []string{"eu-central", "us-east", "us-west"}
+		defer func() { allocator.order = nil }()
+
+		settings := r.regionSettings(context.Background(), "room1", "81.2.69.160")
+		require.Equal(t, []string{"eu-central", "us-east", "us-west"}, regionNames(settings))
+		require.Equal(t, "wss://eu-central.example.com", settings.Regions[0].Url)
+		require.Equal(t, []string{"81.2.69.160"}, allocator.clientIPs)
+	})
+
+	t.Run("region of the room first for unknown clients", func(t *testing.T) {
+		settings := r.regionSettings(context.Background(), "room1", "")
+		require.Equal(t, []string{"us-east", "us-west", "eu-central"}, regionNames(settings))
+	})
+
+	t.Run("none without urls", func(t *testing.T) {
+		r.config.NodeSelector.Regions = []config.RegionConfig{{Name: "internal"}}
+		require.Nil(t, r.regionSettings(context.Background(), "room1", "81.2.69.160"))
+	})
+}
+
+func newDrainTestRoomManager(t *testing.T) (*RoomManager, *routingfakes.FakeRouter, *drainTestRoomAllocator) {
+	conf, err := config.NewConfig("", true, nil, nil)
+	require.NoError(t, err)
+	currentNode, err := routing.NewLocalNode(nil)
+	require.NoError(t, err)
+
+	router := &routingfakes.FakeRouter{}
+	allocator := &drainTestRoomAllocator{}
+	r := &RoomManager{
+		config:        conf,
+		currentNode:   currentNode,
+		router:        router,
+		roomAllocator: allocator,
+		rooms:         make(map[livekit.RoomName]*rtc.Room),
+	}
+	t.Cleanup(func() {
+		for _, room := range r.rooms {
+			room.Close(types.ParticipantCloseReasonNone)
+		}
+	})
+	return r, router, allocator
+}
+
+func addDrainTestRoom(t *testing.T, r *RoomManager, roomName livekit.RoomName, numParticipants int) []*typesfakes.FakeLocalParticipant {
+	room := rtc.NewRoom(
+		&livekit.Room{Name: string(roomName)},
+		nil,
+		rtc.WebRTCConfig{},
+		config.RoomConfig{EmptyTimeout: 5 * 60, DepartureTimeout: 1},
+		&sfu.AudioConfig{},
+		&livekit.ServerInfo{},
+		telemetry.NewTelemetryService(webhook.NewDefaultNotifier("", "", nil), &telemetryfakes.FakeAnalyticsService{}, metric.MetricsAggregatorConfig{}),
+		nil, nil, nil,
+	)
+	var participants []*typesfakes.FakeLocalParticipant
+	for i := 0; i < numParticipants; i++ {
+		p := rtc.NewMockParticipant(livekit.ParticipantIdentity(fmt.Sprintf("%s-p%d", roomName, i)), types.CurrentProtocol, false, false)
+		require.NoError(t, room.Join(p, nil, nil, nil))
+		participants = append(participants, p)
+	}
+	r.rooms[roomName] = room
+	return participants
+}
+
+// drainTestRoomAllocator places drained rooms and orders regions as configured
+type drainTestRoomAllocator struct {
+	RoomAllocator
+	lock      sync.Mutex
+	err       error
+	selected  []livekit.RoomName
+	order     []string
+	clientIPs []string
+}
+
+func (a *drainTestRoomAllocator) SelectRoomNode(_ context.Context, roomName livekit.RoomName, _ livekit.NodeID) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.err != nil {
+		return a.err
+	}
+	a.selected = append(a.selected, roomName)
+	return nil
+}
+
+func (a *drainTestRoomAllocator) SortRegionsByDistance(clientIP string, regions []config.RegionConfig) bool {
+	a.clientIPs = append(a.clientIPs, clientIP)
+	if a.order == nil {
+		return false
+	}
+	slices.SortFunc(regions, func(x, y config.RegionConfig) int {
+		return slices.Index(a.order, x.Name) - slices.Index(a.order, y.Name)
+	})
+	return true
+}
@@ -0,0 +1,451 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// Participants may connect to an edge node in their region instead of the origin node hosting their room,
+// see selector.ParticipantPlacer. The edge node runs its own instance of the room, served on an edge topic,
+// and joins the room at the origin node. Tracks published on either node are relayed to the other one, where
+// they are published by a participant mirroring the publisher. The origin relays the tracks of its participants
+// as well as the tracks relayed from the other edges, so that all participants of the room receive each other.
+//
+// Participants without published tracks and data messages are not mirrored, RoomService requests for
+// participants connected to an edge node go to the origin node.
+
+func edgeRoomTopic(roomName livekit.RoomName, nodeID livekit.NodeID) rpc.RoomTopic {
+	return rpc.FormatRoomTopic(livekit.RoomName(fmt.Sprintf("%s@%s", roomName, nodeID)))
+}
+
+type roomEdgeKey struct {
+	room livekit.RoomName
+	node livekit.NodeID
+}
+
+// roomEdgeLink relays the tracks of a room on this node to the instance of the room on another node
+type roomEdgeLink struct {
+	room        *rtc.Room
+	origin      bool // this node is the origin of the room
+	remoteNode  livekit.NodeID
+	remoteTopic rpc.RoomTopic
+	localTopic  rpc.RoomTopic
+
+	lock   sync.Mutex
+	tracks map[livekit.TrackID]*rtc.TrackRelay // source track ID -> relay
+	// the room on the remote node is gone, relays stop without notifying it
+	remoteClosed bool
+	closed       bool
+}
+
+func newRoomEdgeLink(room *rtc.Room, origin bool, remoteNode livekit.NodeID, remoteTopic, localTopic rpc.RoomTopic) *roomEdgeLink {
+	return &roomEdgeLink{
+		room:        room,
+		origin:      origin,
+		remoteNode:  remoteNode,
+		remoteTopic: remoteTopic,
+		localTopic:  localTopic,
+		tracks:      make(map[livekit.TrackID]*rtc.TrackRelay),
+	}
+}
+
+func (l *roomEdgeLink) shouldRelay(p types.Participant, track types.MediaTrack) bool {
+	if _, ok := p.(types.LocalParticipant); ok {
+		return true
+	}
+	if !l.origin {
+		return false
+	}
+	// forwarded into the room, including tracks of the other edges but not those from the remote node itself
+	rt, ok := track.(*relayedTrack)
+	return !ok || rt.sourceNode != l.remoteNode
+}
+
+func (l *roomEdgeLink) trackBySession(sessionID uint64) *rtc.TrackRelay {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, tr := range l.tracks {
+		if tr.SessionID() == sessionID {
+			return tr
+		}
+	}
+	return nil
+}
+
+func (l *roomEdgeLink) close(remoteClosed bool) {
+	l.lock.Lock()
+	if l.closed {
+		l.lock.Unlock()
+		return
+	}
+	l.closed = true
+	l.remoteClosed = remoteClosed
+	tracks := l.tracks
+	l.tracks = make(map[livekit.TrackID]*rtc.TrackRelay)
+	l.lock.Unlock()
+
+	for _, tr := range tracks {
+		tr.Close()
+	}
+}
+
+// edgeOriginNode returns the origin node of a room when participants connecting to this node join it as an edge
+func (r *RoomManager) edgeOriginNode(ctx context.Context, roomName livekit.RoomName) livekit.NodeID {
+	if r.relayTransport == nil {
+		return ""
+	}
+	node, err := r.router.GetNodeForRoom(ctx, roomName)
+	if err != nil || livekit.NodeID(node.Id) == r.currentNode.NodeID() {
+		return ""
+	}
+	return livekit.NodeID(node.Id)
+}
+
+func (r *RoomManager) getOrCreateEdgeRoom(ctx context.Context, createRoom *livekit.CreateRoomRequest, originNodeID livekit.NodeID) (*rtc.Room, error) {
+	roomName := livekit.RoomName(createRoom.Name)
+
+	r.lock.RLock()
+	lastSeenRoom := r.rooms[roomName]
+	r.lock.RUnlock()
+
+	if lastSeenRoom != nil && lastSeenRoom.Hold() {
+		return lastSeenRoom, nil
+	}
+
+	// the room is created at its origin, the edge runs with the same settings
+	if _, err := r.router.CreateRoom(ctx, createRoom); err != nil {
+		return nil, err
+	}
+	ri, internal, err := r.roomStore.LoadRoom(ctx, roomName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+
+	currentRoom := r.rooms[roomName]
+	for currentRoom != lastSeenRoom {
+		r.lock.Unlock()
+		if currentRoom != nil && currentRoom.Hold() {
+			return currentRoom, nil
+		}
+
+		lastSeenRoom = currentRoom
+		r.lock.Lock()
+		currentRoom = r.rooms[roomName]
+	}
+
+	newRoom := rtc.NewRoom(ri, internal, *r.rtcConfig, r.config.Room, &r.config.Audio, r.serverInfo, r.telemetry, r.agentClient, r.agentStore, r.egressLauncher)
+
+	// only relays are served for the edge, RoomService and agent dispatch requests are handled at the origin
+	edgeTopic := edgeRoomTopic(roomName, r.currentNode.NodeID())
+	roomExtensionServer := NewRoomExtensionServer(r, r.bus)
+	killRoomExtensionServer := r.roomExtensionServers.Replace(edgeTopic, roomExtensionServer)
+	if err := roomExtensionServer.RegisterAllRoomTopics(edgeTopic); err != nil {
+		killRoomExtensionServer()
+		r.lock.Unlock()
+		return nil, err
+	}
+
+	link := newRoomEdgeLink(newRoom, false, originNodeID, rpc.FormatRoomTopic(roomName), edgeTopic)
+	r.relaysLock.Lock()
+	r.edgeLinks[roomEdgeKey{room: roomName, node: originNodeID}] = link
+	r.relaysLock.Unlock()
+
+	newRoom.OnClose(func() {
+		killRoomExtensionServer()
+		r.closeRoomEdgeLinks(roomName)
+
+		// the room state belongs to the origin, it is not deleted
+		r.lock.Lock()
+		if r.rooms[roomName] == newRoom {
+			delete(r.rooms, roomName)
+		}
+		r.lock.Unlock()
+
+		go func() {
+			_, err := r.extensionClient.LeaveRoomEdge(context.Background(), rpc.FormatRoomTopic(roomName), &LeaveRoomEdgeRequest{
+				Room:   string(roomName),
+				NodeID: string(r.currentNode.NodeID()),
+			})
+			if err != nil {
+				newRoom.Logger.Debugw("could not leave room at origin", "error", err, "originNodeID", originNodeID)
+			}
+		}()
+		newRoom.Logger.Infow("edge room closed", "originNodeID", originNodeID)
+	})
+
+	newRoom.OnTrackChanged(func(p types.Participant, track types.MediaTrack) {
+		go r.relayToRoomEdge(link, p, track)
+	})
+
+	newRoom.OnParticipantChanged(func(p types.LocalParticipant) {
+		if !p.IsDisconnected() {
+			if err := r.roomStore.StoreParticipant(ctx, roomName, p.ToProto()); err != nil {
+				newRoom.Logger.Errorw("could not handle participant change", err)
+			}
+		}
+	})
+
+	r.rooms[roomName] = newRoom
+
+	r.lock.Unlock()
+
+	newRoom.Hold()
+
+	// the origin relays the tracks of the room once joined
+	_, err = r.extensionClient.JoinRoomEdge(ctx, rpc.FormatRoomTopic(roomName), &JoinRoomEdgeRequest{
+		Room:   string(roomName),
+		NodeID: string(r.currentNode.NodeID()),
+	})
+	if err != nil {
+		newRoom.Release()
+		newRoom.Close(types.ParticipantCloseReasonNone)
+		return nil, err
+	}
+
+	newRoom.Logger.Infow("edge room started", "originNodeID", originNodeID)
+	return newRoom, nil
+}
+
+// relayToRoomEdge relays a track to the room on the other node of a link, or updates it there when already relayed
+func (r *RoomManager) relayToRoomEdge(link *roomEdgeLink, p types.Participant, track types.MediaTrack) {
+	if !link.shouldRelay(p, track) {
+		return
+	}
+	ti, err := proto.Marshal(track.ToProto())
+	if err != nil {
+		return
+	}
+
+	link.lock.Lock()
+	if link.closed {
+		link.lock.Unlock()
+		return
+	}
+	if tr := link.tracks[track.ID()]; tr != nil {
+		link.lock.Unlock()
+		r.updateRoomEdgeTrack(link, &UpdateRelayedTrackRequest{
+			Room:      string(link.room.Name()),
+			Identity:  string(p.Identity()),
+			SessionID: tr.SessionID(),
+			TrackInfo: ti,
+		})
+		return
+	}
+	tr, err := rtc.NewTrackRelay(rtc.TrackRelayParams{
+		Source:          track,
+		DestinationNode: link.remoteNode,
+		Transport:       r.relayTransport,
+		Logger:          rtc.LoggerWithTrack(link.room.Logger.WithValues("participant", p.Identity(), "edgeNodeID", link.remoteNode), track.ID(), false),
+	})
+	if err != nil {
+		link.lock.Unlock()
+		// not bound yet, relayed with the next change of the track
+		link.room.Logger.Debugw("could not relay track to edge", "error", err, "trackID", track.ID(), "edgeNodeID", link.remoteNode)
+		return
+	}
+	link.tracks[track.ID()] = tr
+	link.lock.Unlock()
+
+	tr.OnClose(func(tr *rtc.TrackRelay) {
+		link.lock.Lock()
+		if link.tracks[track.ID()] == tr {
+			delete(link.tracks, track.ID())
+		}
+		remoteClosed := link.remoteClosed
+		link.lock.Unlock()
+
+		if !remoteClosed {
+			go r.updateRoomEdgeTrack(link, &UpdateRelayedTrackRequest{
+				Room:      string(link.room.Name()),
+				Identity:  string(p.Identity()),
+				SessionID: tr.SessionID(),
+				Closed:    true,
+			})
+		}
+	})
+
+	pi := p.ToProto()
+	res, err := r.extensionClient.RelayTrack(context.Background(), link.remoteTopic, &RelayTrackRequest{
+		Room:             string(link.room.Name()),
+		Identity:         string(p.Identity()),
+		Name:             pi.Name,
+		SourceRoom:       string(link.room.Name()),
+		SourceIdentity:   string(p.Identity()),
+		SourceAddress:    r.relayAddress(),
+		SessionID:        tr.SessionID(),
+		TrackInfo:        ti,
+		Codecs:           tr.Codecs(),
+		HeaderExtensions: tr.HeaderExtensions(),
+		Edge:             true,
+		Kind:             int32(pi.Kind),
+		Attributes:       pi.Attributes,
+		SourceNode:       string(r.currentNode.NodeID()),
+		SourceTopic:      string(link.localTopic),
+	})
+	if err != nil {
+		link.room.Logger.Warnw("could not relay track to edge", err, "trackID", track.ID(), "edgeNodeID", link.remoteNode)
+		tr.Close()
+		return
+	}
+	addr, err := net.ResolveUDPAddr("udp", res.Address)
+	if err != nil {
+		tr.Close()
+		return
+	}
+	tr.Start(addr, livekit.TrackID(res.TrackSid))
+}
+
+func (r *RoomManager) updateRoomEdgeTrack(link *roomEdgeLink, req *UpdateRelayedTrackRequest) {
+	if _, err := r.extensionClient.UpdateRelayedTrack(context.Background(), link.remoteTopic, req); err != nil {
+		link.room.Logger.Debugw("could not update relayed track", "error", err, "edgeNodeID", link.remoteNode, "sessionID", req.SessionID)
+	}
+}
+
+func (r *RoomManager) relayToRoomEdges(roomName livekit.RoomName, p types.Participant, track types.MediaTrack) {
+	for _, link := range r.roomEdgeLinks(roomName) {
+		go r.relayToRoomEdge(link, p, track)
+	}
+}
+
+func (r *RoomManager) roomEdgeLinks(roomName livekit.RoomName) []*roomEdgeLink {
+	r.relaysLock.Lock()
+	defer r.relaysLock.Unlock()
+
+	var links []*roomEdgeLink
+	for key, link := range r.edgeLinks {
+		if key.room == roomName {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+func (r *RoomManager) roomEdgeTrackLocked(roomName livekit.RoomName, sessionID uint64) *rtc.TrackRelay {
+	for key, link := range r.edgeLinks {
+		if key.room != roomName {
+			continue
+		}
+		if tr := link.trackBySession(sessionID); tr != nil {
+			return tr
+		}
+	}
+	return nil
+}
+
+func (r *RoomManager) closeRoomEdgeLinks(roomName livekit.RoomName) {
+	r.relaysLock.Lock()
+	var links []*roomEdgeLink
+	for key, link := range r.edgeLinks {
+		if key.room == roomName {
+			links = append(links, link)
+			delete(r.edgeLinks, key)
+		}
+	}
+	r.relaysLock.Unlock()
+
+	for _, link := range links {
+		link.close(false)
+		if link.origin {
+			link.room.Release()
+		}
+	}
+}
+
+// JoinRoomEdge starts relaying the tracks of a room at its origin to an edge node
+func (r *RoomManager) JoinRoomEdge(ctx context.Context, req *JoinRoomEdgeRequest) (*JoinRoomEdgeResponse, error) {
+	if r.relayTransport == nil {
+		return nil, ErrRelayNotEnabled
+	}
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	edgeNodeID := livekit.NodeID(req.NodeID)
+	key := roomEdgeKey{room: room.Name(), node: edgeNodeID}
+	r.relaysLock.Lock()
+	if r.edgeLinks[key] != nil {
+		r.relaysLock.Unlock()
+		return &JoinRoomEdgeResponse{}, nil
+	}
+	// the room stays open while participants are connected to the edge
+	if !room.Hold() {
+		r.relaysLock.Unlock()
+		return nil, ErrRoomNotFound
+	}
+	link := newRoomEdgeLink(room, true, edgeNodeID, edgeRoomTopic(room.Name(), edgeNodeID), rpc.FormatRoomTopic(room.Name()))
+	r.edgeLinks[key] = link
+	r.relaysLock.Unlock()
+
+	room.Logger.Infow("edge joined room", "edgeNodeID", edgeNodeID)
+	for _, p := range room.GetParticipants() {
+		for _, track := range p.GetPublishedTracks() {
+			go r.relayToRoomEdge(link, p, track)
+		}
+	}
+	for _, bp := range room.GetBridgeParticipants() {
+		for _, track := range bp.GetPublishedTracks() {
+			go r.relayToRoomEdge(link, bp, track)
+		}
+	}
+	return &JoinRoomEdgeResponse{}, nil
+}
+
+// LeaveRoomEdge stops relaying to an edge node once the room closed there
+func (r *RoomManager) LeaveRoomEdge(_ context.Context, req *LeaveRoomEdgeRequest) (*LeaveRoomEdgeResponse, error) {
+	roomName := livekit.RoomName(req.Room)
+	edgeNodeID := livekit.NodeID(req.NodeID)
+
+	r.relaysLock.Lock()
+	key := roomEdgeKey{room: roomName, node: edgeNodeID}
+	link := r.edgeLinks[key]
+	delete(r.edgeLinks, key)
+	var relayedTracks []*relayedTrack
+	for _, rt := range r.relayedTracks {
+		if rt.edge && rt.sourceNode == edgeNodeID && rt.room.Name() == roomName {
+			relayedTracks = append(relayedTracks, rt)
+		}
+	}
+	r.relaysLock.Unlock()
+
+	// tracks of the edge participants
+	for _, rt := range relayedTracks {
+		rt.closedBySource.Store(true)
+		rt.Close(false)
+	}
+	if link == nil {
+		return &LeaveRoomEdgeResponse{}, nil
+	}
+
+	link.close(true)
+	link.room.Release()
+	link.room.Logger.Infow("edge left room", "edgeNodeID", edgeNodeID)
+	return &LeaveRoomEdgeResponse{}, nil
+}
@@ -0,0 +1,361 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/protocol/webhook"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/metric"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/routing/routingfakes"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/livekit-server/pkg/sfu/relay"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/telemetryfakes"
+)
+
+const testEdgeRoom = livekit.RoomName("span")
+
+func TestRoomEdge(t *testing.T) {
+	t.Run("tracks are relayed to the edge", func(t *testing.T) {
+		origin, edge, publisher := newRoomEdgeTestNodes(t)
+		sessionID := publisher.publish(t, origin, livekit.TrackType_AUDIO)
+
+		track := edgeRelayedTrack(t, edge, "pub")
+		require.Equal(t, livekit.ParticipantInfo_STANDARD, edge.GetRoom(context.Background(), testEdgeRoom).GetBridgeParticipant("pub").Kind())
+
+		sub := newEdgeTestSubscriber()
+		require.NoError(t, track.Receivers()[0].AddDownTrack(sub))
+		for sn := uint16(1); sn <= 3; sn++ {
+			require.NoError(t, publisher.transport.WriteRTP(origin.relayTransport.LocalAddr(), sessionID, 0, 0, testEdgeRTPPacket(t, sn)))
+		}
+		for sn := uint16(1); sn <= 3; sn++ {
+			select {
+			case got := <-sub.packets:
+				require.Equal(t, sn, got)
+			case <-time.After(time.Second):
+				t.Fatal("no packet relayed to the edge")
+			}
+		}
+	})
+
+	t.Run("qualities subscribed at the edge reach the publisher node", func(t *testing.T) {
+		origin, edge, publisher := newRoomEdgeTestNodes(t)
+		sessionID := publisher.publish(t, origin, livekit.TrackType_VIDEO)
+
+		track := edgeRelayedTrack(t, edge, "pub")
+		track.NotifySubscriberNodeMaxQuality("ND_other", []types.SubscribedCodecQuality{{CodecMime: mime.MimeTypeVP8, Quality: livekit.VideoQuality_MEDIUM}})
+
+		select {
+		case req := <-publisher.qualities:
+			require.Equal(t, string(testEdgeRoom), req.Room)
+			require.Equal(t, sessionID, req.SessionID)
+			require.Equal(t, []RelaySubscribedQuality{{Mime: mime.MimeTypeVP8.String(), Quality: livekit.VideoQuality_MEDIUM}}, req.Qualities)
+		case <-time.After(time.Second):
+			t.Fatal("subscribed quality not reported")
+		}
+	})
+
+	t.Run("tracks of the edge node are not relayed back to it", func(t *testing.T) {
+		origin, edge, _ := newRoomEdgeTestNodes(t)
+		edgeRoom := edge.GetRoom(context.Background(), testEdgeRoom)
+
+		link := origin.roomEdgeLinks(testEdgeRoom)[0]
+		rt := &relayedTrack{sourceNode: edge.currentNode.NodeID()}
+		require.False(t, link.shouldRelay(nil, rt))
+		rt.sourceNode = "ND_P"
+		require.True(t, link.shouldRelay(nil, rt))
+		require.False(t, edge.roomEdgeLinks(edgeRoom.Name())[0].shouldRelay(nil, rt))
+	})
+
+	t.Run("origin stops relaying once the edge room closes", func(t *testing.T) {
+		origin, edge, publisher := newRoomEdgeTestNodes(t)
+		publisher.publish(t, origin, livekit.TrackType_AUDIO)
+		edgeRelayedTrack(t, edge, "pub")
+
+		link := origin.roomEdgeLinks(testEdgeRoom)[0]
+		require.Eventually(t, func() bool {
+			link.lock.Lock()
+			defer link.lock.Unlock()
+			return len(link.tracks) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		edge.GetRoom(context.Background(), testEdgeRoom).Close(types.ParticipantCloseReasonNone)
+		require.Eventually(t, func() bool {
+			return len(origin.roomEdgeLinks(testEdgeRoom)) == 0
+		}, time.Second, 10*time.Millisecond)
+		link.lock.Lock()
+		defer link.lock.Unlock()
+		require.True(t, link.closed)
+		require.Empty(t, link.tracks)
+	})
+}
+
+// newRoomEdgeTestNodes starts the room at an origin node, an edge node joins it
+func newRoomEdgeTestNodes(t *testing.T) (*RoomManager, *RoomManager, *edgeTestPublisher) {
+	prometheus.Init("test", livekit.NodeType_SERVER)
+
+	bus := psrpc.NewLocalMessageBus()
+	store := NewLocalStore()
+	router := &routingfakes.FakeRouter{}
+
+	origin := newRoomEdgeTestManager(t, "ND_origin", bus, store, router)
+	router.GetNodeForRoomReturns(&livekit.Node{Id: "ND_origin"}, nil)
+	_, err := origin.getOrCreateRoom(context.Background(), &livekit.CreateRoomRequest{Name: string(testEdgeRoom)})
+	require.NoError(t, err)
+
+	edge := newRoomEdgeTestManager(t, "ND_edge", bus, store, router)
+	_, err = edge.getOrCreateEdgeRoom(context.Background(), &livekit.CreateRoomRequest{Name: string(testEdgeRoom)}, "ND_origin")
+	require.NoError(t, err)
+	require.Len(t, origin.roomEdgeLinks(testEdgeRoom), 1)
+
+	publisher := &edgeTestPublisher{
+		transport: newRoomEdgeTestTransport(t),
+		qualities: make(chan *UpdateRelayQualityRequest, 10),
+	}
+	server := NewRoomExtensionServer(publisher, bus)
+	require.NoError(t, server.RegisterAllRoomTopics(edgeRoomTopic(testEdgeRoom, "ND_P")))
+	t.Cleanup(server.Kill)
+	return origin, edge, publisher
+}
+
+func newRoomEdgeTestManager(t *testing.T, nodeID livekit.NodeID, bus psrpc.MessageBus, store *LocalStore, router routing.Router) *RoomManager {
+	conf, err := config.NewConfig("", true, nil, nil)
+	require.NoError(t, err)
+	conf.Keys = map[string]string{"key": "secret"}
+	conf.RTC.NodeIP = "127.0.0.1"
+	conf.RTC.UDPPort = rtcconfig.PortRange{}
+	conf.RTC.TCPPort = 0
+	conf.RTC.Relay = config.RelayConfig{Enabled: true, BindAddress: "127.0.0.1"}
+
+	currentNode, err := routing.NewLocalNode(conf)
+	require.NoError(t, err)
+	currentNode.SetNodeID(nodeID)
+
+	extensionClient, err := NewRoomExtensionClient(rpc.ClientParams{Bus: bus})
+	require.NoError(t, err)
+
+	r, err := NewLocalRoomManager(
+		conf,
+		store,
+		currentNode,
+		router,
+		&edgeTestRoomAllocator{store: store},
+		telemetry.NewTelemetryService(webhook.NewDefaultNotifier("", "", nil), &telemetryfakes.FakeAnalyticsService{}, metric.MetricsAggregatorConfig{}),
+		nil,
+		nil,
+		store,
+		nil,
+		utils.NewDefaultTimedVersionGenerator(),
+		nil,
+		bus,
+		nil,
+		extensionClient,
+	)
+	require.NoError(t, err)
+	t.Cleanup(r.Stop)
+	return r
+}
+
+func newRoomEdgeTestTransport(t *testing.T) *relay.Transport {
+	// nodes of the cluster derive the same key from the API keys
+	tr, err := relay.NewTransport("127.0.0.1", 0, relayKey(&config.Config{Keys: map[string]string{"key": "secret"}}), logger.GetLogger())
+	require.NoError(t, err)
+	t.Cleanup(tr.Close)
+	return tr
+}
+
+// edgeRelayedTrack waits for a track of the participant to be relayed into the edge room
+func edgeRelayedTrack(t *testing.T, edge *RoomManager, identity livekit.ParticipantIdentity) *relayedTrack {
+	var track *relayedTrack
+	require.Eventually(t, func() bool {
+		bp := edge.GetRoom(context.Background(), testEdgeRoom).GetBridgeParticipant(identity)
+		if bp == nil {
+			return false
+		}
+		tracks := bp.GetPublishedTracks()
+		if len(tracks) == 0 {
+			return false
+		}
+		track = tracks[0].(*relayedTrack)
+		return true
+	}, time.Second, 10*time.Millisecond)
+	return track
+}
+
+func testEdgeRTPPacket(t *testing.T, sn uint16) []byte {
+	pkt := rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    111,
+			SequenceNumber: sn,
+			Timestamp:      uint32(sn) * 960,
+			SSRC:           1234,
+		},
+		Payload: []byte{0x10, 0x00, 0x00, 0x00},
+	}
+	b, err := pkt.Marshal()
+	require.NoError(t, err)
+	return b
+}
+
+// ---------------------------------------------------------
+
+// edgeTestPublisher is another edge node of the room with a participant publishing
+type edgeTestPublisher struct {
+	RoomExtensionServerImpl
+	transport *relay.Transport
+	qualities chan *UpdateRelayQualityRequest
+}
+
+// publish relays a track of the participant into the room at the origin
+func (p *edgeTestPublisher) publish(t *testing.T, origin *RoomManager, kind livekit.TrackType) uint64 {
+	codec := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2},
+		PayloadType:        111,
+	}
+	ti := &livekit.TrackInfo{Sid: "TR_source", Type: kind, Name: "track"}
+	if kind == livekit.TrackType_VIDEO {
+		codec = webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000},
+			PayloadType:        96,
+		}
+		ti.Width, ti.Height = 1280, 720
+		ti.Layers = []*livekit.VideoLayer{{Quality: livekit.VideoQuality_HIGH, Width: 1280, Height: 720}}
+	}
+	b, err := proto.Marshal(ti)
+	require.NoError(t, err)
+
+	sessionID := p.transport.NewSession(p)
+	_, err = origin.RelayTrack(context.Background(), &RelayTrackRequest{
+		Room:           string(testEdgeRoom),
+		Identity:       "pub",
+		SourceRoom:     string(testEdgeRoom),
+		SourceIdentity: "pub",
+		SourceAddress:  p.transport.LocalAddr().String(),
+		SessionID:      sessionID,
+		TrackInfo:      b,
+		Codecs:         []webrtc.RTPCodecParameters{codec},
+		Edge:           true,
+		Kind:           int32(livekit.ParticipantInfo_STANDARD),
+		SourceNode:     "ND_P",
+		SourceTopic:    string(edgeRoomTopic(testEdgeRoom, "ND_P")),
+	})
+	require.NoError(t, err)
+	return sessionID
+}
+
+func (p *edgeTestPublisher) UpdateRelayQuality(_ context.Context, req *UpdateRelayQualityRequest) (*UpdateRelayQualityResponse, error) {
+	p.qualities <- req
+	return &UpdateRelayQualityResponse{}, nil
+}
+
+func (p *edgeTestPublisher) HandleRTP(_ int, _ int32, _ []byte) {}
+
+func (p *edgeTestPublisher) HandleSenderReport(_ int, _ int32, _ *livekit.RTCPSenderReportState) {}
+
+func (p *edgeTestPublisher) HandleKeyFrameRequest(_ int, _ int32) {}
+
+// ---------------------------------------------------------
+
+type edgeTestRoomAllocator struct {
+	store *LocalStore
+}
+
+func (a *edgeTestRoomAllocator) AutoCreateEnabled(_ context.Context) bool {
+	return true
+}
+
+func (a *edgeTestRoomAllocator) SelectRoomNode(_ context.Context, _ livekit.RoomName, _ livekit.NodeID) error {
+	return nil
+}
+
+func (a *edgeTestRoomAllocator) CreateRoom(ctx context.Context, req *livekit.CreateRoomRequest, _ bool) (*livekit.Room, *livekit.RoomInternal, bool, error) {
+	rm := &livekit.Room{Sid: utils.NewGuid(utils.RoomPrefix), Name: req.Name, CreationTime: time.Now().Unix()}
+	internal := &livekit.RoomInternal{}
+	if err := a.store.StoreRoom(ctx, rm, internal); err != nil {
+		return nil, nil, false, err
+	}
+	return rm, internal, true, nil
+}
+
+func (a *edgeTestRoomAllocator) ValidateCreateRoom(_ context.Context, _ livekit.RoomName) error {
+	return nil
+}
+
+func (a *edgeTestRoomAllocator) AdmitParticipant(_ context.Context, _ livekit.RoomName, _ *livekit.Node, _ *auth.ClaimGrants, _ bool) error {
+	return nil
+}
+
+func (a *edgeTestRoomAllocator) SelectParticipantNode(_ context.Context, _ livekit.RoomName, _ string) (livekit.NodeID, error) {
+	return "", nil
+}
+
+func (a *edgeTestRoomAllocator) SortRegionsByDistance(_ string, _ []config.RegionConfig) bool {
+	return false
+}
+
+// ---------------------------------------------------------
+
+// edgeTestSubscriber is attached to the receiver of a relayed track in place of a down track
+type edgeTestSubscriber struct {
+	packets chan uint16
+}
+
+func newEdgeTestSubscriber() *edgeTestSubscriber {
+	return &edgeTestSubscriber{packets: make(chan uint16, 10)}
+}
+
+func (s *edgeTestSubscriber) UpTrackLayersChange()                           {}
+func (s *edgeTestSubscriber) UpTrackBitrateAvailabilityChange()              {}
+func (s *edgeTestSubscriber) UpTrackMaxPublishedLayerChange(_ int32)         {}
+func (s *edgeTestSubscriber) UpTrackMaxTemporalLayerSeenChange(_ int32)      {}
+func (s *edgeTestSubscriber) UpTrackBitrateReport(_ []int32, _ sfu.Bitrates) {}
+func (s *edgeTestSubscriber) Resync()                                        {}
+func (s *edgeTestSubscriber) SetReceiver(_ sfu.TrackReceiver)                {}
+func (s *edgeTestSubscriber) ID() string                                     { return "DT_test" }
+func (s *edgeTestSubscriber) SubscriberID() livekit.ParticipantID            { return "PA_test" }
+func (s *edgeTestSubscriber) IsClosed() bool                                 { return false }
+func (s *edgeTestSubscriber) Close()                                         {}
+
+func (s *edgeTestSubscriber) WriteRTP(p *buffer.ExtPacket, _ int32) error {
+	s.packets <- p.Packet.SequenceNumber
+	return nil
+}
+
+func (s *edgeTestSubscriber) HandleRTCPSenderReportData(_ webrtc.PayloadType, _ bool, _ int32, _ *livekit.RTCPSenderReportState) error {
+	return nil
+}
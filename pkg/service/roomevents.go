@@ -0,0 +1,149 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc"
+)
+
+const (
+	roomEventsDefaultLimit = 100
+	roomEventsMaxLimit     = 1000
+
+	// events are batched before they are written to the store
+	roomEventJournalFlushInterval = time.Second
+)
+
+type GetRoomEventsRequest struct {
+	Room string `json:"room"`
+	// page_token of the previous response, empty to start from the oldest event kept
+	PageToken string `json:"page_token,omitempty"`
+	Limit     uint32 `json:"limit,omitempty"`
+}
+
+func (r *GetRoomEventsRequest) GetRoom() string {
+	return r.Room
+}
+
+type GetRoomEventsResponse struct {
+	Events []*rtc.RoomEvent `json:"events"`
+	// pass to the next request to continue, it remains valid for events appended later
+	NextPageToken string `json:"next_page_token"`
+}
+
+// GetRoomEvents pages through the event journal of a room, kept for room.event_journal.retention
+// after the last event
+func (s *RoomService) GetRoomEvents(ctx context.Context, req *GetRoomEventsRequest) (*GetRoomEventsResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "pageToken", req.PageToken)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	var offset int64
+	if req.PageToken != "" {
+		var err error
+		if offset, err = strconv.ParseInt(req.PageToken, 10, 64); err != nil || offset < 0 {
+			return nil, ErrInvalidPageToken
+		}
+	}
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = roomEventsDefaultLimit
+	}
+	limit = min(limit, roomEventsMaxLimit)
+
+	events, next, err := s.roomStore.ListRoomEvents(ctx, livekit.RoomName(req.Room), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []*rtc.RoomEvent{}
+	}
+	return &GetRoomEventsResponse{
+		Events:        events,
+		NextPageToken: strconv.FormatInt(next, 10),
+	}, nil
+}
+
+// ---------------------------------------------------------------
+
+// roomEventJournal batches the events of a room hosted on this node and appends them to the store in order
+type roomEventJournal struct {
+	store    RoomEventStore
+	roomName livekit.RoomName
+	conf     config.EventJournalConfig
+	logger   logger.Logger
+
+	flushLock sync.Mutex
+
+	lock    sync.Mutex
+	pending []*rtc.RoomEvent
+	timer   *time.Timer
+}
+
+func newRoomEventJournal(store RoomEventStore, roomName livekit.RoomName, conf config.EventJournalConfig, logger logger.Logger) *roomEventJournal {
+	return &roomEventJournal{
+		store:    store,
+		roomName: roomName,
+		conf:     conf,
+		logger:   logger,
+	}
+}
+
+func (j *roomEventJournal) Append(event *rtc.RoomEvent) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.pending = append(j.pending, event)
+	if j.timer == nil {
+		j.timer = time.AfterFunc(roomEventJournalFlushInterval, j.flush)
+	}
+}
+
+// Close writes pending events
+func (j *roomEventJournal) Close() {
+	j.flush()
+}
+
+func (j *roomEventJournal) flush() {
+	j.flushLock.Lock()
+	defer j.flushLock.Unlock()
+
+	j.lock.Lock()
+	events := j.pending
+	j.pending = nil
+	if j.timer != nil {
+		j.timer.Stop()
+		j.timer = nil
+	}
+	j.lock.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+	if err := j.store.AppendRoomEvents(context.Background(), j.roomName, events, j.conf.Retention, j.conf.MaxEvents); err != nil {
+		j.logger.Warnw("could not append room events", err, "numEvents", len(events))
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/service"
+)
+
+func TestRoomEventStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) service.RoomEventStore{
+		"local": func(t *testing.T) service.RoomEventStore {
+			return service.NewLocalStore()
+		},
+		"redis": func(t *testing.T) service.RoomEventStore {
+			return redisStoreDocker(t)
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+			roomName := livekit.RoomName(fmt.Sprintf("events-%d", time.Now().UnixNano()))
+
+			newEvents := func(from, to int) []*rtc.RoomEvent {
+				var events []*rtc.RoomEvent
+				for i := from; i < to; i++ {
+					events = append(events, &rtc.RoomEvent{
+						Type:                rtc.RoomEventParticipantJoined,
+						At:                  int64(i),
+						ParticipantIdentity: fmt.Sprintf("p%d", i),
+					})
+				}
+				return events
+			}
+
+			// nothing recorded
+			events, next, err := store.ListRoomEvents(ctx, roomName, 0, 10)
+			require.NoError(t, err)
+			require.Empty(t, events)
+			require.Equal(t, int64(0), next)
+
+			require.NoError(t, store.AppendRoomEvents(ctx, roomName, newEvents(0, 3), time.Minute, 5))
+			require.NoError(t, store.AppendRoomEvents(ctx, roomName, newEvents(3, 4), time.Minute, 5))
+
+			events, next, err = store.ListRoomEvents(ctx, roomName, 0, 3)
+			require.NoError(t, err)
+			require.Len(t, events, 3)
+			require.Equal(t, "p0", events[0].ParticipantIdentity)
+			require.Equal(t, rtc.RoomEventParticipantJoined, events[0].Type)
+			require.Equal(t, int64(3), next)
+
+			events, next, err = store.ListRoomEvents(ctx, roomName, next, 3)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			require.Equal(t, "p3", events[0].ParticipantIdentity)
+			require.Equal(t, int64(4), next)
+
+			// caught up, the token stays valid for events appended later
+			events, next, err = store.ListRoomEvents(ctx, roomName, next, 3)
+			require.NoError(t, err)
+			require.Empty(t, events)
+			require.Equal(t, int64(4), next)
+
+			// the oldest events are dropped beyond max events, paging skips them
+			require.NoError(t, store.AppendRoomEvents(ctx, roomName, newEvents(4, 8), time.Minute, 5))
+			events, next, err = store.ListRoomEvents(ctx, roomName, next, 10)
+			require.NoError(t, err)
+			require.Len(t, events, 4)
+			require.Equal(t, "p4", events[0].ParticipantIdentity)
+			require.Equal(t, int64(8), next)
+
+			events, next, err = store.ListRoomEvents(ctx, roomName, 0, 10)
+			require.NoError(t, err)
+			require.Len(t, events, 5)
+			require.Equal(t, "p3", events[0].ParticipantIdentity)
+			require.Equal(t, int64(8), next)
+		})
+	}
+}
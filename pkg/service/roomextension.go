@@ -0,0 +1,530 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+	"github.com/livekit/psrpc/pkg/client"
+	"github.com/livekit/psrpc/pkg/info"
+	"github.com/livekit/psrpc/pkg/rand"
+	"github.com/livekit/psrpc/pkg/server"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc"
+)
+
+// RoomService extensions that are not part of the protocol definitions.
+// They are exposed as JSON twirp methods and routed to the RTC node hosting the room
+// over psrpc, with JSON payloads wrapped in BytesValue.
+
+const (
+	roomExtensionServiceName = "RoomExtension"
+
+	roomExtensionMoveParticipant  = "MoveParticipant"
+	roomExtensionForwardTrack     = "ForwardTrack"
+	roomExtensionStopForwardTrack = "StopForwardTrack"
+
+	roomExtensionUpdateParticipantPolicy = "UpdateParticipantPolicy"
+	roomExtensionApplyRoomSchedule       = "ApplyRoomSchedule"
+
+	roomExtensionUpdateRoomLobby       = "UpdateRoomLobby"
+	roomExtensionListLobbyParticipants = "ListLobbyParticipants"
+	roomExtensionAdmitParticipant      = "AdmitParticipant"
+
+	roomExtensionStartPacketCapture = "StartPacketCapture"
+	roomExtensionStopPacketCapture  = "StopPacketCapture"
+	roomExtensionGetPacketCapture   = "GetPacketCapture"
+
+	// between nodes, relaying tracks into rooms hosted on other nodes
+	roomExtensionRelayTrack         = "RelayTrack"
+	roomExtensionUpdateRelayedTrack = "UpdateRelayedTrack"
+	roomExtensionUpdateRelayQuality = "UpdateRelayQuality"
+
+	// between origin and edge nodes of a room
+	roomExtensionJoinRoomEdge  = "JoinRoomEdge"
+	roomExtensionLeaveRoomEdge = "LeaveRoomEdge"
+)
+
+var roomExtensionMethods = []string{
+	roomExtensionMoveParticipant,
+	roomExtensionForwardTrack,
+	roomExtensionStopForwardTrack,
+	roomExtensionUpdateParticipantPolicy,
+	roomExtensionApplyRoomSchedule,
+	roomExtensionUpdateRoomLobby,
+	roomExtensionListLobbyParticipants,
+	roomExtensionAdmitParticipant,
+	roomExtensionStartPacketCapture,
+	roomExtensionStopPacketCapture,
+	roomExtensionGetPacketCapture,
+	roomExtensionRelayTrack,
+	roomExtensionUpdateRelayedTrack,
+	roomExtensionUpdateRelayQuality,
+	roomExtensionJoinRoomEdge,
+	roomExtensionLeaveRoomEdge,
+}
+
+type MoveParticipantRequest struct {
+	Room            string `json:"room"`
+	Identity        string `json:"identity"`
+	DestinationRoom string `json:"destination_room"`
+}
+
+func (r *MoveParticipantRequest) GetRoom() string {
+	return r.Room
+}
+
+func (r *MoveParticipantRequest) GetIdentity() string {
+	return r.Identity
+}
+
+type MoveParticipantResponse struct{}
+
+type ForwardTrackRequest struct {
+	Room            string `json:"room"`
+	Identity        string `json:"identity"`
+	TrackSid        string `json:"track_sid"`
+	DestinationRoom string `json:"destination_room"`
+}
+
+func (r *ForwardTrackRequest) GetRoom() string {
+	return r.Room
+}
+
+func (r *ForwardTrackRequest) GetIdentity() string {
+	return r.Identity
+}
+
+type ForwardTrackResponse struct {
+	// identity of the virtual participant publishing the track in the destination room
+	Identity string `json:"identity"`
+	// sid of the forwarded track in the destination room
+	TrackSid string `json:"track_sid"`
+}
+
+type StopForwardTrackRequest struct {
+	Room            string `json:"room"`
+	Identity        string `json:"identity"`
+	TrackSid        string `json:"track_sid"`
+	DestinationRoom string `json:"destination_room"`
+}
+
+func (r *StopForwardTrackRequest) GetRoom() string {
+	return r.Room
+}
+
+func (r *StopForwardTrackRequest) GetIdentity() string {
+	return r.Identity
+}
+
+type StopForwardTrackResponse struct{}
+
+// UpdateParticipantPolicyRequest replaces the participant session policy of a room,
+// zero disables a limit. Policy claims of participant tokens take precedence.
+type UpdateParticipantPolicyRequest struct {
+	Room                 string `json:"room"`
+	MaxDurationSeconds   uint32 `json:"max_duration_seconds"`
+	IdleTimeoutSeconds   uint32 `json:"idle_timeout_seconds"`
+	WarningBeforeSeconds uint32 `json:"warning_before_seconds"`
+}
+
+func (r *UpdateParticipantPolicyRequest) GetRoom() string {
+	return r.Room
+}
+
+type UpdateParticipantPolicyResponse struct {
+	MaxDurationSeconds   uint32 `json:"max_duration_seconds"`
+	IdleTimeoutSeconds   uint32 `json:"idle_timeout_seconds"`
+	WarningBeforeSeconds uint32 `json:"warning_before_seconds"`
+}
+
+// RoomParticipantPolicy is the participant session policy set on a room with UpdateParticipantPolicy,
+// it is stored with the room so that it survives a move of the room to another node
+type RoomParticipantPolicy struct {
+	Room                 string `json:"room"`
+	MaxDurationSeconds   uint32 `json:"max_duration_seconds"`
+	IdleTimeoutSeconds   uint32 `json:"idle_timeout_seconds"`
+	WarningBeforeSeconds uint32 `json:"warning_before_seconds"`
+}
+
+func (p *RoomParticipantPolicy) ParticipantPolicyConfig() config.ParticipantPolicyConfig {
+	return config.ParticipantPolicyConfig{
+		MaxDuration:   time.Duration(p.MaxDurationSeconds) * time.Second,
+		IdleTimeout:   time.Duration(p.IdleTimeoutSeconds) * time.Second,
+		WarningBefore: time.Duration(p.WarningBeforeSeconds) * time.Second,
+	}
+}
+
+// ApplyRoomScheduleRequest makes the node hosting the room reload its schedule from the store
+type ApplyRoomScheduleRequest struct {
+	Room string `json:"room"`
+}
+
+type ApplyRoomScheduleResponse struct{}
+
+// UpdateRoomLobbyRequest turns the lobby of a room on or off. When it is turned off,
+// everyone waiting is admitted.
+type UpdateRoomLobbyRequest struct {
+	Room    string `json:"room"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (r *UpdateRoomLobbyRequest) GetRoom() string {
+	return r.Room
+}
+
+type UpdateRoomLobbyResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type ListLobbyParticipantsRequest struct {
+	Room string `json:"room"`
+}
+
+func (r *ListLobbyParticipantsRequest) GetRoom() string {
+	return r.Room
+}
+
+type ListLobbyParticipantsResponse struct {
+	Participants []*rtc.LobbyParticipant `json:"participants"`
+}
+
+type AdmitParticipantRequest struct {
+	Room     string `json:"room"`
+	Identity string `json:"identity"`
+}
+
+func (r *AdmitParticipantRequest) GetRoom() string {
+	return r.Room
+}
+
+func (r *AdmitParticipantRequest) GetIdentity() string {
+	return r.Identity
+}
+
+type AdmitParticipantResponse struct{}
+
+// RelayTrackRequest is sent by the node hosting the source room to the node hosting the destination room,
+// which publishes the track with the ingress participant of the bridge and receives its media from SourceAddress
+// under SessionID
+type RelayTrackRequest struct {
+	Room             string                               `json:"room"`
+	Identity         string                               `json:"identity"`
+	Name             string                               `json:"name"`
+	SourceRoom       string                               `json:"source_room"`
+	SourceIdentity   string                               `json:"source_identity"`
+	SourceAddress    string                               `json:"source_address"`
+	SessionID        uint64                               `json:"session_id"`
+	TrackInfo        []byte                               `json:"track_info"`
+	Codecs           []webrtc.RTPCodecParameters          `json:"codecs"`
+	HeaderExtensions []webrtc.RTPHeaderExtensionParameter `json:"header_extensions"`
+	// set for tracks relayed between the origin and an edge node of the room, they are published
+	// by a participant mirroring the source participant instead of a bridge participant
+	Edge       bool              `json:"edge,omitempty"`
+	Kind       int32             `json:"kind,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	SourceNode string            `json:"source_node,omitempty"`
+	// topic the source room is served on, defaults to the room topic of SourceRoom
+	SourceTopic string `json:"source_topic,omitempty"`
+}
+
+type RelayTrackResponse struct {
+	TrackSid string `json:"track_sid"`
+	// relay transport of the destination node
+	Address string `json:"address"`
+}
+
+// UpdateRelayedTrackRequest passes changes of a relayed track on to the destination node,
+// a closed track is unpublished there
+type UpdateRelayedTrackRequest struct {
+	Room      string `json:"room"`
+	Identity  string `json:"identity"`
+	SessionID uint64 `json:"session_id"`
+	TrackInfo []byte `json:"track_info,omitempty"`
+	Closed    bool   `json:"closed,omitempty"`
+}
+
+type UpdateRelayedTrackResponse struct{}
+
+// UpdateRelayQualityRequest reports max qualities subscribed on the destination node back to the source room
+type UpdateRelayQualityRequest struct {
+	Room      string                   `json:"room"`
+	Identity  string                   `json:"identity"`
+	TrackSid  string                   `json:"track_sid"`
+	SessionID uint64                   `json:"session_id"`
+	Qualities []RelaySubscribedQuality `json:"qualities"`
+}
+
+type RelaySubscribedQuality struct {
+	Mime    string               `json:"mime"`
+	Quality livekit.VideoQuality `json:"quality"`
+}
+
+type UpdateRelayQualityResponse struct{}
+
+// JoinRoomEdge is sent by an edge node hosting participants of a room to its origin node, which starts relaying
+// the tracks of the room to the edge
+type JoinRoomEdgeRequest struct {
+	Room   string `json:"room"`
+	NodeID string `json:"node_id"`
+}
+
+type JoinRoomEdgeResponse struct{}
+
+type LeaveRoomEdgeRequest struct {
+	Room   string `json:"room"`
+	NodeID string `json:"node_id"`
+}
+
+type LeaveRoomEdgeResponse struct{}
+
+// RoomExtensionServerImpl is implemented by the RTC node
+type RoomExtensionServerImpl interface {
+	MoveParticipant(ctx context.Context, req *MoveParticipantRequest) (*MoveParticipantResponse, error)
+	ForwardTrack(ctx context.Context, req *ForwardTrackRequest) (*ForwardTrackResponse, error)
+	StopForwardTrack(ctx context.Context, req *StopForwardTrackRequest) (*StopForwardTrackResponse, error)
+	UpdateParticipantPolicy(ctx context.Context, req *UpdateParticipantPolicyRequest) (*UpdateParticipantPolicyResponse, error)
+	ApplyRoomSchedule(ctx context.Context, req *ApplyRoomScheduleRequest) (*ApplyRoomScheduleResponse, error)
+	UpdateRoomLobby(ctx context.Context, req *UpdateRoomLobbyRequest) (*UpdateRoomLobbyResponse, error)
+	ListLobbyParticipants(ctx context.Context, req *ListLobbyParticipantsRequest) (*ListLobbyParticipantsResponse, error)
+	AdmitParticipant(ctx context.Context, req *AdmitParticipantRequest) (*AdmitParticipantResponse, error)
+	StartPacketCapture(ctx context.Context, req *StartPacketCaptureRequest) (*PacketCaptureResponse, error)
+	StopPacketCapture(ctx context.Context, req *StopPacketCaptureRequest) (*PacketCaptureResponse, error)
+	GetPacketCapture(ctx context.Context, req *GetPacketCaptureRequest) (*GetPacketCaptureResponse, error)
+	RelayTrack(ctx context.Context, req *RelayTrackRequest) (*RelayTrackResponse, error)
+	UpdateRelayedTrack(ctx context.Context, req *UpdateRelayedTrackRequest) (*UpdateRelayedTrackResponse, error)
+	UpdateRelayQuality(ctx context.Context, req *UpdateRelayQualityRequest) (*UpdateRelayQualityResponse, error)
+	JoinRoomEdge(ctx context.Context, req *JoinRoomEdgeRequest) (*JoinRoomEdgeResponse, error)
+	LeaveRoomEdge(ctx context.Context, req *LeaveRoomEdgeRequest) (*LeaveRoomEdgeResponse, error)
+}
+
+// ---------------------------------------------------------------
+
+type RoomExtensionClient struct {
+	client *client.RPCClient
+}
+
+func NewRoomExtensionClient(params rpc.ClientParams) (*RoomExtensionClient, error) {
+	sd := &info.ServiceDefinition{
+		Name: roomExtensionServiceName,
+		ID:   rand.NewClientID(),
+	}
+	for _, method := range roomExtensionMethods {
+		sd.RegisterMethod(method, false, false, true, true)
+	}
+
+	rpcClient, err := client.NewRPCClient(sd, params.Bus, params.Options()...)
+	if err != nil {
+		return nil, err
+	}
+	return &RoomExtensionClient{client: rpcClient}, nil
+}
+
+func (c *RoomExtensionClient) MoveParticipant(ctx context.Context, room rpc.RoomTopic, req *MoveParticipantRequest, opts ...psrpc.RequestOption) (*MoveParticipantResponse, error) {
+	return requestRoomExtension[MoveParticipantRequest, MoveParticipantResponse](ctx, c, roomExtensionMoveParticipant, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) ForwardTrack(ctx context.Context, room rpc.RoomTopic, req *ForwardTrackRequest, opts ...psrpc.RequestOption) (*ForwardTrackResponse, error) {
+	return requestRoomExtension[ForwardTrackRequest, ForwardTrackResponse](ctx, c, roomExtensionForwardTrack, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) StopForwardTrack(ctx context.Context, room rpc.RoomTopic, req *StopForwardTrackRequest, opts ...psrpc.RequestOption) (*StopForwardTrackResponse, error) {
+	return requestRoomExtension[StopForwardTrackRequest, StopForwardTrackResponse](ctx, c, roomExtensionStopForwardTrack, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) UpdateParticipantPolicy(ctx context.Context, room rpc.RoomTopic, req *UpdateParticipantPolicyRequest, opts ...psrpc.RequestOption) (*UpdateParticipantPolicyResponse, error) {
+	return requestRoomExtension[UpdateParticipantPolicyRequest, UpdateParticipantPolicyResponse](ctx, c, roomExtensionUpdateParticipantPolicy, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) ApplyRoomSchedule(ctx context.Context, room rpc.RoomTopic, req *ApplyRoomScheduleRequest, opts ...psrpc.RequestOption) (*ApplyRoomScheduleResponse, error) {
+	return requestRoomExtension[ApplyRoomScheduleRequest, ApplyRoomScheduleResponse](ctx, c, roomExtensionApplyRoomSchedule, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) UpdateRoomLobby(ctx context.Context, room rpc.RoomTopic, req *UpdateRoomLobbyRequest, opts ...psrpc.RequestOption) (*UpdateRoomLobbyResponse, error) {
+	return requestRoomExtension[UpdateRoomLobbyRequest, UpdateRoomLobbyResponse](ctx, c, roomExtensionUpdateRoomLobby, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) ListLobbyParticipants(ctx context.Context, room rpc.RoomTopic, req *ListLobbyParticipantsRequest, opts ...psrpc.RequestOption) (*ListLobbyParticipantsResponse, error) {
+	return requestRoomExtension[ListLobbyParticipantsRequest, ListLobbyParticipantsResponse](ctx, c, roomExtensionListLobbyParticipants, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) AdmitParticipant(ctx context.Context, room rpc.RoomTopic, req *AdmitParticipantRequest, opts ...psrpc.RequestOption) (*AdmitParticipantResponse, error) {
+	return requestRoomExtension[AdmitParticipantRequest, AdmitParticipantResponse](ctx, c, roomExtensionAdmitParticipant, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) StartPacketCapture(ctx context.Context, room rpc.RoomTopic, req *StartPacketCaptureRequest, opts ...psrpc.RequestOption) (*PacketCaptureResponse, error) {
+	return requestRoomExtension[StartPacketCaptureRequest, PacketCaptureResponse](ctx, c, roomExtensionStartPacketCapture, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) StopPacketCapture(ctx context.Context, room rpc.RoomTopic, req *StopPacketCaptureRequest, opts ...psrpc.RequestOption) (*PacketCaptureResponse, error) {
+	return requestRoomExtension[StopPacketCaptureRequest, PacketCaptureResponse](ctx, c, roomExtensionStopPacketCapture, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) GetPacketCapture(ctx context.Context, room rpc.RoomTopic, req *GetPacketCaptureRequest, opts ...psrpc.RequestOption) (*GetPacketCaptureResponse, error) {
+	return requestRoomExtension[GetPacketCaptureRequest, GetPacketCaptureResponse](ctx, c, roomExtensionGetPacketCapture, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) RelayTrack(ctx context.Context, room rpc.RoomTopic, req *RelayTrackRequest, opts ...psrpc.RequestOption) (*RelayTrackResponse, error) {
+	return requestRoomExtension[RelayTrackRequest, RelayTrackResponse](ctx, c, roomExtensionRelayTrack, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) UpdateRelayedTrack(ctx context.Context, room rpc.RoomTopic, req *UpdateRelayedTrackRequest, opts ...psrpc.RequestOption) (*UpdateRelayedTrackResponse, error) {
+	return requestRoomExtension[UpdateRelayedTrackRequest, UpdateRelayedTrackResponse](ctx, c, roomExtensionUpdateRelayedTrack, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) UpdateRelayQuality(ctx context.Context, room rpc.RoomTopic, req *UpdateRelayQualityRequest, opts ...psrpc.RequestOption) (*UpdateRelayQualityResponse, error) {
+	return requestRoomExtension[UpdateRelayQualityRequest, UpdateRelayQualityResponse](ctx, c, roomExtensionUpdateRelayQuality, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) JoinRoomEdge(ctx context.Context, room rpc.RoomTopic, req *JoinRoomEdgeRequest, opts ...psrpc.RequestOption) (*JoinRoomEdgeResponse, error) {
+	return requestRoomExtension[JoinRoomEdgeRequest, JoinRoomEdgeResponse](ctx, c, roomExtensionJoinRoomEdge, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) LeaveRoomEdge(ctx context.Context, room rpc.RoomTopic, req *LeaveRoomEdgeRequest, opts ...psrpc.RequestOption) (*LeaveRoomEdgeResponse, error) {
+	return requestRoomExtension[LeaveRoomEdgeRequest, LeaveRoomEdgeResponse](ctx, c, roomExtensionLeaveRoomEdge, room, req, opts...)
+}
+
+func (c *RoomExtensionClient) Close() {
+	c.client.Close()
+}
+
+func requestRoomExtension[Req, Res any](
+	ctx context.Context,
+	c *RoomExtensionClient,
+	method string,
+	room rpc.RoomTopic,
+	req *Req,
+	opts ...psrpc.RequestOption,
+) (*Res, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return nil, psrpc.NewError(psrpc.MalformedRequest, err)
+	}
+	res, err := client.RequestSingle[*wrapperspb.BytesValue](ctx, c.client, method, []string{string(room)}, wrapperspb.Bytes(b), opts...)
+	if err != nil {
+		return nil, err
+	}
+	var out Res
+	if err := json.Unmarshal(res.GetValue(), &out); err != nil {
+		return nil, psrpc.NewError(psrpc.MalformedResponse, err)
+	}
+	return &out, nil
+}
+
+// ---------------------------------------------------------------
+
+type RoomExtensionServer struct {
+	svc RoomExtensionServerImpl
+	rpc *server.RPCServer
+}
+
+func NewRoomExtensionServer(svc RoomExtensionServerImpl, bus psrpc.MessageBus, opts ...psrpc.ServerOption) *RoomExtensionServer {
+	sd := &info.ServiceDefinition{
+		Name: roomExtensionServiceName,
+		ID:   rand.NewServerID(),
+	}
+	s := server.NewRPCServer(sd, bus, opts...)
+	for _, method := range roomExtensionMethods {
+		sd.RegisterMethod(method, false, false, true, true)
+	}
+	return &RoomExtensionServer{
+		svc: svc,
+		rpc: s,
+	}
+}
+
+func (s *RoomExtensionServer) RegisterAllRoomTopics(room rpc.RoomTopic) error {
+	topic := []string{string(room)}
+	if err := server.RegisterHandler(s.rpc, roomExtensionMoveParticipant, topic, roomExtensionHandler(s.svc.MoveParticipant), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionForwardTrack, topic, roomExtensionHandler(s.svc.ForwardTrack), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionStopForwardTrack, topic, roomExtensionHandler(s.svc.StopForwardTrack), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionUpdateParticipantPolicy, topic, roomExtensionHandler(s.svc.UpdateParticipantPolicy), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionApplyRoomSchedule, topic, roomExtensionHandler(s.svc.ApplyRoomSchedule), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionUpdateRoomLobby, topic, roomExtensionHandler(s.svc.UpdateRoomLobby), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionListLobbyParticipants, topic, roomExtensionHandler(s.svc.ListLobbyParticipants), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionAdmitParticipant, topic, roomExtensionHandler(s.svc.AdmitParticipant), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionStartPacketCapture, topic, roomExtensionHandler(s.svc.StartPacketCapture), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionStopPacketCapture, topic, roomExtensionHandler(s.svc.StopPacketCapture), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionGetPacketCapture, topic, roomExtensionHandler(s.svc.GetPacketCapture), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionRelayTrack, topic, roomExtensionHandler(s.svc.RelayTrack), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionUpdateRelayedTrack, topic, roomExtensionHandler(s.svc.UpdateRelayedTrack), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionUpdateRelayQuality, topic, roomExtensionHandler(s.svc.UpdateRelayQuality), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionJoinRoomEdge, topic, roomExtensionHandler(s.svc.JoinRoomEdge), nil); err != nil {
+		return err
+	}
+	if err := server.RegisterHandler(s.rpc, roomExtensionLeaveRoomEdge, topic, roomExtensionHandler(s.svc.LeaveRoomEdge), nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *RoomExtensionServer) Kill() {
+	s.rpc.Close(true)
+}
+
+func roomExtensionHandler[Req, Res any](fn func(context.Context, *Req) (*Res, error)) func(context.Context, *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error) {
+	return func(ctx context.Context, in *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error) {
+		var req Req
+		if err := json.Unmarshal(in.GetValue(), &req); err != nil {
+			return nil, psrpc.NewError(psrpc.MalformedRequest, err)
+		}
+		res, err := fn(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(res)
+		if err != nil {
+			return nil, psrpc.NewError(psrpc.Internal, err)
+		}
+		return wrapperspb.Bytes(b), nil
+	}
+}
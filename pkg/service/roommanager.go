@@ -22,10 +22,12 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"go.uber.org/atomic"
 	"golang.org/x/exp/maps"
 
 	"github.com/livekit/livekit-server/pkg/agent"
 	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/relay"
 	sutils "github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
 	"github.com/livekit/protocol/auth"
@@ -60,6 +62,11 @@ type iceConfigCacheKey struct {
 	participantIdentity livekit.ParticipantIdentity
 }
 
+type roomBridgeKey struct {
+	sourceRoom livekit.RoomName
+	identity   livekit.ParticipantIdentity
+}
+
 // RoomManager manages rooms and its interaction with participants.
 // It's responsible for creating, deleting rooms, as well as running sessions for participants
 type RoomManager struct {
@@ -81,11 +88,28 @@ type RoomManager struct {
 	versionGenerator  utils.TimedVersionGenerator
 	turnAuthHandler   *TURNAuthHandler
 	bus               psrpc.MessageBus
+	extensionClient   *RoomExtensionClient
+	relayTransport    *relay.Transport
+
+	rooms        map[livekit.RoomName]*rtc.Room
+	snapshotters map[livekit.RoomName]*roomSnapshotter
+
+	bridgesLock sync.Mutex
+	bridges     map[roomBridgeKey]*rtc.RoomBridge
 
-	rooms map[livekit.RoomName]*rtc.Room
+	relaysLock    sync.Mutex
+	relays        map[roomBridgeKey]*rtc.RoomRelay
+	relayedTracks map[uint64]*relayedTrack // session ID -> track
+	edgeLinks     map[roomEdgeKey]*roomEdgeLink
+
+	packetCaptures *packetCaptures
+
+	sessionsLock     sync.Mutex
+	sessionRoomMoved map[routing.MessageSource]func(room *rtc.Room)
 
 	roomServers          utils.MultitonService[rpc.RoomTopic]
 	agentDispatchServers utils.MultitonService[rpc.RoomTopic]
+	roomExtensionServers utils.MultitonService[rpc.RoomTopic]
 	participantServers   utils.MultitonService[rpc.ParticipantTopic]
 
 	iceConfigCache *sutils.IceConfigCache[iceConfigCacheKey]
@@ -108,6 +132,7 @@ func NewLocalRoomManager(
 	turnAuthHandler *TURNAuthHandler,
 	bus psrpc.MessageBus,
 	forwardStats *sfu.ForwardStats,
+	extensionClient *RoomExtensionClient,
 ) (*RoomManager, error) {
 	rtcConf, err := rtc.NewWebRTCConfig(conf)
 	if err != nil {
@@ -130,8 +155,18 @@ func NewLocalRoomManager(
 		turnAuthHandler:   turnAuthHandler,
 		bus:               bus,
 		forwardStats:      forwardStats,
+		extensionClient:   extensionClient,
+
+		rooms:         make(map[livekit.RoomName]*rtc.Room),
+		snapshotters:  make(map[livekit.RoomName]*roomSnapshotter),
+		bridges:       make(map[roomBridgeKey]*rtc.RoomBridge),
+		relays:        make(map[roomBridgeKey]*rtc.RoomRelay),
+		relayedTracks: make(map[uint64]*relayedTrack),
+		edgeLinks:     make(map[roomEdgeKey]*roomEdgeLink),
+
+		packetCaptures: newPacketCaptures(conf.RTC.PacketCapture),
 
-		rooms: make(map[livekit.RoomName]*rtc.Room),
+		sessionRoomMoved: make(map[routing.MessageSource]func(room *rtc.Room)),
 
 		iceConfigCache: sutils.NewIceConfigCache[iceConfigCacheKey](0),
 
@@ -145,6 +180,13 @@ func NewLocalRoomManager(
 		},
 	}
 
+	if conf.RTC.Relay.Enabled {
+		r.relayTransport, err = relay.NewTransport(conf.RTC.Relay.BindAddress, conf.RTC.Relay.Port, relayKey(conf), logger.GetLogger().WithComponent(sutils.ComponentRelay))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	r.roomManagerServer, err = rpc.NewTypedRoomManagerServer(r, bus, rpc.WithServerLogger(logger.GetLogger()), middleware.WithServerMetrics(rpc.PSRPCMetricsObserver{}), psrpc.WithServerChannelSize(conf.PSRPC.BufferSize))
 	if err != nil {
 		return nil, err
@@ -223,9 +265,16 @@ func (r *RoomManager) Stop() {
 		room.Close(types.ParticipantCloseReasonRoomManagerStop)
 	}
 
+	r.packetCaptures.stopAll()
+
+	if r.relayTransport != nil {
+		r.relayTransport.Close()
+	}
+
 	r.roomManagerServer.Kill()
 	r.roomServers.Kill()
 	r.agentDispatchServers.Kill()
+	r.roomExtensionServers.Kill()
 	r.participantServers.Kill()
 
 	if r.rtcConfig != nil {
@@ -265,7 +314,13 @@ func (r *RoomManager) StartSession(
 	sessionStartTime := time.Now()
 
 	createRoom := pi.CreateRoom
-	room, err := r.getOrCreateRoom(ctx, createRoom)
+	var room *rtc.Room
+	var err error
+	if originNodeID := r.edgeOriginNode(ctx, livekit.RoomName(createRoom.Name)); originNodeID != "" {
+		room, err = r.getOrCreateEdgeRoom(ctx, createRoom, originNodeID)
+	} else {
+		room, err = r.getOrCreateRoom(ctx, createRoom)
+	}
 	if err != nil {
 		return err
 	}
@@ -350,7 +405,14 @@ func (r *RoomManager) StartSession(
 		// we need to clean up the existing participant, so a new one can join
 		participant.GetLogger().Infow("removing duplicate participant")
 		room.RemoveParticipant(participant.Identity(), participant.ID(), types.ParticipantCloseReasonDuplicateIdentity)
-	} else if pi.Reconnect {
+	}
+
+	// a participant hosted by a node that failed is resumed by the signal node, it is re-created here
+	// with its identity and state kept
+	recovered := false
+	if participant == nil && pi.Reconnect && r.isParticipantRecoverable(ctx, room.Name(), pi) {
+		recovered = true
+	} else if participant == nil && pi.Reconnect {
 		// send leave request if participant is trying to reconnect without keep subscribe state
 		// but missing from the room
 		var leave *livekit.LeaveRequest
@@ -375,6 +437,9 @@ func (r *RoomManager) StartSession(
 	}
 
 	sid := livekit.ParticipantID(guid.New(utils.ParticipantPrefix))
+	if recovered {
+		sid = pi.ID
+	}
 	pLogger := rtc.LoggerWithParticipant(
 		rtc.LoggerWithRoom(logger.GetLogger(), room.Name(), room.ID()),
 		pi.Identity,
@@ -386,6 +451,7 @@ func (r *RoomManager) StartSession(
 		"nodeID", r.currentNode.NodeID(),
 		"numParticipants", room.GetParticipantCount(),
 		"participantInit", &pi,
+		"recovered", recovered,
 	)
 
 	clientConf := r.clientConfManager.GetConfiguration(pi.Client)
@@ -420,6 +486,8 @@ func (r *RoomManager) StartSession(
 	if pi.SubscriberAllowPause != nil {
 		subscriberAllowPause = *pi.SubscriberAllowPause
 	}
+	// participants of a tenant are held to the limits of the tenant
+	limitConf := roomLimitConfig(r.config, room.Name())
 	participant, err = rtc.NewParticipant(rtc.ParticipantParams{
 		Identity:                pi.Identity,
 		Name:                    pi.Name,
@@ -428,7 +496,7 @@ func (r *RoomManager) StartSession(
 		Sink:                    responseSink,
 		AudioConfig:             r.config.Audio,
 		VideoConfig:             r.config.Video,
-		LimitConfig:             r.config.Limit,
+		LimitConfig:             limitConf,
 		ProtocolVersion:         pv,
 		SessionStartTime:        sessionStartTime,
 		Telemetry:               r.telemetry,
@@ -447,19 +515,20 @@ func (r *RoomManager) StartSession(
 		AllowTCPFallback:        allowFallback,
 		TURNSEnabled:            r.config.IsTURNSEnabled(),
 		GetParticipantInfo: func(pID livekit.ParticipantID) *livekit.ParticipantInfo {
-			if p := room.GetParticipantByID(pID); p != nil {
-				return p.ToProto()
-			}
-			return nil
+			return room.GetParticipantInfo(pID)
 		},
+		GetRegionSettings: func(ip string) *livekit.RegionSettings {
+			return r.regionSettings(context.Background(), room.Name(), ip)
+		},
+		RecordRoomEvent:              room.RecordEvent,
 		ReconnectOnPublicationError:  reconnectOnPublicationError,
 		ReconnectOnSubscriptionError: reconnectOnSubscriptionError,
 		ReconnectOnDataChannelError:  reconnectOnDataChannelError,
 		VersionGenerator:             r.versionGenerator,
 		TrackResolver:                room.ResolveMediaTrackForSubscriber,
 		SubscriberAllowPause:         subscriberAllowPause,
-		SubscriptionLimitAudio:       r.config.Limit.SubscriptionLimitAudio,
-		SubscriptionLimitVideo:       r.config.Limit.SubscriptionLimitVideo,
+		SubscriptionLimitAudio:       limitConf.SubscriptionLimitAudio,
+		SubscriptionLimitVideo:       limitConf.SubscriptionLimitVideo,
 		PlayoutDelay:                 roomInternal.GetPlayoutDelay(),
 		SyncStreams:                  roomInternal.GetSyncStreams(),
 		ForwardStats:                 r.forwardStats,
@@ -477,14 +546,52 @@ func (r *RoomManager) StartSession(
 	// join room
 	opts := rtc.ParticipantOptions{
 		AutoSubscribe: pi.AutoSubscribe,
+		PolicyClaim:   pi.PolicyClaim,
 	}
 	iceServers := r.iceServersForParticipant(apiKey, participant, iceConfig.PreferenceSubscriber == livekit.ICECandidateType_ICT_TLS)
-	if err = room.Join(participant, requestSource, &opts, iceServers); err != nil {
+	if recovered {
+		err = room.RecoverParticipant(participant, requestSource, &opts, iceServers, pi.ReconnectReason)
+	} else {
+		err = room.Join(participant, requestSource, &opts, iceServers)
+	}
+	if err != nil {
 		pLogger.Errorw("could not join room", err)
 		_ = participant.Close(true, types.ParticipantCloseReasonJoinFailed, false)
 		return err
 	}
+	room.RestoreSubscriptionPermission(participant)
+
+	if err = r.startParticipantSession(ctx, room, protoRoom, participant, pi.Client, pLogger); err != nil {
+		return err
+	}
 
+	go r.rtcSessionWorker(room, participant, requestSource)
+	return nil
+}
+
+// isParticipantRecoverable returns true when the participant is still registered in the room by the node
+// that hosted it, as it failed without cleaning up
+func (r *RoomManager) isParticipantRecoverable(ctx context.Context, roomName livekit.RoomName, pi routing.ParticipantInit) bool {
+	if pi.ID == "" {
+		return false
+	}
+	p, err := r.roomStore.LoadParticipant(ctx, roomName, pi.Identity)
+	if err != nil {
+		return false
+	}
+	return livekit.ParticipantID(p.Sid) == pi.ID
+}
+
+// startParticipantSession registers a participant that has joined the room with the message bus and the room store,
+// and sets up callbacks to clean up when it leaves the room
+func (r *RoomManager) startParticipantSession(
+	ctx context.Context,
+	room *rtc.Room,
+	protoRoom *livekit.Room,
+	participant types.LocalParticipant,
+	clientInfo *livekit.ClientInfo,
+	pLogger logger.Logger,
+) error {
 	participantTopic := rpc.FormatParticipantTopic(room.Name(), participant.Identity())
 	participantServer := must.Get(rpc.NewTypedParticipantServer(r, r.bus))
 	killParticipantServer := r.participantServers.Replace(participantTopic, participantServer)
@@ -495,14 +602,13 @@ func (r *RoomManager) StartSession(
 		return err
 	}
 
-	if err = r.roomStore.StoreParticipant(ctx, room.Name(), participant.ToProto()); err != nil {
+	if err := r.roomStore.StoreParticipant(ctx, room.Name(), participant.ToProto()); err != nil {
 		pLogger.Errorw("could not store participant", err)
 	}
 
 	persistRoomForParticipantCount := func(proto *livekit.Room) {
 		if !participant.Hidden() && !room.IsClosed() {
-			err = r.roomStore.StoreRoom(ctx, proto, room.Internal())
-			if err != nil {
+			if err := r.roomStore.StoreRoom(ctx, proto, room.Internal()); err != nil {
 				logger.Errorw("could not store room", err)
 			}
 		}
@@ -512,7 +618,7 @@ func (r *RoomManager) StartSession(
 	persistRoomForParticipantCount(room.ToProto())
 
 	clientMeta := &livekit.AnalyticsClientMeta{Region: r.currentNode.Region(), Node: string(r.currentNode.NodeID())}
-	r.telemetry.ParticipantJoined(ctx, protoRoom, participant.ToProto(), pi.Client, clientMeta, true)
+	r.telemetry.ParticipantJoined(ctx, protoRoom, participant.ToProto(), clientInfo, clientMeta, true)
 	participant.OnClose(func(p types.LocalParticipant) {
 		killParticipantServer()
 
@@ -527,15 +633,13 @@ func (r *RoomManager) StartSession(
 	})
 	participant.OnClaimsChanged(func(participant types.LocalParticipant) {
 		pLogger.Debugw("refreshing client token after claims change")
-		if err := r.refreshToken(participant); err != nil {
+		if err := r.refreshToken(room, participant); err != nil {
 			pLogger.Errorw("could not refresh token", err)
 		}
 	})
 	participant.OnICEConfigChanged(func(participant types.LocalParticipant, iceConfig *livekit.ICEConfig) {
 		r.iceConfigCache.Put(iceConfigCacheKey{room.Name(), participant.Identity()}, iceConfig)
 	})
-
-	go r.rtcSessionWorker(room, participant, requestSource)
 	return nil
 }
 
@@ -557,6 +661,11 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, createRoom *livekit.C
 		return nil, err
 	}
 
+	var snapshot *rtc.RoomSnapshot
+	if r.config.Room.Snapshot.Enabled {
+		ri, internal, snapshot = r.restoreRoomSnapshot(ctx, ri, internal, created)
+	}
+
 	r.lock.Lock()
 
 	currentRoom := r.rooms[roomName]
@@ -591,13 +700,73 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, createRoom *livekit.C
 		return nil, err
 	}
 
+	roomExtensionServer := NewRoomExtensionServer(r, r.bus)
+	killRoomExtensionServer := r.roomExtensionServers.Replace(roomTopic, roomExtensionServer)
+	if err := roomExtensionServer.RegisterAllRoomTopics(roomTopic); err != nil {
+		killRoomServer()
+		killDispServer()
+		killRoomExtensionServer()
+		r.lock.Unlock()
+		return nil, err
+	}
+
+	var snapshotter *roomSnapshotter
+	if conf := r.config.Room.Snapshot; conf.Enabled {
+		if snapshot != nil {
+			newRoom.RestoreSnapshot(snapshot)
+		}
+		snapshotter = newRoomSnapshotter(r.roomStore, newRoom, conf)
+	}
+
+	var journal *roomEventJournal
+	if conf := r.config.Room.EventJournal; conf.Enabled {
+		journal = newRoomEventJournal(r.roomStore, roomName, conf, newRoom.Logger)
+		newRoom.OnEvent(journal.Append)
+		newRoom.RecordEvent(&rtc.RoomEvent{
+			Type:    rtc.RoomEventRoomStarted,
+			Details: map[string]string{"node_id": string(r.currentNode.NodeID())},
+		})
+	}
+
 	newRoom.OnClose(func() {
 		killRoomServer()
 		killDispServer()
+		killRoomExtensionServer()
+		r.closeRoomEdgeLinks(roomName)
+		r.packetCaptures.closeRoom(roomName)
+		// a room drained off this node lives on another node, its state is kept and it has not ended.
+		// the final snapshot was stored when the room was drained.
+		moved := r.isRoomMoved(ctx, roomName)
+		if journal != nil {
+			if !moved {
+				newRoom.RecordEvent(&rtc.RoomEvent{Type: rtc.RoomEventRoomEnded})
+			}
+			journal.Close()
+		}
+		if snapshotter != nil {
+			if moved {
+				snapshotter.Stop()
+			} else {
+				snapshotter.Close()
+			}
+			r.lock.Lock()
+			if r.snapshotters[roomName] == snapshotter {
+				delete(r.snapshotters, roomName)
+			}
+			r.lock.Unlock()
+		}
+		if moved {
+			r.lock.Lock()
+			delete(r.rooms, roomName)
+			r.lock.Unlock()
+			newRoom.Logger.Infow("room closed after moving to another node")
+			return
+		}
 
 		roomInfo := newRoom.ToProto()
-		r.telemetry.RoomEnded(ctx, roomInfo)
-		prometheus.RoomEnded(time.Unix(roomInfo.CreationTime, 0))
+		report := r.storeQualityReport(ctx, roomInfo)
+		r.telemetry.RoomEnded(ctx, roomInfo, report)
+		prometheus.RoomEnded(roomName, time.Unix(roomInfo.CreationTime, 0))
 		if err := r.deleteRoom(ctx, roomName); err != nil {
 			newRoom.Logger.Errorw("could not delete room", err)
 		}
@@ -605,6 +774,10 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, createRoom *livekit.C
 		newRoom.Logger.Infow("room closed")
 	})
 
+	newRoom.OnTrackChanged(func(p types.Participant, track types.MediaTrack) {
+		r.relayToRoomEdges(roomName, p, track)
+	})
+
 	newRoom.OnRoomUpdated(func() {
 		if err := r.roomStore.StoreRoom(ctx, newRoom.ToProto(), newRoom.Internal()); err != nil {
 			newRoom.Logger.Errorw("could not handle metadata update", err)
@@ -620,11 +793,21 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, createRoom *livekit.C
 	})
 
 	r.rooms[roomName] = newRoom
+	if snapshotter != nil {
+		r.snapshotters[roomName] = snapshotter
+	}
 
 	r.lock.Unlock()
 
 	newRoom.Hold()
 
+	if err := r.applyRoomSchedule(ctx, newRoom); err != nil {
+		newRoom.Logger.Errorw("could not apply room schedule", err)
+	}
+	if err := r.applyRoomParticipantPolicy(ctx, newRoom); err != nil {
+		newRoom.Logger.Errorw("could not apply participant policy", err)
+	}
+
 	r.telemetry.RoomStarted(ctx, newRoom.ToProto())
 	prometheus.RoomStarted()
 
@@ -665,8 +848,20 @@ func (r *RoomManager) rtcSessionWorker(room *rtc.Room, participant types.LocalPa
 		}
 	}()
 
+	// participant could be moved to another room on this node
+	var currentRoom atomic.Pointer[rtc.Room]
+	currentRoom.Store(room)
+	r.sessionsLock.Lock()
+	r.sessionRoomMoved[requestSource] = currentRoom.Store
+	r.sessionsLock.Unlock()
+	defer func() {
+		r.sessionsLock.Lock()
+		delete(r.sessionRoomMoved, requestSource)
+		r.sessionsLock.Unlock()
+	}()
+
 	// send first refresh for cases when client token is close to expiring
-	_ = r.refreshToken(participant)
+	_ = r.refreshToken(room, participant)
 	tokenTicker := time.NewTicker(tokenRefreshInterval)
 	defer tokenTicker.Stop()
 	for {
@@ -675,10 +870,11 @@ func (r *RoomManager) rtcSessionWorker(room *rtc.Room, participant types.LocalPa
 			return
 		case <-tokenTicker.C:
 			// refresh token with the first API Key/secret pair
-			if err := r.refreshToken(participant); err != nil {
+			if err := r.refreshToken(currentRoom.Load(), participant); err != nil {
 				pLogger.Errorw("could not refresh token", err, "connID", requestSource.ConnectionID())
 			}
 		case obj := <-requestSource.ReadChan():
+			room := currentRoom.Load()
 			if obj == nil {
 				if room.GetParticipantRequestSource(participant.Identity()) == requestSource {
 					participant.HandleSignalSourceClose()
@@ -696,6 +892,23 @@ func (r *RoomManager) rtcSessionWorker(room *rtc.Room, participant types.LocalPa
 	}
 }
 
+// moveParticipantSession rebinds a participant to a room on this node, its RTC session worker
+// handles signal messages for that room from then on
+func (r *RoomManager) moveParticipantSession(participant types.LocalParticipant, requestSource routing.MessageSource, room *rtc.Room) {
+	participant.MoveToRoom(types.MoveToRoomParams{
+		RoomName:           room.Name(),
+		TrackResolver:      room.ResolveMediaTrackForSubscriber,
+		GetParticipantInfo: room.GetParticipantInfo,
+	})
+
+	r.sessionsLock.Lock()
+	onRoomMoved := r.sessionRoomMoved[requestSource]
+	r.sessionsLock.Unlock()
+	if onRoomMoved != nil {
+		onRoomMoved(room)
+	}
+}
+
 type participantReq interface {
 	GetRoom() string
 	GetIdentity() string
@@ -726,6 +939,324 @@ func (r *RoomManager) RemoveParticipant(ctx context.Context, req *livekit.RoomPa
 	return &livekit.RemoveParticipantResponse{}, nil
 }
 
+func (r *RoomManager) MoveParticipant(ctx context.Context, req *MoveParticipantRequest) (*MoveParticipantResponse, error) {
+	room, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// moving keeps the transport, so the destination room has to be hosted on this node
+	destRoomName := livekit.RoomName(req.DestinationRoom)
+	if err = r.claimRoomOnThisNode(ctx, destRoomName); err != nil {
+		return nil, err
+	}
+
+	destRoom, err := r.getOrCreateRoom(ctx, &livekit.CreateRoomRequest{Name: req.DestinationRoom})
+	if err != nil {
+		return nil, err
+	}
+	defer destRoom.Release()
+
+	// refuse the move while the participant is still in the source room
+	if err = destRoom.CanMoveInParticipant(participant); err != nil {
+		return nil, err
+	}
+
+	pLogger := participant.GetLogger()
+	pLogger.Infow("moving participant", "destinationRoom", destRoomName)
+
+	_, requestSource, opts, err := room.MoveOutParticipant(participant.Identity())
+	if err != nil {
+		return nil, err
+	}
+
+	r.moveParticipantSession(participant, requestSource, destRoom)
+	if err = destRoom.MoveInParticipant(participant, requestSource, opts); err != nil {
+		pLogger.Warnw("could not move participant into room, moving back", err, "destinationRoom", destRoomName)
+		r.moveParticipantSession(participant, requestSource, room)
+		if rerr := room.MoveInParticipant(participant, requestSource, opts); rerr != nil {
+			pLogger.Warnw("could not move participant back", rerr)
+			_ = participant.Close(true, types.ParticipantCloseReasonJoinFailed, false)
+			return nil, err
+		}
+		if rerr := r.startParticipantSession(ctx, room, room.ToProto(), participant, participant.GetClientInfo(), pLogger); rerr != nil {
+			return nil, rerr
+		}
+		return nil, err
+	}
+
+	if err = r.startParticipantSession(ctx, destRoom, destRoom.ToProto(), participant, participant.GetClientInfo(), pLogger); err != nil {
+		return nil, err
+	}
+
+	// client token has to be scoped to the destination room for resumption
+	if err = r.refreshToken(destRoom, participant); err != nil {
+		pLogger.Warnw("could not refresh token after move", err)
+	}
+	return &MoveParticipantResponse{}, nil
+}
+
+func (r *RoomManager) ForwardTrack(ctx context.Context, req *ForwardTrackRequest) (*ForwardTrackResponse, error) {
+	room, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// forwarded tracks share receivers with the source track, a destination room hosted on another node is relayed to
+	destRoomName := livekit.RoomName(req.DestinationRoom)
+	if err = r.claimRoomOnThisNode(ctx, destRoomName); err != nil {
+		if errors.Is(err, ErrDestinationRoomOnOtherNode) {
+			return r.relayTrack(ctx, room, participant, req)
+		}
+		return nil, err
+	}
+
+	destRoom, err := r.getOrCreateRoom(ctx, &livekit.CreateRoomRequest{Name: req.DestinationRoom})
+	if err != nil {
+		return nil, err
+	}
+	defer destRoom.Release()
+
+	bridge, err := r.getOrCreateRoomBridge(ctx, room, destRoom, participant)
+	if err != nil {
+		return nil, err
+	}
+
+	bt, err := bridge.ForwardTrack(livekit.TrackID(req.TrackSid))
+	if err != nil {
+		if len(bridge.Tracks()) == 0 {
+			bridge.Close()
+		}
+		switch {
+		case errors.Is(err, rtc.ErrTrackNotFound):
+			return nil, ErrTrackNotFound
+		case errors.Is(err, rtc.ErrTrackNotBound):
+			return nil, ErrTrackNotReceiving
+		}
+		return nil, err
+	}
+
+	if err = r.roomStore.StoreParticipant(ctx, destRoomName, bridge.DestinationParticipant().ToProto()); err != nil {
+		bridge.DestinationRoom().Logger.Errorw("could not store bridge participant", err)
+	}
+	return &ForwardTrackResponse{
+		Identity: string(bridge.Identity()),
+		TrackSid: string(bt.ID()),
+	}, nil
+}
+
+func (r *RoomManager) StopForwardTrack(ctx context.Context, req *StopForwardTrackRequest) (*StopForwardTrackResponse, error) {
+	room, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := roomBridgeKey{
+		sourceRoom: room.Name(),
+		identity:   rtc.BridgeParticipantIdentity(participant.Identity(), livekit.RoomName(req.DestinationRoom)),
+	}
+	r.bridgesLock.Lock()
+	bridge := r.bridges[key]
+	r.bridgesLock.Unlock()
+	if bridge == nil {
+		return r.stopRelayTrack(key, livekit.TrackID(req.TrackSid))
+	}
+
+	if err = bridge.StopTrack(livekit.TrackID(req.TrackSid)); err != nil {
+		return nil, ErrBridgeNotFound
+	}
+
+	if dp := bridge.DestinationParticipant(); !dp.IsClosed() {
+		if err = r.roomStore.StoreParticipant(ctx, livekit.RoomName(req.DestinationRoom), dp.ToProto()); err != nil {
+			bridge.DestinationRoom().Logger.Errorw("could not store bridge participant", err)
+		}
+	}
+	return &StopForwardTrackResponse{}, nil
+}
+
+func (r *RoomManager) UpdateParticipantPolicy(ctx context.Context, req *UpdateParticipantPolicyRequest) (*UpdateParticipantPolicyResponse, error) {
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	// stored with the room, so that the room keeps the policy when it is moved or restored on another node
+	policy := &RoomParticipantPolicy{
+		Room:                 req.Room,
+		MaxDurationSeconds:   req.MaxDurationSeconds,
+		IdleTimeoutSeconds:   req.IdleTimeoutSeconds,
+		WarningBeforeSeconds: req.WarningBeforeSeconds,
+	}
+	if err := r.roomStore.StoreRoomParticipantPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	room.SetParticipantPolicy(policy.ParticipantPolicyConfig())
+	return &UpdateParticipantPolicyResponse{
+		MaxDurationSeconds:   req.MaxDurationSeconds,
+		IdleTimeoutSeconds:   req.IdleTimeoutSeconds,
+		WarningBeforeSeconds: req.WarningBeforeSeconds,
+	}, nil
+}
+
+func (r *RoomManager) ApplyRoomSchedule(ctx context.Context, req *ApplyRoomScheduleRequest) (*ApplyRoomScheduleResponse, error) {
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	if err := r.applyRoomSchedule(ctx, room); err != nil {
+		return nil, err
+	}
+	return &ApplyRoomScheduleResponse{}, nil
+}
+
+func (r *RoomManager) UpdateRoomLobby(ctx context.Context, req *UpdateRoomLobbyRequest) (*UpdateRoomLobbyResponse, error) {
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	room.SetLobbyEnabled(req.Enabled)
+	return &UpdateRoomLobbyResponse{Enabled: req.Enabled}, nil
+}
+
+func (r *RoomManager) ListLobbyParticipants(ctx context.Context, req *ListLobbyParticipantsRequest) (*ListLobbyParticipantsResponse, error) {
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	return &ListLobbyParticipantsResponse{Participants: room.GetWaitingParticipants()}, nil
+}
+
+func (r *RoomManager) AdmitParticipant(ctx context.Context, req *AdmitParticipantRequest) (*AdmitParticipantResponse, error) {
+	room, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = room.AdmitParticipant(participant.Identity()); err != nil {
+		return nil, ErrParticipantNotWaiting
+	}
+	return &AdmitParticipantResponse{}, nil
+}
+
+// storeQualityReport stores the quality report of the ended room and returns it, nil when reports are disabled
+func (r *RoomManager) storeQualityReport(ctx context.Context, roomInfo *livekit.Room) *telemetry.QualityReport {
+	report := r.telemetry.RoomQualityReport(ctx, roomInfo)
+	conf := r.config.Room.QualityReport
+	if report == nil || !conf.Enabled {
+		return nil
+	}
+	if err := r.roomStore.StoreRoomQualityReport(ctx, report, conf.Retention); err != nil {
+		logger.Errorw("could not store room quality report", err, "room", roomInfo.Name, "roomID", roomInfo.Sid)
+	}
+	return report
+}
+
+func (r *RoomManager) applyRoomSchedule(ctx context.Context, room *rtc.Room) error {
+	schedule, err := r.roomStore.LoadRoomSchedule(ctx, room.Name())
+	if errors.Is(err, ErrRoomScheduleNotFound) {
+		if _, ok := room.GetSchedule(); ok {
+			room.ClearSchedule()
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	room.SetSchedule(schedule.RoomScheduleWindow(time.Now()))
+	return nil
+}
+
+// applyRoomParticipantPolicy restores a participant policy set on the room before it was created on this node
+func (r *RoomManager) applyRoomParticipantPolicy(ctx context.Context, room *rtc.Room) error {
+	policy, err := r.roomStore.LoadRoomParticipantPolicy(ctx, room.Name())
+	if errors.Is(err, ErrRoomParticipantPolicyNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	room.SetParticipantPolicy(policy.ParticipantPolicyConfig())
+	return nil
+}
+
+func (r *RoomManager) getOrCreateRoomBridge(
+	ctx context.Context,
+	room *rtc.Room,
+	destRoom *rtc.Room,
+	participant types.LocalParticipant,
+) (*rtc.RoomBridge, error) {
+	key := roomBridgeKey{
+		sourceRoom: room.Name(),
+		identity:   rtc.BridgeParticipantIdentity(participant.Identity(), destRoom.Name()),
+	}
+
+	r.bridgesLock.Lock()
+	defer r.bridgesLock.Unlock()
+
+	if bridge := r.bridges[key]; bridge != nil {
+		return bridge, nil
+	}
+
+	bridge, err := rtc.NewRoomBridge(rtc.RoomBridgeParams{
+		SourceRoom:         room,
+		DestinationRoom:    destRoom,
+		Publisher:          participant,
+		DynacastPauseDelay: r.config.Video.DynacastPauseDelay,
+		VersionGenerator:   r.versionGenerator,
+		Telemetry:          r.telemetry,
+		Logger:             participant.GetLogger().WithValues("destinationRoom", destRoom.Name()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.bridges[key] = bridge
+
+	for _, br := range []struct {
+		room livekit.RoomName
+		p    *rtc.BridgeParticipant
+	}{
+		{room.Name(), bridge.SourceParticipant()},
+		{destRoom.Name(), bridge.DestinationParticipant()},
+	} {
+		if err := r.roomStore.StoreParticipant(ctx, br.room, br.p.ToProto()); err != nil {
+			room.Logger.Errorw("could not store bridge participant", err, "room", br.room)
+		}
+	}
+
+	bridge.OnClose(func(b *rtc.RoomBridge) {
+		r.bridgesLock.Lock()
+		if r.bridges[key] == b {
+			delete(r.bridges, key)
+		}
+		r.bridgesLock.Unlock()
+
+		for _, roomName := range []livekit.RoomName{key.sourceRoom, b.DestinationRoom().Name()} {
+			if err := r.roomStore.DeleteParticipant(context.Background(), roomName, key.identity); err != nil {
+				room.Logger.Errorw("could not delete bridge participant", err, "room", roomName)
+			}
+		}
+	})
+	return bridge, nil
+}
+
+// claimRoomOnThisNode ensures the room is hosted on this node, assigning it when it is not placed yet
+func (r *RoomManager) claimRoomOnThisNode(ctx context.Context, roomName livekit.RoomName) error {
+	node, err := r.router.GetNodeForRoom(ctx, roomName)
+	switch {
+	case errors.Is(err, routing.ErrNotFound):
+		return r.router.SetNodeForRoom(ctx, roomName, r.currentNode.NodeID())
+	case err != nil:
+		return err
+	case livekit.NodeID(node.Id) != r.currentNode.NodeID():
+		return ErrDestinationRoomOnOtherNode
+	}
+	return nil
+}
+
 func (r *RoomManager) MutePublishedTrack(ctx context.Context, req *livekit.MuteRoomTrackRequest) (*livekit.MuteRoomTrackResponse, error) {
 	_, participant, err := r.roomAndParticipantForReq(ctx, req)
 	if err != nil {
@@ -957,7 +1488,7 @@ func (r *RoomManager) iceServersForParticipant(apiKey string, participant types.
 	return iceServers
 }
 
-func (r *RoomManager) refreshToken(participant types.LocalParticipant) error {
+func (r *RoomManager) refreshToken(room *rtc.Room, participant types.LocalParticipant) error {
 	key, secret, err := r.getFirstKeyPair()
 	if err != nil {
 		return err
@@ -973,7 +1504,16 @@ func (r *RoomManager) refreshToken(participant types.LocalParticipant) error {
 		SetVideoGrant(grants.Video).
 		SetRoomConfig(grants.GetRoomConfiguration()).
 		SetRoomPreset(grants.RoomPreset)
-	jwt, err := token.ToJWT()
+
+	var jwt string
+	if policyClaim := room.GetParticipantPolicyClaim(participant.Identity()); policyClaim != nil {
+		// the policy claim is not part of the grants and has to be carried over separately
+		jwt, err = tokenWithClaims(key, secret, tokenDefaultTTL, token.GetGrants(), map[string]any{
+			rtc.ParticipantPolicyClaim: policyClaim,
+		})
+	} else {
+		jwt, err = token.ToJWT()
+	}
 	if err == nil {
 		err = participant.SendRefreshToken(jwt)
 	}
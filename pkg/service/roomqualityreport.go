@@ -0,0 +1,42 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/protocol/livekit"
+)
+
+type GetRoomQualityReportRequest struct {
+	Room string `json:"room"`
+}
+
+func (r *GetRoomQualityReportRequest) GetRoom() string {
+	return r.Room
+}
+
+// GetRoomQualityReport returns the quality report of the last session of a room, kept for
+// room.quality_report.retention after the room ended
+func (s *RoomService) GetRoomQualityReport(ctx context.Context, req *GetRoomQualityReportRequest) (*telemetry.QualityReport, error) {
+	AppendLogFields(ctx, "room", req.Room)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	return s.roomStore.LoadRoomQualityReport(ctx, livekit.RoomName(req.Room))
+}
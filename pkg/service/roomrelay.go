@@ -0,0 +1,466 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net"
+	"slices"
+	"strconv"
+
+	"go.uber.org/atomic"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+)
+
+// Tracks are forwarded into a destination room hosted on another node by relaying them. The node hosting
+// the source room attaches a TrackRelay to the track and asks the destination node over psrpc to publish
+// a RelayedTrack, media then flows over the relay transports of both nodes. Changes of the track go to
+// the destination node, max subscribed qualities come back to the source node.
+
+// relayedTrack is a track published in a room on this node, relayed from another node
+type relayedTrack struct {
+	*rtc.RelayedTrack
+
+	room           *rtc.Room
+	identity       livekit.ParticipantIdentity
+	sourceRoom     livekit.RoomName
+	sourceIdentity livekit.ParticipantIdentity
+	sourceTopic    rpc.RoomTopic
+	// relayed between the origin and an edge node of the room, see roomedge.go
+	edge           bool
+	sourceNode     livekit.NodeID
+	closedBySource atomic.Bool
+}
+
+// relayKey authenticates the packets relayed between nodes, nodes of a cluster share their API keys
+// unless a relay key is set
+func relayKey(conf *config.Config) []byte {
+	if conf.RTC.Relay.Key != "" {
+		return []byte(conf.RTC.Relay.Key)
+	}
+	if len(conf.Keys) == 0 {
+		return nil
+	}
+
+	h := sha256.New()
+	keys := maps.Keys(conf.Keys)
+	slices.Sort(keys)
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(conf.Keys[key]))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)
+}
+
+func (r *RoomManager) relayAddress() string {
+	return net.JoinHostPort(r.currentNode.NodeIP(), strconv.Itoa(int(r.relayTransport.Port())))
+}
+
+// relayTrack is ForwardTrack for a destination room hosted on another node
+func (r *RoomManager) relayTrack(ctx context.Context, room *rtc.Room, participant types.LocalParticipant, req *ForwardTrackRequest) (*ForwardTrackResponse, error) {
+	if r.relayTransport == nil {
+		return nil, ErrForwardToOtherNodeUnsupported
+	}
+
+	// makes sure the destination room is running on its node
+	destRoomName := livekit.RoomName(req.DestinationRoom)
+	if _, err := r.router.CreateRoom(ctx, &livekit.CreateRoomRequest{Name: req.DestinationRoom}); err != nil {
+		return nil, err
+	}
+	destNode, err := r.router.GetNodeForRoom(ctx, destRoomName)
+	if err != nil {
+		return nil, err
+	}
+
+	roomRelay, err := r.getOrCreateRoomRelay(ctx, room, destRoomName, livekit.NodeID(destNode.Id), participant)
+	if err != nil {
+		return nil, err
+	}
+
+	trackID := livekit.TrackID(req.TrackSid)
+	tr, created, err := roomRelay.ForwardTrack(trackID)
+	if err != nil {
+		if len(roomRelay.Tracks()) == 0 {
+			roomRelay.Close()
+		}
+		switch {
+		case errors.Is(err, rtc.ErrTrackNotFound):
+			return nil, ErrTrackNotFound
+		case errors.Is(err, rtc.ErrTrackNotBound):
+			return nil, ErrTrackNotReceiving
+		}
+		return nil, err
+	}
+	if !created {
+		return &ForwardTrackResponse{
+			Identity: string(roomRelay.Identity()),
+			TrackSid: string(tr.DestinationTrackID()),
+		}, nil
+	}
+
+	track := participant.GetPublishedTrack(trackID)
+	if track == nil {
+		tr.Close()
+		return nil, ErrTrackNotFound
+	}
+	ti, err := proto.Marshal(track.ToProto())
+	if err != nil {
+		tr.Close()
+		return nil, err
+	}
+	res, err := r.extensionClient.RelayTrack(ctx, rpc.FormatRoomTopic(destRoomName), &RelayTrackRequest{
+		Room:             req.DestinationRoom,
+		Identity:         string(roomRelay.Identity()),
+		Name:             participant.ToProto().Name,
+		SourceRoom:       string(room.Name()),
+		SourceIdentity:   string(participant.Identity()),
+		SourceAddress:    r.relayAddress(),
+		SessionID:        tr.SessionID(),
+		TrackInfo:        ti,
+		Codecs:           tr.Codecs(),
+		HeaderExtensions: tr.HeaderExtensions(),
+	})
+	if err != nil {
+		tr.Close()
+		return nil, err
+	}
+	addr, err := net.ResolveUDPAddr("udp", res.Address)
+	if err != nil {
+		tr.Close()
+		return nil, err
+	}
+	tr.Start(addr, livekit.TrackID(res.TrackSid))
+
+	return &ForwardTrackResponse{
+		Identity: string(roomRelay.Identity()),
+		TrackSid: res.TrackSid,
+	}, nil
+}
+
+func (r *RoomManager) stopRelayTrack(key roomBridgeKey, trackID livekit.TrackID) (*StopForwardTrackResponse, error) {
+	r.relaysLock.Lock()
+	roomRelay := r.relays[key]
+	r.relaysLock.Unlock()
+	if roomRelay == nil {
+		return nil, ErrBridgeNotFound
+	}
+
+	if err := roomRelay.StopTrack(trackID); err != nil {
+		return nil, ErrBridgeNotFound
+	}
+	return &StopForwardTrackResponse{}, nil
+}
+
+func (r *RoomManager) getOrCreateRoomRelay(
+	ctx context.Context,
+	room *rtc.Room,
+	destRoomName livekit.RoomName,
+	destNodeID livekit.NodeID,
+	participant types.LocalParticipant,
+) (*rtc.RoomRelay, error) {
+	key := roomBridgeKey{
+		sourceRoom: room.Name(),
+		identity:   rtc.BridgeParticipantIdentity(participant.Identity(), destRoomName),
+	}
+
+	r.relaysLock.Lock()
+	defer r.relaysLock.Unlock()
+
+	if roomRelay := r.relays[key]; roomRelay != nil {
+		return roomRelay, nil
+	}
+
+	roomRelay, err := rtc.NewRoomRelay(rtc.RoomRelayParams{
+		SourceRoom:       room,
+		DestinationRoom:  destRoomName,
+		DestinationNode:  destNodeID,
+		Publisher:        participant,
+		Transport:        r.relayTransport,
+		VersionGenerator: r.versionGenerator,
+		Logger:           participant.GetLogger().WithValues("destinationRoom", destRoomName, "destinationNodeID", destNodeID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.relays[key] = roomRelay
+
+	if err := r.roomStore.StoreParticipant(ctx, room.Name(), roomRelay.SourceParticipant().ToProto()); err != nil {
+		room.Logger.Errorw("could not store bridge participant", err)
+	}
+
+	roomRelay.OnTrackUpdated(func(roomRelay *rtc.RoomRelay, track types.MediaTrack) {
+		tr := roomRelay.Track(track.ID())
+		ti, err := proto.Marshal(track.ToProto())
+		if tr == nil || err != nil {
+			return
+		}
+		go r.updateRelayedTrack(roomRelay, &UpdateRelayedTrackRequest{
+			Room:      string(roomRelay.DestinationRoom()),
+			Identity:  string(roomRelay.Identity()),
+			SessionID: tr.SessionID(),
+			TrackInfo: ti,
+		})
+	})
+	roomRelay.OnTrackClosed(func(roomRelay *rtc.RoomRelay, tr *rtc.TrackRelay) {
+		go r.updateRelayedTrack(roomRelay, &UpdateRelayedTrackRequest{
+			Room:      string(roomRelay.DestinationRoom()),
+			Identity:  string(roomRelay.Identity()),
+			SessionID: tr.SessionID(),
+			Closed:    true,
+		})
+	})
+	roomRelay.OnClose(func(roomRelay *rtc.RoomRelay) {
+		r.relaysLock.Lock()
+		if r.relays[key] == roomRelay {
+			delete(r.relays, key)
+		}
+		r.relaysLock.Unlock()
+
+		if err := r.roomStore.DeleteParticipant(context.Background(), key.sourceRoom, key.identity); err != nil {
+			room.Logger.Errorw("could not delete bridge participant", err)
+		}
+	})
+	return roomRelay, nil
+}
+
+func (r *RoomManager) updateRelayedTrack(roomRelay *rtc.RoomRelay, req *UpdateRelayedTrackRequest) {
+	if _, err := r.extensionClient.UpdateRelayedTrack(context.Background(), rpc.FormatRoomTopic(roomRelay.DestinationRoom()), req); err != nil {
+		roomRelay.SourceRoom().Logger.Debugw("could not update relayed track", "error", err, "destinationRoom", req.Room, "sessionID", req.SessionID)
+	}
+}
+
+// RelayTrack publishes a track relayed from another node in a room of this node
+func (r *RoomManager) RelayTrack(ctx context.Context, req *RelayTrackRequest) (*RelayTrackResponse, error) {
+	if r.relayTransport == nil {
+		return nil, ErrRelayNotEnabled
+	}
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	sourceAddr, err := net.ResolveUDPAddr("udp", req.SourceAddress)
+	if err != nil {
+		return nil, psrpc.NewError(psrpc.MalformedRequest, err)
+	}
+	source := &livekit.TrackInfo{}
+	if err := proto.Unmarshal(req.TrackInfo, source); err != nil {
+		return nil, psrpc.NewError(psrpc.MalformedRequest, err)
+	}
+
+	identity := livekit.ParticipantIdentity(req.Identity)
+	bp := room.GetBridgeParticipant(identity)
+	if bp == nil {
+		params := rtc.BridgeParticipantParams{
+			Identity: identity,
+			Name:     req.Name,
+			Kind:     livekit.ParticipantInfo_INGRESS,
+			Attributes: map[string]string{
+				rtc.BridgeAttributeSourceRoom:     req.SourceRoom,
+				rtc.BridgeAttributeSourceIdentity: req.SourceIdentity,
+			},
+			VersionGenerator: r.versionGenerator,
+			Logger:           room.Logger.WithValues("participant", identity),
+		}
+		if req.Edge {
+			// mirrors the participant, which is stored by the node it is connected to
+			params.Kind = livekit.ParticipantInfo_Kind(req.Kind)
+			params.Attributes = req.Attributes
+		}
+		bp = rtc.NewBridgeParticipant(params)
+		if err := room.AddBridgeParticipant(bp); err != nil {
+			return nil, err
+		}
+		if !req.Edge {
+			bp.OnClose(func(_ *rtc.BridgeParticipant) {
+				if err := r.roomStore.DeleteParticipant(context.Background(), room.Name(), identity); err != nil {
+					room.Logger.Errorw("could not delete bridge participant", err)
+				}
+			})
+		}
+	}
+
+	rt := &relayedTrack{
+		RelayedTrack: rtc.NewRelayedTrack(rtc.RelayedTrackParams{
+			Source:                     source,
+			SessionID:                  req.SessionID,
+			SourceAddress:              sourceAddr,
+			Codecs:                     req.Codecs,
+			HeaderExtensions:           req.HeaderExtensions,
+			Transport:                  r.relayTransport,
+			ParticipantID:              bp.ID(),
+			ParticipantIdentity:        identity,
+			ReceiverConfig:             r.rtcConfig.Receiver,
+			SubscriberConfig:           r.rtcConfig.Subscriber,
+			AudioConfig:                r.config.Audio,
+			PLIThrottleConfig:          r.config.RTC.PLIThrottle,
+			StreamTrackerManagerConfig: r.config.Video.StreamTrackerManager,
+			DynacastPauseDelay:         r.config.Video.DynacastPauseDelay,
+			Telemetry:                  r.telemetry,
+			Logger:                     rtc.LoggerWithTrack(room.Logger.WithValues("participant", identity), livekit.TrackID(source.Sid), true),
+		}),
+		room:           room,
+		identity:       identity,
+		sourceRoom:     livekit.RoomName(req.SourceRoom),
+		sourceIdentity: livekit.ParticipantIdentity(req.SourceIdentity),
+		sourceTopic:    rpc.FormatRoomTopic(livekit.RoomName(req.SourceRoom)),
+		edge:           req.Edge,
+		sourceNode:     livekit.NodeID(req.SourceNode),
+	}
+	if req.SourceTopic != "" {
+		rt.sourceTopic = rpc.RoomTopic(req.SourceTopic)
+	}
+	rt.OnSubscribedMaxQualityChange(func(qualities []types.SubscribedCodecQuality) {
+		go r.updateRelayQuality(rt, qualities)
+	})
+	rt.AddOnClose(func(_ bool) {
+		r.onRelayedTrackClosed(rt)
+	})
+
+	r.relaysLock.Lock()
+	r.relayedTracks[req.SessionID] = rt
+	r.relaysLock.Unlock()
+
+	if err := room.PublishBridgedTrack(identity, rt); err != nil {
+		rt.closedBySource.Store(true)
+		rt.Close(false)
+		return nil, err
+	}
+	if !req.Edge {
+		if err := r.roomStore.StoreParticipant(ctx, room.Name(), bp.ToProto()); err != nil {
+			room.Logger.Errorw("could not store bridge participant", err)
+		}
+	}
+
+	room.Logger.Infow("relayed track published", "participant", identity, "trackID", rt.ID(), "sourceTrackID", rt.SourceID(), "edge", req.Edge)
+	return &RelayTrackResponse{
+		TrackSid: string(rt.ID()),
+		Address:  r.relayAddress(),
+	}, nil
+}
+
+func (r *RoomManager) onRelayedTrackClosed(rt *relayedTrack) {
+	r.relaysLock.Lock()
+	if r.relayedTracks[rt.SessionID()] == rt {
+		delete(r.relayedTracks, rt.SessionID())
+	}
+	r.relaysLock.Unlock()
+
+	rt.room.UnpublishBridgedTrack(rt.identity, rt)
+	if bp := rt.room.GetBridgeParticipant(rt.identity); bp != nil {
+		if len(bp.GetPublishedTracks()) == 0 {
+			rt.room.RemoveBridgeParticipant(rt.identity, types.ParticipantCloseReasonNone)
+		} else if !rt.edge {
+			if err := r.roomStore.StoreParticipant(context.Background(), rt.room.Name(), bp.ToProto()); err != nil {
+				rt.room.Logger.Errorw("could not store bridge participant", err)
+			}
+		}
+	}
+
+	// edge links stop relaying when the room closes on either side
+	if rt.closedBySource.Load() || rt.edge {
+		return
+	}
+	// closed on this side, i. e. the destination room closed, the source node stops sending
+	go func() {
+		_, err := r.extensionClient.StopForwardTrack(context.Background(), rpc.FormatRoomTopic(rt.sourceRoom), &StopForwardTrackRequest{
+			Room:            string(rt.sourceRoom),
+			Identity:        string(rt.sourceIdentity),
+			TrackSid:        string(rt.SourceID()),
+			DestinationRoom: string(rt.room.Name()),
+		})
+		if err != nil {
+			rt.room.Logger.Debugw("could not stop relay", "error", err, "sessionID", rt.SessionID())
+		}
+	}()
+}
+
+func (r *RoomManager) updateRelayQuality(rt *relayedTrack, qualities []types.SubscribedCodecQuality) {
+	req := &UpdateRelayQualityRequest{
+		Room:      string(rt.sourceRoom),
+		Identity:  string(rt.identity),
+		TrackSid:  string(rt.SourceID()),
+		SessionID: rt.SessionID(),
+	}
+	for _, q := range qualities {
+		req.Qualities = append(req.Qualities, RelaySubscribedQuality{Mime: q.CodecMime.String(), Quality: q.Quality})
+	}
+	if _, err := r.extensionClient.UpdateRelayQuality(context.Background(), rt.sourceTopic, req); err != nil {
+		rt.room.Logger.Debugw("could not update relay quality", "error", err, "sessionID", rt.SessionID())
+	}
+}
+
+// UpdateRelayedTrack applies changes of a track relayed into a room of this node
+func (r *RoomManager) UpdateRelayedTrack(_ context.Context, req *UpdateRelayedTrackRequest) (*UpdateRelayedTrackResponse, error) {
+	r.relaysLock.Lock()
+	rt := r.relayedTracks[req.SessionID]
+	r.relaysLock.Unlock()
+	if rt == nil || string(rt.room.Name()) != req.Room {
+		return nil, ErrRelayedTrackNotFound
+	}
+
+	if req.Closed {
+		rt.closedBySource.Store(true)
+		rt.Close(false)
+		return &UpdateRelayedTrackResponse{}, nil
+	}
+
+	source := &livekit.TrackInfo{}
+	if err := proto.Unmarshal(req.TrackInfo, source); err != nil {
+		return nil, psrpc.NewError(psrpc.MalformedRequest, err)
+	}
+	rt.UpdateSource(source)
+	rt.room.UpdateBridgedTrack(rt.identity, rt)
+	return &UpdateRelayedTrackResponse{}, nil
+}
+
+// UpdateRelayQuality applies max qualities subscribed on the destination node of a relay to the publisher
+func (r *RoomManager) UpdateRelayQuality(_ context.Context, req *UpdateRelayQualityRequest) (*UpdateRelayQualityResponse, error) {
+	var tr *rtc.TrackRelay
+	r.relaysLock.Lock()
+	if roomRelay := r.relays[roomBridgeKey{
+		sourceRoom: livekit.RoomName(req.Room),
+		identity:   livekit.ParticipantIdentity(req.Identity),
+	}]; roomRelay != nil {
+		tr = roomRelay.Track(livekit.TrackID(req.TrackSid))
+	} else {
+		tr = r.roomEdgeTrackLocked(livekit.RoomName(req.Room), req.SessionID)
+	}
+	r.relaysLock.Unlock()
+	if tr == nil {
+		return nil, ErrBridgeNotFound
+	}
+
+	qualities := make([]types.SubscribedCodecQuality, 0, len(req.Qualities))
+	for _, q := range req.Qualities {
+		qualities = append(qualities, types.SubscribedCodecQuality{CodecMime: mime.NormalizeMimeType(q.Mime), Quality: q.Quality})
+	}
+	tr.UpdateSubscribedQualities(qualities)
+	return &UpdateRelayQualityResponse{}, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
+)
+
+func TestRelayTrack(t *testing.T) {
+	origin, _, publisher := newRoomEdgeTestNodes(t)
+	room := origin.GetRoom(context.Background(), testEdgeRoom)
+
+	ti, err := proto.Marshal(&livekit.TrackInfo{Sid: "TR_source", Type: livekit.TrackType_AUDIO, Name: "mic"})
+	require.NoError(t, err)
+	sessionID := publisher.transport.NewSession(publisher)
+	res, err := origin.RelayTrack(context.Background(), &RelayTrackRequest{
+		Room:           string(testEdgeRoom),
+		Identity:       "bridge",
+		SourceRoom:     "other",
+		SourceIdentity: "pub",
+		SourceAddress:  publisher.transport.LocalAddr().String(),
+		SessionID:      sessionID,
+		TrackInfo:      ti,
+		Codecs: []webrtc.RTPCodecParameters{{
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2},
+			PayloadType:        111,
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, origin.relayAddress(), res.Address)
+
+	// published by a bridge participant, stored like one of the room
+	bp := room.GetBridgeParticipant("bridge")
+	require.NotNil(t, bp)
+	require.Equal(t, livekit.ParticipantInfo_INGRESS, bp.Kind())
+	require.Equal(t, "other", bp.ToProto().Attributes[rtc.BridgeAttributeSourceRoom])
+	require.Len(t, bp.GetPublishedTracks(), 1)
+	require.Equal(t, livekit.TrackID(res.TrackSid), bp.GetPublishedTracks()[0].ID())
+	_, err = origin.roomStore.LoadParticipant(context.Background(), testEdgeRoom, "bridge")
+	require.NoError(t, err)
+
+	// updates apply to the relayed track, the bridge participant leaves with its last track
+	muted, err := proto.Marshal(&livekit.TrackInfo{Sid: "TR_source", Type: livekit.TrackType_AUDIO, Name: "mic", Muted: true})
+	require.NoError(t, err)
+	_, err = origin.UpdateRelayedTrack(context.Background(), &UpdateRelayedTrackRequest{Room: string(testEdgeRoom), SessionID: sessionID, TrackInfo: muted})
+	require.NoError(t, err)
+	require.True(t, bp.GetPublishedTracks()[0].IsMuted())
+
+	_, err = origin.UpdateRelayedTrack(context.Background(), &UpdateRelayedTrackRequest{Room: "other", SessionID: sessionID, Closed: true})
+	require.ErrorIs(t, err, ErrRelayedTrackNotFound)
+
+	_, err = origin.UpdateRelayedTrack(context.Background(), &UpdateRelayedTrackRequest{Room: string(testEdgeRoom), SessionID: sessionID, Closed: true})
+	require.NoError(t, err)
+	require.Nil(t, room.GetBridgeParticipant("bridge"))
+	_, err = origin.roomStore.LoadParticipant(context.Background(), testEdgeRoom, "bridge")
+	require.ErrorIs(t, err, ErrParticipantNotFound)
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
+)
+
+const (
+	RoomScheduleRecurrenceNone   = ""
+	RoomScheduleRecurrenceDaily  = "daily"
+	RoomScheduleRecurrenceWeekly = "weekly"
+
+	// joins before the start time are rejected
+	RoomScheduleEarlyJoinReject = "reject"
+	// participants joining before the start time wait in a lobby, without publishing or subscribing
+	RoomScheduleEarlyJoinLobby = "lobby"
+)
+
+// RoomSchedule pre-provisions a room with a start and end time. Rooms are created from the schedule
+// configuration when the first participant joins, and closed at the end time.
+// Recurring schedules repeat the same window daily or weekly until RepeatUntil.
+type RoomSchedule struct {
+	Room string `json:"room"`
+	// unix seconds of the first occurrence
+	StartTime int64 `json:"start_time"`
+	EndTime   int64 `json:"end_time"`
+
+	Recurrence string `json:"recurrence,omitempty"`
+	// unix seconds, no occurrence starts after this time, 0 repeats forever
+	RepeatUntil int64 `json:"repeat_until,omitempty"`
+
+	// reject (default) or lobby
+	EarlyJoin string `json:"early_join,omitempty"`
+	// participants are warned this many seconds before the room closes
+	WarningBeforeEndSeconds uint32 `json:"warning_before_end_seconds,omitempty"`
+
+	// configuration the room is created with
+	EmptyTimeout     uint32 `json:"empty_timeout,omitempty"`
+	DepartureTimeout uint32 `json:"departure_timeout,omitempty"`
+	MaxParticipants  uint32 `json:"max_participants,omitempty"`
+	Metadata         string `json:"metadata,omitempty"`
+}
+
+type ListRoomSchedulesRequest struct {
+	// when empty, all schedules are returned
+	Names []string `json:"names,omitempty"`
+}
+
+type ListRoomSchedulesResponse struct {
+	Schedules []*RoomSchedule `json:"schedules"`
+}
+
+type DeleteRoomScheduleRequest struct {
+	Room string `json:"room"`
+}
+
+type DeleteRoomScheduleResponse struct{}
+
+func (s *RoomSchedule) GetRoom() string {
+	return s.Room
+}
+
+func (s *RoomSchedule) Validate() error {
+	if s.Room == "" {
+		return fmt.Errorf("%w: room is required", ErrInvalidRoomSchedule)
+	}
+	if s.StartTime <= 0 || s.EndTime <= s.StartTime {
+		return fmt.Errorf("%w: end_time has to be after start_time", ErrInvalidRoomSchedule)
+	}
+	if s.WarningBeforeEndSeconds != 0 && int64(s.WarningBeforeEndSeconds) >= s.EndTime-s.StartTime {
+		return fmt.Errorf("%w: warning_before_end_seconds exceeds the room duration", ErrInvalidRoomSchedule)
+	}
+
+	switch s.EarlyJoin {
+	case "", RoomScheduleEarlyJoinReject, RoomScheduleEarlyJoinLobby:
+	default:
+		return fmt.Errorf("%w: unknown early_join %q", ErrInvalidRoomSchedule, s.EarlyJoin)
+	}
+
+	period := s.period()
+	switch s.Recurrence {
+	case RoomScheduleRecurrenceNone:
+	case RoomScheduleRecurrenceDaily, RoomScheduleRecurrenceWeekly:
+		if time.Duration(s.EndTime-s.StartTime)*time.Second > period {
+			return fmt.Errorf("%w: occurrences cannot overlap", ErrInvalidRoomSchedule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown recurrence %q", ErrInvalidRoomSchedule, s.Recurrence)
+	}
+	return nil
+}
+
+func (s *RoomSchedule) period() time.Duration {
+	switch s.Recurrence {
+	case RoomScheduleRecurrenceDaily:
+		return 24 * time.Hour
+	case RoomScheduleRecurrenceWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Window returns the occurrence that is in progress at now, or the next one.
+// ok is false when the schedule has no occurrence left.
+func (s *RoomSchedule) Window(now time.Time) (start time.Time, end time.Time, ok bool) {
+	start = time.Unix(s.StartTime, 0)
+	duration := time.Duration(s.EndTime-s.StartTime) * time.Second
+
+	if period := s.period(); period > 0 && now.After(start) {
+		// occurrences keep the wall clock time of the first one in the local time zone across daylight saving changes
+		n := int(now.Sub(start) / period)
+		days := int(period / (24 * time.Hour))
+		start = start.AddDate(0, 0, n*days)
+		if !now.Before(start.Add(duration)) {
+			start = start.AddDate(0, 0, days)
+		}
+		if s.RepeatUntil > 0 && start.After(time.Unix(s.RepeatUntil, 0)) {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	end = start.Add(duration)
+	if !now.Before(end) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
+
+// RoomScheduleWindow is the occurrence applied to an rtc.Room
+func (s *RoomSchedule) RoomScheduleWindow(now time.Time) rtc.RoomScheduleWindow {
+	start, end, ok := s.Window(now)
+	if !ok {
+		// no occurrence left, the room closes right away
+		return rtc.RoomScheduleWindow{EndAt: now}
+	}
+	return rtc.RoomScheduleWindow{
+		StartAt:         start,
+		EndAt:           end,
+		WarningBefore:   time.Duration(s.WarningBeforeEndSeconds) * time.Second,
+		HoldBeforeStart: s.EarlyJoin == RoomScheduleEarlyJoinLobby,
+	}
+}
+
+// applyToCreateRoomRequest fills room configuration not given in the request from the schedule
+func (s *RoomSchedule) applyToCreateRoomRequest(req *livekit.CreateRoomRequest) {
+	if req.EmptyTimeout == 0 {
+		req.EmptyTimeout = s.EmptyTimeout
+	}
+	if req.DepartureTimeout == 0 {
+		req.DepartureTimeout = s.DepartureTimeout
+	}
+	if req.MaxParticipants == 0 {
+		req.MaxParticipants = s.MaxParticipants
+	}
+	if req.Metadata == "" {
+		req.Metadata = s.Metadata
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/service"
+)
+
+func TestRoomScheduleValidate(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+	valid := service.RoomSchedule{
+		Room:      "standup",
+		StartTime: start.Unix(),
+		EndTime:   start.Add(30 * time.Minute).Unix(),
+	}
+	require.NoError(t, valid.Validate())
+
+	for name, update := range map[string]func(s *service.RoomSchedule){
+		"missing room":        func(s *service.RoomSchedule) { s.Room = "" },
+		"end before start":    func(s *service.RoomSchedule) { s.EndTime = s.StartTime - 1 },
+		"unknown recurrence":  func(s *service.RoomSchedule) { s.Recurrence = "monthly" },
+		"unknown early join":  func(s *service.RoomSchedule) { s.EarlyJoin = "queue" },
+		"warning too long":    func(s *service.RoomSchedule) { s.WarningBeforeEndSeconds = 3600 },
+		"overlapping repeats": func(s *service.RoomSchedule) { s.Recurrence = "daily"; s.EndTime = s.StartTime + 25*3600 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := valid
+			update(&s)
+			require.ErrorIs(t, s.Validate(), service.ErrInvalidRoomSchedule)
+		})
+	}
+}
+
+func TestRoomScheduleWindow(t *testing.T) {
+	first := time.Date(2024, 3, 4, 9, 0, 0, 0, time.Local)
+	s := service.RoomSchedule{
+		Room:      "standup",
+		StartTime: first.Unix(),
+		EndTime:   first.Add(15 * time.Minute).Unix(),
+	}
+
+	t.Run("one time", func(t *testing.T) {
+		start, end, ok := s.Window(first.Add(-time.Hour))
+		require.True(t, ok)
+		require.True(t, first.Equal(start))
+		require.True(t, first.Add(15*time.Minute).Equal(end))
+
+		_, _, ok = s.Window(first.Add(10 * time.Minute))
+		require.True(t, ok)
+
+		_, _, ok = s.Window(first.Add(15 * time.Minute))
+		require.False(t, ok)
+	})
+
+	t.Run("daily", func(t *testing.T) {
+		daily := s
+		daily.Recurrence = service.RoomScheduleRecurrenceDaily
+		daily.RepeatUntil = first.AddDate(0, 0, 3).Unix()
+
+		// in progress
+		start, _, ok := daily.Window(first.AddDate(0, 0, 2).Add(5 * time.Minute))
+		require.True(t, ok)
+		require.True(t, first.AddDate(0, 0, 2).Equal(start))
+
+		// next occurrence after the current one ended
+		start, _, ok = daily.Window(first.AddDate(0, 0, 2).Add(time.Hour))
+		require.True(t, ok)
+		require.True(t, first.AddDate(0, 0, 3).Equal(start))
+
+		// no occurrence after repeat until
+		_, _, ok = daily.Window(first.AddDate(0, 0, 3).Add(time.Hour))
+		require.False(t, ok)
+	})
+
+	t.Run("weekly", func(t *testing.T) {
+		weekly := s
+		weekly.Recurrence = service.RoomScheduleRecurrenceWeekly
+
+		start, _, ok := weekly.Window(first.AddDate(0, 0, 1))
+		require.True(t, ok)
+		require.True(t, first.AddDate(0, 0, 7).Equal(start))
+	})
+}
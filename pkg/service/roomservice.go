@@ -17,6 +17,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/twitchtv/twirp"
@@ -32,7 +33,7 @@ import (
 )
 
 type RoomService struct {
-	limitConf         config.LimitConfig
+	config            *config.Config
 	apiConf           config.APIConfig
 	router            routing.MessageRouter
 	roomAllocator     RoomAllocator
@@ -41,10 +42,11 @@ type RoomService struct {
 	topicFormatter    rpc.TopicFormatter
 	roomClient        rpc.TypedRoomClient
 	participantClient rpc.TypedParticipantClient
+	extensionClient   *RoomExtensionClient
 }
 
 func NewRoomService(
-	limitConf config.LimitConfig,
+	conf *config.Config,
 	apiConf config.APIConfig,
 	router routing.MessageRouter,
 	roomAllocator RoomAllocator,
@@ -53,9 +55,10 @@ func NewRoomService(
 	topicFormatter rpc.TopicFormatter,
 	roomClient rpc.TypedRoomClient,
 	participantClient rpc.TypedParticipantClient,
+	extensionClient *RoomExtensionClient,
 ) (svc *RoomService, err error) {
 	svc = &RoomService{
-		limitConf:         limitConf,
+		config:            conf,
 		apiConf:           apiConf,
 		router:            router,
 		roomAllocator:     roomAllocator,
@@ -64,6 +67,7 @@ func NewRoomService(
 		topicFormatter:    topicFormatter,
 		roomClient:        roomClient,
 		participantClient: participantClient,
+		extensionClient:   extensionClient,
 	}
 	return
 }
@@ -79,8 +83,9 @@ func (s *RoomService) CreateRoom(ctx context.Context, req *livekit.CreateRoomReq
 		return nil, ErrEgressNotConnected
 	}
 
-	if !s.limitConf.CheckRoomNameLength(req.Name) {
-		return nil, fmt.Errorf("%w: max length %d", ErrRoomNameExceedsLimits, s.limitConf.MaxRoomNameLength)
+	limitConf := roomLimitConfig(s.config, livekit.RoomName(req.Name))
+	if !limitConf.CheckRoomNameLength(req.Name) {
+		return nil, fmt.Errorf("%w: max length %d", ErrRoomNameExceedsLimits, limitConf.MaxRoomNameLength)
 	}
 
 	err := s.roomAllocator.SelectRoomNode(ctx, livekit.RoomName(req.Name), livekit.NodeID(req.NodeId))
@@ -204,6 +209,195 @@ func (s *RoomService) RemoveParticipant(ctx context.Context, req *livekit.RoomPa
 	return res, err
 }
 
+// MoveParticipant moves a participant to another room hosted on the same node, keeping its connection
+func (s *RoomService) MoveParticipant(ctx context.Context, req *MoveParticipantRequest) (*MoveParticipantResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "destinationRoom", req.DestinationRoom)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	// destination room is created when it does not exist yet
+	if err := EnsureCreatePermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	if req.Identity == "" {
+		return nil, ErrIdentityEmpty
+	}
+	if req.DestinationRoom == req.Room {
+		return nil, ErrMoveToSameRoom
+	}
+	limitConf := roomLimitConfig(s.config, livekit.RoomName(req.DestinationRoom))
+	if !limitConf.CheckRoomNameLength(req.DestinationRoom) {
+		return nil, fmt.Errorf("%w: max length %d", ErrRoomNameExceedsLimits, limitConf.MaxRoomNameLength)
+	}
+
+	if _, err := s.roomStore.LoadParticipant(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity)); err == ErrParticipantNotFound {
+		return nil, twirp.NotFoundError("participant not found")
+	}
+
+	return s.extensionClient.MoveParticipant(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+// ForwardTrack publishes a track into another room as a virtual participant. The destination room
+// may be hosted on another node when rtc.relay is enabled on both nodes, otherwise it has to be hosted
+// on the node of the source room
+func (s *RoomService) ForwardTrack(ctx context.Context, req *ForwardTrackRequest) (*ForwardTrackResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", req.TrackSid, "destinationRoom", req.DestinationRoom)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	// destination room is created when it does not exist yet
+	if err := EnsureCreatePermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	if req.Identity == "" {
+		return nil, ErrIdentityEmpty
+	}
+	if req.TrackSid == "" {
+		return nil, ErrTrackNotFound
+	}
+	if req.DestinationRoom == req.Room {
+		return nil, ErrForwardToSameRoom
+	}
+	limitConf := roomLimitConfig(s.config, livekit.RoomName(req.DestinationRoom))
+	if !limitConf.CheckRoomNameLength(req.DestinationRoom) {
+		return nil, fmt.Errorf("%w: max length %d", ErrRoomNameExceedsLimits, limitConf.MaxRoomNameLength)
+	}
+
+	return s.extensionClient.ForwardTrack(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) StopForwardTrack(ctx context.Context, req *StopForwardTrackRequest) (*StopForwardTrackResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", req.TrackSid, "destinationRoom", req.DestinationRoom)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	return s.extensionClient.StopForwardTrack(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) UpdateParticipantPolicy(ctx context.Context, req *UpdateParticipantPolicyRequest) (*UpdateParticipantPolicyResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "maxDuration", req.MaxDurationSeconds, "idleTimeout", req.IdleTimeoutSeconds, "warningBefore", req.WarningBeforeSeconds)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	return s.extensionClient.UpdateParticipantPolicy(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+// UpdateRoomLobby turns the lobby of a room on or off
+func (s *RoomService) UpdateRoomLobby(ctx context.Context, req *UpdateRoomLobbyRequest) (*UpdateRoomLobbyResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "enabled", req.Enabled)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	return s.extensionClient.UpdateRoomLobby(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) ListLobbyParticipants(ctx context.Context, req *ListLobbyParticipantsRequest) (*ListLobbyParticipantsResponse, error) {
+	AppendLogFields(ctx, "room", req.Room)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	return s.extensionClient.ListLobbyParticipants(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+// AdmitParticipant lets a participant waiting in the lobby into the room
+func (s *RoomService) AdmitParticipant(ctx context.Context, req *AdmitParticipantRequest) (*AdmitParticipantResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity)
+
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	return s.extensionClient.AdmitParticipant(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+// CreateRoomSchedule creates or replaces the schedule of a room. A room that is already
+// running picks up the new schedule right away.
+func (s *RoomService) CreateRoomSchedule(ctx context.Context, req *RoomSchedule) (*RoomSchedule, error) {
+	AppendLogFields(ctx, "room", req.Room, "startTime", req.StartTime, "endTime", req.EndTime, "recurrence", req.Recurrence)
+
+	if err := EnsureCreatePermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	limitConf := roomLimitConfig(s.config, livekit.RoomName(req.Room))
+	if !limitConf.CheckRoomNameLength(req.Room) {
+		return nil, fmt.Errorf("%w: max length %d", ErrRoomNameExceedsLimits, limitConf.MaxRoomNameLength)
+	}
+	if !limitConf.CheckMetadataSize(req.Metadata) {
+		return nil, twirp.InvalidArgumentError(ErrMetadataExceedsLimits.Error(), strconv.Itoa(int(limitConf.MaxMetadataSize)))
+	}
+
+	if err := s.roomStore.StoreRoomSchedule(ctx, req); err != nil {
+		return nil, err
+	}
+	s.applyRoomSchedule(ctx, livekit.RoomName(req.Room))
+	return req, nil
+}
+
+func (s *RoomService) ListRoomSchedules(ctx context.Context, req *ListRoomSchedulesRequest) (*ListRoomSchedulesResponse, error) {
+	AppendLogFields(ctx, "room", req.Names)
+
+	if err := EnsureListPermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	var names []livekit.RoomName
+	if len(req.Names) != 0 {
+		names = livekit.StringsAsIDs[livekit.RoomName](req.Names)
+	}
+	schedules, err := s.roomStore.ListRoomSchedules(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	if tenant := GetTenant(ctx); tenant != "" {
+		schedules = slices.DeleteFunc(schedules, func(schedule *RoomSchedule) bool {
+			return tenantForRoomName(schedule.Room) != tenant
+		})
+	}
+	return &ListRoomSchedulesResponse{Schedules: schedules}, nil
+}
+
+func (s *RoomService) DeleteRoomSchedule(ctx context.Context, req *DeleteRoomScheduleRequest) (*DeleteRoomScheduleResponse, error) {
+	AppendLogFields(ctx, "room", req.Room)
+
+	if err := EnsureCreatePermission(ctx); err != nil {
+		return nil, twirpAuthError(err)
+	}
+
+	if _, err := s.roomStore.LoadRoomSchedule(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
+	if err := s.roomStore.DeleteRoomSchedule(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
+	s.applyRoomSchedule(ctx, livekit.RoomName(req.Room))
+	return &DeleteRoomScheduleResponse{}, nil
+}
+
+// applyRoomSchedule notifies the node hosting a running room of schedule changes
+func (s *RoomService) applyRoomSchedule(ctx context.Context, roomName livekit.RoomName) {
+	if _, _, err := s.roomStore.LoadRoom(ctx, roomName, false); err != nil {
+		return
+	}
+	if _, err := s.extensionClient.ApplyRoomSchedule(ctx, s.topicFormatter.RoomTopic(ctx, roomName), &ApplyRoomScheduleRequest{Room: string(roomName)}); err != nil {
+		logger.Warnw("could not apply room schedule", err, "room", roomName)
+	}
+}
+
 func (s *RoomService) MutePublishedTrack(ctx context.Context, req *livekit.MuteRoomTrackRequest) (*livekit.MuteRoomTrackResponse, error) {
 	RecordRequest(ctx, req)
 
@@ -222,16 +416,17 @@ func (s *RoomService) UpdateParticipant(ctx context.Context, req *livekit.Update
 
 	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity)
 
-	if !s.limitConf.CheckParticipantNameLength(req.Name) {
-		return nil, twirp.InvalidArgumentError(ErrNameExceedsLimits.Error(), strconv.Itoa(s.limitConf.MaxParticipantNameLength))
+	limitConf := roomLimitConfig(s.config, livekit.RoomName(req.Room))
+	if !limitConf.CheckParticipantNameLength(req.Name) {
+		return nil, twirp.InvalidArgumentError(ErrNameExceedsLimits.Error(), strconv.Itoa(limitConf.MaxParticipantNameLength))
 	}
 
-	if !s.limitConf.CheckMetadataSize(req.Metadata) {
-		return nil, twirp.InvalidArgumentError(ErrMetadataExceedsLimits.Error(), strconv.Itoa(int(s.limitConf.MaxMetadataSize)))
+	if !limitConf.CheckMetadataSize(req.Metadata) {
+		return nil, twirp.InvalidArgumentError(ErrMetadataExceedsLimits.Error(), strconv.Itoa(int(limitConf.MaxMetadataSize)))
 	}
 
-	if !s.limitConf.CheckAttributesSize(req.Attributes) {
-		return nil, twirp.InvalidArgumentError(ErrAttributeExceedsLimits.Error(), strconv.Itoa(int(s.limitConf.MaxAttributesSize)))
+	if !limitConf.CheckAttributesSize(req.Attributes) {
+		return nil, twirp.InvalidArgumentError(ErrAttributeExceedsLimits.Error(), strconv.Itoa(int(limitConf.MaxAttributesSize)))
 	}
 
 	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
@@ -284,7 +479,8 @@ func (s *RoomService) UpdateRoomMetadata(ctx context.Context, req *livekit.Updat
 	RecordRequest(ctx, redactUpdateRoomMetadataRequest(req))
 
 	AppendLogFields(ctx, "room", req.Room, "size", len(req.Metadata))
-	maxMetadataSize := int(s.limitConf.MaxMetadataSize)
+	limitConf := roomLimitConfig(s.config, livekit.RoomName(req.Room))
+	maxMetadataSize := int(limitConf.MaxMetadataSize)
 	if maxMetadataSize > 0 && len(req.Metadata) > maxMetadataSize {
 		return nil, twirp.InvalidArgumentError(ErrMetadataExceedsLimits.Error(), strconv.Itoa(maxMetadataSize))
 	}
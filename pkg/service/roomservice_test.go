@@ -17,6 +17,7 @@ package service_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/twitchtv/twirp"
@@ -46,6 +47,144 @@ func TestDeleteRoom(t *testing.T) {
 	})
 }
 
+func TestMoveParticipant(t *testing.T) {
+	t.Run("missing create permission", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		grant := &auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomAdmin: true, Room: "testroom"},
+		}
+		ctx := service.WithGrants(context.Background(), grant, "")
+		_, err := svc.MoveParticipant(ctx, &service.MoveParticipantRequest{
+			Room:            "testroom",
+			Identity:        "p1",
+			DestinationRoom: "breakout",
+		})
+		terr, ok := err.(twirp.Error)
+		require.True(t, ok)
+		require.Equal(t, twirp.Unauthenticated, terr.Code())
+	})
+
+	t.Run("same room", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		grant := &auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomAdmin: true, RoomCreate: true, Room: "testroom"},
+		}
+		ctx := service.WithGrants(context.Background(), grant, "")
+		_, err := svc.MoveParticipant(ctx, &service.MoveParticipantRequest{
+			Room:            "testroom",
+			Identity:        "p1",
+			DestinationRoom: "testroom",
+		})
+		require.ErrorIs(t, err, service.ErrMoveToSameRoom)
+	})
+}
+
+func TestForwardTrack(t *testing.T) {
+	t.Run("missing admin permission", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		grant := &auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomCreate: true},
+		}
+		ctx := service.WithGrants(context.Background(), grant, "")
+		_, err := svc.ForwardTrack(ctx, &service.ForwardTrackRequest{
+			Room:            "stage",
+			Identity:        "speaker",
+			TrackSid:        "TR_1",
+			DestinationRoom: "overflow",
+		})
+		terr, ok := err.(twirp.Error)
+		require.True(t, ok)
+		require.Equal(t, twirp.Unauthenticated, terr.Code())
+	})
+
+	t.Run("same room", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		grant := &auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomAdmin: true, RoomCreate: true, Room: "stage"},
+		}
+		ctx := service.WithGrants(context.Background(), grant, "")
+		_, err := svc.ForwardTrack(ctx, &service.ForwardTrackRequest{
+			Room:            "stage",
+			Identity:        "speaker",
+			TrackSid:        "TR_1",
+			DestinationRoom: "stage",
+		})
+		require.ErrorIs(t, err, service.ErrForwardToSameRoom)
+	})
+}
+
+func TestUpdateParticipantPolicy(t *testing.T) {
+	t.Run("missing admin permission", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		grant := &auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomAdmin: true, Room: "other"},
+		}
+		ctx := service.WithGrants(context.Background(), grant, "")
+		_, err := svc.UpdateParticipantPolicy(ctx, &service.UpdateParticipantPolicyRequest{
+			Room:               "stage",
+			MaxDurationSeconds: 3600,
+		})
+		terr, ok := err.(twirp.Error)
+		require.True(t, ok)
+		require.Equal(t, twirp.Unauthenticated, terr.Code())
+	})
+}
+
+func TestAdmitParticipant(t *testing.T) {
+	t.Run("missing admin permission", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		grant := &auth.ClaimGrants{
+			Video: &auth.VideoGrant{RoomJoin: true, Room: "stage"},
+		}
+		ctx := service.WithGrants(context.Background(), grant, "")
+		_, err := svc.AdmitParticipant(ctx, &service.AdmitParticipantRequest{
+			Room:     "stage",
+			Identity: "guest",
+		})
+		terr, ok := err.(twirp.Error)
+		require.True(t, ok)
+		require.Equal(t, twirp.Unauthenticated, terr.Code())
+	})
+}
+
+func TestCreateRoomSchedule(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+	schedule := &service.RoomSchedule{
+		Room:      "standup",
+		StartTime: start.Unix(),
+		EndTime:   start.Add(15 * time.Minute).Unix(),
+	}
+
+	t.Run("missing create permission", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		ctx := service.WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{}}, "")
+		_, err := svc.CreateRoomSchedule(ctx, schedule)
+		terr, ok := err.(twirp.Error)
+		require.True(t, ok)
+		require.Equal(t, twirp.Unauthenticated, terr.Code())
+	})
+
+	t.Run("invalid schedule", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		ctx := service.WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{RoomCreate: true}}, "")
+		_, err := svc.CreateRoomSchedule(ctx, &service.RoomSchedule{Room: "standup"})
+		require.ErrorIs(t, err, service.ErrInvalidRoomSchedule)
+		require.Equal(t, 0, svc.store.StoreRoomScheduleCallCount())
+	})
+
+	t.Run("schedule is stored", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		svc.store.LoadRoomReturns(nil, nil, service.ErrRoomNotFound)
+		ctx := service.WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{RoomCreate: true}}, "")
+		res, err := svc.CreateRoomSchedule(ctx, schedule)
+		require.NoError(t, err)
+		require.Equal(t, schedule, res)
+		require.Equal(t, 1, svc.store.StoreRoomScheduleCallCount())
+		_, stored := svc.store.StoreRoomScheduleArgsForCall(0)
+		require.Equal(t, schedule, stored)
+	})
+}
+
 func TestMetaDataLimits(t *testing.T) {
 	t.Run("metadata exceed limits", func(t *testing.T) {
 		svc := newTestRoomService(config.LimitConfig{MaxMetadataSize: 5})
@@ -108,8 +247,9 @@ func newTestRoomService(limitConf config.LimitConfig) *TestRoomService {
 	router := &routingfakes.FakeRouter{}
 	allocator := &servicefakes.FakeRoomAllocator{}
 	store := &servicefakes.FakeServiceStore{}
+	store.LoadRoomScheduleReturns(nil, service.ErrRoomScheduleNotFound)
 	svc, err := service.NewRoomService(
-		limitConf,
+		&config.Config{Limit: limitConf},
 		config.APIConfig{ExecutionTimeout: 2},
 		router,
 		allocator,
@@ -118,6 +258,7 @@ func newTestRoomService(limitConf config.LimitConfig) *TestRoomService {
 		rpc.NewTopicFormatter(),
 		&rpcfakes.FakeTypedRoomClient{},
 		&rpcfakes.FakeTypedParticipantClient{},
+		nil,
 	)
 	if err != nil {
 		panic(err)
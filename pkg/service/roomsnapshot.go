@@ -0,0 +1,134 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc"
+)
+
+// roomSnapshotter periodically stores the snapshot of a room hosted on this node, so the room can be restored
+// on another node if this one fails
+type roomSnapshotter struct {
+	store RoomSnapshotStore
+	room  *rtc.Room
+	conf  config.RoomSnapshotConfig
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newRoomSnapshotter(store RoomSnapshotStore, room *rtc.Room, conf config.RoomSnapshotConfig) *roomSnapshotter {
+	s := &roomSnapshotter{
+		store: store,
+		room:  room,
+		conf:  conf,
+		done:  make(chan struct{}),
+	}
+	go s.worker()
+	return s
+}
+
+func (s *roomSnapshotter) worker() {
+	ticker := time.NewTicker(s.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.store.StoreRoomSnapshot(context.Background(), s.room.Snapshot(), s.conf.Retention); err != nil {
+				s.room.Logger.Warnw("could not store room snapshot", err)
+			}
+		}
+	}
+}
+
+// Close stops snapshotting and deletes the snapshot, the room ended and is not to be restored
+func (s *roomSnapshotter) Close() {
+	s.stop()
+	if err := s.store.DeleteRoomSnapshot(context.Background(), s.room.Name()); err != nil {
+		s.room.Logger.Warnw("could not delete room snapshot", err)
+	}
+}
+
+// Handoff stops snapshotting and stores a final snapshot for the node the room moves to. It is called before
+// the room is placed on that node, so a periodic snapshot can not overwrite the final one after it was restored.
+func (s *roomSnapshotter) Handoff() {
+	s.stop()
+	if err := s.store.StoreRoomSnapshot(context.Background(), s.room.Snapshot(), s.conf.Retention); err != nil {
+		s.room.Logger.Warnw("could not store room snapshot", err)
+	}
+}
+
+// Stop stops snapshotting and keeps the stored snapshot
+func (s *roomSnapshotter) Stop() {
+	s.stop()
+}
+
+func (s *roomSnapshotter) stop() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// restoreRoomSnapshot returns the snapshot of a room that was hosted by a node that failed. When the room
+// itself was lost from the store, it is recreated from the snapshot.
+func (r *RoomManager) restoreRoomSnapshot(
+	ctx context.Context,
+	ri *livekit.Room,
+	internal *livekit.RoomInternal,
+	created bool,
+) (*livekit.Room, *livekit.RoomInternal, *rtc.RoomSnapshot) {
+	snapshot, err := r.roomStore.LoadRoomSnapshot(ctx, livekit.RoomName(ri.Name))
+	if err != nil {
+		if !errors.Is(err, ErrRoomSnapshotNotFound) {
+			logger.Warnw("could not load room snapshot", err, "room", ri.Name)
+		}
+		return ri, internal, nil
+	}
+
+	if !created {
+		if snapshot.Room.Sid != ri.Sid {
+			// left behind by an earlier session of the room
+			return ri, internal, nil
+		}
+		// the stored room is kept up to date, only state missing from it is restored
+		return ri, internal, snapshot
+	}
+
+	ri = utils.CloneProto(snapshot.Room)
+	ri.NumParticipants = 0
+	ri.NumPublishers = 0
+	ri.ActiveRecording = false
+	if snapshot.Internal != nil {
+		internal = snapshot.Internal
+	}
+	if err = r.roomStore.StoreRoom(ctx, ri, internal); err != nil {
+		logger.Warnw("could not store restored room", err, "room", ri.Name)
+	}
+	logger.Infow("restoring room from snapshot", "room", ri.Name, "roomID", ri.Sid, "snapshotAt", snapshot.CreatedAt)
+	return ri, internal, snapshot
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/service"
+)
+
+func TestRoomSnapshotStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) service.RoomSnapshotStore{
+		"local": func(t *testing.T) service.RoomSnapshotStore {
+			return service.NewLocalStore()
+		},
+		"redis": func(t *testing.T) service.RoomSnapshotStore {
+			return redisStoreDocker(t)
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+			roomName := livekit.RoomName(fmt.Sprintf("snapshot-%d", time.Now().UnixNano()))
+
+			_, err := store.LoadRoomSnapshot(ctx, roomName)
+			require.ErrorIs(t, err, service.ErrRoomSnapshotNotFound)
+
+			snapshot := &rtc.RoomSnapshot{
+				Room:     &livekit.Room{Sid: "RM_snapshot", Name: string(roomName), Metadata: "metadata"},
+				Internal: &livekit.RoomInternal{SyncStreams: true},
+				AgentDispatches: []*livekit.AgentDispatch{
+					{Id: "AD_1", AgentName: "agent", Room: string(roomName)},
+				},
+				SubscriptionPermissions: map[livekit.ParticipantIdentity]*livekit.SubscriptionPermission{
+					"publisher": {AllParticipants: false},
+				},
+				CreatedAt: time.UnixMilli(time.Now().UnixMilli()),
+			}
+			require.NoError(t, store.StoreRoomSnapshot(ctx, snapshot, time.Minute))
+
+			loaded, err := store.LoadRoomSnapshot(ctx, roomName)
+			require.NoError(t, err)
+			require.Equal(t, "metadata", loaded.Room.Metadata)
+			require.True(t, loaded.Internal.SyncStreams)
+			require.Len(t, loaded.AgentDispatches, 1)
+			require.Equal(t, "agent", loaded.AgentDispatches[0].AgentName)
+			require.Contains(t, loaded.SubscriptionPermissions, livekit.ParticipantIdentity("publisher"))
+			require.True(t, snapshot.CreatedAt.Equal(loaded.CreatedAt))
+
+			// replaced as a whole
+			snapshot.AgentDispatches = nil
+			require.NoError(t, store.StoreRoomSnapshot(ctx, snapshot, time.Minute))
+			loaded, err = store.LoadRoomSnapshot(ctx, roomName)
+			require.NoError(t, err)
+			require.Empty(t, loaded.AgentDispatches)
+
+			require.NoError(t, store.DeleteRoomSnapshot(ctx, roomName))
+			_, err = store.LoadRoomSnapshot(ctx, roomName)
+			require.ErrorIs(t, err, service.ErrRoomSnapshotNotFound)
+		})
+	}
+}
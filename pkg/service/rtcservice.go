@@ -49,7 +49,6 @@ type RTCService struct {
 	currentNode   routing.LocalNode
 	config        *config.Config
 	isDev         bool
-	limits        config.LimitConfig
 	parser        *uaparser.Parser
 	telemetry     telemetry.TelemetryService
 
@@ -72,7 +71,6 @@ func NewRTCService(
 		currentNode:   currentNode,
 		config:        conf,
 		isDev:         conf.Development,
-		limits:        conf.Limit,
 		parser:        uaparser.NewFromSaved(),
 		telemetry:     telemetry,
 		connections:   map[*websocket.Conn]struct{}{},
@@ -96,15 +94,24 @@ func (s *RTCService) SetupRoutes(mux *http.ServeMux) {
 }
 
 func (s *RTCService) validate(w http.ResponseWriter, r *http.Request) {
-	_, _, code, err := s.validateInternal(r)
+	_, _, code, err := s.validateInternal(r, false)
 	if err != nil {
-		handleError(w, r, code, err)
+		s.handleJoinError(w, r, code, err)
 		return
 	}
 	_, _ = w.Write([]byte("success"))
 }
 
-func (s *RTCService) validateInternal(r *http.Request) (livekit.RoomName, routing.ParticipantInit, int, error) {
+// handleJoinError tells clients refused by admission control when to retry
+func (s *RTCService) handleJoinError(w http.ResponseWriter, r *http.Request, status int, err error, keysAndValues ...interface{}) {
+	if errors.Is(err, ErrNodeOverloaded) && s.config.Limit.Admission.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(s.config.Limit.Admission.RetryAfter.Seconds())))
+	}
+	handleError(w, r, status, err, keysAndValues...)
+}
+
+// validateInternal checks a join request, join is false for a dry run that does not reserve capacity for the participant
+func (s *RTCService) validateInternal(r *http.Request, join bool) (livekit.RoomName, routing.ParticipantInit, int, error) {
 	claims := GetGrants(r.Context())
 	var pi routing.ParticipantInit
 
@@ -121,9 +128,6 @@ func (s *RTCService) validateInternal(r *http.Request) (livekit.RoomName, routin
 	if claims.Identity == "" {
 		return "", pi, http.StatusBadRequest, ErrIdentityEmpty
 	}
-	if limit := s.config.Limit.MaxParticipantIdentityLength; limit > 0 && len(claims.Identity) > limit {
-		return "", pi, http.StatusBadRequest, fmt.Errorf("%w: max length %d", ErrParticipantIdentityExceedsLimits, limit)
-	}
 
 	roomName := livekit.RoomName(r.FormValue("room"))
 	reconnectParam := r.FormValue("reconnect")
@@ -138,7 +142,12 @@ func (s *RTCService) validateInternal(r *http.Request) (livekit.RoomName, routin
 	if onlyName != "" {
 		roomName = onlyName
 	}
-	if limit := s.config.Limit.MaxRoomNameLength; limit > 0 && len(roomName) > limit {
+	roomName = TenantRoomName(GetTenant(r.Context()), roomName)
+	limits := roomLimitConfig(s.config, roomName)
+	if limit := limits.MaxParticipantIdentityLength; limit > 0 && len(claims.Identity) > limit {
+		return "", pi, http.StatusBadRequest, fmt.Errorf("%w: max length %d", ErrParticipantIdentityExceedsLimits, limit)
+	}
+	if limit := limits.MaxRoomNameLength; limit > 0 && len(roomName) > limit {
 		return "", pi, http.StatusBadRequest, fmt.Errorf("%w: max length %d", ErrRoomNameExceedsLimits, limit)
 	}
 
@@ -158,6 +167,8 @@ func (s *RTCService) validateInternal(r *http.Request) (livekit.RoomName, routin
 	if err != nil {
 		if errors.Is(err, ErrRoomNotFound) {
 			return "", pi, http.StatusNotFound, err
+		} else if errors.Is(err, ErrRoomNotStarted) || errors.Is(err, ErrRoomScheduleEnded) {
+			return "", pi, http.StatusForbidden, err
 		} else {
 			return "", pi, http.StatusInternalServerError, err
 		}
@@ -167,9 +178,18 @@ func (s *RTCService) validateInternal(r *http.Request) (livekit.RoomName, routin
 	if router, ok := s.router.(routing.Router); ok {
 		region = router.GetRegion()
 		if foundNode, err := router.GetNodeForRoom(r.Context(), roomName); err == nil {
-			if selector.LimitsReached(s.limits, foundNode.Stats) {
+			if selector.LimitsReached(limits, foundNode.Stats) {
 				return "", pi, http.StatusServiceUnavailable, rtc.ErrLimitExceeded
 			}
+			// reconnecting participants are already part of the load of the node
+			if !boolValue(reconnectParam) {
+				if err := s.roomAllocator.AdmitParticipant(r.Context(), roomName, foundNode, claims, join); err != nil {
+					if errors.Is(err, ErrNodeOverloaded) {
+						return "", pi, http.StatusServiceUnavailable, err
+					}
+					return "", pi, http.StatusInternalServerError, err
+				}
+			}
 		}
 	}
 
@@ -189,6 +209,7 @@ func (s *RTCService) validateInternal(r *http.Request) (livekit.RoomName, routin
 		Grants:          claims,
 		Region:          region,
 		CreateRoom:      createRequest,
+		PolicyClaim:     GetParticipantPolicyClaim(r.Context()),
 	}
 	if pi.Reconnect {
 		pi.ID = livekit.ParticipantID(participantID)
@@ -218,9 +239,9 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	roomName, pi, code, err := s.validateInternal(r)
+	roomName, pi, code, err := s.validateInternal(r, true)
 	if err != nil {
-		handleError(w, r, code, err)
+		s.handleJoinError(w, r, code, err)
 		return
 	}
 
@@ -238,7 +259,7 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	for attempt := 0; attempt < s.config.SignalRelay.ConnectAttempts; attempt++ {
 		connectionTimeout := 3 * time.Second * time.Duration(attempt+1)
 		ctx := utils.ContextWithAttempt(r.Context(), attempt)
-		cr, initialResponse, err = s.startConnection(ctx, roomName, pi, connectionTimeout)
+		cr, initialResponse, err = s.startConnection(ctx, roomName, pi, connectionTimeout, nil)
 		if err == nil || errors.Is(err, context.Canceled) {
 			break
 		}
@@ -251,7 +272,7 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if errors.As(err, &psrpcErr) {
 			status = psrpcErr.ToHttp()
 		}
-		handleError(w, r, status, err, loggerFields...)
+		s.handleJoinError(w, r, status, err, loggerFields...)
 		return
 	}
 
@@ -273,17 +294,33 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	// follow the session so it could be resumed on another node if the one hosting the participant fails
+	session := routing.NewSignalSession(pi)
+	session.ObserveResponse(initialResponse)
+
+	// the connection is replaced when the participant is resumed on another node
+	var crLock sync.Mutex
+	currentConnection := func() connectionResult {
+		crLock.Lock()
+		defer crLock.Unlock()
+		return cr
+	}
+
 	closedByClient := atomic.NewBool(false)
 	done := make(chan struct{})
 	// function exits when websocket terminates, it'll close the event reading off of request sink and response source as well
 	defer func() {
+		crLock.Lock()
+		close(done)
+		lastCR := cr
+		crLock.Unlock()
+
 		pLogger.Debugw("finishing WS connection",
-			"connID", cr.ConnectionID,
+			"connID", lastCR.ConnectionID,
 			"closedByClient", closedByClient.Load(),
 		)
-		cr.ResponseSource.Close()
-		cr.RequestSink.Close()
-		close(done)
+		lastCR.ResponseSource.Close()
+		lastCR.RequestSink.Close()
 
 		signalStats.Stop()
 	}()
@@ -338,23 +375,49 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 		}()
 		for {
+			current := currentConnection()
 			select {
 			case <-done:
 				return
-			case msg := <-cr.ResponseSource.ReadChan():
+			case msg := <-current.ResponseSource.ReadChan():
 				if msg == nil {
-					pLogger.Debugw("nothing to read from response source", "connID", cr.ConnectionID)
-					return
+					if !s.shouldResumeConnection(current.ResponseSource, session) {
+						pLogger.Debugw("nothing to read from response source", "connID", current.ConnectionID)
+						return
+					}
+
+					pLogger.Infow("RTC node unreachable, resuming participant", "connID", current.ConnectionID, "nodeID", current.NodeID)
+					resumed, resumeResponse, err := s.resumeConnection(r.Context(), roomName, pi, session)
+					if err != nil {
+						pLogger.Warnw("could not resume participant", err, "connID", current.ConnectionID)
+						return
+					}
+
+					crLock.Lock()
+					select {
+					case <-done:
+						crLock.Unlock()
+						resumed.RequestSink.Close()
+						resumed.ResponseSource.Close()
+						return
+					default:
+						cr = resumed
+					}
+					crLock.Unlock()
+
+					pLogger.Infow("resumed participant", "connID", resumed.ConnectionID, "nodeID", resumed.NodeID)
+					msg = resumeResponse
 				}
 				res, ok := msg.(*livekit.SignalResponse)
 				if !ok {
 					pLogger.Errorw(
 						"unexpected message type", nil,
 						"type", fmt.Sprintf("%T", msg),
-						"connID", cr.ConnectionID,
+						"connID", current.ConnectionID,
 					)
 					continue
 				}
+				session.ObserveResponse(res)
 
 				switch m := res.Message.(type) {
 				case *livekit.SignalResponse_Offer:
@@ -389,7 +452,7 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			if IsWebSocketCloseError(err) {
 				closedByClient.Store(true)
 			} else {
-				pLogger.Errorw("error reading from websocket", err, "connID", cr.ConnectionID)
+				pLogger.Errorw("error reading from websocket", err, "connID", currentConnection().ConnectionID)
 			}
 			return
 		}
@@ -430,8 +493,16 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			pLogger.Debugw("received answer", "answer", m)
 		}
 
-		if err := cr.RequestSink.WriteMessage(req); err != nil {
-			pLogger.Warnw("error writing to request sink", err, "connID", cr.ConnectionID)
+		session.ObserveRequest(req)
+
+		current := currentConnection()
+		if err := current.RequestSink.WriteMessage(req); err != nil {
+			if s.config.SignalRelay.ResumeOnNodeFailure && session.CanResume() {
+				// the response side resumes the participant, the request is part of the state synced then
+				pLogger.Debugw("dropping request while RTC node is unreachable", "error", err, "connID", current.ConnectionID)
+				continue
+			}
+			pLogger.Warnw("error writing to request sink", err, "connID", current.ConnectionID)
 			return
 		}
 	}
@@ -525,6 +596,11 @@ func (s *RTCService) DrainConnections(interval time.Duration) {
 	}
 }
 
+// edgeSignalRouter starts signal connections on a node other than the one hosting the room
+type edgeSignalRouter interface {
+	StartParticipantSignalWithNodeID(ctx context.Context, roomName livekit.RoomName, pi routing.ParticipantInit, nodeID livekit.NodeID) (routing.StartParticipantSignalResults, error)
+}
+
 type connectionResult struct {
 	routing.StartParticipantSignalResults
 	Room *livekit.Room
@@ -535,6 +611,7 @@ func (s *RTCService) startConnection(
 	roomName livekit.RoomName,
 	pi routing.ParticipantInit,
 	timeout time.Duration,
+	syncState *livekit.SyncState,
 ) (connectionResult, *livekit.SignalResponse, error) {
 	var cr connectionResult
 	var err error
@@ -543,12 +620,34 @@ func (s *RTCService) startConnection(
 		return cr, nil, err
 	}
 
+	// participants far from the node hosting the room join an edge node in their region instead
+	edgeNodeID, err := s.roomAllocator.SelectParticipantNode(ctx, roomName, pi.Client.GetAddress())
+	if err != nil {
+		return cr, nil, err
+	}
+
 	// this needs to be started first *before* using router functions on this node
-	cr.StartParticipantSignalResults, err = s.router.StartParticipantSignal(ctx, roomName, pi)
+	if router, ok := s.router.(edgeSignalRouter); ok && edgeNodeID != "" {
+		cr.StartParticipantSignalResults, err = router.StartParticipantSignalWithNodeID(ctx, roomName, pi, edgeNodeID)
+		cr.NodeSelectionReason = "edge"
+	} else {
+		cr.StartParticipantSignalResults, err = s.router.StartParticipantSignal(ctx, roomName, pi)
+	}
 	if err != nil {
 		return cr, nil, err
 	}
 
+	if syncState != nil {
+		err = cr.RequestSink.WriteMessage(&livekit.SignalRequest{
+			Message: &livekit.SignalRequest_SyncState{SyncState: syncState},
+		})
+		if err != nil {
+			cr.RequestSink.Close()
+			cr.ResponseSource.Close()
+			return cr, nil, err
+		}
+	}
+
 	// wait for the first message before upgrading to websocket. If no one is
 	// responding to our connection attempt, we should terminate the connection
 	// instead of waiting forever on the WebSocket
@@ -563,6 +662,44 @@ func (s *RTCService) startConnection(
 	return cr, initialResponse, nil
 }
 
+// shouldResumeConnection returns true when the response source failed rather than being closed,
+// i.e. the RTC node hosting the participant became unreachable while it was still in the room
+func (s *RTCService) shouldResumeConnection(source routing.MessageSource, session *routing.SignalSession) bool {
+	if !s.config.SignalRelay.ResumeOnNodeFailure || !session.CanResume() {
+		return false
+	}
+	failed, ok := source.(interface{ Err() error })
+	return ok && failed.Err() != nil
+}
+
+// resumeConnection re-establishes the participant on another RTC node with the state followed by the
+// signal node, the client only has to go through an ICE restart
+func (s *RTCService) resumeConnection(
+	ctx context.Context,
+	roomName livekit.RoomName,
+	pi routing.ParticipantInit,
+	session *routing.SignalSession,
+) (connectionResult, *livekit.SignalResponse, error) {
+	pi.Reconnect = true
+	pi.ReconnectReason = livekit.ReconnectReason_RR_SIGNAL_DISCONNECTED
+	pi.ID = session.ParticipantID()
+
+	// the failed node is only replaced once its stats become stale, retry until then
+	deadline := time.Now().Add(s.config.SignalRelay.ResumeTimeout)
+	for {
+		cr, res, err := s.startConnection(ctx, roomName, pi, s.config.SignalRelay.MaxRetryInterval, session.SyncState())
+		if err == nil || errors.Is(err, context.Canceled) || time.Now().After(deadline) {
+			return cr, res, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return cr, nil, ctx.Err()
+		case <-time.After(s.config.SignalRelay.MinRetryInterval):
+		}
+	}
+}
+
 func readInitialResponse(source routing.MessageSource, timeout time.Duration) (*livekit.SignalResponse, error) {
 	responseTimer := time.NewTimer(timeout)
 	defer responseTimer.Stop()
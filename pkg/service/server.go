@@ -29,6 +29,7 @@ import (
 
 	"github.com/pion/turn/v4"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/cors"
 	"github.com/twitchtv/twirp"
 	"github.com/urfave/negroni/v3"
@@ -42,12 +43,14 @@ import (
 
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/version"
 )
 
 type LivekitServer struct {
 	config       *config.Config
 	ioService    *IOInfoService
+	telemetry    telemetry.TelemetryService
 	rtcService   *RTCService
 	agentService *AgentService
 	httpServer   *http.Server
@@ -57,18 +60,20 @@ type LivekitServer struct {
 	signalServer *SignalServer
 	turnServer   *turn.Server
 	currentNode  routing.LocalNode
+	health       *healthChecker
 	running      atomic.Bool
 	doneChan     chan struct{}
 	closedChan   chan struct{}
 }
 
 func NewLivekitServer(conf *config.Config,
-	roomService livekit.RoomService,
+	roomService *RoomService,
 	agentDispatchService *AgentDispatchService,
 	egressService *EgressService,
 	ingressService *IngressService,
 	sipService *SIPService,
 	ioService *IOInfoService,
+	telemetryService telemetry.TelemetryService,
 	rtcService *RTCService,
 	agentService *AgentService,
 	keyProvider auth.KeyProvider,
@@ -77,10 +82,12 @@ func NewLivekitServer(conf *config.Config,
 	signalServer *SignalServer,
 	turnServer *turn.Server,
 	currentNode routing.LocalNode,
+	redisClient redis.UniversalClient,
 ) (s *LivekitServer, err error) {
 	s = &LivekitServer{
 		config:       conf,
 		ioService:    ioService,
+		telemetry:    telemetryService,
 		rtcService:   rtcService,
 		agentService: agentService,
 		router:       router,
@@ -91,6 +98,15 @@ func NewLivekitServer(conf *config.Config,
 		currentNode: currentNode,
 		closedChan:  make(chan struct{}),
 	}
+	s.health = &healthChecker{
+		conf:        conf,
+		currentNode: currentNode,
+		router:      router,
+		redisClient: redisClient,
+		turnServer:  turnServer,
+		version:     version.Version,
+		isRunning:   s.IsRunning,
+	}
 
 	middlewares := []negroni.Handler{
 		// always first
@@ -109,12 +125,20 @@ func NewLivekitServer(conf *config.Config,
 	if keyProvider != nil {
 		middlewares = append(middlewares, NewAPIKeyAuthMiddleware(keyProvider))
 	}
+	if len(conf.Tenants) != 0 {
+		if err = ValidateTenants(conf); err != nil {
+			return nil, err
+		}
+		middlewares = append(middlewares, NewTenantMiddleware(conf))
+	}
 
+	serverHooks := twirp.ChainHooks(
+		TwirpLogger(),
+		TwirpRequestStatusReporter(),
+	)
 	serverOptions := []interface{}{
-		twirp.WithServerHooks(twirp.ChainHooks(
-			TwirpLogger(),
-			TwirpRequestStatusReporter(),
-		)),
+		twirp.WithServerHooks(serverHooks),
+		twirp.WithServerInterceptors(TenantInterceptor()),
 	}
 	for _, opt := range xtwirp.DefaultServerOptions() {
 		serverOptions = append(serverOptions, opt)
@@ -138,9 +162,30 @@ func NewLivekitServer(conf *config.Config,
 	xtwirp.RegisterServer(mux, egressServer)
 	xtwirp.RegisterServer(mux, ingressServer)
 	xtwirp.RegisterServer(mux, sipServer)
+	for _, m := range []*twirpJSONMethod{
+		newTwirpJSONMethod("RoomService", "MoveParticipant", serverHooks, roomService.MoveParticipant),
+		newTwirpJSONMethod("RoomService", "ForwardTrack", serverHooks, roomService.ForwardTrack),
+		newTwirpJSONMethod("RoomService", "StopForwardTrack", serverHooks, roomService.StopForwardTrack),
+		newTwirpJSONMethod("RoomService", "UpdateParticipantPolicy", serverHooks, roomService.UpdateParticipantPolicy),
+		newTwirpJSONMethod("RoomService", "CreateRoomSchedule", serverHooks, roomService.CreateRoomSchedule),
+		newTwirpJSONMethod("RoomService", "ListRoomSchedules", serverHooks, roomService.ListRoomSchedules),
+		newTwirpJSONMethod("RoomService", "DeleteRoomSchedule", serverHooks, roomService.DeleteRoomSchedule),
+		newTwirpJSONMethod("RoomService", "GetRoomQualityReport", serverHooks, roomService.GetRoomQualityReport),
+		newTwirpJSONMethod("RoomService", "GetRoomEvents", serverHooks, roomService.GetRoomEvents),
+		newTwirpJSONMethod("RoomService", "UpdateRoomLobby", serverHooks, roomService.UpdateRoomLobby),
+		newTwirpJSONMethod("RoomService", "ListLobbyParticipants", serverHooks, roomService.ListLobbyParticipants),
+		newTwirpJSONMethod("RoomService", "AdmitParticipant", serverHooks, roomService.AdmitParticipant),
+		newTwirpJSONMethod("RoomService", "StartPacketCapture", serverHooks, roomService.StartPacketCapture),
+		newTwirpJSONMethod("RoomService", "StopPacketCapture", serverHooks, roomService.StopPacketCapture),
+	} {
+		mux.Handle(m.Path(), m)
+	}
+	mux.HandleFunc(packetCapturePath, roomService.DownloadPacketCapture)
 	mux.Handle("/rtc", rtcService)
 	rtcService.SetupRoutes(mux)
 	mux.Handle("/agent", agentService)
+	mux.HandleFunc(healthzPath, s.healthz)
+	mux.HandleFunc(readyzPath, s.readyz)
 	mux.HandleFunc("/", s.defaultHandler)
 
 	s.httpServer = &http.Server{
@@ -304,6 +349,7 @@ func (s *LivekitServer) Start() error {
 	s.roomManager.Stop()
 	s.signalServer.Stop()
 	s.ioService.Stop()
+	s.telemetry.Close()
 
 	close(s.closedChan)
 	return nil
@@ -312,6 +358,9 @@ func (s *LivekitServer) Start() error {
 func (s *LivekitServer) Stop(force bool) {
 	// wait for all participants to exit
 	s.router.Drain()
+	if conf := s.config.Drain; conf.Reconnect && !force {
+		go s.roomManager.DrainRooms(conf.Window)
+	}
 	partTicker := time.NewTicker(5 * time.Second)
 	waitingForParticipants := !force && s.roomManager.HasParticipants()
 	for waitingForParticipants {
@@ -6,11 +6,28 @@ import (
 	"sync"
 	"time"
 
+	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/protocol/livekit"
 )
 
 type FakeObjectStore struct {
+	AppendRoomEventsStub        func(context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) error
+	appendRoomEventsMutex       sync.RWMutex
+	appendRoomEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 []*rtc.RoomEvent
+		arg4 time.Duration
+		arg5 int
+	}
+	appendRoomEventsReturns struct {
+		result1 error
+	}
+	appendRoomEventsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeleteParticipantStub        func(context.Context, livekit.RoomName, livekit.ParticipantIdentity) error
 	deleteParticipantMutex       sync.RWMutex
 	deleteParticipantArgsForCall []struct {
@@ -36,6 +53,30 @@ type FakeObjectStore struct {
 	deleteRoomReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteRoomScheduleStub        func(context.Context, livekit.RoomName) error
+	deleteRoomScheduleMutex       sync.RWMutex
+	deleteRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	deleteRoomScheduleReturns struct {
+		result1 error
+	}
+	deleteRoomScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteRoomSnapshotStub        func(context.Context, livekit.RoomName) error
+	deleteRoomSnapshotMutex       sync.RWMutex
+	deleteRoomSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	deleteRoomSnapshotReturns struct {
+		result1 error
+	}
+	deleteRoomSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ListParticipantsStub        func(context.Context, livekit.RoomName) ([]*livekit.ParticipantInfo, error)
 	listParticipantsMutex       sync.RWMutex
 	listParticipantsArgsForCall []struct {
@@ -50,6 +91,38 @@ type FakeObjectStore struct {
 		result1 []*livekit.ParticipantInfo
 		result2 error
 	}
+	ListRoomEventsStub        func(context.Context, livekit.RoomName, int64, int) ([]*rtc.RoomEvent, int64, error)
+	listRoomEventsMutex       sync.RWMutex
+	listRoomEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 int64
+		arg4 int
+	}
+	listRoomEventsReturns struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}
+	listRoomEventsReturnsOnCall map[int]struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}
+	ListRoomSchedulesStub        func(context.Context, []livekit.RoomName) ([]*service.RoomSchedule, error)
+	listRoomSchedulesMutex       sync.RWMutex
+	listRoomSchedulesArgsForCall []struct {
+		arg1 context.Context
+		arg2 []livekit.RoomName
+	}
+	listRoomSchedulesReturns struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}
+	listRoomSchedulesReturnsOnCall map[int]struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}
 	ListRoomsStub        func(context.Context, []livekit.RoomName) ([]*livekit.Room, error)
 	listRoomsMutex       sync.RWMutex
 	listRoomsArgsForCall []struct {
@@ -96,6 +169,62 @@ type FakeObjectStore struct {
 		result2 *livekit.RoomInternal
 		result3 error
 	}
+	LoadRoomParticipantPolicyStub        func(context.Context, livekit.RoomName) (*service.RoomParticipantPolicy, error)
+	loadRoomParticipantPolicyMutex       sync.RWMutex
+	loadRoomParticipantPolicyArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomParticipantPolicyReturns struct {
+		result1 *service.RoomParticipantPolicy
+		result2 error
+	}
+	loadRoomParticipantPolicyReturnsOnCall map[int]struct {
+		result1 *service.RoomParticipantPolicy
+		result2 error
+	}
+	LoadRoomQualityReportStub        func(context.Context, livekit.RoomName) (*telemetry.QualityReport, error)
+	loadRoomQualityReportMutex       sync.RWMutex
+	loadRoomQualityReportArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomQualityReportReturns struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}
+	loadRoomQualityReportReturnsOnCall map[int]struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}
+	LoadRoomScheduleStub        func(context.Context, livekit.RoomName) (*service.RoomSchedule, error)
+	loadRoomScheduleMutex       sync.RWMutex
+	loadRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomScheduleReturns struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}
+	loadRoomScheduleReturnsOnCall map[int]struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}
+	LoadRoomSnapshotStub        func(context.Context, livekit.RoomName) (*rtc.RoomSnapshot, error)
+	loadRoomSnapshotMutex       sync.RWMutex
+	loadRoomSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomSnapshotReturns struct {
+		result1 *rtc.RoomSnapshot
+		result2 error
+	}
+	loadRoomSnapshotReturnsOnCall map[int]struct {
+		result1 *rtc.RoomSnapshot
+		result2 error
+	}
 	LockRoomStub        func(context.Context, livekit.RoomName, time.Duration) (string, error)
 	lockRoomMutex       sync.RWMutex
 	lockRoomArgsForCall []struct {
@@ -137,6 +266,56 @@ type FakeObjectStore struct {
 	storeRoomReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StoreRoomParticipantPolicyStub        func(context.Context, *service.RoomParticipantPolicy) error
+	storeRoomParticipantPolicyMutex       sync.RWMutex
+	storeRoomParticipantPolicyArgsForCall []struct {
+		arg1 context.Context
+		arg2 *service.RoomParticipantPolicy
+	}
+	storeRoomParticipantPolicyReturns struct {
+		result1 error
+	}
+	storeRoomParticipantPolicyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreRoomQualityReportStub        func(context.Context, *telemetry.QualityReport, time.Duration) error
+	storeRoomQualityReportMutex       sync.RWMutex
+	storeRoomQualityReportArgsForCall []struct {
+		arg1 context.Context
+		arg2 *telemetry.QualityReport
+		arg3 time.Duration
+	}
+	storeRoomQualityReportReturns struct {
+		result1 error
+	}
+	storeRoomQualityReportReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreRoomScheduleStub        func(context.Context, *service.RoomSchedule) error
+	storeRoomScheduleMutex       sync.RWMutex
+	storeRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 *service.RoomSchedule
+	}
+	storeRoomScheduleReturns struct {
+		result1 error
+	}
+	storeRoomScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreRoomSnapshotStub        func(context.Context, *rtc.RoomSnapshot, time.Duration) error
+	storeRoomSnapshotMutex       sync.RWMutex
+	storeRoomSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 *rtc.RoomSnapshot
+		arg3 time.Duration
+	}
+	storeRoomSnapshotReturns struct {
+		result1 error
+	}
+	storeRoomSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
 	UnlockRoomStub        func(context.Context, livekit.RoomName, string) error
 	unlockRoomMutex       sync.RWMutex
 	unlockRoomArgsForCall []struct {
@@ -154,6 +333,76 @@ type FakeObjectStore struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeObjectStore) AppendRoomEvents(arg1 context.Context, arg2 livekit.RoomName, arg3 []*rtc.RoomEvent, arg4 time.Duration, arg5 int) error {
+	var arg3Copy []*rtc.RoomEvent
+	if arg3 != nil {
+		arg3Copy = make([]*rtc.RoomEvent, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.appendRoomEventsMutex.Lock()
+	ret, specificReturn := fake.appendRoomEventsReturnsOnCall[len(fake.appendRoomEventsArgsForCall)]
+	fake.appendRoomEventsArgsForCall = append(fake.appendRoomEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 []*rtc.RoomEvent
+		arg4 time.Duration
+		arg5 int
+	}{arg1, arg2, arg3Copy, arg4, arg5})
+	stub := fake.AppendRoomEventsStub
+	fakeReturns := fake.appendRoomEventsReturns
+	fake.recordInvocation("AppendRoomEvents", []interface{}{arg1, arg2, arg3Copy, arg4, arg5})
+	fake.appendRoomEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeObjectStore) AppendRoomEventsCallCount() int {
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
+	return len(fake.appendRoomEventsArgsForCall)
+}
+
+func (fake *FakeObjectStore) AppendRoomEventsCalls(stub func(context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = stub
+}
+
+func (fake *FakeObjectStore) AppendRoomEventsArgsForCall(i int) (context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) {
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
+	argsForCall := fake.appendRoomEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeObjectStore) AppendRoomEventsReturns(result1 error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = nil
+	fake.appendRoomEventsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) AppendRoomEventsReturnsOnCall(i int, result1 error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = nil
+	if fake.appendRoomEventsReturnsOnCall == nil {
+		fake.appendRoomEventsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.appendRoomEventsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeObjectStore) DeleteParticipant(arg1 context.Context, arg2 livekit.RoomName, arg3 livekit.ParticipantIdentity) error {
 	fake.deleteParticipantMutex.Lock()
 	ret, specificReturn := fake.deleteParticipantReturnsOnCall[len(fake.deleteParticipantArgsForCall)]
@@ -279,6 +528,130 @@ func (fake *FakeObjectStore) DeleteRoomReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeObjectStore) DeleteRoomSchedule(arg1 context.Context, arg2 livekit.RoomName) error {
+	fake.deleteRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.deleteRoomScheduleReturnsOnCall[len(fake.deleteRoomScheduleArgsForCall)]
+	fake.deleteRoomScheduleArgsForCall = append(fake.deleteRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.DeleteRoomScheduleStub
+	fakeReturns := fake.deleteRoomScheduleReturns
+	fake.recordInvocation("DeleteRoomSchedule", []interface{}{arg1, arg2})
+	fake.deleteRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeObjectStore) DeleteRoomScheduleCallCount() int {
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	return len(fake.deleteRoomScheduleArgsForCall)
+}
+
+func (fake *FakeObjectStore) DeleteRoomScheduleCalls(stub func(context.Context, livekit.RoomName) error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = stub
+}
+
+func (fake *FakeObjectStore) DeleteRoomScheduleArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	argsForCall := fake.deleteRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) DeleteRoomScheduleReturns(result1 error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = nil
+	fake.deleteRoomScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) DeleteRoomScheduleReturnsOnCall(i int, result1 error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = nil
+	if fake.deleteRoomScheduleReturnsOnCall == nil {
+		fake.deleteRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteRoomScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) DeleteRoomSnapshot(arg1 context.Context, arg2 livekit.RoomName) error {
+	fake.deleteRoomSnapshotMutex.Lock()
+	ret, specificReturn := fake.deleteRoomSnapshotReturnsOnCall[len(fake.deleteRoomSnapshotArgsForCall)]
+	fake.deleteRoomSnapshotArgsForCall = append(fake.deleteRoomSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.DeleteRoomSnapshotStub
+	fakeReturns := fake.deleteRoomSnapshotReturns
+	fake.recordInvocation("DeleteRoomSnapshot", []interface{}{arg1, arg2})
+	fake.deleteRoomSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeObjectStore) DeleteRoomSnapshotCallCount() int {
+	fake.deleteRoomSnapshotMutex.RLock()
+	defer fake.deleteRoomSnapshotMutex.RUnlock()
+	return len(fake.deleteRoomSnapshotArgsForCall)
+}
+
+func (fake *FakeObjectStore) DeleteRoomSnapshotCalls(stub func(context.Context, livekit.RoomName) error) {
+	fake.deleteRoomSnapshotMutex.Lock()
+	defer fake.deleteRoomSnapshotMutex.Unlock()
+	fake.DeleteRoomSnapshotStub = stub
+}
+
+func (fake *FakeObjectStore) DeleteRoomSnapshotArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.deleteRoomSnapshotMutex.RLock()
+	defer fake.deleteRoomSnapshotMutex.RUnlock()
+	argsForCall := fake.deleteRoomSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) DeleteRoomSnapshotReturns(result1 error) {
+	fake.deleteRoomSnapshotMutex.Lock()
+	defer fake.deleteRoomSnapshotMutex.Unlock()
+	fake.DeleteRoomSnapshotStub = nil
+	fake.deleteRoomSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) DeleteRoomSnapshotReturnsOnCall(i int, result1 error) {
+	fake.deleteRoomSnapshotMutex.Lock()
+	defer fake.deleteRoomSnapshotMutex.Unlock()
+	fake.DeleteRoomSnapshotStub = nil
+	if fake.deleteRoomSnapshotReturnsOnCall == nil {
+		fake.deleteRoomSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteRoomSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeObjectStore) ListParticipants(arg1 context.Context, arg2 livekit.RoomName) ([]*livekit.ParticipantInfo, error) {
 	fake.listParticipantsMutex.Lock()
 	ret, specificReturn := fake.listParticipantsReturnsOnCall[len(fake.listParticipantsArgsForCall)]
@@ -344,6 +717,146 @@ func (fake *FakeObjectStore) ListParticipantsReturnsOnCall(i int, result1 []*liv
 	}{result1, result2}
 }
 
+func (fake *FakeObjectStore) ListRoomEvents(arg1 context.Context, arg2 livekit.RoomName, arg3 int64, arg4 int) ([]*rtc.RoomEvent, int64, error) {
+	fake.listRoomEventsMutex.Lock()
+	ret, specificReturn := fake.listRoomEventsReturnsOnCall[len(fake.listRoomEventsArgsForCall)]
+	fake.listRoomEventsArgsForCall = append(fake.listRoomEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 int64
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListRoomEventsStub
+	fakeReturns := fake.listRoomEventsReturns
+	fake.recordInvocation("ListRoomEvents", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listRoomEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeObjectStore) ListRoomEventsCallCount() int {
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	return len(fake.listRoomEventsArgsForCall)
+}
+
+func (fake *FakeObjectStore) ListRoomEventsCalls(stub func(context.Context, livekit.RoomName, int64, int) ([]*rtc.RoomEvent, int64, error)) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = stub
+}
+
+func (fake *FakeObjectStore) ListRoomEventsArgsForCall(i int) (context.Context, livekit.RoomName, int64, int) {
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	argsForCall := fake.listRoomEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeObjectStore) ListRoomEventsReturns(result1 []*rtc.RoomEvent, result2 int64, result3 error) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = nil
+	fake.listRoomEventsReturns = struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeObjectStore) ListRoomEventsReturnsOnCall(i int, result1 []*rtc.RoomEvent, result2 int64, result3 error) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = nil
+	if fake.listRoomEventsReturnsOnCall == nil {
+		fake.listRoomEventsReturnsOnCall = make(map[int]struct {
+			result1 []*rtc.RoomEvent
+			result2 int64
+			result3 error
+		})
+	}
+	fake.listRoomEventsReturnsOnCall[i] = struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeObjectStore) ListRoomSchedules(arg1 context.Context, arg2 []livekit.RoomName) ([]*service.RoomSchedule, error) {
+	var arg2Copy []livekit.RoomName
+	if arg2 != nil {
+		arg2Copy = make([]livekit.RoomName, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.listRoomSchedulesMutex.Lock()
+	ret, specificReturn := fake.listRoomSchedulesReturnsOnCall[len(fake.listRoomSchedulesArgsForCall)]
+	fake.listRoomSchedulesArgsForCall = append(fake.listRoomSchedulesArgsForCall, struct {
+		arg1 context.Context
+		arg2 []livekit.RoomName
+	}{arg1, arg2Copy})
+	stub := fake.ListRoomSchedulesStub
+	fakeReturns := fake.listRoomSchedulesReturns
+	fake.recordInvocation("ListRoomSchedules", []interface{}{arg1, arg2Copy})
+	fake.listRoomSchedulesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeObjectStore) ListRoomSchedulesCallCount() int {
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
+	return len(fake.listRoomSchedulesArgsForCall)
+}
+
+func (fake *FakeObjectStore) ListRoomSchedulesCalls(stub func(context.Context, []livekit.RoomName) ([]*service.RoomSchedule, error)) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = stub
+}
+
+func (fake *FakeObjectStore) ListRoomSchedulesArgsForCall(i int) (context.Context, []livekit.RoomName) {
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
+	argsForCall := fake.listRoomSchedulesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) ListRoomSchedulesReturns(result1 []*service.RoomSchedule, result2 error) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = nil
+	fake.listRoomSchedulesReturns = struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) ListRoomSchedulesReturnsOnCall(i int, result1 []*service.RoomSchedule, result2 error) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = nil
+	if fake.listRoomSchedulesReturnsOnCall == nil {
+		fake.listRoomSchedulesReturnsOnCall = make(map[int]struct {
+			result1 []*service.RoomSchedule
+			result2 error
+		})
+	}
+	fake.listRoomSchedulesReturnsOnCall[i] = struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeObjectStore) ListRooms(arg1 context.Context, arg2 []livekit.RoomName) ([]*livekit.Room, error) {
 	var arg2Copy []livekit.RoomName
 	if arg2 != nil {
@@ -549,6 +1062,266 @@ func (fake *FakeObjectStore) LoadRoomReturnsOnCall(i int, result1 *livekit.Room,
 	}{result1, result2, result3}
 }
 
+func (fake *FakeObjectStore) LoadRoomParticipantPolicy(arg1 context.Context, arg2 livekit.RoomName) (*service.RoomParticipantPolicy, error) {
+	fake.loadRoomParticipantPolicyMutex.Lock()
+	ret, specificReturn := fake.loadRoomParticipantPolicyReturnsOnCall[len(fake.loadRoomParticipantPolicyArgsForCall)]
+	fake.loadRoomParticipantPolicyArgsForCall = append(fake.loadRoomParticipantPolicyArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomParticipantPolicyStub
+	fakeReturns := fake.loadRoomParticipantPolicyReturns
+	fake.recordInvocation("LoadRoomParticipantPolicy", []interface{}{arg1, arg2})
+	fake.loadRoomParticipantPolicyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeObjectStore) LoadRoomParticipantPolicyCallCount() int {
+	fake.loadRoomParticipantPolicyMutex.RLock()
+	defer fake.loadRoomParticipantPolicyMutex.RUnlock()
+	return len(fake.loadRoomParticipantPolicyArgsForCall)
+}
+
+func (fake *FakeObjectStore) LoadRoomParticipantPolicyCalls(stub func(context.Context, livekit.RoomName) (*service.RoomParticipantPolicy, error)) {
+	fake.loadRoomParticipantPolicyMutex.Lock()
+	defer fake.loadRoomParticipantPolicyMutex.Unlock()
+	fake.LoadRoomParticipantPolicyStub = stub
+}
+
+func (fake *FakeObjectStore) LoadRoomParticipantPolicyArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomParticipantPolicyMutex.RLock()
+	defer fake.loadRoomParticipantPolicyMutex.RUnlock()
+	argsForCall := fake.loadRoomParticipantPolicyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) LoadRoomParticipantPolicyReturns(result1 *service.RoomParticipantPolicy, result2 error) {
+	fake.loadRoomParticipantPolicyMutex.Lock()
+	defer fake.loadRoomParticipantPolicyMutex.Unlock()
+	fake.LoadRoomParticipantPolicyStub = nil
+	fake.loadRoomParticipantPolicyReturns = struct {
+		result1 *service.RoomParticipantPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) LoadRoomParticipantPolicyReturnsOnCall(i int, result1 *service.RoomParticipantPolicy, result2 error) {
+	fake.loadRoomParticipantPolicyMutex.Lock()
+	defer fake.loadRoomParticipantPolicyMutex.Unlock()
+	fake.LoadRoomParticipantPolicyStub = nil
+	if fake.loadRoomParticipantPolicyReturnsOnCall == nil {
+		fake.loadRoomParticipantPolicyReturnsOnCall = make(map[int]struct {
+			result1 *service.RoomParticipantPolicy
+			result2 error
+		})
+	}
+	fake.loadRoomParticipantPolicyReturnsOnCall[i] = struct {
+		result1 *service.RoomParticipantPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) LoadRoomQualityReport(arg1 context.Context, arg2 livekit.RoomName) (*telemetry.QualityReport, error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	ret, specificReturn := fake.loadRoomQualityReportReturnsOnCall[len(fake.loadRoomQualityReportArgsForCall)]
+	fake.loadRoomQualityReportArgsForCall = append(fake.loadRoomQualityReportArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomQualityReportStub
+	fakeReturns := fake.loadRoomQualityReportReturns
+	fake.recordInvocation("LoadRoomQualityReport", []interface{}{arg1, arg2})
+	fake.loadRoomQualityReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeObjectStore) LoadRoomQualityReportCallCount() int {
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	return len(fake.loadRoomQualityReportArgsForCall)
+}
+
+func (fake *FakeObjectStore) LoadRoomQualityReportCalls(stub func(context.Context, livekit.RoomName) (*telemetry.QualityReport, error)) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = stub
+}
+
+func (fake *FakeObjectStore) LoadRoomQualityReportArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	argsForCall := fake.loadRoomQualityReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) LoadRoomQualityReportReturns(result1 *telemetry.QualityReport, result2 error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = nil
+	fake.loadRoomQualityReportReturns = struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) LoadRoomQualityReportReturnsOnCall(i int, result1 *telemetry.QualityReport, result2 error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = nil
+	if fake.loadRoomQualityReportReturnsOnCall == nil {
+		fake.loadRoomQualityReportReturnsOnCall = make(map[int]struct {
+			result1 *telemetry.QualityReport
+			result2 error
+		})
+	}
+	fake.loadRoomQualityReportReturnsOnCall[i] = struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) LoadRoomSchedule(arg1 context.Context, arg2 livekit.RoomName) (*service.RoomSchedule, error) {
+	fake.loadRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.loadRoomScheduleReturnsOnCall[len(fake.loadRoomScheduleArgsForCall)]
+	fake.loadRoomScheduleArgsForCall = append(fake.loadRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomScheduleStub
+	fakeReturns := fake.loadRoomScheduleReturns
+	fake.recordInvocation("LoadRoomSchedule", []interface{}{arg1, arg2})
+	fake.loadRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeObjectStore) LoadRoomScheduleCallCount() int {
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	return len(fake.loadRoomScheduleArgsForCall)
+}
+
+func (fake *FakeObjectStore) LoadRoomScheduleCalls(stub func(context.Context, livekit.RoomName) (*service.RoomSchedule, error)) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = stub
+}
+
+func (fake *FakeObjectStore) LoadRoomScheduleArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	argsForCall := fake.loadRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) LoadRoomScheduleReturns(result1 *service.RoomSchedule, result2 error) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = nil
+	fake.loadRoomScheduleReturns = struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) LoadRoomScheduleReturnsOnCall(i int, result1 *service.RoomSchedule, result2 error) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = nil
+	if fake.loadRoomScheduleReturnsOnCall == nil {
+		fake.loadRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 *service.RoomSchedule
+			result2 error
+		})
+	}
+	fake.loadRoomScheduleReturnsOnCall[i] = struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) LoadRoomSnapshot(arg1 context.Context, arg2 livekit.RoomName) (*rtc.RoomSnapshot, error) {
+	fake.loadRoomSnapshotMutex.Lock()
+	ret, specificReturn := fake.loadRoomSnapshotReturnsOnCall[len(fake.loadRoomSnapshotArgsForCall)]
+	fake.loadRoomSnapshotArgsForCall = append(fake.loadRoomSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomSnapshotStub
+	fakeReturns := fake.loadRoomSnapshotReturns
+	fake.recordInvocation("LoadRoomSnapshot", []interface{}{arg1, arg2})
+	fake.loadRoomSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeObjectStore) LoadRoomSnapshotCallCount() int {
+	fake.loadRoomSnapshotMutex.RLock()
+	defer fake.loadRoomSnapshotMutex.RUnlock()
+	return len(fake.loadRoomSnapshotArgsForCall)
+}
+
+func (fake *FakeObjectStore) LoadRoomSnapshotCalls(stub func(context.Context, livekit.RoomName) (*rtc.RoomSnapshot, error)) {
+	fake.loadRoomSnapshotMutex.Lock()
+	defer fake.loadRoomSnapshotMutex.Unlock()
+	fake.LoadRoomSnapshotStub = stub
+}
+
+func (fake *FakeObjectStore) LoadRoomSnapshotArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomSnapshotMutex.RLock()
+	defer fake.loadRoomSnapshotMutex.RUnlock()
+	argsForCall := fake.loadRoomSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) LoadRoomSnapshotReturns(result1 *rtc.RoomSnapshot, result2 error) {
+	fake.loadRoomSnapshotMutex.Lock()
+	defer fake.loadRoomSnapshotMutex.Unlock()
+	fake.LoadRoomSnapshotStub = nil
+	fake.loadRoomSnapshotReturns = struct {
+		result1 *rtc.RoomSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) LoadRoomSnapshotReturnsOnCall(i int, result1 *rtc.RoomSnapshot, result2 error) {
+	fake.loadRoomSnapshotMutex.Lock()
+	defer fake.loadRoomSnapshotMutex.Unlock()
+	fake.LoadRoomSnapshotStub = nil
+	if fake.loadRoomSnapshotReturnsOnCall == nil {
+		fake.loadRoomSnapshotReturnsOnCall = make(map[int]struct {
+			result1 *rtc.RoomSnapshot
+			result2 error
+		})
+	}
+	fake.loadRoomSnapshotReturnsOnCall[i] = struct {
+		result1 *rtc.RoomSnapshot
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeObjectStore) LockRoom(arg1 context.Context, arg2 livekit.RoomName, arg3 time.Duration) (string, error) {
 	fake.lockRoomMutex.Lock()
 	ret, specificReturn := fake.lockRoomReturnsOnCall[len(fake.lockRoomArgsForCall)]
@@ -741,6 +1514,256 @@ func (fake *FakeObjectStore) StoreRoomReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeObjectStore) StoreRoomParticipantPolicy(arg1 context.Context, arg2 *service.RoomParticipantPolicy) error {
+	fake.storeRoomParticipantPolicyMutex.Lock()
+	ret, specificReturn := fake.storeRoomParticipantPolicyReturnsOnCall[len(fake.storeRoomParticipantPolicyArgsForCall)]
+	fake.storeRoomParticipantPolicyArgsForCall = append(fake.storeRoomParticipantPolicyArgsForCall, struct {
+		arg1 context.Context
+		arg2 *service.RoomParticipantPolicy
+	}{arg1, arg2})
+	stub := fake.StoreRoomParticipantPolicyStub
+	fakeReturns := fake.storeRoomParticipantPolicyReturns
+	fake.recordInvocation("StoreRoomParticipantPolicy", []interface{}{arg1, arg2})
+	fake.storeRoomParticipantPolicyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeObjectStore) StoreRoomParticipantPolicyCallCount() int {
+	fake.storeRoomParticipantPolicyMutex.RLock()
+	defer fake.storeRoomParticipantPolicyMutex.RUnlock()
+	return len(fake.storeRoomParticipantPolicyArgsForCall)
+}
+
+func (fake *FakeObjectStore) StoreRoomParticipantPolicyCalls(stub func(context.Context, *service.RoomParticipantPolicy) error) {
+	fake.storeRoomParticipantPolicyMutex.Lock()
+	defer fake.storeRoomParticipantPolicyMutex.Unlock()
+	fake.StoreRoomParticipantPolicyStub = stub
+}
+
+func (fake *FakeObjectStore) StoreRoomParticipantPolicyArgsForCall(i int) (context.Context, *service.RoomParticipantPolicy) {
+	fake.storeRoomParticipantPolicyMutex.RLock()
+	defer fake.storeRoomParticipantPolicyMutex.RUnlock()
+	argsForCall := fake.storeRoomParticipantPolicyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) StoreRoomParticipantPolicyReturns(result1 error) {
+	fake.storeRoomParticipantPolicyMutex.Lock()
+	defer fake.storeRoomParticipantPolicyMutex.Unlock()
+	fake.StoreRoomParticipantPolicyStub = nil
+	fake.storeRoomParticipantPolicyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) StoreRoomParticipantPolicyReturnsOnCall(i int, result1 error) {
+	fake.storeRoomParticipantPolicyMutex.Lock()
+	defer fake.storeRoomParticipantPolicyMutex.Unlock()
+	fake.StoreRoomParticipantPolicyStub = nil
+	if fake.storeRoomParticipantPolicyReturnsOnCall == nil {
+		fake.storeRoomParticipantPolicyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomParticipantPolicyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) StoreRoomQualityReport(arg1 context.Context, arg2 *telemetry.QualityReport, arg3 time.Duration) error {
+	fake.storeRoomQualityReportMutex.Lock()
+	ret, specificReturn := fake.storeRoomQualityReportReturnsOnCall[len(fake.storeRoomQualityReportArgsForCall)]
+	fake.storeRoomQualityReportArgsForCall = append(fake.storeRoomQualityReportArgsForCall, struct {
+		arg1 context.Context
+		arg2 *telemetry.QualityReport
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.StoreRoomQualityReportStub
+	fakeReturns := fake.storeRoomQualityReportReturns
+	fake.recordInvocation("StoreRoomQualityReport", []interface{}{arg1, arg2, arg3})
+	fake.storeRoomQualityReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeObjectStore) StoreRoomQualityReportCallCount() int {
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	return len(fake.storeRoomQualityReportArgsForCall)
+}
+
+func (fake *FakeObjectStore) StoreRoomQualityReportCalls(stub func(context.Context, *telemetry.QualityReport, time.Duration) error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = stub
+}
+
+func (fake *FakeObjectStore) StoreRoomQualityReportArgsForCall(i int) (context.Context, *telemetry.QualityReport, time.Duration) {
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	argsForCall := fake.storeRoomQualityReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeObjectStore) StoreRoomQualityReportReturns(result1 error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = nil
+	fake.storeRoomQualityReportReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) StoreRoomQualityReportReturnsOnCall(i int, result1 error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = nil
+	if fake.storeRoomQualityReportReturnsOnCall == nil {
+		fake.storeRoomQualityReportReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomQualityReportReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) StoreRoomSchedule(arg1 context.Context, arg2 *service.RoomSchedule) error {
+	fake.storeRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.storeRoomScheduleReturnsOnCall[len(fake.storeRoomScheduleArgsForCall)]
+	fake.storeRoomScheduleArgsForCall = append(fake.storeRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 *service.RoomSchedule
+	}{arg1, arg2})
+	stub := fake.StoreRoomScheduleStub
+	fakeReturns := fake.storeRoomScheduleReturns
+	fake.recordInvocation("StoreRoomSchedule", []interface{}{arg1, arg2})
+	fake.storeRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeObjectStore) StoreRoomScheduleCallCount() int {
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	return len(fake.storeRoomScheduleArgsForCall)
+}
+
+func (fake *FakeObjectStore) StoreRoomScheduleCalls(stub func(context.Context, *service.RoomSchedule) error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = stub
+}
+
+func (fake *FakeObjectStore) StoreRoomScheduleArgsForCall(i int) (context.Context, *service.RoomSchedule) {
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	argsForCall := fake.storeRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeObjectStore) StoreRoomScheduleReturns(result1 error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = nil
+	fake.storeRoomScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) StoreRoomScheduleReturnsOnCall(i int, result1 error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = nil
+	if fake.storeRoomScheduleReturnsOnCall == nil {
+		fake.storeRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) StoreRoomSnapshot(arg1 context.Context, arg2 *rtc.RoomSnapshot, arg3 time.Duration) error {
+	fake.storeRoomSnapshotMutex.Lock()
+	ret, specificReturn := fake.storeRoomSnapshotReturnsOnCall[len(fake.storeRoomSnapshotArgsForCall)]
+	fake.storeRoomSnapshotArgsForCall = append(fake.storeRoomSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 *rtc.RoomSnapshot
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.StoreRoomSnapshotStub
+	fakeReturns := fake.storeRoomSnapshotReturns
+	fake.recordInvocation("StoreRoomSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.storeRoomSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeObjectStore) StoreRoomSnapshotCallCount() int {
+	fake.storeRoomSnapshotMutex.RLock()
+	defer fake.storeRoomSnapshotMutex.RUnlock()
+	return len(fake.storeRoomSnapshotArgsForCall)
+}
+
+func (fake *FakeObjectStore) StoreRoomSnapshotCalls(stub func(context.Context, *rtc.RoomSnapshot, time.Duration) error) {
+	fake.storeRoomSnapshotMutex.Lock()
+	defer fake.storeRoomSnapshotMutex.Unlock()
+	fake.StoreRoomSnapshotStub = stub
+}
+
+func (fake *FakeObjectStore) StoreRoomSnapshotArgsForCall(i int) (context.Context, *rtc.RoomSnapshot, time.Duration) {
+	fake.storeRoomSnapshotMutex.RLock()
+	defer fake.storeRoomSnapshotMutex.RUnlock()
+	argsForCall := fake.storeRoomSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeObjectStore) StoreRoomSnapshotReturns(result1 error) {
+	fake.storeRoomSnapshotMutex.Lock()
+	defer fake.storeRoomSnapshotMutex.Unlock()
+	fake.StoreRoomSnapshotStub = nil
+	fake.storeRoomSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeObjectStore) StoreRoomSnapshotReturnsOnCall(i int, result1 error) {
+	fake.storeRoomSnapshotMutex.Lock()
+	defer fake.storeRoomSnapshotMutex.Unlock()
+	fake.StoreRoomSnapshotStub = nil
+	if fake.storeRoomSnapshotReturnsOnCall == nil {
+		fake.storeRoomSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeObjectStore) UnlockRoom(arg1 context.Context, arg2 livekit.RoomName, arg3 string) error {
 	fake.unlockRoomMutex.Lock()
 	ret, specificReturn := fake.unlockRoomReturnsOnCall[len(fake.unlockRoomArgsForCall)]
@@ -807,24 +1830,50 @@ func (fake *FakeObjectStore) UnlockRoomReturnsOnCall(i int, result1 error) {
 func (fake *FakeObjectStore) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
 	fake.deleteParticipantMutex.RLock()
 	defer fake.deleteParticipantMutex.RUnlock()
 	fake.deleteRoomMutex.RLock()
 	defer fake.deleteRoomMutex.RUnlock()
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	fake.deleteRoomSnapshotMutex.RLock()
+	defer fake.deleteRoomSnapshotMutex.RUnlock()
 	fake.listParticipantsMutex.RLock()
 	defer fake.listParticipantsMutex.RUnlock()
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
 	fake.listRoomsMutex.RLock()
 	defer fake.listRoomsMutex.RUnlock()
 	fake.loadParticipantMutex.RLock()
 	defer fake.loadParticipantMutex.RUnlock()
 	fake.loadRoomMutex.RLock()
 	defer fake.loadRoomMutex.RUnlock()
+	fake.loadRoomParticipantPolicyMutex.RLock()
+	defer fake.loadRoomParticipantPolicyMutex.RUnlock()
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	fake.loadRoomSnapshotMutex.RLock()
+	defer fake.loadRoomSnapshotMutex.RUnlock()
 	fake.lockRoomMutex.RLock()
 	defer fake.lockRoomMutex.RUnlock()
 	fake.storeParticipantMutex.RLock()
 	defer fake.storeParticipantMutex.RUnlock()
 	fake.storeRoomMutex.RLock()
 	defer fake.storeRoomMutex.RUnlock()
+	fake.storeRoomParticipantPolicyMutex.RLock()
+	defer fake.storeRoomParticipantPolicyMutex.RUnlock()
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	fake.storeRoomSnapshotMutex.RLock()
+	defer fake.storeRoomSnapshotMutex.RUnlock()
 	fake.unlockRoomMutex.RLock()
 	defer fake.unlockRoomMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
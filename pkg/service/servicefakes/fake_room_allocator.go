@@ -5,11 +5,28 @@ import (
 	"context"
 	"sync"
 
+	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 )
 
 type FakeRoomAllocator struct {
+	AdmitParticipantStub        func(context.Context, livekit.RoomName, *livekit.Node, *auth.ClaimGrants, bool) error
+	admitParticipantMutex       sync.RWMutex
+	admitParticipantArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 *livekit.Node
+		arg4 *auth.ClaimGrants
+		arg5 bool
+	}
+	admitParticipantReturns struct {
+		result1 error
+	}
+	admitParticipantReturnsOnCall map[int]struct {
+		result1 error
+	}
 	AutoCreateEnabledStub        func(context.Context) bool
 	autoCreateEnabledMutex       sync.RWMutex
 	autoCreateEnabledArgsForCall []struct {
@@ -40,6 +57,21 @@ type FakeRoomAllocator struct {
 		result3 bool
 		result4 error
 	}
+	SelectParticipantNodeStub        func(context.Context, livekit.RoomName, string) (livekit.NodeID, error)
+	selectParticipantNodeMutex       sync.RWMutex
+	selectParticipantNodeArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 string
+	}
+	selectParticipantNodeReturns struct {
+		result1 livekit.NodeID
+		result2 error
+	}
+	selectParticipantNodeReturnsOnCall map[int]struct {
+		result1 livekit.NodeID
+		result2 error
+	}
 	SelectRoomNodeStub        func(context.Context, livekit.RoomName, livekit.NodeID) error
 	selectRoomNodeMutex       sync.RWMutex
 	selectRoomNodeArgsForCall []struct {
@@ -53,6 +85,18 @@ type FakeRoomAllocator struct {
 	selectRoomNodeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SortRegionsByDistanceStub        func(string, []config.RegionConfig) bool
+	sortRegionsByDistanceMutex       sync.RWMutex
+	sortRegionsByDistanceArgsForCall []struct {
+		arg1 string
+		arg2 []config.RegionConfig
+	}
+	sortRegionsByDistanceReturns struct {
+		result1 bool
+	}
+	sortRegionsByDistanceReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	ValidateCreateRoomStub        func(context.Context, livekit.RoomName) error
 	validateCreateRoomMutex       sync.RWMutex
 	validateCreateRoomArgsForCall []struct {
@@ -69,6 +113,71 @@ type FakeRoomAllocator struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeRoomAllocator) AdmitParticipant(arg1 context.Context, arg2 livekit.RoomName, arg3 *livekit.Node, arg4 *auth.ClaimGrants, arg5 bool) error {
+	fake.admitParticipantMutex.Lock()
+	ret, specificReturn := fake.admitParticipantReturnsOnCall[len(fake.admitParticipantArgsForCall)]
+	fake.admitParticipantArgsForCall = append(fake.admitParticipantArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 *livekit.Node
+		arg4 *auth.ClaimGrants
+		arg5 bool
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.AdmitParticipantStub
+	fakeReturns := fake.admitParticipantReturns
+	fake.recordInvocation("AdmitParticipant", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.admitParticipantMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRoomAllocator) AdmitParticipantCallCount() int {
+	fake.admitParticipantMutex.RLock()
+	defer fake.admitParticipantMutex.RUnlock()
+	return len(fake.admitParticipantArgsForCall)
+}
+
+func (fake *FakeRoomAllocator) AdmitParticipantCalls(stub func(context.Context, livekit.RoomName, *livekit.Node, *auth.ClaimGrants, bool) error) {
+	fake.admitParticipantMutex.Lock()
+	defer fake.admitParticipantMutex.Unlock()
+	fake.AdmitParticipantStub = stub
+}
+
+func (fake *FakeRoomAllocator) AdmitParticipantArgsForCall(i int) (context.Context, livekit.RoomName, *livekit.Node, *auth.ClaimGrants, bool) {
+	fake.admitParticipantMutex.RLock()
+	defer fake.admitParticipantMutex.RUnlock()
+	argsForCall := fake.admitParticipantArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeRoomAllocator) AdmitParticipantReturns(result1 error) {
+	fake.admitParticipantMutex.Lock()
+	defer fake.admitParticipantMutex.Unlock()
+	fake.AdmitParticipantStub = nil
+	fake.admitParticipantReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomAllocator) AdmitParticipantReturnsOnCall(i int, result1 error) {
+	fake.admitParticipantMutex.Lock()
+	defer fake.admitParticipantMutex.Unlock()
+	fake.AdmitParticipantStub = nil
+	if fake.admitParticipantReturnsOnCall == nil {
+		fake.admitParticipantReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.admitParticipantReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeRoomAllocator) AutoCreateEnabled(arg1 context.Context) bool {
 	fake.autoCreateEnabledMutex.Lock()
 	ret, specificReturn := fake.autoCreateEnabledReturnsOnCall[len(fake.autoCreateEnabledArgsForCall)]
@@ -202,6 +311,72 @@ func (fake *FakeRoomAllocator) CreateRoomReturnsOnCall(i int, result1 *livekit.R
 	}{result1, result2, result3, result4}
 }
 
+func (fake *FakeRoomAllocator) SelectParticipantNode(arg1 context.Context, arg2 livekit.RoomName, arg3 string) (livekit.NodeID, error) {
+	fake.selectParticipantNodeMutex.Lock()
+	ret, specificReturn := fake.selectParticipantNodeReturnsOnCall[len(fake.selectParticipantNodeArgsForCall)]
+	fake.selectParticipantNodeArgsForCall = append(fake.selectParticipantNodeArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.SelectParticipantNodeStub
+	fakeReturns := fake.selectParticipantNodeReturns
+	fake.recordInvocation("SelectParticipantNode", []interface{}{arg1, arg2, arg3})
+	fake.selectParticipantNodeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRoomAllocator) SelectParticipantNodeCallCount() int {
+	fake.selectParticipantNodeMutex.RLock()
+	defer fake.selectParticipantNodeMutex.RUnlock()
+	return len(fake.selectParticipantNodeArgsForCall)
+}
+
+func (fake *FakeRoomAllocator) SelectParticipantNodeCalls(stub func(context.Context, livekit.RoomName, string) (livekit.NodeID, error)) {
+	fake.selectParticipantNodeMutex.Lock()
+	defer fake.selectParticipantNodeMutex.Unlock()
+	fake.SelectParticipantNodeStub = stub
+}
+
+func (fake *FakeRoomAllocator) SelectParticipantNodeArgsForCall(i int) (context.Context, livekit.RoomName, string) {
+	fake.selectParticipantNodeMutex.RLock()
+	defer fake.selectParticipantNodeMutex.RUnlock()
+	argsForCall := fake.selectParticipantNodeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeRoomAllocator) SelectParticipantNodeReturns(result1 livekit.NodeID, result2 error) {
+	fake.selectParticipantNodeMutex.Lock()
+	defer fake.selectParticipantNodeMutex.Unlock()
+	fake.SelectParticipantNodeStub = nil
+	fake.selectParticipantNodeReturns = struct {
+		result1 livekit.NodeID
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRoomAllocator) SelectParticipantNodeReturnsOnCall(i int, result1 livekit.NodeID, result2 error) {
+	fake.selectParticipantNodeMutex.Lock()
+	defer fake.selectParticipantNodeMutex.Unlock()
+	fake.SelectParticipantNodeStub = nil
+	if fake.selectParticipantNodeReturnsOnCall == nil {
+		fake.selectParticipantNodeReturnsOnCall = make(map[int]struct {
+			result1 livekit.NodeID
+			result2 error
+		})
+	}
+	fake.selectParticipantNodeReturnsOnCall[i] = struct {
+		result1 livekit.NodeID
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeRoomAllocator) SelectRoomNode(arg1 context.Context, arg2 livekit.RoomName, arg3 livekit.NodeID) error {
 	fake.selectRoomNodeMutex.Lock()
 	ret, specificReturn := fake.selectRoomNodeReturnsOnCall[len(fake.selectRoomNodeArgsForCall)]
@@ -265,6 +440,73 @@ func (fake *FakeRoomAllocator) SelectRoomNodeReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeRoomAllocator) SortRegionsByDistance(arg1 string, arg2 []config.RegionConfig) bool {
+	var arg2Copy []config.RegionConfig
+	if arg2 != nil {
+		arg2Copy = make([]config.RegionConfig, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.sortRegionsByDistanceMutex.Lock()
+	ret, specificReturn := fake.sortRegionsByDistanceReturnsOnCall[len(fake.sortRegionsByDistanceArgsForCall)]
+	fake.sortRegionsByDistanceArgsForCall = append(fake.sortRegionsByDistanceArgsForCall, struct {
+		arg1 string
+		arg2 []config.RegionConfig
+	}{arg1, arg2Copy})
+	stub := fake.SortRegionsByDistanceStub
+	fakeReturns := fake.sortRegionsByDistanceReturns
+	fake.recordInvocation("SortRegionsByDistance", []interface{}{arg1, arg2Copy})
+	fake.sortRegionsByDistanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRoomAllocator) SortRegionsByDistanceCallCount() int {
+	fake.sortRegionsByDistanceMutex.RLock()
+	defer fake.sortRegionsByDistanceMutex.RUnlock()
+	return len(fake.sortRegionsByDistanceArgsForCall)
+}
+
+func (fake *FakeRoomAllocator) SortRegionsByDistanceCalls(stub func(string, []config.RegionConfig) bool) {
+	fake.sortRegionsByDistanceMutex.Lock()
+	defer fake.sortRegionsByDistanceMutex.Unlock()
+	fake.SortRegionsByDistanceStub = stub
+}
+
+func (fake *FakeRoomAllocator) SortRegionsByDistanceArgsForCall(i int) (string, []config.RegionConfig) {
+	fake.sortRegionsByDistanceMutex.RLock()
+	defer fake.sortRegionsByDistanceMutex.RUnlock()
+	argsForCall := fake.sortRegionsByDistanceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRoomAllocator) SortRegionsByDistanceReturns(result1 bool) {
+	fake.sortRegionsByDistanceMutex.Lock()
+	defer fake.sortRegionsByDistanceMutex.Unlock()
+	fake.SortRegionsByDistanceStub = nil
+	fake.sortRegionsByDistanceReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeRoomAllocator) SortRegionsByDistanceReturnsOnCall(i int, result1 bool) {
+	fake.sortRegionsByDistanceMutex.Lock()
+	defer fake.sortRegionsByDistanceMutex.Unlock()
+	fake.SortRegionsByDistanceStub = nil
+	if fake.sortRegionsByDistanceReturnsOnCall == nil {
+		fake.sortRegionsByDistanceReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.sortRegionsByDistanceReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeRoomAllocator) ValidateCreateRoom(arg1 context.Context, arg2 livekit.RoomName) error {
 	fake.validateCreateRoomMutex.Lock()
 	ret, specificReturn := fake.validateCreateRoomReturnsOnCall[len(fake.validateCreateRoomArgsForCall)]
@@ -330,12 +572,18 @@ func (fake *FakeRoomAllocator) ValidateCreateRoomReturnsOnCall(i int, result1 er
 func (fake *FakeRoomAllocator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.admitParticipantMutex.RLock()
+	defer fake.admitParticipantMutex.RUnlock()
 	fake.autoCreateEnabledMutex.RLock()
 	defer fake.autoCreateEnabledMutex.RUnlock()
 	fake.createRoomMutex.RLock()
 	defer fake.createRoomMutex.RUnlock()
+	fake.selectParticipantNodeMutex.RLock()
+	defer fake.selectParticipantNodeMutex.RUnlock()
 	fake.selectRoomNodeMutex.RLock()
 	defer fake.selectRoomNodeMutex.RUnlock()
+	fake.sortRegionsByDistanceMutex.RLock()
+	defer fake.sortRegionsByDistanceMutex.RUnlock()
 	fake.validateCreateRoomMutex.RLock()
 	defer fake.validateCreateRoomMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -0,0 +1,218 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package servicefakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/protocol/livekit"
+)
+
+type FakeRoomEventStore struct {
+	AppendRoomEventsStub        func(context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) error
+	appendRoomEventsMutex       sync.RWMutex
+	appendRoomEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 []*rtc.RoomEvent
+		arg4 time.Duration
+		arg5 int
+	}
+	appendRoomEventsReturns struct {
+		result1 error
+	}
+	appendRoomEventsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListRoomEventsStub        func(context.Context, livekit.RoomName, int64, int) ([]*rtc.RoomEvent, int64, error)
+	listRoomEventsMutex       sync.RWMutex
+	listRoomEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 int64
+		arg4 int
+	}
+	listRoomEventsReturns struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}
+	listRoomEventsReturnsOnCall map[int]struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRoomEventStore) AppendRoomEvents(arg1 context.Context, arg2 livekit.RoomName, arg3 []*rtc.RoomEvent, arg4 time.Duration, arg5 int) error {
+	var arg3Copy []*rtc.RoomEvent
+	if arg3 != nil {
+		arg3Copy = make([]*rtc.RoomEvent, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.appendRoomEventsMutex.Lock()
+	ret, specificReturn := fake.appendRoomEventsReturnsOnCall[len(fake.appendRoomEventsArgsForCall)]
+	fake.appendRoomEventsArgsForCall = append(fake.appendRoomEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 []*rtc.RoomEvent
+		arg4 time.Duration
+		arg5 int
+	}{arg1, arg2, arg3Copy, arg4, arg5})
+	stub := fake.AppendRoomEventsStub
+	fakeReturns := fake.appendRoomEventsReturns
+	fake.recordInvocation("AppendRoomEvents", []interface{}{arg1, arg2, arg3Copy, arg4, arg5})
+	fake.appendRoomEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRoomEventStore) AppendRoomEventsCallCount() int {
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
+	return len(fake.appendRoomEventsArgsForCall)
+}
+
+func (fake *FakeRoomEventStore) AppendRoomEventsCalls(stub func(context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = stub
+}
+
+func (fake *FakeRoomEventStore) AppendRoomEventsArgsForCall(i int) (context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) {
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
+	argsForCall := fake.appendRoomEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeRoomEventStore) AppendRoomEventsReturns(result1 error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = nil
+	fake.appendRoomEventsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomEventStore) AppendRoomEventsReturnsOnCall(i int, result1 error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = nil
+	if fake.appendRoomEventsReturnsOnCall == nil {
+		fake.appendRoomEventsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.appendRoomEventsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomEventStore) ListRoomEvents(arg1 context.Context, arg2 livekit.RoomName, arg3 int64, arg4 int) ([]*rtc.RoomEvent, int64, error) {
+	fake.listRoomEventsMutex.Lock()
+	ret, specificReturn := fake.listRoomEventsReturnsOnCall[len(fake.listRoomEventsArgsForCall)]
+	fake.listRoomEventsArgsForCall = append(fake.listRoomEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 int64
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListRoomEventsStub
+	fakeReturns := fake.listRoomEventsReturns
+	fake.recordInvocation("ListRoomEvents", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listRoomEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeRoomEventStore) ListRoomEventsCallCount() int {
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	return len(fake.listRoomEventsArgsForCall)
+}
+
+func (fake *FakeRoomEventStore) ListRoomEventsCalls(stub func(context.Context, livekit.RoomName, int64, int) ([]*rtc.RoomEvent, int64, error)) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = stub
+}
+
+func (fake *FakeRoomEventStore) ListRoomEventsArgsForCall(i int) (context.Context, livekit.RoomName, int64, int) {
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	argsForCall := fake.listRoomEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeRoomEventStore) ListRoomEventsReturns(result1 []*rtc.RoomEvent, result2 int64, result3 error) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = nil
+	fake.listRoomEventsReturns = struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRoomEventStore) ListRoomEventsReturnsOnCall(i int, result1 []*rtc.RoomEvent, result2 int64, result3 error) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = nil
+	if fake.listRoomEventsReturnsOnCall == nil {
+		fake.listRoomEventsReturnsOnCall = make(map[int]struct {
+			result1 []*rtc.RoomEvent
+			result2 int64
+			result3 error
+		})
+	}
+	fake.listRoomEventsReturnsOnCall[i] = struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRoomEventStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRoomEventStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ service.RoomEventStore = new(FakeRoomEventStore)
@@ -0,0 +1,200 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package servicefakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/protocol/livekit"
+)
+
+type FakeRoomQualityReportStore struct {
+	LoadRoomQualityReportStub        func(context.Context, livekit.RoomName) (*telemetry.QualityReport, error)
+	loadRoomQualityReportMutex       sync.RWMutex
+	loadRoomQualityReportArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomQualityReportReturns struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}
+	loadRoomQualityReportReturnsOnCall map[int]struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}
+	StoreRoomQualityReportStub        func(context.Context, *telemetry.QualityReport, time.Duration) error
+	storeRoomQualityReportMutex       sync.RWMutex
+	storeRoomQualityReportArgsForCall []struct {
+		arg1 context.Context
+		arg2 *telemetry.QualityReport
+		arg3 time.Duration
+	}
+	storeRoomQualityReportReturns struct {
+		result1 error
+	}
+	storeRoomQualityReportReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRoomQualityReportStore) LoadRoomQualityReport(arg1 context.Context, arg2 livekit.RoomName) (*telemetry.QualityReport, error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	ret, specificReturn := fake.loadRoomQualityReportReturnsOnCall[len(fake.loadRoomQualityReportArgsForCall)]
+	fake.loadRoomQualityReportArgsForCall = append(fake.loadRoomQualityReportArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomQualityReportStub
+	fakeReturns := fake.loadRoomQualityReportReturns
+	fake.recordInvocation("LoadRoomQualityReport", []interface{}{arg1, arg2})
+	fake.loadRoomQualityReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRoomQualityReportStore) LoadRoomQualityReportCallCount() int {
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	return len(fake.loadRoomQualityReportArgsForCall)
+}
+
+func (fake *FakeRoomQualityReportStore) LoadRoomQualityReportCalls(stub func(context.Context, livekit.RoomName) (*telemetry.QualityReport, error)) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = stub
+}
+
+func (fake *FakeRoomQualityReportStore) LoadRoomQualityReportArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	argsForCall := fake.loadRoomQualityReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRoomQualityReportStore) LoadRoomQualityReportReturns(result1 *telemetry.QualityReport, result2 error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = nil
+	fake.loadRoomQualityReportReturns = struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRoomQualityReportStore) LoadRoomQualityReportReturnsOnCall(i int, result1 *telemetry.QualityReport, result2 error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = nil
+	if fake.loadRoomQualityReportReturnsOnCall == nil {
+		fake.loadRoomQualityReportReturnsOnCall = make(map[int]struct {
+			result1 *telemetry.QualityReport
+			result2 error
+		})
+	}
+	fake.loadRoomQualityReportReturnsOnCall[i] = struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRoomQualityReportStore) StoreRoomQualityReport(arg1 context.Context, arg2 *telemetry.QualityReport, arg3 time.Duration) error {
+	fake.storeRoomQualityReportMutex.Lock()
+	ret, specificReturn := fake.storeRoomQualityReportReturnsOnCall[len(fake.storeRoomQualityReportArgsForCall)]
+	fake.storeRoomQualityReportArgsForCall = append(fake.storeRoomQualityReportArgsForCall, struct {
+		arg1 context.Context
+		arg2 *telemetry.QualityReport
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.StoreRoomQualityReportStub
+	fakeReturns := fake.storeRoomQualityReportReturns
+	fake.recordInvocation("StoreRoomQualityReport", []interface{}{arg1, arg2, arg3})
+	fake.storeRoomQualityReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRoomQualityReportStore) StoreRoomQualityReportCallCount() int {
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	return len(fake.storeRoomQualityReportArgsForCall)
+}
+
+func (fake *FakeRoomQualityReportStore) StoreRoomQualityReportCalls(stub func(context.Context, *telemetry.QualityReport, time.Duration) error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = stub
+}
+
+func (fake *FakeRoomQualityReportStore) StoreRoomQualityReportArgsForCall(i int) (context.Context, *telemetry.QualityReport, time.Duration) {
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	argsForCall := fake.storeRoomQualityReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeRoomQualityReportStore) StoreRoomQualityReportReturns(result1 error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = nil
+	fake.storeRoomQualityReportReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomQualityReportStore) StoreRoomQualityReportReturnsOnCall(i int, result1 error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = nil
+	if fake.storeRoomQualityReportReturnsOnCall == nil {
+		fake.storeRoomQualityReportReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomQualityReportReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomQualityReportStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRoomQualityReportStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ service.RoomQualityReportStore = new(FakeRoomQualityReportStore)
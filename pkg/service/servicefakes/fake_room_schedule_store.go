@@ -0,0 +1,358 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package servicefakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/protocol/livekit"
+)
+
+type FakeRoomScheduleStore struct {
+	DeleteRoomScheduleStub        func(context.Context, livekit.RoomName) error
+	deleteRoomScheduleMutex       sync.RWMutex
+	deleteRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	deleteRoomScheduleReturns struct {
+		result1 error
+	}
+	deleteRoomScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ListRoomSchedulesStub        func(context.Context, []livekit.RoomName) ([]*service.RoomSchedule, error)
+	listRoomSchedulesMutex       sync.RWMutex
+	listRoomSchedulesArgsForCall []struct {
+		arg1 context.Context
+		arg2 []livekit.RoomName
+	}
+	listRoomSchedulesReturns struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}
+	listRoomSchedulesReturnsOnCall map[int]struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}
+	LoadRoomScheduleStub        func(context.Context, livekit.RoomName) (*service.RoomSchedule, error)
+	loadRoomScheduleMutex       sync.RWMutex
+	loadRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomScheduleReturns struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}
+	loadRoomScheduleReturnsOnCall map[int]struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}
+	StoreRoomScheduleStub        func(context.Context, *service.RoomSchedule) error
+	storeRoomScheduleMutex       sync.RWMutex
+	storeRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 *service.RoomSchedule
+	}
+	storeRoomScheduleReturns struct {
+		result1 error
+	}
+	storeRoomScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRoomScheduleStore) DeleteRoomSchedule(arg1 context.Context, arg2 livekit.RoomName) error {
+	fake.deleteRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.deleteRoomScheduleReturnsOnCall[len(fake.deleteRoomScheduleArgsForCall)]
+	fake.deleteRoomScheduleArgsForCall = append(fake.deleteRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.DeleteRoomScheduleStub
+	fakeReturns := fake.deleteRoomScheduleReturns
+	fake.recordInvocation("DeleteRoomSchedule", []interface{}{arg1, arg2})
+	fake.deleteRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRoomScheduleStore) DeleteRoomScheduleCallCount() int {
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	return len(fake.deleteRoomScheduleArgsForCall)
+}
+
+func (fake *FakeRoomScheduleStore) DeleteRoomScheduleCalls(stub func(context.Context, livekit.RoomName) error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = stub
+}
+
+func (fake *FakeRoomScheduleStore) DeleteRoomScheduleArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	argsForCall := fake.deleteRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRoomScheduleStore) DeleteRoomScheduleReturns(result1 error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = nil
+	fake.deleteRoomScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomScheduleStore) DeleteRoomScheduleReturnsOnCall(i int, result1 error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = nil
+	if fake.deleteRoomScheduleReturnsOnCall == nil {
+		fake.deleteRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteRoomScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomScheduleStore) ListRoomSchedules(arg1 context.Context, arg2 []livekit.RoomName) ([]*service.RoomSchedule, error) {
+	var arg2Copy []livekit.RoomName
+	if arg2 != nil {
+		arg2Copy = make([]livekit.RoomName, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.listRoomSchedulesMutex.Lock()
+	ret, specificReturn := fake.listRoomSchedulesReturnsOnCall[len(fake.listRoomSchedulesArgsForCall)]
+	fake.listRoomSchedulesArgsForCall = append(fake.listRoomSchedulesArgsForCall, struct {
+		arg1 context.Context
+		arg2 []livekit.RoomName
+	}{arg1, arg2Copy})
+	stub := fake.ListRoomSchedulesStub
+	fakeReturns := fake.listRoomSchedulesReturns
+	fake.recordInvocation("ListRoomSchedules", []interface{}{arg1, arg2Copy})
+	fake.listRoomSchedulesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRoomScheduleStore) ListRoomSchedulesCallCount() int {
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
+	return len(fake.listRoomSchedulesArgsForCall)
+}
+
+func (fake *FakeRoomScheduleStore) ListRoomSchedulesCalls(stub func(context.Context, []livekit.RoomName) ([]*service.RoomSchedule, error)) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = stub
+}
+
+func (fake *FakeRoomScheduleStore) ListRoomSchedulesArgsForCall(i int) (context.Context, []livekit.RoomName) {
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
+	argsForCall := fake.listRoomSchedulesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRoomScheduleStore) ListRoomSchedulesReturns(result1 []*service.RoomSchedule, result2 error) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = nil
+	fake.listRoomSchedulesReturns = struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRoomScheduleStore) ListRoomSchedulesReturnsOnCall(i int, result1 []*service.RoomSchedule, result2 error) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = nil
+	if fake.listRoomSchedulesReturnsOnCall == nil {
+		fake.listRoomSchedulesReturnsOnCall = make(map[int]struct {
+			result1 []*service.RoomSchedule
+			result2 error
+		})
+	}
+	fake.listRoomSchedulesReturnsOnCall[i] = struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRoomScheduleStore) LoadRoomSchedule(arg1 context.Context, arg2 livekit.RoomName) (*service.RoomSchedule, error) {
+	fake.loadRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.loadRoomScheduleReturnsOnCall[len(fake.loadRoomScheduleArgsForCall)]
+	fake.loadRoomScheduleArgsForCall = append(fake.loadRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomScheduleStub
+	fakeReturns := fake.loadRoomScheduleReturns
+	fake.recordInvocation("LoadRoomSchedule", []interface{}{arg1, arg2})
+	fake.loadRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRoomScheduleStore) LoadRoomScheduleCallCount() int {
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	return len(fake.loadRoomScheduleArgsForCall)
+}
+
+func (fake *FakeRoomScheduleStore) LoadRoomScheduleCalls(stub func(context.Context, livekit.RoomName) (*service.RoomSchedule, error)) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = stub
+}
+
+func (fake *FakeRoomScheduleStore) LoadRoomScheduleArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	argsForCall := fake.loadRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRoomScheduleStore) LoadRoomScheduleReturns(result1 *service.RoomSchedule, result2 error) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = nil
+	fake.loadRoomScheduleReturns = struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRoomScheduleStore) LoadRoomScheduleReturnsOnCall(i int, result1 *service.RoomSchedule, result2 error) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = nil
+	if fake.loadRoomScheduleReturnsOnCall == nil {
+		fake.loadRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 *service.RoomSchedule
+			result2 error
+		})
+	}
+	fake.loadRoomScheduleReturnsOnCall[i] = struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRoomScheduleStore) StoreRoomSchedule(arg1 context.Context, arg2 *service.RoomSchedule) error {
+	fake.storeRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.storeRoomScheduleReturnsOnCall[len(fake.storeRoomScheduleArgsForCall)]
+	fake.storeRoomScheduleArgsForCall = append(fake.storeRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 *service.RoomSchedule
+	}{arg1, arg2})
+	stub := fake.StoreRoomScheduleStub
+	fakeReturns := fake.storeRoomScheduleReturns
+	fake.recordInvocation("StoreRoomSchedule", []interface{}{arg1, arg2})
+	fake.storeRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRoomScheduleStore) StoreRoomScheduleCallCount() int {
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	return len(fake.storeRoomScheduleArgsForCall)
+}
+
+func (fake *FakeRoomScheduleStore) StoreRoomScheduleCalls(stub func(context.Context, *service.RoomSchedule) error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = stub
+}
+
+func (fake *FakeRoomScheduleStore) StoreRoomScheduleArgsForCall(i int) (context.Context, *service.RoomSchedule) {
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	argsForCall := fake.storeRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeRoomScheduleStore) StoreRoomScheduleReturns(result1 error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = nil
+	fake.storeRoomScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomScheduleStore) StoreRoomScheduleReturnsOnCall(i int, result1 error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = nil
+	if fake.storeRoomScheduleReturnsOnCall == nil {
+		fake.storeRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRoomScheduleStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRoomScheduleStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ service.RoomScheduleStore = new(FakeRoomScheduleStore)
@@ -4,12 +4,30 @@ package servicefakes
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/protocol/livekit"
 )
 
 type FakeServiceStore struct {
+	AppendRoomEventsStub        func(context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) error
+	appendRoomEventsMutex       sync.RWMutex
+	appendRoomEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 []*rtc.RoomEvent
+		arg4 time.Duration
+		arg5 int
+	}
+	appendRoomEventsReturns struct {
+		result1 error
+	}
+	appendRoomEventsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeleteRoomStub        func(context.Context, livekit.RoomName) error
 	deleteRoomMutex       sync.RWMutex
 	deleteRoomArgsForCall []struct {
@@ -22,6 +40,30 @@ type FakeServiceStore struct {
 	deleteRoomReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteRoomScheduleStub        func(context.Context, livekit.RoomName) error
+	deleteRoomScheduleMutex       sync.RWMutex
+	deleteRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	deleteRoomScheduleReturns struct {
+		result1 error
+	}
+	deleteRoomScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteRoomSnapshotStub        func(context.Context, livekit.RoomName) error
+	deleteRoomSnapshotMutex       sync.RWMutex
+	deleteRoomSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	deleteRoomSnapshotReturns struct {
+		result1 error
+	}
+	deleteRoomSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ListParticipantsStub        func(context.Context, livekit.RoomName) ([]*livekit.ParticipantInfo, error)
 	listParticipantsMutex       sync.RWMutex
 	listParticipantsArgsForCall []struct {
@@ -36,6 +78,38 @@ type FakeServiceStore struct {
 		result1 []*livekit.ParticipantInfo
 		result2 error
 	}
+	ListRoomEventsStub        func(context.Context, livekit.RoomName, int64, int) ([]*rtc.RoomEvent, int64, error)
+	listRoomEventsMutex       sync.RWMutex
+	listRoomEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 int64
+		arg4 int
+	}
+	listRoomEventsReturns struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}
+	listRoomEventsReturnsOnCall map[int]struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}
+	ListRoomSchedulesStub        func(context.Context, []livekit.RoomName) ([]*service.RoomSchedule, error)
+	listRoomSchedulesMutex       sync.RWMutex
+	listRoomSchedulesArgsForCall []struct {
+		arg1 context.Context
+		arg2 []livekit.RoomName
+	}
+	listRoomSchedulesReturns struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}
+	listRoomSchedulesReturnsOnCall map[int]struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}
 	ListRoomsStub        func(context.Context, []livekit.RoomName) ([]*livekit.Room, error)
 	listRoomsMutex       sync.RWMutex
 	listRoomsArgsForCall []struct {
@@ -82,10 +156,186 @@ type FakeServiceStore struct {
 		result2 *livekit.RoomInternal
 		result3 error
 	}
+	LoadRoomParticipantPolicyStub        func(context.Context, livekit.RoomName) (*service.RoomParticipantPolicy, error)
+	loadRoomParticipantPolicyMutex       sync.RWMutex
+	loadRoomParticipantPolicyArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomParticipantPolicyReturns struct {
+		result1 *service.RoomParticipantPolicy
+		result2 error
+	}
+	loadRoomParticipantPolicyReturnsOnCall map[int]struct {
+		result1 *service.RoomParticipantPolicy
+		result2 error
+	}
+	LoadRoomQualityReportStub        func(context.Context, livekit.RoomName) (*telemetry.QualityReport, error)
+	loadRoomQualityReportMutex       sync.RWMutex
+	loadRoomQualityReportArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomQualityReportReturns struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}
+	loadRoomQualityReportReturnsOnCall map[int]struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}
+	LoadRoomScheduleStub        func(context.Context, livekit.RoomName) (*service.RoomSchedule, error)
+	loadRoomScheduleMutex       sync.RWMutex
+	loadRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomScheduleReturns struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}
+	loadRoomScheduleReturnsOnCall map[int]struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}
+	LoadRoomSnapshotStub        func(context.Context, livekit.RoomName) (*rtc.RoomSnapshot, error)
+	loadRoomSnapshotMutex       sync.RWMutex
+	loadRoomSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}
+	loadRoomSnapshotReturns struct {
+		result1 *rtc.RoomSnapshot
+		result2 error
+	}
+	loadRoomSnapshotReturnsOnCall map[int]struct {
+		result1 *rtc.RoomSnapshot
+		result2 error
+	}
+	StoreRoomParticipantPolicyStub        func(context.Context, *service.RoomParticipantPolicy) error
+	storeRoomParticipantPolicyMutex       sync.RWMutex
+	storeRoomParticipantPolicyArgsForCall []struct {
+		arg1 context.Context
+		arg2 *service.RoomParticipantPolicy
+	}
+	storeRoomParticipantPolicyReturns struct {
+		result1 error
+	}
+	storeRoomParticipantPolicyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreRoomQualityReportStub        func(context.Context, *telemetry.QualityReport, time.Duration) error
+	storeRoomQualityReportMutex       sync.RWMutex
+	storeRoomQualityReportArgsForCall []struct {
+		arg1 context.Context
+		arg2 *telemetry.QualityReport
+		arg3 time.Duration
+	}
+	storeRoomQualityReportReturns struct {
+		result1 error
+	}
+	storeRoomQualityReportReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreRoomScheduleStub        func(context.Context, *service.RoomSchedule) error
+	storeRoomScheduleMutex       sync.RWMutex
+	storeRoomScheduleArgsForCall []struct {
+		arg1 context.Context
+		arg2 *service.RoomSchedule
+	}
+	storeRoomScheduleReturns struct {
+		result1 error
+	}
+	storeRoomScheduleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StoreRoomSnapshotStub        func(context.Context, *rtc.RoomSnapshot, time.Duration) error
+	storeRoomSnapshotMutex       sync.RWMutex
+	storeRoomSnapshotArgsForCall []struct {
+		arg1 context.Context
+		arg2 *rtc.RoomSnapshot
+		arg3 time.Duration
+	}
+	storeRoomSnapshotReturns struct {
+		result1 error
+	}
+	storeRoomSnapshotReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeServiceStore) AppendRoomEvents(arg1 context.Context, arg2 livekit.RoomName, arg3 []*rtc.RoomEvent, arg4 time.Duration, arg5 int) error {
+	var arg3Copy []*rtc.RoomEvent
+	if arg3 != nil {
+		arg3Copy = make([]*rtc.RoomEvent, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.appendRoomEventsMutex.Lock()
+	ret, specificReturn := fake.appendRoomEventsReturnsOnCall[len(fake.appendRoomEventsArgsForCall)]
+	fake.appendRoomEventsArgsForCall = append(fake.appendRoomEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 []*rtc.RoomEvent
+		arg4 time.Duration
+		arg5 int
+	}{arg1, arg2, arg3Copy, arg4, arg5})
+	stub := fake.AppendRoomEventsStub
+	fakeReturns := fake.appendRoomEventsReturns
+	fake.recordInvocation("AppendRoomEvents", []interface{}{arg1, arg2, arg3Copy, arg4, arg5})
+	fake.appendRoomEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceStore) AppendRoomEventsCallCount() int {
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
+	return len(fake.appendRoomEventsArgsForCall)
+}
+
+func (fake *FakeServiceStore) AppendRoomEventsCalls(stub func(context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = stub
+}
+
+func (fake *FakeServiceStore) AppendRoomEventsArgsForCall(i int) (context.Context, livekit.RoomName, []*rtc.RoomEvent, time.Duration, int) {
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
+	argsForCall := fake.appendRoomEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeServiceStore) AppendRoomEventsReturns(result1 error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = nil
+	fake.appendRoomEventsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) AppendRoomEventsReturnsOnCall(i int, result1 error) {
+	fake.appendRoomEventsMutex.Lock()
+	defer fake.appendRoomEventsMutex.Unlock()
+	fake.AppendRoomEventsStub = nil
+	if fake.appendRoomEventsReturnsOnCall == nil {
+		fake.appendRoomEventsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.appendRoomEventsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeServiceStore) DeleteRoom(arg1 context.Context, arg2 livekit.RoomName) error {
 	fake.deleteRoomMutex.Lock()
 	ret, specificReturn := fake.deleteRoomReturnsOnCall[len(fake.deleteRoomArgsForCall)]
@@ -148,6 +398,130 @@ func (fake *FakeServiceStore) DeleteRoomReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeServiceStore) DeleteRoomSchedule(arg1 context.Context, arg2 livekit.RoomName) error {
+	fake.deleteRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.deleteRoomScheduleReturnsOnCall[len(fake.deleteRoomScheduleArgsForCall)]
+	fake.deleteRoomScheduleArgsForCall = append(fake.deleteRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.DeleteRoomScheduleStub
+	fakeReturns := fake.deleteRoomScheduleReturns
+	fake.recordInvocation("DeleteRoomSchedule", []interface{}{arg1, arg2})
+	fake.deleteRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceStore) DeleteRoomScheduleCallCount() int {
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	return len(fake.deleteRoomScheduleArgsForCall)
+}
+
+func (fake *FakeServiceStore) DeleteRoomScheduleCalls(stub func(context.Context, livekit.RoomName) error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = stub
+}
+
+func (fake *FakeServiceStore) DeleteRoomScheduleArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	argsForCall := fake.deleteRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) DeleteRoomScheduleReturns(result1 error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = nil
+	fake.deleteRoomScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) DeleteRoomScheduleReturnsOnCall(i int, result1 error) {
+	fake.deleteRoomScheduleMutex.Lock()
+	defer fake.deleteRoomScheduleMutex.Unlock()
+	fake.DeleteRoomScheduleStub = nil
+	if fake.deleteRoomScheduleReturnsOnCall == nil {
+		fake.deleteRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteRoomScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) DeleteRoomSnapshot(arg1 context.Context, arg2 livekit.RoomName) error {
+	fake.deleteRoomSnapshotMutex.Lock()
+	ret, specificReturn := fake.deleteRoomSnapshotReturnsOnCall[len(fake.deleteRoomSnapshotArgsForCall)]
+	fake.deleteRoomSnapshotArgsForCall = append(fake.deleteRoomSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.DeleteRoomSnapshotStub
+	fakeReturns := fake.deleteRoomSnapshotReturns
+	fake.recordInvocation("DeleteRoomSnapshot", []interface{}{arg1, arg2})
+	fake.deleteRoomSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceStore) DeleteRoomSnapshotCallCount() int {
+	fake.deleteRoomSnapshotMutex.RLock()
+	defer fake.deleteRoomSnapshotMutex.RUnlock()
+	return len(fake.deleteRoomSnapshotArgsForCall)
+}
+
+func (fake *FakeServiceStore) DeleteRoomSnapshotCalls(stub func(context.Context, livekit.RoomName) error) {
+	fake.deleteRoomSnapshotMutex.Lock()
+	defer fake.deleteRoomSnapshotMutex.Unlock()
+	fake.DeleteRoomSnapshotStub = stub
+}
+
+func (fake *FakeServiceStore) DeleteRoomSnapshotArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.deleteRoomSnapshotMutex.RLock()
+	defer fake.deleteRoomSnapshotMutex.RUnlock()
+	argsForCall := fake.deleteRoomSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) DeleteRoomSnapshotReturns(result1 error) {
+	fake.deleteRoomSnapshotMutex.Lock()
+	defer fake.deleteRoomSnapshotMutex.Unlock()
+	fake.DeleteRoomSnapshotStub = nil
+	fake.deleteRoomSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) DeleteRoomSnapshotReturnsOnCall(i int, result1 error) {
+	fake.deleteRoomSnapshotMutex.Lock()
+	defer fake.deleteRoomSnapshotMutex.Unlock()
+	fake.DeleteRoomSnapshotStub = nil
+	if fake.deleteRoomSnapshotReturnsOnCall == nil {
+		fake.deleteRoomSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteRoomSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeServiceStore) ListParticipants(arg1 context.Context, arg2 livekit.RoomName) ([]*livekit.ParticipantInfo, error) {
 	fake.listParticipantsMutex.Lock()
 	ret, specificReturn := fake.listParticipantsReturnsOnCall[len(fake.listParticipantsArgsForCall)]
@@ -213,6 +587,146 @@ func (fake *FakeServiceStore) ListParticipantsReturnsOnCall(i int, result1 []*li
 	}{result1, result2}
 }
 
+func (fake *FakeServiceStore) ListRoomEvents(arg1 context.Context, arg2 livekit.RoomName, arg3 int64, arg4 int) ([]*rtc.RoomEvent, int64, error) {
+	fake.listRoomEventsMutex.Lock()
+	ret, specificReturn := fake.listRoomEventsReturnsOnCall[len(fake.listRoomEventsArgsForCall)]
+	fake.listRoomEventsArgsForCall = append(fake.listRoomEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 int64
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListRoomEventsStub
+	fakeReturns := fake.listRoomEventsReturns
+	fake.recordInvocation("ListRoomEvents", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listRoomEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeServiceStore) ListRoomEventsCallCount() int {
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	return len(fake.listRoomEventsArgsForCall)
+}
+
+func (fake *FakeServiceStore) ListRoomEventsCalls(stub func(context.Context, livekit.RoomName, int64, int) ([]*rtc.RoomEvent, int64, error)) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = stub
+}
+
+func (fake *FakeServiceStore) ListRoomEventsArgsForCall(i int) (context.Context, livekit.RoomName, int64, int) {
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	argsForCall := fake.listRoomEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeServiceStore) ListRoomEventsReturns(result1 []*rtc.RoomEvent, result2 int64, result3 error) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = nil
+	fake.listRoomEventsReturns = struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeServiceStore) ListRoomEventsReturnsOnCall(i int, result1 []*rtc.RoomEvent, result2 int64, result3 error) {
+	fake.listRoomEventsMutex.Lock()
+	defer fake.listRoomEventsMutex.Unlock()
+	fake.ListRoomEventsStub = nil
+	if fake.listRoomEventsReturnsOnCall == nil {
+		fake.listRoomEventsReturnsOnCall = make(map[int]struct {
+			result1 []*rtc.RoomEvent
+			result2 int64
+			result3 error
+		})
+	}
+	fake.listRoomEventsReturnsOnCall[i] = struct {
+		result1 []*rtc.RoomEvent
+		result2 int64
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeServiceStore) ListRoomSchedules(arg1 context.Context, arg2 []livekit.RoomName) ([]*service.RoomSchedule, error) {
+	var arg2Copy []livekit.RoomName
+	if arg2 != nil {
+		arg2Copy = make([]livekit.RoomName, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.listRoomSchedulesMutex.Lock()
+	ret, specificReturn := fake.listRoomSchedulesReturnsOnCall[len(fake.listRoomSchedulesArgsForCall)]
+	fake.listRoomSchedulesArgsForCall = append(fake.listRoomSchedulesArgsForCall, struct {
+		arg1 context.Context
+		arg2 []livekit.RoomName
+	}{arg1, arg2Copy})
+	stub := fake.ListRoomSchedulesStub
+	fakeReturns := fake.listRoomSchedulesReturns
+	fake.recordInvocation("ListRoomSchedules", []interface{}{arg1, arg2Copy})
+	fake.listRoomSchedulesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceStore) ListRoomSchedulesCallCount() int {
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
+	return len(fake.listRoomSchedulesArgsForCall)
+}
+
+func (fake *FakeServiceStore) ListRoomSchedulesCalls(stub func(context.Context, []livekit.RoomName) ([]*service.RoomSchedule, error)) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = stub
+}
+
+func (fake *FakeServiceStore) ListRoomSchedulesArgsForCall(i int) (context.Context, []livekit.RoomName) {
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
+	argsForCall := fake.listRoomSchedulesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) ListRoomSchedulesReturns(result1 []*service.RoomSchedule, result2 error) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = nil
+	fake.listRoomSchedulesReturns = struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) ListRoomSchedulesReturnsOnCall(i int, result1 []*service.RoomSchedule, result2 error) {
+	fake.listRoomSchedulesMutex.Lock()
+	defer fake.listRoomSchedulesMutex.Unlock()
+	fake.ListRoomSchedulesStub = nil
+	if fake.listRoomSchedulesReturnsOnCall == nil {
+		fake.listRoomSchedulesReturnsOnCall = make(map[int]struct {
+			result1 []*service.RoomSchedule
+			result2 error
+		})
+	}
+	fake.listRoomSchedulesReturnsOnCall[i] = struct {
+		result1 []*service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeServiceStore) ListRooms(arg1 context.Context, arg2 []livekit.RoomName) ([]*livekit.Room, error) {
 	var arg2Copy []livekit.RoomName
 	if arg2 != nil {
@@ -418,19 +932,555 @@ func (fake *FakeServiceStore) LoadRoomReturnsOnCall(i int, result1 *livekit.Room
 	}{result1, result2, result3}
 }
 
+func (fake *FakeServiceStore) LoadRoomParticipantPolicy(arg1 context.Context, arg2 livekit.RoomName) (*service.RoomParticipantPolicy, error) {
+	fake.loadRoomParticipantPolicyMutex.Lock()
+	ret, specificReturn := fake.loadRoomParticipantPolicyReturnsOnCall[len(fake.loadRoomParticipantPolicyArgsForCall)]
+	fake.loadRoomParticipantPolicyArgsForCall = append(fake.loadRoomParticipantPolicyArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomParticipantPolicyStub
+	fakeReturns := fake.loadRoomParticipantPolicyReturns
+	fake.recordInvocation("LoadRoomParticipantPolicy", []interface{}{arg1, arg2})
+	fake.loadRoomParticipantPolicyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceStore) LoadRoomParticipantPolicyCallCount() int {
+	fake.loadRoomParticipantPolicyMutex.RLock()
+	defer fake.loadRoomParticipantPolicyMutex.RUnlock()
+	return len(fake.loadRoomParticipantPolicyArgsForCall)
+}
+
+func (fake *FakeServiceStore) LoadRoomParticipantPolicyCalls(stub func(context.Context, livekit.RoomName) (*service.RoomParticipantPolicy, error)) {
+	fake.loadRoomParticipantPolicyMutex.Lock()
+	defer fake.loadRoomParticipantPolicyMutex.Unlock()
+	fake.LoadRoomParticipantPolicyStub = stub
+}
+
+func (fake *FakeServiceStore) LoadRoomParticipantPolicyArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomParticipantPolicyMutex.RLock()
+	defer fake.loadRoomParticipantPolicyMutex.RUnlock()
+	argsForCall := fake.loadRoomParticipantPolicyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) LoadRoomParticipantPolicyReturns(result1 *service.RoomParticipantPolicy, result2 error) {
+	fake.loadRoomParticipantPolicyMutex.Lock()
+	defer fake.loadRoomParticipantPolicyMutex.Unlock()
+	fake.LoadRoomParticipantPolicyStub = nil
+	fake.loadRoomParticipantPolicyReturns = struct {
+		result1 *service.RoomParticipantPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) LoadRoomParticipantPolicyReturnsOnCall(i int, result1 *service.RoomParticipantPolicy, result2 error) {
+	fake.loadRoomParticipantPolicyMutex.Lock()
+	defer fake.loadRoomParticipantPolicyMutex.Unlock()
+	fake.LoadRoomParticipantPolicyStub = nil
+	if fake.loadRoomParticipantPolicyReturnsOnCall == nil {
+		fake.loadRoomParticipantPolicyReturnsOnCall = make(map[int]struct {
+			result1 *service.RoomParticipantPolicy
+			result2 error
+		})
+	}
+	fake.loadRoomParticipantPolicyReturnsOnCall[i] = struct {
+		result1 *service.RoomParticipantPolicy
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) LoadRoomQualityReport(arg1 context.Context, arg2 livekit.RoomName) (*telemetry.QualityReport, error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	ret, specificReturn := fake.loadRoomQualityReportReturnsOnCall[len(fake.loadRoomQualityReportArgsForCall)]
+	fake.loadRoomQualityReportArgsForCall = append(fake.loadRoomQualityReportArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomQualityReportStub
+	fakeReturns := fake.loadRoomQualityReportReturns
+	fake.recordInvocation("LoadRoomQualityReport", []interface{}{arg1, arg2})
+	fake.loadRoomQualityReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceStore) LoadRoomQualityReportCallCount() int {
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	return len(fake.loadRoomQualityReportArgsForCall)
+}
+
+func (fake *FakeServiceStore) LoadRoomQualityReportCalls(stub func(context.Context, livekit.RoomName) (*telemetry.QualityReport, error)) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = stub
+}
+
+func (fake *FakeServiceStore) LoadRoomQualityReportArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	argsForCall := fake.loadRoomQualityReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) LoadRoomQualityReportReturns(result1 *telemetry.QualityReport, result2 error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = nil
+	fake.loadRoomQualityReportReturns = struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) LoadRoomQualityReportReturnsOnCall(i int, result1 *telemetry.QualityReport, result2 error) {
+	fake.loadRoomQualityReportMutex.Lock()
+	defer fake.loadRoomQualityReportMutex.Unlock()
+	fake.LoadRoomQualityReportStub = nil
+	if fake.loadRoomQualityReportReturnsOnCall == nil {
+		fake.loadRoomQualityReportReturnsOnCall = make(map[int]struct {
+			result1 *telemetry.QualityReport
+			result2 error
+		})
+	}
+	fake.loadRoomQualityReportReturnsOnCall[i] = struct {
+		result1 *telemetry.QualityReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) LoadRoomSchedule(arg1 context.Context, arg2 livekit.RoomName) (*service.RoomSchedule, error) {
+	fake.loadRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.loadRoomScheduleReturnsOnCall[len(fake.loadRoomScheduleArgsForCall)]
+	fake.loadRoomScheduleArgsForCall = append(fake.loadRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomScheduleStub
+	fakeReturns := fake.loadRoomScheduleReturns
+	fake.recordInvocation("LoadRoomSchedule", []interface{}{arg1, arg2})
+	fake.loadRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceStore) LoadRoomScheduleCallCount() int {
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	return len(fake.loadRoomScheduleArgsForCall)
+}
+
+func (fake *FakeServiceStore) LoadRoomScheduleCalls(stub func(context.Context, livekit.RoomName) (*service.RoomSchedule, error)) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = stub
+}
+
+func (fake *FakeServiceStore) LoadRoomScheduleArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	argsForCall := fake.loadRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) LoadRoomScheduleReturns(result1 *service.RoomSchedule, result2 error) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = nil
+	fake.loadRoomScheduleReturns = struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) LoadRoomScheduleReturnsOnCall(i int, result1 *service.RoomSchedule, result2 error) {
+	fake.loadRoomScheduleMutex.Lock()
+	defer fake.loadRoomScheduleMutex.Unlock()
+	fake.LoadRoomScheduleStub = nil
+	if fake.loadRoomScheduleReturnsOnCall == nil {
+		fake.loadRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 *service.RoomSchedule
+			result2 error
+		})
+	}
+	fake.loadRoomScheduleReturnsOnCall[i] = struct {
+		result1 *service.RoomSchedule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) LoadRoomSnapshot(arg1 context.Context, arg2 livekit.RoomName) (*rtc.RoomSnapshot, error) {
+	fake.loadRoomSnapshotMutex.Lock()
+	ret, specificReturn := fake.loadRoomSnapshotReturnsOnCall[len(fake.loadRoomSnapshotArgsForCall)]
+	fake.loadRoomSnapshotArgsForCall = append(fake.loadRoomSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+	}{arg1, arg2})
+	stub := fake.LoadRoomSnapshotStub
+	fakeReturns := fake.loadRoomSnapshotReturns
+	fake.recordInvocation("LoadRoomSnapshot", []interface{}{arg1, arg2})
+	fake.loadRoomSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeServiceStore) LoadRoomSnapshotCallCount() int {
+	fake.loadRoomSnapshotMutex.RLock()
+	defer fake.loadRoomSnapshotMutex.RUnlock()
+	return len(fake.loadRoomSnapshotArgsForCall)
+}
+
+func (fake *FakeServiceStore) LoadRoomSnapshotCalls(stub func(context.Context, livekit.RoomName) (*rtc.RoomSnapshot, error)) {
+	fake.loadRoomSnapshotMutex.Lock()
+	defer fake.loadRoomSnapshotMutex.Unlock()
+	fake.LoadRoomSnapshotStub = stub
+}
+
+func (fake *FakeServiceStore) LoadRoomSnapshotArgsForCall(i int) (context.Context, livekit.RoomName) {
+	fake.loadRoomSnapshotMutex.RLock()
+	defer fake.loadRoomSnapshotMutex.RUnlock()
+	argsForCall := fake.loadRoomSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) LoadRoomSnapshotReturns(result1 *rtc.RoomSnapshot, result2 error) {
+	fake.loadRoomSnapshotMutex.Lock()
+	defer fake.loadRoomSnapshotMutex.Unlock()
+	fake.LoadRoomSnapshotStub = nil
+	fake.loadRoomSnapshotReturns = struct {
+		result1 *rtc.RoomSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) LoadRoomSnapshotReturnsOnCall(i int, result1 *rtc.RoomSnapshot, result2 error) {
+	fake.loadRoomSnapshotMutex.Lock()
+	defer fake.loadRoomSnapshotMutex.Unlock()
+	fake.LoadRoomSnapshotStub = nil
+	if fake.loadRoomSnapshotReturnsOnCall == nil {
+		fake.loadRoomSnapshotReturnsOnCall = make(map[int]struct {
+			result1 *rtc.RoomSnapshot
+			result2 error
+		})
+	}
+	fake.loadRoomSnapshotReturnsOnCall[i] = struct {
+		result1 *rtc.RoomSnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeServiceStore) StoreRoomParticipantPolicy(arg1 context.Context, arg2 *service.RoomParticipantPolicy) error {
+	fake.storeRoomParticipantPolicyMutex.Lock()
+	ret, specificReturn := fake.storeRoomParticipantPolicyReturnsOnCall[len(fake.storeRoomParticipantPolicyArgsForCall)]
+	fake.storeRoomParticipantPolicyArgsForCall = append(fake.storeRoomParticipantPolicyArgsForCall, struct {
+		arg1 context.Context
+		arg2 *service.RoomParticipantPolicy
+	}{arg1, arg2})
+	stub := fake.StoreRoomParticipantPolicyStub
+	fakeReturns := fake.storeRoomParticipantPolicyReturns
+	fake.recordInvocation("StoreRoomParticipantPolicy", []interface{}{arg1, arg2})
+	fake.storeRoomParticipantPolicyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceStore) StoreRoomParticipantPolicyCallCount() int {
+	fake.storeRoomParticipantPolicyMutex.RLock()
+	defer fake.storeRoomParticipantPolicyMutex.RUnlock()
+	return len(fake.storeRoomParticipantPolicyArgsForCall)
+}
+
+func (fake *FakeServiceStore) StoreRoomParticipantPolicyCalls(stub func(context.Context, *service.RoomParticipantPolicy) error) {
+	fake.storeRoomParticipantPolicyMutex.Lock()
+	defer fake.storeRoomParticipantPolicyMutex.Unlock()
+	fake.StoreRoomParticipantPolicyStub = stub
+}
+
+func (fake *FakeServiceStore) StoreRoomParticipantPolicyArgsForCall(i int) (context.Context, *service.RoomParticipantPolicy) {
+	fake.storeRoomParticipantPolicyMutex.RLock()
+	defer fake.storeRoomParticipantPolicyMutex.RUnlock()
+	argsForCall := fake.storeRoomParticipantPolicyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) StoreRoomParticipantPolicyReturns(result1 error) {
+	fake.storeRoomParticipantPolicyMutex.Lock()
+	defer fake.storeRoomParticipantPolicyMutex.Unlock()
+	fake.StoreRoomParticipantPolicyStub = nil
+	fake.storeRoomParticipantPolicyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) StoreRoomParticipantPolicyReturnsOnCall(i int, result1 error) {
+	fake.storeRoomParticipantPolicyMutex.Lock()
+	defer fake.storeRoomParticipantPolicyMutex.Unlock()
+	fake.StoreRoomParticipantPolicyStub = nil
+	if fake.storeRoomParticipantPolicyReturnsOnCall == nil {
+		fake.storeRoomParticipantPolicyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomParticipantPolicyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) StoreRoomQualityReport(arg1 context.Context, arg2 *telemetry.QualityReport, arg3 time.Duration) error {
+	fake.storeRoomQualityReportMutex.Lock()
+	ret, specificReturn := fake.storeRoomQualityReportReturnsOnCall[len(fake.storeRoomQualityReportArgsForCall)]
+	fake.storeRoomQualityReportArgsForCall = append(fake.storeRoomQualityReportArgsForCall, struct {
+		arg1 context.Context
+		arg2 *telemetry.QualityReport
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.StoreRoomQualityReportStub
+	fakeReturns := fake.storeRoomQualityReportReturns
+	fake.recordInvocation("StoreRoomQualityReport", []interface{}{arg1, arg2, arg3})
+	fake.storeRoomQualityReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceStore) StoreRoomQualityReportCallCount() int {
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	return len(fake.storeRoomQualityReportArgsForCall)
+}
+
+func (fake *FakeServiceStore) StoreRoomQualityReportCalls(stub func(context.Context, *telemetry.QualityReport, time.Duration) error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = stub
+}
+
+func (fake *FakeServiceStore) StoreRoomQualityReportArgsForCall(i int) (context.Context, *telemetry.QualityReport, time.Duration) {
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	argsForCall := fake.storeRoomQualityReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeServiceStore) StoreRoomQualityReportReturns(result1 error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = nil
+	fake.storeRoomQualityReportReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) StoreRoomQualityReportReturnsOnCall(i int, result1 error) {
+	fake.storeRoomQualityReportMutex.Lock()
+	defer fake.storeRoomQualityReportMutex.Unlock()
+	fake.StoreRoomQualityReportStub = nil
+	if fake.storeRoomQualityReportReturnsOnCall == nil {
+		fake.storeRoomQualityReportReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomQualityReportReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) StoreRoomSchedule(arg1 context.Context, arg2 *service.RoomSchedule) error {
+	fake.storeRoomScheduleMutex.Lock()
+	ret, specificReturn := fake.storeRoomScheduleReturnsOnCall[len(fake.storeRoomScheduleArgsForCall)]
+	fake.storeRoomScheduleArgsForCall = append(fake.storeRoomScheduleArgsForCall, struct {
+		arg1 context.Context
+		arg2 *service.RoomSchedule
+	}{arg1, arg2})
+	stub := fake.StoreRoomScheduleStub
+	fakeReturns := fake.storeRoomScheduleReturns
+	fake.recordInvocation("StoreRoomSchedule", []interface{}{arg1, arg2})
+	fake.storeRoomScheduleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceStore) StoreRoomScheduleCallCount() int {
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	return len(fake.storeRoomScheduleArgsForCall)
+}
+
+func (fake *FakeServiceStore) StoreRoomScheduleCalls(stub func(context.Context, *service.RoomSchedule) error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = stub
+}
+
+func (fake *FakeServiceStore) StoreRoomScheduleArgsForCall(i int) (context.Context, *service.RoomSchedule) {
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	argsForCall := fake.storeRoomScheduleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeServiceStore) StoreRoomScheduleReturns(result1 error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = nil
+	fake.storeRoomScheduleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) StoreRoomScheduleReturnsOnCall(i int, result1 error) {
+	fake.storeRoomScheduleMutex.Lock()
+	defer fake.storeRoomScheduleMutex.Unlock()
+	fake.StoreRoomScheduleStub = nil
+	if fake.storeRoomScheduleReturnsOnCall == nil {
+		fake.storeRoomScheduleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomScheduleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) StoreRoomSnapshot(arg1 context.Context, arg2 *rtc.RoomSnapshot, arg3 time.Duration) error {
+	fake.storeRoomSnapshotMutex.Lock()
+	ret, specificReturn := fake.storeRoomSnapshotReturnsOnCall[len(fake.storeRoomSnapshotArgsForCall)]
+	fake.storeRoomSnapshotArgsForCall = append(fake.storeRoomSnapshotArgsForCall, struct {
+		arg1 context.Context
+		arg2 *rtc.RoomSnapshot
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.StoreRoomSnapshotStub
+	fakeReturns := fake.storeRoomSnapshotReturns
+	fake.recordInvocation("StoreRoomSnapshot", []interface{}{arg1, arg2, arg3})
+	fake.storeRoomSnapshotMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeServiceStore) StoreRoomSnapshotCallCount() int {
+	fake.storeRoomSnapshotMutex.RLock()
+	defer fake.storeRoomSnapshotMutex.RUnlock()
+	return len(fake.storeRoomSnapshotArgsForCall)
+}
+
+func (fake *FakeServiceStore) StoreRoomSnapshotCalls(stub func(context.Context, *rtc.RoomSnapshot, time.Duration) error) {
+	fake.storeRoomSnapshotMutex.Lock()
+	defer fake.storeRoomSnapshotMutex.Unlock()
+	fake.StoreRoomSnapshotStub = stub
+}
+
+func (fake *FakeServiceStore) StoreRoomSnapshotArgsForCall(i int) (context.Context, *rtc.RoomSnapshot, time.Duration) {
+	fake.storeRoomSnapshotMutex.RLock()
+	defer fake.storeRoomSnapshotMutex.RUnlock()
+	argsForCall := fake.storeRoomSnapshotArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeServiceStore) StoreRoomSnapshotReturns(result1 error) {
+	fake.storeRoomSnapshotMutex.Lock()
+	defer fake.storeRoomSnapshotMutex.Unlock()
+	fake.StoreRoomSnapshotStub = nil
+	fake.storeRoomSnapshotReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeServiceStore) StoreRoomSnapshotReturnsOnCall(i int, result1 error) {
+	fake.storeRoomSnapshotMutex.Lock()
+	defer fake.storeRoomSnapshotMutex.Unlock()
+	fake.StoreRoomSnapshotStub = nil
+	if fake.storeRoomSnapshotReturnsOnCall == nil {
+		fake.storeRoomSnapshotReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.storeRoomSnapshotReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeServiceStore) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.appendRoomEventsMutex.RLock()
+	defer fake.appendRoomEventsMutex.RUnlock()
 	fake.deleteRoomMutex.RLock()
 	defer fake.deleteRoomMutex.RUnlock()
+	fake.deleteRoomScheduleMutex.RLock()
+	defer fake.deleteRoomScheduleMutex.RUnlock()
+	fake.deleteRoomSnapshotMutex.RLock()
+	defer fake.deleteRoomSnapshotMutex.RUnlock()
 	fake.listParticipantsMutex.RLock()
 	defer fake.listParticipantsMutex.RUnlock()
+	fake.listRoomEventsMutex.RLock()
+	defer fake.listRoomEventsMutex.RUnlock()
+	fake.listRoomSchedulesMutex.RLock()
+	defer fake.listRoomSchedulesMutex.RUnlock()
 	fake.listRoomsMutex.RLock()
 	defer fake.listRoomsMutex.RUnlock()
 	fake.loadParticipantMutex.RLock()
 	defer fake.loadParticipantMutex.RUnlock()
 	fake.loadRoomMutex.RLock()
 	defer fake.loadRoomMutex.RUnlock()
+	fake.loadRoomParticipantPolicyMutex.RLock()
+	defer fake.loadRoomParticipantPolicyMutex.RUnlock()
+	fake.loadRoomQualityReportMutex.RLock()
+	defer fake.loadRoomQualityReportMutex.RUnlock()
+	fake.loadRoomScheduleMutex.RLock()
+	defer fake.loadRoomScheduleMutex.RUnlock()
+	fake.loadRoomSnapshotMutex.RLock()
+	defer fake.loadRoomSnapshotMutex.RUnlock()
+	fake.storeRoomParticipantPolicyMutex.RLock()
+	defer fake.storeRoomParticipantPolicyMutex.RUnlock()
+	fake.storeRoomQualityReportMutex.RLock()
+	defer fake.storeRoomQualityReportMutex.RUnlock()
+	fake.storeRoomScheduleMutex.RLock()
+	defer fake.storeRoomScheduleMutex.RUnlock()
+	fake.storeRoomSnapshotMutex.RLock()
+	defer fake.storeRoomSnapshotMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
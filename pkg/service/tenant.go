@@ -0,0 +1,325 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+const (
+	tenantSeparator = "/"
+	// tokens signed with keys of the deployment pick a tenant with this attribute
+	tenantAttribute = "lk.tenant"
+)
+
+var ErrInvalidTenant = errors.New("invalid tenant configuration")
+
+type tenantKey struct{}
+
+// request fields holding room names, namespaced for requests of a tenant
+var tenantRoomFields = []protoreflect.Name{"room", "room_name", "destination_room"}
+
+// fields holding room names in types of the room extension API
+var tenantRoomStructFields = []string{"Room", "RoomName", "DestinationRoom"}
+
+func ValidateTenants(conf *config.Config) error {
+	keys := make(map[string]string)
+	for name, tenant := range conf.Tenants {
+		if name == "" || strings.Contains(name, tenantSeparator) {
+			return fmt.Errorf("%w: tenant name %q", ErrInvalidTenant, name)
+		}
+		if tenant == nil {
+			continue
+		}
+		for _, key := range tenant.Keys {
+			if other, ok := keys[key]; ok {
+				return fmt.Errorf("%w: key %s used by tenants %s and %s", ErrInvalidTenant, key, other, name)
+			}
+			keys[key] = name
+		}
+	}
+	return nil
+}
+
+// TenantRoomName returns the name of a room of the tenant within the deployment
+func TenantRoomName(tenant string, roomName livekit.RoomName) livekit.RoomName {
+	if tenant == "" || roomName == "" || strings.HasPrefix(string(roomName), tenant+tenantSeparator) {
+		return roomName
+	}
+	return livekit.RoomName(tenant + tenantSeparator + string(roomName))
+}
+
+// tenantForRoom returns the tenant a room belongs to, empty for rooms of the deployment
+func tenantForRoom(conf *config.Config, roomName livekit.RoomName) string {
+	name, _, ok := strings.Cut(string(roomName), tenantSeparator)
+	if !ok {
+		return ""
+	}
+	if _, ok = conf.Tenants[name]; !ok {
+		return ""
+	}
+	return name
+}
+
+// tenantForGrants returns the tenant of the key a token is signed with. Tokens signed with a key of the
+// deployment may act for a tenant with the lk.tenant attribute.
+func tenantForGrants(conf *config.Config, apiKey string, grants *auth.ClaimGrants) string {
+	for name, tenant := range conf.Tenants {
+		if tenant != nil && slices.Contains(tenant.Keys, apiKey) {
+			return name
+		}
+	}
+	if name := grants.Attributes[tenantAttribute]; name != "" {
+		if _, ok := conf.Tenants[name]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+func tenantConfig(conf *config.Config, tenant string) *config.TenantConfig {
+	if tenant == "" {
+		return nil
+	}
+	return conf.Tenants[tenant]
+}
+
+// roomLimitConfig returns the limits applied to a room, the limits of its tenant when set
+func roomLimitConfig(conf *config.Config, roomName livekit.RoomName) config.LimitConfig {
+	if tc := tenantConfig(conf, tenantForRoom(conf, roomName)); tc != nil && tc.Limit != nil {
+		return *tc.Limit
+	}
+	return conf.Limit
+}
+
+func GetTenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// TenantMiddleware resolves the tenant of authenticated requests, and scopes the room of their grants to it
+type TenantMiddleware struct {
+	conf *config.Config
+}
+
+func NewTenantMiddleware(conf *config.Config) *TenantMiddleware {
+	return &TenantMiddleware{conf: conf}
+}
+
+func (m *TenantMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx := r.Context()
+	grants := GetGrants(ctx)
+	if grants == nil {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	tenant := tenantForGrants(m.conf, GetAPIKey(ctx), grants)
+	if _, ok := grants.Attributes[tenantAttribute]; ok || tenant != "" {
+		grants = grants.Clone()
+		delete(grants.Attributes, tenantAttribute)
+		if grants.Video != nil {
+			grants.Video.Room = string(TenantRoomName(tenant, livekit.RoomName(grants.Video.Room)))
+		}
+		ctx = WithGrants(ctx, grants, GetAPIKey(ctx))
+	}
+	if tenant != "" {
+		ctx = context.WithValue(ctx, tenantKey{}, tenant)
+	}
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// TenantInterceptor namespaces rooms of API requests made by a tenant, and drops rooms of other tenants
+// from responses. Tenants see the names of their rooms without the tenant prefix.
+func TenantInterceptor() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tenant := GetTenant(ctx)
+			if tenant == "" {
+				return next(ctx, req)
+			}
+
+			namespaceRequestRooms(tenant, req)
+			res, err := next(ctx, req)
+			if err == nil {
+				if m, ok := res.(proto.Message); ok {
+					// responses may hold objects of the store
+					m = proto.Clone(m)
+					filterTenantRooms(tenant, m.ProtoReflect())
+					stripTenantRooms(tenant, m.ProtoReflect())
+					res = m
+				}
+			}
+			return res, err
+		}
+	}
+}
+
+func namespaceRequestRooms(tenant string, req any) {
+	switch r := req.(type) {
+	case *livekit.CreateRoomRequest:
+		r.Name = string(TenantRoomName(tenant, livekit.RoomName(r.Name)))
+	case *livekit.ListRoomsRequest:
+		for i, name := range r.Names {
+			r.Names[i] = string(TenantRoomName(tenant, livekit.RoomName(name)))
+		}
+	case *ListRoomSchedulesRequest:
+		for i, name := range r.Names {
+			r.Names[i] = string(TenantRoomName(tenant, livekit.RoomName(name)))
+		}
+	}
+
+	if m, ok := req.(proto.Message); ok {
+		msg := m.ProtoReflect()
+		fields := msg.Descriptor().Fields()
+		for _, name := range tenantRoomFields {
+			fd := fields.ByName(name)
+			if fd == nil || fd.Kind() != protoreflect.StringKind || fd.IsList() {
+				continue
+			}
+			if room := msg.Get(fd).String(); room != "" {
+				msg.Set(fd, protoreflect.ValueOfString(string(TenantRoomName(tenant, livekit.RoomName(room)))))
+			}
+		}
+		return
+	}
+
+	// requests of the room extension API
+	v := reflect.ValueOf(req)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	for _, name := range []string{"Room", "DestinationRoom"} {
+		f := v.Elem().FieldByName(name)
+		if f.IsValid() && f.Kind() == reflect.String && f.CanSet() && f.String() != "" {
+			f.SetString(string(TenantRoomName(tenant, livekit.RoomName(f.String()))))
+		}
+	}
+}
+
+// filterTenantRooms drops list items of rooms outside the tenant, such as rooms, egresses or ingresses
+func filterTenantRooms(tenant string, msg protoreflect.Message) {
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !fd.IsList() || fd.Kind() != protoreflect.MessageKind || !msg.Has(fd) {
+			continue
+		}
+		roomField := itemRoomField(fd.Message())
+		if roomField == nil {
+			continue
+		}
+
+		list := msg.Mutable(fd).List()
+		n := 0
+		for j := 0; j < list.Len(); j++ {
+			item := list.Get(j)
+			if tenantForRoomName(item.Message().Get(roomField).String()) == tenant {
+				list.Set(n, item)
+				n++
+			}
+		}
+		list.Truncate(n)
+	}
+}
+
+// stripTenantRooms removes the tenant prefix from room names of a message and the messages it holds
+func stripTenantRooms(tenant string, msg protoreflect.Message) {
+	prefix := tenant + tenantSeparator
+	isRoom := msg.Descriptor().FullName() == (&livekit.Room{}).ProtoReflect().Descriptor().FullName()
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsMap() || !msg.Has(fd) {
+			continue
+		}
+		switch {
+		case fd.Kind() == protoreflect.MessageKind && fd.IsList():
+			list := msg.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				stripTenantRooms(tenant, list.Get(j).Message())
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			stripTenantRooms(tenant, msg.Mutable(fd).Message())
+		case fd.Kind() == protoreflect.StringKind && !fd.IsList() && ((isRoom && fd.Name() == "name") || slices.Contains(tenantRoomFields, fd.Name())):
+			msg.Set(fd, protoreflect.ValueOfString(strings.TrimPrefix(msg.Get(fd).String(), prefix)))
+		}
+	}
+}
+
+// stripTenantRoomFields removes the tenant prefix from room names of responses of the room extension API
+func stripTenantRoomFields(tenant string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			stripTenantRoomFields(tenant, v.Elem())
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			stripTenantRoomFields(tenant, v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if f.Kind() == reflect.String && slices.Contains(tenantRoomStructFields, t.Field(i).Name) {
+				f.SetString(strings.TrimPrefix(f.String(), tenant+tenantSeparator))
+			} else {
+				stripTenantRoomFields(tenant, f)
+			}
+		}
+	}
+}
+
+func itemRoomField(md protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	if md.FullName() == (&livekit.Room{}).ProtoReflect().Descriptor().FullName() {
+		return md.Fields().ByName("name")
+	}
+	for _, name := range tenantRoomFields {
+		if fd := md.Fields().ByName(name); fd != nil && fd.Kind() == protoreflect.StringKind && !fd.IsList() {
+			return fd
+		}
+	}
+	return nil
+}
+
+func tenantForRoomName(roomName string) string {
+	name, _, ok := strings.Cut(roomName, tenantSeparator)
+	if !ok {
+		return ""
+	}
+	return name
+}
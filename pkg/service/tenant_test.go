@@ -0,0 +1,167 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+func TestTenants(t *testing.T) {
+	conf := &config.Config{
+		Tenants: map[string]*config.TenantConfig{
+			"acme":   {Keys: []string{"acme-key"}},
+			"globex": {Keys: []string{"globex-key"}},
+		},
+	}
+	require.NoError(t, ValidateTenants(conf))
+
+	t.Run("validate", func(t *testing.T) {
+		require.ErrorIs(t, ValidateTenants(&config.Config{
+			Tenants: map[string]*config.TenantConfig{"a/b": {}},
+		}), ErrInvalidTenant)
+		require.ErrorIs(t, ValidateTenants(&config.Config{
+			Tenants: map[string]*config.TenantConfig{"a": {Keys: []string{"key"}}, "b": {Keys: []string{"key"}}},
+		}), ErrInvalidTenant)
+	})
+
+	t.Run("room names", func(t *testing.T) {
+		require.Equal(t, livekit.RoomName("acme/room"), TenantRoomName("acme", "room"))
+		require.Equal(t, livekit.RoomName("acme/room"), TenantRoomName("acme", "acme/room"))
+		require.Equal(t, livekit.RoomName("acme/globex/room"), TenantRoomName("acme", "globex/room"))
+		require.Equal(t, livekit.RoomName("room"), TenantRoomName("", "room"))
+
+		require.Equal(t, "acme", tenantForRoom(conf, "acme/room"))
+		require.Equal(t, "", tenantForRoom(conf, "other/room"))
+		require.Equal(t, "", tenantForRoom(conf, "room"))
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		limitConf := &config.Config{
+			Limit: config.LimitConfig{MaxMetadataSize: 10},
+			Tenants: map[string]*config.TenantConfig{
+				"acme":   {Limit: &config.LimitConfig{MaxMetadataSize: 100}},
+				"globex": {},
+			},
+		}
+		require.Equal(t, uint32(100), roomLimitConfig(limitConf, "acme/room").MaxMetadataSize)
+		require.Equal(t, uint32(10), roomLimitConfig(limitConf, "globex/room").MaxMetadataSize)
+		require.Equal(t, uint32(10), roomLimitConfig(limitConf, "room").MaxMetadataSize)
+	})
+
+	t.Run("grants", func(t *testing.T) {
+		require.Equal(t, "acme", tenantForGrants(conf, "acme-key", &auth.ClaimGrants{}))
+		// keys of a tenant cannot act for another one
+		require.Equal(t, "acme", tenantForGrants(conf, "acme-key", &auth.ClaimGrants{
+			Attributes: map[string]string{tenantAttribute: "globex"},
+		}))
+		require.Equal(t, "globex", tenantForGrants(conf, "deployment-key", &auth.ClaimGrants{
+			Attributes: map[string]string{tenantAttribute: "globex"},
+		}))
+		require.Equal(t, "", tenantForGrants(conf, "deployment-key", &auth.ClaimGrants{
+			Attributes: map[string]string{tenantAttribute: "unknown"},
+		}))
+	})
+
+	t.Run("requests", func(t *testing.T) {
+		var res any
+		next := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return res, nil
+		}
+		ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+		intercept := TenantInterceptor()(next)
+
+		create := &livekit.CreateRoomRequest{Name: "room"}
+		_, err := intercept(ctx, create)
+		require.NoError(t, err)
+		require.Equal(t, "acme/room", create.Name)
+
+		remove := &livekit.RoomParticipantIdentity{Room: "room", Identity: "participant"}
+		_, err = intercept(ctx, remove)
+		require.NoError(t, err)
+		require.Equal(t, "acme/room", remove.Room)
+
+		res = &livekit.ListRoomsResponse{Rooms: []*livekit.Room{{Name: "acme/room"}, {Name: "globex/room"}, {Name: "room"}}}
+		list, err := intercept(ctx, &livekit.ListRoomsRequest{})
+		require.NoError(t, err)
+		require.Len(t, list.(*livekit.ListRoomsResponse).Rooms, 1)
+		require.Equal(t, "room", list.(*livekit.ListRoomsResponse).Rooms[0].Name)
+		// responses are copies, stored rooms keep their name
+		require.Equal(t, "acme/room", res.(*livekit.ListRoomsResponse).Rooms[0].Name)
+
+		res = &livekit.ListEgressResponse{Items: []*livekit.EgressInfo{
+			{RoomName: "globex/room"},
+			{RoomName: "acme/room", Request: &livekit.EgressInfo_RoomComposite{RoomComposite: &livekit.RoomCompositeEgressRequest{RoomName: "acme/room"}}},
+		}}
+		egress, err := intercept(ctx, &livekit.ListEgressRequest{})
+		require.NoError(t, err)
+		require.Len(t, egress.(*livekit.ListEgressResponse).Items, 1)
+		require.Equal(t, "room", egress.(*livekit.ListEgressResponse).Items[0].RoomName)
+		require.Equal(t, "room", egress.(*livekit.ListEgressResponse).Items[0].GetRoomComposite().RoomName)
+
+		schedules := &ListRoomSchedulesResponse{Schedules: []*RoomSchedule{{Room: "acme/room"}}}
+		stripTenantRoomFields("acme", reflect.ValueOf(schedules))
+		require.Equal(t, "room", schedules.Schedules[0].Room)
+
+		move := &MoveParticipantRequest{Room: "room", DestinationRoom: "other"}
+		namespaceRequestRooms("acme", move)
+		require.Equal(t, "acme/room", move.Room)
+		require.Equal(t, "acme/other", move.DestinationRoom)
+	})
+	// names tenants get from the API and webhooks are the same, and resolve to the same room in requests
+	t.Run("round trip", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+		var stored *livekit.Room
+		intercept := TenantInterceptor()(func(ctx context.Context, req interface{}) (interface{}, error) {
+			switch r := req.(type) {
+			case *livekit.CreateRoomRequest:
+				stored = &livekit.Room{Name: r.Name}
+				return stored, nil
+			case *livekit.ListRoomsRequest:
+				return &livekit.ListRoomsResponse{Rooms: []*livekit.Room{stored}}, nil
+			case *livekit.DeleteRoomRequest:
+				require.Equal(t, stored.Name, r.Room)
+				return &livekit.DeleteRoomResponse{}, nil
+			}
+			return nil, nil
+		})
+
+		created, err := intercept(ctx, &livekit.CreateRoomRequest{Name: "standup"})
+		require.NoError(t, err)
+		require.Equal(t, "acme/standup", stored.Name)
+		require.Equal(t, "standup", created.(*livekit.Room).Name)
+
+		list, err := intercept(ctx, &livekit.ListRoomsRequest{})
+		require.NoError(t, err)
+		apiName := list.(*livekit.ListRoomsResponse).Rooms[0].Name
+
+		acme := &tenantTestNotifier{}
+		n := newTenantNotifier(conf, nil, map[string]webhook.QueuedNotifier{"acme": acme})
+		require.NoError(t, n.QueueNotify(ctx, &livekit.WebhookEvent{Event: webhook.EventRoomStarted, Room: stored}))
+		require.Equal(t, apiName, acme.events[0].Room.Name)
+
+		_, err = intercept(ctx, &livekit.DeleteRoomRequest{Room: apiName})
+		require.NoError(t, err)
+	})
+}
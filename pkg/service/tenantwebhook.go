@@ -0,0 +1,131 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+)
+
+// tenantNotifier sends webhooks of rooms of a tenant to the tenant, in addition to the deployment webhooks
+type tenantNotifier struct {
+	conf *config.Config
+	// nil when the deployment has no webhooks
+	notifier webhook.QueuedNotifier
+	tenants  map[string]webhook.QueuedNotifier
+}
+
+var _ telemetry.QualityReportNotifier = (*tenantNotifier)(nil)
+
+func createTenantWebhookNotifiers(conf *config.Config, provider auth.KeyProvider) (map[string]webhook.QueuedNotifier, error) {
+	notifiers := make(map[string]webhook.QueuedNotifier)
+	for name, tenant := range conf.Tenants {
+		if tenant == nil || len(tenant.WebHook.URLs) == 0 {
+			continue
+		}
+		apiKey := tenant.WebHook.APIKey
+		if apiKey == "" && len(tenant.Keys) != 0 {
+			apiKey = tenant.Keys[0]
+		}
+		secret := provider.GetSecret(apiKey)
+		if secret == "" {
+			return nil, ErrWebHookMissingAPIKey
+		}
+		notifiers[name] = telemetry.NewWebhookNotifier(apiKey, secret, tenant.WebHook.URLs)
+	}
+	return notifiers, nil
+}
+
+func newTenantNotifier(conf *config.Config, notifier webhook.QueuedNotifier, tenants map[string]webhook.QueuedNotifier) *tenantNotifier {
+	return &tenantNotifier{
+		conf:     conf,
+		notifier: notifier,
+		tenants:  tenants,
+	}
+}
+
+func (n *tenantNotifier) RegisterProcessedHook(f func(ctx context.Context, whi *livekit.WebhookInfo)) {
+	if n.notifier != nil {
+		n.notifier.RegisterProcessedHook(f)
+	}
+	for _, notifier := range n.tenants {
+		notifier.RegisterProcessedHook(f)
+	}
+}
+
+func (n *tenantNotifier) QueueNotify(ctx context.Context, event *livekit.WebhookEvent) error {
+	var err error
+	if n.notifier != nil {
+		err = n.notifier.QueueNotify(ctx, event)
+	}
+	tenant := tenantForRoom(n.conf, webhookEventRoom(event))
+	if notifier := n.tenants[tenant]; notifier != nil {
+		if tenantErr := notifier.QueueNotify(ctx, tenantWebhookEvent(tenant, event)); tenantErr != nil {
+			err = tenantErr
+		}
+	}
+	return err
+}
+
+func (n *tenantNotifier) QueueNotifyRoomFinished(ctx context.Context, event *livekit.WebhookEvent, report *telemetry.QualityReport) error {
+	var err error
+	if n.notifier != nil {
+		err = queueNotifyRoomFinished(ctx, n.notifier, event, report)
+	}
+	tenant := tenantForRoom(n.conf, webhookEventRoom(event))
+	if notifier := n.tenants[tenant]; notifier != nil {
+		tenantReport := *report
+		tenantReport.RoomName = strings.TrimPrefix(report.RoomName, tenant+tenantSeparator)
+		if tenantErr := queueNotifyRoomFinished(ctx, notifier, tenantWebhookEvent(tenant, event), &tenantReport); tenantErr != nil {
+			err = tenantErr
+		}
+	}
+	return err
+}
+
+func queueNotifyRoomFinished(ctx context.Context, notifier webhook.QueuedNotifier, event *livekit.WebhookEvent, report *telemetry.QualityReport) error {
+	if n, ok := notifier.(telemetry.QualityReportNotifier); ok {
+		return n.QueueNotifyRoomFinished(ctx, event, report)
+	}
+	return notifier.QueueNotify(ctx, event)
+}
+
+func webhookEventRoom(event *livekit.WebhookEvent) livekit.RoomName {
+	switch {
+	case event.Room != nil:
+		return livekit.RoomName(event.Room.Name)
+	case event.EgressInfo != nil:
+		return livekit.RoomName(event.EgressInfo.RoomName)
+	case event.IngressInfo != nil:
+		return livekit.RoomName(event.IngressInfo.RoomName)
+	}
+	return ""
+}
+
+// tenantWebhookEvent returns a copy of the event with the room names the tenant knows, without the tenant prefix
+func tenantWebhookEvent(tenant string, event *livekit.WebhookEvent) *livekit.WebhookEvent {
+	event = proto.Clone(event).(*livekit.WebhookEvent)
+	stripTenantRooms(tenant, event.ProtoReflect())
+	return event
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+)
+
+type tenantTestNotifier struct {
+	webhook.QueuedNotifier
+	events  []*livekit.WebhookEvent
+	reports []*telemetry.QualityReport
+}
+
+func (n *tenantTestNotifier) QueueNotify(_ context.Context, event *livekit.WebhookEvent) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func (n *tenantTestNotifier) QueueNotifyRoomFinished(_ context.Context, event *livekit.WebhookEvent, report *telemetry.QualityReport) error {
+	n.events = append(n.events, event)
+	n.reports = append(n.reports, report)
+	return nil
+}
+
+func TestTenantNotifier(t *testing.T) {
+	conf := &config.Config{
+		Tenants: map[string]*config.TenantConfig{"acme": {}, "globex": {}},
+	}
+	deployment := &tenantTestNotifier{}
+	acme := &tenantTestNotifier{}
+	n := newTenantNotifier(conf, deployment, map[string]webhook.QueuedNotifier{"acme": acme})
+
+	require.NoError(t, n.QueueNotify(context.Background(), &livekit.WebhookEvent{
+		Event: webhook.EventRoomStarted,
+		Room:  &livekit.Room{Name: "acme/room"},
+	}))
+	require.NoError(t, n.QueueNotify(context.Background(), &livekit.WebhookEvent{
+		Event:      webhook.EventEgressStarted,
+		EgressInfo: &livekit.EgressInfo{RoomName: "acme/room"},
+	}))
+	require.NoError(t, n.QueueNotify(context.Background(), &livekit.WebhookEvent{
+		Event: webhook.EventRoomStarted,
+		Room:  &livekit.Room{Name: "globex/room"},
+	}))
+
+	// the deployment sees every room with its tenant prefix
+	require.Len(t, deployment.events, 3)
+	require.Equal(t, "acme/room", deployment.events[0].Room.Name)
+	require.Equal(t, "acme/room", deployment.events[1].EgressInfo.RoomName)
+
+	// tenants see their own rooms without the prefix
+	require.Len(t, acme.events, 2)
+	require.Equal(t, "room", acme.events[0].Room.Name)
+	require.Equal(t, "room", acme.events[1].EgressInfo.RoomName)
+}
+
+func TestTenantNotifierQualityReport(t *testing.T) {
+	conf := &config.Config{
+		Tenants: map[string]*config.TenantConfig{"acme": {}},
+	}
+	deployment := &tenantTestNotifier{}
+	acme := &tenantTestNotifier{}
+	n := newTenantNotifier(conf, deployment, map[string]webhook.QueuedNotifier{"acme": acme})
+
+	report := &telemetry.QualityReport{RoomSid: "RM_1", RoomName: "acme/room", Score: 4}
+	require.NoError(t, n.QueueNotifyRoomFinished(context.Background(), &livekit.WebhookEvent{
+		Event: webhook.EventRoomFinished,
+		Room:  &livekit.Room{Sid: "RM_1", Name: "acme/room"},
+	}, report))
+
+	// the report is delivered with room_finished to the deployment and to the tenant, with the room names each knows
+	require.Equal(t, []*telemetry.QualityReport{report}, deployment.reports)
+	require.Equal(t, "acme/room", deployment.events[0].Room.Name)
+	require.Len(t, acme.reports, 1)
+	require.Equal(t, "room", acme.reports[0].RoomName)
+	require.Equal(t, "room", acme.events[0].Room.Name)
+	require.Equal(t, "acme/room", report.RoomName)
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+
+	"github.com/livekit/protocol/utils/xtwirp"
+)
+
+// twirpJSONMethod serves a single twirp method without a protobuf definition.
+// Requests and responses are JSON only, errors use the twirp wire format and
+// server hooks are invoked the same way generated servers invoke them.
+type twirpJSONMethod struct {
+	service string
+	method  string
+	hooks   *twirp.ServerHooks
+	handle  func(ctx context.Context, body *json.Decoder) (any, error)
+}
+
+func newTwirpJSONMethod[Req, Res any](
+	service string,
+	method string,
+	hooks *twirp.ServerHooks,
+	fn func(ctx context.Context, req *Req) (*Res, error),
+) *twirpJSONMethod {
+	return &twirpJSONMethod{
+		service: service,
+		method:  method,
+		hooks:   hooks,
+		handle: func(ctx context.Context, d *json.Decoder) (any, error) {
+			req := new(Req)
+			if err := d.Decode(req); err != nil {
+				return nil, twirp.NewError(twirp.Malformed, "the json request could not be decoded").WithMeta("cause", err.Error())
+			}
+			tenant := GetTenant(ctx)
+			if tenant == "" {
+				return fn(ctx, req)
+			}
+
+			namespaceRequestRooms(tenant, req)
+			res, err := fn(ctx, req)
+			if err != nil || res == nil {
+				return res, err
+			}
+			// responses may hold objects of the store, tenants get a copy without the tenant prefix
+			b, err := json.Marshal(res)
+			if err != nil {
+				return nil, err
+			}
+			tenantRes := new(Res)
+			if err = json.Unmarshal(b, tenantRes); err != nil {
+				return nil, err
+			}
+			stripTenantRoomFields(tenant, reflect.ValueOf(tenantRes))
+			return tenantRes, nil
+		},
+	}
+}
+
+func (m *twirpJSONMethod) Path() string {
+	return fmt.Sprintf("/twirp/livekit.%s/%s", m.service, m.method)
+}
+
+func (m *twirpJSONMethod) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx = ctxsetters.WithPackageName(ctx, "livekit")
+	ctx = ctxsetters.WithServiceName(ctx, m.service)
+	ctx = ctxsetters.WithResponseWriter(ctx, w)
+
+	var err error
+	if m.hooks != nil && m.hooks.RequestReceived != nil {
+		if ctx, err = m.hooks.RequestReceived(ctx); err != nil {
+			m.writeError(ctx, w, err)
+			return
+		}
+	}
+
+	if r.Method != http.MethodPost {
+		m.writeError(ctx, w, twirp.NewError(twirp.BadRoute, fmt.Sprintf("unsupported method %q (only POST is allowed)", r.Method)))
+		return
+	}
+
+	ctx = ctxsetters.WithMethodName(ctx, m.method)
+	if m.hooks != nil && m.hooks.RequestRouted != nil {
+		if ctx, err = m.hooks.RequestRouted(ctx); err != nil {
+			m.writeError(ctx, w, err)
+			return
+		}
+	}
+
+	res, err := m.handle(ctx, json.NewDecoder(r.Body))
+	if err != nil {
+		m.writeError(ctx, w, err)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		m.writeError(ctx, w, twirp.InternalErrorWith(err))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+
+	if m.hooks != nil && m.hooks.ResponseSent != nil {
+		m.hooks.ResponseSent(ctx)
+	}
+}
+
+func (m *twirpJSONMethod) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	var twerr twirp.Error
+	if !errors.As(err, &twerr) {
+		twerr = xtwirp.ToError(err)
+	}
+	if m.hooks != nil && m.hooks.Error != nil {
+		ctx = m.hooks.Error(ctx, twerr)
+	}
+	ctx = ctxsetters.WithStatusCode(ctx, twirp.ServerHTTPStatusFromErrorCode(twerr.Code()))
+	_ = twirp.WriteError(w, twerr)
+
+	if m.hooks != nil && m.hooks.ResponseSent != nil {
+		m.hooks.ResponseSent(ctx)
+	}
+}
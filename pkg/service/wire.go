@@ -30,6 +30,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/agent"
 	"github.com/livekit/livekit-server/pkg/clientconfiguration"
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/metric"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/telemetry"
@@ -47,6 +48,7 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	wire.Build(
 		getNodeID,
 		createRedisClient,
+		createGossip,
 		createStore,
 		wire.Bind(new(ServiceStore), new(ObjectStore)),
 		createKeyProvider,
@@ -54,11 +56,11 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 		createClientConfiguration,
 		createForwardStats,
 		routing.CreateRouter,
-		getLimitConf,
 		config.DefaultAPIConfig,
 		wire.Bind(new(routing.MessageRouter), new(routing.Router)),
 		wire.Bind(new(livekit.RoomService), new(*RoomService)),
 		telemetry.NewAnalyticsService,
+		getMetricsAggregatorConfig,
 		telemetry.NewTelemetryService,
 		getMessageBus,
 		NewIOInfoService,
@@ -93,6 +95,7 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 		rpc.NewTopicFormatter,
 		rpc.NewTypedRoomClient,
 		rpc.NewTypedParticipantClient,
+		NewRoomExtensionClient,
 		rpc.NewTypedAgentDispatchInternalClient,
 		NewLocalRoomManager,
 		NewTURNAuthHandler,
@@ -107,6 +110,7 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 func InitializeRouter(conf *config.Config, currentNode routing.LocalNode) (routing.Router, error) {
 	wire.Build(
 		createRedisClient,
+		createGossip,
 		getNodeID,
 		getMessageBus,
 		getSignalRelayConfig,
@@ -156,16 +160,23 @@ func createKeyProvider(conf *config.Config) (auth.KeyProvider, error) {
 }
 
 func createWebhookNotifier(conf *config.Config, provider auth.KeyProvider) (webhook.QueuedNotifier, error) {
-	wc := conf.WebHook
-	if len(wc.URLs) == 0 {
-		return nil, nil
-	}
-	secret := provider.GetSecret(wc.APIKey)
-	if secret == "" {
-		return nil, ErrWebHookMissingAPIKey
+	var notifier webhook.QueuedNotifier
+	if wc := conf.WebHook; len(wc.URLs) != 0 {
+		secret := provider.GetSecret(wc.APIKey)
+		if secret == "" {
+			return nil, ErrWebHookMissingAPIKey
+		}
+		notifier = telemetry.NewWebhookNotifier(wc.APIKey, secret, wc.URLs)
 	}
 
-	return webhook.NewDefaultNotifier(wc.APIKey, secret, wc.URLs), nil
+	tenantNotifiers, err := createTenantWebhookNotifiers(conf, provider)
+	if err != nil {
+		return nil, err
+	}
+	if len(tenantNotifiers) == 0 {
+		return notifier, nil
+	}
+	return newTenantNotifier(conf, notifier, tenantNotifiers), nil
 }
 
 func createRedisClient(conf *config.Config) (redis.UniversalClient, error) {
@@ -175,18 +186,35 @@ func createRedisClient(conf *config.Config) (redis.UniversalClient, error) {
 	return redisLiveKit.GetRedisClient(&conf.Redis)
 }
 
-func createStore(rc redis.UniversalClient) ObjectStore {
+func createStore(conf *config.Config, rc redis.UniversalClient, g *routing.Gossip) ObjectStore {
 	if rc != nil {
+		if conf.RedisStore.ShardedKeys {
+			return NewShardedRedisStore(rc, conf.RedisStore.IndexShards)
+		}
 		return NewRedisStore(rc)
 	}
+	if g != nil {
+		return NewGossipStore(g)
+	}
 	return NewLocalStore()
 }
 
-func getMessageBus(rc redis.UniversalClient) psrpc.MessageBus {
-	if rc == nil {
-		return psrpc.NewLocalMessageBus()
+// createGossip forms a cluster without Redis when configured, Redis takes precedence
+func createGossip(conf *config.Config, currentNode routing.LocalNode) (*routing.Gossip, error) {
+	if conf.Redis.IsConfigured() || !conf.Cluster.IsConfigured() {
+		return nil, nil
+	}
+	return routing.NewGossip(conf.Cluster, currentNode)
+}
+
+func getMessageBus(rc redis.UniversalClient, g *routing.Gossip) psrpc.MessageBus {
+	if rc != nil {
+		return psrpc.NewRedisMessageBus(rc)
 	}
-	return psrpc.NewRedisMessageBus(rc)
+	if g != nil {
+		return routing.NewGossipMessageBus(g)
+	}
+	return psrpc.NewLocalMessageBus()
 }
 
 func getEgressStore(s ObjectStore) EgressStore {
@@ -213,6 +241,8 @@ func getAgentStore(s ObjectStore) AgentStore {
 		return store
 	case *LocalStore:
 		return store
+	case *GossipStore:
+		return store
 	default:
 		return nil
 	}
@@ -239,14 +269,14 @@ func createClientConfiguration() clientconfiguration.ClientConfigurationManager
 	return clientconfiguration.NewStaticClientConfigurationManager(clientconfiguration.StaticConfigurations)
 }
 
-func getLimitConf(config *config.Config) config.LimitConfig {
-	return config.Limit
-}
-
 func getRoomConfig(config *config.Config) config.RoomConfig {
 	return config.Room
 }
 
+func getMetricsAggregatorConfig(config *config.Config) metric.MetricsAggregatorConfig {
+	return config.Metric.Aggregator
+}
+
 func getSignalRelayConfig(config *config.Config) config.SignalRelayConfig {
 	return config.SignalRelay
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/agent"
 	"github.com/livekit/livekit-server/pkg/clientconfiguration"
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/metric"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/telemetry"
@@ -36,14 +37,17 @@ import (
 // Injectors from wire.go:
 
 func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*LivekitServer, error) {
-	limitConfig := getLimitConf(conf)
 	apiConfig := config.DefaultAPIConfig()
 	universalClient, err := createRedisClient(conf)
 	if err != nil {
 		return nil, err
 	}
 	nodeID := getNodeID(currentNode)
-	messageBus := getMessageBus(universalClient)
+	gossip, err := createGossip(conf, currentNode)
+	if err != nil {
+		return nil, err
+	}
+	messageBus := getMessageBus(universalClient, gossip)
 	signalRelayConfig := getSignalRelayConfig(conf)
 	signalClient, err := routing.NewSignalClient(nodeID, messageBus, signalRelayConfig)
 	if err != nil {
@@ -60,8 +64,8 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	if err != nil {
 		return nil, err
 	}
-	router := routing.CreateRouter(universalClient, currentNode, signalClient, roomManagerClient, keepalivePubSub)
-	objectStore := createStore(universalClient)
+	router := routing.CreateRouter(universalClient, currentNode, signalClient, roomManagerClient, keepalivePubSub, gossip)
+	objectStore := createStore(conf, universalClient, gossip)
 	roomAllocator, err := NewRoomAllocator(conf, router, objectStore)
 	if err != nil {
 		return nil, err
@@ -81,8 +85,12 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	if err != nil {
 		return nil, err
 	}
-	analyticsService := telemetry.NewAnalyticsService(conf, currentNode)
-	telemetryService := telemetry.NewTelemetryService(queuedNotifier, analyticsService)
+	analyticsService, err := telemetry.NewAnalyticsService(conf, currentNode)
+	if err != nil {
+		return nil, err
+	}
+	metricsAggregatorConfig := getMetricsAggregatorConfig(conf)
+	telemetryService := telemetry.NewTelemetryService(queuedNotifier, analyticsService, metricsAggregatorConfig)
 	ioInfoService, err := NewIOInfoService(messageBus, egressStore, ingressStore, sipStore, telemetryService)
 	if err != nil {
 		return nil, err
@@ -97,7 +105,11 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	if err != nil {
 		return nil, err
 	}
-	roomService, err := NewRoomService(limitConfig, apiConfig, router, roomAllocator, objectStore, rtcEgressLauncher, topicFormatter, roomClient, participantClient)
+	roomExtensionClient, err := NewRoomExtensionClient(clientParams)
+	if err != nil {
+		return nil, err
+	}
+	roomService, err := NewRoomService(conf, apiConfig, router, roomAllocator, objectStore, rtcEgressLauncher, topicFormatter, roomClient, participantClient, roomExtensionClient)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +145,7 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	timedVersionGenerator := utils.NewDefaultTimedVersionGenerator()
 	turnAuthHandler := NewTURNAuthHandler(keyProvider)
 	forwardStats := createForwardStats(conf)
-	roomManager, err := NewLocalRoomManager(conf, objectStore, currentNode, router, roomAllocator, telemetryService, clientConfigurationManager, client, agentStore, rtcEgressLauncher, timedVersionGenerator, turnAuthHandler, messageBus, forwardStats)
+	roomManager, err := NewLocalRoomManager(conf, objectStore, currentNode, router, roomAllocator, telemetryService, clientConfigurationManager, client, agentStore, rtcEgressLauncher, timedVersionGenerator, turnAuthHandler, messageBus, forwardStats, roomExtensionClient)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +158,7 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	if err != nil {
 		return nil, err
 	}
-	livekitServer, err := NewLivekitServer(conf, roomService, agentDispatchService, egressService, ingressService, sipService, ioInfoService, rtcService, agentService, keyProvider, router, roomManager, signalServer, server, currentNode)
+	livekitServer, err := NewLivekitServer(conf, roomService, agentDispatchService, egressService, ingressService, sipService, ioInfoService, telemetryService, rtcService, agentService, keyProvider, router, roomManager, signalServer, server, currentNode, universalClient)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +171,11 @@ func InitializeRouter(conf *config.Config, currentNode routing.LocalNode) (routi
 		return nil, err
 	}
 	nodeID := getNodeID(currentNode)
-	messageBus := getMessageBus(universalClient)
+	gossip, err := createGossip(conf, currentNode)
+	if err != nil {
+		return nil, err
+	}
+	messageBus := getMessageBus(universalClient, gossip)
 	signalRelayConfig := getSignalRelayConfig(conf)
 	signalClient, err := routing.NewSignalClient(nodeID, messageBus, signalRelayConfig)
 	if err != nil {
@@ -176,7 +192,7 @@ func InitializeRouter(conf *config.Config, currentNode routing.LocalNode) (routi
 	if err != nil {
 		return nil, err
 	}
-	router := routing.CreateRouter(universalClient, currentNode, signalClient, roomManagerClient, keepalivePubSub)
+	router := routing.CreateRouter(universalClient, currentNode, signalClient, roomManagerClient, keepalivePubSub, gossip)
 	return router, nil
 }
 
@@ -216,16 +232,23 @@ func createKeyProvider(conf *config.Config) (auth.KeyProvider, error) {
 }
 
 func createWebhookNotifier(conf *config.Config, provider auth.KeyProvider) (webhook.QueuedNotifier, error) {
-	wc := conf.WebHook
-	if len(wc.URLs) == 0 {
-		return nil, nil
-	}
-	secret := provider.GetSecret(wc.APIKey)
-	if secret == "" {
-		return nil, ErrWebHookMissingAPIKey
+	var notifier webhook.QueuedNotifier
+	if wc := conf.WebHook; len(wc.URLs) != 0 {
+		secret := provider.GetSecret(wc.APIKey)
+		if secret == "" {
+			return nil, ErrWebHookMissingAPIKey
+		}
+		notifier = telemetry.NewWebhookNotifier(wc.APIKey, secret, wc.URLs)
 	}
 
-	return webhook.NewDefaultNotifier(wc.APIKey, secret, wc.URLs), nil
+	tenantNotifiers, err := createTenantWebhookNotifiers(conf, provider)
+	if err != nil {
+		return nil, err
+	}
+	if len(tenantNotifiers) == 0 {
+		return notifier, nil
+	}
+	return newTenantNotifier(conf, notifier, tenantNotifiers), nil
 }
 
 func createRedisClient(conf *config.Config) (redis.UniversalClient, error) {
@@ -235,18 +258,35 @@ func createRedisClient(conf *config.Config) (redis.UniversalClient, error) {
 	return redis2.GetRedisClient(&conf.Redis)
 }
 
-func createStore(rc redis.UniversalClient) ObjectStore {
+func createStore(conf *config.Config, rc redis.UniversalClient, g *routing.Gossip) ObjectStore {
 	if rc != nil {
+		if conf.RedisStore.ShardedKeys {
+			return NewShardedRedisStore(rc, conf.RedisStore.IndexShards)
+		}
 		return NewRedisStore(rc)
 	}
+	if g != nil {
+		return NewGossipStore(g)
+	}
 	return NewLocalStore()
 }
 
-func getMessageBus(rc redis.UniversalClient) psrpc.MessageBus {
-	if rc == nil {
-		return psrpc.NewLocalMessageBus()
+// createGossip forms a cluster without Redis when configured, Redis takes precedence
+func createGossip(conf *config.Config, currentNode routing.LocalNode) (*routing.Gossip, error) {
+	if conf.Redis.IsConfigured() || !conf.Cluster.IsConfigured() {
+		return nil, nil
+	}
+	return routing.NewGossip(conf.Cluster, currentNode)
+}
+
+func getMessageBus(rc redis.UniversalClient, g *routing.Gossip) psrpc.MessageBus {
+	if rc != nil {
+		return psrpc.NewRedisMessageBus(rc)
 	}
-	return psrpc.NewRedisMessageBus(rc)
+	if g != nil {
+		return routing.NewGossipMessageBus(g)
+	}
+	return psrpc.NewLocalMessageBus()
 }
 
 func getEgressStore(s ObjectStore) EgressStore {
@@ -273,6 +313,8 @@ func getAgentStore(s ObjectStore) AgentStore {
 		return store
 	case *LocalStore:
 		return store
+	case *GossipStore:
+		return store
 	default:
 		return nil
 	}
@@ -299,14 +341,14 @@ func createClientConfiguration() clientconfiguration.ClientConfigurationManager
 	return clientconfiguration.NewStaticClientConfigurationManager(clientconfiguration.StaticConfigurations)
 }
 
-func getLimitConf(config2 *config.Config) config.LimitConfig {
-	return config2.Limit
-}
-
 func getRoomConfig(config2 *config.Config) config.RoomConfig {
 	return config2.Room
 }
 
+func getMetricsAggregatorConfig(config2 *config.Config) metric.MetricsAggregatorConfig {
+	return config2.Metric.Aggregator
+}
+
 func getSignalRelayConfig(config2 *config.Config) config.SignalRelayConfig {
 	return config2.SignalRelay
 }
@@ -32,6 +32,7 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/capture"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	act "github.com/livekit/livekit-server/pkg/sfu/rtpextension/abscapturetime"
 	dd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/dependencydescriptor"
@@ -145,6 +146,8 @@ type Buffer struct {
 	rtxPktBuf           []byte
 
 	absCaptureTimeExtID uint8
+
+	captureTap *capture.Tap
 }
 
 // NewBuffer constructs a new Buffer
@@ -173,6 +176,10 @@ func (b *Buffer) SetLogger(logger logger.Logger) {
 	}
 }
 
+func (b *Buffer) SetCaptureTap(tap *capture.Tap) {
+	b.captureTap = tap
+}
+
 func (b *Buffer) SetPaused(paused bool) {
 	b.Lock()
 	defer b.Unlock()
@@ -350,6 +357,10 @@ func (b *Buffer) Write(pkt []byte) (n int, err error) {
 		return
 	}
 
+	if c := b.captureTap.Load(); c != nil {
+		c.WriteRTP(capture.DirectionIngress, pkt)
+	}
+
 	b.Lock()
 	if b.closed.Load() {
 		b.Unlock()
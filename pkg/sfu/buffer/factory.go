@@ -19,6 +19,8 @@ import (
 	"sync"
 
 	"github.com/pion/transport/v3/packetio"
+
+	"github.com/livekit/livekit-server/pkg/sfu/capture"
 )
 
 type FactoryOfBufferFactory struct {
@@ -40,6 +42,7 @@ func (f *FactoryOfBufferFactory) CreateBufferFactory() *Factory {
 		rtpBuffers:           make(map[uint32]*Buffer),
 		rtcpReaders:          make(map[uint32]*RTCPReader),
 		rtxPair:              make(map[uint32]uint32),
+		captureTap:           capture.NewTap(),
 	}
 }
 
@@ -50,6 +53,7 @@ type Factory struct {
 	rtpBuffers           map[uint32]*Buffer
 	rtcpReaders          map[uint32]*RTCPReader
 	rtxPair              map[uint32]uint32 // repair -> base
+	captureTap           *capture.Tap
 }
 
 func (f *Factory) GetOrNew(packetType packetio.BufferPacketType, ssrc uint32) io.ReadWriteCloser {
@@ -61,6 +65,7 @@ func (f *Factory) GetOrNew(packetType packetio.BufferPacketType, ssrc uint32) io
 			return reader
 		}
 		reader := NewRTCPReader(ssrc)
+		reader.SetCaptureTap(f.captureTap)
 		f.rtcpReaders[ssrc] = reader
 		reader.OnClose(func() {
 			f.Lock()
@@ -73,6 +78,7 @@ func (f *Factory) GetOrNew(packetType packetio.BufferPacketType, ssrc uint32) io
 			return reader
 		}
 		buffer := NewBuffer(ssrc, f.trackingPacketsVideo, f.trackingPacketsAudio)
+		buffer.SetCaptureTap(f.captureTap)
 		f.rtpBuffers[ssrc] = buffer
 		for repair, base := range f.rtxPair {
 			if repair == ssrc {
@@ -129,3 +135,8 @@ func (f *Factory) SetRTXPair(repair, base uint32) {
 		repairBuffer.SetPrimaryBufferForRTX(baseBuffer)
 	}
 }
+
+// CaptureTap is where packet captures of the participant owning the factory are attached
+func (f *Factory) CaptureTap() *capture.Tap {
+	return f.captureTap
+}
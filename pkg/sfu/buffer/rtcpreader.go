@@ -18,6 +18,8 @@ import (
 	"io"
 
 	"go.uber.org/atomic"
+
+	"github.com/livekit/livekit-server/pkg/sfu/capture"
 )
 
 type RTCPReader struct {
@@ -25,6 +27,8 @@ type RTCPReader struct {
 	closed   atomic.Bool
 	onPacket atomic.Value // func([]byte)
 	onClose  func()
+
+	captureTap *capture.Tap
 }
 
 func NewRTCPReader(ssrc uint32) *RTCPReader {
@@ -36,12 +40,19 @@ func (r *RTCPReader) Write(p []byte) (n int, err error) {
 		err = io.EOF
 		return
 	}
+	if c := r.captureTap.Load(); c != nil {
+		c.WriteRTCP(capture.DirectionIngress, p)
+	}
 	if f, ok := r.onPacket.Load().(func([]byte)); ok && f != nil {
 		f(p)
 	}
 	return
 }
 
+func (r *RTCPReader) SetCaptureTap(tap *capture.Tap) {
+	r.captureTap = tap
+}
+
 func (r *RTCPReader) OnClose(fn func()) {
 	r.onClose = fn
 }
@@ -0,0 +1,317 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/logger"
+)
+
+// Packets are written with synthetic addresses, the participant on one side and the SFU on the other,
+// RTP and RTCP share the 5-tuple as they do with rtcp-mux.
+var (
+	ParticipantAddr = netip.MustParseAddrPort("10.0.0.2:50000")
+	SFUAddr         = netip.MustParseAddrPort("10.0.0.1:7882")
+)
+
+type Direction int
+
+const (
+	// DirectionIngress is from the participant to the SFU
+	DirectionIngress Direction = iota
+	// DirectionEgress is from the SFU to the participant
+	DirectionEgress
+)
+
+type StopReason string
+
+const (
+	StopReasonRequested StopReason = "requested"
+	StopReasonDuration  StopReason = "duration"
+	StopReasonSize      StopReason = "size"
+	StopReasonClosed    StopReason = "closed"
+)
+
+type CaptureParams struct {
+	ID                  string
+	ParticipantIdentity string
+	Duration            time.Duration
+	MaxBytes            int
+	// only RTP headers are kept, RTCP is always captured in full
+	HeadersOnly bool
+	Logger      logger.Logger
+}
+
+// CaptureInfo describes a capture
+type CaptureInfo struct {
+	ID                  string     `json:"id"`
+	ParticipantIdentity string     `json:"participant_identity"`
+	HeadersOnly         bool       `json:"headers_only"`
+	StartedAt           int64      `json:"started_at"`
+	StoppedAt           int64      `json:"stopped_at,omitempty"`
+	StopReason          StopReason `json:"stop_reason,omitempty"`
+	Packets             uint64     `json:"packets"`
+	Bytes               int        `json:"bytes"`
+}
+
+// Capture records decrypted RTP and RTCP packets of a participant in pcapng format in memory,
+// bounded by duration and size
+type Capture struct {
+	params CaptureParams
+
+	lock       sync.Mutex
+	data       []byte
+	scratch    []byte
+	startedAt  time.Time
+	stoppedAt  time.Time
+	stopReason StopReason
+	packets    uint64
+	timer      *time.Timer
+	onStop     []func(c *Capture)
+}
+
+func NewCapture(params CaptureParams) *Capture {
+	c := &Capture{
+		params:    params,
+		startedAt: time.Now(),
+	}
+	c.data = appendSectionHeader(c.data, fmt.Sprintf("capture %s of participant %s", params.ID, params.ParticipantIdentity))
+	c.data = appendInterfaceDescription(c.data, "livekit")
+	if params.Duration > 0 {
+		c.timer = time.AfterFunc(params.Duration, func() {
+			c.stop(StopReasonDuration)
+		})
+	}
+	return c
+}
+
+func (c *Capture) ID() string {
+	return c.params.ID
+}
+
+// OnStop registers a callback invoked once when the capture stops
+func (c *Capture) OnStop(fn func(c *Capture)) {
+	c.lock.Lock()
+	if c.stoppedAt.IsZero() {
+		c.onStop = append(c.onStop, fn)
+		c.lock.Unlock()
+		return
+	}
+	c.lock.Unlock()
+
+	fn(c)
+}
+
+func (c *Capture) WriteRTP(direction Direction, pkt []byte) {
+	captured := pkt
+	if c.params.HeadersOnly {
+		var hdr rtp.Header
+		n, err := hdr.Unmarshal(pkt)
+		if err != nil {
+			return
+		}
+		captured = pkt[:n]
+	}
+	c.write(direction, captured, len(pkt))
+}
+
+// WriteRTPHeaderAndPayload captures an RTP packet which has not been marshalled yet
+func (c *Capture) WriteRTPHeaderAndPayload(direction Direction, hdr *rtp.Header, payload []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.stoppedAt.IsZero() {
+		return
+	}
+
+	headerSize := hdr.MarshalSize()
+	if cap(c.scratch) < headerSize {
+		c.scratch = make([]byte, headerSize, headerSize+len(payload))
+	}
+	c.scratch = c.scratch[:headerSize]
+	if _, err := hdr.MarshalTo(c.scratch); err != nil {
+		return
+	}
+	if !c.params.HeadersOnly {
+		c.scratch = append(c.scratch, payload...)
+	}
+	c.writeLocked(direction, c.scratch, headerSize+len(payload))
+}
+
+func (c *Capture) WriteRTCP(direction Direction, pkt []byte) {
+	c.write(direction, pkt, len(pkt))
+}
+
+func (c *Capture) write(direction Direction, captured []byte, originalLength int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.stoppedAt.IsZero() {
+		return
+	}
+	c.writeLocked(direction, captured, originalLength)
+}
+
+func (c *Capture) writeLocked(direction Direction, captured []byte, originalLength int) {
+	headersSize := ipv4HeaderSize + udpHeaderSize
+	if c.params.MaxBytes > 0 && len(c.data)+enhancedPacketSize(headersSize+len(captured)) > c.params.MaxBytes {
+		if onStop := c.stopLocked(StopReasonSize); len(onStop) != 0 {
+			// writers hold the lock, callbacks are invoked outside of it
+			go func() {
+				for _, fn := range onStop {
+					fn(c)
+				}
+			}()
+		}
+		return
+	}
+
+	src, dst := ParticipantAddr, SFUAddr
+	if direction == DirectionEgress {
+		src, dst = SFUAddr, ParticipantAddr
+	}
+	frame := appendIPv4UDPHeaders(make([]byte, 0, headersSize+len(captured)), src, dst, originalLength)
+	frame = append(frame, captured...)
+	c.data = appendEnhancedPacket(c.data, time.Now(), direction == DirectionIngress, frame, headersSize+originalLength)
+	c.packets++
+}
+
+func (c *Capture) Stop() {
+	c.stop(StopReasonRequested)
+}
+
+func (c *Capture) Close() {
+	c.stop(StopReasonClosed)
+}
+
+func (c *Capture) stop(reason StopReason) {
+	c.lock.Lock()
+	onStop := c.stopLocked(reason)
+	c.lock.Unlock()
+
+	for _, fn := range onStop {
+		fn(c)
+	}
+}
+
+func (c *Capture) stopLocked(reason StopReason) []func(c *Capture) {
+	if !c.stoppedAt.IsZero() {
+		return nil
+	}
+
+	c.stoppedAt = time.Now()
+	c.stopReason = reason
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	if c.params.Logger != nil {
+		c.params.Logger.Infow("packet capture stopped", "captureID", c.params.ID, "reason", reason, "packets", c.packets, "bytes", len(c.data))
+	}
+
+	onStop := c.onStop
+	c.onStop = nil
+	return onStop
+}
+
+func (c *Capture) IsStopped() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return !c.stoppedAt.IsZero()
+}
+
+func (c *Capture) Info() *CaptureInfo {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	info := &CaptureInfo{
+		ID:                  c.params.ID,
+		ParticipantIdentity: c.params.ParticipantIdentity,
+		HeadersOnly:         c.params.HeadersOnly,
+		StartedAt:           c.startedAt.Unix(),
+		StopReason:          c.stopReason,
+		Packets:             c.packets,
+		Bytes:               len(c.data),
+	}
+	if !c.stoppedAt.IsZero() {
+		info.StoppedAt = c.stoppedAt.Unix()
+	}
+	return info
+}
+
+// Bytes returns the pcapng file captured so far
+func (c *Capture) Bytes() []byte {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]byte(nil), c.data...)
+}
+
+// ReadAt reads the pcapng file captured so far at off, bytes are only ever appended so earlier reads stay valid
+func (c *Capture) ReadAt(p []byte, off int64) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= int64(len(c.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, c.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// ---------------------------------------------------------------
+
+// Tap is where a capture is attached to packet paths, it is shared by all buffers of a participant
+type Tap struct {
+	capture atomic.Pointer[Capture]
+}
+
+func NewTap() *Tap {
+	return &Tap{}
+}
+
+// Load returns the active capture, nil when not capturing
+func (t *Tap) Load() *Capture {
+	if t == nil {
+		return nil
+	}
+	return t.capture.Load()
+}
+
+// Attach starts feeding packets to the capture, replacing any active one which is stopped,
+// the capture is detached when it stops
+func (t *Tap) Attach(c *Capture) {
+	if prev := t.capture.Swap(c); prev != nil && prev != c {
+		prev.Stop()
+	}
+	c.OnStop(func(c *Capture) {
+		t.capture.CompareAndSwap(c, nil)
+	})
+}
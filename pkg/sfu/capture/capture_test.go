@@ -0,0 +1,215 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+type pcapngBlock struct {
+	blockType uint32
+	body      []byte
+}
+
+func parseBlocks(t *testing.T, b []byte) []pcapngBlock {
+	var blocks []pcapngBlock
+	for len(b) != 0 {
+		require.GreaterOrEqual(t, len(b), 12)
+		total := int(binary.LittleEndian.Uint32(b[4:]))
+		require.Zero(t, total%4)
+		require.LessOrEqual(t, total, len(b))
+		require.Equal(t, uint32(total), binary.LittleEndian.Uint32(b[total-4:]))
+		blocks = append(blocks, pcapngBlock{
+			blockType: binary.LittleEndian.Uint32(b),
+			body:      b[8 : total-4],
+		})
+		b = b[total:]
+	}
+	return blocks
+}
+
+// packetData returns the captured bytes and original length of an enhanced packet block
+func packetData(block pcapngBlock) ([]byte, int) {
+	capturedLength := binary.LittleEndian.Uint32(block.body[12:])
+	originalLength := binary.LittleEndian.Uint32(block.body[16:])
+	return block.body[20 : 20+capturedLength], int(originalLength)
+}
+
+func testRTPPacket(t *testing.T, payloadSize int) []byte {
+	pkt := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    111,
+			SequenceNumber: 1000,
+			Timestamp:      48000,
+			SSRC:           1234,
+		},
+		Payload: make([]byte, payloadSize),
+	}
+	b, err := pkt.Marshal()
+	require.NoError(t, err)
+	return b
+}
+
+func TestCapture(t *testing.T) {
+	t.Run("pcapng structure", func(t *testing.T) {
+		c := NewCapture(CaptureParams{ID: "PC_test", ParticipantIdentity: "p1"})
+		pkt := testRTPPacket(t, 101)
+		c.WriteRTP(DirectionIngress, pkt)
+		c.WriteRTCP(DirectionEgress, []byte{0x80, 0xc8, 0x00, 0x06})
+
+		blocks := parseBlocks(t, c.Bytes())
+		require.Len(t, blocks, 4)
+		require.Equal(t, uint32(blockTypeSectionHeader), blocks[0].blockType)
+		require.Equal(t, uint32(byteOrderMagic), binary.LittleEndian.Uint32(blocks[0].body))
+		require.Equal(t, uint32(blockTypeInterfaceDesc), blocks[1].blockType)
+		require.Equal(t, uint16(linkTypeRaw), binary.LittleEndian.Uint16(blocks[1].body))
+
+		require.Equal(t, uint32(blockTypeEnhancedPacket), blocks[2].blockType)
+		data, originalLength := packetData(blocks[2])
+		require.Equal(t, ipv4HeaderSize+udpHeaderSize+len(pkt), originalLength)
+		require.Equal(t, pkt, data[ipv4HeaderSize+udpHeaderSize:])
+		// ingress goes from the participant to the SFU
+		require.Equal(t, ParticipantAddr.Addr().AsSlice(), data[12:16])
+		require.Equal(t, SFUAddr.Addr().AsSlice(), data[16:20])
+		require.Equal(t, ParticipantAddr.Port(), binary.BigEndian.Uint16(data[20:]))
+
+		data, _ = packetData(blocks[3])
+		require.Equal(t, SFUAddr.Addr().AsSlice(), data[12:16])
+
+		info := c.Info()
+		require.Equal(t, uint64(2), info.Packets)
+		require.Equal(t, len(c.Bytes()), info.Bytes)
+		require.Zero(t, info.StoppedAt)
+	})
+
+	t.Run("ip header checksum", func(t *testing.T) {
+		b := appendIPv4UDPHeaders(nil, ParticipantAddr, SFUAddr, 100)
+		var sum uint32
+		for i := 0; i < ipv4HeaderSize; i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(b[i:]))
+		}
+		for sum>>16 != 0 {
+			sum = (sum & 0xFFFF) + (sum >> 16)
+		}
+		require.Equal(t, uint32(0xFFFF), sum)
+		require.Equal(t, uint16(ipv4HeaderSize+udpHeaderSize+100), binary.BigEndian.Uint16(b[2:]))
+	})
+
+	t.Run("headers only", func(t *testing.T) {
+		c := NewCapture(CaptureParams{ID: "PC_test", HeadersOnly: true})
+		pkt := testRTPPacket(t, 500)
+		c.WriteRTP(DirectionIngress, pkt)
+
+		var hdr rtp.Header
+		hdrSize, err := hdr.Unmarshal(pkt)
+		require.NoError(t, err)
+		c.WriteRTPHeaderAndPayload(DirectionEgress, &hdr, pkt[hdrSize:])
+
+		blocks := parseBlocks(t, c.Bytes())
+		require.Len(t, blocks, 4)
+		for _, block := range blocks[2:] {
+			data, originalLength := packetData(block)
+			require.Equal(t, ipv4HeaderSize+udpHeaderSize+hdrSize, len(data))
+			require.Equal(t, pkt[:hdrSize], data[ipv4HeaderSize+udpHeaderSize:])
+			require.Equal(t, ipv4HeaderSize+udpHeaderSize+len(pkt), originalLength)
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		pkt := testRTPPacket(t, 100)
+		maxBytes := len(NewCapture(CaptureParams{ID: "PC_test"}).Bytes()) + 3*enhancedPacketSize(ipv4HeaderSize+udpHeaderSize+len(pkt))
+		c := NewCapture(CaptureParams{ID: "PC_test", MaxBytes: maxBytes})
+		stopped := make(chan struct{})
+		c.OnStop(func(_ *Capture) {
+			close(stopped)
+		})
+
+		for i := 0; i < 5; i++ {
+			c.WriteRTP(DirectionIngress, pkt)
+		}
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("capture did not stop")
+		}
+
+		info := c.Info()
+		require.Equal(t, StopReasonSize, info.StopReason)
+		require.Equal(t, uint64(3), info.Packets)
+		require.Equal(t, maxBytes, info.Bytes)
+	})
+
+	t.Run("duration limit", func(t *testing.T) {
+		c := NewCapture(CaptureParams{ID: "PC_test", Duration: 50 * time.Millisecond})
+		require.Eventually(t, c.IsStopped, time.Second, 10*time.Millisecond)
+		require.Equal(t, StopReasonDuration, c.Info().StopReason)
+
+		size := len(c.Bytes())
+		c.WriteRTP(DirectionIngress, testRTPPacket(t, 10))
+		require.Equal(t, size, len(c.Bytes()))
+	})
+}
+
+func TestCaptureReadAt(t *testing.T) {
+	c := NewCapture(CaptureParams{ID: "PC_test"})
+	for i := 0; i < 3; i++ {
+		c.WriteRTP(DirectionIngress, testRTPPacket(t, 10))
+	}
+	data := c.Bytes()
+
+	var read []byte
+	chunk := make([]byte, 7)
+	for off := int64(0); ; {
+		n, err := c.ReadAt(chunk, off)
+		read = append(read, chunk[:n]...)
+		off += int64(n)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	require.Equal(t, data, read)
+
+	n, err := c.ReadAt(chunk, int64(len(data)))
+	require.Zero(t, n)
+	require.Equal(t, io.EOF, err)
+}
+
+func TestTap(t *testing.T) {
+	var nilTap *Tap
+	require.Nil(t, nilTap.Load())
+
+	tap := NewTap()
+	c1 := NewCapture(CaptureParams{ID: "PC_1"})
+	tap.Attach(c1)
+	require.Equal(t, c1, tap.Load())
+
+	// attaching another capture stops the active one
+	c2 := NewCapture(CaptureParams{ID: "PC_2"})
+	tap.Attach(c2)
+	require.Equal(t, c2, tap.Load())
+	require.True(t, c1.IsStopped())
+	require.Equal(t, StopReasonRequested, c1.Info().StopReason)
+
+	c2.Stop()
+	require.Nil(t, tap.Load())
+}
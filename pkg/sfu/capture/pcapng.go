@@ -0,0 +1,160 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"time"
+)
+
+// minimal pcapng (https://www.ietf.org/archive/id/draft-ietf-opsawg-pcapng-02.html) encoding,
+// one section with a single raw IPv4 interface
+
+const (
+	blockTypeSectionHeader  = 0x0A0D0D0A
+	blockTypeInterfaceDesc  = 0x00000001
+	blockTypeEnhancedPacket = 0x00000006
+
+	byteOrderMagic = 0x1A2B3C4D
+
+	// LINKTYPE_RAW, packets start with the IP header
+	linkTypeRaw = 101
+	snapLength  = 65535
+
+	optionEndOfOpt   = 0
+	optionComment    = 1
+	optionEPBFlags   = 2
+	optionIfName     = 2
+	optionShbUserApp = 4
+
+	epbFlagsInbound  = 0x1
+	epbFlagsOutbound = 0x2
+
+	ipv4HeaderSize = 20
+	udpHeaderSize  = 8
+	ipProtocolUDP  = 17
+	ipDefaultTTL   = 64
+)
+
+func pad4(n int) int {
+	return (n + 3) &^ 3
+}
+
+func appendOption(b []byte, code uint16, value []byte) []byte {
+	b = binary.LittleEndian.AppendUint16(b, code)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(value)))
+	b = append(b, value...)
+	for i := len(value); i < pad4(len(value)); i++ {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func appendEndOfOptions(b []byte) []byte {
+	return binary.LittleEndian.AppendUint32(b, optionEndOfOpt)
+}
+
+// appendBlock frames body as a block of the given type, filling in both total length fields
+func appendBlock(b []byte, blockType uint32, body []byte) []byte {
+	total := uint32(12 + len(body))
+	b = binary.LittleEndian.AppendUint32(b, blockType)
+	b = binary.LittleEndian.AppendUint32(b, total)
+	b = append(b, body...)
+	return binary.LittleEndian.AppendUint32(b, total)
+}
+
+func appendSectionHeader(b []byte, comment string) []byte {
+	body := binary.LittleEndian.AppendUint32(nil, byteOrderMagic)
+	body = binary.LittleEndian.AppendUint16(body, 1) // major version
+	body = binary.LittleEndian.AppendUint16(body, 0) // minor version
+	// section length not specified
+	body = binary.LittleEndian.AppendUint64(body, 0xFFFFFFFFFFFFFFFF)
+	body = appendOption(body, optionShbUserApp, []byte("livekit-server"))
+	if comment != "" {
+		body = appendOption(body, optionComment, []byte(comment))
+	}
+	body = appendEndOfOptions(body)
+	return appendBlock(b, blockTypeSectionHeader, body)
+}
+
+func appendInterfaceDescription(b []byte, name string) []byte {
+	body := binary.LittleEndian.AppendUint16(nil, linkTypeRaw)
+	body = binary.LittleEndian.AppendUint16(body, 0) // reserved
+	body = binary.LittleEndian.AppendUint32(body, snapLength)
+	body = appendOption(body, optionIfName, []byte(name))
+	body = appendEndOfOptions(body)
+	return appendBlock(b, blockTypeInterfaceDesc, body)
+}
+
+// appendEnhancedPacket appends a packet captured at the given time, data may be shorter than originalLength when truncated,
+// the default interface timestamp resolution of microseconds is used
+func appendEnhancedPacket(b []byte, at time.Time, isInbound bool, data []byte, originalLength int) []byte {
+	ts := uint64(at.UnixMicro())
+	body := binary.LittleEndian.AppendUint32(nil, 0) // interface id
+	body = binary.LittleEndian.AppendUint32(body, uint32(ts>>32))
+	body = binary.LittleEndian.AppendUint32(body, uint32(ts))
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(data)))
+	body = binary.LittleEndian.AppendUint32(body, uint32(originalLength))
+	body = append(body, data...)
+	for i := len(data); i < pad4(len(data)); i++ {
+		body = append(body, 0)
+	}
+	flags := uint32(epbFlagsOutbound)
+	if isInbound {
+		flags = epbFlagsInbound
+	}
+	body = appendOption(body, optionEPBFlags, binary.LittleEndian.AppendUint32(nil, flags))
+	body = appendEndOfOptions(body)
+	return appendBlock(b, blockTypeEnhancedPacket, body)
+}
+
+// enhancedPacketSize is the size of an enhanced packet block with capturedLength bytes of data
+func enhancedPacketSize(capturedLength int) int {
+	// block header/trailer + interface/timestamp/lengths + data + flags option + end of options
+	return 12 + 20 + pad4(capturedLength) + 8 + 4
+}
+
+// appendIPv4UDPHeaders appends synthetic IPv4 and UDP headers for a UDP payload of payloadLength bytes,
+// the UDP checksum is left at zero, i. e. not computed, which is allowed over IPv4
+func appendIPv4UDPHeaders(b []byte, src netip.AddrPort, dst netip.AddrPort, payloadLength int) []byte {
+	udpLength := udpHeaderSize + payloadLength
+	totalLength := ipv4HeaderSize + udpLength
+
+	start := len(b)
+	b = append(b, 0x45, 0) // version 4, IHL 5, DSCP/ECN
+	b = binary.BigEndian.AppendUint16(b, uint16(totalLength))
+	b = binary.BigEndian.AppendUint16(b, 0)      // identification
+	b = binary.BigEndian.AppendUint16(b, 0x4000) // don't fragment
+	b = append(b, ipDefaultTTL, ipProtocolUDP)
+	b = binary.BigEndian.AppendUint16(b, 0) // checksum, filled in below
+	srcIP, dstIP := src.Addr().As4(), dst.Addr().As4()
+	b = append(b, srcIP[:]...)
+	b = append(b, dstIP[:]...)
+
+	var sum uint32
+	for i := start; i < start+ipv4HeaderSize; i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(b[i:]))
+	}
+	for sum>>16 != 0 {
+		sum = (sum & 0xFFFF) + (sum >> 16)
+	}
+	binary.BigEndian.PutUint16(b[start+10:], ^uint16(sum))
+
+	b = binary.BigEndian.AppendUint16(b, src.Port())
+	b = binary.BigEndian.AppendUint16(b, dst.Port())
+	b = binary.BigEndian.AppendUint16(b, uint16(udpLength))
+	return binary.BigEndian.AppendUint16(b, 0) // checksum
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/sfu/rtpstats"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 )
 
 const (
@@ -51,6 +52,7 @@ type ConnectionStatsParams struct {
 	IncludeRTT         bool
 	IncludeJitter      bool
 	EnableBitrateScore bool
+	AudioScorer        AudioScorer
 	ReceiverProvider   ConnectionStatsReceiverProvider
 	SenderProvider     ConnectionStatsSenderProvider
 	Logger             logger.Logger
@@ -72,6 +74,10 @@ type ConnectionStats struct {
 
 	scorer *qualityScorer
 
+	freezes              *freezeDetector
+	lastScoredFreezes    FreezeStats
+	lastDeltaFreezeStats FreezeStats
+
 	done core.Fuse
 }
 
@@ -84,6 +90,7 @@ func NewConnectionStats(params ConnectionStatsParams) *ConnectionStats {
 			EnableBitrateScore: params.EnableBitrateScore,
 			Logger:             params.Logger,
 		}),
+		freezes: newFreezeDetector(),
 	}
 }
 
@@ -95,6 +102,7 @@ func (cs *ConnectionStats) StartAt(codecMimeType mime.MimeType, isFECEnabled boo
 	cs.isVideo.Store(mime.IsMimeTypeVideo(codecMimeType))
 	cs.codecMimeType.Store(codecMimeType)
 	cs.scorer.StartAt(getPacketLossWeight(codecMimeType, isFECEnabled), at)
+	cs.scorer.SetEModel(cs.getEModel(codecMimeType, isFECEnabled))
 
 	go cs.updateStatsWorker()
 }
@@ -111,6 +119,15 @@ func (cs *ConnectionStats) UpdateCodec(codecMimeType mime.MimeType, isFECEnabled
 	cs.isVideo.Store(mime.IsMimeTypeVideo(codecMimeType))
 	cs.codecMimeType.Store(codecMimeType)
 	cs.scorer.UpdatePacketLossWeight(getPacketLossWeight(codecMimeType, isFECEnabled))
+	cs.scorer.SetEModel(cs.getEModel(codecMimeType, isFECEnabled))
+}
+
+func (cs *ConnectionStats) getEModel(codecMimeType mime.MimeType, isFECEnabled bool) *eModel {
+	if cs.params.AudioScorer != AudioScorerEModel {
+		return nil
+	}
+
+	return newEModel(codecMimeType, isFECEnabled)
 }
 
 func (cs *ConnectionStats) OnStatsUpdate(fn func(cs *ConnectionStats, stat *livekit.AnalyticsStat)) {
@@ -122,6 +139,7 @@ func (cs *ConnectionStats) UpdateMuteAt(isMuted bool, at time.Time) {
 		return
 	}
 
+	cs.freezes.UpdateMuteAt(isMuted, at)
 	cs.scorer.UpdateMuteAt(isMuted, at)
 }
 
@@ -130,6 +148,7 @@ func (cs *ConnectionStats) UpdateMute(isMuted bool) {
 		return
 	}
 
+	cs.freezes.UpdateMuteAt(isMuted, time.Now())
 	cs.scorer.UpdateMute(isMuted)
 }
 
@@ -170,6 +189,7 @@ func (cs *ConnectionStats) UpdatePauseAt(isPaused bool, at time.Time) {
 		return
 	}
 
+	cs.freezes.UpdatePauseAt(isPaused, at)
 	cs.scorer.UpdatePauseAt(isPaused, at)
 }
 
@@ -178,6 +198,7 @@ func (cs *ConnectionStats) UpdatePause(isPaused bool) {
 		return
 	}
 
+	cs.freezes.UpdatePauseAt(isPaused, time.Now())
 	cs.scorer.UpdatePause(isPaused)
 }
 
@@ -197,10 +218,79 @@ func (cs *ConnectionStats) AddLayerTransition(distance float64) {
 	cs.scorer.AddLayerTransition(distance)
 }
 
+// AddFrameAt records the end of a video frame sent to the subscriber
+func (cs *ConnectionStats) AddFrameAt(at time.Time) {
+	if cs.done.IsBroken() {
+		return
+	}
+
+	cs.freezes.AddFrameAt(at)
+}
+
+func (cs *ConnectionStats) AddFrame() {
+	cs.AddFrameAt(time.Now())
+}
+
+// AddKeyFrameAt records a video key frame sent to the subscriber
+func (cs *ConnectionStats) AddKeyFrameAt(at time.Time) {
+	if cs.done.IsBroken() {
+		return
+	}
+
+	cs.freezes.AddKeyFrameAt(at)
+}
+
+func (cs *ConnectionStats) AddKeyFrame() {
+	cs.AddKeyFrameAt(time.Now())
+}
+
+// AddKeyFrameRequestAt records a key frame request (PLI/FIR) from the subscriber
+func (cs *ConnectionStats) AddKeyFrameRequestAt(at time.Time) {
+	if cs.done.IsBroken() {
+		return
+	}
+
+	cs.freezes.AddKeyFrameRequestAt(at)
+}
+
+func (cs *ConnectionStats) AddKeyFrameRequest() {
+	cs.AddKeyFrameRequestAt(time.Now())
+}
+
+// GetDeltaFreezeStats returns the freezes since the previous call, nil if there were none
+func (cs *ConnectionStats) GetDeltaFreezeStats() *FreezeStats {
+	stats := cs.freezes.GetStats()
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	delta := stats.sub(&cs.lastDeltaFreezeStats)
+	cs.lastDeltaFreezeStats = stats
+	if delta.IsZero() {
+		return nil
+	}
+	return delta
+}
+
+func (cs *ConnectionStats) getFrozenSinceLastScore() time.Duration {
+	stats := cs.freezes.GetStats()
+
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	frozen := stats.FrozenDuration() - cs.lastScoredFreezes.FrozenDuration()
+	cs.lastScoredFreezes = stats
+	return frozen
+}
+
 func (cs *ConnectionStats) GetScoreAndQuality() (float32, livekit.ConnectionQuality) {
 	return cs.scorer.GetMOSAndQuality()
 }
 
+func (cs *ConnectionStats) GetRFactorAndMOS() (float32, float32) {
+	return cs.scorer.GetRFactorAndMOS()
+}
+
 func (cs *ConnectionStats) updateScoreWithAggregate(agg *rtpstats.RTPDeltaInfo, lastRTCPAt time.Time, at time.Time) float32 {
 	var stat windowStat
 	if agg != nil {
@@ -217,6 +307,7 @@ func (cs *ConnectionStats) updateScoreWithAggregate(agg *rtpstats.RTPDeltaInfo,
 
 		stat.lastRTCPAt = lastRTCPAt
 	}
+	stat.frozen = cs.getFrozenSinceLastScore()
 	if at.IsZero() {
 		cs.scorer.Update(&stat)
 	} else {
@@ -326,6 +417,14 @@ func (cs *ConnectionStats) updateStreamingStart(at time.Time) time.Time {
 
 func (cs *ConnectionStats) getStat() {
 	score, streams, isSender := cs.updateScoreAt(time.Time{})
+	if len(streams) != 0 && cs.scorer.IsEModel() {
+		direction := prometheus.Incoming
+		if isSender {
+			direction = prometheus.Outgoing
+		}
+		rFactor, mos := cs.scorer.GetRFactorAndMOS()
+		prometheus.RecordAudioQuality(direction, rFactor, mos)
+	}
 
 	if cs.onStatsUpdate != nil && len(streams) != 0 {
 		analyticsStreams := make([]*livekit.AnalyticsStream, 0, len(streams))
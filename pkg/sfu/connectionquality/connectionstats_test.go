@@ -901,3 +901,243 @@ func TestConnectionQuality(t *testing.T) {
 		}
 	})
 }
+
+func TestEModel(t *testing.T) {
+	window := func(packets uint32, lost uint32, bytes uint64, rtt uint32) *windowStat {
+		return &windowStat{
+			duration:    5 * time.Second,
+			packets:     packets,
+			packetsLost: lost,
+			bytes:       bytes,
+			rttMax:      rtt,
+		}
+	}
+
+	t.Run("opus", func(t *testing.T) {
+		e := newEModel(mime.MimeTypeOpus, false)
+
+		// 32 kbps, no impairment other than codec delay
+		require.InDelta(t, 92.564, e.calculateRFactor(window(250, 0, 20000, 0), 1.0), 1e-3)
+
+		// 5% loss
+		require.InDelta(t, 60.064, e.calculateRFactor(window(250, 13, 20000, 0), 1.0), 1e-3)
+
+		// 400 ms RTT adds delay impairment beyond the 177.3 ms knee
+		require.InDelta(t, 82.352, e.calculateRFactor(window(250, 0, 20000, 400), 1.0), 1e-3)
+
+		// low bitrate adds equipment impairment
+		require.InDelta(t, 72.564, e.calculateRFactor(window(250, 0, 6250, 0), 1.0), 1e-3)
+
+		// DTX windows keep the impairment of the last active window and discount loss
+		require.InDelta(t, 72.564, e.calculateRFactor(window(25, 0, 75, 0), 0.3), 1e-3)
+		require.Greater(t, e.calculateRFactor(window(25, 5, 75, 0), 0.3), e.calculateRFactor(window(25, 5, 75, 0), 1.0))
+	})
+
+	t.Run("red and fec", func(t *testing.T) {
+		opus := newEModel(mime.MimeTypeOpus, false)
+		fec := newEModel(mime.MimeTypeOpus, true)
+		red := newEModel(mime.MimeTypeRED, false)
+
+		w := window(250, 25, 20000, 0)
+		require.Greater(t, fec.calculateRFactor(w, 1.0), opus.calculateRFactor(w, 1.0))
+		require.Greater(t, red.calculateRFactor(window(250, 25, 60000, 0), 1.0), fec.calculateRFactor(w, 1.0))
+	})
+
+	t.Run("not audio", func(t *testing.T) {
+		require.Nil(t, newEModel(mime.MimeTypeVP8, false))
+	})
+
+	t.Run("connection stats", func(t *testing.T) {
+		trp := newTestReceiverProvider()
+		cs := NewConnectionStats(ConnectionStatsParams{
+			AudioScorer:      AudioScorerEModel,
+			ReceiverProvider: trp,
+			Logger:           logger.GetLogger(),
+		})
+
+		duration := 5 * time.Second
+		now := time.Now()
+		cs.StartAt(mime.MimeTypeOpus, false, now.Add(-duration))
+
+		trp.setStreams(map[uint32]*buffer.StreamStatsWithLayers{
+			1: {
+				RTPStats: &rtpstats.RTPDeltaInfo{
+					StartTime: now,
+					EndTime:   now.Add(duration),
+					Packets:   250,
+					Bytes:     20000,
+				},
+			},
+		})
+		cs.updateScoreAt(now.Add(duration))
+		// R of the window, 93.2 less the delay impairment of Opus
+		rFactor, mos := cs.GetRFactorAndMOS()
+		require.InDelta(t, 92.56, rFactor, 1e-2)
+		require.InDelta(t, scoreToMOS(float64(rFactor)), mos, 1e-6)
+		_, quality := cs.GetScoreAndQuality()
+		require.Equal(t, livekit.ConnectionQuality_EXCELLENT, quality)
+
+		// 11% loss, rated lower but still GOOD by the E-model
+		now = now.Add(duration)
+		trp.setStreams(map[uint32]*buffer.StreamStatsWithLayers{
+			1: {
+				RTPStats: &rtpstats.RTPDeltaInfo{
+					StartTime:   now,
+					EndTime:     now.Add(duration),
+					Packets:     225,
+					PacketsLost: 25,
+					Bytes:       18000,
+				},
+			},
+		})
+		cs.updateScoreAt(now.Add(duration))
+		// R is reported as rated, the score moving towards it is smoothed
+		rFactor, mos = cs.GetRFactorAndMOS()
+		require.InDelta(t, 42.56, rFactor, 1e-2)
+		require.InDelta(t, scoreToMOS(float64(rFactor)), mos, 1e-6)
+		score, _ := cs.scorer.GetScoreAndQuality()
+		require.InDelta(t, 52.86, score, 1e-2)
+		_, quality = cs.GetScoreAndQuality()
+		require.Equal(t, livekit.ConnectionQuality_GOOD, quality)
+	})
+}
+
+func TestFreezeDetection(t *testing.T) {
+	frameInterval := 33 * time.Millisecond
+	addFrames := func(cs *ConnectionStats, at time.Time, num int) time.Time {
+		for i := 0; i < num; i++ {
+			at = at.Add(frameInterval)
+			cs.AddFrameAt(at)
+		}
+		return at
+	}
+
+	t.Run("causes", func(t *testing.T) {
+		cs := NewConnectionStats(ConnectionStatsParams{
+			Logger: logger.GetLogger(),
+		})
+		require.Nil(t, cs.GetDeltaFreezeStats())
+
+		now := addFrames(cs, time.Now(), 20)
+		require.Nil(t, cs.GetDeltaFreezeStats())
+
+		// gap well beyond frame interval
+		now = now.Add(500 * time.Millisecond)
+		cs.AddFrameAt(now)
+		now = addFrames(cs, now, 5)
+
+		// subscriber lost a frame and asked for a key frame, frames till the key frame cannot be decoded
+		cs.AddKeyFrameRequestAt(now)
+		now = addFrames(cs, now, 3)
+		cs.AddKeyFrameAt(now.Add(frameInterval))
+		now = addFrames(cs, now, 1)
+
+		fs := cs.GetDeltaFreezeStats()
+		require.NotNil(t, fs)
+		require.Equal(t, FreezeCauseStats{Count: 1, Duration: 500 * time.Millisecond}, fs.Network)
+		require.Equal(t, FreezeCauseStats{Count: 1, Duration: 4 * frameInterval}, fs.KeyFrame)
+		require.Equal(t, uint32(3), fs.FramesDropped)
+		require.Zero(t, fs.Paused.Count)
+		require.Nil(t, cs.GetDeltaFreezeStats())
+
+		// paused by allocator, lasts till the first frame after resume and is not a network freeze
+		cs.UpdatePauseAt(true, now)
+		cs.AddKeyFrameRequestAt(now.Add(time.Second))
+		cs.UpdatePauseAt(false, now.Add(2*time.Second))
+		cs.AddFrameAt(now.Add(2100 * time.Millisecond))
+		now = addFrames(cs, now.Add(2100*time.Millisecond), 5)
+
+		fs = cs.GetDeltaFreezeStats()
+		require.NotNil(t, fs)
+		require.Equal(t, FreezeCauseStats{Count: 1, Duration: 2100 * time.Millisecond}, fs.Paused)
+		require.Zero(t, fs.Network.Count)
+		require.Zero(t, fs.KeyFrame.Count)
+
+		// muted streams do not freeze
+		cs.UpdateMuteAt(true, now)
+		cs.UpdateMuteAt(false, now.Add(3*time.Second))
+		addFrames(cs, now.Add(3*time.Second), 5)
+		require.Nil(t, cs.GetDeltaFreezeStats())
+	})
+
+	t.Run("loss, key frame request and key frame", func(t *testing.T) {
+		cs := NewConnectionStats(ConnectionStatsParams{
+			Logger: logger.GetLogger(),
+		})
+		now := addFrames(cs, time.Now(), 20)
+
+		// frames are lost, the subscriber asks for a key frame after a while and gets it later,
+		// the freeze is split at the request rather than counted by both causes
+		cs.AddKeyFrameRequestAt(now.Add(300 * time.Millisecond))
+		now = now.Add(800 * time.Millisecond)
+		cs.AddKeyFrameAt(now)
+		cs.AddFrameAt(now)
+		now = addFrames(cs, now, 5)
+
+		fs := cs.GetDeltaFreezeStats()
+		require.NotNil(t, fs)
+		require.Equal(t, FreezeCauseStats{Count: 1, Duration: 300 * time.Millisecond}, fs.Network)
+		require.Equal(t, FreezeCauseStats{Count: 1, Duration: 500 * time.Millisecond}, fs.KeyFrame)
+		require.Equal(t, 800*time.Millisecond, fs.FrozenDuration())
+		require.Zero(t, fs.FramesDropped)
+
+		// asked right away, the freeze is all waiting for the key frame
+		cs.AddKeyFrameRequestAt(now.Add(50 * time.Millisecond))
+		now = now.Add(700 * time.Millisecond)
+		cs.AddKeyFrameAt(now)
+		cs.AddFrameAt(now)
+
+		fs = cs.GetDeltaFreezeStats()
+		require.NotNil(t, fs)
+		require.Zero(t, fs.Network.Count)
+		require.Equal(t, FreezeCauseStats{Count: 1, Duration: 650 * time.Millisecond}, fs.KeyFrame)
+		require.Equal(t, 650*time.Millisecond, fs.FrozenDuration())
+	})
+
+	t.Run("score", func(t *testing.T) {
+		trp := newTestReceiverProvider()
+		cs := NewConnectionStats(ConnectionStatsParams{
+			ReceiverProvider: trp,
+			Logger:           logger.GetLogger(),
+		})
+
+		duration := 5 * time.Second
+		now := time.Now()
+		cs.StartAt(mime.MimeTypeVP8, false, now.Add(-duration))
+
+		// video frozen for a fifth of the window without any packet loss
+		at := addFrames(cs, now, 20)
+		at = at.Add(time.Second)
+		cs.AddFrameAt(at)
+		addFrames(cs, at, 20)
+
+		trp.setStreams(map[uint32]*buffer.StreamStatsWithLayers{
+			1: {
+				RTPStats: &rtpstats.RTPDeltaInfo{
+					StartTime: now,
+					EndTime:   now.Add(duration),
+					Packets:   250,
+				},
+			},
+		})
+		cs.updateScoreAt(now.Add(duration))
+		_, quality := cs.GetScoreAndQuality()
+		require.Equal(t, livekit.ConnectionQuality_GOOD, quality)
+
+		// frozen time is consumed by the window
+		now = now.Add(duration)
+		trp.setStreams(map[uint32]*buffer.StreamStatsWithLayers{
+			1: {
+				RTPStats: &rtpstats.RTPDeltaInfo{
+					StartTime: now,
+					EndTime:   now.Add(duration),
+					Packets:   250,
+				},
+			},
+		})
+		cs.updateScoreAt(now.Add(duration))
+		cs.updateScoreAt(now.Add(2 * duration))
+		_, quality = cs.GetScoreAndQuality()
+		require.Equal(t, livekit.ConnectionQuality_EXCELLENT, quality)
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectionquality
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+)
+
+// AudioScorer selects the model used to score audio tracks
+type AudioScorer string
+
+const (
+	// AudioScorerDefault scores audio with the same loss/delay heuristic as video
+	AudioScorerDefault AudioScorer = ""
+	// AudioScorerEModel scores audio with the ITU-T G.107 E-model
+	AudioScorerEModel AudioScorer = "emodel"
+)
+
+// Validate returns an error for models other than the known ones
+func (s AudioScorer) Validate() error {
+	switch s {
+	case AudioScorerDefault, AudioScorerEModel:
+		return nil
+	default:
+		return fmt.Errorf("unknown audio quality scorer %q, expected %q or none", string(s), string(AudioScorerEModel))
+	}
+}
+
+const (
+	// R = Ro - Is with G.107 default values, the maximum rating without any impairment
+	cEModelDefaultR = 93.2
+
+	// Opus is used with 20 ms frames, plus 6.5 ms of encoder look-ahead
+	cEModelOpusFrameMs    = 20.0
+	cEModelOpusCodecDelay = cEModelOpusFrameMs + 6.5
+	// G.711 with 20 ms packetization
+	cEModelPCMCodecDelay = 20.0
+
+	// packet loss robustness of Opus PLC, and with in-band FEC
+	cEModelOpusBpl    = 10.0
+	cEModelOpusFECBpl = 20.0
+	// G.711 with PLC as per G.113 Appendix I
+	cEModelPCMBpl = 25.1
+
+	// RED carries two redundant encodings besides the primary one
+	cEModelREDEncodings = 3
+
+	// windows with fewer packets than this ratio of the usual packet rate are treated as DTX/silence
+	cEModelDTXRatio = 0.9
+)
+
+// Opus does not have equipment impairment factors in G.113, these are derived from
+// listening tests of Opus against codecs that do, for the narrowband E-model scale.
+var eModelOpusIe = []struct {
+	minKbps float64
+	ie      float64
+}{
+	{minKbps: 24, ie: 0},
+	{minKbps: 16, ie: 5},
+	{minKbps: 12, ie: 11},
+	{minKbps: 0, ie: 20},
+}
+
+// eModel computes the transmission rating factor R of ITU-T G.107 for an audio stream.
+// Unmeasured parameters use G.107 default values.
+type eModel struct {
+	codecDelayMs float64
+	bpl          float64
+	isOpus       bool
+	isRED        bool
+
+	// equipment impairment of the last window with active speech
+	ie float64
+}
+
+func newEModel(mimeType mime.MimeType, isFECEnabled bool) *eModel {
+	switch mimeType {
+	case mime.MimeTypeOpus, mime.MimeTypeRED:
+		e := &eModel{
+			codecDelayMs: cEModelOpusCodecDelay,
+			bpl:          cEModelOpusBpl,
+			isOpus:       true,
+			isRED:        mimeType == mime.MimeTypeRED,
+		}
+		if isFECEnabled {
+			e.bpl = cEModelOpusFECBpl
+		}
+		return e
+
+	case mime.MimeTypePCMU, mime.MimeTypePCMA:
+		return &eModel{
+			codecDelayMs: cEModelPCMCodecDelay,
+			bpl:          cEModelPCMBpl,
+		}
+
+	default:
+		return nil
+	}
+}
+
+// calculateRFactor rates a window, dtxFactor is the ratio (0, 1] of adjusted to configured packet loss weight,
+// i. e. lower during DTX, when loss affects silence rather than speech.
+func (e *eModel) calculateRFactor(w *windowStat, dtxFactor float64) float64 {
+	// delay impairment Id, simplified for echo-free terminals, using mouth-to-ear delay
+	// made of network one-way delay, jitter buffer and codec delay
+	ta := float64(w.rttMax)/2.0 + (w.jitterMax*2.0)/1000.0 + e.codecDelayMs
+	id := 0.024 * ta
+	if ta > 177.3 {
+		id += 0.11 * (ta - 177.3)
+	}
+
+	// equipment impairment Ie is derived from the bitrate of windows with active speech only,
+	// DTX packets would make the bitrate look much lower than the one used while talking
+	if dtxFactor >= cEModelDTXRatio && w.packets > 0 && e.isOpus {
+		kbps := float64(w.bytes*8) / float64(w.packets) / cEModelOpusFrameMs
+		if e.isRED {
+			kbps /= cEModelREDEncodings
+		}
+		for _, entry := range eModelOpusIe {
+			if kbps >= entry.minKbps {
+				e.ie = entry.ie
+				break
+			}
+		}
+	}
+
+	// effective equipment impairment Ie,eff for random loss (BurstR = 1)
+	var ppl float64
+	actualLost := w.packetsLost - w.packetsMissing - w.packetsOutOfOrder
+	if int32(actualLost) > 0 && w.packets+w.packetsPadding > 0 {
+		ppl = float64(actualLost) / float64(w.packets+w.packetsPadding)
+	}
+	if e.isRED {
+		// a packet is only lost to the decoder when its redundant copies are lost too,
+		// assuming some burstiness, losses are counted as pairs rather than triplets
+		ppl = ppl * ppl
+	}
+	ppl *= 100.0 * dtxFactor
+	ieEff := e.ie + (95.0-e.ie)*ppl/(ppl+e.bpl)
+
+	return math.Max(0.0, math.Min(cMaxScore, cEModelDefaultR-id-ieEff))
+}
@@ -0,0 +1,267 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectionquality
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// a gap between frames is a freeze when it is longer than
+	// max(cFreezeIntervalFactor * usual interval, usual interval + cFreezeIntervalMargin),
+	// same as the definition used by WebRTC stats
+	cFreezeIntervalFactor = 3
+	cFreezeIntervalMargin = 150 * time.Millisecond
+	// number of frames before the usual frame interval is trusted
+	cFreezeMinFrames = 10
+	// weight of the latest frame interval in the usual frame interval
+	cFreezeIntervalAlpha = 0.1
+)
+
+// FreezeCause is the reason a subscriber did not see new video frames
+type FreezeCause int
+
+const (
+	// FreezeCauseNetwork is a gap between forwarded frames, i. e. frames were lost or delayed before reaching the SFU
+	FreezeCauseNetwork FreezeCause = iota
+	// FreezeCauseKeyFrame is a subscriber decoder waiting for a key frame after loss
+	FreezeCauseKeyFrame
+	// FreezeCausePaused is the stream allocator pausing the stream due to congestion
+	FreezeCausePaused
+)
+
+func (f FreezeCause) String() string {
+	switch f {
+	case FreezeCauseNetwork:
+		return "network"
+	case FreezeCauseKeyFrame:
+		return "key_frame"
+	case FreezeCausePaused:
+		return "paused"
+	default:
+		return "unknown"
+	}
+}
+
+type FreezeCauseStats struct {
+	Count    uint32
+	Duration time.Duration
+}
+
+func (f FreezeCauseStats) sub(other FreezeCauseStats) FreezeCauseStats {
+	return FreezeCauseStats{
+		Count:    f.Count - other.Count,
+		Duration: f.Duration - other.Duration,
+	}
+}
+
+// FreezeStats are the video freezes seen by a subscriber
+type FreezeStats struct {
+	Network  FreezeCauseStats
+	KeyFrame FreezeCauseStats
+	Paused   FreezeCauseStats
+
+	// frames forwarded while the subscriber was waiting for a key frame, those cannot be decoded
+	FramesDropped uint32
+}
+
+func (f *FreezeStats) Get(cause FreezeCause) FreezeCauseStats {
+	switch cause {
+	case FreezeCauseNetwork:
+		return f.Network
+	case FreezeCauseKeyFrame:
+		return f.KeyFrame
+	case FreezeCausePaused:
+		return f.Paused
+	default:
+		return FreezeCauseStats{}
+	}
+}
+
+func (f *FreezeStats) IsZero() bool {
+	return f.Network.Count == 0 && f.KeyFrame.Count == 0 && f.Paused.Count == 0 && f.FramesDropped == 0
+}
+
+// FrozenDuration is the time the subscriber was frozen for reasons other than the SFU pausing the stream.
+// A network freeze ends when the subscriber asks for a key frame, so the causes do not overlap.
+func (f *FreezeStats) FrozenDuration() time.Duration {
+	return f.Network.Duration + f.KeyFrame.Duration
+}
+
+func (f *FreezeStats) sub(other *FreezeStats) *FreezeStats {
+	return &FreezeStats{
+		Network:       f.Network.sub(other.Network),
+		KeyFrame:      f.KeyFrame.sub(other.KeyFrame),
+		Paused:        f.Paused.sub(other.Paused),
+		FramesDropped: f.FramesDropped - other.FramesDropped,
+	}
+}
+
+// ------------------------------------------
+
+// freezeDetector tracks frames forwarded to a subscriber and attributes gaps to a cause
+type freezeDetector struct {
+	lock  sync.Mutex
+	stats FreezeStats
+
+	numFrames     int
+	lastFrameAt   time.Time
+	frameInterval float64 // in ns
+
+	isMuted bool
+
+	// a pause lasts till the first frame after resume
+	pausedAt  time.Time
+	isResumed bool
+
+	keyFrameRequestedAt time.Time
+	// start of the last key frame wait, till the next frame ends the gap it is part of
+	keyFrameWaitFrom time.Time
+}
+
+func newFreezeDetector() *freezeDetector {
+	return &freezeDetector{}
+}
+
+func (f *freezeDetector) AddFrameAt(at time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.pausedAt.IsZero() {
+		if !f.isResumed {
+			return
+		}
+
+		f.stats.Paused.Count++
+		f.stats.Paused.Duration += at.Sub(f.pausedAt)
+		f.pausedAt = time.Time{}
+		f.isResumed = false
+		f.lastFrameAt = at
+		return
+	}
+
+	if f.isMuted {
+		return
+	}
+
+	waitFrom := f.keyFrameRequestedAt
+	if !waitFrom.IsZero() {
+		f.stats.FramesDropped++
+	} else {
+		waitFrom = f.keyFrameWaitFrom
+	}
+	f.keyFrameWaitFrom = time.Time{}
+
+	if !f.lastFrameAt.IsZero() {
+		gap := at.Sub(f.lastFrameAt)
+		if f.numFrames >= cFreezeMinFrames && gap > f.freezeThreshold() {
+			// once the subscriber asked for a key frame, the freeze is counted as waiting for it
+			networkGap := gap
+			if !waitFrom.IsZero() && waitFrom.Before(at) {
+				networkGap = max(0, waitFrom.Sub(f.lastFrameAt))
+			}
+			if networkGap > f.freezeThreshold() {
+				f.stats.Network.Count++
+				f.stats.Network.Duration += networkGap
+			}
+		} else if f.frameInterval == 0 {
+			f.frameInterval = float64(gap)
+		} else {
+			f.frameInterval = cFreezeIntervalAlpha*float64(gap) + (1.0-cFreezeIntervalAlpha)*f.frameInterval
+		}
+	}
+	f.numFrames++
+	f.lastFrameAt = at
+}
+
+func (f *freezeDetector) freezeThreshold() time.Duration {
+	interval := time.Duration(f.frameInterval)
+	return max(cFreezeIntervalFactor*interval, interval+cFreezeIntervalMargin)
+}
+
+func (f *freezeDetector) AddKeyFrameAt(at time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.keyFrameRequestedAt.IsZero() {
+		return
+	}
+
+	f.stats.KeyFrame.Count++
+	f.stats.KeyFrame.Duration += at.Sub(f.keyFrameRequestedAt)
+	f.keyFrameWaitFrom = f.keyFrameRequestedAt
+	f.keyFrameRequestedAt = time.Time{}
+}
+
+func (f *freezeDetector) AddKeyFrameRequestAt(at time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// a paused or muted stream restarts with a key frame anyway, not waiting on the subscriber
+	if f.isMuted || !f.pausedAt.IsZero() || !f.keyFrameRequestedAt.IsZero() {
+		return
+	}
+
+	f.keyFrameRequestedAt = at
+}
+
+func (f *freezeDetector) UpdatePauseAt(isPaused bool, at time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if isPaused {
+		if f.pausedAt.IsZero() {
+			f.pausedAt = at
+			f.isResumed = false
+			f.keyFrameRequestedAt = time.Time{}
+			f.keyFrameWaitFrom = time.Time{}
+			f.lastFrameAt = time.Time{}
+		}
+		return
+	}
+
+	if !f.pausedAt.IsZero() {
+		f.isResumed = true
+	}
+}
+
+func (f *freezeDetector) UpdateMuteAt(isMuted bool, at time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.isMuted == isMuted {
+		return
+	}
+
+	f.isMuted = isMuted
+	if isMuted && !f.pausedAt.IsZero() {
+		// mute ends the pause, anything after that is intended by the user
+		f.stats.Paused.Count++
+		f.stats.Paused.Duration += at.Sub(f.pausedAt)
+		f.pausedAt = time.Time{}
+		f.isResumed = false
+	}
+	f.keyFrameRequestedAt = time.Time{}
+	f.keyFrameWaitFrom = time.Time{}
+	f.lastFrameAt = time.Time{}
+}
+
+func (f *freezeDetector) GetStats() FreezeStats {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.stats
+}
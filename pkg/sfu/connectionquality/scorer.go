@@ -38,6 +38,8 @@ const (
 
 	cDistanceWeight = float64(35.0) // each spatial layer missed drops a quality level
 
+	cFreezeWeight = float64(2.0) // video frozen for a tenth of the window drops a quality level
+
 	cUnmuteTimeThreshold = float64(0.5)
 
 	cPPSQuantization         = float64(2)
@@ -67,6 +69,7 @@ type windowStat struct {
 	rttMax            uint32
 	jitterMax         float64
 	lastRTCPAt        time.Time
+	frozen            time.Duration
 }
 
 func (w *windowStat) calculatePacketScore(aplw float64, includeRTT bool, includeJitter bool) float64 {
@@ -152,8 +155,17 @@ func (w *windowStat) calculateBitrateScore(expectedBits int64, isEnabled bool) f
 	return score
 }
 
+func (w *windowStat) calculateFreezeScore() float64 {
+	if w.frozen <= 0 || w.duration <= 0 {
+		return cMaxScore
+	}
+
+	frozenRatio := math.Min(1.0, float64(w.frozen)/float64(w.duration))
+	return math.Max(0.0, cMaxScore-frozenRatio*100.0*cFreezeWeight)
+}
+
 func (w *windowStat) String() string {
-	return fmt.Sprintf("start: %+v, dur: %+v, p: %d, pp: %d, pl: %d, pm: %d, pooo: %d, b: %d, rtt: %d, jitter: %0.2f, lastRTCP: %+v",
+	return fmt.Sprintf("start: %+v, dur: %+v, p: %d, pp: %d, pl: %d, pm: %d, pooo: %d, b: %d, rtt: %d, jitter: %0.2f, lastRTCP: %+v, frozen: %+v",
 		w.startedAt,
 		w.duration,
 		w.packets,
@@ -165,6 +177,7 @@ func (w *windowStat) String() string {
 		w.rttMax,
 		w.jitterMax,
 		w.lastRTCPAt,
+		w.frozen,
 	)
 }
 
@@ -184,6 +197,7 @@ func (w *windowStat) MarshalLogObject(e zapcore.ObjectEncoder) error {
 	e.AddUint32("rttMax", w.rttMax)
 	e.AddFloat64("jitterMax", w.jitterMax)
 	e.AddTime("lastRTCPAt", w.lastRTCPAt)
+	e.AddString("frozen", w.frozen.String())
 	return nil
 }
 
@@ -223,6 +237,11 @@ type qualityScorer struct {
 
 	aggregateBitrate *utils.TimedAggregator[int64]
 	layerDistance    *utils.TimedAggregator[float64]
+
+	// when set, packet score is the E-model rating factor
+	eModel *eModel
+	// rating factor of the last window, before it is combined with other scores and smoothed
+	rFactor float64
 }
 
 func newQualityScorer(params qualityScorerParams) *qualityScorer {
@@ -265,6 +284,21 @@ func (q *qualityScorer) UpdatePacketLossWeight(packetLossWeight float64) {
 	q.packetLossWeight = packetLossWeight
 }
 
+func (q *qualityScorer) SetEModel(e *eModel) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.eModel = e
+	q.rFactor = cEModelDefaultR
+}
+
+func (q *qualityScorer) IsEModel() bool {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	return q.eModel != nil
+}
+
 func (q *qualityScorer) updateMuteAtLocked(isMuted bool, at time.Time) {
 	if isMuted {
 		q.mutedAt = at
@@ -412,7 +446,7 @@ func (q *qualityScorer) updateAtLocked(stat *windowStat, at time.Time) {
 
 	aplw := q.getAdjustedPacketLossWeight(stat)
 	reason := "none"
-	var score, packetScore, bitrateScore, layerScore float64
+	var score, packetScore, bitrateScore, layerScore, freezeScore float64
 	if stat.packets+stat.packetsPadding == 0 {
 		if !stat.lastRTCPAt.IsZero() && at.Sub(stat.lastRTCPAt) > stat.duration {
 			reason = "rtcp"
@@ -421,13 +455,27 @@ func (q *qualityScorer) updateAtLocked(stat *windowStat, at time.Time) {
 			reason = "dry"
 			score = qualityTransitionScore[livekit.ConnectionQuality_POOR]
 		}
+		if q.eModel != nil {
+			q.rFactor = score
+		}
 	} else {
-		packetScore = stat.calculatePacketScore(aplw, q.params.IncludeRTT, q.params.IncludeJitter)
+		if q.eModel != nil {
+			dtxFactor := 1.0
+			if q.packetLossWeight > 0 {
+				dtxFactor = aplw / q.packetLossWeight
+			}
+			packetScore = q.eModel.calculateRFactor(stat, dtxFactor)
+			q.rFactor = packetScore
+		} else {
+			packetScore = stat.calculatePacketScore(aplw, q.params.IncludeRTT, q.params.IncludeJitter)
+		}
 		bitrateScore = stat.calculateBitrateScore(expectedBits, q.params.EnableBitrateScore)
 		layerScore = math.Max(math.Min(cMaxScore, cMaxScore-(expectedDistance*cDistanceWeight)), 0.0)
+		freezeScore = stat.calculateFreezeScore()
 
 		minScore := math.Min(packetScore, bitrateScore)
 		minScore = math.Min(minScore, layerScore)
+		minScore = math.Min(minScore, freezeScore)
 
 		switch {
 		case packetScore == minScore:
@@ -441,6 +489,10 @@ func (q *qualityScorer) updateAtLocked(stat *windowStat, at time.Time) {
 		case layerScore == minScore:
 			reason = "layer"
 			score = layerScore
+
+		case freezeScore == minScore:
+			reason = "freeze"
+			score = freezeScore
 		}
 
 		factor := cIncreaseFactor
@@ -467,6 +519,7 @@ func (q *qualityScorer) updateAtLocked(stat *windowStat, at time.Time) {
 		"packetScore", packetScore,
 		"layerScore", layerScore,
 		"bitrateScore", bitrateScore,
+		"freezeScore", freezeScore,
 		"quality", currCQ,
 		"stat", stat,
 		"packetLossWeight", q.packetLossWeight,
@@ -601,6 +654,15 @@ func (q *qualityScorer) GetMOSAndQuality() (float32, livekit.ConnectionQuality)
 	return scoreToMOS(q.score), scoreToConnectionQuality(q.score)
 }
 
+// GetRFactorAndMOS returns the E-model transmission rating factor (R) of the last window and the MOS derived
+// from it. Unlike the score, R is not smoothed nor lowered by bitrate, layer or freeze scores.
+func (q *qualityScorer) GetRFactorAndMOS() (float32, float32) {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+
+	return float32(q.rFactor), scoreToMOS(q.rFactor)
+}
+
 // ------------------------------------------
 
 func scoreToConnectionQuality(score float64) livekit.ConnectionQuality {
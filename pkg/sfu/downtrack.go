@@ -38,6 +38,7 @@ import (
 	"github.com/livekit/protocol/utils/mono"
 
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/capture"
 	"github.com/livekit/livekit-server/pkg/sfu/ccutils"
 	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
@@ -240,6 +241,7 @@ type DowntrackParams struct {
 	RTCPWriter                     func([]rtcp.Packet) error
 	DisableSenderReportPassThrough bool
 	SupportsCodecChange            bool
+	AudioScorer                    connectionquality.AudioScorer
 }
 
 // DownTrack implements TrackLocal, is the track used to write packets
@@ -406,6 +408,7 @@ func NewDownTrack(params DowntrackParams) (*DownTrack, error) {
 	)
 
 	d.connectionStats = connectionquality.NewConnectionStats(connectionquality.ConnectionStatsParams{
+		AudioScorer:    d.params.AudioScorer,
 		SenderProvider: d,
 		Logger:         d.params.Logger.WithValues("direction", "down"),
 	})
@@ -1055,6 +1058,10 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		)
 	}
 
+	if c := d.getCapture(); c != nil {
+		c.WriteRTPHeaderAndPayload(capture.DirectionEgress, hdr, payload)
+	}
+
 	headerSize := hdr.MarshalSize()
 	d.rtpStats.Update(
 		extPkt.Arrival,
@@ -1081,6 +1088,7 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 	if extPkt.KeyFrame {
 		d.isNACKThrottled.Store(false)
 		d.rtpStats.UpdateKeyFrame(1)
+		d.connectionStats.AddKeyFrame()
 		d.params.Logger.Debugw(
 			"forwarded key frame",
 			"layer", layer,
@@ -1088,6 +1096,9 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 			"rtpts", tp.rtp.extTimestamp,
 		)
 	}
+	if hdr.Marker && d.kind == webrtc.RTPCodecTypeVideo {
+		d.connectionStats.AddFrame()
+	}
 
 	if tp.isSwitching {
 		d.postMaxLayerNotifierEvent("switching")
@@ -1681,11 +1692,25 @@ func (d *DownTrack) CreateSenderReport() *rtcp.SenderReport {
 	}
 
 	_, tsOffset, refSenderReport := d.forwarder.GetSenderReportParams()
-	return d.rtpStats.GetRtcpSenderReport(d.ssrc, refSenderReport, tsOffset, !d.params.DisableSenderReportPassThrough)
+	sr := d.rtpStats.GetRtcpSenderReport(d.ssrc, refSenderReport, tsOffset, !d.params.DisableSenderReportPassThrough)
+	if c := d.getCapture(); c != nil && sr != nil {
+		if b, err := sr.Marshal(); err == nil {
+			c.WriteRTCP(capture.DirectionEgress, b)
+		}
+	}
+	return sr
 
 	// not sending RTCP Sender Report for RTX
 }
 
+// getCapture returns the active packet capture of the subscriber, nil when not capturing
+func (d *DownTrack) getCapture() *capture.Capture {
+	if d.params.BufferFactory == nil {
+		return nil
+	}
+	return d.params.BufferFactory.CaptureTap().Load()
+}
+
 func (d *DownTrack) writeBlankFrameRTP(duration float32, generation uint32) chan struct{} {
 	done := make(chan struct{})
 	go func() {
@@ -1893,12 +1918,14 @@ func (d *DownTrack) handleRTCP(bytes []byte) {
 		case *rtcp.PictureLossIndication:
 			if p.MediaSSRC == d.ssrc {
 				numPLIs++
+				d.connectionStats.AddKeyFrameRequest()
 				sendPliOnce()
 			}
 
 		case *rtcp.FullIntraRequest:
 			if p.MediaSSRC == d.ssrc {
 				numFIRs++
+				d.connectionStats.AddKeyFrameRequest()
 				sendPliOnce()
 			}
 
@@ -2119,6 +2146,10 @@ func (d *DownTrack) retransmitPacket(epm *extPacketMeta, sourcePkt []byte, isPro
 		payload = payload[:rtxOffset+int(epm.numCodecBytesOut)+len(pkt.Payload)-int(epm.numCodecBytesIn)]
 	}
 
+	if c := d.getCapture(); c != nil {
+		c.WriteRTPHeaderAndPayload(capture.DirectionEgress, hdr, payload)
+	}
+
 	headerSize := hdr.MarshalSize()
 	var (
 		payloadSize, paddingSize int
@@ -2366,6 +2397,11 @@ func (d *DownTrack) GetConnectionScoreAndQuality() (float32, livekit.ConnectionQ
 	return d.connectionStats.GetScoreAndQuality()
 }
 
+// GetDeltaFreezeStats returns the video freezes seen by the subscriber since the previous call, nil if none
+func (d *DownTrack) GetDeltaFreezeStats() *connectionquality.FreezeStats {
+	return d.connectionStats.GetDeltaFreezeStats()
+}
+
 func (d *DownTrack) GetTrackStats() *livekit.RTPStats {
 	return rtpstats.ReconcileRTPStatsWithRTX(d.rtpStats.ToProto(), d.rtpStatsRTX.ToProto())
 }
@@ -71,6 +71,8 @@ type AudioConfig struct {
 	ActiveREDEncoding bool `yaml:"active_red_encoding,omitempty"`
 	// enable proxying weakest subscriber loss to publisher in RTCP Receiver Report
 	EnableLossProxying bool `yaml:"enable_loss_proxying,omitempty"`
+	// model used to score audio connection quality, "emodel" for ITU-T G.107 E-model, heuristic scoring when empty
+	QualityScorer connectionquality.AudioScorer `yaml:"quality_scorer,omitempty"`
 }
 
 var (
@@ -165,7 +167,7 @@ type WebRTCReceiver struct {
 	trackID            livekit.TrackID
 	streamID           string
 	kind               webrtc.RTPCodecType
-	receiver           *webrtc.RTPReceiver
+	receiver           rtpParametersProvider
 	codec              webrtc.RTPCodecParameters
 	codecState         ReceiverCodecState
 	codecStateLock     sync.Mutex
@@ -201,6 +203,12 @@ type WebRTCReceiver struct {
 	forwardStats *ForwardStats
 }
 
+// rtpParametersProvider is satisfied by *webrtc.RTPReceiver, relayed tracks provide the parameters
+// negotiated on the origin node instead
+type rtpParametersProvider interface {
+	GetParameters() webrtc.RTPParameters
+}
+
 type ReceiverOpts func(w *WebRTCReceiver) *WebRTCReceiver
 
 // WithPliThrottleConfig indicates minimum time(ms) between sending PLIs
@@ -255,6 +263,18 @@ func NewWebRTCReceiver(
 	onRTCP func([]rtcp.Packet),
 	streamTrackerManagerConfig StreamTrackerManagerConfig,
 	opts ...ReceiverOpts,
+) *WebRTCReceiver {
+	return newWebRTCReceiver(receiver, track, trackInfo, logger, onRTCP, streamTrackerManagerConfig, opts...)
+}
+
+func newWebRTCReceiver(
+	receiver rtpParametersProvider,
+	track TrackRemote,
+	trackInfo *livekit.TrackInfo,
+	logger logger.Logger,
+	onRTCP func([]rtcp.Packet),
+	streamTrackerManagerConfig StreamTrackerManagerConfig,
+	opts ...ReceiverOpts,
 ) *WebRTCReceiver {
 	w := &WebRTCReceiver{
 		logger:     logger,
@@ -280,6 +300,7 @@ func NewWebRTCReceiver(
 	})
 
 	w.connectionStats = connectionquality.NewConnectionStats(connectionquality.ConnectionStatsParams{
+		AudioScorer:      w.audioConfig.QualityScorer,
 		ReceiverProvider: w,
 		Logger:           w.logger.WithValues("direction", "up"),
 	})
@@ -0,0 +1,451 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/mediatransportutil/pkg/bucket"
+	"github.com/livekit/mediatransportutil/pkg/nack"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+// Media of relayed tracks is exchanged between nodes in UDP datagrams, one RTP packet per datagram as
+// received from the publisher. Every datagram is laid out as
+//
+//	type (1 byte) | session ID (8 bytes) | codec index (1 byte) | layer (1 byte) | payload | tag (10 bytes)
+//
+// the session ID is assigned by the node relaying the track when the relay is set up over psrpc. The tag is
+// a truncated HMAC-SHA256 of the rest of the datagram under the key shared by the nodes of the cluster,
+// datagrams without a valid tag are dropped.
+//
+// RTP packets lost on the way are requested again with NACKs, sent back to the address packets come from,
+// and retransmitted from a short history kept by the sending node.
+
+type PacketType byte
+
+const (
+	PacketTypeRTP PacketType = iota + 1
+	PacketTypeSenderReport
+	PacketTypeKeyFrameRequest
+	PacketTypeNack
+)
+
+const (
+	headerSize       = 11
+	tagSize          = 10
+	senderReportSize = 24
+	nackPairSize     = 4
+
+	// packets kept per stream for retransmission
+	historySize = 256
+	// gaps larger than this are a restart of the stream rather than losses
+	maxLossGap   = 100
+	nackInterval = 20 * time.Millisecond
+)
+
+var (
+	ErrTransportClosed = errors.New("relay transport closed")
+	ErrInvalidPacket   = errors.New("invalid relay packet")
+	ErrInvalidTag      = errors.New("invalid relay packet tag")
+	ErrKeyNotSet       = errors.New("relay key not set")
+)
+
+// Handler receives the packets of a relay session
+type Handler interface {
+	HandleRTP(codec int, layer int32, pkt []byte)
+	HandleSenderReport(codec int, layer int32, sr *livekit.RTCPSenderReportState)
+	HandleKeyFrameRequest(codec int, layer int32)
+}
+
+type Transport struct {
+	conn   *net.UDPConn
+	key    []byte
+	logger logger.Logger
+	closed atomic.Bool
+
+	lock     sync.RWMutex
+	sessions map[uint64]*session
+}
+
+func NewTransport(bindAddress string, port uint32, key []byte, logger logger.Logger) (*Transport, error) {
+	if len(key) == 0 {
+		return nil, ErrKeyNotSet
+	}
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(bindAddress), Port: int(port)})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Transport{
+		conn:     conn,
+		key:      key,
+		logger:   logger,
+		sessions: make(map[uint64]*session),
+	}
+	go t.readWorker()
+	go t.nackWorker()
+	return t, nil
+}
+
+func (t *Transport) LocalAddr() *net.UDPAddr {
+	return t.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Port is advertised along with the node IP to the nodes sending to this one
+func (t *Transport) Port() uint32 {
+	return uint32(t.LocalAddr().Port)
+}
+
+// NewSession registers the handler under a new random session ID
+func (t *Transport) NewSession(h Handler) uint64 {
+	var b [8]byte
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for {
+		_, _ = rand.Read(b[:])
+		id := binary.BigEndian.Uint64(b[:])
+		if id != 0 && t.sessions[id] == nil {
+			t.sessions[id] = newSession(h)
+			return id
+		}
+	}
+}
+
+// SetSession registers the handler of a session set up by the other side, i. e. for key frame requests
+// coming back to the sending node
+func (t *Transport) SetSession(id uint64, h Handler) {
+	t.lock.Lock()
+	t.sessions[id] = newSession(h)
+	t.lock.Unlock()
+}
+
+func (t *Transport) RemoveSession(id uint64) {
+	t.lock.Lock()
+	delete(t.sessions, id)
+	t.lock.Unlock()
+}
+
+func (t *Transport) WriteRTP(addr *net.UDPAddr, session uint64, codec int, layer int32, pkt []byte) error {
+	if s := t.getSession(session); s != nil {
+		s.stream(codec, layer).store(pkt)
+	}
+	return t.write(addr, PacketTypeRTP, session, codec, layer, pkt)
+}
+
+func (t *Transport) WriteSenderReport(addr *net.UDPAddr, session uint64, codec int, layer int32, sr *livekit.RTCPSenderReportState) error {
+	var b [senderReportSize]byte
+	binary.BigEndian.PutUint32(b[0:4], sr.RtpTimestamp)
+	binary.BigEndian.PutUint64(b[4:12], sr.NtpTimestamp)
+	binary.BigEndian.PutUint32(b[12:16], sr.Packets)
+	binary.BigEndian.PutUint64(b[16:24], sr.Octets)
+	return t.write(addr, PacketTypeSenderReport, session, codec, layer, b[:])
+}
+
+func (t *Transport) WriteKeyFrameRequest(addr *net.UDPAddr, session uint64, codec int, layer int32) error {
+	return t.write(addr, PacketTypeKeyFrameRequest, session, codec, layer, nil)
+}
+
+func (t *Transport) write(addr *net.UDPAddr, typ PacketType, session uint64, codec int, layer int32, payload []byte) error {
+	if t.closed.Load() {
+		return ErrTransportClosed
+	}
+
+	b := make([]byte, headerSize+len(payload), headerSize+len(payload)+tagSize)
+	b[0] = byte(typ)
+	binary.BigEndian.PutUint64(b[1:9], session)
+	b[9] = byte(codec)
+	b[10] = byte(layer)
+	copy(b[headerSize:], payload)
+	b = append(b, t.tag(b)...)
+	_, err := t.conn.WriteToUDP(b, addr)
+	return err
+}
+
+func (t *Transport) tag(b []byte) []byte {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write(b)
+	return mac.Sum(nil)[:tagSize]
+}
+
+func (t *Transport) getSession(id uint64) *session {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.sessions[id]
+}
+
+func (t *Transport) Close() {
+	if t.closed.Swap(true) {
+		return
+	}
+	_ = t.conn.Close()
+}
+
+func (t *Transport) readWorker() {
+	b := make([]byte, bucket.MaxPktSize+headerSize+tagSize)
+	for {
+		n, addr, err := t.conn.ReadFromUDP(b)
+		if err != nil {
+			if !t.closed.Load() {
+				t.logger.Warnw("relay transport read failed", err)
+			}
+			return
+		}
+		if err := t.handle(addr, b[:n]); err != nil {
+			t.logger.Debugw("dropping relay packet", "error", err)
+		}
+	}
+}
+
+func (t *Transport) handle(addr *net.UDPAddr, b []byte) error {
+	if len(b) < headerSize+tagSize {
+		return ErrInvalidPacket
+	}
+	b, tag := b[:len(b)-tagSize], b[len(b)-tagSize:]
+	if !hmac.Equal(tag, t.tag(b)) {
+		return ErrInvalidTag
+	}
+
+	s := t.getSession(binary.BigEndian.Uint64(b[1:9]))
+	if s == nil {
+		return nil
+	}
+
+	codec := int(b[9])
+	layer := int32(b[10])
+	payload := b[headerSize:]
+	switch PacketType(b[0]) {
+	case PacketTypeRTP:
+		s.stream(codec, layer).received(addr, payload)
+		s.handler.HandleRTP(codec, layer, payload)
+
+	case PacketTypeSenderReport:
+		if len(payload) < senderReportSize {
+			return ErrInvalidPacket
+		}
+		s.handler.HandleSenderReport(codec, layer, &livekit.RTCPSenderReportState{
+			RtpTimestamp: binary.BigEndian.Uint32(payload[0:4]),
+			NtpTimestamp: binary.BigEndian.Uint64(payload[4:12]),
+			Packets:      binary.BigEndian.Uint32(payload[12:16]),
+			Octets:       binary.BigEndian.Uint64(payload[16:24]),
+		})
+
+	case PacketTypeKeyFrameRequest:
+		s.handler.HandleKeyFrameRequest(codec, layer)
+
+	case PacketTypeNack:
+		if len(payload)%nackPairSize != 0 {
+			return ErrInvalidPacket
+		}
+		st := s.stream(codec, layer)
+		for i := 0; i < len(payload); i += nackPairSize {
+			np := rtcp.NackPair{
+				PacketID:    binary.BigEndian.Uint16(payload[i : i+2]),
+				LostPackets: rtcp.PacketBitmap(binary.BigEndian.Uint16(payload[i+2 : i+4])),
+			}
+			for _, sn := range np.PacketList() {
+				if pkt := st.load(sn); pkt != nil {
+					_ = t.write(addr, PacketTypeRTP, binary.BigEndian.Uint64(b[1:9]), codec, layer, pkt)
+				}
+			}
+		}
+
+	default:
+		return ErrInvalidPacket
+	}
+	return nil
+}
+
+// nackWorker requests lost packets again, until they arrive or the NACK queue gives up on them
+func (t *Transport) nackWorker() {
+	ticker := time.NewTicker(nackInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if t.closed.Load() {
+			return
+		}
+
+		t.lock.RLock()
+		sessions := make(map[uint64]*session, len(t.sessions))
+		for id, s := range t.sessions {
+			sessions[id] = s
+		}
+		t.lock.RUnlock()
+
+		for id, s := range sessions {
+			for key, st := range s.getStreams() {
+				addr, nacks := st.nacks()
+				if len(nacks) == 0 {
+					continue
+				}
+				payload := make([]byte, 0, len(nacks)*nackPairSize)
+				for _, np := range nacks {
+					payload = binary.BigEndian.AppendUint16(payload, np.PacketID)
+					payload = binary.BigEndian.AppendUint16(payload, uint16(np.LostPackets))
+				}
+				if err := t.write(addr, PacketTypeNack, id, key.codec, key.layer, payload); err != nil {
+					t.logger.Debugw("could not send relay nack", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// ---------------------------------------------------------
+
+type streamKey struct {
+	codec int
+	layer int32
+}
+
+type session struct {
+	handler Handler
+
+	lock    sync.Mutex
+	streams map[streamKey]*stream
+}
+
+func newSession(h Handler) *session {
+	return &session{
+		handler: h,
+		streams: make(map[streamKey]*stream),
+	}
+}
+
+func (s *session) stream(codec int, layer int32) *stream {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := streamKey{codec: codec, layer: layer}
+	st := s.streams[key]
+	if st == nil {
+		st = &stream{}
+		s.streams[key] = st
+	}
+	return st
+}
+
+func (s *session) getStreams() map[streamKey]*stream {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	streams := make(map[streamKey]*stream, len(s.streams))
+	for key, st := range s.streams {
+		streams[key] = st
+	}
+	return streams
+}
+
+// stream keeps the packets sent for retransmission, and tracks the losses of the packets received
+type stream struct {
+	lock sync.Mutex
+
+	// sent
+	history [historySize][]byte
+
+	// received
+	source    *net.UDPAddr
+	started   bool
+	highestSN uint16
+	nacker    *nack.NackQueue
+}
+
+func rtpSequenceNumber(pkt []byte) (uint16, bool) {
+	// version 2 header
+	if len(pkt) < 12 || pkt[0]>>6 != 2 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint16(pkt[2:4]), true
+}
+
+func (st *stream) store(pkt []byte) {
+	sn, ok := rtpSequenceNumber(pkt)
+	if !ok {
+		return
+	}
+
+	st.lock.Lock()
+	st.history[sn%historySize] = append(st.history[sn%historySize][:0], pkt...)
+	st.lock.Unlock()
+}
+
+func (st *stream) load(sn uint16) []byte {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	pkt := st.history[sn%historySize]
+	if stored, ok := rtpSequenceNumber(pkt); !ok || stored != sn {
+		return nil
+	}
+	return append([]byte(nil), pkt...)
+}
+
+func (st *stream) received(addr *net.UDPAddr, pkt []byte) {
+	sn, ok := rtpSequenceNumber(pkt)
+	if !ok {
+		return
+	}
+
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	st.source = addr
+	if st.nacker == nil {
+		st.nacker = nack.NewNACKQueue(nack.NackQueueParamsDefault)
+	}
+	if !st.started {
+		st.started = true
+		st.highestSN = sn
+		return
+	}
+
+	diff := sn - st.highestSN
+	switch {
+	case diff == 0:
+	case diff < 0x8000:
+		if diff <= maxLossGap {
+			for lost := st.highestSN + 1; lost != sn; lost++ {
+				st.nacker.Push(lost)
+			}
+		}
+		st.highestSN = sn
+	default:
+		// late or retransmitted
+		st.nacker.Remove(sn)
+	}
+}
+
+func (st *stream) nacks() (*net.UDPAddr, []rtcp.NackPair) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	if st.nacker == nil {
+		return nil, nil
+	}
+	nacks, _ := st.nacker.Pairs()
+	return st.source, nacks
+}
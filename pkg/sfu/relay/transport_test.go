@@ -0,0 +1,216 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package relay
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+type testPacket struct {
+	typ     PacketType
+	codec   int
+	layer   int32
+	payload []byte
+	sr      *livekit.RTCPSenderReportState
+}
+
+type testHandler struct {
+	packets chan testPacket
+}
+
+func newTestHandler() *testHandler {
+	return &testHandler{packets: make(chan testPacket, 10)}
+}
+
+func (h *testHandler) HandleRTP(codec int, layer int32, pkt []byte) {
+	h.packets <- testPacket{typ: PacketTypeRTP, codec: codec, layer: layer, payload: append([]byte{}, pkt...)}
+}
+
+func (h *testHandler) HandleSenderReport(codec int, layer int32, sr *livekit.RTCPSenderReportState) {
+	h.packets <- testPacket{typ: PacketTypeSenderReport, codec: codec, layer: layer, sr: sr}
+}
+
+func (h *testHandler) HandleKeyFrameRequest(codec int, layer int32) {
+	h.packets <- testPacket{typ: PacketTypeKeyFrameRequest, codec: codec, layer: layer}
+}
+
+func (h *testHandler) next(t *testing.T) testPacket {
+	select {
+	case p := <-h.packets:
+		return p
+	case <-time.After(time.Second):
+		t.Fatal("no packet received")
+		return testPacket{}
+	}
+}
+
+func newTestTransport(t *testing.T) *Transport {
+	return newTestTransportWithKey(t, []byte("secret"))
+}
+
+func newTestTransportWithKey(t *testing.T, key []byte) *Transport {
+	tr, err := NewTransport("127.0.0.1", 0, key, logger.GetLogger())
+	require.NoError(t, err)
+	t.Cleanup(tr.Close)
+	return tr
+}
+
+func TestTransport(t *testing.T) {
+	sender := newTestTransport(t)
+	receiver := newTestTransport(t)
+
+	h := newTestHandler()
+	session := receiver.NewSession(h)
+	require.NotZero(t, session)
+
+	t.Run("rtp", func(t *testing.T) {
+		require.NoError(t, sender.WriteRTP(receiver.LocalAddr(), session, 1, 2, []byte{1, 2, 3}))
+		p := h.next(t)
+		require.Equal(t, PacketTypeRTP, p.typ)
+		require.Equal(t, 1, p.codec)
+		require.Equal(t, int32(2), p.layer)
+		require.Equal(t, []byte{1, 2, 3}, p.payload)
+	})
+
+	t.Run("sender report", func(t *testing.T) {
+		sr := &livekit.RTCPSenderReportState{
+			RtpTimestamp: 1234,
+			NtpTimestamp: 5678,
+			Packets:      10,
+			Octets:       1000,
+		}
+		require.NoError(t, sender.WriteSenderReport(receiver.LocalAddr(), session, 0, 1, sr))
+		p := h.next(t)
+		require.Equal(t, PacketTypeSenderReport, p.typ)
+		require.Equal(t, sr.RtpTimestamp, p.sr.RtpTimestamp)
+		require.Equal(t, sr.NtpTimestamp, p.sr.NtpTimestamp)
+		require.Equal(t, sr.Packets, p.sr.Packets)
+		require.Equal(t, sr.Octets, p.sr.Octets)
+	})
+
+	t.Run("key frame request", func(t *testing.T) {
+		// sent back to the node relaying the track, under the session ID set up by the receiving node
+		sh := newTestHandler()
+		sender.SetSession(session, sh)
+		require.NoError(t, receiver.WriteKeyFrameRequest(sender.LocalAddr(), session, 0, 2))
+		p := sh.next(t)
+		require.Equal(t, PacketTypeKeyFrameRequest, p.typ)
+		require.Equal(t, int32(2), p.layer)
+	})
+
+	t.Run("removed session", func(t *testing.T) {
+		receiver.RemoveSession(session)
+		require.NoError(t, sender.WriteRTP(receiver.LocalAddr(), session, 0, 0, []byte{1}))
+		select {
+		case <-h.packets:
+			t.Fatal("packet of removed session handled")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		sender.Close()
+		require.ErrorIs(t, sender.WriteRTP(receiver.LocalAddr(), session, 0, 0, []byte{1}), ErrTransportClosed)
+	})
+}
+
+func (h *testHandler) none(t *testing.T) {
+	select {
+	case p := <-h.packets:
+		t.Fatalf("unexpected packet %+v", p)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func testRTPPacket(t *testing.T, sn uint16) []byte {
+	pkt, err := (&rtp.Packet{
+		Header:  rtp.Header{Version: 2, SequenceNumber: sn, SSRC: 1234},
+		Payload: []byte{byte(sn)},
+	}).Marshal()
+	require.NoError(t, err)
+	return pkt
+}
+
+func TestTransportAuthentication(t *testing.T) {
+	receiver := newTestTransport(t)
+	h := newTestHandler()
+	session := receiver.NewSession(h)
+
+	t.Run("key required", func(t *testing.T) {
+		_, err := NewTransport("127.0.0.1", 0, nil, logger.GetLogger())
+		require.ErrorIs(t, err, ErrKeyNotSet)
+	})
+
+	t.Run("packets of another key are dropped", func(t *testing.T) {
+		other := newTestTransportWithKey(t, []byte("other"))
+		require.NoError(t, other.WriteRTP(receiver.LocalAddr(), session, 0, 0, []byte{1}))
+		h.none(t)
+	})
+
+	t.Run("tampered packets are dropped", func(t *testing.T) {
+		conn, err := net.DialUDP("udp", nil, receiver.LocalAddr())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		b := []byte{byte(PacketTypeRTP), 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+		binary.BigEndian.PutUint64(b[1:9], session)
+		b = append(b, receiver.tag(b)...)
+		// flips the layer
+		b[10] = 1
+		_, err = conn.Write(b)
+		require.NoError(t, err)
+		h.none(t)
+	})
+}
+
+func TestTransportRetransmission(t *testing.T) {
+	sender := newTestTransport(t)
+	receiver := newTestTransport(t)
+
+	h := newTestHandler()
+	session := receiver.NewSession(h)
+	// the relaying node keeps the packets it sends under the session
+	sender.SetSession(session, newTestHandler())
+
+	// nothing listens there, the packet is lost
+	lost, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	require.NoError(t, err)
+	lostAddr := lost.LocalAddr().(*net.UDPAddr)
+	require.NoError(t, lost.Close())
+
+	require.NoError(t, sender.WriteRTP(receiver.LocalAddr(), session, 0, 1, testRTPPacket(t, 10)))
+	require.NoError(t, sender.WriteRTP(lostAddr, session, 0, 1, testRTPPacket(t, 11)))
+	require.NoError(t, sender.WriteRTP(receiver.LocalAddr(), session, 0, 1, testRTPPacket(t, 12)))
+
+	for _, expected := range []uint16{10, 12, 11} {
+		p := h.next(t)
+		require.Equal(t, PacketTypeRTP, p.typ)
+		require.Equal(t, int32(1), p.layer)
+		sn, ok := rtpSequenceNumber(p.payload)
+		require.True(t, ok)
+		require.Equal(t, expected, sn)
+	}
+	// not requested again once received
+	h.none(t)
+}
@@ -0,0 +1,208 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v4"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+)
+
+var _ TrackReceiver = (*RelayReceiver)(nil)
+
+type RelayReceiverParams struct {
+	TrackInfo                  *livekit.TrackInfo
+	StreamID                   string
+	Codec                      webrtc.RTPCodecParameters
+	HeaderExtensions           []webrtc.RTPHeaderExtensionParameter
+	PacketBufferSizeVideo      int
+	PacketBufferSizeAudio      int
+	StreamTrackerManagerConfig StreamTrackerManagerConfig
+	Logger                     logger.Logger
+	// OnKeyFrameRequest passes key frame requests of subscribers on to the origin node
+	OnKeyFrameRequest func(layer int32)
+}
+
+// RelayReceiver receives a track published on another node. The origin node forwards the RTP packets
+// of each layer as received from the publisher, they are written into a buffer per layer the same way
+// up tracks feed a WebRTCReceiver, so down tracks on this node are set up as for a local publisher.
+type RelayReceiver struct {
+	*WebRTCReceiver
+
+	params RelayReceiverParams
+
+	lock    sync.Mutex
+	buffers map[int32]*buffer.Buffer
+	layers  map[uint32]int32 // ssrc -> layer
+	closed  bool
+}
+
+func NewRelayReceiver(params RelayReceiverParams, opts ...ReceiverOpts) *RelayReceiver {
+	r := &RelayReceiver{
+		params:  params,
+		buffers: make(map[int32]*buffer.Buffer),
+		layers:  make(map[uint32]int32),
+	}
+	r.WebRTCReceiver = newWebRTCReceiver(
+		r,
+		r.newTrackRemote(0, 0),
+		params.TrackInfo,
+		params.Logger,
+		r.onRTCP,
+		params.StreamTrackerManagerConfig,
+		opts...,
+	)
+	return r
+}
+
+// GetParameters returns the parameters negotiated with the publisher on the origin node
+func (r *RelayReceiver) GetParameters() webrtc.RTPParameters {
+	return webrtc.RTPParameters{
+		HeaderExtensions: r.params.HeaderExtensions,
+		Codecs:           []webrtc.RTPCodecParameters{r.params.Codec},
+	}
+}
+
+// WriteRTP feeds a packet of the layer, the buffer of a layer is set up by its first packet
+func (r *RelayReceiver) WriteRTP(layer int32, pkt []byte) {
+	if buff := r.getOrCreateBuffer(layer, pkt); buff != nil {
+		_, _ = buff.Write(pkt)
+	}
+}
+
+// SetSenderReport applies a sender report of the publisher, used for synchronisation by down tracks
+func (r *RelayReceiver) SetSenderReport(layer int32, sr *livekit.RTCPSenderReportState) {
+	r.lock.Lock()
+	buff := r.buffers[layer]
+	r.lock.Unlock()
+
+	if buff != nil {
+		buff.SetSenderReportData(sr.RtpTimestamp, sr.NtpTimestamp, sr.Packets, uint32(sr.Octets))
+	}
+}
+
+// Close closes the buffers, the receiver and its down tracks close once forwarding stops
+func (r *RelayReceiver) Close() {
+	r.lock.Lock()
+	r.closed = true
+	buffers := r.buffers
+	r.buffers = make(map[int32]*buffer.Buffer)
+	r.lock.Unlock()
+
+	for _, buff := range buffers {
+		_ = buff.Close()
+	}
+}
+
+func (r *RelayReceiver) getOrCreateBuffer(layer int32, pkt []byte) *buffer.Buffer {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if buff := r.buffers[layer]; buff != nil {
+		return buff
+	}
+	if r.closed || len(pkt) < 12 {
+		return nil
+	}
+
+	ssrc := binary.BigEndian.Uint32(pkt[8:12])
+	buff := buffer.NewBuffer(ssrc, r.params.PacketBufferSizeVideo, r.params.PacketBufferSizeAudio)
+	if err := r.AddUpTrack(r.newTrackRemote(layer, ssrc), buff); err != nil {
+		r.params.Logger.Warnw("could not add relayed layer", err, "layer", layer, "ssrc", ssrc)
+		_ = buff.Close()
+		return nil
+	}
+
+	var bitrates int
+	if ti := r.params.TrackInfo; len(ti.Layers) > int(layer) {
+		bitrates = int(ti.Layers[layer].GetBitrate())
+	}
+	buff.Bind(r.GetParameters(), r.params.Codec.RTPCodecCapability, bitrates)
+
+	r.buffers[layer] = buff
+	r.layers[ssrc] = layer
+	return buff
+}
+
+func (r *RelayReceiver) onRTCP(packets []rtcp.Packet) {
+	if r.params.OnKeyFrameRequest == nil {
+		return
+	}
+
+	// receiver reports and NACKs are answered by the origin node for its own leg, only key frame requests are passed on
+	for _, pkt := range packets {
+		var ssrc uint32
+		switch p := pkt.(type) {
+		case *rtcp.PictureLossIndication:
+			ssrc = p.MediaSSRC
+		case *rtcp.FullIntraRequest:
+			ssrc = p.MediaSSRC
+		default:
+			continue
+		}
+
+		r.lock.Lock()
+		layer, ok := r.layers[ssrc]
+		r.lock.Unlock()
+		if ok {
+			r.params.OnKeyFrameRequest(layer)
+		}
+	}
+}
+
+func (r *RelayReceiver) newTrackRemote(layer int32, ssrc uint32) TrackRemote {
+	t := &relayTrackRemote{
+		id:       r.params.TrackInfo.Sid,
+		streamID: r.params.StreamID,
+		ssrc:     webrtc.SSRC(ssrc),
+		codec:    r.params.Codec,
+		kind:     webrtc.RTPCodecTypeAudio,
+	}
+	if mime.IsMimeTypeStringVideo(r.params.Codec.MimeType) {
+		t.kind = webrtc.RTPCodecTypeVideo
+		if !mime.IsMimeTypeStringSVC(r.params.Codec.MimeType) {
+			t.rid = buffer.SpatialLayerToRid(layer, r.params.TrackInfo)
+		}
+	}
+	return t
+}
+
+// ---------------------------------------------------------
+
+type relayTrackRemote struct {
+	id       string
+	rid      string
+	streamID string
+	ssrc     webrtc.SSRC
+	kind     webrtc.RTPCodecType
+	codec    webrtc.RTPCodecParameters
+}
+
+func (t *relayTrackRemote) ID() string                       { return t.id }
+func (t *relayTrackRemote) RID() string                      { return t.rid }
+func (t *relayTrackRemote) Msid() string                     { return t.streamID + " " + t.id }
+func (t *relayTrackRemote) SSRC() webrtc.SSRC                { return t.ssrc }
+func (t *relayTrackRemote) StreamID() string                 { return t.streamID }
+func (t *relayTrackRemote) Kind() webrtc.RTPCodecType        { return t.kind }
+func (t *relayTrackRemote) Codec() webrtc.RTPCodecParameters { return t.codec }
+func (t *relayTrackRemote) RTCTrack() *webrtc.TrackRemote    { return nil }
@@ -188,7 +188,8 @@ type StreamAllocatorParams struct {
 type StreamAllocator struct {
 	params StreamAllocatorParams
 
-	onStreamStateChange func(update *StreamStateUpdate) error
+	onStreamStateChange            func(update *StreamStateUpdate) error
+	onChannelCongestionStateChange func(fromState bwe.CongestionState, toState bwe.CongestionState, estimatedAvailableChannelCapacity int64)
 
 	sendSideBWEInterceptor cc.BandwidthEstimator
 
@@ -265,6 +266,11 @@ func (s *StreamAllocator) OnStreamStateChange(f func(update *StreamStateUpdate)
 	s.onStreamStateChange = f
 }
 
+// OnChannelCongestionStateChange is notified of congestion state changes of the channel after the allocator has seen them
+func (s *StreamAllocator) OnChannelCongestionStateChange(f func(fromState bwe.CongestionState, toState bwe.CongestionState, estimatedAvailableChannelCapacity int64)) {
+	s.onChannelCongestionStateChange = f
+}
+
 func (s *StreamAllocator) SetSendSideBWEInterceptor(sendSideBWEInterceptor cc.BandwidthEstimator) {
 	if sendSideBWEInterceptor != nil {
 		sendSideBWEInterceptor.OnTargetBitrateChange(s.onTargetBitrateChange)
@@ -819,6 +825,10 @@ func (s *StreamAllocator) handleSignalSetChannelCapacity(event Event) {
 
 func (s *StreamAllocator) handleSignalCongestionStateChange(event Event) {
 	cscd := event.Data.(congestionStateChangeData)
+	if s.onChannelCongestionStateChange != nil {
+		s.onChannelCongestionStateChange(cscd.fromState, cscd.toState, cscd.estimatedAvailableChannelCapacity)
+	}
+
 	if cscd.toState != bwe.CongestionStateNone {
 		// end/abort any running probe if channel is not clear
 		s.maybeStopProbe()
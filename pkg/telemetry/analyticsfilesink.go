@@ -0,0 +1,137 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+const analyticsFileSuffix = ".jsonl"
+
+// fileAnalyticsSink appends JSON lines to files in a directory, starting a new file by size and age
+type fileAnalyticsSink struct {
+	conf   config.AnalyticsFileConfig
+	prefix string
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func newFileAnalyticsSink(conf config.AnalyticsFileConfig, nodeID string) (*fileAnalyticsSink, error) {
+	if err := os.MkdirAll(conf.Directory, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileAnalyticsSink{
+		conf:   conf,
+		prefix: fmt.Sprintf("analytics-%s-", nodeID),
+	}, nil
+}
+
+func (s *fileAnalyticsSink) Name() string {
+	return "file"
+}
+
+func (s *fileAnalyticsSink) Write(_ context.Context, records []*AnalyticsRecord) error {
+	data, err := marshalAnalyticsRecords(records)
+	if err != nil {
+		return err
+	}
+
+	if s.file != nil && s.shouldRotate(time.Now()) {
+		if err = s.file.Close(); err != nil {
+			logger.Warnw("failed to close analytics file", err, "file", s.file.Name())
+		}
+		s.file = nil
+	}
+	if s.file == nil {
+		if err = s.open(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileAnalyticsSink) shouldRotate(now time.Time) bool {
+	if s.conf.MaxSize > 0 && s.size >= s.conf.MaxSize {
+		return true
+	}
+	return s.conf.MaxAge > 0 && now.Sub(s.openedAt) >= s.conf.MaxAge
+}
+
+func (s *fileAnalyticsSink) open() error {
+	now := time.Now()
+	// names sort in creation order, which pruning relies on
+	name := filepath.Join(s.conf.Directory, s.prefix+now.UTC().Format("20060102T150405.000000000")+analyticsFileSuffix)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	s.file = file
+	s.size = 0
+	s.openedAt = now
+	s.prune()
+	return nil
+}
+
+// prune removes the oldest files beyond MaxFiles
+func (s *fileAnalyticsSink) prune() {
+	if s.conf.MaxFiles <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(s.conf.Directory)
+	if err != nil {
+		logger.Warnw("failed to list analytics files", err)
+		return
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), s.prefix) && strings.HasSuffix(entry.Name(), analyticsFileSuffix) {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+
+	for len(names) > s.conf.MaxFiles {
+		if err = os.Remove(filepath.Join(s.conf.Directory, names[0])); err != nil {
+			logger.Warnw("failed to remove analytics file", err, "file", names[0])
+		}
+		names = names[1:]
+	}
+}
+
+func (s *fileAnalyticsSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
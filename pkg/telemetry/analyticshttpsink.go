@@ -0,0 +1,75 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+// httpAnalyticsSink posts each batch as JSON lines
+type httpAnalyticsSink struct {
+	conf   config.AnalyticsHTTPConfig
+	client *http.Client
+}
+
+func newHTTPAnalyticsSink(conf config.AnalyticsHTTPConfig) *httpAnalyticsSink {
+	return &httpAnalyticsSink{
+		conf:   conf,
+		client: &http.Client{Timeout: conf.Timeout},
+	}
+}
+
+func (s *httpAnalyticsSink) Name() string {
+	return "http"
+}
+
+func (s *httpAnalyticsSink) Write(ctx context.Context, records []*AnalyticsRecord) error {
+	data, err := marshalAnalyticsRecords(records)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.conf.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for k, v := range s.conf.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("analytics endpoint returned %s", res.Status)
+	}
+	return nil
+}
+
+func (s *httpAnalyticsSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
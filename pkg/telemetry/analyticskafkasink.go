@@ -0,0 +1,476 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+// A minimal Kafka producer speaking the wire protocol directly: Metadata v1 to find partition leaders
+// and Produce v3 with uncompressed v2 record batches, which brokers from 0.11 onwards accept.
+
+const (
+	kafkaAPIKeyProduce  = 0
+	kafkaAPIKeyMetadata = 3
+
+	kafkaProduceVersion  = 3
+	kafkaMetadataVersion = 1
+)
+
+var (
+	errKafkaNoPartitions = errors.New("kafka topic has no available partitions")
+	errKafkaMalformed    = errors.New("malformed kafka response")
+
+	kafkaCRCTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type kafkaError int16
+
+func (e kafkaError) Error() string {
+	return "kafka error code " + strconv.Itoa(int(e))
+}
+
+type kafkaPartition struct {
+	id     int32
+	leader string
+}
+
+type kafkaAnalyticsSink struct {
+	conf config.AnalyticsKafkaConfig
+	acks int16
+
+	correlationID int32
+	partitions    []kafkaPartition
+	next          int
+	conns         map[string]net.Conn
+}
+
+func newKafkaAnalyticsSink(conf config.AnalyticsKafkaConfig) (*kafkaAnalyticsSink, error) {
+	if conf.Topic == "" {
+		return nil, errors.New("analytics kafka topic is required")
+	}
+
+	var acks int16
+	switch conf.RequiredAcks {
+	case "none":
+		acks = 0
+	case "", "leader":
+		acks = 1
+	case "all":
+		acks = -1
+	default:
+		return nil, fmt.Errorf("unknown analytics kafka required_acks %q", conf.RequiredAcks)
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = config.DefaultConfig.Analytics.Kafka.Timeout
+	}
+
+	return &kafkaAnalyticsSink{
+		conf:  conf,
+		acks:  acks,
+		conns: make(map[string]net.Conn),
+	}, nil
+}
+
+func (s *kafkaAnalyticsSink) Name() string {
+	return "kafka"
+}
+
+func (s *kafkaAnalyticsSink) Write(ctx context.Context, records []*AnalyticsRecord) error {
+	batch, err := encodeKafkaRecordBatch(records)
+	if err != nil {
+		return err
+	}
+
+	// metadata is refreshed once when a broker fails or leadership moved
+	for attempt := 0; ; attempt++ {
+		err = s.produce(ctx, batch)
+		if err == nil || attempt == 1 || ctx.Err() != nil {
+			return err
+		}
+		s.reset()
+	}
+}
+
+func (s *kafkaAnalyticsSink) produce(ctx context.Context, batch []byte) error {
+	if len(s.partitions) == 0 {
+		if err := s.refreshMetadata(ctx); err != nil {
+			return err
+		}
+	}
+	partition := s.partitions[s.next%len(s.partitions)]
+	s.next++
+
+	req := &kafkaEncoder{}
+	req.nullableString(nil) // transactional id
+	req.int16(s.acks)
+	req.int32(int32(s.conf.Timeout / time.Millisecond))
+	req.int32(1)
+	req.string(s.conf.Topic)
+	req.int32(1)
+	req.int32(partition.id)
+	req.bytes(batch)
+
+	res, err := s.request(ctx, partition.leader, kafkaAPIKeyProduce, kafkaProduceVersion, req.buf.Bytes(), s.acks != 0)
+	if err != nil || res == nil {
+		return err
+	}
+
+	d := &kafkaDecoder{buf: res}
+	for i := d.int32(); i > 0 && d.err == nil; i-- {
+		d.string()
+		for j := d.int32(); j > 0 && d.err == nil; j-- {
+			d.int32() // partition
+			if code := d.int16(); code != 0 && d.err == nil {
+				return kafkaError(code)
+			}
+			d.int64() // base offset
+			d.int64() // log append time
+		}
+	}
+	return d.err
+}
+
+func (s *kafkaAnalyticsSink) refreshMetadata(ctx context.Context) error {
+	req := &kafkaEncoder{}
+	req.int32(1)
+	req.string(s.conf.Topic)
+
+	var lastErr error
+	for _, broker := range s.conf.Brokers {
+		res, err := s.request(ctx, broker, kafkaAPIKeyMetadata, kafkaMetadataVersion, req.buf.Bytes(), true)
+		if err != nil {
+			lastErr = err
+			s.closeConn(broker)
+			continue
+		}
+
+		partitions, err := decodeKafkaMetadata(res, s.conf.Topic)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		s.partitions = partitions
+		return nil
+	}
+	return lastErr
+}
+
+func decodeKafkaMetadata(res []byte, topic string) ([]kafkaPartition, error) {
+	d := &kafkaDecoder{buf: res}
+	brokers := make(map[int32]string)
+	for i := d.int32(); i > 0 && d.err == nil; i-- {
+		nodeID := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.nullableString() // rack
+		brokers[nodeID] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.int32() // controller id
+
+	var partitions []kafkaPartition
+	for i := d.int32(); i > 0 && d.err == nil; i-- {
+		topicCode := d.int16()
+		name := d.string()
+		d.int8() // is internal
+		for j := d.int32(); j > 0 && d.err == nil; j-- {
+			code := d.int16()
+			id := d.int32()
+			leader := d.int32()
+			d.int32Array() // replicas
+			d.int32Array() // isr
+			if name != topic || topicCode != 0 || code != 0 {
+				continue
+			}
+			if addr, ok := brokers[leader]; ok {
+				partitions = append(partitions, kafkaPartition{id: id, leader: addr})
+			}
+		}
+		if name == topic && topicCode != 0 && d.err == nil {
+			return nil, kafkaError(topicCode)
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(partitions) == 0 {
+		return nil, errKafkaNoPartitions
+	}
+	return partitions, nil
+}
+
+// request sends a request and reads its response, no response is read when expectResponse is false
+func (s *kafkaAnalyticsSink) request(ctx context.Context, addr string, apiKey int16, version int16, body []byte, expectResponse bool) ([]byte, error) {
+	conn, err := s.conn(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.conf.Timeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	s.correlationID++
+	correlationID := s.correlationID
+
+	msg := &kafkaEncoder{}
+	msg.int32(0) // size, filled in below
+	msg.int16(apiKey)
+	msg.int16(version)
+	msg.int32(correlationID)
+	msg.string(s.conf.ClientID)
+	msg.buf.Write(body)
+	out := msg.buf.Bytes()
+	binary.BigEndian.PutUint32(out, uint32(len(out)-4))
+
+	if _, err = conn.Write(out); err != nil {
+		s.closeConn(addr)
+		return nil, err
+	}
+	if !expectResponse {
+		return nil, nil
+	}
+
+	var header [8]byte
+	if _, err = io.ReadFull(conn, header[:]); err != nil {
+		s.closeConn(addr)
+		return nil, err
+	}
+	size := int32(binary.BigEndian.Uint32(header[:4]))
+	if size < 4 || int32(binary.BigEndian.Uint32(header[4:])) != correlationID {
+		s.closeConn(addr)
+		return nil, errKafkaMalformed
+	}
+	res := make([]byte, size-4)
+	if _, err = io.ReadFull(conn, res); err != nil {
+		s.closeConn(addr)
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *kafkaAnalyticsSink) conn(ctx context.Context, addr string) (net.Conn, error) {
+	if conn := s.conns[addr]; conn != nil {
+		return conn, nil
+	}
+	dialer := &net.Dialer{Timeout: s.conf.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s.conns[addr] = conn
+	return conn, nil
+}
+
+func (s *kafkaAnalyticsSink) closeConn(addr string) {
+	if conn := s.conns[addr]; conn != nil {
+		_ = conn.Close()
+		delete(s.conns, addr)
+	}
+}
+
+func (s *kafkaAnalyticsSink) reset() {
+	for addr := range s.conns {
+		s.closeConn(addr)
+	}
+	s.partitions = nil
+}
+
+func (s *kafkaAnalyticsSink) Close() error {
+	s.reset()
+	return nil
+}
+
+// encodeKafkaRecordBatch encodes records as an uncompressed v2 record batch with JSON values
+func encodeKafkaRecordBatch(records []*AnalyticsRecord) ([]byte, error) {
+	if len(records) == 0 {
+		return nil, errors.New("empty record batch")
+	}
+
+	baseTimestamp := records[0].Timestamp.UnixMilli()
+	maxTimestamp := baseTimestamp
+	body := &kafkaEncoder{}
+	for i, r := range records {
+		value, err := r.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		ts := r.Timestamp.UnixMilli()
+		maxTimestamp = max(maxTimestamp, ts)
+
+		rec := &kafkaEncoder{}
+		rec.int8(0) // attributes
+		rec.varint(ts - baseTimestamp)
+		rec.varint(int64(i))
+		rec.varint(-1) // null key
+		rec.varint(int64(len(value)))
+		rec.buf.Write(value)
+		rec.varint(0) // headers
+
+		body.varint(int64(rec.buf.Len()))
+		body.buf.Write(rec.buf.Bytes())
+	}
+
+	// the crc covers everything from attributes onwards
+	crcd := &kafkaEncoder{}
+	crcd.int16(0) // attributes, no compression
+	crcd.int32(int32(len(records) - 1))
+	crcd.int64(baseTimestamp)
+	crcd.int64(maxTimestamp)
+	crcd.int64(-1) // producer id
+	crcd.int16(-1) // producer epoch
+	crcd.int32(-1) // base sequence
+	crcd.int32(int32(len(records)))
+	crcd.buf.Write(body.buf.Bytes())
+
+	batch := &kafkaEncoder{}
+	batch.int64(0) // base offset
+	batch.int32(int32(4 + 1 + 4 + crcd.buf.Len()))
+	batch.int32(-1) // partition leader epoch
+	batch.int8(2)   // magic
+	batch.int32(int32(crc32.Checksum(crcd.buf.Bytes(), kafkaCRCTable)))
+	batch.buf.Write(crcd.buf.Bytes())
+	return batch.buf.Bytes(), nil
+}
+
+type kafkaEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *kafkaEncoder) int8(v int8) {
+	e.buf.WriteByte(byte(v))
+}
+
+func (e *kafkaEncoder) int16(v int16) {
+	e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+}
+
+func (e *kafkaEncoder) int32(v int32) {
+	e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+}
+
+func (e *kafkaEncoder) int64(v int64) {
+	e.buf.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+}
+
+func (e *kafkaEncoder) varint(v int64) {
+	e.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (e *kafkaEncoder) string(v string) {
+	e.int16(int16(len(v)))
+	e.buf.WriteString(v)
+}
+
+func (e *kafkaEncoder) nullableString(v *string) {
+	if v == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*v)
+}
+
+func (e *kafkaEncoder) bytes(v []byte) {
+	e.int32(int32(len(v)))
+	e.buf.Write(v)
+}
+
+// kafkaDecoder reads big endian fields, the first error sticks and zero values are returned after it
+type kafkaDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *kafkaDecoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = errKafkaMalformed
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *kafkaDecoder) int8() int8 {
+	if b := d.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *kafkaDecoder) string() string {
+	return string(d.take(int(d.int16())))
+}
+
+func (d *kafkaDecoder) nullableString() *string {
+	n := d.int16()
+	if n < 0 {
+		return nil
+	}
+	s := string(d.take(int(n)))
+	return &s
+}
+
+func (d *kafkaDecoder) int32Array() []int32 {
+	n := d.int32()
+	if n < 0 || int(n) > len(d.buf)/4 {
+		if n > 0 {
+			d.err = errKafkaMalformed
+		}
+		return nil
+	}
+	v := make([]int32, 0, n)
+	for i := int32(0); i < n; i++ {
+		v = append(v, d.int32())
+	}
+	return v
+}
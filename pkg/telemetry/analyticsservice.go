@@ -16,8 +16,10 @@ package telemetry
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/atomic"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/livekit/protocol/livekit"
@@ -34,6 +36,10 @@ type AnalyticsService interface {
 	SendStats(ctx context.Context, stats []*livekit.AnalyticsStat)
 	SendEvent(ctx context.Context, events *livekit.AnalyticsEvent)
 	SendNodeRoomStates(ctx context.Context, nodeRooms *livekit.AnalyticsNodeRooms)
+	// SendMetrics writes a metrics batch reported by or for a participant to the analytics sinks
+	SendMetrics(ctx context.Context, roomID livekit.RoomID, roomName livekit.RoomName, identity livekit.ParticipantIdentity, batch *livekit.MetricsBatch)
+	// Close writes queued records to the analytics sinks and closes them, records sent afterwards are dropped
+	Close()
 }
 
 type analyticsService struct {
@@ -44,17 +50,29 @@ type analyticsService struct {
 	events    rpc.AnalyticsRecorderService_IngestEventsClient
 	stats     rpc.AnalyticsRecorderService_IngestStatsClient
 	nodeRooms rpc.AnalyticsRecorderService_IngestNodeRoomStatesClient
+
+	sinks []*analyticsSinkWriter
 }
 
-func NewAnalyticsService(_ *config.Config, currentNode routing.LocalNode) AnalyticsService {
-	return &analyticsService{
-		analyticsKey: "", // TODO: conf.AnalyticsKey
+func NewAnalyticsService(conf *config.Config, currentNode routing.LocalNode) (AnalyticsService, error) {
+	a := &analyticsService{
+		analyticsKey: conf.Analytics.AnalyticsKey,
 		nodeID:       string(currentNode.NodeID()),
 	}
+
+	sinks, err := newAnalyticsSinks(&conf.Analytics, a.nodeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sink := range sinks {
+		logger.Infow("writing analytics", "sink", sink.Name())
+		a.sinks = append(a.sinks, newAnalyticsSinkWriter(sink, &conf.Analytics))
+	}
+	return a, nil
 }
 
 func (a *analyticsService) SendStats(_ context.Context, stats []*livekit.AnalyticsStat) {
-	if a.stats == nil {
+	if a.stats == nil && len(a.sinks) == 0 {
 		return
 	}
 
@@ -63,19 +81,31 @@ func (a *analyticsService) SendStats(_ context.Context, stats []*livekit.Analyti
 		stat.AnalyticsKey = a.analyticsKey
 		stat.Node = a.nodeID
 	}
+	for _, stat := range stats {
+		a.writeToSinks(AnalyticsRecordTypeStats, stat.TimeStamp.AsTime(), stat)
+	}
+
+	if a.stats == nil {
+		return
+	}
 	if err := a.stats.Send(&livekit.AnalyticsStats{Stats: stats}); err != nil {
 		logger.Errorw("failed to send stats", err)
 	}
 }
 
 func (a *analyticsService) SendEvent(_ context.Context, event *livekit.AnalyticsEvent) {
-	if a.events == nil {
+	if a.events == nil && len(a.sinks) == 0 {
 		return
 	}
 
 	event.Id = guid.New("AE_")
 	event.NodeId = a.nodeID
 	event.AnalyticsKey = a.analyticsKey
+	a.writeToSinks(AnalyticsRecordTypeEvent, event.Timestamp.AsTime(), event)
+
+	if a.events == nil {
+		return
+	}
 	if err := a.events.Send(&livekit.AnalyticsEvents{
 		Events: []*livekit.AnalyticsEvent{event},
 	}); err != nil {
@@ -84,14 +114,72 @@ func (a *analyticsService) SendEvent(_ context.Context, event *livekit.Analytics
 }
 
 func (a *analyticsService) SendNodeRoomStates(_ context.Context, nodeRooms *livekit.AnalyticsNodeRooms) {
-	if a.nodeRooms == nil {
+	if a.nodeRooms == nil && len(a.sinks) == 0 {
 		return
 	}
 
 	nodeRooms.NodeId = a.nodeID
 	nodeRooms.SequenceNumber = a.sequenceNumber.Add(1)
 	nodeRooms.Timestamp = timestamppb.Now()
+	a.writeToSinks(AnalyticsRecordTypeNodeRooms, nodeRooms.Timestamp.AsTime(), nodeRooms)
+
+	if a.nodeRooms == nil {
+		return
+	}
 	if err := a.nodeRooms.Send(nodeRooms); err != nil {
 		logger.Errorw("failed to send node room states", err)
 	}
 }
+
+func (a *analyticsService) SendMetrics(
+	_ context.Context,
+	roomID livekit.RoomID,
+	roomName livekit.RoomName,
+	identity livekit.ParticipantIdentity,
+	batch *livekit.MetricsBatch,
+) {
+	if len(a.sinks) == 0 {
+		return
+	}
+
+	ts := time.Now()
+	if batch.NormalizedTimestamp != nil {
+		ts = batch.NormalizedTimestamp.AsTime()
+	}
+	record := &AnalyticsRecord{
+		Type:                AnalyticsRecordTypeMetrics,
+		Timestamp:           ts,
+		RoomID:              roomID,
+		RoomName:            roomName,
+		ParticipantIdentity: identity,
+		Message:             proto.Clone(batch),
+	}
+	for _, w := range a.sinks {
+		w.enqueue(record)
+	}
+}
+
+func (a *analyticsService) Close() {
+	for _, w := range a.sinks {
+		w.close()
+	}
+}
+
+// writeToSinks queues a copy of the message, callers keep modifying theirs after sending
+func (a *analyticsService) writeToSinks(recordType AnalyticsRecordType, ts time.Time, msg proto.Message) {
+	if len(a.sinks) == 0 {
+		return
+	}
+	if ts.Unix() <= 0 {
+		ts = time.Now()
+	}
+
+	record := &AnalyticsRecord{
+		Type:      recordType,
+		Timestamp: ts,
+		Message:   proto.Clone(msg),
+	}
+	for _, w := range a.sinks {
+		w.enqueue(record)
+	}
+}
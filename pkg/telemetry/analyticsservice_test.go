@@ -0,0 +1,385 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+)
+
+type analyticsLine struct {
+	Type      string          `json:"type"`
+	Timestamp int64           `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func newTestAnalyticsService(t *testing.T, update func(conf *config.AnalyticsConfig)) telemetry.AnalyticsService {
+	conf := &config.Config{Analytics: config.DefaultConfig.Analytics}
+	conf.Analytics.AnalyticsKey = "test-key"
+	conf.Analytics.FlushInterval = 20 * time.Millisecond
+	update(&conf.Analytics)
+
+	node, err := routing.NewLocalNodeFromNodeProto(&livekit.Node{Id: "ND_test"})
+	require.NoError(t, err)
+	a, err := telemetry.NewAnalyticsService(conf, node)
+	require.NoError(t, err)
+	return a
+}
+
+func sendTestAnalytics(a telemetry.AnalyticsService) {
+	a.SendEvent(context.Background(), &livekit.AnalyticsEvent{
+		Type:      livekit.AnalyticsEventType_ROOM_CREATED,
+		Timestamp: timestamppb.Now(),
+		RoomId:    "RM_test",
+	})
+	a.SendStats(context.Background(), []*livekit.AnalyticsStat{
+		{Kind: livekit.StreamType_UPSTREAM, TimeStamp: timestamppb.Now(), RoomId: "RM_test", TrackId: "TR_a"},
+		{Kind: livekit.StreamType_DOWNSTREAM, TimeStamp: timestamppb.Now(), RoomId: "RM_test", TrackId: "TR_b"},
+	})
+}
+
+func requireTestAnalytics(t *testing.T, lines []analyticsLine) {
+	require.Len(t, lines, 3)
+	require.Equal(t, "event", lines[0].Type)
+	event := map[string]any{}
+	require.NoError(t, json.Unmarshal(lines[0].Data, &event))
+	require.Equal(t, "RM_test", event["roomId"])
+	require.Equal(t, "ND_test", event["nodeId"])
+	require.Equal(t, "test-key", event["analyticsKey"])
+	require.Equal(t, "stats", lines[1].Type)
+	require.Equal(t, "stats", lines[2].Type)
+}
+
+func parseAnalyticsLines(t *testing.T, r io.Reader) []analyticsLine {
+	var lines []analyticsLine
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var line analyticsLine
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestAnalyticsFileSink(t *testing.T) {
+	dir := t.TempDir()
+	a := newTestAnalyticsService(t, func(conf *config.AnalyticsConfig) {
+		// every batch of three records is flushed right away and starts a new file
+		conf.BatchSize = 3
+		conf.FlushInterval = time.Minute
+		conf.File.Directory = dir
+		conf.File.MaxSize = 1
+		conf.File.MaxFiles = 2
+	})
+
+	readAll := func() []analyticsLine {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var lines []analyticsLine
+		for _, entry := range entries {
+			f, err := os.Open(filepath.Join(dir, entry.Name()))
+			require.NoError(t, err)
+			lines = append(lines, parseAnalyticsLines(t, f)...)
+			f.Close()
+		}
+		return lines
+	}
+
+	sendTestAnalytics(a)
+	require.Eventually(t, func() bool { return len(readAll()) == 3 }, 2*time.Second, 10*time.Millisecond)
+	requireTestAnalytics(t, readAll())
+
+	// only the newest two files are kept
+	for i := 0; i < 3; i++ {
+		sendTestAnalytics(a)
+	}
+	require.Eventually(t, func() bool {
+		entries, err := os.ReadDir(dir)
+		return err == nil && len(entries) == 2 && len(readAll()) == 6
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestAnalyticsClose(t *testing.T) {
+	dir := t.TempDir()
+	a := newTestAnalyticsService(t, func(conf *config.AnalyticsConfig) {
+		// neither the batch size nor the flush interval is reached before closing
+		conf.BatchSize = 10
+		conf.FlushInterval = time.Minute
+		conf.File.Directory = dir
+	})
+
+	sendTestAnalytics(a)
+	a.Close()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	f, err := os.Open(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	defer f.Close()
+	requireTestAnalytics(t, parseAnalyticsLines(t, f))
+
+	// records sent after closing are dropped and closing again is a no-op
+	sendTestAnalytics(a)
+	a.Close()
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestAnalyticsHTTPSink(t *testing.T) {
+	var lock sync.Mutex
+	var lines []analyticsLine
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		lock.Lock()
+		lines = append(lines, parseAnalyticsLines(t, r.Body)...)
+		lock.Unlock()
+	}))
+	defer srv.Close()
+
+	a := newTestAnalyticsService(t, func(conf *config.AnalyticsConfig) {
+		conf.HTTP.URL = srv.URL
+		conf.HTTP.Headers = map[string]string{"Authorization": "Bearer secret"}
+	})
+	sendTestAnalytics(a)
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(lines) == 3
+	}, 2*time.Second, 10*time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	requireTestAnalytics(t, lines)
+}
+
+func TestAnalyticsKafkaSink(t *testing.T) {
+	broker := newKafkaStub(t, "analytics")
+	a := newTestAnalyticsService(t, func(conf *config.AnalyticsConfig) {
+		conf.Kafka.Brokers = []string{broker.addr}
+		conf.Kafka.Topic = "analytics"
+	})
+	sendTestAnalytics(a)
+
+	require.Eventually(t, func() bool {
+		return len(broker.values()) == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var lines []analyticsLine
+	for _, v := range broker.values() {
+		var line analyticsLine
+		require.NoError(t, json.Unmarshal(v, &line))
+		lines = append(lines, line)
+	}
+	requireTestAnalytics(t, lines)
+}
+
+// kafkaStub is a single broker answering Metadata v1 and Produce v3 requests for one topic
+type kafkaStub struct {
+	t     *testing.T
+	topic string
+	addr  string
+
+	lock     sync.Mutex
+	produced [][]byte
+}
+
+func newKafkaStub(t *testing.T, topic string) *kafkaStub {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	s := &kafkaStub{t: t, topic: topic, addr: ln.Addr().String()}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *kafkaStub) values() [][]byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([][]byte(nil), s.produced...)
+}
+
+func (s *kafkaStub) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+
+		r := &kafkaStubReader{buf: req}
+		apiKey := r.int16()
+		r.int16() // version
+		correlationID := r.int32()
+		r.string() // client id
+
+		var res []byte
+		switch apiKey {
+		case 3:
+			res = s.metadata()
+		case 0:
+			res = s.produce(r)
+		default:
+			s.t.Errorf("unexpected kafka api key %d", apiKey)
+			return
+		}
+
+		out := binary.BigEndian.AppendUint32(nil, uint32(len(res)+4))
+		out = binary.BigEndian.AppendUint32(out, uint32(correlationID))
+		if _, err := conn.Write(append(out, res...)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *kafkaStub) metadata() []byte {
+	host, portStr, _ := net.SplitHostPort(s.addr)
+	port, _ := strconv.Atoi(portStr)
+
+	var b []byte
+	b = binary.BigEndian.AppendUint32(b, 1) // brokers
+	b = binary.BigEndian.AppendUint32(b, 0) // node id
+	b = appendKafkaString(b, host)
+	b = binary.BigEndian.AppendUint32(b, uint32(port))
+	b = binary.BigEndian.AppendUint16(b, 0xffff) // null rack
+	b = binary.BigEndian.AppendUint32(b, 0)      // controller
+	b = binary.BigEndian.AppendUint32(b, 1)      // topics
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = appendKafkaString(b, s.topic)
+	b = append(b, 0)                        // not internal
+	b = binary.BigEndian.AppendUint32(b, 1) // partitions
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint32(b, 0) // partition
+	b = binary.BigEndian.AppendUint32(b, 0) // leader
+	b = binary.BigEndian.AppendUint32(b, 1) // replicas
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = binary.BigEndian.AppendUint32(b, 1) // isr
+	b = binary.BigEndian.AppendUint32(b, 0)
+	return b
+}
+
+func (s *kafkaStub) produce(r *kafkaStubReader) []byte {
+	require.Equal(s.t, int16(-1), r.int16()) // transactional id
+	require.Equal(s.t, int16(1), r.int16())  // acks
+	r.int32()                                // timeout
+	require.Equal(s.t, int32(1), r.int32())
+	require.Equal(s.t, s.topic, r.string())
+	require.Equal(s.t, int32(1), r.int32())
+	partition := r.int32()
+	batch := &kafkaStubReader{buf: r.take(int(r.int32()))}
+
+	batch.take(8 + 4 + 4) // base offset, length, leader epoch
+	require.Equal(s.t, byte(2), batch.take(1)[0])
+	crc := uint32(batch.int32())
+	require.Equal(s.t, crc, crc32.Checksum(batch.buf, crc32.MakeTable(crc32.Castagnoli)))
+	batch.take(2 + 4 + 8 + 8 + 8 + 2 + 4)
+	count := batch.int32()
+
+	s.lock.Lock()
+	for i := int32(0); i < count; i++ {
+		batch.varint() // length
+		batch.take(1)  // attributes
+		batch.varint() // timestamp delta
+		require.Equal(s.t, int64(i), batch.varint())
+		require.Equal(s.t, int64(-1), batch.varint()) // key
+		s.produced = append(s.produced, batch.take(int(batch.varint())))
+		batch.varint() // headers
+	}
+	s.lock.Unlock()
+
+	var b []byte
+	b = binary.BigEndian.AppendUint32(b, 1)
+	b = appendKafkaString(b, s.topic)
+	b = binary.BigEndian.AppendUint32(b, 1)
+	b = binary.BigEndian.AppendUint32(b, uint32(partition))
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint64(b, 0)
+	b = binary.BigEndian.AppendUint64(b, 0)
+	b = binary.BigEndian.AppendUint32(b, 0) // throttle time
+	return b
+}
+
+func appendKafkaString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+type kafkaStubReader struct {
+	buf []byte
+}
+
+func (r *kafkaStubReader) take(n int) []byte {
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *kafkaStubReader) int16() int16 {
+	return int16(binary.BigEndian.Uint16(r.take(2)))
+}
+
+func (r *kafkaStubReader) int32() int32 {
+	return int32(binary.BigEndian.Uint32(r.take(4)))
+}
+
+func (r *kafkaStubReader) string() string {
+	return string(r.take(int(r.int16())))
+}
+
+func (r *kafkaStubReader) varint() int64 {
+	v, n := binary.Varint(r.buf)
+	r.buf = r.buf[n:]
+	return v
+}
@@ -0,0 +1,220 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+type AnalyticsRecordType string
+
+const (
+	AnalyticsRecordTypeStats     AnalyticsRecordType = "stats"
+	AnalyticsRecordTypeEvent     AnalyticsRecordType = "event"
+	AnalyticsRecordTypeNodeRooms AnalyticsRecordType = "node_rooms"
+	AnalyticsRecordTypeMetrics   AnalyticsRecordType = "metrics"
+)
+
+// AnalyticsRecord is a single AnalyticsStat, AnalyticsEvent, AnalyticsNodeRooms or MetricsBatch written to sinks.
+// MetricsBatch does not carry its room and participant, they are set on the record instead.
+type AnalyticsRecord struct {
+	Type                AnalyticsRecordType
+	Timestamp           time.Time
+	RoomID              livekit.RoomID
+	RoomName            livekit.RoomName
+	ParticipantIdentity livekit.ParticipantIdentity
+	Message             proto.Message
+}
+
+type analyticsRecordJSON struct {
+	Type                AnalyticsRecordType `json:"type"`
+	Timestamp           int64               `json:"timestamp"`
+	RoomID              string              `json:"room_id,omitempty"`
+	RoomName            string              `json:"room_name,omitempty"`
+	ParticipantIdentity string              `json:"participant_identity,omitempty"`
+	Data                json.RawMessage     `json:"data"`
+}
+
+func (r *AnalyticsRecord) MarshalJSON() ([]byte, error) {
+	data, err := protojson.Marshal(r.Message)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&analyticsRecordJSON{
+		Type:                r.Type,
+		Timestamp:           r.Timestamp.UnixMilli(),
+		RoomID:              string(r.RoomID),
+		RoomName:            string(r.RoomName),
+		ParticipantIdentity: string(r.ParticipantIdentity),
+		Data:                data,
+	})
+}
+
+// marshalAnalyticsRecords encodes records as JSON lines
+func marshalAnalyticsRecords(records []*AnalyticsRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, r := range records {
+		line, err := r.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// AnalyticsSink is a destination for analytics records. Write is called from a single goroutine per sink.
+type AnalyticsSink interface {
+	Name() string
+	Write(ctx context.Context, records []*AnalyticsRecord) error
+	Close() error
+}
+
+func newAnalyticsSinks(conf *config.AnalyticsConfig, nodeID string) ([]AnalyticsSink, error) {
+	var sinks []AnalyticsSink
+	if conf.File.Directory != "" {
+		sink, err := newFileAnalyticsSink(conf.File, nodeID)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if conf.HTTP.URL != "" {
+		sinks = append(sinks, newHTTPAnalyticsSink(conf.HTTP))
+	}
+	if len(conf.Kafka.Brokers) != 0 {
+		sink, err := newKafkaAnalyticsSink(conf.Kafka)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// analyticsSinkWriter batches records for a sink, so slow sinks do not block the telemetry service
+type analyticsSinkWriter struct {
+	sink          AnalyticsSink
+	batchSize     int
+	flushInterval time.Duration
+	records       chan *AnalyticsRecord
+	dropped       atomic.Uint64
+	closeOnce     sync.Once
+	closed        chan struct{}
+	done          chan struct{}
+}
+
+func newAnalyticsSinkWriter(sink AnalyticsSink, conf *config.AnalyticsConfig) *analyticsSinkWriter {
+	w := &analyticsSinkWriter{
+		sink:          sink,
+		batchSize:     max(conf.BatchSize, 1),
+		flushInterval: conf.FlushInterval,
+		records:       make(chan *AnalyticsRecord, max(conf.QueueSize, 1)),
+		closed:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = config.DefaultConfig.Analytics.FlushInterval
+	}
+	go w.worker()
+	return w
+}
+
+func (w *analyticsSinkWriter) enqueue(record *AnalyticsRecord) {
+	select {
+	case <-w.closed:
+		return
+	default:
+	}
+
+	select {
+	case w.records <- record:
+	default:
+		w.dropped.Inc()
+	}
+}
+
+func (w *analyticsSinkWriter) worker() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*AnalyticsRecord, 0, w.batchSize)
+	flush := func() {
+		if dropped := w.dropped.Swap(0); dropped != 0 {
+			logger.Warnw("analytics sink falling behind, dropped records", nil, "sink", w.sink.Name(), "dropped", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), w.flushInterval)
+		if err := w.sink.Write(ctx, batch); err != nil {
+			logger.Errorw("failed to write analytics records", err, "sink", w.sink.Name(), "count", len(batch))
+		}
+		cancel()
+		batch = make([]*AnalyticsRecord, 0, w.batchSize)
+	}
+
+	for {
+		select {
+		case record := <-w.records:
+			batch = append(batch, record)
+			if len(batch) >= w.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-w.closed:
+			for {
+				select {
+				case record := <-w.records:
+					batch = append(batch, record)
+					if len(batch) >= w.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					if err := w.sink.Close(); err != nil {
+						logger.Errorw("failed to close analytics sink", err, "sink", w.sink.Name())
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// close flushes queued records and closes the sink
+func (w *analyticsSinkWriter) close() {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+	})
+	<-w.done
+}
@@ -20,6 +20,7 @@ import (
 
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/livekit/livekit-server/pkg/metric"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	"github.com/livekit/protocol/livekit"
@@ -28,6 +29,12 @@ import (
 	"github.com/livekit/protocol/webhook"
 )
 
+const (
+	// webhook events of rooms with a lobby, not part of the protocol webhook package
+	EventParticipantWaiting  = "participant_waiting"
+	EventParticipantAdmitted = "participant_admitted"
+)
+
 func (t *telemetryService) NotifyEvent(ctx context.Context, event *livekit.WebhookEvent) {
 	if t.notifier == nil {
 		return
@@ -41,6 +48,23 @@ func (t *telemetryService) NotifyEvent(ctx context.Context, event *livekit.Webho
 	}
 }
 
+// notifyRoomFinished sends the room_finished webhook with the quality report of the room, when the notifier
+// can deliver it
+func (t *telemetryService) notifyRoomFinished(ctx context.Context, event *livekit.WebhookEvent, report *QualityReport) {
+	notifier, ok := t.notifier.(QualityReportNotifier)
+	if !ok || report == nil {
+		t.NotifyEvent(ctx, event)
+		return
+	}
+
+	event.CreatedAt = time.Now().Unix()
+	event.Id = guid.New("EV_")
+
+	if err := notifier.QueueNotifyRoomFinished(ctx, event, report); err != nil {
+		logger.Warnw("failed to notify webhook", err, "event", event.Event)
+	}
+}
+
 func (t *telemetryService) RoomStarted(ctx context.Context, room *livekit.Room) {
 	t.enqueue(func() {
 		t.NotifyEvent(ctx, &livekit.WebhookEvent{
@@ -56,12 +80,12 @@ func (t *telemetryService) RoomStarted(ctx context.Context, room *livekit.Room)
 	})
 }
 
-func (t *telemetryService) RoomEnded(ctx context.Context, room *livekit.Room) {
+func (t *telemetryService) RoomEnded(ctx context.Context, room *livekit.Room, report *QualityReport) {
 	t.enqueue(func() {
-		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+		t.notifyRoomFinished(ctx, &livekit.WebhookEvent{
 			Event: webhook.EventRoomFinished,
 			Room:  room,
-		})
+		}, report)
 
 		t.SendEvent(ctx, &livekit.AnalyticsEvent{
 			Type:      livekit.AnalyticsEventType_ROOM_ENDED,
@@ -102,6 +126,26 @@ func (t *telemetryService) ParticipantJoined(
 	})
 }
 
+func (t *telemetryService) ParticipantWaiting(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo) {
+	t.enqueue(func() {
+		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+			Event:       EventParticipantWaiting,
+			Room:        room,
+			Participant: participant,
+		})
+	})
+}
+
+func (t *telemetryService) ParticipantAdmitted(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo) {
+	t.enqueue(func() {
+		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+			Event:       EventParticipantAdmitted,
+			Room:        room,
+			Participant: participant,
+		})
+	})
+}
+
 func (t *telemetryService) ParticipantActive(
 	ctx context.Context,
 	room *livekit.Room,
@@ -188,6 +232,11 @@ func (t *telemetryService) ParticipantLeft(ctx context.Context,
 				prometheus.SubParticipant()
 			}
 		}
+		prometheus.ParticipantLeftRoom(
+			livekit.RoomName(room.Name),
+			livekit.ParticipantID(participant.Sid),
+			livekit.ParticipantIdentity(participant.Identity),
+		)
 
 		if isConnected && shouldSendEvent {
 			t.NotifyEvent(ctx, &livekit.WebhookEvent{
@@ -292,6 +341,14 @@ func (t *telemetryService) TrackSubscribed(
 ) {
 	t.enqueue(func() {
 		prometheus.RecordTrackSubscribeSuccess(track.Type.String())
+		if worker, ok := t.getWorker(participantID); ok {
+			prometheus.RecordRoomTrackSubscribed(
+				worker.roomName,
+				participantID,
+				livekit.ParticipantIdentity(publisher.GetIdentity()),
+				livekit.TrackID(track.Sid),
+			)
+		}
 
 		if !shouldSendEvent {
 			return
@@ -331,6 +388,9 @@ func (t *telemetryService) TrackUnsubscribed(
 ) {
 	t.enqueue(func() {
 		prometheus.RecordTrackUnsubscribed(track.Type.String())
+		if worker, ok := t.getWorker(participantID); ok {
+			prometheus.RecordRoomTrackUnsubscribed(worker.roomName, participantID, livekit.TrackID(track.Sid))
+		}
 
 		if shouldSendEvent {
 			room := t.getRoomDetails(participantID)
@@ -533,6 +593,43 @@ func (t *telemetryService) Webhook(ctx context.Context, webhookInfo *livekit.Web
 	})
 }
 
+func (t *telemetryService) ParticipantMetrics(ctx context.Context, participantID livekit.ParticipantID, batch *livekit.MetricsBatch) {
+	if !t.metricsConfig.Enabled() {
+		return
+	}
+
+	t.enqueue(func() {
+		filtered := t.metricsFilter.Filter(batch)
+		if filtered == nil {
+			return
+		}
+
+		if t.metricsConfig.Prometheus {
+			for _, ts := range filtered.TimeSeries {
+				name, _, _ := metric.MetricLabelName(filtered, ts.Label)
+				for _, sample := range ts.Samples {
+					prometheus.RecordParticipantMetricSample(name, sample.Value)
+				}
+			}
+			for _, ev := range filtered.Events {
+				if ev.EndTimestampMs == nil {
+					continue
+				}
+				name, _, _ := metric.MetricLabelName(filtered, ev.Label)
+				prometheus.RecordParticipantMetricEvent(name, *ev.EndTimestampMs-ev.StartTimestampMs)
+			}
+		}
+
+		if t.metricsConfig.Analytics {
+			worker, ok := t.getWorker(participantID)
+			if !ok {
+				return
+			}
+			t.SendMetrics(ctx, worker.roomID, worker.roomName, worker.participantIdentity, filtered)
+		}
+	})
+}
+
 // returns a livekit.Room with only name and sid filled out
 // returns nil if room is not found
 func (t *telemetryService) getRoomDetails(participantID livekit.ParticipantID) *livekit.Room {
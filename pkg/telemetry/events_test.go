@@ -21,6 +21,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/livekit/livekit-server/pkg/metric"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/livekit-server/pkg/telemetry/telemetryfakes"
 	"github.com/livekit/protocol/livekit"
 )
 
@@ -236,3 +239,52 @@ func Test_OnTrackSubscribed_EventIsSent(t *testing.T) {
 	require.Equal(t, publisherInfo.Identity, eventTrackSubscribed.Publisher.Identity)
 
 }
+
+func Test_ParticipantMetrics(t *testing.T) {
+	analytics := &telemetryfakes.FakeAnalyticsService{}
+	sut := telemetry.NewTelemetryService(nil, analytics, metric.MetricsAggregatorConfig{
+		Prometheus: true,
+		Analytics:  true,
+		Labels:     []string{livekit.MetricLabel_AGENTS_TTS_TTFB.String(), "speech_latency"},
+	})
+
+	room := &livekit.Room{Sid: "RM_metrics", Name: "metrics"}
+	participantInfo := &livekit.ParticipantInfo{Sid: "PA_agent", Identity: "agent"}
+	sut.ParticipantJoined(context.Background(), room, participantInfo, nil, nil, false)
+
+	end := int64(1500)
+	batch := &livekit.MetricsBatch{
+		StrData: []string{"speech_latency", "vad_probability"},
+		TimeSeries: []*livekit.TimeSeriesMetric{
+			{Label: uint32(livekit.MetricLabel_AGENTS_TTS_TTFB), Samples: []*livekit.MetricSample{{Value: 250}, {Value: 300}}},
+			{Label: uint32(livekit.MetricLabel_SUBSCRIBER_RTT), Samples: []*livekit.MetricSample{{Value: 40}}},
+			{Label: uint32(livekit.MetricLabel_METRIC_LABEL_PREDEFINED_MAX_VALUE) + 1, Samples: []*livekit.MetricSample{{Value: 0.9}}},
+		},
+		Events: []*livekit.EventMetric{
+			{Label: uint32(livekit.MetricLabel_METRIC_LABEL_PREDEFINED_MAX_VALUE), StartTimestampMs: 1000, EndTimestampMs: &end},
+		},
+	}
+	sut.ParticipantMetrics(context.Background(), livekit.ParticipantID(participantInfo.Sid), batch)
+
+	require.Eventually(t, func() bool {
+		return analytics.SendMetricsCallCount() == 1
+	}, time.Second, 10*time.Millisecond)
+	_, roomID, roomName, identity, sent := analytics.SendMetricsArgsForCall(0)
+	require.Equal(t, livekit.RoomID(room.Sid), roomID)
+	require.Equal(t, livekit.RoomName(room.Name), roomName)
+	require.Equal(t, livekit.ParticipantIdentity(participantInfo.Identity), identity)
+
+	// only allowed labels are aggregated
+	require.Len(t, sent.TimeSeries, 1)
+	require.Equal(t, uint32(livekit.MetricLabel_AGENTS_TTS_TTFB), sent.TimeSeries[0].Label)
+	require.Len(t, sent.Events, 1)
+
+	// nothing is sent when no label is allowed
+	sut.ParticipantMetrics(context.Background(), livekit.ParticipantID(participantInfo.Sid), &livekit.MetricsBatch{
+		TimeSeries: []*livekit.TimeSeriesMetric{
+			{Label: uint32(livekit.MetricLabel_SUBSCRIBER_RTT), Samples: []*livekit.MetricSample{{Value: 40}}},
+		},
+	})
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 1, analytics.SendMetricsCallCount())
+}
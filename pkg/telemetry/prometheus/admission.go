@@ -0,0 +1,117 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+const (
+	AdmissionAdmitted   = "admitted"
+	AdmissionRefused    = "refused"
+	AdmissionRedirected = "redirected"
+)
+
+var (
+	admissionDecisions         *prometheus.CounterVec
+	admissionCPUPerTrack       *prometheus.GaugeVec
+	admissionBytesPerTrack     *prometheus.GaugeVec
+	admissionParticipantTracks *prometheus.HistogramVec
+	admissionPredictedCPULoad  *prometheus.GaugeVec
+	admissionPredictedBytes    *prometheus.GaugeVec
+)
+
+func initAdmissionStats(nodeID string, nodeType livekit.NodeType) {
+	constLabels := prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()}
+
+	admissionDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "admission",
+		Name:        "decisions",
+		ConstLabels: constLabels,
+	}, []string{"decision"})
+
+	// model inputs and predictions are labelled by the node the participant was checked against
+	admissionCPUPerTrack = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "admission",
+		Name:        "cpu_per_track",
+		ConstLabels: constLabels,
+		Help:        "CPU cores used to forward a track, learnt from node stats.",
+	}, []string{"target_node"})
+	admissionBytesPerTrack = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "admission",
+		Name:        "bytes_per_track",
+		ConstLabels: constLabels,
+		Help:        "Bytes per second of a forwarded track, learnt from node stats.",
+	}, []string{"target_node", "direction"})
+	admissionParticipantTracks = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "admission",
+		Name:        "participant_tracks",
+		ConstLabels: constLabels,
+		Help:        "Tracks a joining participant is expected to add, including the fan-out of its own tracks.",
+		Buckets:     []float64{0, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+	}, []string{"direction"})
+	admissionPredictedCPULoad = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "admission",
+		Name:        "predicted_cpu_load",
+		ConstLabels: constLabels,
+	}, []string{"target_node"})
+	admissionPredictedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "admission",
+		Name:        "predicted_bytes_per_sec",
+		ConstLabels: constLabels,
+	}, []string{"target_node"})
+
+	prometheus.MustRegister(admissionDecisions)
+	prometheus.MustRegister(admissionCPUPerTrack)
+	prometheus.MustRegister(admissionBytesPerTrack)
+	prometheus.MustRegister(admissionParticipantTracks)
+	prometheus.MustRegister(admissionPredictedCPULoad)
+	prometheus.MustRegister(admissionPredictedBytes)
+}
+
+// RecordAdmissionDecision counts a decision, one of admitted, refused or redirected
+func RecordAdmissionDecision(decision string) {
+	if admissionDecisions == nil {
+		return
+	}
+	admissionDecisions.WithLabelValues(decision).Inc()
+}
+
+// RecordAdmissionModel records the inputs and the prediction of checking a participant against a node
+func RecordAdmissionModel(
+	targetNode livekit.NodeID,
+	cpuPerTrack, bytesInPerTrack, bytesOutPerTrack float64,
+	tracksIn, tracksOut int,
+	predictedCPULoad, predictedBytesPerSec float64,
+) {
+	if admissionDecisions == nil {
+		return
+	}
+	admissionCPUPerTrack.WithLabelValues(string(targetNode)).Set(cpuPerTrack)
+	admissionBytesPerTrack.WithLabelValues(string(targetNode), string(Incoming)).Set(bytesInPerTrack)
+	admissionBytesPerTrack.WithLabelValues(string(targetNode), string(Outgoing)).Set(bytesOutPerTrack)
+	admissionParticipantTracks.WithLabelValues(string(Incoming)).Observe(float64(tracksIn))
+	admissionParticipantTracks.WithLabelValues(string(Outgoing)).Observe(float64(tracksOut))
+	admissionPredictedCPULoad.WithLabelValues(string(targetNode)).Set(predictedCPULoad)
+	admissionPredictedBytes.WithLabelValues(string(targetNode)).Set(predictedBytesPerSec)
+}
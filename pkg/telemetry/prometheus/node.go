@@ -125,9 +125,12 @@ func Init(nodeID string, nodeType livekit.NodeType) error {
 
 	initPacketStats(nodeID, nodeType)
 	initRoomStats(nodeID, nodeType)
+	initRoomSeries(nodeID, nodeType)
+	initParticipantMetrics(nodeID, nodeType)
 	rpc.InitPSRPCStats(prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()})
 	initQualityStats(nodeID, nodeType)
 	initDataPacketStats(nodeID, nodeType)
+	initAdmissionStats(nodeID, nodeType)
 
 	var err error
 	cpuStats, err = hwstats.NewCPUStats(nil)
@@ -0,0 +1,61 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promParticipantMetric              *prometheus.HistogramVec
+	promParticipantMetricEventDuration *prometheus.HistogramVec
+)
+
+func initParticipantMetrics(nodeID string, nodeType livekit.NodeType) {
+	constLabels := prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()}
+
+	// metrics batches carry latencies in ms as well as counters, buckets cover both
+	promParticipantMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "participant_metric",
+		Name:        "value",
+		ConstLabels: constLabels,
+		Buckets:     []float64{1, 5, 10, 25, 50, 100, 200, 300, 500, 750, 1000, 1500, 2000, 3000, 5000, 10000},
+	}, []string{"metric"})
+	promParticipantMetricEventDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "participant_metric",
+		Name:        "event_duration_ms",
+		ConstLabels: constLabels,
+		Buckets:     []float64{10, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000},
+	}, []string{"metric"})
+
+	prometheus.MustRegister(promParticipantMetric)
+	prometheus.MustRegister(promParticipantMetricEventDuration)
+}
+
+// RecordParticipantMetricSample records a time series sample of a metrics batch
+func RecordParticipantMetricSample(metric string, value float32) {
+	promParticipantMetric.WithLabelValues(metric).Observe(float64(value))
+}
+
+// RecordParticipantMetricEvent records the duration of a metrics batch event that has ended
+func RecordParticipantMetricEvent(metric string, durationMs int64) {
+	if durationMs >= 0 {
+		promParticipantMetricEventDuration.WithLabelValues(metric).Observe(float64(durationMs))
+	}
+}
@@ -15,6 +15,8 @@
 package prometheus
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/livekit/protocol/livekit"
@@ -23,6 +25,13 @@ import (
 var (
 	qualityRating prometheus.Histogram
 	qualityScore  prometheus.Histogram
+
+	audioQualityRFactor *prometheus.HistogramVec
+	audioQualityMOS     *prometheus.HistogramVec
+
+	videoFreezes        *prometheus.CounterVec
+	videoFreezeDuration *prometheus.CounterVec
+	videoFramesDropped  *prometheus.CounterVec
 )
 
 func initQualityStats(nodeID string, nodeType livekit.NodeType) {
@@ -41,11 +50,69 @@ func initQualityStats(nodeID string, nodeType livekit.NodeType) {
 		Buckets:     []float64{1.0, 2.0, 2.5, 3.0, 3.25, 3.5, 3.75, 4.0, 4.25, 4.5},
 	})
 
+	audioQualityRFactor = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "quality",
+		Name:        "audio_r_factor",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		// G.107 user satisfaction categories
+		Buckets: []float64{50, 60, 70, 80, 90, 100},
+	}, []string{"direction"})
+	audioQualityMOS = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "quality",
+		Name:        "audio_mos",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Buckets:     []float64{1.0, 2.0, 2.5, 3.0, 3.25, 3.5, 3.75, 4.0, 4.25, 4.5},
+	}, []string{"direction"})
+
+	videoFreezes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "quality",
+		Name:        "video_freezes_total",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"source", "cause"})
+	videoFreezeDuration = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "quality",
+		Name:        "video_freeze_seconds_total",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"source", "cause"})
+	videoFramesDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "quality",
+		Name:        "video_frames_dropped_total",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"source"})
+
 	prometheus.MustRegister(qualityRating)
 	prometheus.MustRegister(qualityScore)
+	prometheus.MustRegister(audioQualityRFactor)
+	prometheus.MustRegister(audioQualityMOS)
+	prometheus.MustRegister(videoFreezes)
+	prometheus.MustRegister(videoFreezeDuration)
+	prometheus.MustRegister(videoFramesDropped)
 }
 
 func RecordQuality(rating livekit.ConnectionQuality, score float32) {
 	qualityRating.Observe(float64(rating))
 	qualityScore.Observe(float64(score))
 }
+
+// RecordAudioQuality records E-model rating of an audio track
+func RecordAudioQuality(direction Direction, rFactor float32, mos float32) {
+	audioQualityRFactor.WithLabelValues(string(direction)).Observe(float64(rFactor))
+	audioQualityMOS.WithLabelValues(string(direction)).Observe(float64(mos))
+}
+
+// RecordVideoFreezes records freezes of a subscribed video track, cause is one of network, key_frame or paused
+func RecordVideoFreezes(trackSource livekit.TrackSource, cause string, count uint32, duration time.Duration) {
+	videoFreezes.WithLabelValues(trackSource.String(), cause).Add(float64(count))
+	videoFreezeDuration.WithLabelValues(trackSource.String(), cause).Add(duration.Seconds())
+}
+
+func RecordVideoFramesDropped(trackSource livekit.TrackSource, frames uint32) {
+	if frames > 0 {
+		videoFramesDropped.WithLabelValues(trackSource.String()).Add(float64(frames))
+	}
+}
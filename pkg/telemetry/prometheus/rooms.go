@@ -134,7 +134,8 @@ func RoomStarted() {
 	roomCurrent.Inc()
 }
 
-func RoomEnded(startedAt time.Time) {
+func RoomEnded(roomName livekit.RoomName, startedAt time.Time) {
+	deleteRoomSeries(roomName)
 	if !startedAt.IsZero() {
 		promRoomDuration.Observe(float64(time.Since(startedAt)) / float64(time.Second))
 	}
@@ -0,0 +1,406 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"strings"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// RoomMetricsConfig enables series labelled by room, and by participant and track for selected rooms.
+// Series of rooms that ended and participants that left are deleted, the least recently updated
+// series are deleted when a limit is reached. Series are labelled with the tenant of the room as well,
+// empty for rooms of the deployment.
+type RoomMetricsConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// rooms that also get series per participant and track, a trailing * matches room name prefixes
+	ParticipantRooms []string `yaml:"participant_rooms,omitempty"`
+	MaxRooms         int      `yaml:"max_rooms,omitempty"`
+	MaxTracks        int      `yaml:"max_tracks,omitempty"`
+}
+
+var DefaultRoomMetricsConfig = RoomMetricsConfig{
+	MaxRooms:  1000,
+	MaxTracks: 5000,
+}
+
+var (
+	promRoomBitrate       *prometheus.GaugeVec
+	promRoomPacketLoss    *prometheus.GaugeVec
+	promRoomQualityScore  *prometheus.GaugeVec
+	promRoomSubscribers   *prometheus.GaugeVec
+	promTrackBitrate      *prometheus.GaugeVec
+	promTrackPacketLoss   *prometheus.GaugeVec
+	promTrackQualityScore *prometheus.GaugeVec
+	promTrackSubscribers  *prometheus.GaugeVec
+
+	roomSeriesVecs        []*prometheus.GaugeVec
+	participantSeriesVecs []*prometheus.GaugeVec
+
+	roomSeriesMu sync.Mutex
+	roomSeries   *roomSeriesTracker
+)
+
+func initRoomSeries(nodeID string, nodeType livekit.NodeType) {
+	constLabels := prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()}
+	newGaugeVec := func(subsystem string, name string, labels []string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   livekitNamespace,
+			Subsystem:   subsystem,
+			Name:        name,
+			ConstLabels: constLabels,
+		}, labels)
+	}
+
+	promRoomBitrate = newGaugeVec("room", "bitrate_bps", []string{"room", "tenant", "direction"})
+	promRoomPacketLoss = newGaugeVec("room", "packet_loss_ratio", []string{"room", "tenant", "direction"})
+	promRoomQualityScore = newGaugeVec("room", "quality_score", []string{"room", "tenant"})
+	promRoomSubscribers = newGaugeVec("room", "subscribers", []string{"room", "tenant"})
+	promTrackBitrate = newGaugeVec("track", "bitrate_bps", []string{"room", "tenant", "participant", "track", "direction"})
+	promTrackPacketLoss = newGaugeVec("track", "packet_loss_ratio", []string{"room", "tenant", "participant", "track", "direction"})
+	promTrackQualityScore = newGaugeVec("track", "quality_score", []string{"room", "tenant", "participant", "track", "direction"})
+	promTrackSubscribers = newGaugeVec("track", "subscribers", []string{"room", "tenant", "participant", "track"})
+
+	roomSeriesVecs = []*prometheus.GaugeVec{promRoomBitrate, promRoomPacketLoss, promRoomQualityScore, promRoomSubscribers}
+	participantSeriesVecs = []*prometheus.GaugeVec{promTrackBitrate, promTrackPacketLoss, promTrackQualityScore, promTrackSubscribers}
+	for _, vec := range append(roomSeriesVecs, participantSeriesVecs...) {
+		prometheus.MustRegister(vec)
+	}
+}
+
+// ConfigureRoomMetrics applies the room metrics configuration, existing room series are deleted.
+// Rooms named <tenant>/<room> are labelled with their tenant when it is one of tenants.
+func ConfigureRoomMetrics(conf RoomMetricsConfig, tenants []string) {
+	roomSeriesMu.Lock()
+	defer roomSeriesMu.Unlock()
+
+	if roomSeries != nil {
+		roomSeries.rooms.Purge()
+		roomSeries.tracks.Purge()
+		roomSeries = nil
+	}
+	if !conf.Enabled || promRoomBitrate == nil {
+		return
+	}
+	roomSeries = newRoomSeriesTracker(conf, tenants)
+}
+
+// RecordRoomTrackStats updates room series, and participant series for allowed rooms,
+// from stats aggregated over a stats interval
+func RecordRoomTrackStats(roomName livekit.RoomName, identity livekit.ParticipantIdentity, stats []*livekit.AnalyticsStat) {
+	roomSeriesMu.Lock()
+	defer roomSeriesMu.Unlock()
+
+	if roomSeries == nil {
+		return
+	}
+	roomSeries.recordStats(roomName, identity, stats)
+}
+
+func RecordRoomTrackSubscribed(roomName livekit.RoomName, subscriberID livekit.ParticipantID, publisher livekit.ParticipantIdentity, trackID livekit.TrackID) {
+	roomSeriesMu.Lock()
+	defer roomSeriesMu.Unlock()
+
+	if roomSeries == nil {
+		return
+	}
+	roomSeries.updateSubscription(roomName, subscriberID, publisher, trackID, 1)
+}
+
+func RecordRoomTrackUnsubscribed(roomName livekit.RoomName, subscriberID livekit.ParticipantID, trackID livekit.TrackID) {
+	roomSeriesMu.Lock()
+	defer roomSeriesMu.Unlock()
+
+	if roomSeries == nil {
+		return
+	}
+	roomSeries.updateSubscription(roomName, subscriberID, "", trackID, -1)
+}
+
+// ParticipantLeftRoom deletes the series of a participant and updates room series
+func ParticipantLeftRoom(roomName livekit.RoomName, participantID livekit.ParticipantID, identity livekit.ParticipantIdentity) {
+	roomSeriesMu.Lock()
+	defer roomSeriesMu.Unlock()
+
+	if roomSeries == nil {
+		return
+	}
+	roomSeries.removeParticipant(roomName, participantID, identity)
+}
+
+func deleteRoomSeries(roomName livekit.RoomName) {
+	roomSeriesMu.Lock()
+	defer roomSeriesMu.Unlock()
+
+	if roomSeries == nil {
+		return
+	}
+	roomSeries.rooms.Remove(roomName)
+}
+
+// -------------------------------------------------------------------------
+
+type trackSeriesKey struct {
+	room      livekit.RoomName
+	identity  livekit.ParticipantIdentity
+	track     livekit.TrackID
+	direction Direction
+}
+
+type trackSample struct {
+	bitrate     float64
+	packets     uint64
+	packetsLost uint64
+	score       float32
+}
+
+type roomSeriesState struct {
+	samples map[trackSeriesKey]trackSample
+	// number of subscriptions per subscriber and per published track
+	subscribers      map[livekit.ParticipantID]int
+	trackSubscribers map[trackSeriesKey]int
+}
+
+type roomSeriesTracker struct {
+	conf    RoomMetricsConfig
+	tenants map[string]struct{}
+	rooms   *lru.Cache[livekit.RoomName, *roomSeriesState]
+	tracks  *lru.Cache[trackSeriesKey, struct{}]
+}
+
+func newRoomSeriesTracker(conf RoomMetricsConfig, tenants []string) *roomSeriesTracker {
+	t := &roomSeriesTracker{conf: conf, tenants: make(map[string]struct{}, len(tenants))}
+	for _, tenant := range tenants {
+		t.tenants[tenant] = struct{}{}
+	}
+	t.rooms, _ = lru.NewWithEvict(max(conf.MaxRooms, 1), func(roomName livekit.RoomName, _ *roomSeriesState) {
+		labels := prometheus.Labels{"room": string(roomName)}
+		for _, vec := range roomSeriesVecs {
+			vec.DeletePartialMatch(labels)
+		}
+		for _, vec := range participantSeriesVecs {
+			vec.DeletePartialMatch(labels)
+		}
+		for _, key := range t.tracks.Keys() {
+			if key.room == roomName {
+				t.tracks.Remove(key)
+			}
+		}
+	})
+	t.tracks, _ = lru.NewWithEvict(max(conf.MaxTracks, 1), func(key trackSeriesKey, _ struct{}) {
+		t.deleteTrackSeries(key)
+	})
+	return t
+}
+
+// tenant returns the tenant label of a room
+func (t *roomSeriesTracker) tenant(roomName livekit.RoomName) string {
+	tenant, _, ok := strings.Cut(string(roomName), "/")
+	if !ok {
+		return ""
+	}
+	if _, ok = t.tenants[tenant]; !ok {
+		return ""
+	}
+	return tenant
+}
+
+func (t *roomSeriesTracker) participantSeriesAllowed(roomName livekit.RoomName) bool {
+	for _, allowed := range t.conf.ParticipantRooms {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(string(roomName), prefix) {
+				return true
+			}
+		} else if allowed == string(roomName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *roomSeriesTracker) room(roomName livekit.RoomName) *roomSeriesState {
+	state, ok := t.rooms.Get(roomName)
+	if !ok {
+		state = &roomSeriesState{
+			samples:          make(map[trackSeriesKey]trackSample),
+			subscribers:      make(map[livekit.ParticipantID]int),
+			trackSubscribers: make(map[trackSeriesKey]int),
+		}
+		t.rooms.Add(roomName, state)
+	}
+	return state
+}
+
+func (t *roomSeriesTracker) recordStats(roomName livekit.RoomName, identity livekit.ParticipantIdentity, stats []*livekit.AnalyticsStat) {
+	state := t.room(roomName)
+	participantSeries := t.participantSeriesAllowed(roomName)
+	for _, stat := range stats {
+		direction := Incoming
+		if stat.Kind == livekit.StreamType_DOWNSTREAM {
+			direction = Outgoing
+		}
+		key := trackSeriesKey{room: roomName, identity: identity, track: livekit.TrackID(stat.TrackId), direction: direction}
+		sample := newTrackSample(stat)
+		state.samples[key] = sample
+
+		if participantSeries {
+			t.tracks.Add(key, struct{}{})
+			labels := []string{string(roomName), t.tenant(roomName), string(identity), stat.TrackId, string(direction)}
+			promTrackBitrate.WithLabelValues(labels...).Set(sample.bitrate)
+			promTrackPacketLoss.WithLabelValues(labels...).Set(sample.lossRatio())
+			if sample.score > 0 {
+				promTrackQualityScore.WithLabelValues(labels...).Set(float64(sample.score))
+			}
+		}
+	}
+	t.updateRoom(roomName, state)
+}
+
+func (t *roomSeriesTracker) updateSubscription(
+	roomName livekit.RoomName,
+	subscriberID livekit.ParticipantID,
+	publisher livekit.ParticipantIdentity,
+	trackID livekit.TrackID,
+	delta int,
+) {
+	state := t.room(roomName)
+	if count := state.subscribers[subscriberID] + delta; count > 0 {
+		state.subscribers[subscriberID] = count
+	} else {
+		delete(state.subscribers, subscriberID)
+	}
+
+	if publisher == "" {
+		// unsubscribes do not carry the publisher, it is known from the subscribe
+		for key := range state.trackSubscribers {
+			if key.track == trackID {
+				publisher = key.identity
+				break
+			}
+		}
+	}
+	key := trackSeriesKey{room: roomName, identity: publisher, track: trackID}
+	count := max(state.trackSubscribers[key]+delta, 0)
+	if count > 0 {
+		state.trackSubscribers[key] = count
+	} else {
+		delete(state.trackSubscribers, key)
+	}
+	if t.participantSeriesAllowed(roomName) && publisher != "" {
+		t.tracks.Add(key, struct{}{})
+		promTrackSubscribers.WithLabelValues(string(roomName), t.tenant(roomName), string(publisher), string(trackID)).Set(float64(count))
+	}
+	t.updateRoom(roomName, state)
+}
+
+func (t *roomSeriesTracker) removeParticipant(roomName livekit.RoomName, participantID livekit.ParticipantID, identity livekit.ParticipantIdentity) {
+	state, ok := t.rooms.Peek(roomName)
+	if !ok {
+		return
+	}
+
+	delete(state.subscribers, participantID)
+	for key := range state.samples {
+		if key.identity == identity {
+			delete(state.samples, key)
+		}
+	}
+	for key := range state.trackSubscribers {
+		if key.identity == identity {
+			delete(state.trackSubscribers, key)
+		}
+	}
+	for _, key := range t.tracks.Keys() {
+		if key.room == roomName && key.identity == identity {
+			t.tracks.Remove(key)
+		}
+	}
+	t.updateRoom(roomName, state)
+}
+
+// updateRoom sets room series from the latest samples of each track in the room
+func (t *roomSeriesTracker) updateRoom(roomName livekit.RoomName, state *roomSeriesState) {
+	tenant := t.tenant(roomName)
+	for _, direction := range []Direction{Incoming, Outgoing} {
+		var total trackSample
+		for key, sample := range state.samples {
+			if key.direction != direction {
+				continue
+			}
+			total.bitrate += sample.bitrate
+			total.packets += sample.packets
+			total.packetsLost += sample.packetsLost
+		}
+		promRoomBitrate.WithLabelValues(string(roomName), tenant, string(direction)).Set(total.bitrate)
+		promRoomPacketLoss.WithLabelValues(string(roomName), tenant, string(direction)).Set(total.lossRatio())
+	}
+
+	var scoreSum float32
+	var scores int
+	for _, sample := range state.samples {
+		if sample.score > 0 {
+			scoreSum += sample.score
+			scores++
+		}
+	}
+	if scores > 0 {
+		promRoomQualityScore.WithLabelValues(string(roomName), tenant).Set(float64(scoreSum / float32(scores)))
+	} else {
+		promRoomQualityScore.DeleteLabelValues(string(roomName), tenant)
+	}
+	promRoomSubscribers.WithLabelValues(string(roomName), tenant).Set(float64(len(state.subscribers)))
+}
+
+func (t *roomSeriesTracker) deleteTrackSeries(key trackSeriesKey) {
+	tenant := t.tenant(key.room)
+	if key.direction == "" {
+		promTrackSubscribers.DeleteLabelValues(string(key.room), tenant, string(key.identity), string(key.track))
+		return
+	}
+	labels := []string{string(key.room), tenant, string(key.identity), string(key.track), string(key.direction)}
+	promTrackBitrate.DeleteLabelValues(labels...)
+	promTrackPacketLoss.DeleteLabelValues(labels...)
+	promTrackQualityScore.DeleteLabelValues(labels...)
+}
+
+func newTrackSample(stat *livekit.AnalyticsStat) trackSample {
+	sample := trackSample{score: stat.Score}
+	var bytes uint64
+	var seconds float64
+	for _, stream := range stat.Streams {
+		bytes += stream.PrimaryBytes + stream.RetransmitBytes + stream.PaddingBytes
+		sample.packets += uint64(stream.PrimaryPackets)
+		sample.packetsLost += uint64(stream.PacketsLost)
+		if stream.StartTime != nil && stream.EndTime != nil {
+			seconds = max(seconds, stream.EndTime.AsTime().Sub(stream.StartTime.AsTime()).Seconds())
+		}
+	}
+	if seconds > 0 {
+		sample.bitrate = float64(bytes*8) / seconds
+	}
+	return sample
+}
+
+func (s trackSample) lossRatio() float64 {
+	if s.packets+s.packetsLost == 0 {
+		return 0
+	}
+	return float64(s.packetsLost) / float64(s.packets+s.packetsLost)
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func newTestTrackStat(trackID string, kind livekit.StreamType, bytes uint64, packets uint32, lost uint32, score float32) *livekit.AnalyticsStat {
+	start := time.Now()
+	return &livekit.AnalyticsStat{
+		TrackId: trackID,
+		Kind:    kind,
+		Score:   score,
+		Streams: []*livekit.AnalyticsStream{{
+			StartTime:      timestamppb.New(start),
+			EndTime:        timestamppb.New(start.Add(10 * time.Second)),
+			PrimaryBytes:   bytes,
+			PrimaryPackets: packets,
+			PacketsLost:    lost,
+		}},
+	}
+}
+
+func TestRoomSeries(t *testing.T) {
+	require.NoError(t, Init("test", livekit.NodeType_SERVER))
+
+	t.Run("disabled", func(t *testing.T) {
+		ConfigureRoomMetrics(RoomMetricsConfig{}, nil)
+		RecordRoomTrackStats("room", "alice", []*livekit.AnalyticsStat{
+			newTestTrackStat("TR_a", livekit.StreamType_UPSTREAM, 1000, 10, 0, 4),
+		})
+		require.Equal(t, 0, testutil.CollectAndCount(promRoomBitrate))
+	})
+
+	t.Run("room and participant series", func(t *testing.T) {
+		ConfigureRoomMetrics(RoomMetricsConfig{
+			Enabled:          true,
+			ParticipantRooms: []string{"town-*"},
+			MaxRooms:         10,
+			MaxTracks:        10,
+		}, nil)
+		defer ConfigureRoomMetrics(RoomMetricsConfig{}, nil)
+
+		RecordRoomTrackStats("town-hall", "alice", []*livekit.AnalyticsStat{
+			newTestTrackStat("TR_a", livekit.StreamType_UPSTREAM, 10000, 90, 10, 4),
+		})
+		RecordRoomTrackStats("town-hall", "bob", []*livekit.AnalyticsStat{
+			newTestTrackStat("TR_b", livekit.StreamType_UPSTREAM, 5000, 100, 0, 3),
+		})
+		RecordRoomTrackSubscribed("town-hall", "PA_bob", "alice", "TR_a")
+		RecordRoomTrackStats("lobby", "carol", []*livekit.AnalyticsStat{
+			newTestTrackStat("TR_c", livekit.StreamType_DOWNSTREAM, 1000, 10, 0, 5),
+		})
+
+		require.Equal(t, float64(12000), testutil.ToFloat64(promRoomBitrate.WithLabelValues("town-hall", "", string(Incoming))))
+		require.InDelta(t, 0.05, testutil.ToFloat64(promRoomPacketLoss.WithLabelValues("town-hall", "", string(Incoming))), 1e-9)
+		require.InDelta(t, 3.5, testutil.ToFloat64(promRoomQualityScore.WithLabelValues("town-hall", "")), 1e-6)
+		require.Equal(t, float64(1), testutil.ToFloat64(promRoomSubscribers.WithLabelValues("town-hall", "")))
+		require.Equal(t, float64(8000), testutil.ToFloat64(promTrackBitrate.WithLabelValues("town-hall", "", "alice", "TR_a", string(Incoming))))
+		require.Equal(t, float64(1), testutil.ToFloat64(promTrackSubscribers.WithLabelValues("town-hall", "", "alice", "TR_a")))
+
+		// participant series only for allowed rooms
+		require.Equal(t, float64(800), testutil.ToFloat64(promRoomBitrate.WithLabelValues("lobby", "", string(Outgoing))))
+		require.Equal(t, 2, testutil.CollectAndCount(promTrackBitrate))
+
+		RecordRoomTrackUnsubscribed("town-hall", "PA_bob", "TR_a")
+		require.Equal(t, float64(0), testutil.ToFloat64(promRoomSubscribers.WithLabelValues("town-hall", "")))
+		require.Equal(t, float64(0), testutil.ToFloat64(promTrackSubscribers.WithLabelValues("town-hall", "", "alice", "TR_a")))
+
+		// series of participants that left are deleted
+		ParticipantLeftRoom("town-hall", "PA_alice", "alice")
+		require.Equal(t, float64(4000), testutil.ToFloat64(promRoomBitrate.WithLabelValues("town-hall", "", string(Incoming))))
+		require.Equal(t, 1, testutil.CollectAndCount(promTrackBitrate))
+
+		// and all series of rooms that ended
+		RoomStarted()
+		RoomEnded("town-hall", time.Time{})
+		require.Equal(t, 0, testutil.CollectAndCount(promTrackBitrate))
+		require.Equal(t, 1, testutil.CollectAndCount(promRoomQualityScore))
+	})
+
+	t.Run("tenant label", func(t *testing.T) {
+		ConfigureRoomMetrics(RoomMetricsConfig{
+			Enabled:          true,
+			ParticipantRooms: []string{"*"},
+			MaxRooms:         10,
+			MaxTracks:        10,
+		}, []string{"acme"})
+		defer ConfigureRoomMetrics(RoomMetricsConfig{}, nil)
+
+		RecordRoomTrackStats("acme/standup", "alice", []*livekit.AnalyticsStat{
+			newTestTrackStat("TR_a", livekit.StreamType_UPSTREAM, 1000, 10, 0, 4),
+		})
+		RecordRoomTrackStats("other/standup", "bob", []*livekit.AnalyticsStat{
+			newTestTrackStat("TR_b", livekit.StreamType_UPSTREAM, 2000, 10, 0, 4),
+		})
+
+		require.Equal(t, float64(800), testutil.ToFloat64(promRoomBitrate.WithLabelValues("acme/standup", "acme", string(Incoming))))
+		require.Equal(t, float64(800), testutil.ToFloat64(promTrackBitrate.WithLabelValues("acme/standup", "acme", "alice", "TR_a", string(Incoming))))
+		// rooms outside configured tenants belong to the deployment
+		require.Equal(t, float64(1600), testutil.ToFloat64(promRoomBitrate.WithLabelValues("other/standup", "", string(Incoming))))
+
+		ParticipantLeftRoom("acme/standup", "PA_alice", "alice")
+		require.Equal(t, 1, testutil.CollectAndCount(promTrackBitrate))
+	})
+
+	t.Run("cardinality limits", func(t *testing.T) {
+		ConfigureRoomMetrics(RoomMetricsConfig{
+			Enabled:          true,
+			ParticipantRooms: []string{"*"},
+			MaxRooms:         2,
+			MaxTracks:        2,
+		}, nil)
+		defer ConfigureRoomMetrics(RoomMetricsConfig{}, nil)
+
+		for _, room := range []livekit.RoomName{"a", "b", "c"} {
+			RecordRoomTrackStats(room, "alice", []*livekit.AnalyticsStat{
+				newTestTrackStat("TR_a", livekit.StreamType_UPSTREAM, 1000, 10, 0, 4),
+			})
+		}
+		// least recently updated room and track series are deleted
+		require.Equal(t, 2*2, testutil.CollectAndCount(promRoomBitrate))
+		require.Equal(t, 2, testutil.CollectAndCount(promTrackBitrate))
+	})
+}
@@ -0,0 +1,404 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
+)
+
+const (
+	// maximum number of samples kept in a track timeline, older samples are merged pairwise once reached
+	qualityTimelineMaxSamples = 120
+
+	QualityDirectionUpstream   = "upstream"
+	QualityDirectionDownstream = "downstream"
+)
+
+// QualityReport summarizes the call quality of a room session, generated when the room ends
+type QualityReport struct {
+	RoomSid      string                      `json:"room_sid"`
+	RoomName     string                      `json:"room_name"`
+	StartedAt    int64                       `json:"started_at"`
+	EndedAt      int64                       `json:"ended_at"`
+	Score        float32                     `json:"score"`
+	Participants []*ParticipantQualityReport `json:"participants"`
+}
+
+type ParticipantQualityReport struct {
+	Sid      string                `json:"sid"`
+	Identity string                `json:"identity"`
+	Score    float32               `json:"score"`
+	Tracks   []*TrackQualityReport `json:"tracks"`
+}
+
+type TrackQualityReport struct {
+	TrackSid      string  `json:"track_sid"`
+	Direction     string  `json:"direction"`
+	Score         float32 `json:"score"`
+	MinScore      float32 `json:"min_score"`
+	Packets       uint64  `json:"packets"`
+	PacketsLost   uint64  `json:"packets_lost"`
+	LossRatio     float64 `json:"loss_ratio"`
+	JitterMs      float64 `json:"jitter_ms"`
+	MaxJitterMs   float64 `json:"max_jitter_ms"`
+	RttMs         float64 `json:"rtt_ms"`
+	MaxRttMs      uint32  `json:"max_rtt_ms"`
+	LayerSwitches uint32  `json:"layer_switches"`
+	// only for subscribed video tracks
+	Freezes  *TrackFreezeReport `json:"freezes,omitempty"`
+	Timeline []*QualitySample   `json:"timeline"`
+}
+
+// TrackFreezeReport breaks down video freezes seen by a subscriber by cause,
+// telling network issues apart from the SFU pausing the stream
+type TrackFreezeReport struct {
+	Network       FreezeSummary `json:"network"`
+	KeyFrame      FreezeSummary `json:"key_frame"`
+	Paused        FreezeSummary `json:"paused"`
+	FramesDropped uint32        `json:"frames_dropped"`
+}
+
+type FreezeSummary struct {
+	Count      uint32 `json:"count"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// QualitySample is a point of the timeline of a track, covering one or more stats intervals
+type QualitySample struct {
+	Timestamp int64   `json:"timestamp"`
+	Score     float32 `json:"score"`
+	LossRatio float64 `json:"loss_ratio"`
+	JitterMs  float64 `json:"jitter_ms"`
+	RttMs     uint32  `json:"rtt_ms"`
+	// highest video layer forwarded in the interval, -1 when not applicable
+	Layer int32 `json:"layer"`
+}
+
+// -------------------------------------------------------------------------
+
+type qualityTrackKey struct {
+	trackID   livekit.TrackID
+	direction livekit.StreamType
+}
+
+type trackQuality struct {
+	report     TrackQualityReport
+	scoreSum   float64
+	scoreCount int
+	jitterSum  float64
+	rttSum     float64
+	rttCount   int
+	samples    int
+	lastLayer  int32
+}
+
+type participantQuality struct {
+	identity livekit.ParticipantIdentity
+	tracks   map[qualityTrackKey]*trackQuality
+}
+
+type roomQuality struct {
+	name         livekit.RoomName
+	startedAt    time.Time
+	participants map[livekit.ParticipantID]*participantQuality
+}
+
+// qualityReports aggregates flushed track stats into per room quality timelines
+type qualityReports struct {
+	lock  sync.Mutex
+	rooms map[livekit.RoomID]*roomQuality
+}
+
+func newQualityReports() *qualityReports {
+	return &qualityReports{
+		rooms: make(map[livekit.RoomID]*roomQuality),
+	}
+}
+
+func (q *qualityReports) record(
+	roomID livekit.RoomID,
+	roomName livekit.RoomName,
+	participantID livekit.ParticipantID,
+	identity livekit.ParticipantIdentity,
+	stats []*livekit.AnalyticsStat,
+) {
+	if roomID == "" {
+		return
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	pq := q.getOrCreateParticipantLocked(roomID, roomName, participantID, identity)
+	for _, stat := range stats {
+		pq.getOrCreateTrack(livekit.TrackID(stat.TrackId), stat.Kind).add(stat)
+	}
+}
+
+func (q *qualityReports) getOrCreateParticipantLocked(
+	roomID livekit.RoomID,
+	roomName livekit.RoomName,
+	participantID livekit.ParticipantID,
+	identity livekit.ParticipantIdentity,
+) *participantQuality {
+	rq := q.rooms[roomID]
+	if rq == nil {
+		rq = &roomQuality{
+			name:         roomName,
+			startedAt:    time.Now(),
+			participants: make(map[livekit.ParticipantID]*participantQuality),
+		}
+		q.rooms[roomID] = rq
+	}
+	pq := rq.participants[participantID]
+	if pq == nil {
+		pq = &participantQuality{
+			identity: identity,
+			tracks:   make(map[qualityTrackKey]*trackQuality),
+		}
+		rq.participants[participantID] = pq
+	}
+	return pq
+}
+
+func (q *qualityReports) recordFreezes(
+	roomID livekit.RoomID,
+	roomName livekit.RoomName,
+	participantID livekit.ParticipantID,
+	identity livekit.ParticipantIdentity,
+	trackID livekit.TrackID,
+	stats *connectionquality.FreezeStats,
+) {
+	if roomID == "" {
+		return
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	tq := q.getOrCreateParticipantLocked(roomID, roomName, participantID, identity).getOrCreateTrack(trackID, livekit.StreamType_DOWNSTREAM)
+	if tq.report.Freezes == nil {
+		tq.report.Freezes = &TrackFreezeReport{}
+	}
+	f := tq.report.Freezes
+	f.Network.add(stats.Network)
+	f.KeyFrame.add(stats.KeyFrame)
+	f.Paused.add(stats.Paused)
+	f.FramesDropped += stats.FramesDropped
+}
+
+func (fs *FreezeSummary) add(stats connectionquality.FreezeCauseStats) {
+	fs.Count += stats.Count
+	fs.DurationMs += stats.Duration.Milliseconds()
+}
+
+func (pq *participantQuality) getOrCreateTrack(trackID livekit.TrackID, kind livekit.StreamType) *trackQuality {
+	key := qualityTrackKey{trackID: trackID, direction: kind}
+	tq := pq.tracks[key]
+	if tq == nil {
+		direction := QualityDirectionUpstream
+		if kind == livekit.StreamType_DOWNSTREAM {
+			direction = QualityDirectionDownstream
+		}
+		tq = &trackQuality{
+			report: TrackQualityReport{
+				TrackSid:  string(trackID),
+				Direction: direction,
+			},
+			lastLayer: -1,
+		}
+		pq.tracks[key] = tq
+	}
+	return tq
+}
+
+func (tq *trackQuality) add(stat *livekit.AnalyticsStat) {
+	sample := &QualitySample{
+		Timestamp: stat.TimeStamp.AsTime().UnixMilli(),
+		Score:     stat.Score,
+		Layer:     -1,
+	}
+
+	var packets, lost uint64
+	for _, stream := range stat.Streams {
+		packets += uint64(stream.PrimaryPackets)
+		lost += uint64(stream.PacketsLost)
+		if jitter := float64(stream.Jitter) / 1e3; jitter > sample.JitterMs {
+			sample.JitterMs = jitter
+		}
+		if stream.Rtt > sample.RttMs {
+			sample.RttMs = stream.Rtt
+		}
+		for _, layer := range stream.VideoLayers {
+			if layer.Packets > 0 && layer.Layer > sample.Layer {
+				sample.Layer = layer.Layer
+			}
+		}
+	}
+	if total := packets + lost; total > 0 {
+		sample.LossRatio = float64(lost) / float64(total)
+	}
+
+	r := &tq.report
+	r.Packets += packets
+	r.PacketsLost += lost
+	if stat.Score > 0 {
+		tq.scoreSum += float64(stat.Score)
+		tq.scoreCount++
+		if r.MinScore == 0 || stat.Score < r.MinScore {
+			r.MinScore = stat.Score
+		}
+	}
+	if stat.MinScore > 0 && stat.MinScore < r.MinScore {
+		r.MinScore = stat.MinScore
+	}
+	tq.jitterSum += sample.JitterMs
+	if sample.JitterMs > r.MaxJitterMs {
+		r.MaxJitterMs = sample.JitterMs
+	}
+	if sample.RttMs > 0 {
+		tq.rttSum += float64(sample.RttMs)
+		tq.rttCount++
+		if sample.RttMs > r.MaxRttMs {
+			r.MaxRttMs = sample.RttMs
+		}
+	}
+	tq.samples++
+
+	if sample.Layer >= 0 {
+		if tq.lastLayer >= 0 && sample.Layer != tq.lastLayer {
+			r.LayerSwitches++
+		}
+		tq.lastLayer = sample.Layer
+	}
+
+	if len(r.Timeline) >= qualityTimelineMaxSamples {
+		r.Timeline = compactTimeline(r.Timeline)
+	}
+	r.Timeline = append(r.Timeline, sample)
+}
+
+// compactTimeline halves the timeline by merging adjacent samples, keeping the worst values of each pair
+func compactTimeline(timeline []*QualitySample) []*QualitySample {
+	compacted := timeline[:0]
+	for i := 0; i < len(timeline); i += 2 {
+		s := timeline[i]
+		if i+1 < len(timeline) {
+			next := timeline[i+1]
+			merged := &QualitySample{
+				Timestamp: next.Timestamp,
+				Score:     s.Score,
+				LossRatio: max(s.LossRatio, next.LossRatio),
+				JitterMs:  max(s.JitterMs, next.JitterMs),
+				RttMs:     max(s.RttMs, next.RttMs),
+				Layer:     next.Layer,
+			}
+			if next.Score > 0 && (merged.Score == 0 || next.Score < merged.Score) {
+				merged.Score = next.Score
+			}
+			s = merged
+		}
+		compacted = append(compacted, s)
+	}
+	return compacted
+}
+
+func (tq *trackQuality) toReport() *TrackQualityReport {
+	r := tq.report
+	if tq.scoreCount > 0 {
+		r.Score = float32(tq.scoreSum / float64(tq.scoreCount))
+	}
+	if total := r.Packets + r.PacketsLost; total > 0 {
+		r.LossRatio = float64(r.PacketsLost) / float64(total)
+	}
+	if tq.samples > 0 {
+		r.JitterMs = tq.jitterSum / float64(tq.samples)
+	}
+	if tq.rttCount > 0 {
+		r.RttMs = tq.rttSum / float64(tq.rttCount)
+	}
+	if tq.report.Freezes != nil {
+		freezes := *tq.report.Freezes
+		r.Freezes = &freezes
+	}
+	r.Timeline = append([]*QualitySample(nil), tq.report.Timeline...)
+	return &r
+}
+
+// end removes the aggregated state of the room and returns its report, nil if no stats were recorded
+func (q *qualityReports) end(room *livekit.Room) *QualityReport {
+	q.lock.Lock()
+	rq := q.rooms[livekit.RoomID(room.Sid)]
+	delete(q.rooms, livekit.RoomID(room.Sid))
+	q.lock.Unlock()
+
+	if rq == nil {
+		return nil
+	}
+
+	report := &QualityReport{
+		RoomSid:   room.Sid,
+		RoomName:  room.Name,
+		StartedAt: rq.startedAt.Unix(),
+		EndedAt:   time.Now().Unix(),
+	}
+	if room.CreationTime > 0 {
+		report.StartedAt = room.CreationTime
+	}
+
+	var roomScoreSum float64
+	var roomScoreCount int
+	for participantID, pq := range rq.participants {
+		pr := &ParticipantQualityReport{
+			Sid:      string(participantID),
+			Identity: string(pq.identity),
+		}
+		var scoreSum float64
+		var scoreCount int
+		for _, tq := range pq.tracks {
+			tr := tq.toReport()
+			if tr.Score > 0 {
+				scoreSum += float64(tr.Score)
+				scoreCount++
+			}
+			pr.Tracks = append(pr.Tracks, tr)
+		}
+		sort.Slice(pr.Tracks, func(i, j int) bool {
+			if pr.Tracks[i].TrackSid != pr.Tracks[j].TrackSid {
+				return pr.Tracks[i].TrackSid < pr.Tracks[j].TrackSid
+			}
+			return pr.Tracks[i].Direction < pr.Tracks[j].Direction
+		})
+		if scoreCount > 0 {
+			pr.Score = float32(scoreSum / float64(scoreCount))
+			roomScoreSum += float64(pr.Score)
+			roomScoreCount++
+		}
+		report.Participants = append(report.Participants, pr)
+	}
+	sort.Slice(report.Participants, func(i, j int) bool {
+		return report.Participants[i].Identity < report.Participants[j].Identity
+	})
+	if roomScoreCount > 0 {
+		report.Score = float32(roomScoreSum / float64(roomScoreCount))
+	}
+	return report
+}
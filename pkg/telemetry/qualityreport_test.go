@@ -0,0 +1,153 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/protocol/livekit"
+)
+
+func Test_RoomQualityReport(t *testing.T) {
+	fixture := createFixture()
+
+	room := &livekit.Room{Sid: "RM_quality", Name: "quality", CreationTime: time.Now().Unix()}
+	partSID := livekit.ParticipantID("PA_alice")
+	participantInfo := &livekit.ParticipantInfo{Sid: string(partSID), Identity: "alice"}
+	fixture.sut.ParticipantJoined(context.Background(), room, participantInfo, nil, nil, false)
+
+	trackID := livekit.TrackID("TR_video")
+	key := telemetry.StatsKeyForTrack(livekit.StreamType_UPSTREAM, partSID, trackID, livekit.TrackSource_CAMERA, livekit.TrackType_VIDEO)
+	sendStat := func(score float32, lost uint32, rtt uint32, layer int32) {
+		fixture.sut.TrackStats(key, &livekit.AnalyticsStat{
+			Score: score,
+			Streams: []*livekit.AnalyticsStream{{
+				PrimaryPackets: 90,
+				PacketsLost:    lost,
+				Rtt:            rtt,
+				Jitter:         20000,
+				VideoLayers:    []*livekit.AnalyticsVideoLayer{{Layer: layer, Packets: 90}},
+			}},
+		})
+	}
+
+	sendStat(4.5, 10, 40, 2)
+	fixture.flush()
+	sendStat(3.5, 0, 80, 1)
+	fixture.flush()
+	sendStat(4.0, 10, 60, 2)
+	time.Sleep(100 * time.Millisecond)
+
+	// pending stats are flushed into the report
+	report := fixture.sut.RoomQualityReport(context.Background(), room)
+	require.NotNil(t, report)
+	require.Equal(t, room.Sid, report.RoomSid)
+	require.Equal(t, room.Name, report.RoomName)
+	require.Equal(t, room.CreationTime, report.StartedAt)
+	require.InDelta(t, 4.0, report.Score, 1e-6)
+
+	require.Len(t, report.Participants, 1)
+	p := report.Participants[0]
+	require.Equal(t, "alice", p.Identity)
+	require.Len(t, p.Tracks, 1)
+
+	tr := p.Tracks[0]
+	require.Equal(t, string(trackID), tr.TrackSid)
+	require.Equal(t, telemetry.QualityDirectionUpstream, tr.Direction)
+	require.InDelta(t, 4.0, tr.Score, 1e-6)
+	require.InDelta(t, 3.5, tr.MinScore, 1e-6)
+	require.Equal(t, uint64(270), tr.Packets)
+	require.Equal(t, uint64(20), tr.PacketsLost)
+	require.InDelta(t, 20.0/290.0, tr.LossRatio, 1e-9)
+	require.InDelta(t, 20.0, tr.JitterMs, 1e-9)
+	require.InDelta(t, 60.0, tr.RttMs, 1e-9)
+	require.Equal(t, uint32(80), tr.MaxRttMs)
+	require.Equal(t, uint32(2), tr.LayerSwitches)
+	require.Len(t, tr.Timeline, 3)
+	require.Equal(t, int32(1), tr.Timeline[1].Layer)
+	require.InDelta(t, 0.1, tr.Timeline[0].LossRatio, 1e-9)
+
+	// state is released once the report is generated
+	require.Nil(t, fixture.sut.RoomQualityReport(context.Background(), room))
+}
+
+func Test_RoomQualityReportTimelineIsBounded(t *testing.T) {
+	fixture := createFixture()
+
+	room := &livekit.Room{Sid: "RM_long", Name: "long"}
+	partSID := livekit.ParticipantID("PA_bob")
+	fixture.sut.ParticipantJoined(context.Background(), room, &livekit.ParticipantInfo{Sid: string(partSID), Identity: "bob"}, nil, nil, false)
+
+	key := telemetry.StatsKeyForTrack(livekit.StreamType_DOWNSTREAM, partSID, "TR_audio", livekit.TrackSource_MICROPHONE, livekit.TrackType_AUDIO)
+	for i := 0; i < 300; i++ {
+		fixture.sut.TrackStats(key, &livekit.AnalyticsStat{
+			Score:   4,
+			Streams: []*livekit.AnalyticsStream{{PrimaryPackets: 50}},
+		})
+		time.Sleep(time.Millisecond)
+		fixture.sut.FlushStats()
+	}
+
+	report := fixture.sut.RoomQualityReport(context.Background(), room)
+	require.NotNil(t, report)
+	tr := report.Participants[0].Tracks[0]
+	require.Equal(t, telemetry.QualityDirectionDownstream, tr.Direction)
+	require.Equal(t, uint64(300*50), tr.Packets)
+	require.LessOrEqual(t, len(tr.Timeline), 120)
+	require.Greater(t, len(tr.Timeline), 60)
+	require.Equal(t, int32(-1), tr.Timeline[0].Layer)
+}
+
+func Test_RoomQualityReportFreezes(t *testing.T) {
+	fixture := createFixture()
+
+	room := &livekit.Room{Sid: "RM_freeze", Name: "freeze"}
+	partSID := livekit.ParticipantID("PA_carol")
+	fixture.sut.ParticipantJoined(context.Background(), room, &livekit.ParticipantInfo{Sid: string(partSID), Identity: "carol"}, nil, nil, false)
+
+	key := telemetry.StatsKeyForTrack(livekit.StreamType_DOWNSTREAM, partSID, "TR_video", livekit.TrackSource_CAMERA, livekit.TrackType_VIDEO)
+	fixture.sut.TrackStats(key, &livekit.AnalyticsStat{
+		Score:   4,
+		Streams: []*livekit.AnalyticsStream{{PrimaryPackets: 300}},
+	})
+	fixture.sut.TrackFreezes(key, &connectionquality.FreezeStats{
+		Network:       connectionquality.FreezeCauseStats{Count: 1, Duration: 600 * time.Millisecond},
+		Paused:        connectionquality.FreezeCauseStats{Count: 1, Duration: 2 * time.Second},
+		FramesDropped: 4,
+	})
+	fixture.sut.TrackFreezes(key, &connectionquality.FreezeStats{
+		Network:  connectionquality.FreezeCauseStats{Count: 2, Duration: time.Second},
+		KeyFrame: connectionquality.FreezeCauseStats{Count: 1, Duration: 300 * time.Millisecond},
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	report := fixture.sut.RoomQualityReport(context.Background(), room)
+	require.NotNil(t, report)
+	tr := report.Participants[0].Tracks[0]
+	require.Equal(t, telemetry.QualityDirectionDownstream, tr.Direction)
+	require.Equal(t, uint64(300), tr.Packets)
+	require.Equal(t, &telemetry.TrackFreezeReport{
+		Network:       telemetry.FreezeSummary{Count: 3, DurationMs: 1600},
+		KeyFrame:      telemetry.FreezeSummary{Count: 1, DurationMs: 300},
+		Paused:        telemetry.FreezeSummary{Count: 1, DurationMs: 2000},
+		FramesDropped: 4,
+	}, tr.Freezes)
+}
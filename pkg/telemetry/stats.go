@@ -15,6 +15,7 @@
 package telemetry
 
 import (
+	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	"github.com/livekit/protocol/livekit"
 )
@@ -97,3 +98,22 @@ func (t *telemetryService) TrackStats(key StatsKey, stat *livekit.AnalyticsStat)
 		}
 	})
 }
+
+func (t *telemetryService) TrackFreezes(key StatsKey, stats *connectionquality.FreezeStats) {
+	t.enqueue(func() {
+		for _, cause := range []connectionquality.FreezeCause{
+			connectionquality.FreezeCauseNetwork,
+			connectionquality.FreezeCauseKeyFrame,
+			connectionquality.FreezeCausePaused,
+		} {
+			if cs := stats.Get(cause); cs.Count != 0 {
+				prometheus.RecordVideoFreezes(key.trackSource, cause.String(), cs.Count, cs.Duration)
+			}
+		}
+		prometheus.RecordVideoFramesDropped(key.trackSource, stats.FramesDropped)
+
+		if worker, ok := t.getWorker(key.participantID); ok {
+			worker.OnTrackFreezes(key.trackID, stats)
+		}
+	})
+}
@@ -21,6 +21,8 @@ import (
 
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	"github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
@@ -33,6 +35,7 @@ type StatsWorker struct {
 
 	ctx                 context.Context
 	t                   TelemetryService
+	qualityReports      *qualityReports
 	roomID              livekit.RoomID
 	roomName            livekit.RoomName
 	participantID       livekit.ParticipantID
@@ -48,6 +51,7 @@ type StatsWorker struct {
 func newStatsWorker(
 	ctx context.Context,
 	t TelemetryService,
+	qualityReports *qualityReports,
 	roomID livekit.RoomID,
 	roomName livekit.RoomName,
 	participantID livekit.ParticipantID,
@@ -56,6 +60,7 @@ func newStatsWorker(
 	s := &StatsWorker{
 		ctx:                 ctx,
 		t:                   t,
+		qualityReports:      qualityReports,
 		roomID:              roomID,
 		roomName:            roomName,
 		participantID:       participantID,
@@ -76,6 +81,10 @@ func (s *StatsWorker) OnTrackStat(trackID livekit.TrackID, direction livekit.Str
 	s.lock.Unlock()
 }
 
+func (s *StatsWorker) OnTrackFreezes(trackID livekit.TrackID, stats *connectionquality.FreezeStats) {
+	s.qualityReports.recordFreezes(s.roomID, s.roomName, s.participantID, s.participantIdentity, trackID, stats)
+}
+
 func (s *StatsWorker) ParticipantID() livekit.ParticipantID {
 	return s.participantID
 }
@@ -111,6 +120,8 @@ func (s *StatsWorker) Flush(now time.Time) bool {
 	stats = s.collectStats(ts, livekit.StreamType_UPSTREAM, incomingPerTrack, stats)
 	stats = s.collectStats(ts, livekit.StreamType_DOWNSTREAM, outgoingPerTrack, stats)
 	if len(stats) > 0 {
+		prometheus.RecordRoomTrackStats(s.roomName, s.participantIdentity, stats)
+		s.qualityReports.record(s.roomID, s.roomName, s.participantID, s.participantIdentity, stats)
 		s.t.SendStats(s.ctx, stats)
 	}
 
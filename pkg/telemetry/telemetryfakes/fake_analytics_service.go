@@ -10,12 +10,25 @@ import (
 )
 
 type FakeAnalyticsService struct {
+	CloseStub        func()
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
 	SendEventStub        func(context.Context, *livekit.AnalyticsEvent)
 	sendEventMutex       sync.RWMutex
 	sendEventArgsForCall []struct {
 		arg1 context.Context
 		arg2 *livekit.AnalyticsEvent
 	}
+	SendMetricsStub        func(context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantIdentity, *livekit.MetricsBatch)
+	sendMetricsMutex       sync.RWMutex
+	sendMetricsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomID
+		arg3 livekit.RoomName
+		arg4 livekit.ParticipantIdentity
+		arg5 *livekit.MetricsBatch
+	}
 	SendNodeRoomStatesStub        func(context.Context, *livekit.AnalyticsNodeRooms)
 	sendNodeRoomStatesMutex       sync.RWMutex
 	sendNodeRoomStatesArgsForCall []struct {
@@ -32,6 +45,30 @@ type FakeAnalyticsService struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeAnalyticsService) Close() {
+	fake.closeMutex.Lock()
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		fake.CloseStub()
+	}
+}
+
+func (fake *FakeAnalyticsService) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeAnalyticsService) CloseCalls(stub func()) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
 func (fake *FakeAnalyticsService) SendEvent(arg1 context.Context, arg2 *livekit.AnalyticsEvent) {
 	fake.sendEventMutex.Lock()
 	fake.sendEventArgsForCall = append(fake.sendEventArgsForCall, struct {
@@ -65,6 +102,42 @@ func (fake *FakeAnalyticsService) SendEventArgsForCall(i int) (context.Context,
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *FakeAnalyticsService) SendMetrics(arg1 context.Context, arg2 livekit.RoomID, arg3 livekit.RoomName, arg4 livekit.ParticipantIdentity, arg5 *livekit.MetricsBatch) {
+	fake.sendMetricsMutex.Lock()
+	fake.sendMetricsArgsForCall = append(fake.sendMetricsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomID
+		arg3 livekit.RoomName
+		arg4 livekit.ParticipantIdentity
+		arg5 *livekit.MetricsBatch
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.SendMetricsStub
+	fake.recordInvocation("SendMetrics", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.sendMetricsMutex.Unlock()
+	if stub != nil {
+		fake.SendMetricsStub(arg1, arg2, arg3, arg4, arg5)
+	}
+}
+
+func (fake *FakeAnalyticsService) SendMetricsCallCount() int {
+	fake.sendMetricsMutex.RLock()
+	defer fake.sendMetricsMutex.RUnlock()
+	return len(fake.sendMetricsArgsForCall)
+}
+
+func (fake *FakeAnalyticsService) SendMetricsCalls(stub func(context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantIdentity, *livekit.MetricsBatch)) {
+	fake.sendMetricsMutex.Lock()
+	defer fake.sendMetricsMutex.Unlock()
+	fake.SendMetricsStub = stub
+}
+
+func (fake *FakeAnalyticsService) SendMetricsArgsForCall(i int) (context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantIdentity, *livekit.MetricsBatch) {
+	fake.sendMetricsMutex.RLock()
+	defer fake.sendMetricsMutex.RUnlock()
+	argsForCall := fake.sendMetricsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
 func (fake *FakeAnalyticsService) SendNodeRoomStates(arg1 context.Context, arg2 *livekit.AnalyticsNodeRooms) {
 	fake.sendNodeRoomStatesMutex.Lock()
 	fake.sendNodeRoomStatesArgsForCall = append(fake.sendNodeRoomStatesArgsForCall, struct {
@@ -139,8 +212,12 @@ func (fake *FakeAnalyticsService) SendStatsArgsForCall(i int) (context.Context,
 func (fake *FakeAnalyticsService) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
 	fake.sendEventMutex.RLock()
 	defer fake.sendEventMutex.RUnlock()
+	fake.sendMetricsMutex.RLock()
+	defer fake.sendMetricsMutex.RUnlock()
 	fake.sendNodeRoomStatesMutex.RLock()
 	defer fake.sendNodeRoomStatesMutex.RUnlock()
 	fake.sendStatsMutex.RLock()
@@ -5,6 +5,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/protocol/livekit"
@@ -17,6 +18,10 @@ type FakeTelemetryService struct {
 		arg1 context.Context
 		arg2 *livekit.APICallInfo
 	}
+	CloseStub        func()
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
 	EgressEndedStub        func(context.Context, *livekit.EgressInfo)
 	egressEndedMutex       sync.RWMutex
 	egressEndedArgsForCall []struct {
@@ -90,6 +95,13 @@ type FakeTelemetryService struct {
 		arg4 *livekit.AnalyticsClientMeta
 		arg5 bool
 	}
+	ParticipantAdmittedStub        func(context.Context, *livekit.Room, *livekit.ParticipantInfo)
+	participantAdmittedMutex       sync.RWMutex
+	participantAdmittedArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.Room
+		arg3 *livekit.ParticipantInfo
+	}
 	ParticipantJoinedStub        func(context.Context, *livekit.Room, *livekit.ParticipantInfo, *livekit.ClientInfo, *livekit.AnalyticsClientMeta, bool)
 	participantJoinedMutex       sync.RWMutex
 	participantJoinedArgsForCall []struct {
@@ -108,6 +120,13 @@ type FakeTelemetryService struct {
 		arg3 *livekit.ParticipantInfo
 		arg4 bool
 	}
+	ParticipantMetricsStub        func(context.Context, livekit.ParticipantID, *livekit.MetricsBatch)
+	participantMetricsMutex       sync.RWMutex
+	participantMetricsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.ParticipantID
+		arg3 *livekit.MetricsBatch
+	}
 	ParticipantResumedStub        func(context.Context, *livekit.Room, *livekit.ParticipantInfo, livekit.NodeID, livekit.ReconnectReason)
 	participantResumedMutex       sync.RWMutex
 	participantResumedArgsForCall []struct {
@@ -117,17 +136,37 @@ type FakeTelemetryService struct {
 		arg4 livekit.NodeID
 		arg5 livekit.ReconnectReason
 	}
+	ParticipantWaitingStub        func(context.Context, *livekit.Room, *livekit.ParticipantInfo)
+	participantWaitingMutex       sync.RWMutex
+	participantWaitingArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.Room
+		arg3 *livekit.ParticipantInfo
+	}
 	ReportStub        func(context.Context, *livekit.ReportInfo)
 	reportMutex       sync.RWMutex
 	reportArgsForCall []struct {
 		arg1 context.Context
 		arg2 *livekit.ReportInfo
 	}
-	RoomEndedStub        func(context.Context, *livekit.Room)
+	RoomEndedStub        func(context.Context, *livekit.Room, *telemetry.QualityReport)
 	roomEndedMutex       sync.RWMutex
 	roomEndedArgsForCall []struct {
 		arg1 context.Context
 		arg2 *livekit.Room
+		arg3 *telemetry.QualityReport
+	}
+	RoomQualityReportStub        func(context.Context, *livekit.Room) *telemetry.QualityReport
+	roomQualityReportMutex       sync.RWMutex
+	roomQualityReportArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.Room
+	}
+	roomQualityReportReturns struct {
+		result1 *telemetry.QualityReport
+	}
+	roomQualityReportReturnsOnCall map[int]struct {
+		result1 *telemetry.QualityReport
 	}
 	RoomStartedStub        func(context.Context, *livekit.Room)
 	roomStartedMutex       sync.RWMutex
@@ -141,6 +180,15 @@ type FakeTelemetryService struct {
 		arg1 context.Context
 		arg2 *livekit.AnalyticsEvent
 	}
+	SendMetricsStub        func(context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantIdentity, *livekit.MetricsBatch)
+	sendMetricsMutex       sync.RWMutex
+	sendMetricsArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomID
+		arg3 livekit.RoomName
+		arg4 livekit.ParticipantIdentity
+		arg5 *livekit.MetricsBatch
+	}
 	SendNodeRoomStatesStub        func(context.Context, *livekit.AnalyticsNodeRooms)
 	sendNodeRoomStatesMutex       sync.RWMutex
 	sendNodeRoomStatesArgsForCall []struct {
@@ -153,6 +201,12 @@ type FakeTelemetryService struct {
 		arg1 context.Context
 		arg2 []*livekit.AnalyticsStat
 	}
+	TrackFreezesStub        func(telemetry.StatsKey, *connectionquality.FreezeStats)
+	trackFreezesMutex       sync.RWMutex
+	trackFreezesArgsForCall []struct {
+		arg1 telemetry.StatsKey
+		arg2 *connectionquality.FreezeStats
+	}
 	TrackMaxSubscribedVideoQualityStub        func(context.Context, livekit.ParticipantID, *livekit.TrackInfo, mime.MimeType, livekit.VideoQuality)
 	trackMaxSubscribedVideoQualityMutex       sync.RWMutex
 	trackMaxSubscribedVideoQualityArgsForCall []struct {
@@ -309,6 +363,30 @@ func (fake *FakeTelemetryService) APICallArgsForCall(i int) (context.Context, *l
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *FakeTelemetryService) Close() {
+	fake.closeMutex.Lock()
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		fake.CloseStub()
+	}
+}
+
+func (fake *FakeTelemetryService) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeTelemetryService) CloseCalls(stub func()) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
 func (fake *FakeTelemetryService) EgressEnded(arg1 context.Context, arg2 *livekit.EgressInfo) {
 	fake.egressEndedMutex.Lock()
 	fake.egressEndedArgsForCall = append(fake.egressEndedArgsForCall, struct {
@@ -699,6 +777,40 @@ func (fake *FakeTelemetryService) ParticipantActiveArgsForCall(i int) (context.C
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
+func (fake *FakeTelemetryService) ParticipantAdmitted(arg1 context.Context, arg2 *livekit.Room, arg3 *livekit.ParticipantInfo) {
+	fake.participantAdmittedMutex.Lock()
+	fake.participantAdmittedArgsForCall = append(fake.participantAdmittedArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.Room
+		arg3 *livekit.ParticipantInfo
+	}{arg1, arg2, arg3})
+	stub := fake.ParticipantAdmittedStub
+	fake.recordInvocation("ParticipantAdmitted", []interface{}{arg1, arg2, arg3})
+	fake.participantAdmittedMutex.Unlock()
+	if stub != nil {
+		fake.ParticipantAdmittedStub(arg1, arg2, arg3)
+	}
+}
+
+func (fake *FakeTelemetryService) ParticipantAdmittedCallCount() int {
+	fake.participantAdmittedMutex.RLock()
+	defer fake.participantAdmittedMutex.RUnlock()
+	return len(fake.participantAdmittedArgsForCall)
+}
+
+func (fake *FakeTelemetryService) ParticipantAdmittedCalls(stub func(context.Context, *livekit.Room, *livekit.ParticipantInfo)) {
+	fake.participantAdmittedMutex.Lock()
+	defer fake.participantAdmittedMutex.Unlock()
+	fake.ParticipantAdmittedStub = stub
+}
+
+func (fake *FakeTelemetryService) ParticipantAdmittedArgsForCall(i int) (context.Context, *livekit.Room, *livekit.ParticipantInfo) {
+	fake.participantAdmittedMutex.RLock()
+	defer fake.participantAdmittedMutex.RUnlock()
+	argsForCall := fake.participantAdmittedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
 func (fake *FakeTelemetryService) ParticipantJoined(arg1 context.Context, arg2 *livekit.Room, arg3 *livekit.ParticipantInfo, arg4 *livekit.ClientInfo, arg5 *livekit.AnalyticsClientMeta, arg6 bool) {
 	fake.participantJoinedMutex.Lock()
 	fake.participantJoinedArgsForCall = append(fake.participantJoinedArgsForCall, struct {
@@ -771,6 +883,40 @@ func (fake *FakeTelemetryService) ParticipantLeftArgsForCall(i int) (context.Con
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
+func (fake *FakeTelemetryService) ParticipantMetrics(arg1 context.Context, arg2 livekit.ParticipantID, arg3 *livekit.MetricsBatch) {
+	fake.participantMetricsMutex.Lock()
+	fake.participantMetricsArgsForCall = append(fake.participantMetricsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.ParticipantID
+		arg3 *livekit.MetricsBatch
+	}{arg1, arg2, arg3})
+	stub := fake.ParticipantMetricsStub
+	fake.recordInvocation("ParticipantMetrics", []interface{}{arg1, arg2, arg3})
+	fake.participantMetricsMutex.Unlock()
+	if stub != nil {
+		fake.ParticipantMetricsStub(arg1, arg2, arg3)
+	}
+}
+
+func (fake *FakeTelemetryService) ParticipantMetricsCallCount() int {
+	fake.participantMetricsMutex.RLock()
+	defer fake.participantMetricsMutex.RUnlock()
+	return len(fake.participantMetricsArgsForCall)
+}
+
+func (fake *FakeTelemetryService) ParticipantMetricsCalls(stub func(context.Context, livekit.ParticipantID, *livekit.MetricsBatch)) {
+	fake.participantMetricsMutex.Lock()
+	defer fake.participantMetricsMutex.Unlock()
+	fake.ParticipantMetricsStub = stub
+}
+
+func (fake *FakeTelemetryService) ParticipantMetricsArgsForCall(i int) (context.Context, livekit.ParticipantID, *livekit.MetricsBatch) {
+	fake.participantMetricsMutex.RLock()
+	defer fake.participantMetricsMutex.RUnlock()
+	argsForCall := fake.participantMetricsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
 func (fake *FakeTelemetryService) ParticipantResumed(arg1 context.Context, arg2 *livekit.Room, arg3 *livekit.ParticipantInfo, arg4 livekit.NodeID, arg5 livekit.ReconnectReason) {
 	fake.participantResumedMutex.Lock()
 	fake.participantResumedArgsForCall = append(fake.participantResumedArgsForCall, struct {
@@ -807,6 +953,40 @@ func (fake *FakeTelemetryService) ParticipantResumedArgsForCall(i int) (context.
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
+func (fake *FakeTelemetryService) ParticipantWaiting(arg1 context.Context, arg2 *livekit.Room, arg3 *livekit.ParticipantInfo) {
+	fake.participantWaitingMutex.Lock()
+	fake.participantWaitingArgsForCall = append(fake.participantWaitingArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.Room
+		arg3 *livekit.ParticipantInfo
+	}{arg1, arg2, arg3})
+	stub := fake.ParticipantWaitingStub
+	fake.recordInvocation("ParticipantWaiting", []interface{}{arg1, arg2, arg3})
+	fake.participantWaitingMutex.Unlock()
+	if stub != nil {
+		fake.ParticipantWaitingStub(arg1, arg2, arg3)
+	}
+}
+
+func (fake *FakeTelemetryService) ParticipantWaitingCallCount() int {
+	fake.participantWaitingMutex.RLock()
+	defer fake.participantWaitingMutex.RUnlock()
+	return len(fake.participantWaitingArgsForCall)
+}
+
+func (fake *FakeTelemetryService) ParticipantWaitingCalls(stub func(context.Context, *livekit.Room, *livekit.ParticipantInfo)) {
+	fake.participantWaitingMutex.Lock()
+	defer fake.participantWaitingMutex.Unlock()
+	fake.ParticipantWaitingStub = stub
+}
+
+func (fake *FakeTelemetryService) ParticipantWaitingArgsForCall(i int) (context.Context, *livekit.Room, *livekit.ParticipantInfo) {
+	fake.participantWaitingMutex.RLock()
+	defer fake.participantWaitingMutex.RUnlock()
+	argsForCall := fake.participantWaitingArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
 func (fake *FakeTelemetryService) Report(arg1 context.Context, arg2 *livekit.ReportInfo) {
 	fake.reportMutex.Lock()
 	fake.reportArgsForCall = append(fake.reportArgsForCall, struct {
@@ -840,17 +1020,18 @@ func (fake *FakeTelemetryService) ReportArgsForCall(i int) (context.Context, *li
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeTelemetryService) RoomEnded(arg1 context.Context, arg2 *livekit.Room) {
+func (fake *FakeTelemetryService) RoomEnded(arg1 context.Context, arg2 *livekit.Room, arg3 *telemetry.QualityReport) {
 	fake.roomEndedMutex.Lock()
 	fake.roomEndedArgsForCall = append(fake.roomEndedArgsForCall, struct {
 		arg1 context.Context
 		arg2 *livekit.Room
-	}{arg1, arg2})
+		arg3 *telemetry.QualityReport
+	}{arg1, arg2, arg3})
 	stub := fake.RoomEndedStub
-	fake.recordInvocation("RoomEnded", []interface{}{arg1, arg2})
+	fake.recordInvocation("RoomEnded", []interface{}{arg1, arg2, arg3})
 	fake.roomEndedMutex.Unlock()
 	if stub != nil {
-		fake.RoomEndedStub(arg1, arg2)
+		fake.RoomEndedStub(arg1, arg2, arg3)
 	}
 }
 
@@ -860,19 +1041,81 @@ func (fake *FakeTelemetryService) RoomEndedCallCount() int {
 	return len(fake.roomEndedArgsForCall)
 }
 
-func (fake *FakeTelemetryService) RoomEndedCalls(stub func(context.Context, *livekit.Room)) {
+func (fake *FakeTelemetryService) RoomEndedCalls(stub func(context.Context, *livekit.Room, *telemetry.QualityReport)) {
 	fake.roomEndedMutex.Lock()
 	defer fake.roomEndedMutex.Unlock()
 	fake.RoomEndedStub = stub
 }
 
-func (fake *FakeTelemetryService) RoomEndedArgsForCall(i int) (context.Context, *livekit.Room) {
+func (fake *FakeTelemetryService) RoomEndedArgsForCall(i int) (context.Context, *livekit.Room, *telemetry.QualityReport) {
 	fake.roomEndedMutex.RLock()
 	defer fake.roomEndedMutex.RUnlock()
 	argsForCall := fake.roomEndedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeTelemetryService) RoomQualityReport(arg1 context.Context, arg2 *livekit.Room) *telemetry.QualityReport {
+	fake.roomQualityReportMutex.Lock()
+	ret, specificReturn := fake.roomQualityReportReturnsOnCall[len(fake.roomQualityReportArgsForCall)]
+	fake.roomQualityReportArgsForCall = append(fake.roomQualityReportArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.Room
+	}{arg1, arg2})
+	stub := fake.RoomQualityReportStub
+	fakeReturns := fake.roomQualityReportReturns
+	fake.recordInvocation("RoomQualityReport", []interface{}{arg1, arg2})
+	fake.roomQualityReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTelemetryService) RoomQualityReportCallCount() int {
+	fake.roomQualityReportMutex.RLock()
+	defer fake.roomQualityReportMutex.RUnlock()
+	return len(fake.roomQualityReportArgsForCall)
+}
+
+func (fake *FakeTelemetryService) RoomQualityReportCalls(stub func(context.Context, *livekit.Room) *telemetry.QualityReport) {
+	fake.roomQualityReportMutex.Lock()
+	defer fake.roomQualityReportMutex.Unlock()
+	fake.RoomQualityReportStub = stub
+}
+
+func (fake *FakeTelemetryService) RoomQualityReportArgsForCall(i int) (context.Context, *livekit.Room) {
+	fake.roomQualityReportMutex.RLock()
+	defer fake.roomQualityReportMutex.RUnlock()
+	argsForCall := fake.roomQualityReportArgsForCall[i]
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *FakeTelemetryService) RoomQualityReportReturns(result1 *telemetry.QualityReport) {
+	fake.roomQualityReportMutex.Lock()
+	defer fake.roomQualityReportMutex.Unlock()
+	fake.RoomQualityReportStub = nil
+	fake.roomQualityReportReturns = struct {
+		result1 *telemetry.QualityReport
+	}{result1}
+}
+
+func (fake *FakeTelemetryService) RoomQualityReportReturnsOnCall(i int, result1 *telemetry.QualityReport) {
+	fake.roomQualityReportMutex.Lock()
+	defer fake.roomQualityReportMutex.Unlock()
+	fake.RoomQualityReportStub = nil
+	if fake.roomQualityReportReturnsOnCall == nil {
+		fake.roomQualityReportReturnsOnCall = make(map[int]struct {
+			result1 *telemetry.QualityReport
+		})
+	}
+	fake.roomQualityReportReturnsOnCall[i] = struct {
+		result1 *telemetry.QualityReport
+	}{result1}
+}
+
 func (fake *FakeTelemetryService) RoomStarted(arg1 context.Context, arg2 *livekit.Room) {
 	fake.roomStartedMutex.Lock()
 	fake.roomStartedArgsForCall = append(fake.roomStartedArgsForCall, struct {
@@ -939,6 +1182,42 @@ func (fake *FakeTelemetryService) SendEventArgsForCall(i int) (context.Context,
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *FakeTelemetryService) SendMetrics(arg1 context.Context, arg2 livekit.RoomID, arg3 livekit.RoomName, arg4 livekit.ParticipantIdentity, arg5 *livekit.MetricsBatch) {
+	fake.sendMetricsMutex.Lock()
+	fake.sendMetricsArgsForCall = append(fake.sendMetricsArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomID
+		arg3 livekit.RoomName
+		arg4 livekit.ParticipantIdentity
+		arg5 *livekit.MetricsBatch
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.SendMetricsStub
+	fake.recordInvocation("SendMetrics", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.sendMetricsMutex.Unlock()
+	if stub != nil {
+		fake.SendMetricsStub(arg1, arg2, arg3, arg4, arg5)
+	}
+}
+
+func (fake *FakeTelemetryService) SendMetricsCallCount() int {
+	fake.sendMetricsMutex.RLock()
+	defer fake.sendMetricsMutex.RUnlock()
+	return len(fake.sendMetricsArgsForCall)
+}
+
+func (fake *FakeTelemetryService) SendMetricsCalls(stub func(context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantIdentity, *livekit.MetricsBatch)) {
+	fake.sendMetricsMutex.Lock()
+	defer fake.sendMetricsMutex.Unlock()
+	fake.SendMetricsStub = stub
+}
+
+func (fake *FakeTelemetryService) SendMetricsArgsForCall(i int) (context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantIdentity, *livekit.MetricsBatch) {
+	fake.sendMetricsMutex.RLock()
+	defer fake.sendMetricsMutex.RUnlock()
+	argsForCall := fake.sendMetricsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
 func (fake *FakeTelemetryService) SendNodeRoomStates(arg1 context.Context, arg2 *livekit.AnalyticsNodeRooms) {
 	fake.sendNodeRoomStatesMutex.Lock()
 	fake.sendNodeRoomStatesArgsForCall = append(fake.sendNodeRoomStatesArgsForCall, struct {
@@ -1010,6 +1289,39 @@ func (fake *FakeTelemetryService) SendStatsArgsForCall(i int) (context.Context,
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *FakeTelemetryService) TrackFreezes(arg1 telemetry.StatsKey, arg2 *connectionquality.FreezeStats) {
+	fake.trackFreezesMutex.Lock()
+	fake.trackFreezesArgsForCall = append(fake.trackFreezesArgsForCall, struct {
+		arg1 telemetry.StatsKey
+		arg2 *connectionquality.FreezeStats
+	}{arg1, arg2})
+	stub := fake.TrackFreezesStub
+	fake.recordInvocation("TrackFreezes", []interface{}{arg1, arg2})
+	fake.trackFreezesMutex.Unlock()
+	if stub != nil {
+		fake.TrackFreezesStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeTelemetryService) TrackFreezesCallCount() int {
+	fake.trackFreezesMutex.RLock()
+	defer fake.trackFreezesMutex.RUnlock()
+	return len(fake.trackFreezesArgsForCall)
+}
+
+func (fake *FakeTelemetryService) TrackFreezesCalls(stub func(telemetry.StatsKey, *connectionquality.FreezeStats)) {
+	fake.trackFreezesMutex.Lock()
+	defer fake.trackFreezesMutex.Unlock()
+	fake.TrackFreezesStub = stub
+}
+
+func (fake *FakeTelemetryService) TrackFreezesArgsForCall(i int) (telemetry.StatsKey, *connectionquality.FreezeStats) {
+	fake.trackFreezesMutex.RLock()
+	defer fake.trackFreezesMutex.RUnlock()
+	argsForCall := fake.trackFreezesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *FakeTelemetryService) TrackMaxSubscribedVideoQuality(arg1 context.Context, arg2 livekit.ParticipantID, arg3 *livekit.TrackInfo, arg4 mime.MimeType, arg5 livekit.VideoQuality) {
 	fake.trackMaxSubscribedVideoQualityMutex.Lock()
 	fake.trackMaxSubscribedVideoQualityArgsForCall = append(fake.trackMaxSubscribedVideoQualityArgsForCall, struct {
@@ -1539,6 +1851,8 @@ func (fake *FakeTelemetryService) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.aPICallMutex.RLock()
 	defer fake.aPICallMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
 	fake.egressEndedMutex.RLock()
 	defer fake.egressEndedMutex.RUnlock()
 	fake.egressStartedMutex.RLock()
@@ -1563,24 +1877,36 @@ func (fake *FakeTelemetryService) Invocations() map[string][][]interface{} {
 	defer fake.notifyEventMutex.RUnlock()
 	fake.participantActiveMutex.RLock()
 	defer fake.participantActiveMutex.RUnlock()
+	fake.participantAdmittedMutex.RLock()
+	defer fake.participantAdmittedMutex.RUnlock()
 	fake.participantJoinedMutex.RLock()
 	defer fake.participantJoinedMutex.RUnlock()
 	fake.participantLeftMutex.RLock()
 	defer fake.participantLeftMutex.RUnlock()
+	fake.participantMetricsMutex.RLock()
+	defer fake.participantMetricsMutex.RUnlock()
 	fake.participantResumedMutex.RLock()
 	defer fake.participantResumedMutex.RUnlock()
+	fake.participantWaitingMutex.RLock()
+	defer fake.participantWaitingMutex.RUnlock()
 	fake.reportMutex.RLock()
 	defer fake.reportMutex.RUnlock()
 	fake.roomEndedMutex.RLock()
 	defer fake.roomEndedMutex.RUnlock()
+	fake.roomQualityReportMutex.RLock()
+	defer fake.roomQualityReportMutex.RUnlock()
 	fake.roomStartedMutex.RLock()
 	defer fake.roomStartedMutex.RUnlock()
 	fake.sendEventMutex.RLock()
 	defer fake.sendEventMutex.RUnlock()
+	fake.sendMetricsMutex.RLock()
+	defer fake.sendMetricsMutex.RUnlock()
 	fake.sendNodeRoomStatesMutex.RLock()
 	defer fake.sendNodeRoomStatesMutex.RUnlock()
 	fake.sendStatsMutex.RLock()
 	defer fake.sendStatsMutex.RUnlock()
+	fake.trackFreezesMutex.RLock()
+	defer fake.trackFreezesMutex.RUnlock()
 	fake.trackMaxSubscribedVideoQualityMutex.RLock()
 	defer fake.trackMaxSubscribedVideoQualityMutex.RUnlock()
 	fake.trackMutedMutex.RLock()
@@ -19,6 +19,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/livekit/livekit-server/pkg/metric"
+	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/protocol/livekit"
@@ -32,16 +34,23 @@ import (
 type TelemetryService interface {
 	// TrackStats is called periodically for each track in both directions (published/subscribed)
 	TrackStats(key StatsKey, stat *livekit.AnalyticsStat)
+	// TrackFreezes is called periodically for subscribed video tracks which had freezes since the previous call
+	TrackFreezes(key StatsKey, stats *connectionquality.FreezeStats)
 
 	// events
 	RoomStarted(ctx context.Context, room *livekit.Room)
-	RoomEnded(ctx context.Context, room *livekit.Room)
+	// RoomEnded - the room closed, its quality report is delivered with the room_finished webhook when not nil
+	RoomEnded(ctx context.Context, room *livekit.Room, report *QualityReport)
 	// ParticipantJoined - a participant establishes signal connection to a room
 	ParticipantJoined(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo, clientInfo *livekit.ClientInfo, clientMeta *livekit.AnalyticsClientMeta, shouldSendEvent bool)
 	// ParticipantActive - a participant establishes media connection
 	ParticipantActive(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo, clientMeta *livekit.AnalyticsClientMeta, isMigration bool)
 	// ParticipantResumed - there has been an ICE restart or connection resume attempt, and we've received their signal connection
 	ParticipantResumed(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo, nodeID livekit.NodeID, reason livekit.ReconnectReason)
+	// ParticipantWaiting - the participant is waiting in the lobby of the room to be admitted
+	ParticipantWaiting(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo)
+	// ParticipantAdmitted - the participant has been admitted from the lobby
+	ParticipantAdmitted(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo)
 	// ParticipantLeft - the participant leaves the room, only sent if ParticipantActive has been called before
 	ParticipantLeft(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo, shouldSendEvent bool)
 	// TrackPublishRequested - a publication attempt has been received
@@ -80,6 +89,11 @@ type TelemetryService interface {
 	Report(ctx context.Context, reportInfo *livekit.ReportInfo)
 	APICall(ctx context.Context, apiCallInfo *livekit.APICallInfo)
 	Webhook(ctx context.Context, webhookInfo *livekit.WebhookInfo)
+	// ParticipantMetrics - a metrics batch was reported by, or collected for a participant
+	ParticipantMetrics(ctx context.Context, participantID livekit.ParticipantID, batch *livekit.MetricsBatch)
+	// RoomQualityReport - flushes pending stats of the room and returns its quality report, nil when no stats were recorded.
+	// aggregated state of the room is released, it should be called once when the room is closed
+	RoomQualityReport(ctx context.Context, room *livekit.Room) *QualityReport
 
 	// helpers
 	AnalyticsService
@@ -106,9 +120,14 @@ type telemetryService struct {
 	workerList *StatsWorker
 
 	flushMu sync.Mutex
+
+	qualityReports *qualityReports
+
+	metricsConfig metric.MetricsAggregatorConfig
+	metricsFilter *metric.MetricsLabelFilter
 }
 
-func NewTelemetryService(notifier webhook.QueuedNotifier, analytics AnalyticsService) TelemetryService {
+func NewTelemetryService(notifier webhook.QueuedNotifier, analytics AnalyticsService, metricsConfig metric.MetricsAggregatorConfig) TelemetryService {
 	t := &telemetryService{
 		AnalyticsService: analytics,
 		notifier:         notifier,
@@ -118,7 +137,10 @@ func NewTelemetryService(notifier webhook.QueuedNotifier, analytics AnalyticsSer
 			FlushOnStop: true,
 			Logger:      logger.GetLogger(),
 		}),
-		workers: make(map[livekit.ParticipantID]*StatsWorker),
+		workers:        make(map[livekit.ParticipantID]*StatsWorker),
+		qualityReports: newQualityReports(),
+		metricsConfig:  metricsConfig,
+		metricsFilter:  metric.NewMetricsLabelFilter(metricsConfig.Labels),
 	}
 	if t.notifier != nil {
 		t.notifier.RegisterProcessedHook(func(ctx context.Context, whi *livekit.WebhookInfo) {
@@ -181,6 +203,32 @@ func (t *telemetryService) FlushStats() {
 	}
 }
 
+// Close flushes pending stats and queued events before closing the analytics sinks
+func (t *telemetryService) Close() {
+	t.FlushStats()
+	<-t.jobsQueue.Stop()
+	t.AnalyticsService.Close()
+}
+
+func (t *telemetryService) RoomQualityReport(_ context.Context, room *livekit.Room) *QualityReport {
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	t.workersMu.RLock()
+	worker := t.workerList
+	t.workersMu.RUnlock()
+
+	// closed workers are reaped by the next periodic flush
+	now := time.Now()
+	for ; worker != nil; worker = worker.next {
+		if worker.roomID == livekit.RoomID(room.Sid) {
+			worker.Flush(now)
+		}
+	}
+
+	return t.qualityReports.end(room)
+}
+
 func (t *telemetryService) run() {
 	for range time.Tick(telemetryStatsUpdateInterval) {
 		t.FlushStats()
@@ -222,6 +270,7 @@ func (t *telemetryService) getOrCreateWorker(
 	worker = newStatsWorker(
 		ctx,
 		t,
+		t.qualityReports,
 		roomID,
 		roomName,
 		participantID,
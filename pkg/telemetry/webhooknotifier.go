@@ -0,0 +1,290 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"github.com/hashicorp/go-retryablehttp"
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/webhook"
+)
+
+const (
+	webhookWorkers   = 10
+	webhookQueueSize = 100
+
+	// field of the room_finished payload carrying the quality report, next to the fields of the event
+	webhookQualityReportField = "qualityReport"
+)
+
+// QualityReportNotifier is implemented by webhook notifiers delivering the quality report of a room with its
+// room_finished event
+type QualityReportNotifier interface {
+	QueueNotifyRoomFinished(ctx context.Context, event *livekit.WebhookEvent, report *QualityReport) error
+}
+
+// WebhookNotifier posts webhook events to URLs the way the protocol notifier does, with the same signature
+// and retries. The protocol payload is the WebhookEvent message, which has no field for the quality report,
+// so the report of a room is added to the JSON of its room_finished event.
+type WebhookNotifier struct {
+	urlNotifiers []*webhookURLNotifier
+}
+
+var (
+	_ webhook.QueuedNotifier = (*WebhookNotifier)(nil)
+	_ QualityReportNotifier  = (*WebhookNotifier)(nil)
+)
+
+func NewWebhookNotifier(apiKey, apiSecret string, urls []string) *WebhookNotifier {
+	n := &WebhookNotifier{}
+	for _, url := range urls {
+		n.urlNotifiers = append(n.urlNotifiers, newWebhookURLNotifier(url, apiKey, apiSecret))
+	}
+	return n
+}
+
+func (n *WebhookNotifier) RegisterProcessedHook(hook func(ctx context.Context, whi *livekit.WebhookInfo)) {
+	for _, u := range n.urlNotifiers {
+		u.setProcessedHook(hook)
+	}
+}
+
+func (n *WebhookNotifier) QueueNotify(ctx context.Context, event *livekit.WebhookEvent) error {
+	return n.QueueNotifyRoomFinished(ctx, event, nil)
+}
+
+func (n *WebhookNotifier) QueueNotifyRoomFinished(ctx context.Context, event *livekit.WebhookEvent, report *QualityReport) error {
+	for _, u := range n.urlNotifiers {
+		u.queue(ctx, event, report)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) Stop(force bool) {
+	var wg sync.WaitGroup
+	for _, u := range n.urlNotifiers {
+		wg.Add(1)
+		go func(u *webhookURLNotifier) {
+			defer wg.Done()
+			u.stop(force)
+		}(u)
+	}
+	wg.Wait()
+}
+
+// -------------------------------------------------------------------------
+
+type webhookURLNotifier struct {
+	url    string
+	client *retryablehttp.Client
+	pool   core.QueuePool
+	logger logger.Logger
+
+	dropped atomic.Int32
+
+	lock          sync.RWMutex
+	apiKey        string
+	apiSecret     string
+	processedHook func(ctx context.Context, whi *livekit.WebhookInfo)
+}
+
+func newWebhookURLNotifier(url, apiKey, apiSecret string) *webhookURLNotifier {
+	client := retryablehttp.NewClient()
+	client.Logger = nil
+	return &webhookURLNotifier{
+		url:    url,
+		client: client,
+		pool: core.NewQueuePool(webhookWorkers, core.QueueWorkerParams{
+			QueueSize:    webhookQueueSize,
+			DropWhenFull: true,
+		}),
+		logger:    logger.GetLogger().WithComponent("webhook"),
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+	}
+}
+
+func (u *webhookURLNotifier) setProcessedHook(hook func(ctx context.Context, whi *livekit.WebhookInfo)) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.processedHook = hook
+}
+
+func (u *webhookURLNotifier) queue(ctx context.Context, event *livekit.WebhookEvent, report *QualityReport) {
+	queuedAt := time.Now()
+	// events of a room are sent in order
+	if u.pool.Submit(webhookEventKey(event), func() {
+		sentAt := time.Now()
+		err := u.send(event, report)
+		u.processed(ctx, event, queuedAt, sentAt, false, err)
+	}) {
+		return
+	}
+
+	u.dropped.Inc()
+	u.logger.Infow("dropped webhook", "event", event.Event, "id", event.Id, "url", u.url)
+	u.processed(ctx, event, time.Time{}, time.Time{}, true, nil)
+}
+
+func (u *webhookURLNotifier) send(event *livekit.WebhookEvent, report *QualityReport) error {
+	event.NumDropped = u.dropped.Swap(0)
+	encoded, err := encodeWebhookEvent(event, report)
+	if err == nil {
+		err = u.post(encoded)
+	}
+	if err != nil {
+		u.dropped.Add(event.NumDropped + 1)
+		u.logger.Warnw("failed to send webhook", err, "event", event.Event, "id", event.Id, "url", u.url)
+		return err
+	}
+	u.logger.Infow("sent webhook", "event", event.Event, "id", event.Id, "url", u.url)
+	return nil
+}
+
+func (u *webhookURLNotifier) post(encoded []byte) error {
+	sum := sha256.Sum256(encoded)
+
+	u.lock.RLock()
+	token, err := auth.NewAccessToken(u.apiKey, u.apiSecret).
+		SetValidFor(5 * time.Minute).
+		SetSha256(base64.StdEncoding.EncodeToString(sum[:])).
+		ToJWT()
+	u.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	req, err := retryablehttp.NewRequest("POST", u.url, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", token)
+	// the custom mime type makes receivers check the signature before parsing
+	req.Header.Set("Content-Type", "application/webhook+json")
+	res, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (u *webhookURLNotifier) processed(
+	ctx context.Context,
+	event *livekit.WebhookEvent,
+	queuedAt time.Time,
+	sentAt time.Time,
+	dropped bool,
+	err error,
+) {
+	u.lock.RLock()
+	hook := u.processedHook
+	u.lock.RUnlock()
+	if hook == nil {
+		return
+	}
+
+	whi := &livekit.WebhookInfo{
+		EventId:    event.Id,
+		Event:      event.Event,
+		CreatedAt:  timestamppb.New(time.Unix(event.CreatedAt, 0)),
+		Url:        u.url,
+		NumDropped: event.NumDropped,
+		IsDropped:  dropped,
+	}
+	if !queuedAt.IsZero() {
+		whi.QueuedAt = timestamppb.New(queuedAt)
+		whi.QueueDurationNs = sentAt.Sub(queuedAt).Nanoseconds()
+		whi.SentAt = timestamppb.New(sentAt)
+		whi.SendDurationNs = time.Since(sentAt).Nanoseconds()
+	}
+	if event.Room != nil {
+		whi.RoomName = event.Room.Name
+		whi.RoomId = event.Room.Sid
+	}
+	if event.Participant != nil {
+		whi.ParticipantIdentity = event.Participant.Identity
+		whi.ParticipantId = event.Participant.Sid
+	}
+	if event.Track != nil {
+		whi.TrackId = event.Track.Sid
+	}
+	if event.EgressInfo != nil {
+		whi.EgressId = event.EgressInfo.EgressId
+		whi.ServiceStatus = event.EgressInfo.Status.String()
+		whi.ServiceError = event.EgressInfo.Error
+	}
+	if event.IngressInfo != nil {
+		whi.IngressId = event.IngressInfo.IngressId
+	}
+	if err != nil {
+		whi.SendError = err.Error()
+	}
+	hook(ctx, whi)
+}
+
+func (u *webhookURLNotifier) stop(force bool) {
+	if force {
+		u.pool.Kill()
+	} else {
+		u.pool.Drain()
+	}
+}
+
+// encodeWebhookEvent returns the JSON of the event, with the quality report in its own field when there is one
+func encodeWebhookEvent(event *livekit.WebhookEvent, report *QualityReport) ([]byte, error) {
+	encoded, err := protojson.Marshal(event)
+	if err != nil || report == nil {
+		return encoded, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	if fields[webhookQualityReportField], err = json.Marshal(report); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+func webhookEventKey(event *livekit.WebhookEvent) string {
+	switch {
+	case event.EgressInfo != nil:
+		return event.EgressInfo.EgressId
+	case event.IngressInfo != nil:
+		return event.IngressInfo.IngressId
+	case event.Room != nil:
+		return event.Room.Name
+	case event.Participant != nil:
+		return event.Participant.Identity
+	case event.Track != nil:
+		return event.Track.Sid
+	}
+	return "default"
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+
+	"github.com/livekit/livekit-server/pkg/telemetry"
+)
+
+func Test_WebhookNotifier(t *testing.T) {
+	payloads := make(chan []byte, 10)
+	events := make(chan *livekit.WebhookEvent, 10)
+	provider := auth.NewSimpleKeyProvider("key", "secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := webhook.Receive(r, provider)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// receivers of the protocol payload ignore the report
+		event := &livekit.WebhookEvent{}
+		if err = (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		payloads <- data
+		events <- event
+	}))
+	defer server.Close()
+
+	n := telemetry.NewWebhookNotifier("key", "secret", []string{server.URL})
+	defer n.Stop(false)
+
+	report := &telemetry.QualityReport{
+		RoomSid:  "RM_1",
+		RoomName: "room",
+		Score:    4.2,
+		Participants: []*telemetry.ParticipantQualityReport{
+			{Sid: "PA_1", Identity: "p1", Score: 4.2},
+		},
+	}
+	room := &livekit.Room{Sid: "RM_1", Name: "room"}
+	require.NoError(t, n.QueueNotify(context.Background(), &livekit.WebhookEvent{Event: webhook.EventParticipantLeft, Id: "EV_1", Room: room}))
+	require.NoError(t, n.QueueNotifyRoomFinished(context.Background(), &livekit.WebhookEvent{Event: webhook.EventRoomFinished, Id: "EV_2", Room: room}, report))
+
+	receive := func() ([]byte, *livekit.WebhookEvent) {
+		select {
+		case data := <-payloads:
+			return data, <-events
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook not received")
+			return nil, nil
+		}
+	}
+
+	// events of the room arrive in order, only room_finished carries the report
+	data, event := receive()
+	require.Equal(t, webhook.EventParticipantLeft, event.Event)
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.NotContains(t, fields, "qualityReport")
+
+	data, event = receive()
+	require.Equal(t, webhook.EventRoomFinished, event.Event)
+	require.Equal(t, "EV_2", event.Id)
+	require.Equal(t, "room", event.Room.Name)
+
+	var payload struct {
+		QualityReport *telemetry.QualityReport `json:"qualityReport"`
+	}
+	require.NoError(t, json.Unmarshal(data, &payload))
+	require.Equal(t, report, payload.QualityReport)
+}